@@ -26,8 +26,20 @@ func main() {
 
 	notifyCh := make(chan os.Signal, 1)
 	signal.Notify(notifyCh, syscall.SIGINT, os.Interrupt)
+	drainCh := make(chan os.Signal, 1)
+	signal.Notify(drainCh, syscall.SIGUSR1)
 
-	<-notifyCh
+wait:
+	for {
+		select {
+		case <-notifyCh:
+			break wait
+		case <-drainCh:
+			app.Drain()
+		case <-app.Drained():
+			break wait
+		}
+	}
 	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
 	defer cancel()
 	app.Close(ctx)
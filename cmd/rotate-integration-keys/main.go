@@ -0,0 +1,26 @@
+package main
+
+import (
+	"context"
+	"log/slog"
+	"os"
+	"time"
+
+	"github.com/cloudyy74/pr-reviewer-service/internal/app"
+	"github.com/cloudyy74/pr-reviewer-service/internal/config"
+)
+
+func main() {
+	cfg := config.MustLoadConfig()
+	log := slog.New(slog.NewTextHandler(os.Stdout, nil))
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Minute)
+	defer cancel()
+
+	rotated, err := app.RotateIntegrationKeys(ctx, cfg, log)
+	if err != nil {
+		log.Error("failed to rotate integration token keys", slog.Any("error", err))
+		os.Exit(1)
+	}
+	log.Info("integration token keys rotated", slog.Int("rotated", rotated), slog.String("primary_key", cfg.Encryption.PrimaryKey))
+}
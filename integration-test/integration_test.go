@@ -10,6 +10,7 @@ import (
 	"path/filepath"
 	"strings"
 	"testing"
+	"time"
 
 	"github.com/cloudyy74/pr-reviewer-service/internal/models"
 	"github.com/cloudyy74/pr-reviewer-service/internal/service"
@@ -23,8 +24,76 @@ var (
 		"../internal/data/000001_users_teams_tables.up.sql",
 		"../internal/data/000002_pr_tables.up.sql",
 		"../internal/data/000003_pr_statuses.up.sql",
+		"../internal/data/000004_team_settings.up.sql",
+		"../internal/data/000005_assignment_acknowledgements.up.sql",
+		"../internal/data/000006_pr_created_at.up.sql",
+		"../internal/data/000007_assignment_anomalies.up.sql",
+		"../internal/data/000008_pr_status_column.up.sql",
+		"../internal/data/000009_team_name_lower_unique.up.sql",
+		"../internal/data/000010_users_username_idx.up.sql",
+		"../internal/data/000011_outbox_events.up.sql",
+		"../internal/data/000012_user_notification_settings.up.sql",
+		"../internal/data/000013_notifications.up.sql",
+		"../internal/data/000014_sessions.up.sql",
+		"../internal/data/000015_user_identities.up.sql",
+		"../internal/data/000016_audit_log.up.sql",
+		"../internal/data/000017_integration_tokens.up.sql",
+		"../internal/data/000018_pr_status_enum.up.sql",
+		"../internal/data/000019_team_rotation_cursors.up.sql",
+		"../internal/data/000020_reviewer_exclusions.up.sql",
+		"../internal/data/000021_team_fallbacks.up.sql",
+		"../internal/data/000022_pull_requests_author_idx.up.sql",
+		"../internal/data/000023_users_review_weight.up.sql",
+		"../internal/data/000024_team_membership_history.up.sql",
+		"../internal/data/000025_assignment_snapshots.up.sql",
+		"../internal/data/000026_users_version.up.sql",
+		"../internal/data/000027_users_working_hours.up.sql",
+		"../internal/data/000028_user_absences.up.sql",
+		"../internal/data/000029_team_required_reviewers.up.sql",
+		"../internal/data/000030_reviewer_review_state.up.sql",
+		"../internal/data/000031_pr_closed_status.up.sql",
+		"../internal/data/000032_jira_review_tasks.up.sql",
+		"../internal/data/000033_pr_description_labels.up.sql",
+		"../internal/data/000034_pr_links.up.sql",
+		"../internal/data/000035_pr_priority.up.sql",
+		"../internal/data/000036_request_nonces.up.sql",
+		"../internal/data/000037_review_deadlines.up.sql",
 	}
 	downMigrations = []string{
+		"../internal/data/000037_review_deadlines.down.sql",
+		"../internal/data/000036_request_nonces.down.sql",
+		"../internal/data/000035_pr_priority.down.sql",
+		"../internal/data/000034_pr_links.down.sql",
+		"../internal/data/000033_pr_description_labels.down.sql",
+		"../internal/data/000032_jira_review_tasks.down.sql",
+		"../internal/data/000031_pr_closed_status.down.sql",
+		"../internal/data/000030_reviewer_review_state.down.sql",
+		"../internal/data/000029_team_required_reviewers.down.sql",
+		"../internal/data/000028_user_absences.down.sql",
+		"../internal/data/000027_users_working_hours.down.sql",
+		"../internal/data/000026_users_version.down.sql",
+		"../internal/data/000025_assignment_snapshots.down.sql",
+		"../internal/data/000024_team_membership_history.down.sql",
+		"../internal/data/000023_users_review_weight.down.sql",
+		"../internal/data/000022_pull_requests_author_idx.down.sql",
+		"../internal/data/000021_team_fallbacks.down.sql",
+		"../internal/data/000020_reviewer_exclusions.down.sql",
+		"../internal/data/000019_team_rotation_cursors.down.sql",
+		"../internal/data/000018_pr_status_enum.down.sql",
+		"../internal/data/000017_integration_tokens.down.sql",
+		"../internal/data/000016_audit_log.down.sql",
+		"../internal/data/000015_user_identities.down.sql",
+		"../internal/data/000014_sessions.down.sql",
+		"../internal/data/000013_notifications.down.sql",
+		"../internal/data/000012_user_notification_settings.down.sql",
+		"../internal/data/000011_outbox_events.down.sql",
+		"../internal/data/000010_users_username_idx.down.sql",
+		"../internal/data/000009_team_name_lower_unique.down.sql",
+		"../internal/data/000008_pr_status_column.down.sql",
+		"../internal/data/000007_assignment_anomalies.down.sql",
+		"../internal/data/000006_pr_created_at.down.sql",
+		"../internal/data/000005_assignment_acknowledgements.down.sql",
+		"../internal/data/000004_team_settings.down.sql",
 		"../internal/data/000003_pr_statuses.down.sql",
 		"../internal/data/000002_pr_tables.down.sql",
 		"../internal/data/000001_users_teams_tables.down.sql",
@@ -107,7 +176,7 @@ func newServices(t *testing.T, pg *postgres.Postgres, log *slog.Logger) (*servic
 	if err != nil {
 		t.Fatalf("user service: %v", err)
 	}
-	prSvc, err := service.NewPRService(txManager, prStorage, userStorage, log)
+	prSvc, err := service.NewPRService(txManager, prStorage, userStorage, teamStorage, log)
 	if err != nil {
 		t.Fatalf("pr service: %v", err)
 	}
@@ -142,7 +211,7 @@ func TestIntegrationTeamLifecycle(t *testing.T) {
 		t.Fatalf("expected 2 users, got %d", len(users))
 	}
 
-	updated, err := userSvc.SetUserActive(ctx, "u2", false)
+	updated, err := userSvc.SetUserActive(ctx, "u2", false, 0)
 	if err != nil {
 		t.Fatalf("SetUserActive: %v", err)
 	}
@@ -185,11 +254,11 @@ func TestIntegrationPRWorkflow(t *testing.T) {
 	if err != nil {
 		t.Fatalf("CreatePR: %v", err)
 	}
-	if len(pr.Reviewers) != 2 {
-		t.Fatalf("expected 2 reviewers, got %d", len(pr.Reviewers))
+	if len(pr.PR.Reviewers) != 2 {
+		t.Fatalf("expected 2 reviewers, got %d", len(pr.PR.Reviewers))
 	}
 
-	reviews, err := prSvc.GetUserReviews(ctx, pr.Reviewers[0])
+	reviews, err := prSvc.GetUserReviews(ctx, pr.PR.Reviewers[0])
 	if err != nil {
 		t.Fatalf("GetUserReviews: %v", err)
 	}
@@ -197,9 +266,9 @@ func TestIntegrationPRWorkflow(t *testing.T) {
 		t.Fatalf("expected reviewer to have 1 assigned PR, got %d", len(reviews.PullRequests))
 	}
 
-	oldReviewer := pr.Reviewers[0]
+	oldReviewer := pr.PR.Reviewers[0]
 	reassignResp, err := prSvc.ReassignReviewer(ctx, &models.PRReassignRequest{
-		ID:            pr.ID,
+		ID:            pr.PR.ID,
 		OldReviewerID: oldReviewer,
 	})
 	if err != nil {
@@ -209,7 +278,7 @@ func TestIntegrationPRWorkflow(t *testing.T) {
 		t.Fatalf("expected reviewer to be replaced with a new teammate")
 	}
 
-	merged, err := prSvc.MergePR(ctx, &models.PRMergeRequest{ID: pr.ID})
+	merged, err := prSvc.MergePR(ctx, &models.PRMergeRequest{ID: pr.PR.ID})
 	if err != nil {
 		t.Fatalf("MergePR: %v", err)
 	}
@@ -224,7 +293,7 @@ func TestIntegrationPRWorkflow(t *testing.T) {
 	if err != nil {
 		t.Fatalf("GetAssignmentsStats: %v", err)
 	}
-	if len(stats.ByPR) != 1 || stats.ByPR[0].PullRequestID != pr.ID || stats.ByPR[0].Reviewers != 2 {
+	if len(stats.ByPR) != 1 || stats.ByPR[0].PullRequestID != pr.PR.ID || stats.ByPR[0].Reviewers != 2 {
 		t.Fatalf("unexpected PR stats: %#v", stats.ByPR)
 	}
 	totalAssignments := 0
@@ -235,3 +304,67 @@ func TestIntegrationPRWorkflow(t *testing.T) {
 		t.Fatalf("expected total assignments 2, got %d", totalAssignments)
 	}
 }
+
+func TestIntegrationReviewVerdictsKeepAssignmentOutcome(t *testing.T) {
+	pg, log := setupIntegrationDB(t)
+	teamSvc, _, prSvc := newServices(t, pg, log)
+	statsStorage, err := storage.NewStatsStorage(pg, log)
+	if err != nil {
+		t.Fatalf("stats storage: %v", err)
+	}
+
+	ctx := context.Background()
+	team := &models.Team{
+		Name: "payments",
+		Members: []*models.User{
+			{ID: "author", Username: "author", IsActive: true},
+			{ID: "reviewer1", Username: "reviewer1", IsActive: true},
+			{ID: "reviewer2", Username: "reviewer2", IsActive: true},
+		},
+	}
+	if _, err := teamSvc.CreateTeam(ctx, team); err != nil {
+		t.Fatalf("CreateTeam: %v", err)
+	}
+	from := time.Now().Add(-time.Hour)
+	pr, err := prSvc.CreatePR(ctx, &models.PRCreateRequest{ID: "pr-verdicts", Title: "refund flow", AuthorID: "author"})
+	if err != nil {
+		t.Fatalf("CreatePR: %v", err)
+	}
+	if len(pr.PR.Reviewers) != 2 {
+		t.Fatalf("expected 2 reviewers, got %d", len(pr.PR.Reviewers))
+	}
+
+	// Verdicts are recorded in the assignment history too, but must not end
+	// the assignment.
+	states := []string{models.ReviewStateChangesRequested, models.ReviewStateApproved}
+	for i, reviewer := range pr.PR.Reviewers {
+		if _, err := prSvc.ApproveReview(ctx, &models.PRApproveRequest{ID: pr.PR.ID, UserID: reviewer, State: states[i]}); err != nil {
+			t.Fatalf("ApproveReview %s: %v", states[i], err)
+		}
+	}
+	if _, err := prSvc.MergePR(ctx, &models.PRMergeRequest{ID: pr.PR.ID}); err != nil {
+		t.Fatalf("MergePR: %v", err)
+	}
+
+	for _, reviewer := range pr.PR.Reviewers {
+		history, err := prSvc.GetAssignmentHistory(ctx, models.AssignmentHistoryQuery{UserID: reviewer})
+		if err != nil {
+			t.Fatalf("GetAssignmentHistory: %v", err)
+		}
+		if len(history.Assignments) != 1 || history.Assignments[0].Outcome != models.OutcomeMerged {
+			t.Fatalf("expected a single merged assignment for %s, got %#v", reviewer, history.Assignments)
+		}
+	}
+	counts, err := statsStorage.GetCompletionCounts(ctx, "payments", from, time.Now().Add(time.Hour), nil)
+	if err != nil {
+		t.Fatalf("GetCompletionCounts: %v", err)
+	}
+	if len(counts) != 2 {
+		t.Fatalf("expected completion counts for both reviewers, got %d", len(counts))
+	}
+	for _, c := range counts {
+		if c.Assigned != 1 || c.Merged != 1 || c.Reassigned != 0 {
+			t.Fatalf("expected verdicts not to count as reassignments: %#v", c)
+		}
+	}
+}
@@ -0,0 +1,398 @@
+// Package alerting evaluates threshold rules over the service's own metrics
+// on a schedule, for deployments without Prometheus and Alertmanager.
+package alerting
+
+import (
+	"cmp"
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"maps"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/cloudyy74/pr-reviewer-service/internal/metrics"
+	"github.com/cloudyy74/pr-reviewer-service/internal/models"
+)
+
+// Rule compares every series of Metric whose labels include Labels with
+// Threshold. With a Window the increase of the series over the window is
+// compared instead of its value, as for counters. The condition has to hold
+// for For before the alert fires.
+type Rule struct {
+	Name      string
+	Metric    string
+	Labels    map[string]string
+	Op        string
+	Threshold float64
+	Window    time.Duration
+	For       time.Duration
+	Severity  string
+	Notify    []string
+}
+
+// Source reads the current samples of some metrics.
+type Source func(ctx context.Context) ([]metrics.Sample, error)
+
+// Notifier delivers firing and resolved alerts to users.
+type Notifier interface {
+	SendAlert(ctx context.Context, alert models.Alert, userIDs []string) error
+}
+
+var ops = map[string]func(a, b float64) bool{
+	">":  func(a, b float64) bool { return a > b },
+	">=": func(a, b float64) bool { return a >= b },
+	"<":  func(a, b float64) bool { return a < b },
+	"<=": func(a, b float64) bool { return a <= b },
+}
+
+type point struct {
+	at    time.Time
+	value float64
+}
+
+type state struct {
+	rule     *Rule
+	alert    models.Alert
+	notified bool
+}
+
+// Engine keeps the state of every rule between evaluations. A firing alert
+// is sent once when it starts firing and once when it resolves; a failed
+// delivery is retried on the next evaluation.
+type Engine struct {
+	rules     []Rule
+	sources   []Source
+	notifier  Notifier
+	log       *slog.Logger
+	now       func() time.Time
+	maxWindow time.Duration
+
+	mu       sync.Mutex
+	lastEval time.Time
+	history  map[string][]point
+	states   map[string]*state
+}
+
+func NewEngine(rules []Rule, sources []Source, notifier Notifier, log *slog.Logger) (*Engine, error) {
+	if log == nil {
+		return nil, errors.New("logger cannot be nil")
+	}
+	if len(sources) == 0 {
+		return nil, errors.New("alerting needs at least one metric source")
+	}
+	e := &Engine{
+		rules:    make([]Rule, 0, len(rules)),
+		sources:  sources,
+		notifier: notifier,
+		log:      log,
+		now:      time.Now,
+		history:  make(map[string][]point),
+		states:   make(map[string]*state),
+	}
+	names := make(map[string]struct{}, len(rules))
+	for _, r := range rules {
+		r.Name = strings.TrimSpace(r.Name)
+		r.Metric = strings.TrimSpace(r.Metric)
+		if r.Name == "" || r.Metric == "" {
+			return nil, errors.New("alert rule needs name and metric")
+		}
+		if _, dup := names[r.Name]; dup {
+			return nil, fmt.Errorf("alert rule %s is defined twice", r.Name)
+		}
+		names[r.Name] = struct{}{}
+		if _, ok := ops[r.Op]; !ok {
+			return nil, fmt.Errorf("alert rule %s: unknown op %q, use >, >=, < or <=", r.Name, r.Op)
+		}
+		if r.Window < 0 || r.For < 0 {
+			return nil, fmt.Errorf("alert rule %s: window and for cannot be negative", r.Name)
+		}
+		if len(r.Notify) > 0 && notifier == nil {
+			return nil, fmt.Errorf("alert rule %s has recipients but there is no notifier", r.Name)
+		}
+		e.maxWindow = max(e.maxWindow, r.Window)
+		e.rules = append(e.rules, r)
+	}
+	return e, nil
+}
+
+// RegistrySource reads the counters of a metrics registry.
+func RegistrySource(r *metrics.Registry) Source {
+	return func(context.Context) ([]metrics.Sample, error) {
+		return r.Gather(), nil
+	}
+}
+
+// OpenPRsMetric is the number of open pull requests by the team of their
+// author. It is read from the database on every evaluation and is only
+// available to rules, not on /metrics.
+const OpenPRsMetric = "pr_reviewer_open_prs"
+
+type OpenPRCounter interface {
+	GetOpenPRsByTeam(ctx context.Context) (map[string]int, error)
+}
+
+func OpenPRsSource(counter OpenPRCounter) Source {
+	return func(ctx context.Context) ([]metrics.Sample, error) {
+		counts, err := counter.GetOpenPRsByTeam(ctx)
+		if err != nil {
+			return nil, err
+		}
+		samples := make([]metrics.Sample, 0, len(counts))
+		for team, n := range counts {
+			samples = append(samples, metrics.Sample{Name: OpenPRsMetric, Labels: map[string]string{"team": team}, Value: float64(n)})
+		}
+		return samples, nil
+	}
+}
+
+// Evaluate reads all sources, updates the state of every rule and sends the
+// alerts that started firing or resolved. It returns the number of alerts
+// delivered.
+func (e *Engine) Evaluate(ctx context.Context) (int, error) {
+	var samples []metrics.Sample
+	for _, source := range e.sources {
+		s, err := source(ctx)
+		if err != nil {
+			return 0, fmt.Errorf("read metrics: %w", err)
+		}
+		samples = append(samples, s...)
+	}
+
+	e.mu.Lock()
+	now := e.now()
+	e.record(samples, now)
+	seen := make(map[string]struct{})
+	for i := range e.rules {
+		rule := &e.rules[i]
+		for _, s := range samples {
+			if s.Name != rule.Metric || !matches(s.Labels, rule.Labels) {
+				continue
+			}
+			key := seriesKey(s.Name, s.Labels)
+			value := s.Value
+			if rule.Window > 0 {
+				value = e.increase(key, s.Value, now.Add(-rule.Window))
+			}
+			stateKey := rule.Name + "\xff" + key
+			seen[stateKey] = struct{}{}
+			e.update(stateKey, rule, s.Labels, value, now)
+		}
+	}
+	for key, st := range e.states {
+		if _, ok := seen[key]; !ok {
+			e.clear(key, st, st.alert.Value, now)
+		}
+	}
+	pending := e.unsent()
+	e.lastEval = now
+	e.mu.Unlock()
+
+	var (
+		sent int
+		errs []error
+	)
+	for key, st := range pending {
+		if len(st.rule.Notify) > 0 {
+			if err := e.notifier.SendAlert(ctx, st.alert, st.rule.Notify); err != nil {
+				errs = append(errs, err)
+				continue
+			}
+			sent++
+		}
+		e.markSent(key, st.alert.State)
+	}
+	return sent, errors.Join(errs...)
+}
+
+// Alerts lists the pending and firing alerts.
+func (e *Engine) Alerts() []*models.Alert {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	alerts := make([]*models.Alert, 0, len(e.states))
+	for _, st := range e.states {
+		if st.alert.State == models.AlertResolved {
+			continue
+		}
+		alert := st.alert
+		alerts = append(alerts, &alert)
+	}
+	slices.SortFunc(alerts, func(a, b *models.Alert) int {
+		return cmp.Or(cmp.Compare(a.Rule, b.Rule), cmp.Compare(a.Expr, b.Expr), cmp.Compare(labelString(a.Labels), labelString(b.Labels)))
+	})
+	return alerts
+}
+
+// record appends the samples to the history of their series and drops points
+// no rule window reaches any more. A series that appears after the first
+// evaluation is taken to have been zero at the previous one, so the first
+// increments of a counter are not lost.
+func (e *Engine) record(samples []metrics.Sample, now time.Time) {
+	if e.maxWindow == 0 {
+		return
+	}
+	cutoff := now.Add(-e.maxWindow)
+	for _, s := range samples {
+		key := seriesKey(s.Name, s.Labels)
+		points := e.history[key]
+		if len(points) == 0 && !e.lastEval.IsZero() {
+			points = append(points, point{at: e.lastEval})
+		}
+		points = append(points, point{at: now, value: s.Value})
+		drop := 0
+		for drop+1 < len(points) && !points[drop+1].at.After(cutoff) {
+			drop++
+		}
+		e.history[key] = points[drop:]
+	}
+	for key, points := range e.history {
+		if points[len(points)-1].at.Before(cutoff) {
+			delete(e.history, key)
+		}
+	}
+}
+
+// increase is the growth of the series since the last point at or before
+// from, or since its first point when it is younger than the window. A value
+// below the baseline means the counter was reset, so all of it is new.
+func (e *Engine) increase(key string, current float64, from time.Time) float64 {
+	points := e.history[key]
+	if len(points) == 0 {
+		return 0
+	}
+	base := points[0]
+	for _, p := range points[1:] {
+		if p.at.After(from) {
+			break
+		}
+		base = p
+	}
+	if current < base.value {
+		return current
+	}
+	return current - base.value
+}
+
+func (e *Engine) update(key string, rule *Rule, labels map[string]string, value float64, now time.Time) {
+	st := e.states[key]
+	if !ops[rule.Op](value, rule.Threshold) {
+		if st != nil {
+			e.clear(key, st, value, now)
+		}
+		return
+	}
+
+	if st == nil || st.alert.State == models.AlertResolved {
+		st = &state{
+			rule: rule,
+			alert: models.Alert{
+				Rule:      rule.Name,
+				Severity:  rule.Severity,
+				State:     models.AlertPending,
+				Expr:      expr(rule, labels),
+				Labels:    labels,
+				Threshold: rule.Threshold,
+				Since:     now,
+			},
+			notified: true,
+		}
+		e.states[key] = st
+	}
+	st.alert.Value = value
+	if st.alert.State == models.AlertPending && !now.Before(st.alert.Since.Add(rule.For)) {
+		st.alert.State = models.AlertFiring
+		st.notified = false
+		e.log.Warn("alert firing",
+			slog.String("rule", rule.Name),
+			slog.String("expr", st.alert.Expr),
+			slog.Float64("value", value),
+		)
+	}
+}
+
+// clear resolves a firing alert whose condition no longer holds or whose
+// series is gone, and forgets a pending one.
+func (e *Engine) clear(key string, st *state, value float64, now time.Time) {
+	switch st.alert.State {
+	case models.AlertFiring:
+		st.alert.State = models.AlertResolved
+		st.alert.Value = value
+		st.alert.ResolvedAt = &now
+		st.notified = false
+		e.log.Info("alert resolved", slog.String("rule", st.rule.Name), slog.String("expr", st.alert.Expr))
+	case models.AlertPending:
+		delete(e.states, key)
+	}
+}
+
+func (e *Engine) unsent() map[string]state {
+	unsent := make(map[string]state)
+	for key, st := range e.states {
+		if !st.notified {
+			unsent[key] = *st
+		}
+	}
+	return unsent
+}
+
+// markSent records a delivery unless the alert changed state meanwhile.
+func (e *Engine) markSent(key, sentState string) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	st := e.states[key]
+	if st == nil || st.alert.State != sentState {
+		return
+	}
+	st.notified = true
+	if st.alert.State == models.AlertResolved {
+		delete(e.states, key)
+	}
+}
+
+func matches(labels, selector map[string]string) bool {
+	for k, v := range selector {
+		if labels[k] != v {
+			return false
+		}
+	}
+	return true
+}
+
+func seriesKey(name string, labels map[string]string) string {
+	return name + labelString(labels)
+}
+
+func labelString(labels map[string]string) string {
+	if len(labels) == 0 {
+		return ""
+	}
+	parts := make([]string, 0, len(labels))
+	for _, k := range slices.Sorted(maps.Keys(labels)) {
+		parts = append(parts, k+"="+strconv.Quote(labels[k]))
+	}
+	return "{" + strings.Join(parts, ",") + "}"
+}
+
+// expr renders the rule for one series the way PromQL would write it.
+func expr(rule *Rule, labels map[string]string) string {
+	series := seriesKey(rule.Metric, labels)
+	if rule.Window > 0 {
+		series = fmt.Sprintf("increase(%s[%s])", series, promDuration(rule.Window))
+	}
+	return fmt.Sprintf("%s %s %s", series, rule.Op, strconv.FormatFloat(rule.Threshold, 'f', -1, 64))
+}
+
+func promDuration(d time.Duration) string {
+	switch {
+	case d%time.Hour == 0:
+		return fmt.Sprintf("%dh", d/time.Hour)
+	case d%time.Minute == 0:
+		return fmt.Sprintf("%dm", d/time.Minute)
+	default:
+		return fmt.Sprintf("%ds", d/time.Second)
+	}
+}
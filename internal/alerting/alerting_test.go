@@ -0,0 +1,158 @@
+package alerting
+
+import (
+	"context"
+	"errors"
+	"io"
+	"log/slog"
+	"testing"
+	"time"
+
+	"github.com/cloudyy74/pr-reviewer-service/internal/metrics"
+	"github.com/cloudyy74/pr-reviewer-service/internal/models"
+)
+
+type fakeNotifier struct {
+	sent []models.Alert
+	to   [][]string
+	err  error
+}
+
+func (f *fakeNotifier) SendAlert(_ context.Context, alert models.Alert, userIDs []string) error {
+	if f.err != nil {
+		return f.err
+	}
+	f.sent = append(f.sent, alert)
+	f.to = append(f.to, userIDs)
+	return nil
+}
+
+type fakeOpenPRs map[string]int
+
+func (f fakeOpenPRs) GetOpenPRsByTeam(context.Context) (map[string]int, error) {
+	return f, nil
+}
+
+func newTestEngine(t *testing.T, rules []Rule, notifier Notifier, sources ...Source) (*Engine, *time.Time) {
+	t.Helper()
+	e, err := NewEngine(rules, sources, notifier, slog.New(slog.NewTextHandler(io.Discard, nil)))
+	if err != nil {
+		t.Fatalf("NewEngine returned err: %v", err)
+	}
+	now := time.Date(2025, 3, 1, 10, 0, 0, 0, time.UTC)
+	e.now = func() time.Time { return now }
+	return e, &now
+}
+
+func TestEngine_CounterIncreaseFiresAndResolves(t *testing.T) {
+	reg := metrics.NewRegistry()
+	errorsTotal := metrics.NewCounterVec("errors_total", "Errors.", "code")
+	reg.Register(errorsTotal)
+	errorsTotal.Inc("PR_EXISTS")
+
+	notifier := &fakeNotifier{}
+	rules := []Rule{{
+		Name:      "no-candidates",
+		Metric:    "errors_total",
+		Labels:    map[string]string{"code": "NO_CANDIDATE"},
+		Op:        ">",
+		Threshold: 2,
+		Window:    15 * time.Minute,
+		Severity:  "warning",
+		Notify:    []string{"lead"},
+	}}
+	e, now := newTestEngine(t, rules, notifier, RegistrySource(reg))
+	ctx := context.Background()
+
+	if sent, err := e.Evaluate(ctx); err != nil || sent != 0 {
+		t.Fatalf("expected nothing on the first evaluation, got %d, %v", sent, err)
+	}
+	for range 3 {
+		errorsTotal.Inc("NO_CANDIDATE")
+	}
+	*now = now.Add(time.Minute)
+	if sent, err := e.Evaluate(ctx); err != nil || sent != 1 {
+		t.Fatalf("expected the alert to fire, got %d, %v", sent, err)
+	}
+	fired := notifier.sent[0]
+	if fired.State != models.AlertFiring || fired.Value != 3 || fired.Expr != `increase(errors_total{code="NO_CANDIDATE"}[15m]) > 2` || notifier.to[0][0] != "lead" {
+		t.Fatalf("unexpected firing alert %+v to %v", fired, notifier.to[0])
+	}
+	if alerts := e.Alerts(); len(alerts) != 1 || alerts[0].Severity != "warning" {
+		t.Fatalf("expected one active alert, got %+v", alerts)
+	}
+
+	*now = now.Add(5 * time.Minute)
+	if sent, _ := e.Evaluate(ctx); sent != 0 {
+		t.Fatalf("a firing alert must be sent once, got %d more", sent)
+	}
+
+	*now = now.Add(15 * time.Minute)
+	if sent, err := e.Evaluate(ctx); err != nil || sent != 1 {
+		t.Fatalf("expected the alert to resolve, got %d, %v", sent, err)
+	}
+	if resolved := notifier.sent[1]; resolved.State != models.AlertResolved || resolved.ResolvedAt == nil || resolved.Value != 0 {
+		t.Fatalf("unexpected resolved alert %+v", resolved)
+	}
+	if alerts := e.Alerts(); len(alerts) != 0 {
+		t.Fatalf("expected no active alerts, got %+v", alerts)
+	}
+}
+
+func TestEngine_ForDelaysFiringAndRetriesDelivery(t *testing.T) {
+	notifier := &fakeNotifier{err: errors.New("smtp down")}
+	open := fakeOpenPRs{"backend": 12, "mobile": 2}
+	rules := []Rule{{
+		Name:      "too-many-open-prs",
+		Metric:    OpenPRsMetric,
+		Op:        ">=",
+		Threshold: 10,
+		For:       10 * time.Minute,
+		Notify:    []string{"lead"},
+	}}
+	e, now := newTestEngine(t, rules, notifier, OpenPRsSource(open))
+	ctx := context.Background()
+
+	if sent, err := e.Evaluate(ctx); err != nil || sent != 0 {
+		t.Fatalf("expected a pending alert only, got %d, %v", sent, err)
+	}
+	alerts := e.Alerts()
+	if len(alerts) != 1 || alerts[0].State != models.AlertPending || alerts[0].Labels["team"] != "backend" {
+		t.Fatalf("expected backend to be pending, got %+v", alerts)
+	}
+
+	*now = now.Add(10 * time.Minute)
+	if _, err := e.Evaluate(ctx); err == nil {
+		t.Fatalf("expected the delivery error")
+	}
+	notifier.err = nil
+	*now = now.Add(time.Minute)
+	if sent, err := e.Evaluate(ctx); err != nil || sent != 1 {
+		t.Fatalf("expected the failed delivery to be retried, got %d, %v", sent, err)
+	}
+	if notifier.sent[0].State != models.AlertFiring || notifier.sent[0].Since != now.Add(-11*time.Minute) {
+		t.Fatalf("unexpected alert %+v", notifier.sent[0])
+	}
+
+	delete(open, "backend")
+	*now = now.Add(time.Minute)
+	if sent, err := e.Evaluate(ctx); err != nil || sent != 1 || notifier.sent[1].State != models.AlertResolved {
+		t.Fatalf("expected a vanished series to resolve, got %d, %v", sent, err)
+	}
+}
+
+func TestNewEngine_ValidatesRules(t *testing.T) {
+	log := slog.New(slog.NewTextHandler(io.Discard, nil))
+	source := OpenPRsSource(fakeOpenPRs{})
+	for name, rules := range map[string][]Rule{
+		"unknown op":   {{Name: "a", Metric: "m", Op: "!="}},
+		"no metric":    {{Name: "a", Op: ">"}},
+		"duplicate":    {{Name: "a", Metric: "m", Op: ">"}, {Name: "a", Metric: "m", Op: "<"}},
+		"no notifier":  {{Name: "a", Metric: "m", Op: ">", Notify: []string{"u1"}}},
+		"negative for": {{Name: "a", Metric: "m", Op: ">", For: -time.Second}},
+	} {
+		if _, err := NewEngine(rules, []Source{source}, nil, log); err == nil {
+			t.Fatalf("%s: expected error", name)
+		}
+	}
+}
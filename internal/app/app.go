@@ -2,27 +2,63 @@ package app
 
 import (
 	"context"
+	"encoding/base64"
 	"errors"
 	"fmt"
 	"log/slog"
 	"net"
 	"net/http"
+	"regexp"
+	"strings"
+	"time"
 
+	"github.com/cloudyy74/pr-reviewer-service/internal/alerting"
+	"github.com/cloudyy74/pr-reviewer-service/internal/chaos"
 	"github.com/cloudyy74/pr-reviewer-service/internal/config"
+	"github.com/cloudyy74/pr-reviewer-service/internal/deprecation"
+	"github.com/cloudyy74/pr-reviewer-service/internal/drain"
 	router "github.com/cloudyy74/pr-reviewer-service/internal/http"
+	"github.com/cloudyy74/pr-reviewer-service/internal/hub"
+	"github.com/cloudyy74/pr-reviewer-service/internal/integration"
+	"github.com/cloudyy74/pr-reviewer-service/internal/jira"
+	"github.com/cloudyy74/pr-reviewer-service/internal/metrics"
+	"github.com/cloudyy74/pr-reviewer-service/internal/notify"
+	"github.com/cloudyy74/pr-reviewer-service/internal/oidc"
+	"github.com/cloudyy74/pr-reviewer-service/internal/piilog"
+	"github.com/cloudyy74/pr-reviewer-service/internal/reqsign"
+	"github.com/cloudyy74/pr-reviewer-service/internal/scheduler"
+	"github.com/cloudyy74/pr-reviewer-service/internal/secrets"
 	"github.com/cloudyy74/pr-reviewer-service/internal/service"
+	"github.com/cloudyy74/pr-reviewer-service/internal/shedding"
 	"github.com/cloudyy74/pr-reviewer-service/internal/storage"
+	"github.com/cloudyy74/pr-reviewer-service/internal/streamtoken"
 	"github.com/cloudyy74/pr-reviewer-service/pkg/postgres"
 )
 
 const (
-	defaultAddr = "localhost:8080"
+	defaultAddr                 = "localhost:8080"
+	defaultAckCheckInterval     = 5 * time.Minute
+	defaultAnomalyCheckInterval = time.Hour
+	defaultShedSampleInterval   = time.Second
+	defaultShedPoolWait         = 50 * time.Millisecond
+	defaultOutboxRelayInterval  = time.Second
+	defaultDigestFlushInterval  = 30 * time.Second
+	defaultAlertEvalInterval    = time.Minute
+	defaultBackfillInterval     = 30 * time.Second
+	defaultStreamTokenTTL       = 5 * time.Minute
+	defaultCalendarTokenTTL     = 365 * 24 * time.Hour
+	defaultSessionTTL           = 12 * time.Hour
+	defaultSessionCookie        = "pr_reviewer_session"
+	sessionCleanupInterval      = time.Hour
+	nonceCleanupInterval        = 10 * time.Minute
 )
 
 type App struct {
 	httpServer *http.Server
 	addr       string
 	database   *postgres.Postgres
+	scheduler  *scheduler.Scheduler
+	drainer    *drain.Controller
 	log        *slog.Logger
 }
 
@@ -35,10 +71,36 @@ func NewApp(cfg *config.Config, log *slog.Logger) (*App, error) {
 	}
 
 	ctx := context.Background()
-	database, err := postgres.New(ctx, cfg.DBURL, log)
+	resolver, err := newSecretResolver(ctx, cfg.Secrets.Vault)
+	if err != nil {
+		return nil, err
+	}
+	if log, err = newPIILogger(ctx, resolver, cfg.Logging.PII, log); err != nil {
+		return nil, err
+	}
+	secretWatcher, err := secrets.NewWatcher(log)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create secret watcher: %w", err)
+	}
+	dbURL, err := resolver.Load(ctx, cfg.DBURL)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load database url: %w", err)
+	}
+	database, err := postgres.New(ctx, dbURL.Value(), log, postgres.ReloadCredentials(func(ctx context.Context) (string, error) {
+		if _, err := dbURL.Refresh(ctx); err != nil {
+			return "", err
+		}
+		return dbURL.Value(), nil
+	}))
 	if err != nil {
 		return nil, fmt.Errorf("failed to create database: %w", err)
 	}
+	dbURL.OnChange(func(value string) {
+		if err := database.UpdateURL(value); err != nil {
+			log.Error("failed to apply new database url", slog.Any("error", err))
+		}
+	})
+	secretWatcher.Watch("db_url", dbURL)
 
 	teamStorage, err := storage.NewTeamStorage(database, log)
 	if err != nil {
@@ -52,23 +114,310 @@ func NewApp(cfg *config.Config, log *slog.Logger) (*App, error) {
 	if err != nil {
 		return nil, fmt.Errorf("failed to create pr storage: %w", err)
 	}
+	statsStorage, err := storage.NewStatsStorage(database, log)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create stats storage: %w", err)
+	}
+	snapshotStorage, err := storage.NewSnapshotStorage(database, log)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create snapshot storage: %w", err)
+	}
+	schemaStorage, err := storage.NewSchemaStorage(database, log)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create schema storage: %w", err)
+	}
 	txManager, err := storage.NewTxManager(database, log)
 	if err != nil {
 		return nil, fmt.Errorf("failed to create tx manager: %w", err)
 	}
 
-	teamService, err := service.NewTeamService(txManager, teamStorage, userStorage, log)
+	var outboxStorage *storage.OutboxStorage
+	if cfg.Outbox.Enabled {
+		if outboxStorage, err = storage.NewOutboxStorage(database, log); err != nil {
+			return nil, fmt.Errorf("failed to create outbox storage: %w", err)
+		}
+	}
+
+	teamOpts := []service.TeamOption{service.WithTeamNameNormalization(cfg.Teams.NameNormalization)}
+	if cfg.Users.UniqueUsernames {
+		teamOpts = append(teamOpts, service.WithUniqueUsernames())
+	}
+	var userOpts []service.UserOption
+	if outboxStorage != nil {
+		teamOpts = append(teamOpts, service.WithTeamOutbox(outboxStorage))
+		userOpts = append(userOpts, service.WithUserOutbox(outboxStorage))
+	}
+	teamService, err := service.NewTeamService(
+		txManager,
+		teamStorage,
+		userStorage,
+		log,
+		teamOpts...,
+	)
 	if err != nil {
 		return nil, fmt.Errorf("failed to create team service: %w", err)
 	}
-	userService, err := service.NewUserService(txManager, userStorage, log)
+	userService, err := service.NewUserService(txManager, userStorage, log, userOpts...)
 	if err != nil {
 		return nil, fmt.Errorf("failed to create user service: %w", err)
 	}
-	prService, err := service.NewPRService(txManager, prStorage, userStorage, log)
+	var notifier service.AssignmentNotifier = chaos.WrapNotifier(hub.NewAssignmentHub())
+	var (
+		dispatcher          *notify.Dispatcher
+		notificationService *service.NotificationService
+	)
+	if cfg.Notifications.Digests {
+		notificationStorage, err := storage.NewNotificationStorage(database, log)
+		if err != nil {
+			return nil, fmt.Errorf("failed to create notification storage: %w", err)
+		}
+		if notificationService, err = service.NewNotificationService(notificationStorage, log); err != nil {
+			return nil, fmt.Errorf("failed to create notification service: %w", err)
+		}
+		sender := notify.Track(notify.NewLogSender(log), "log", notificationStorage, log)
+		if dispatcher, err = notify.NewDispatcher(notifier, sender, userService, log); err != nil {
+			return nil, fmt.Errorf("failed to create notification dispatcher: %w", err)
+		}
+		notifier = dispatcher
+	}
+	prOpts := []service.PROption{
+		service.WithAssignmentNotifier(notifier),
+		service.WithTransferCapacity(cfg.Stats.ReviewerCapacity),
+	}
+	if cfg.PullRequests.DuplicateCheck {
+		prOpts = append(prOpts, service.WithDuplicateCheck(cfg.PullRequests.DuplicateThreshold))
+	}
+	var idPattern *regexp.Regexp
+	if cfg.PullRequests.IDPattern != "" {
+		if idPattern, err = regexp.Compile(cfg.PullRequests.IDPattern); err != nil {
+			return nil, fmt.Errorf("invalid pull request id pattern: %w", err)
+		}
+	}
+	prOpts = append(prOpts, service.WithPRIDPolicy(cfg.PullRequests.IDMaxLength, idPattern))
+	switch cfg.PullRequests.ReviewerStrategy {
+	case "", service.ReviewerStrategyRandom, service.ReviewerStrategyLoadBalanced, service.ReviewerStrategyRoundRobin,
+		service.ReviewerStrategyWorkingHours:
+		prOpts = append(prOpts, service.WithReviewerStrategy(cfg.PullRequests.ReviewerStrategy))
+	default:
+		return nil, fmt.Errorf("unknown pull_requests.reviewer_strategy %q", cfg.PullRequests.ReviewerStrategy)
+	}
+	if cfg.PullRequests.RecentReviewerPRs > 0 {
+		prOpts = append(prOpts, service.WithRecentReviewerMemory(cfg.PullRequests.RecentReviewerPRs))
+	}
+	if cfg.PullRequests.GenerateIDs {
+		prOpts = append(prOpts, service.WithGeneratedPRIDs())
+	}
+	if cfg.PullRequests.RequireApprovals {
+		prOpts = append(prOpts, service.WithRequiredApprovals())
+	}
+	if outboxStorage != nil {
+		prOpts = append(prOpts, service.WithOutbox(outboxStorage))
+	}
+	prService, err := service.NewPRService(txManager, prStorage, userStorage, teamStorage, log, prOpts...)
 	if err != nil {
 		return nil, fmt.Errorf("failed to create pr service: %w", err)
 	}
+	// The notification dispatcher needs the user service before the PR
+	// service exists, so the reassigner is attached afterwards.
+	service.WithReviewReassigner(prService)(userService)
+
+	statsService, err := service.NewStatsService(
+		statsStorage,
+		log,
+		service.WithReviewerCapacity(cfg.Stats.ReviewerCapacity),
+		service.WithAnomalyThreshold(cfg.Stats.AnomalyShareThreshold, cfg.Stats.AnomalyMinAssignments),
+	)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create stats service: %w", err)
+	}
+
+	schemaService, err := service.NewSchemaService(schemaStorage, log)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create schema service: %w", err)
+	}
+	report, err := schemaService.Check(ctx)
+	if err != nil {
+		log.Error("failed to check database schema", slog.Any("error", err))
+	} else if !report.OK {
+		log.Error(
+			"database schema does not match migrations",
+			slog.Int("expected_version", report.ExpectedVersion),
+			slog.Int("current_version", report.CurrentVersion),
+			slog.Any("problems", report.Problems),
+		)
+	}
+
+	jobs, err := scheduler.New(log)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create scheduler: %w", err)
+	}
+	ackCheckInterval := cfg.Scheduler.AckCheckInterval
+	if ackCheckInterval <= 0 {
+		ackCheckInterval = defaultAckCheckInterval
+	}
+	if err := jobs.Add("reassign-unacknowledged", ackCheckInterval, func(ctx context.Context) error {
+		_, err := prService.ReassignExpiredAcknowledgements(ctx)
+		return err
+	}); err != nil {
+		return nil, fmt.Errorf("failed to schedule ack check: %w", err)
+	}
+	anomalyCheckInterval := cfg.Scheduler.AnomalyCheckInterval
+	if anomalyCheckInterval <= 0 {
+		anomalyCheckInterval = defaultAnomalyCheckInterval
+	}
+	if err := jobs.Add("detect-assignment-anomalies", anomalyCheckInterval, func(ctx context.Context) error {
+		_, err := statsService.DetectAnomalies(ctx)
+		return err
+	}); err != nil {
+		return nil, fmt.Errorf("failed to schedule anomaly check: %w", err)
+	}
+
+	migrationService, err := newOnlineMigrationService(
+		database,
+		[]statusMigrationTarget{prStorage, statsStorage, teamStorage, userStorage, snapshotStorage},
+		cfg.OnlineMigrations,
+		log,
+	)
+	if err != nil {
+		return nil, err
+	}
+	backfillInterval := cfg.OnlineMigrations.BackfillInterval
+	if backfillInterval <= 0 {
+		backfillInterval = defaultBackfillInterval
+	}
+	if err := jobs.Add("online-migrations-backfill", backfillInterval, migrationService.Run); err != nil {
+		return nil, fmt.Errorf("failed to schedule online migration backfill: %w", err)
+	}
+
+	var shedder *shedding.Shedder
+	if cfg.LoadShedding.Enabled {
+		poolWait := cfg.LoadShedding.PoolWaitThreshold
+		if poolWait <= 0 {
+			poolWait = defaultShedPoolWait
+		}
+		shedder, err = shedding.New(database.DB.Stats, poolWait, log)
+		if err != nil {
+			return nil, fmt.Errorf("failed to create load shedder: %w", err)
+		}
+		sampleInterval := cfg.LoadShedding.SampleInterval
+		if sampleInterval <= 0 {
+			sampleInterval = defaultShedSampleInterval
+		}
+		if err := jobs.Add("sample-db-pool", sampleInterval, func(context.Context) error {
+			shedder.Sample()
+			return nil
+		}); err != nil {
+			return nil, fmt.Errorf("failed to schedule pool sampling: %w", err)
+		}
+	}
+
+	if dispatcher != nil {
+		flushInterval := cfg.Notifications.FlushInterval
+		if flushInterval <= 0 {
+			flushInterval = defaultDigestFlushInterval
+		}
+		if err := jobs.Add("flush-notifications", flushInterval, func(ctx context.Context) error {
+			_, err := dispatcher.Flush(ctx)
+			return err
+		}); err != nil {
+			return nil, fmt.Errorf("failed to schedule notification flush: %w", err)
+		}
+	}
+
+	var alertEngine *alerting.Engine
+	if len(cfg.Alerts.Rules) > 0 {
+		rules := make([]alerting.Rule, 0, len(cfg.Alerts.Rules))
+		for _, r := range cfg.Alerts.Rules {
+			if len(r.Notify) > 0 && dispatcher == nil {
+				return nil, fmt.Errorf("alert rule %s has notify recipients, which requires notifications.digests", r.Name)
+			}
+			rules = append(rules, alerting.Rule(r))
+		}
+		var alertNotifier alerting.Notifier
+		if dispatcher != nil {
+			alertNotifier = dispatcher
+		}
+		alertEngine, err = alerting.NewEngine(
+			rules,
+			[]alerting.Source{alerting.RegistrySource(metrics.Default), alerting.OpenPRsSource(statsStorage)},
+			alertNotifier,
+			log,
+		)
+		if err != nil {
+			return nil, fmt.Errorf("failed to create alert engine: %w", err)
+		}
+		evalInterval := cfg.Alerts.EvalInterval
+		if evalInterval <= 0 {
+			evalInterval = defaultAlertEvalInterval
+		}
+		if err := jobs.Add("evaluate-alerts", evalInterval, func(ctx context.Context) error {
+			_, err := alertEngine.Evaluate(ctx)
+			return err
+		}); err != nil {
+			return nil, fmt.Errorf("failed to schedule alert evaluation: %w", err)
+		}
+	}
+
+	integrations, err := integration.NewRegistry(userStorage, log)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create integration registry: %w", err)
+	}
+	if cfg.Jira.Enabled {
+		if outboxStorage == nil {
+			return nil, errors.New("jira.enabled requires outbox.enabled")
+		}
+		jiraToken, err := resolver.Load(ctx, cfg.Jira.Token)
+		if err != nil {
+			return nil, fmt.Errorf("failed to load jira token: %w", err)
+		}
+		jiraTasks, err := storage.NewJiraTaskStorage(database, log)
+		if err != nil {
+			return nil, fmt.Errorf("failed to create jira task storage: %w", err)
+		}
+		jiraTeams := make([]jira.Team, 0, len(cfg.Jira.Teams))
+		for _, t := range cfg.Jira.Teams {
+			jiraTeams = append(jiraTeams, jira.Team(t))
+		}
+		jiraConnector, err := jira.NewConnector(
+			jira.Config{BaseURL: cfg.Jira.BaseURL, User: cfg.Jira.User, Token: jiraToken.Value(), Teams: jiraTeams},
+			jiraTasks,
+			&http.Client{Timeout: cfg.Jira.Timeout},
+			log,
+		)
+		if err != nil {
+			return nil, fmt.Errorf("failed to create jira connector: %w", err)
+		}
+		if err := integrations.Register(jiraConnector, jiraConnector.TeamNames()...); err != nil {
+			return nil, fmt.Errorf("failed to register jira connector: %w", err)
+		}
+	}
+	var outboxPublisher service.OutboxPublisher
+	if integrations.Len() > 0 {
+		outboxPublisher = integrations
+	}
+	var outboxRelay *service.OutboxRelay
+	if outboxStorage != nil {
+		outboxRelay, err = service.NewOutboxRelay(
+			outboxStorage,
+			outboxPublisher,
+			log,
+			service.WithOutboxBatching(cfg.Outbox.BatchSize, cfg.Outbox.Workers),
+			service.WithOutboxLease(cfg.Outbox.Lease),
+		)
+		if err != nil {
+			return nil, fmt.Errorf("failed to create outbox relay: %w", err)
+		}
+		relayInterval := cfg.Outbox.RelayInterval
+		if relayInterval <= 0 {
+			relayInterval = defaultOutboxRelayInterval
+		}
+		if err := jobs.Add("relay-outbox", relayInterval, func(ctx context.Context) error {
+			_, err := outboxRelay.RelayBatch(ctx)
+			return err
+		}); err != nil {
+			return nil, fmt.Errorf("failed to schedule outbox relay: %w", err)
+		}
+	}
 
 	_, port, err := net.SplitHostPort(cfg.Addr)
 	if err != nil {
@@ -76,12 +425,287 @@ func NewApp(cfg *config.Config, log *slog.Logger) (*App, error) {
 	}
 
 	mux := http.NewServeMux()
-	if err := router.SetupRouter(mux, port, teamService, userService, prService, log); err != nil {
+	if err := router.SetupRouter(mux, port, teamService, userService, prService, statsService, schemaService, log); err != nil {
 		return nil, fmt.Errorf("failed to create router: %w", err)
 	}
+	if err := router.SetupMetricsRoutes(mux, metrics.Default, log); err != nil {
+		return nil, fmt.Errorf("failed to register metrics routes: %w", err)
+	}
+	if chaos.Enabled && cfg.Env != "prod" {
+		if err := router.SetupFaultRoutes(mux, chaos.Controller{}, log); err != nil {
+			return nil, fmt.Errorf("failed to register fault routes: %w", err)
+		}
+		log.Warn("fault injection endpoints are enabled", slog.String("env", cfg.Env))
+	}
+	if notificationService != nil {
+		if err := router.SetupNotificationRoutes(mux, notificationService, log); err != nil {
+			return nil, fmt.Errorf("failed to register notification routes: %w", err)
+		}
+	}
+	if outboxRelay != nil {
+		if err := router.SetupOutboxRoutes(mux, outboxRelay, log); err != nil {
+			return nil, fmt.Errorf("failed to register outbox routes: %w", err)
+		}
+	}
+	if outboxStorage != nil {
+		eventLog, err := service.NewEventLog(outboxStorage, log)
+		if err != nil {
+			return nil, fmt.Errorf("failed to create event log: %w", err)
+		}
+		if err := router.SetupEventRoutes(mux, eventLog, log); err != nil {
+			return nil, fmt.Errorf("failed to register event routes: %w", err)
+		}
+	}
+	if alertEngine != nil {
+		if err := router.SetupAlertRoutes(mux, alertEngine, log); err != nil {
+			return nil, fmt.Errorf("failed to register alert routes: %w", err)
+		}
+	}
+	if err := router.SetupMigrationRoutes(mux, migrationService, log); err != nil {
+		return nil, fmt.Errorf("failed to register migration routes: %w", err)
+	}
+	var streamSigner *streamtoken.Signer
+	if cfg.StreamTokens.Secret != "" {
+		ttl := cfg.StreamTokens.TTL
+		if ttl <= 0 {
+			ttl = defaultStreamTokenTTL
+		}
+		streamSecret, err := resolver.Load(ctx, cfg.StreamTokens.Secret)
+		if err != nil {
+			return nil, fmt.Errorf("failed to load stream token secret: %w", err)
+		}
+		if streamSigner, err = streamtoken.NewSigner(streamSecret.Value(), ttl); err != nil {
+			return nil, fmt.Errorf("failed to create stream token signer: %w", err)
+		}
+		streamSecret.OnChange(func(secret string) {
+			if err := streamSigner.Rotate(secret); err != nil {
+				log.Error("failed to rotate stream token secret", slog.Any("error", err))
+			}
+		})
+		secretWatcher.Watch("stream_tokens.secret", streamSecret)
+		streamTokenService, err := service.NewStreamTokenService(userStorage, streamSigner, log)
+		if err != nil {
+			return nil, fmt.Errorf("failed to create stream token service: %w", err)
+		}
+		if err := router.SetupStreamTokenRoutes(mux, streamTokenService, log); err != nil {
+			return nil, fmt.Errorf("failed to register stream token routes: %w", err)
+		}
+	} else if cfg.StreamTokens.Required {
+		return nil, errors.New("stream_tokens.required is set but stream_tokens.secret is empty")
+	}
+	requestVerifier, err := newRequestVerifier(ctx, resolver, secretWatcher, cfg.RequestSigning, log)
+	if err != nil {
+		return nil, err
+	}
+	var requestNonces router.NonceStore
+	if requestVerifier != nil && cfg.RequestSigning.RejectReplays {
+		nonceStorage, err := storage.NewNonceStorage(database, log)
+		if err != nil {
+			return nil, fmt.Errorf("failed to create nonce storage: %w", err)
+		}
+		if err := jobs.Add("delete-expired-nonces", nonceCleanupInterval, func(ctx context.Context) error {
+			_, err := nonceStorage.DeleteExpiredNonces(ctx)
+			return err
+		}); err != nil {
+			return nil, fmt.Errorf("failed to schedule nonce cleanup: %w", err)
+		}
+		requestNonces = nonceStorage
+	}
+	if cfg.CalendarFeed.Secret != "" {
+		// A calendar token lives for months; signed with the stream secret it
+		// would also open the streaming endpoints.
+		if cfg.StreamTokens.Secret != "" && cfg.CalendarFeed.Secret == cfg.StreamTokens.Secret {
+			return nil, errors.New("calendar_feed.secret must differ from stream_tokens.secret")
+		}
+		ttl := cfg.CalendarFeed.TTL
+		if ttl <= 0 {
+			ttl = defaultCalendarTokenTTL
+		}
+		calendarSecret, err := resolver.Load(ctx, cfg.CalendarFeed.Secret)
+		if err != nil {
+			return nil, fmt.Errorf("failed to load calendar feed secret: %w", err)
+		}
+		calendarSigner, err := streamtoken.NewSigner(calendarSecret.Value(), ttl)
+		if err != nil {
+			return nil, fmt.Errorf("failed to create calendar token signer: %w", err)
+		}
+		calendarSecret.OnChange(func(secret string) {
+			if err := calendarSigner.Rotate(secret); err != nil {
+				log.Error("failed to rotate calendar feed secret", slog.Any("error", err))
+			}
+		})
+		secretWatcher.Watch("calendar_feed.secret", calendarSecret)
+		calendarTokenService, err := service.NewStreamTokenService(userStorage, calendarSigner, log)
+		if err != nil {
+			return nil, fmt.Errorf("failed to create calendar token service: %w", err)
+		}
+		if err := router.SetupCalendarRoutes(mux, calendarTokenService, calendarSigner, prService, log); err != nil {
+			return nil, fmt.Errorf("failed to register calendar routes: %w", err)
+		}
+	}
+	var (
+		authService   *service.AuthService
+		sessionCookie router.SessionCookie
+	)
+	if cfg.Sessions.Enabled {
+		sessionStorage, err := storage.NewSessionStorage(database, log)
+		if err != nil {
+			return nil, fmt.Errorf("failed to create session storage: %w", err)
+		}
+		ttl := cfg.Sessions.TTL
+		if ttl <= 0 {
+			ttl = defaultSessionTTL
+		}
+		if authService, err = service.NewAuthService(userStorage, sessionStorage, ttl, log); err != nil {
+			return nil, fmt.Errorf("failed to create auth service: %w", err)
+		}
+		sessionCookie = router.SessionCookie{Name: cfg.Sessions.CookieName, Secure: cfg.Sessions.SecureCookie}
+		if sessionCookie.Name == "" {
+			sessionCookie.Name = defaultSessionCookie
+		}
+		if err := router.SetupSessionRoutes(mux, authService, sessionCookie, cfg.Impersonation.Admins, log); err != nil {
+			return nil, fmt.Errorf("failed to register session routes: %w", err)
+		}
+		if err := jobs.Add("delete-expired-sessions", sessionCleanupInterval, func(ctx context.Context) error {
+			_, err := sessionStorage.DeleteExpiredSessions(ctx)
+			return err
+		}); err != nil {
+			return nil, fmt.Errorf("failed to schedule session cleanup: %w", err)
+		}
+	}
+	var identityService *service.IdentityService
+	if cfg.OIDC.Issuer != "" {
+		verifier, err := oidc.NewVerifier(cfg.OIDC.Issuer, cfg.OIDC.Audience, nil, log)
+		if err != nil {
+			return nil, fmt.Errorf("failed to create oidc verifier: %w", err)
+		}
+		identityStorage, err := storage.NewIdentityStorage(database, log)
+		if err != nil {
+			return nil, fmt.Errorf("failed to create identity storage: %w", err)
+		}
+		var identityOpts []service.IdentityOption
+		if cfg.OIDC.AutoProvision {
+			if cfg.OIDC.ProvisionTeam == "" {
+				return nil, errors.New("oidc.auto_provision is set but oidc.provision_team is empty")
+			}
+			identityOpts = append(identityOpts, service.WithAutoProvision(cfg.OIDC.ProvisionTeam))
+		}
+		identityService, err = service.NewIdentityService(txManager, verifier, identityStorage, userStorage, log, identityOpts...)
+		if err != nil {
+			return nil, fmt.Errorf("failed to create identity service: %w", err)
+		}
+	}
+	if cfg.Encryption.PrimaryKey != "" {
+		fieldCipher, err := newFieldCipher(ctx, resolver, cfg.Encryption)
+		if err != nil {
+			return nil, err
+		}
+		integrationTokenService, err := newIntegrationTokenService(database, txManager, teamStorage, fieldCipher, log)
+		if err != nil {
+			return nil, err
+		}
+		if err := router.SetupIntegrationTokenRoutes(mux, integrationTokenService, log); err != nil {
+			return nil, fmt.Errorf("failed to register integration token routes: %w", err)
+		}
+	}
+	auditStorage, err := storage.NewAuditStorage(database, log)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create audit storage: %w", err)
+	}
+	var impersonationService *service.ImpersonationService
+	if len(cfg.Impersonation.Admins) > 0 {
+		if authService == nil && identityService == nil {
+			return nil, errors.New("impersonation.admins requires sessions or oidc to authenticate admins")
+		}
+		impersonationService, err = service.NewImpersonationService(userStorage, auditStorage, cfg.Impersonation.Admins, log)
+		if err != nil {
+			return nil, fmt.Errorf("failed to create impersonation service: %w", err)
+		}
+	}
+	if cfg.Secrets.RefreshInterval > 0 && secretWatcher.Len() > 0 {
+		if err := jobs.Add("refresh-secrets", cfg.Secrets.RefreshInterval, secretWatcher.Refresh); err != nil {
+			return nil, fmt.Errorf("failed to schedule secret refresh: %w", err)
+		}
+	}
+	drainer, err := drain.New(cfg.DrainDelay, log)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create drain controller: %w", err)
+	}
+	if err := router.SetupDrainRoutes(mux, drainer, log); err != nil {
+		return nil, fmt.Errorf("failed to register drain routes: %w", err)
+	}
+	exclusionStorage, err := storage.NewExclusionStorage(database, log)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create exclusion storage: %w", err)
+	}
+	exclusionService, err := service.NewExclusionService(exclusionStorage, log)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create exclusion service: %w", err)
+	}
+	if err := router.SetupExclusionRoutes(mux, exclusionService, log); err != nil {
+		return nil, fmt.Errorf("failed to register exclusion routes: %w", err)
+	}
+	snapshotService, err := service.NewSnapshotService(txManager, snapshotStorage, prStorage, auditStorage, log)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create snapshot service: %w", err)
+	}
+	if err := router.SetupSnapshotRoutes(mux, snapshotService, log); err != nil {
+		return nil, fmt.Errorf("failed to register snapshot routes: %w", err)
+	}
+	deprecationRules := make([]deprecation.Rule, 0, len(cfg.Deprecations))
+	for _, d := range cfg.Deprecations {
+		deprecationRules = append(deprecationRules, deprecation.Rule(d))
+	}
+	deprecations, err := deprecation.New(deprecationRules)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load deprecations: %w", err)
+	}
+	if err := router.SetupDeprecationRoutes(mux, deprecations, log); err != nil {
+		return nil, fmt.Errorf("failed to register deprecation routes: %w", err)
+	}
+	if cfg.HTTPServer.UI {
+		if err := router.SetupUIRoutes(mux, log); err != nil {
+			return nil, fmt.Errorf("failed to register ui routes: %w", err)
+		}
+	}
+	var handler http.Handler = mux
+	if shedder != nil {
+		if err := router.SetupLoadRoutes(mux, shedder, log); err != nil {
+			return nil, fmt.Errorf("failed to register load routes: %w", err)
+		}
+		handler = router.ShedLowPriority(handler, shedder, log)
+	}
+	if cfg.Debug.LogPayloads {
+		if cfg.Env == "local" || cfg.Env == "dev" {
+			handler = router.PayloadLogger(handler, log, cfg.Debug.RedactFields, cfg.Debug.MaxBodyBytes)
+		} else {
+			log.Warn("payload logging is ignored outside local/dev env", slog.String("env", cfg.Env))
+		}
+	}
+	handler = router.RequireJSON(handler, log)
+	handler = router.Deprecations(handler, deprecations)
+	if impersonationService != nil {
+		handler = router.Impersonate(handler, impersonationService, log)
+	}
+	if requestVerifier != nil {
+		handler = router.VerifySignature(handler, requestVerifier, requestNonces, cfg.RequestSigning.Required, log)
+	}
+	if authService != nil {
+		handler = router.SessionAuth(handler, authService, sessionCookie, log)
+	}
+	if identityService != nil {
+		handler = router.BearerIdentity(handler, identityService, log)
+	}
+	if streamSigner != nil {
+		handler = router.RequireStreamToken(handler, streamSigner, cfg.StreamTokens.Required, log)
+	}
+	handler = router.ResponseEnvelope(handler, cfg.HTTPServer.ResponseEnvelope)
+	handler = router.RouteMethods(handler, mux, router.CORSPolicy(cfg.HTTPServer.CORS), log)
+	handler = router.RequestDeadline(handler, log)
+	handler = router.DrainAware(handler, drainer, log)
 	httpServer := &http.Server{
 		Addr:              cfg.Addr,
-		Handler:           mux,
+		Handler:           handler,
 		ReadHeaderTimeout: cfg.Timeout,
 		ReadTimeout:       cfg.Timeout,
 		WriteTimeout:      cfg.Timeout,
@@ -92,11 +716,14 @@ func NewApp(cfg *config.Config, log *slog.Logger) (*App, error) {
 		httpServer: httpServer,
 		addr:       cfg.Addr,
 		database:   database,
+		scheduler:  jobs,
+		drainer:    drainer,
 		log:        log,
 	}, nil
 }
 
 func (a *App) Run() error {
+	a.scheduler.Start(context.Background())
 	a.log.Info("starting http server", slog.String("port", a.addr))
 	return a.httpServer.ListenAndServe()
 }
@@ -108,10 +735,220 @@ func (a *App) MustRun() {
 	}
 }
 
+// Drain fails readiness and schedules shutdown after the configured
+// de-registration delay; Drained reports when that delay has elapsed.
+func (a *App) Drain() {
+	a.drainer.Start()
+}
+
+func (a *App) Drained() <-chan struct{} {
+	return a.drainer.Done()
+}
+
 func (a *App) Close(ctx context.Context) {
-	a.database.Close()
 	a.log.Info("trying to shutdown server")
 	if err := a.httpServer.Shutdown(ctx); err != nil {
 		a.log.Warn("failed to close http server", slog.Any("error", err))
 	}
+	a.scheduler.Stop()
+	a.database.Close()
+}
+
+func newSecretResolver(ctx context.Context, cfg config.Vault) (*secrets.Resolver, error) {
+	if cfg.Addr == "" {
+		return secrets.NewResolver(nil), nil
+	}
+	token, err := secrets.NewResolver(nil).Resolve(ctx, cfg.Token)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load vault token: %w", err)
+	}
+	vault, err := secrets.NewVaultClient(cfg.Addr, token, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create vault client: %w", err)
+	}
+	return secrets.NewResolver(vault), nil
+}
+
+// RotateIntegrationKeys re-encrypts stored integration tokens with
+// encryption.primary_key. Old keys must stay in encryption.keys until it
+// succeeds.
+func RotateIntegrationKeys(ctx context.Context, cfg *config.Config, log *slog.Logger) (int, error) {
+	if cfg.Encryption.PrimaryKey == "" {
+		return 0, errors.New("encryption.primary_key is not set")
+	}
+	resolver, err := newSecretResolver(ctx, cfg.Secrets.Vault)
+	if err != nil {
+		return 0, err
+	}
+	fieldCipher, err := newFieldCipher(ctx, resolver, cfg.Encryption)
+	if err != nil {
+		return 0, err
+	}
+	dbURL, err := resolver.Resolve(ctx, cfg.DBURL)
+	if err != nil {
+		return 0, fmt.Errorf("failed to load database url: %w", err)
+	}
+	database, err := postgres.New(ctx, dbURL, log)
+	if err != nil {
+		return 0, fmt.Errorf("failed to create database: %w", err)
+	}
+	defer database.Close()
+	txManager, err := storage.NewTxManager(database, log)
+	if err != nil {
+		return 0, fmt.Errorf("failed to create tx manager: %w", err)
+	}
+	teamStorage, err := storage.NewTeamStorage(database, log)
+	if err != nil {
+		return 0, fmt.Errorf("failed to create team storage: %w", err)
+	}
+	integrationTokenService, err := newIntegrationTokenService(database, txManager, teamStorage, fieldCipher, log)
+	if err != nil {
+		return 0, err
+	}
+	return integrationTokenService.RotateKeys(ctx)
+}
+
+func newIntegrationTokenService(
+	database *postgres.Postgres,
+	txManager *storage.TxManagerSQL,
+	teamStorage *storage.TeamStorage,
+	fieldCipher *storage.FieldCipher,
+	log *slog.Logger,
+) (*service.IntegrationTokenService, error) {
+	tokenStorage, err := storage.NewIntegrationTokenStorage(database, fieldCipher, log)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create integration token storage: %w", err)
+	}
+	integrationTokenService, err := service.NewIntegrationTokenService(txManager, teamStorage, tokenStorage, log)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create integration token service: %w", err)
+	}
+	return integrationTokenService, nil
+}
+
+func newFieldCipher(ctx context.Context, resolver *secrets.Resolver, cfg config.Encryption) (*storage.FieldCipher, error) {
+	keys := make(map[string][]byte, len(cfg.Keys))
+	for id, ref := range cfg.Keys {
+		encoded, err := resolver.Resolve(ctx, ref)
+		if err != nil {
+			return nil, fmt.Errorf("failed to load encryption key %q: %w", id, err)
+		}
+		key, err := base64.StdEncoding.DecodeString(strings.TrimSpace(encoded))
+		if err != nil {
+			return nil, fmt.Errorf("encryption key %q must be base64: %w", id, err)
+		}
+		keys[id] = key
+	}
+	fieldCipher, err := storage.NewFieldCipher(cfg.PrimaryKey, keys)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create field cipher: %w", err)
+	}
+	return fieldCipher, nil
+}
+
+// statusMigrationTarget is a storage that reads pull request status and so
+// follows the pr_status_enum phase.
+type statusMigrationTarget interface {
+	SetStatusMigrationPhase(storage.MigrationPhase)
+}
+
+func newOnlineMigrationService(
+	database *postgres.Postgres,
+	statusTargets []statusMigrationTarget,
+	cfg config.OnlineMigrations,
+	log *slog.Logger,
+) (*service.OnlineMigrationService, error) {
+	for name := range cfg.Phases {
+		if name != storage.PRStatusEnumMigration.Name {
+			return nil, fmt.Errorf("unknown online migration %q", name)
+		}
+	}
+	phase, err := storage.ParseMigrationPhase(cfg.Phases[storage.PRStatusEnumMigration.Name])
+	if err != nil {
+		return nil, fmt.Errorf("online migration %s: %w", storage.PRStatusEnumMigration.Name, err)
+	}
+	migrationStorage, err := storage.NewOnlineMigrationStorage(database, log)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create online migration storage: %w", err)
+	}
+	migrations := []service.OnlineMigration{{
+		ColumnMigration: storage.PRStatusEnumMigration,
+		Phase:           phase,
+		Apply: func(phase storage.MigrationPhase) {
+			for _, t := range statusTargets {
+				t.SetStatusMigrationPhase(phase)
+			}
+		},
+	}}
+	svc, err := service.NewOnlineMigrationService(
+		migrationStorage,
+		migrations,
+		log,
+		service.WithBackfillBatchSize(cfg.BatchSize),
+	)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create online migration service: %w", err)
+	}
+	return svc, nil
+}
+
+func newPIILogger(ctx context.Context, resolver *secrets.Resolver, cfg config.PIILogging, log *slog.Logger) (*slog.Logger, error) {
+	level := slog.LevelInfo
+	if cfg.MinLevel != "" {
+		if err := level.UnmarshalText([]byte(cfg.MinLevel)); err != nil {
+			return nil, fmt.Errorf("invalid logging.pii.min_level: %w", err)
+		}
+	}
+	hashKey, err := resolver.Resolve(ctx, cfg.HashKey)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load logging.pii.hash_key: %w", err)
+	}
+	handler, err := piilog.New(log.Handler(), piilog.Config{
+		Mode:     cfg.Mode,
+		Fields:   cfg.Fields,
+		MinLevel: level,
+		HashKey:  hashKey,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("invalid logging.pii: %w", err)
+	}
+	return slog.New(handler), nil
+}
+
+// newRequestVerifier loads the secrets of the signing clients and keeps them
+// fresh with the other watched secrets. It returns nil when no client is
+// configured.
+func newRequestVerifier(ctx context.Context, resolver *secrets.Resolver, watcher *secrets.Watcher, cfg config.RequestSigning, log *slog.Logger) (*reqsign.Verifier, error) {
+	if len(cfg.Clients) == 0 {
+		if cfg.Required {
+			return nil, errors.New("request_signing.required is set but request_signing.clients is empty")
+		}
+		return nil, nil
+	}
+	verifier, err := reqsign.NewVerifier(cfg.MaxSkew)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create request verifier: %w", err)
+	}
+	seen := make(map[string]struct{}, len(cfg.Clients))
+	for _, c := range cfg.Clients {
+		clientID := strings.TrimSpace(c.ID)
+		if _, dup := seen[clientID]; dup {
+			return nil, fmt.Errorf("request signing client %s is defined twice", clientID)
+		}
+		seen[clientID] = struct{}{}
+		secret, err := resolver.Load(ctx, c.Secret)
+		if err != nil {
+			return nil, fmt.Errorf("failed to load request signing secret of %s: %w", clientID, err)
+		}
+		if err := verifier.SetSecret(clientID, secret.Value()); err != nil {
+			return nil, err
+		}
+		secret.OnChange(func(value string) {
+			if err := verifier.SetSecret(clientID, value); err != nil {
+				log.Error("failed to rotate request signing secret", slog.String("client_id", clientID), slog.Any("error", err))
+			}
+		})
+		watcher.Watch("request_signing.clients."+clientID+".secret", secret)
+	}
+	return verifier, nil
 }
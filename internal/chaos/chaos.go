@@ -0,0 +1,54 @@
+package chaos
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/cloudyy74/pr-reviewer-service/internal/models"
+)
+
+const maxStorageDelay = 30 * time.Second
+
+var (
+	ErrInjected      = errors.New("injected fault")
+	ErrInvalidConfig = errors.New("invalid fault config")
+	ErrDisabled      = errors.New("fault injection is not compiled in, build with -tags chaos")
+)
+
+type Notifier interface {
+	Publish(event models.AssignmentEvent)
+	Subscribe(userID string) (<-chan models.AssignmentEvent, func())
+}
+
+type Controller struct{}
+
+func (Controller) Faults() models.FaultConfig {
+	return current()
+}
+
+func (Controller) SetFaults(cfg models.FaultConfig) (models.FaultConfig, error) {
+	if err := validate(cfg); err != nil {
+		return models.FaultConfig{}, err
+	}
+	if err := set(cfg); err != nil {
+		return models.FaultConfig{}, err
+	}
+	return current(), nil
+}
+
+func validate(cfg models.FaultConfig) error {
+	for name, pct := range map[string]int{
+		"storage_delay_percent":      cfg.StorageDelayPercent,
+		"storage_fail_percent":       cfg.StorageFailPercent,
+		"drop_notifications_percent": cfg.DropNotificationsPercent,
+	} {
+		if pct < 0 || pct > 100 {
+			return fmt.Errorf("%w: %s must be between 0 and 100", ErrInvalidConfig, name)
+		}
+	}
+	if cfg.StorageDelayMs < 0 || time.Duration(cfg.StorageDelayMs)*time.Millisecond > maxStorageDelay {
+		return fmt.Errorf("%w: storage_delay_ms must be between 0 and %d", ErrInvalidConfig, maxStorageDelay.Milliseconds())
+	}
+	return nil
+}
@@ -0,0 +1,25 @@
+package chaos
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/cloudyy74/pr-reviewer-service/internal/models"
+)
+
+func TestValidate(t *testing.T) {
+	valid := models.FaultConfig{Enabled: true, StorageDelayMs: 200, StorageDelayPercent: 50, StorageFailPercent: 10, DropNotificationsPercent: 100}
+	if err := validate(valid); err != nil {
+		t.Fatalf("validate returned err: %v", err)
+	}
+	for _, cfg := range []models.FaultConfig{
+		{StorageFailPercent: 101},
+		{DropNotificationsPercent: -1},
+		{StorageDelayMs: -5},
+		{StorageDelayMs: 60_000},
+	} {
+		if err := validate(cfg); !errors.Is(err, ErrInvalidConfig) {
+			t.Fatalf("expected ErrInvalidConfig for %+v, got %v", cfg, err)
+		}
+	}
+}
@@ -0,0 +1,27 @@
+//go:build !chaos
+
+package chaos
+
+import (
+	"context"
+
+	"github.com/cloudyy74/pr-reviewer-service/internal/models"
+)
+
+const Enabled = false
+
+func current() models.FaultConfig {
+	return models.FaultConfig{}
+}
+
+func set(models.FaultConfig) error {
+	return ErrDisabled
+}
+
+func StorageFault(context.Context) error {
+	return nil
+}
+
+func WrapNotifier(n Notifier) Notifier {
+	return n
+}
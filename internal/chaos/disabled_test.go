@@ -0,0 +1,20 @@
+//go:build !chaos
+
+package chaos
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/cloudyy74/pr-reviewer-service/internal/models"
+)
+
+func TestDisabled_NoFaults(t *testing.T) {
+	if _, err := (Controller{}).SetFaults(models.FaultConfig{Enabled: true, StorageFailPercent: 100}); !errors.Is(err, ErrDisabled) {
+		t.Fatalf("expected ErrDisabled, got %v", err)
+	}
+	if err := StorageFault(context.Background()); err != nil {
+		t.Fatalf("StorageFault returned err: %v", err)
+	}
+}
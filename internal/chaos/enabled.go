@@ -0,0 +1,72 @@
+//go:build chaos
+
+package chaos
+
+import (
+	"context"
+	"math/rand/v2"
+	"sync"
+	"time"
+
+	"github.com/cloudyy74/pr-reviewer-service/internal/models"
+)
+
+const Enabled = true
+
+var (
+	mu     sync.RWMutex
+	config models.FaultConfig
+	roll   = func() int { return rand.IntN(100) }
+)
+
+func current() models.FaultConfig {
+	mu.RLock()
+	defer mu.RUnlock()
+	return config
+}
+
+func set(cfg models.FaultConfig) error {
+	mu.Lock()
+	config = cfg
+	mu.Unlock()
+	return nil
+}
+
+func hit(pct int) bool {
+	return pct > 0 && roll() < pct
+}
+
+func StorageFault(ctx context.Context) error {
+	cfg := current()
+	if !cfg.Enabled {
+		return nil
+	}
+	if cfg.StorageDelayMs > 0 && hit(cfg.StorageDelayPercent) {
+		timer := time.NewTimer(time.Duration(cfg.StorageDelayMs) * time.Millisecond)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return ctx.Err()
+		case <-timer.C:
+		}
+	}
+	if hit(cfg.StorageFailPercent) {
+		return ErrInjected
+	}
+	return nil
+}
+
+func WrapNotifier(n Notifier) Notifier {
+	return droppingNotifier{Notifier: n}
+}
+
+type droppingNotifier struct {
+	Notifier
+}
+
+func (d droppingNotifier) Publish(event models.AssignmentEvent) {
+	if cfg := current(); cfg.Enabled && hit(cfg.DropNotificationsPercent) {
+		return
+	}
+	d.Notifier.Publish(event)
+}
@@ -0,0 +1,75 @@
+//go:build chaos
+
+package chaos
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/cloudyy74/pr-reviewer-service/internal/models"
+)
+
+type countingNotifier struct {
+	published int
+}
+
+func (c *countingNotifier) Publish(models.AssignmentEvent) {
+	c.published++
+}
+
+func (c *countingNotifier) Subscribe(string) (<-chan models.AssignmentEvent, func()) {
+	return nil, func() {}
+}
+
+func withFaults(t *testing.T, cfg models.FaultConfig) {
+	t.Helper()
+	if _, err := (Controller{}).SetFaults(cfg); err != nil {
+		t.Fatalf("SetFaults returned err: %v", err)
+	}
+	t.Cleanup(func() { _ = set(models.FaultConfig{}) })
+}
+
+func TestStorageFault_FailAndDelay(t *testing.T) {
+	withFaults(t, models.FaultConfig{Enabled: true, StorageDelayMs: 20, StorageDelayPercent: 100, StorageFailPercent: 100})
+
+	start := time.Now()
+	if err := StorageFault(context.Background()); !errors.Is(err, ErrInjected) {
+		t.Fatalf("expected ErrInjected, got %v", err)
+	}
+	if time.Since(start) < 20*time.Millisecond {
+		t.Fatalf("expected injected delay")
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if err := StorageFault(ctx); !errors.Is(err, context.Canceled) {
+		t.Fatalf("expected context.Canceled, got %v", err)
+	}
+}
+
+func TestStorageFault_DisabledConfig(t *testing.T) {
+	withFaults(t, models.FaultConfig{Enabled: false, StorageFailPercent: 100})
+
+	if err := StorageFault(context.Background()); err != nil {
+		t.Fatalf("expected no fault, got %v", err)
+	}
+}
+
+func TestWrapNotifier_Drops(t *testing.T) {
+	inner := &countingNotifier{}
+	n := WrapNotifier(inner)
+
+	withFaults(t, models.FaultConfig{Enabled: true, DropNotificationsPercent: 100})
+	n.Publish(models.AssignmentEvent{UserID: "u1"})
+	if inner.published != 0 {
+		t.Fatalf("expected notification to be dropped")
+	}
+
+	withFaults(t, models.FaultConfig{Enabled: true})
+	n.Publish(models.AssignmentEvent{UserID: "u1"})
+	if inner.published != 1 {
+		t.Fatalf("expected notification to be delivered")
+	}
+}
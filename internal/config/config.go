@@ -11,15 +11,222 @@ import (
 )
 
 type Config struct {
-	Env        string `yaml:"env" env-default:"local"`
-	DBURL      string `yaml:"db_url" env-required:"true"`
-	HTTPServer `yaml:"http_server"`
+	Env              string `yaml:"env" env-default:"local"`
+	DBURL            string `yaml:"db_url" env-required:"true"`
+	HTTPServer       `yaml:"http_server"`
+	PullRequests     PullRequests     `yaml:"pull_requests"`
+	Scheduler        Scheduler        `yaml:"scheduler"`
+	Stats            Stats            `yaml:"stats"`
+	Teams            Teams            `yaml:"teams"`
+	Users            Users            `yaml:"users"`
+	Debug            Debug            `yaml:"debug"`
+	Logging          Logging          `yaml:"logging"`
+	LoadShedding     LoadShedding     `yaml:"load_shedding"`
+	Outbox           Outbox           `yaml:"outbox"`
+	Notifications    Notifications    `yaml:"notifications"`
+	Alerts           Alerts           `yaml:"alerts"`
+	StreamTokens     StreamTokens     `yaml:"stream_tokens"`
+	CalendarFeed     CalendarFeed     `yaml:"calendar_feed"`
+	Jira             Jira             `yaml:"jira"`
+	Sessions         Sessions         `yaml:"sessions"`
+	OIDC             OIDC             `yaml:"oidc"`
+	Impersonation    Impersonation    `yaml:"impersonation"`
+	RequestSigning   RequestSigning   `yaml:"request_signing"`
+	Secrets          Secrets          `yaml:"secrets"`
+	Encryption       Encryption       `yaml:"encryption"`
+	OnlineMigrations OnlineMigrations `yaml:"online_migrations"`
+	Deprecations     []Deprecation    `yaml:"deprecations"`
 }
 
 type HTTPServer struct {
-	Addr        string        `yaml:"addr" env-default:"localhost:8080"`
-	Timeout     time.Duration `yaml:"timeout" env-default:"4s"`
-	IdleTimeout time.Duration `yaml:"idle_timeout" env-default:"60s"`
+	Addr             string        `yaml:"addr" env-default:"localhost:8080"`
+	Timeout          time.Duration `yaml:"timeout" env-default:"4s"`
+	IdleTimeout      time.Duration `yaml:"idle_timeout" env-default:"60s"`
+	DrainDelay       time.Duration `yaml:"drain_delay" env-default:"15s"`
+	ResponseEnvelope bool          `yaml:"response_envelope" env-default:"false"`
+	UI               bool          `yaml:"ui" env-default:"true"`
+	CORS             CORS          `yaml:"cors"`
+}
+
+type CORS struct {
+	AllowedOrigins   []string      `yaml:"allowed_origins"`
+	AllowedHeaders   []string      `yaml:"allowed_headers"`
+	AllowCredentials bool          `yaml:"allow_credentials" env-default:"false"`
+	MaxAge           time.Duration `yaml:"max_age" env-default:"10m"`
+}
+
+type PullRequests struct {
+	DuplicateCheck     bool    `yaml:"duplicate_check" env-default:"false"`
+	DuplicateThreshold float64 `yaml:"duplicate_threshold" env-default:"0.85"`
+	IDMaxLength        int     `yaml:"id_max_length" env-default:"64"`
+	IDPattern          string  `yaml:"id_pattern"`
+	GenerateIDs        bool    `yaml:"generate_ids" env-default:"false"`
+	ReviewerStrategy   string  `yaml:"reviewer_strategy" env-default:"random"`
+	RecentReviewerPRs  int     `yaml:"recent_reviewer_prs" env-default:"0"`
+	RequireApprovals   bool    `yaml:"require_approvals" env-default:"false"`
+}
+
+type Stats struct {
+	ReviewerCapacity      int     `yaml:"reviewer_capacity" env-default:"5"`
+	AnomalyShareThreshold float64 `yaml:"anomaly_share_threshold" env-default:"0.5"`
+	AnomalyMinAssignments int     `yaml:"anomaly_min_assignments" env-default:"5"`
+}
+
+type Teams struct {
+	NameNormalization string `yaml:"name_normalization" env-default:"preserve"`
+}
+
+type Users struct {
+	UniqueUsernames bool `yaml:"unique_usernames" env-default:"false"`
+}
+
+type Debug struct {
+	LogPayloads  bool     `yaml:"log_payloads" env-default:"false"`
+	RedactFields []string `yaml:"redact_fields"`
+	MaxBodyBytes int      `yaml:"max_body_bytes" env-default:"4096"`
+}
+
+type Logging struct {
+	PII PIILogging `yaml:"pii"`
+}
+
+type PIILogging struct {
+	Mode     string   `yaml:"mode" env-default:"off"`
+	Fields   []string `yaml:"fields"`
+	MinLevel string   `yaml:"min_level" env-default:"info"`
+	HashKey  string   `yaml:"hash_key"`
+}
+
+type LoadShedding struct {
+	Enabled           bool          `yaml:"enabled" env-default:"false"`
+	PoolWaitThreshold time.Duration `yaml:"pool_wait_threshold" env-default:"50ms"`
+	SampleInterval    time.Duration `yaml:"sample_interval" env-default:"1s"`
+}
+
+type Outbox struct {
+	Enabled       bool          `yaml:"enabled" env-default:"false"`
+	BatchSize     int           `yaml:"batch_size" env-default:"100"`
+	Workers       int           `yaml:"workers" env-default:"4"`
+	Lease         time.Duration `yaml:"lease" env-default:"30s"`
+	RelayInterval time.Duration `yaml:"relay_interval" env-default:"1s"`
+}
+
+type Notifications struct {
+	Digests       bool          `yaml:"digests" env-default:"false"`
+	FlushInterval time.Duration `yaml:"flush_interval" env-default:"30s"`
+}
+
+type Alerts struct {
+	EvalInterval time.Duration `yaml:"eval_interval" env-default:"1m"`
+	Rules        []AlertRule   `yaml:"rules"`
+}
+
+type AlertRule struct {
+	Name      string            `yaml:"name"`
+	Metric    string            `yaml:"metric"`
+	Labels    map[string]string `yaml:"labels"`
+	Op        string            `yaml:"op"`
+	Threshold float64           `yaml:"threshold"`
+	Window    time.Duration     `yaml:"window"`
+	For       time.Duration     `yaml:"for"`
+	Severity  string            `yaml:"severity"`
+	Notify    []string          `yaml:"notify"`
+}
+
+type StreamTokens struct {
+	Secret   string        `yaml:"secret"`
+	TTL      time.Duration `yaml:"ttl" env-default:"5m"`
+	Required bool          `yaml:"required" env-default:"false"`
+}
+
+type CalendarFeed struct {
+	Secret string        `yaml:"secret"`
+	TTL    time.Duration `yaml:"ttl" env-default:"8760h"`
+}
+
+type Jira struct {
+	Enabled bool          `yaml:"enabled" env-default:"false"`
+	BaseURL string        `yaml:"base_url"`
+	User    string        `yaml:"user"`
+	Token   string        `yaml:"token"`
+	Timeout time.Duration `yaml:"timeout" env-default:"10s"`
+	Teams   []JiraTeam    `yaml:"teams"`
+}
+
+type JiraTeam struct {
+	TeamName         string `yaml:"team_name"`
+	ProjectKey       string `yaml:"project_key"`
+	ParentIssue      string `yaml:"parent_issue"`
+	IssueType        string `yaml:"issue_type"`
+	DoneTransition   string `yaml:"done_transition"`
+	CancelTransition string `yaml:"cancel_transition"`
+}
+
+type Sessions struct {
+	Enabled      bool          `yaml:"enabled" env-default:"false"`
+	TTL          time.Duration `yaml:"ttl" env-default:"12h"`
+	CookieName   string        `yaml:"cookie_name" env-default:"pr_reviewer_session"`
+	SecureCookie bool          `yaml:"secure_cookie" env-default:"true"`
+}
+
+type OIDC struct {
+	Issuer        string `yaml:"issuer"`
+	Audience      string `yaml:"audience"`
+	AutoProvision bool   `yaml:"auto_provision" env-default:"false"`
+	ProvisionTeam string `yaml:"provision_team"`
+}
+
+type Impersonation struct {
+	Admins []string `yaml:"admins"`
+}
+
+// RequestSigning lets internal services authenticate with an HMAC of each
+// request instead of a user identity. Secrets accept file: and vault: refs.
+// RejectReplays stores used signatures so each is accepted only once.
+type RequestSigning struct {
+	Required      bool            `yaml:"required" env-default:"false"`
+	MaxSkew       time.Duration   `yaml:"max_skew" env-default:"5m"`
+	RejectReplays bool            `yaml:"reject_replays" env-default:"true"`
+	Clients       []SigningClient `yaml:"clients"`
+}
+
+type SigningClient struct {
+	ID     string `yaml:"id"`
+	Secret string `yaml:"secret"`
+}
+
+type Secrets struct {
+	RefreshInterval time.Duration `yaml:"refresh_interval" env-default:"5m"`
+	Vault           Vault         `yaml:"vault"`
+}
+
+type Vault struct {
+	Addr  string `yaml:"addr"`
+	Token string `yaml:"token"`
+}
+
+type Encryption struct {
+	PrimaryKey string            `yaml:"primary_key"`
+	Keys       map[string]string `yaml:"keys"`
+}
+
+type OnlineMigrations struct {
+	Phases           map[string]string `yaml:"phases"`
+	BatchSize        int               `yaml:"batch_size" env-default:"500"`
+	BackfillInterval time.Duration     `yaml:"backfill_interval" env-default:"30s"`
+}
+
+type Deprecation struct {
+	Route  string `yaml:"route"`
+	Field  string `yaml:"field"`
+	Since  string `yaml:"since"`
+	Sunset string `yaml:"sunset"`
+	Link   string `yaml:"link"`
+}
+
+type Scheduler struct {
+	AckCheckInterval     time.Duration `yaml:"ack_check_interval" env-default:"5m"`
+	AnomalyCheckInterval time.Duration `yaml:"anomaly_check_interval" env-default:"1h"`
 }
 
 func MustLoadConfig() *Config {
@@ -0,0 +1,3 @@
+drop index if exists pull_requests_author_id_idx;
+
+drop table if exists team_settings;
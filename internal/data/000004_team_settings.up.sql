@@ -0,0 +1,7 @@
+create table if not exists team_settings (
+    team_name varchar(64) primary key not null references teams(name) on delete cascade,
+    strict_duplicate_check boolean not null default false
+);
+
+create index if not exists pull_requests_author_id_idx
+    on pull_requests(author_id);
@@ -0,0 +1,8 @@
+drop table if exists assignment_events;
+
+alter table team_settings
+    drop column if exists ack_timeout_hours;
+
+alter table pull_requests_reviewers
+    drop column if exists acknowledged_at,
+    drop column if exists assigned_at;
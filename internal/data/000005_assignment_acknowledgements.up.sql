@@ -0,0 +1,21 @@
+alter table pull_requests_reviewers
+    add column if not exists assigned_at timestamp with time zone not null default now(),
+    add column if not exists acknowledged_at timestamp with time zone;
+
+alter table team_settings
+    add column if not exists ack_timeout_hours int not null default 0;
+
+create table if not exists assignment_events (
+    id bigserial primary key,
+    pull_request_id varchar(64) not null references pull_requests(id) on delete cascade,
+    user_id varchar(64) not null references users(id) on delete cascade,
+    event varchar(32) not null,
+    reason varchar(256) not null default '',
+    created_at timestamp with time zone not null default now()
+);
+
+create index if not exists assignment_events_user_id_created_at_idx
+    on assignment_events(user_id, created_at);
+
+create index if not exists assignment_events_pull_request_id_idx
+    on assignment_events(pull_request_id);
@@ -0,0 +1,4 @@
+drop index if exists pull_requests_created_at_idx;
+
+alter table pull_requests
+    drop column if exists created_at;
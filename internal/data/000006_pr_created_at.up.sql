@@ -0,0 +1,5 @@
+alter table pull_requests
+    add column if not exists created_at timestamp with time zone not null default now();
+
+create index if not exists pull_requests_created_at_idx
+    on pull_requests(created_at);
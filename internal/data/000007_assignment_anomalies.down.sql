@@ -0,0 +1,3 @@
+drop index if exists assignment_anomalies_week_start_idx;
+
+drop table if exists assignment_anomalies;
@@ -0,0 +1,16 @@
+create table if not exists assignment_anomalies (
+    id bigserial primary key,
+    team_name varchar(64) not null references teams(name) on delete cascade,
+    user_id varchar(64) not null references users(id) on delete cascade,
+    week_start timestamp with time zone not null,
+    user_assignments int not null,
+    team_assignments int not null,
+    reviewers_count int not null,
+    share double precision not null,
+    explanation text not null,
+    detected_at timestamp with time zone not null default now(),
+    unique (team_name, user_id, week_start)
+);
+
+create index if not exists assignment_anomalies_week_start_idx
+    on assignment_anomalies(week_start);
@@ -0,0 +1,28 @@
+drop index if exists pull_requests_status_idx;
+
+create table if not exists statuses (
+    id serial primary key,
+    name varchar(64) unique not null
+);
+
+insert into statuses (name)
+values
+    ('OPEN'),
+    ('MERGED')
+on conflict (name) do nothing;
+
+alter table pull_requests
+    add column if not exists status_id int references statuses(id);
+
+update pull_requests pr
+set status_id = s.id
+from statuses s
+where s.name = pr.status;
+
+alter table pull_requests
+    alter column status_id set not null,
+    drop constraint if exists pull_requests_status_check,
+    drop column if exists status;
+
+create index if not exists pull_requests_status_id_idx
+    on pull_requests(status_id);
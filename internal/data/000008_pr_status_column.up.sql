@@ -0,0 +1,21 @@
+alter table pull_requests
+    add column if not exists status varchar(16);
+
+update pull_requests pr
+set status = s.name
+from statuses s
+where s.id = pr.status_id;
+
+alter table pull_requests
+    alter column status set not null,
+    add constraint pull_requests_status_check check (status in ('OPEN', 'MERGED'));
+
+drop index if exists pull_requests_status_id_idx;
+
+alter table pull_requests
+    drop column if exists status_id;
+
+drop table if exists statuses;
+
+create index if not exists pull_requests_status_idx
+    on pull_requests(status);
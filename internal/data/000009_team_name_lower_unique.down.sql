@@ -0,0 +1 @@
+drop index if exists teams_name_lower_idx;
@@ -0,0 +1 @@
+create unique index if not exists teams_name_lower_idx on teams (lower(name));
@@ -0,0 +1,3 @@
+drop index if exists outbox_events_pending_idx;
+
+drop table if exists outbox_events;
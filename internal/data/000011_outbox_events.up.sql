@@ -0,0 +1,14 @@
+create table if not exists outbox_events (
+    id bigserial primary key,
+    topic varchar(64) not null,
+    aggregate_id varchar(64) not null,
+    payload jsonb not null,
+    created_at timestamp with time zone not null default now(),
+    available_at timestamp with time zone not null default now(),
+    attempts int not null default 0,
+    last_error text,
+    delivered_at timestamp with time zone
+);
+
+create index if not exists outbox_events_pending_idx
+    on outbox_events(available_at, id) where delivered_at is null;
@@ -0,0 +1,4 @@
+create table if not exists user_notification_settings (
+    user_id varchar(64) primary key not null references users(id) on delete cascade,
+    digest_window_minutes int not null default 0 check (digest_window_minutes >= 0)
+);
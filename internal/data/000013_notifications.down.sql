@@ -0,0 +1,2 @@
+drop index if exists notifications_user_created_idx;
+drop table if exists notifications;
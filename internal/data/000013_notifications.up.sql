@@ -0,0 +1,16 @@
+create table if not exists notifications (
+    id bigserial primary key,
+    dedup_key varchar(255) not null unique,
+    user_id varchar(64) not null,
+    channel varchar(32) not null,
+    event varchar(32) not null,
+    pull_request_ids jsonb not null default '[]',
+    status varchar(16) not null,
+    attempts int not null default 1,
+    last_error text,
+    created_at timestamp with time zone not null default now(),
+    updated_at timestamp with time zone not null default now()
+);
+
+create index if not exists notifications_user_created_idx
+    on notifications(user_id, created_at desc);
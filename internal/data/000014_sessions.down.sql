@@ -0,0 +1,3 @@
+drop index if exists sessions_expires_at_idx;
+drop table if exists sessions;
+drop table if exists user_credentials;
@@ -0,0 +1,16 @@
+create table if not exists user_credentials (
+    user_id varchar(64) primary key not null references users(id) on delete cascade,
+    password_hash text not null,
+    updated_at timestamp with time zone not null default now()
+);
+
+create table if not exists sessions (
+    id varchar(64) primary key not null,
+    user_id varchar(64) not null references users(id) on delete cascade,
+    csrf_token varchar(64) not null,
+    created_at timestamp with time zone not null default now(),
+    expires_at timestamp with time zone not null
+);
+
+create index if not exists sessions_expires_at_idx
+    on sessions(expires_at);
@@ -0,0 +1,2 @@
+drop index if exists user_identities_user_id_idx;
+drop table if exists user_identities;
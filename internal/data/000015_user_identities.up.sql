@@ -0,0 +1,10 @@
+create table if not exists user_identities (
+    issuer varchar(255) not null,
+    subject varchar(255) not null,
+    user_id varchar(64) not null references users(id) on delete cascade,
+    created_at timestamp with time zone not null default now(),
+    primary key (issuer, subject)
+);
+
+create index if not exists user_identities_user_id_idx
+    on user_identities(user_id);
@@ -0,0 +1,3 @@
+drop index if exists audit_log_actor_created_idx;
+drop index if exists audit_log_subject_created_idx;
+drop table if exists audit_log;
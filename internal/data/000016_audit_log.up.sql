@@ -0,0 +1,15 @@
+create table if not exists audit_log (
+    id bigserial primary key,
+    actor_id varchar(64) not null,
+    subject_id varchar(64) not null,
+    method varchar(16) not null,
+    path varchar(255) not null,
+    status int not null,
+    created_at timestamp with time zone not null default now()
+);
+
+create index if not exists audit_log_subject_created_idx
+    on audit_log(subject_id, created_at);
+
+create index if not exists audit_log_actor_created_idx
+    on audit_log(actor_id, created_at);
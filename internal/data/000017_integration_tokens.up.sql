@@ -0,0 +1,8 @@
+create table if not exists integration_tokens (
+    team_name varchar(64) not null references teams(name) on delete cascade,
+    provider varchar(32) not null,
+    token_encrypted text not null,
+    key_id varchar(64) not null,
+    updated_at timestamp with time zone not null default now(),
+    primary key (team_name, provider)
+);
@@ -0,0 +1,4 @@
+alter table pull_requests
+    drop column if exists status_enum;
+
+drop type if exists pr_status;
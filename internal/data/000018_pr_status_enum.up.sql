@@ -0,0 +1,10 @@
+do $$
+begin
+    create type pr_status as enum ('OPEN', 'MERGED');
+exception
+    when duplicate_object then null;
+end
+$$;
+
+alter table pull_requests
+    add column if not exists status_enum pr_status;
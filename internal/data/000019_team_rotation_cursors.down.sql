@@ -0,0 +1 @@
+drop table if exists team_rotation_cursors;
@@ -0,0 +1,5 @@
+create table if not exists team_rotation_cursors (
+    team_name varchar(64) primary key not null references teams(name) on delete cascade,
+    last_user_id varchar(64),
+    updated_at timestamp with time zone not null default now()
+);
@@ -0,0 +1,11 @@
+create table if not exists reviewer_exclusions (
+    reviewer_id varchar(64) not null references users(id) on delete cascade,
+    author_id varchar(64) not null references users(id) on delete cascade,
+    mutual boolean not null default false,
+    reason text not null default '',
+    created_at timestamp with time zone not null default now(),
+    primary key (reviewer_id, author_id),
+    check (reviewer_id <> author_id)
+);
+
+create index if not exists reviewer_exclusions_author_idx on reviewer_exclusions (author_id);
@@ -0,0 +1,7 @@
+create table if not exists team_fallbacks (
+    team_name varchar(64) not null references teams(name) on delete cascade,
+    buddy_team varchar(64) not null references teams(name) on delete cascade,
+    position integer not null,
+    primary key (team_name, buddy_team),
+    check (team_name <> buddy_team)
+);
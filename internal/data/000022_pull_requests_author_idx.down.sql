@@ -0,0 +1 @@
+drop index if exists pull_requests_author_created_at_idx;
@@ -0,0 +1 @@
+create index if not exists pull_requests_author_created_at_idx on pull_requests (author_id, created_at);
@@ -0,0 +1 @@
+alter table users drop column if exists review_weight;
@@ -0,0 +1 @@
+alter table users add column if not exists review_weight real not null default 1 check (review_weight > 0);
@@ -0,0 +1 @@
+drop table if exists team_membership_history;
@@ -0,0 +1,22 @@
+create table if not exists team_membership_history (
+    id bigserial primary key,
+    team_name varchar(64) not null references teams(name) on delete cascade,
+    user_id varchar(64) not null references users(id) on delete cascade,
+    change varchar(16) not null,
+    other_team varchar(64),
+    changed_at timestamptz not null default now(),
+    check (change in ('JOINED', 'LEFT'))
+);
+
+create index if not exists team_membership_history_team_idx
+    on team_membership_history (team_name, changed_at);
+
+create index if not exists team_membership_history_user_idx
+    on team_membership_history (user_id, changed_at);
+
+-- When existing members joined is unknown; record them as members since the
+-- epoch so point-in-time queries treat them as always on the team.
+insert into team_membership_history (team_name, user_id, change, changed_at)
+select team_name, id, 'JOINED', timestamptz 'epoch'
+from users
+where team_name is not null;
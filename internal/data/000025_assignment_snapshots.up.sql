@@ -0,0 +1,8 @@
+create table if not exists assignment_snapshots (
+    id bigserial primary key,
+    label varchar(128) not null default '',
+    created_by varchar(64) not null default '',
+    reviewers jsonb not null,
+    rotation_cursors jsonb not null,
+    created_at timestamp with time zone not null default now()
+);
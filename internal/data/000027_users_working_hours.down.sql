@@ -0,0 +1,4 @@
+alter table users
+    drop column if exists work_end_min,
+    drop column if exists work_start_min,
+    drop column if exists timezone;
@@ -0,0 +1,4 @@
+alter table users
+    add column if not exists timezone varchar(64) not null default 'UTC',
+    add column if not exists work_start_min smallint check (work_start_min between 0 and 1439),
+    add column if not exists work_end_min smallint check (work_end_min between 0 and 1439);
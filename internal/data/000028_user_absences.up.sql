@@ -0,0 +1,11 @@
+create table if not exists user_absences (
+    id bigserial primary key,
+    user_id varchar(64) not null references users(id) on delete cascade,
+    start_date date not null,
+    end_date date not null,
+    reason text not null default '',
+    created_at timestamp with time zone not null default now(),
+    check (end_date >= start_date)
+);
+
+create index if not exists user_absences_user_end_idx on user_absences (user_id, end_date);
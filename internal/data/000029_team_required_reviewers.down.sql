@@ -0,0 +1 @@
+drop table if exists team_required_reviewers;
@@ -0,0 +1,9 @@
+create table if not exists team_required_reviewers (
+    team_name varchar(64) not null references teams(name) on delete cascade,
+    position integer not null,
+    component varchar(128) not null default '',
+    path_prefix varchar(256) not null default '',
+    user_id varchar(64) not null references users(id) on delete cascade,
+    primary key (team_name, position),
+    check (component <> '' or path_prefix <> '')
+);
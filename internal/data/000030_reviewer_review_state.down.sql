@@ -0,0 +1,3 @@
+alter table pull_requests_reviewers
+    drop column if exists reviewed_at,
+    drop column if exists review_state;
@@ -0,0 +1,4 @@
+alter table pull_requests_reviewers
+    add column if not exists review_state varchar(32) not null default 'PENDING'
+        check (review_state in ('PENDING', 'APPROVED', 'CHANGES_REQUESTED')),
+    add column if not exists reviewed_at timestamptz;
@@ -0,0 +1,11 @@
+-- Postgres cannot drop an enum value, so CLOSED stays in pr_status. Closed
+-- pull requests go back to OPEN; their reviewers were never removed.
+update pull_requests
+set status = 'OPEN',
+    status_enum = case when status_enum is null then null else 'OPEN'::pr_status end
+where status = 'CLOSED';
+
+alter table pull_requests
+    drop constraint if exists pull_requests_status_check,
+    add constraint pull_requests_status_check check (status in ('OPEN', 'MERGED')),
+    drop column if exists closed_at;
@@ -0,0 +1,6 @@
+alter type pr_status add value if not exists 'CLOSED';
+
+alter table pull_requests
+    drop constraint if exists pull_requests_status_check,
+    add constraint pull_requests_status_check check (status in ('OPEN', 'MERGED', 'CLOSED')),
+    add column if not exists closed_at timestamptz;
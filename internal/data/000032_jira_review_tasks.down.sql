@@ -0,0 +1,3 @@
+drop index if exists jira_review_tasks_open_idx;
+
+drop table if exists jira_review_tasks;
@@ -0,0 +1,11 @@
+create table if not exists jira_review_tasks (
+    issue_key varchar(64) primary key,
+    pull_request_id varchar(64) not null references pull_requests(id) on delete cascade,
+    user_id varchar(64) not null,
+    created_at timestamp with time zone not null default now(),
+    done_at timestamp with time zone
+);
+
+create index if not exists jira_review_tasks_open_idx
+    on jira_review_tasks(pull_request_id, user_id)
+    where done_at is null;
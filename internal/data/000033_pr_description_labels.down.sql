@@ -0,0 +1,4 @@
+drop table if exists pr_labels;
+
+alter table pull_requests
+    drop column if exists description;
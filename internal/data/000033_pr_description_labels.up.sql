@@ -0,0 +1,11 @@
+alter table pull_requests
+    add column if not exists description text not null default '';
+
+create table if not exists pr_labels (
+    pull_request_id varchar(64) not null references pull_requests(id) on delete cascade,
+    label varchar(64) not null,
+    primary key (pull_request_id, label)
+);
+
+create index if not exists pr_labels_label_idx
+    on pr_labels(label, pull_request_id);
@@ -0,0 +1,3 @@
+alter table pull_requests
+    drop column if exists repository_url,
+    drop column if exists branch;
@@ -0,0 +1,3 @@
+alter table pull_requests
+    add column if not exists repository_url text not null default '',
+    add column if not exists branch varchar(255) not null default '';
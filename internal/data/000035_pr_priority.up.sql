@@ -0,0 +1,3 @@
+alter table pull_requests
+    add column if not exists priority varchar(16) not null default 'NORMAL'
+        check (priority in ('LOW', 'NORMAL', 'HIGH', 'URGENT'));
@@ -0,0 +1,2 @@
+drop index if exists request_nonces_expires_at_idx;
+drop table if exists request_nonces;
@@ -0,0 +1,9 @@
+create table if not exists request_nonces (
+    client_id varchar(64) not null,
+    nonce varchar(128) not null,
+    expires_at timestamp with time zone not null,
+    primary key (client_id, nonce)
+);
+
+create index if not exists request_nonces_expires_at_idx
+    on request_nonces(expires_at);
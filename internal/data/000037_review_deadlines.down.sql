@@ -0,0 +1,7 @@
+drop index if exists pull_requests_deadline_idx;
+
+alter table team_settings
+    drop column if exists review_sla_hours;
+
+alter table pull_requests
+    drop column if exists deadline;
@@ -0,0 +1,9 @@
+alter table pull_requests
+    add column if not exists deadline timestamp with time zone;
+
+alter table team_settings
+    add column if not exists review_sla_hours int not null default 0;
+
+create index if not exists pull_requests_deadline_idx
+    on pull_requests(deadline)
+    where deadline is not null;
@@ -0,0 +1,110 @@
+package data
+
+import (
+	"embed"
+	"fmt"
+	"io/fs"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+//go:embed *.sql
+var Migrations embed.FS
+
+type Schema struct {
+	Version int
+	Tables  map[string][]string
+	Indexes []string
+}
+
+var (
+	createTableRe  = regexp.MustCompile(`^create table if not exists (\w+) \((.*)\)$`)
+	dropTableRe    = regexp.MustCompile(`^drop table if exists (\w+)`)
+	alterTableRe   = regexp.MustCompile(`^alter table (\w+) (.*)$`)
+	addColumnRe    = regexp.MustCompile(`add column if not exists (\w+)`)
+	dropColumnRe   = regexp.MustCompile(`drop column if exists (\w+)`)
+	createIndexRe  = regexp.MustCompile(`^create (?:unique )?index if not exists (\w+)`)
+	dropIndexRe    = regexp.MustCompile(`^drop index if exists (\w+)`)
+	tableKeywordRe = regexp.MustCompile(`^(primary key|unique|constraint|foreign key|check)\b`)
+)
+
+func ExpectedSchema() (*Schema, error) {
+	names, err := fs.Glob(Migrations, "*.up.sql")
+	if err != nil {
+		return nil, fmt.Errorf("list migrations: %w", err)
+	}
+	slices.Sort(names)
+
+	schema := &Schema{Tables: make(map[string][]string)}
+	for _, name := range names {
+		version, err := strconv.Atoi(strings.SplitN(name, "_", 2)[0])
+		if err != nil {
+			return nil, fmt.Errorf("parse migration version of %s: %w", name, err)
+		}
+		schema.Version = max(schema.Version, version)
+
+		content, err := Migrations.ReadFile(name)
+		if err != nil {
+			return nil, fmt.Errorf("read migration %s: %w", name, err)
+		}
+		for _, stmt := range strings.Split(string(content), ";") {
+			schema.apply(strings.Join(strings.Fields(strings.ToLower(stmt)), " "))
+		}
+	}
+	return schema, nil
+}
+
+func (s *Schema) apply(stmt string) {
+	if m := createTableRe.FindStringSubmatch(stmt); m != nil {
+		var columns []string
+		for _, def := range splitTopLevel(m[2]) {
+			if def == "" || tableKeywordRe.MatchString(def) {
+				continue
+			}
+			columns = append(columns, strings.Fields(def)[0])
+		}
+		s.Tables[m[1]] = columns
+		return
+	}
+	if m := dropTableRe.FindStringSubmatch(stmt); m != nil {
+		delete(s.Tables, m[1])
+		return
+	}
+	if m := alterTableRe.FindStringSubmatch(stmt); m != nil {
+		for _, add := range addColumnRe.FindAllStringSubmatch(m[2], -1) {
+			s.Tables[m[1]] = append(s.Tables[m[1]], add[1])
+		}
+		for _, drop := range dropColumnRe.FindAllStringSubmatch(m[2], -1) {
+			s.Tables[m[1]] = slices.DeleteFunc(s.Tables[m[1]], func(c string) bool { return c == drop[1] })
+		}
+		return
+	}
+	if m := createIndexRe.FindStringSubmatch(stmt); m != nil {
+		s.Indexes = append(s.Indexes, m[1])
+		return
+	}
+	if m := dropIndexRe.FindStringSubmatch(stmt); m != nil {
+		s.Indexes = slices.DeleteFunc(s.Indexes, func(i string) bool { return i == m[1] })
+	}
+}
+
+func splitTopLevel(body string) []string {
+	var parts []string
+	depth, start := 0, 0
+	for i, r := range body {
+		switch r {
+		case '(':
+			depth++
+		case ')':
+			depth--
+		case ',':
+			if depth == 0 {
+				parts = append(parts, strings.TrimSpace(body[start:i]))
+				start = i + 1
+			}
+		}
+	}
+	return append(parts, strings.TrimSpace(body[start:]))
+}
@@ -0,0 +1,95 @@
+package data
+
+import (
+	"slices"
+	"testing"
+)
+
+func TestExpectedSchema(t *testing.T) {
+	schema, err := ExpectedSchema()
+	if err != nil {
+		t.Fatalf("ExpectedSchema returned err: %v", err)
+	}
+	if schema.Version < 37 {
+		t.Fatalf("unexpected version: %d", schema.Version)
+	}
+	if got := schema.Tables["users"]; !slices.Equal(got, []string{"id", "username", "team_name", "is_active", "review_weight", "version", "timezone", "work_start_min", "work_end_min"}) {
+		t.Fatalf("unexpected users columns: %v", got)
+	}
+	if got := schema.Tables["pull_requests_reviewers"]; !slices.Contains(got, "acknowledged_at") || !slices.Contains(got, "review_state") || slices.Contains(got, "primary") {
+		t.Fatalf("unexpected pull_requests_reviewers columns: %v", got)
+	}
+	if got := schema.Tables["assignment_anomalies"]; slices.Contains(got, "unique") || !slices.Contains(got, "explanation") {
+		t.Fatalf("unexpected assignment_anomalies columns: %v", got)
+	}
+	if got := schema.Tables["pull_requests"]; !slices.Contains(got, "status") || !slices.Contains(got, "closed_at") || !slices.Contains(got, "description") || !slices.Contains(got, "branch") || !slices.Contains(got, "priority") || !slices.Contains(got, "deadline") || slices.Contains(got, "status_id") {
+		t.Fatalf("unexpected pull_requests columns: %v", got)
+	}
+	if got := schema.Tables["outbox_events"]; !slices.Contains(got, "available_at") || !slices.Contains(got, "delivered_at") {
+		t.Fatalf("unexpected outbox_events columns: %v", got)
+	}
+	if got := schema.Tables["user_notification_settings"]; !slices.Equal(got, []string{"user_id", "digest_window_minutes"}) {
+		t.Fatalf("unexpected user_notification_settings columns: %v", got)
+	}
+	if _, ok := schema.Tables["statuses"]; ok {
+		t.Fatalf("statuses table should be dropped")
+	}
+	if got := schema.Tables["notifications"]; !slices.Contains(got, "dedup_key") || !slices.Contains(got, "last_error") {
+		t.Fatalf("unexpected notifications columns: %v", got)
+	}
+	if got := schema.Tables["sessions"]; !slices.Equal(got, []string{"id", "user_id", "csrf_token", "created_at", "expires_at"}) {
+		t.Fatalf("unexpected sessions columns: %v", got)
+	}
+	if got := schema.Tables["pr_labels"]; !slices.Equal(got, []string{"pull_request_id", "label"}) {
+		t.Fatalf("unexpected pr_labels columns: %v", got)
+	}
+	if got := schema.Tables["request_nonces"]; !slices.Equal(got, []string{"client_id", "nonce", "expires_at"}) {
+		t.Fatalf("unexpected request_nonces columns: %v", got)
+	}
+	if _, ok := schema.Tables["user_credentials"]; !ok {
+		t.Fatalf("expected user_credentials table")
+	}
+	if got := schema.Tables["user_identities"]; !slices.Contains(got, "subject") || slices.Contains(got, "primary") {
+		t.Fatalf("unexpected user_identities columns: %v", got)
+	}
+	if got := schema.Tables["pull_requests"]; !slices.Contains(got, "status") || !slices.Contains(got, "status_enum") {
+		t.Fatalf("expected status and status_enum columns, got %v", got)
+	}
+	if got := schema.Tables["integration_tokens"]; !slices.Contains(got, "token_encrypted") || !slices.Contains(got, "key_id") {
+		t.Fatalf("unexpected integration_tokens columns: %v", got)
+	}
+	if got := schema.Tables["assignment_snapshots"]; !slices.Contains(got, "reviewers") || !slices.Contains(got, "rotation_cursors") {
+		t.Fatalf("unexpected assignment_snapshots columns: %v", got)
+	}
+	if !slices.Contains(schema.Indexes, "audit_log_subject_created_idx") || !slices.Contains(schema.Indexes, "audit_log_actor_created_idx") {
+		t.Fatalf("expected audit_log indexes, got %v", schema.Indexes)
+	}
+	if !slices.Contains(schema.Indexes, "notifications_user_created_idx") {
+		t.Fatalf("expected notifications index in %v", schema.Indexes)
+	}
+	if !slices.Contains(schema.Indexes, "users_username_idx") {
+		t.Fatalf("expected username index in %v", schema.Indexes)
+	}
+	if !slices.Contains(schema.Indexes, "teams_name_lower_idx") {
+		t.Fatalf("expected teams name index in %v", schema.Indexes)
+	}
+	if !slices.Contains(schema.Indexes, "pull_requests_created_at_idx") {
+		t.Fatalf("expected created_at index in %v", schema.Indexes)
+	}
+	if got := schema.Tables["user_absences"]; !slices.Equal(got, []string{"id", "user_id", "start_date", "end_date", "reason", "created_at"}) {
+		t.Fatalf("unexpected user_absences columns: %v", got)
+	}
+	if got := schema.Tables["team_required_reviewers"]; !slices.Equal(got, []string{"team_name", "position", "component", "path_prefix", "user_id"}) {
+		t.Fatalf("unexpected team_required_reviewers columns: %v", got)
+	}
+}
+
+func TestSchema_ApplyDrops(t *testing.T) {
+	schema := &Schema{Tables: map[string][]string{"t": {"a", "b"}}, Indexes: []string{"t_idx"}}
+	schema.apply("alter table t drop column if exists b")
+	schema.apply("drop index if exists t_idx")
+
+	if !slices.Equal(schema.Tables["t"], []string{"a"}) || len(schema.Indexes) != 0 {
+		t.Fatalf("unexpected schema: %#v", schema)
+	}
+}
@@ -0,0 +1,169 @@
+package deprecation
+
+import (
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/cloudyy74/pr-reviewer-service/internal/models"
+)
+
+// OtherCallers collects usage once a deprecation has seen maxCallers
+// distinct callers, so a flood of one-off keys cannot grow memory unbounded.
+const OtherCallers = "other"
+
+const maxCallers = 1000
+
+// Rule marks a route, or a single field of it, as deprecated. Route is a
+// path optionally prefixed by a method ("GET /users/getReview"); Since and
+// Sunset are RFC 3339 timestamps or YYYY-MM-DD dates.
+type Rule struct {
+	Route  string
+	Field  string
+	Since  string
+	Sunset string
+	Link   string
+}
+
+type entry struct {
+	notice  models.DeprecationNotice
+	method  string
+	path    string
+	total   int64
+	last    time.Time
+	callers map[string]*models.DeprecationCaller
+}
+
+// Tracker matches requests against configured deprecations and counts how
+// often each caller still uses them.
+type Tracker struct {
+	mu      sync.Mutex
+	entries []*entry
+	now     func() time.Time
+}
+
+func New(rules []Rule) (*Tracker, error) {
+	t := &Tracker{now: time.Now}
+	for _, rule := range rules {
+		e, err := newEntry(rule)
+		if err != nil {
+			return nil, err
+		}
+		t.entries = append(t.entries, e)
+	}
+	return t, nil
+}
+
+func newEntry(rule Rule) (*entry, error) {
+	route := strings.TrimSpace(rule.Route)
+	method, path, ok := strings.Cut(route, " ")
+	if !ok {
+		method, path = "", route
+	}
+	path = strings.TrimSpace(path)
+	if !strings.HasPrefix(path, "/") {
+		return nil, fmt.Errorf("deprecation route %q must contain a path", rule.Route)
+	}
+	e := &entry{
+		notice: models.DeprecationNotice{
+			Route: route,
+			Field: strings.TrimSpace(rule.Field),
+			Link:  strings.TrimSpace(rule.Link),
+		},
+		method:  strings.ToUpper(method),
+		path:    path,
+		callers: make(map[string]*models.DeprecationCaller),
+	}
+	var err error
+	if e.notice.Since, err = parseDate(rule.Since); err != nil {
+		return nil, fmt.Errorf("deprecation %s since: %w", route, err)
+	}
+	if e.notice.Sunset, err = parseDate(rule.Sunset); err != nil {
+		return nil, fmt.Errorf("deprecation %s sunset: %w", route, err)
+	}
+	if e.notice.Since != nil && e.notice.Sunset != nil && e.notice.Sunset.Before(*e.notice.Since) {
+		return nil, fmt.Errorf("deprecation %s: sunset is before since", route)
+	}
+	return e, nil
+}
+
+func parseDate(raw string) (*time.Time, error) {
+	raw = strings.TrimSpace(raw)
+	if raw == "" {
+		return nil, nil
+	}
+	t, err := time.Parse(time.RFC3339, raw)
+	if err != nil {
+		if t, err = time.Parse(time.DateOnly, raw); err != nil {
+			return nil, errors.New("must be RFC3339 timestamp or YYYY-MM-DD date")
+		}
+	}
+	return &t, nil
+}
+
+// Use returns the deprecations matching the request and records the call
+// against caller for each of them.
+func (t *Tracker) Use(method, path, caller string) []models.DeprecationNotice {
+	var notices []models.DeprecationNotice
+	now := t.now()
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	for _, e := range t.entries {
+		if e.path != path || (e.method != "" && e.method != method) {
+			continue
+		}
+		notices = append(notices, e.notice)
+		e.total++
+		e.last = now
+		c, ok := e.callers[caller]
+		if !ok {
+			if len(e.callers) >= maxCallers {
+				caller = OtherCallers
+			}
+			if c, ok = e.callers[caller]; !ok {
+				c = &models.DeprecationCaller{Key: caller}
+				e.callers[caller] = c
+			}
+		}
+		c.Count++
+		c.LastUsedAt = now
+	}
+	return notices
+}
+
+// Report lists every configured deprecation with its usage, busiest callers
+// first.
+func (t *Tracker) Report() *models.DeprecationsResponse {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	resp := &models.DeprecationsResponse{Deprecations: make([]*models.DeprecationUsage, 0, len(t.entries))}
+	for _, e := range t.entries {
+		usage := &models.DeprecationUsage{
+			DeprecationNotice: e.notice,
+			Total:             e.total,
+			Callers:           make([]*models.DeprecationCaller, 0, len(e.callers)),
+		}
+		if e.total > 0 {
+			last := e.last
+			usage.LastUsedAt = &last
+		}
+		for _, c := range e.callers {
+			cp := *c
+			usage.Callers = append(usage.Callers, &cp)
+		}
+		slices.SortFunc(usage.Callers, func(a, b *models.DeprecationCaller) int {
+			if a.Count != b.Count {
+				if a.Count > b.Count {
+					return -1
+				}
+				return 1
+			}
+			return strings.Compare(a.Key, b.Key)
+		})
+		resp.Deprecations = append(resp.Deprecations, usage)
+	}
+	return resp
+}
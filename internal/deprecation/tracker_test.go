@@ -0,0 +1,69 @@
+package deprecation
+
+import (
+	"testing"
+	"time"
+)
+
+func TestTracker_UseMatchesRouteAndCountsCallers(t *testing.T) {
+	tr, err := New([]Rule{
+		{Route: "GET /users/getReview", Since: "2026-01-01", Sunset: "2026-06-01", Link: "https://docs/deprecations"},
+		{Route: "/pullRequest/create", Field: "assigned_reviewers"},
+	})
+	if err != nil {
+		t.Fatalf("New: %v", err)
+	}
+	now := time.Date(2026, 3, 1, 0, 0, 0, 0, time.UTC)
+	tr.now = func() time.Time { return now }
+
+	if got := tr.Use("POST", "/users/getReview", "a"); len(got) != 0 {
+		t.Fatalf("method must match, got %v", got)
+	}
+	tr.Use("GET", "/users/getReview", "a")
+	tr.Use("GET", "/users/getReview", "b")
+	notices := tr.Use("GET", "/users/getReview", "b")
+	if len(notices) != 1 || notices[0].Sunset == nil || notices[0].Link == "" {
+		t.Fatalf("unexpected notices %+v", notices)
+	}
+	if got := tr.Use("POST", "/pullRequest/create", "a"); len(got) != 1 || got[0].Field != "assigned_reviewers" {
+		t.Fatalf("expected field deprecation for any method, got %+v", got)
+	}
+
+	report := tr.Report()
+	if len(report.Deprecations) != 2 {
+		t.Fatalf("expected 2 deprecations, got %d", len(report.Deprecations))
+	}
+	first := report.Deprecations[0]
+	if first.Total != 3 || first.LastUsedAt == nil || !first.LastUsedAt.Equal(now) {
+		t.Fatalf("unexpected usage %+v", first)
+	}
+	if len(first.Callers) != 2 || first.Callers[0].Key != "b" || first.Callers[0].Count != 2 {
+		t.Fatalf("expected busiest caller first, got %+v", first.Callers)
+	}
+}
+
+func TestTracker_LimitsDistinctCallers(t *testing.T) {
+	tr, err := New([]Rule{{Route: "/ping"}})
+	if err != nil {
+		t.Fatalf("New: %v", err)
+	}
+	for i := range maxCallers + 5 {
+		tr.Use("GET", "/ping", string(rune('a'+i%26))+time.Duration(i).String())
+	}
+	callers := tr.Report().Deprecations[0].Callers
+	if len(callers) != maxCallers+1 || callers[0].Key != OtherCallers || callers[0].Count != 5 {
+		t.Fatalf("expected overflow bucket, got %d callers, first %+v", len(callers), callers[0])
+	}
+}
+
+func TestNew_RejectsInvalidRules(t *testing.T) {
+	for _, rule := range []Rule{
+		{Route: "GET"},
+		{Route: "/a", Sunset: "soon"},
+		{Route: "/a", Since: "2026-05-01", Sunset: "2026-01-01"},
+	} {
+		if _, err := New([]Rule{rule}); err == nil {
+			t.Fatalf("expected error for %+v", rule)
+		}
+	}
+}
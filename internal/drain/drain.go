@@ -0,0 +1,78 @@
+package drain
+
+import (
+	"errors"
+	"log/slog"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// Controller coordinates connection draining before shutdown: readiness is
+// reported as failed first, and Done fires only after the load balancer has
+// had delay to stop routing new requests to this instance.
+type Controller struct {
+	delay time.Duration
+	log   *slog.Logger
+	after func(time.Duration) <-chan time.Time
+
+	draining atomic.Bool
+	since    atomic.Pointer[time.Time]
+	once     sync.Once
+	done     chan struct{}
+}
+
+func New(delay time.Duration, log *slog.Logger) (*Controller, error) {
+	if delay < 0 {
+		return nil, errors.New("drain delay cannot be negative")
+	}
+	if log == nil {
+		return nil, errors.New("logger cannot be nil")
+	}
+	return &Controller{
+		delay: delay,
+		log:   log,
+		after: time.After,
+		done:  make(chan struct{}),
+	}, nil
+}
+
+// Start begins draining. It returns false if draining was already started,
+// so repeated signals or requests do not restart the delay.
+func (c *Controller) Start() bool {
+	started := false
+	c.once.Do(func() {
+		started = true
+		now := time.Now()
+		c.since.Store(&now)
+		c.draining.Store(true)
+		c.log.Info("draining connections", slog.Duration("delay", c.delay))
+		go func() {
+			<-c.after(c.delay)
+			close(c.done)
+		}()
+	})
+	return started
+}
+
+func (c *Controller) Draining() bool {
+	return c.draining.Load()
+}
+
+// Since reports when draining started, or the zero time if it has not.
+func (c *Controller) Since() time.Time {
+	if t := c.since.Load(); t != nil {
+		return *t
+	}
+	return time.Time{}
+}
+
+func (c *Controller) Delay() time.Duration {
+	return c.delay
+}
+
+// Done is closed once the de-registration delay has elapsed and the server
+// can be shut down.
+func (c *Controller) Done() <-chan struct{} {
+	return c.done
+}
@@ -0,0 +1,49 @@
+package drain
+
+import (
+	"io"
+	"log/slog"
+	"testing"
+	"time"
+)
+
+func TestController_Start(t *testing.T) {
+	c, err := New(time.Minute, slog.New(slog.NewTextHandler(io.Discard, nil)))
+	if err != nil {
+		t.Fatalf("New: %v", err)
+	}
+	fire := make(chan time.Time)
+	var delays []time.Duration
+	c.after = func(d time.Duration) <-chan time.Time {
+		delays = append(delays, d)
+		return fire
+	}
+
+	if c.Draining() {
+		t.Fatalf("expected not draining before Start")
+	}
+	if !c.Start() {
+		t.Fatalf("expected first Start to begin draining")
+	}
+	if c.Start() {
+		t.Fatalf("expected second Start to be ignored")
+	}
+	if !c.Draining() || c.Since().IsZero() {
+		t.Fatalf("expected draining state to be recorded")
+	}
+
+	select {
+	case <-c.Done():
+		t.Fatalf("expected Done to wait for the delay")
+	case <-time.After(10 * time.Millisecond):
+	}
+	fire <- time.Now()
+	select {
+	case <-c.Done():
+	case <-time.After(time.Second):
+		t.Fatalf("expected Done after the delay")
+	}
+	if len(delays) != 1 || delays[0] != time.Minute {
+		t.Fatalf("unexpected delays %v", delays)
+	}
+}
@@ -0,0 +1,35 @@
+package http
+
+import (
+	"errors"
+	"log/slog"
+	"net/http"
+
+	"github.com/cloudyy74/pr-reviewer-service/internal/models"
+)
+
+type AlertMonitor interface {
+	Alerts() []*models.Alert
+}
+
+func SetupAlertRoutes(mux *http.ServeMux, alerts AlertMonitor, log *slog.Logger) error {
+	if mux == nil {
+		return errors.New("mux cannot be nil")
+	}
+	if alerts == nil {
+		return errors.New("alert monitor cannot be nil")
+	}
+	if log == nil {
+		return errors.New("logger cannot be nil")
+	}
+	r := router{
+		alerts: alerts,
+		log:    log,
+	}
+	mux.HandleFunc("GET /admin/alerts", r.panicMiddleware(r.loggingMiddleware(r.getAlerts)))
+	return nil
+}
+
+func (rtr *router) getAlerts(w http.ResponseWriter, _ *http.Request) {
+	rtr.responseJSON(w, http.StatusOK, &models.AlertsResponse{Alerts: rtr.alerts.Alerts()})
+}
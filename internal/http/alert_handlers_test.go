@@ -0,0 +1,39 @@
+package http
+
+import (
+	"encoding/json"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/cloudyy74/pr-reviewer-service/internal/models"
+)
+
+type fakeAlertMonitor []*models.Alert
+
+func (f fakeAlertMonitor) Alerts() []*models.Alert {
+	return f
+}
+
+func TestGetAlerts(t *testing.T) {
+	mux := http.NewServeMux()
+	monitor := fakeAlertMonitor{{Rule: "too-many-open-prs", State: models.AlertFiring, Labels: map[string]string{"team": "backend"}, Value: 12}}
+	if err := SetupAlertRoutes(mux, monitor, slog.New(slog.NewTextHandler(io.Discard, nil))); err != nil {
+		t.Fatalf("SetupAlertRoutes returned err: %v", err)
+	}
+
+	rec := httptest.NewRecorder()
+	mux.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/admin/alerts", nil))
+	if rec.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d", rec.Code)
+	}
+	var resp models.AlertsResponse
+	if err := json.NewDecoder(rec.Body).Decode(&resp); err != nil {
+		t.Fatalf("failed to decode response: %v", err)
+	}
+	if len(resp.Alerts) != 1 || resp.Alerts[0].State != models.AlertFiring || resp.Alerts[0].Labels["team"] != "backend" {
+		t.Fatalf("unexpected alerts: %+v", resp.Alerts)
+	}
+}
@@ -0,0 +1,153 @@
+package http
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"log/slog"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/cloudyy74/pr-reviewer-service/internal/models"
+)
+
+const (
+	calendarFeedPath = "/users/calendar.ics"
+	icsTimeLayout    = "20060102T150405Z"
+	icsLineLimit     = 75
+)
+
+type CalendarFeedService interface {
+	CalendarFeed(ctx context.Context, userID string) (*models.CalendarFeed, error)
+}
+
+// SetupCalendarRoutes registers the per-user ICS feed of review deadlines and
+// the endpoint issuing its URL. Calendar apps cannot send headers, so the
+// feed is authorized by a long-lived signed token in the query string; tokens
+// must come from their own signer so they are not accepted as stream tokens.
+func SetupCalendarRoutes(mux *http.ServeMux, tokens StreamTokenService, verifier StreamTokenVerifier, feeds CalendarFeedService, log *slog.Logger) error {
+	if mux == nil {
+		return errors.New("mux cannot be nil")
+	}
+	if tokens == nil {
+		return errors.New("calendar token service cannot be nil")
+	}
+	if verifier == nil {
+		return errors.New("calendar token verifier cannot be nil")
+	}
+	if feeds == nil {
+		return errors.New("calendar feed service cannot be nil")
+	}
+	if log == nil {
+		return errors.New("logger cannot be nil")
+	}
+	r := router{
+		calendarTokens:   tokens,
+		calendarVerifier: verifier,
+		calendarFeeds:    feeds,
+		log:              log,
+	}
+	mux.HandleFunc("POST /auth/calendarToken", r.panicMiddleware(r.loggingMiddleware(r.issueCalendarToken)))
+	// The feed URL carries the token, so it is logged without the query.
+	mux.HandleFunc("GET "+calendarFeedPath, r.panicMiddleware(r.getCalendarFeed))
+	return nil
+}
+
+func (rtr *router) issueCalendarToken(w http.ResponseWriter, r *http.Request) {
+	var req models.StreamTokenRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		rtr.handleError(w, r, newResponseError(ErrCodeBadRequest, "bad json request"))
+		return
+	}
+	token, err := rtr.calendarTokens.IssueStreamToken(r.Context(), req.UserID)
+	if err != nil {
+		rtr.handleError(w, r, err)
+		return
+	}
+	rtr.responseJSON(w, http.StatusOK, &models.CalendarToken{
+		Token:     token.Token,
+		UserID:    token.UserID,
+		ExpiresAt: token.ExpiresAt,
+		FeedURL:   calendarFeedPath + "?" + url.Values{"token": {token.Token}}.Encode(),
+	})
+}
+
+func (rtr *router) getCalendarFeed(w http.ResponseWriter, r *http.Request) {
+	rtr.log.Info("request",
+		slog.String("method", r.Method),
+		slog.String("url", r.URL.Path),
+		slog.String("remote_addr", r.RemoteAddr),
+	)
+	token := strings.TrimSpace(r.URL.Query().Get("token"))
+	if token == "" {
+		rtr.handleError(w, r, newResponseError(ErrCodeUnauthorized, "calendar token is required"))
+		return
+	}
+	userID, err := rtr.calendarVerifier.Verify(token)
+	if err != nil {
+		rtr.handleError(w, r, err)
+		return
+	}
+	feed, err := rtr.calendarFeeds.CalendarFeed(r.Context(), userID)
+	if err != nil {
+		rtr.handleError(w, r, err)
+		return
+	}
+	w.Header().Set("Content-Type", "text/calendar; charset=utf-8")
+	w.Header().Set("Cache-Control", "no-store")
+	w.WriteHeader(http.StatusOK)
+	if _, err := w.Write([]byte(renderICS(feed, time.Now()))); err != nil {
+		rtr.log.Error("failed to write calendar feed", slog.Any("error", err))
+	}
+}
+
+// renderICS writes the feed as an iCalendar (RFC 5545) document.
+func renderICS(feed *models.CalendarFeed, now time.Time) string {
+	var b strings.Builder
+	line := func(name, value string) {
+		writeICSLine(&b, name+":"+value)
+	}
+	line("BEGIN", "VCALENDAR")
+	line("VERSION", "2.0")
+	line("PRODID", "-//pr-reviewer-service//review deadlines//EN")
+	line("CALSCALE", "GREGORIAN")
+	line("X-WR-CALNAME", escapeICS("Reviews of "+feed.UserID))
+	for _, e := range feed.Events {
+		line("BEGIN", "VEVENT")
+		line("UID", escapeICS(e.UID+"@pr-reviewer-service"))
+		line("DTSTAMP", now.UTC().Format(icsTimeLayout))
+		line("DTSTART", e.Start.UTC().Format(icsTimeLayout))
+		line("DTEND", e.End.UTC().Format(icsTimeLayout))
+		line("SUMMARY", escapeICS(e.Summary))
+		line("DESCRIPTION", escapeICS(e.Description))
+		line("END", "VEVENT")
+	}
+	line("END", "VCALENDAR")
+	return b.String()
+}
+
+var icsEscaper = strings.NewReplacer(`\`, `\\`, ";", `\;`, ",", `\,`, "\r\n", `\n`, "\n", `\n`, "\r", `\n`)
+
+func escapeICS(s string) string {
+	return icsEscaper.Replace(s)
+}
+
+// writeICSLine folds content lines longer than 75 octets without splitting
+// UTF-8 sequences.
+func writeICSLine(b *strings.Builder, s string) {
+	limit := icsLineLimit
+	for len(s) > limit {
+		cut := limit
+		for cut > 0 && s[cut]&0xC0 == 0x80 {
+			cut--
+		}
+		b.WriteString(s[:cut])
+		b.WriteString("\r\n ")
+		s = s[cut:]
+		limit = icsLineLimit - 1
+	}
+	b.WriteString(s)
+	b.WriteString("\r\n")
+}
@@ -0,0 +1,123 @@
+package http
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/cloudyy74/pr-reviewer-service/internal/models"
+)
+
+type fakeCalendarFeeds struct{}
+
+func (fakeCalendarFeeds) CalendarFeed(_ context.Context, userID string) (*models.CalendarFeed, error) {
+	due := time.Date(2026, 3, 2, 10, 0, 0, 0, time.UTC)
+	return &models.CalendarFeed{
+		UserID: userID,
+		Events: []*models.CalendarEvent{{
+			UID:         "pr1/" + userID,
+			Summary:     "Review due: fix, then ship; now",
+			Description: "line one\nline two",
+			Start:       due,
+			End:         due.Add(30 * time.Minute),
+		}},
+	}, nil
+}
+
+func newCalendarMux(t *testing.T) *http.ServeMux {
+	t.Helper()
+	mux := http.NewServeMux()
+	err := SetupCalendarRoutes(mux, fakeStreamTokens{}, fakeVerifier{"signed": "u1"}, fakeCalendarFeeds{},
+		slog.New(slog.NewTextHandler(io.Discard, nil)))
+	if err != nil {
+		t.Fatalf("SetupCalendarRoutes returned err: %v", err)
+	}
+	return mux
+}
+
+func TestIssueCalendarToken(t *testing.T) {
+	mux := newCalendarMux(t)
+	rec := httptest.NewRecorder()
+	mux.ServeHTTP(rec, httptest.NewRequest(http.MethodPost, "/auth/calendarToken", strings.NewReader(`{"user_id":"u1"}`)))
+	if rec.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d", rec.Code)
+	}
+	var resp models.CalendarToken
+	if err := json.NewDecoder(rec.Body).Decode(&resp); err != nil {
+		t.Fatalf("failed to decode response: %v", err)
+	}
+	if resp.UserID != "u1" || resp.FeedURL != "/users/calendar.ics?token=signed" {
+		t.Fatalf("unexpected response: %+v", resp)
+	}
+}
+
+func TestGetCalendarFeed(t *testing.T) {
+	mux := newCalendarMux(t)
+	rec := httptest.NewRecorder()
+	mux.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/users/calendar.ics?token=signed", nil))
+	if rec.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d", rec.Code)
+	}
+	if ct := rec.Header().Get("Content-Type"); !strings.HasPrefix(ct, "text/calendar") {
+		t.Fatalf("unexpected content type %q", ct)
+	}
+	body := rec.Body.String()
+	for _, want := range []string{
+		"BEGIN:VCALENDAR\r\n",
+		"UID:pr1/u1@pr-reviewer-service\r\n",
+		"DTSTART:20260302T100000Z\r\n",
+		"DTEND:20260302T103000Z\r\n",
+		`SUMMARY:Review due: fix\, then ship\; now` + "\r\n",
+		`DESCRIPTION:line one\nline two` + "\r\n",
+		"END:VCALENDAR\r\n",
+	} {
+		if !strings.Contains(body, want) {
+			t.Fatalf("feed misses %q:\n%s", want, body)
+		}
+	}
+
+	for name, target := range map[string]string{
+		"missing token": "/users/calendar.ics",
+		"forged token":  "/users/calendar.ics?token=forged",
+		"expired token": "/users/calendar.ics?token=expired",
+	} {
+		t.Run(name, func(t *testing.T) {
+			rec := httptest.NewRecorder()
+			mux.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, target, nil))
+			if rec.Code != http.StatusUnauthorized {
+				t.Fatalf("expected 401, got %d", rec.Code)
+			}
+		})
+	}
+}
+
+func TestWriteICSLine_Folds(t *testing.T) {
+	var b strings.Builder
+	writeICSLine(&b, "SUMMARY:"+strings.Repeat("ж", 60))
+	lines := strings.Split(strings.TrimSuffix(b.String(), "\r\n"), "\r\n")
+	if len(lines) < 2 {
+		t.Fatalf("expected folded line, got %q", b.String())
+	}
+	var unfolded strings.Builder
+	for i, l := range lines {
+		if len(l) > icsLineLimit {
+			t.Fatalf("line %d is %d octets", i, len(l))
+		}
+		if i > 0 {
+			if !strings.HasPrefix(l, " ") {
+				t.Fatalf("continuation line %d does not start with a space", i)
+			}
+			l = l[1:]
+		}
+		unfolded.WriteString(l)
+	}
+	if unfolded.String() != "SUMMARY:"+strings.Repeat("ж", 60) {
+		t.Fatalf("unfolded line differs: %q", unfolded.String())
+	}
+}
@@ -0,0 +1,55 @@
+package http
+
+import (
+	"fmt"
+	"log/slog"
+	"mime"
+	"net/http"
+	"strings"
+)
+
+const jsonContentType = "application/json"
+
+// RequireJSON rejects POST requests whose body is not declared as JSON with
+// 415 before any handler decodes it. A charset parameter is accepted when it
+// names UTF-8. Requests without a body, such as POST /admin/drain, may omit
+// Content-Type.
+func RequireJSON(next http.Handler, log *slog.Logger) http.Handler {
+	rtr := &router{log: log}
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost {
+			next.ServeHTTP(w, r)
+			return
+		}
+		if err := checkJSONContentType(r); err != nil {
+			rtr.handleError(w, r, err)
+			return
+		}
+		next.ServeHTTP(w, r)
+	})
+}
+
+func checkJSONContentType(r *http.Request) error {
+	raw := r.Header.Get("Content-Type")
+	if raw == "" {
+		if r.ContentLength == 0 {
+			return nil
+		}
+		return newResponseError(ErrCodeUnsupportedMediaType, "Content-Type is required, use "+jsonContentType)
+	}
+	mediaType, params, err := mime.ParseMediaType(raw)
+	if err != nil {
+		return newResponseError(ErrCodeUnsupportedMediaType, fmt.Sprintf("invalid Content-Type %q, use %s", raw, jsonContentType))
+	}
+	if mediaType != jsonContentType {
+		return newResponseError(ErrCodeUnsupportedMediaType, fmt.Sprintf("unsupported Content-Type %q, use %s", mediaType, jsonContentType))
+	}
+	if charset, ok := params["charset"]; ok && !isUTF8(charset) {
+		return newResponseError(ErrCodeUnsupportedMediaType, fmt.Sprintf("unsupported charset %q, use utf-8", charset))
+	}
+	return nil
+}
+
+func isUTF8(charset string) bool {
+	return strings.EqualFold(charset, "utf-8") || strings.EqualFold(charset, "utf8")
+}
@@ -0,0 +1,55 @@
+package http
+
+import (
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestRequireJSON(t *testing.T) {
+	handler := RequireJSON(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	}), slog.New(slog.NewTextHandler(io.Discard, nil)))
+
+	cases := []struct {
+		name        string
+		method      string
+		contentType string
+		body        string
+		want        int
+	}{
+		{"json", http.MethodPost, "application/json", `{}`, http.StatusOK},
+		{"json utf-8 charset", http.MethodPost, "application/json; charset=UTF-8", `{}`, http.StatusOK},
+		{"json quoted charset", http.MethodPost, `Application/JSON;charset="utf-8"`, `{}`, http.StatusOK},
+		{"empty body without type", http.MethodPost, "", "", http.StatusOK},
+		{"get is not checked", http.MethodGet, "text/plain", "", http.StatusOK},
+		{"missing type", http.MethodPost, "", `{}`, http.StatusUnsupportedMediaType},
+		{"form", http.MethodPost, "application/x-www-form-urlencoded", "a=b", http.StatusUnsupportedMediaType},
+		{"text", http.MethodPost, "text/plain", `{}`, http.StatusUnsupportedMediaType},
+		{"other charset", http.MethodPost, "application/json; charset=latin1", `{}`, http.StatusUnsupportedMediaType},
+		{"malformed", http.MethodPost, "application/json; charset", `{}`, http.StatusUnsupportedMediaType},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			var body io.Reader
+			if tc.body != "" {
+				body = strings.NewReader(tc.body)
+			}
+			req := httptest.NewRequest(tc.method, "/pullRequest/create", body)
+			if tc.contentType != "" {
+				req.Header.Set("Content-Type", tc.contentType)
+			}
+			rec := httptest.NewRecorder()
+			handler.ServeHTTP(rec, req)
+			if rec.Code != tc.want {
+				t.Fatalf("expected %d, got %d: %s", tc.want, rec.Code, rec.Body.String())
+			}
+			if tc.want == http.StatusUnsupportedMediaType && !strings.Contains(rec.Body.String(), ErrCodeUnsupportedMediaType) {
+				t.Fatalf("expected %s in body, got %s", ErrCodeUnsupportedMediaType, rec.Body.String())
+			}
+		})
+	}
+}
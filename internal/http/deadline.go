@@ -0,0 +1,94 @@
+package http
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+const (
+	headerRequestDeadline = "X-Request-Deadline"
+	headerRequestTimeout  = "X-Request-Timeout"
+	headerGRPCTimeout     = "Grpc-Timeout"
+)
+
+var errInvalidTimeout = errors.New("invalid timeout")
+
+var grpcTimeoutUnits = map[byte]time.Duration{
+	'H': time.Hour,
+	'M': time.Minute,
+	'S': time.Second,
+	'm': time.Millisecond,
+	'u': time.Microsecond,
+	'n': time.Nanosecond,
+}
+
+// RequestDeadline derives the request context deadline from the caller's
+// budget so that DB queries are cancelled once the caller has given up. An
+// absolute X-Request-Deadline (RFC 3339) wins over a relative timeout in
+// grpc-timeout format ("500m", "2S"). Without either header the request is
+// served as before.
+func RequestDeadline(next http.Handler, log *slog.Logger) http.Handler {
+	rtr := &router{log: log}
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		deadline, ok, err := requestDeadline(r, time.Now())
+		if err != nil {
+			rtr.handleError(w, r, newResponseError(ErrCodeBadRequest, err.Error()))
+			return
+		}
+		if !ok {
+			next.ServeHTTP(w, r)
+			return
+		}
+		if !deadline.After(time.Now()) {
+			rtr.handleError(w, r, context.DeadlineExceeded)
+			return
+		}
+		ctx, cancel := context.WithDeadline(r.Context(), deadline)
+		defer cancel()
+		next.ServeHTTP(w, r.WithContext(ctx))
+	})
+}
+
+func requestDeadline(r *http.Request, now time.Time) (time.Time, bool, error) {
+	if raw := r.Header.Get(headerRequestDeadline); raw != "" {
+		deadline, err := time.Parse(time.RFC3339Nano, raw)
+		if err != nil {
+			return time.Time{}, false, fmt.Errorf("%s must be an RFC 3339 timestamp", headerRequestDeadline)
+		}
+		return deadline, true, nil
+	}
+	for _, header := range []string{headerRequestTimeout, headerGRPCTimeout} {
+		raw := r.Header.Get(header)
+		if raw == "" {
+			continue
+		}
+		timeout, err := parseGRPCTimeout(raw)
+		if err != nil {
+			return time.Time{}, false, fmt.Errorf("%s: %w %q", header, err, raw)
+		}
+		return now.Add(timeout), true, nil
+	}
+	return time.Time{}, false, nil
+}
+
+// parseGRPCTimeout parses the grpc-timeout wire format: up to eight digits
+// followed by a single unit letter.
+func parseGRPCTimeout(raw string) (time.Duration, error) {
+	if len(raw) < 2 || len(raw) > 9 {
+		return 0, errInvalidTimeout
+	}
+	unit, ok := grpcTimeoutUnits[raw[len(raw)-1]]
+	if !ok {
+		return 0, errInvalidTimeout
+	}
+	value, err := strconv.ParseInt(raw[:len(raw)-1], 10, 64)
+	if err != nil || value < 0 {
+		return 0, errInvalidTimeout
+	}
+	return time.Duration(value) * unit, nil
+}
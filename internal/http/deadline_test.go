@@ -0,0 +1,101 @@
+package http
+
+import (
+	"context"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestParseGRPCTimeout(t *testing.T) {
+	cases := map[string]time.Duration{
+		"500m": 500 * time.Millisecond,
+		"2S":   2 * time.Second,
+		"1H":   time.Hour,
+		"10u":  10 * time.Microsecond,
+	}
+	for raw, want := range cases {
+		got, err := parseGRPCTimeout(raw)
+		if err != nil || got != want {
+			t.Fatalf("parseGRPCTimeout(%q) = %v, %v; want %v", raw, got, err, want)
+		}
+	}
+	for _, raw := range []string{"", "5", "5s", "-1S", "123456789S", "1.5S"} {
+		if _, err := parseGRPCTimeout(raw); err == nil {
+			t.Fatalf("expected error for %q", raw)
+		}
+	}
+}
+
+func TestRequestDeadline(t *testing.T) {
+	var got time.Time
+	var hasDeadline bool
+	handler := RequestDeadline(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		got, hasDeadline = r.Context().Deadline()
+		w.WriteHeader(http.StatusOK)
+	}), slog.New(slog.NewTextHandler(io.Discard, nil)))
+
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/ping", nil))
+	if rec.Code != http.StatusOK || hasDeadline {
+		t.Fatalf("expected no deadline without headers, got %d %v", rec.Code, hasDeadline)
+	}
+
+	req := httptest.NewRequest(http.MethodGet, "/ping", nil)
+	req.Header.Set("Grpc-Timeout", "2S")
+	start := time.Now()
+	rec = httptest.NewRecorder()
+	handler.ServeHTTP(rec, req)
+	if rec.Code != http.StatusOK || !hasDeadline || got.Sub(start) > 3*time.Second || got.Sub(start) < time.Second {
+		t.Fatalf("expected ~2s deadline, got %v (%v)", got.Sub(start), hasDeadline)
+	}
+
+	deadline := time.Now().Add(time.Minute).UTC().Truncate(time.Second)
+	req = httptest.NewRequest(http.MethodGet, "/ping", nil)
+	req.Header.Set("X-Request-Deadline", deadline.Format(time.RFC3339))
+	req.Header.Set("X-Request-Timeout", "1S")
+	rec = httptest.NewRecorder()
+	handler.ServeHTTP(rec, req)
+	if !got.Equal(deadline) {
+		t.Fatalf("expected absolute deadline %v to win, got %v", deadline, got)
+	}
+}
+
+func TestRequestDeadline_Rejects(t *testing.T) {
+	called := false
+	handler := RequestDeadline(http.HandlerFunc(func(http.ResponseWriter, *http.Request) {
+		called = true
+	}), slog.New(slog.NewTextHandler(io.Discard, nil)))
+
+	req := httptest.NewRequest(http.MethodGet, "/ping", nil)
+	req.Header.Set("X-Request-Timeout", "soon")
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, req)
+	if rec.Code != http.StatusBadRequest {
+		t.Fatalf("expected 400 for malformed timeout, got %d", rec.Code)
+	}
+
+	req = httptest.NewRequest(http.MethodGet, "/ping", nil)
+	req.Header.Set("X-Request-Deadline", time.Now().Add(-time.Second).Format(time.RFC3339Nano))
+	rec = httptest.NewRecorder()
+	handler.ServeHTTP(rec, req)
+	if rec.Code != http.StatusGatewayTimeout {
+		t.Fatalf("expected 504 for expired deadline, got %d", rec.Code)
+	}
+	if called {
+		t.Fatalf("expected handler not to run")
+	}
+}
+
+func TestMapError_DeadlineExceeded(t *testing.T) {
+	rtr := &router{log: slog.New(slog.NewTextHandler(io.Discard, nil))}
+	ctx, cancel := context.WithTimeout(context.Background(), 0)
+	defer cancel()
+	<-ctx.Done()
+	if got := rtr.mapError(ctx.Err()); got.Code != ErrCodeDeadline {
+		t.Fatalf("expected %s, got %s", ErrCodeDeadline, got.Code)
+	}
+}
@@ -0,0 +1,93 @@
+package http
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"strings"
+
+	"github.com/cloudyy74/pr-reviewer-service/internal/models"
+)
+
+type DeprecationTracker interface {
+	Use(method, path, caller string) []models.DeprecationNotice
+	Report() *models.DeprecationsResponse
+}
+
+func SetupDeprecationRoutes(mux *http.ServeMux, tracker DeprecationTracker, log *slog.Logger) error {
+	if mux == nil {
+		return errors.New("mux cannot be nil")
+	}
+	if tracker == nil {
+		return errors.New("deprecation tracker cannot be nil")
+	}
+	if log == nil {
+		return errors.New("logger cannot be nil")
+	}
+	r := router{
+		deprecations: tracker,
+		log:          log,
+	}
+	mux.HandleFunc("GET /admin/deprecations", r.panicMiddleware(r.loggingMiddleware(r.getDeprecations)))
+	return nil
+}
+
+func (rtr *router) getDeprecations(w http.ResponseWriter, _ *http.Request) {
+	rtr.responseJSON(w, http.StatusOK, rtr.deprecations.Report())
+}
+
+// Deprecations announces deprecated routes and fields with Deprecation,
+// Sunset and Link headers and counts their use per caller, so removals can
+// be planned against real traffic.
+func Deprecations(next http.Handler, tracker DeprecationTracker) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		notices := tracker.Use(r.Method, r.URL.Path, callerKey(r))
+		for _, n := range notices {
+			setDeprecationHeaders(w.Header(), n)
+		}
+		next.ServeHTTP(w, r)
+	})
+}
+
+func setDeprecationHeaders(h http.Header, n models.DeprecationNotice) {
+	if h.Get("Deprecation") == "" {
+		if n.Since != nil {
+			h.Set("Deprecation", fmt.Sprintf("@%d", n.Since.Unix()))
+		} else {
+			h.Set("Deprecation", "true")
+		}
+	}
+	if n.Sunset != nil {
+		if cur, err := http.ParseTime(h.Get("Sunset")); err != nil || n.Sunset.Before(cur) {
+			h.Set("Sunset", n.Sunset.UTC().Format(http.TimeFormat))
+		}
+	}
+	if n.Link != "" {
+		h.Add("Link", fmt.Sprintf("<%s>; rel=\"deprecation\"", n.Link))
+	}
+	if n.Field != "" {
+		h.Add("X-Deprecated-Fields", n.Field)
+	}
+}
+
+// callerKey identifies who is calling without keeping their credentials:
+// API keys and bearer tokens are reduced to a short fingerprint.
+func callerKey(r *http.Request) string {
+	key := strings.TrimSpace(r.Header.Get("X-Api-Key"))
+	if key == "" {
+		if token, ok := strings.CutPrefix(r.Header.Get("Authorization"), "Bearer "); ok {
+			key = strings.TrimSpace(token)
+		}
+	}
+	if key != "" {
+		sum := sha256.Sum256([]byte(key))
+		return "key:" + hex.EncodeToString(sum[:6])
+	}
+	if user, ok := UserFromContext(r.Context()); ok {
+		return "user:" + user.ID
+	}
+	return "anonymous"
+}
@@ -0,0 +1,91 @@
+package http
+
+import (
+	"encoding/json"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/cloudyy74/pr-reviewer-service/internal/models"
+)
+
+type fakeDeprecationTracker struct {
+	notices []models.DeprecationNotice
+	callers []string
+}
+
+func (f *fakeDeprecationTracker) Use(method, path, caller string) []models.DeprecationNotice {
+	if method != http.MethodGet || path != "/old" {
+		return nil
+	}
+	f.callers = append(f.callers, caller)
+	return f.notices
+}
+
+func (f *fakeDeprecationTracker) Report() *models.DeprecationsResponse {
+	return &models.DeprecationsResponse{Deprecations: []*models.DeprecationUsage{{
+		DeprecationNotice: f.notices[0],
+		Total:             int64(len(f.callers)),
+	}}}
+}
+
+func TestDeprecations_SetsHeadersAndCountsCaller(t *testing.T) {
+	since := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+	sunset := time.Date(2026, 7, 1, 0, 0, 0, 0, time.UTC)
+	tracker := &fakeDeprecationTracker{notices: []models.DeprecationNotice{{
+		Route:  "GET /old",
+		Since:  &since,
+		Sunset: &sunset,
+		Link:   "https://docs/old",
+	}}}
+	handler := Deprecations(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		w.WriteHeader(http.StatusNoContent)
+	}), tracker)
+
+	req := httptest.NewRequest(http.MethodGet, "/old", nil)
+	req.Header.Set("Authorization", "Bearer secret-token")
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, req)
+
+	if got := rec.Header().Get("Deprecation"); got != "@1767225600" {
+		t.Fatalf("unexpected Deprecation header %q", got)
+	}
+	if got := rec.Header().Get("Sunset"); got != "Wed, 01 Jul 2026 00:00:00 GMT" {
+		t.Fatalf("unexpected Sunset header %q", got)
+	}
+	if got := rec.Header().Get("Link"); got != `<https://docs/old>; rel="deprecation"` {
+		t.Fatalf("unexpected Link header %q", got)
+	}
+	if len(tracker.callers) != 1 || tracker.callers[0] == "anonymous" || tracker.callers[0] == "key:secret-token" {
+		t.Fatalf("expected fingerprinted caller, got %v", tracker.callers)
+	}
+
+	rec = httptest.NewRecorder()
+	handler.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/fresh", nil))
+	if rec.Header().Get("Deprecation") != "" {
+		t.Fatalf("unexpected Deprecation header on a current route")
+	}
+}
+
+func TestGetDeprecations(t *testing.T) {
+	mux := http.NewServeMux()
+	tracker := &fakeDeprecationTracker{notices: []models.DeprecationNotice{{Route: "GET /old"}}}
+	if err := SetupDeprecationRoutes(mux, tracker, slog.New(slog.NewTextHandler(io.Discard, nil))); err != nil {
+		t.Fatalf("SetupDeprecationRoutes returned err: %v", err)
+	}
+	rec := httptest.NewRecorder()
+	mux.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/admin/deprecations", nil))
+	if rec.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d", rec.Code)
+	}
+	var resp models.DeprecationsResponse
+	if err := json.NewDecoder(rec.Body).Decode(&resp); err != nil {
+		t.Fatalf("decode response: %v", err)
+	}
+	if len(resp.Deprecations) != 1 || resp.Deprecations[0].Route != "GET /old" || resp.Deprecations[0].Callers == nil {
+		t.Fatalf("unexpected response %+v", resp)
+	}
+}
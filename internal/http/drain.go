@@ -0,0 +1,77 @@
+package http
+
+import (
+	"errors"
+	"log/slog"
+	"net/http"
+	"time"
+
+	"github.com/cloudyy74/pr-reviewer-service/internal/models"
+)
+
+type Drainer interface {
+	Start() bool
+	Draining() bool
+	Since() time.Time
+	Delay() time.Duration
+}
+
+func SetupDrainRoutes(mux *http.ServeMux, drainer Drainer, log *slog.Logger) error {
+	if mux == nil {
+		return errors.New("mux cannot be nil")
+	}
+	if drainer == nil {
+		return errors.New("drainer cannot be nil")
+	}
+	if log == nil {
+		return errors.New("logger cannot be nil")
+	}
+	r := router{
+		drainer: drainer,
+		log:     log,
+	}
+	mux.HandleFunc("GET /admin/drain", r.panicMiddleware(r.loggingMiddleware(r.getDrainStatus)))
+	mux.HandleFunc("POST /admin/drain", r.panicMiddleware(r.loggingMiddleware(r.startDrain)))
+	return nil
+}
+
+func (rtr *router) startDrain(w http.ResponseWriter, r *http.Request) {
+	if rtr.drainer.Start() {
+		rtr.log.Warn("drain requested", slog.String("remote_addr", r.RemoteAddr))
+	}
+	rtr.responseJSON(w, http.StatusAccepted, drainStatus(rtr.drainer))
+}
+
+func (rtr *router) getDrainStatus(w http.ResponseWriter, _ *http.Request) {
+	rtr.responseJSON(w, http.StatusOK, drainStatus(rtr.drainer))
+}
+
+func drainStatus(d Drainer) models.DrainStatus {
+	status := models.DrainStatus{
+		Draining:     d.Draining(),
+		DelaySeconds: d.Delay().Seconds(),
+	}
+	if since := d.Since(); !since.IsZero() {
+		status.Since = &since
+	}
+	return status
+}
+
+// DrainAware fails readiness once draining has started so the load balancer
+// de-registers the instance, and asks keep-alive clients to reconnect
+// elsewhere while in-flight requests are still served.
+func DrainAware(next http.Handler, drainer Drainer, log *slog.Logger) http.Handler {
+	rtr := &router{log: log}
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if !drainer.Draining() {
+			next.ServeHTTP(w, r)
+			return
+		}
+		w.Header().Set("Connection", "close")
+		if r.URL.Path == "/ready" {
+			rtr.responseJSON(w, http.StatusServiceUnavailable, models.PingResponse{Status: "unavailable", Message: "draining"})
+			return
+		}
+		next.ServeHTTP(w, r)
+	})
+}
@@ -0,0 +1,74 @@
+package http
+
+import (
+	"encoding/json"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/cloudyy74/pr-reviewer-service/internal/models"
+)
+
+type fakeDrainer struct {
+	draining bool
+	since    time.Time
+}
+
+func (f *fakeDrainer) Start() bool {
+	if f.draining {
+		return false
+	}
+	f.draining = true
+	f.since = time.Now()
+	return true
+}
+
+func (f *fakeDrainer) Draining() bool       { return f.draining }
+func (f *fakeDrainer) Since() time.Time     { return f.since }
+func (f *fakeDrainer) Delay() time.Duration { return 15 * time.Second }
+
+func TestDrain_FlipsReadiness(t *testing.T) {
+	log := slog.New(slog.NewTextHandler(io.Discard, nil))
+	drainer := &fakeDrainer{}
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /ready", func(w http.ResponseWriter, _ *http.Request) { w.WriteHeader(http.StatusOK) })
+	mux.HandleFunc("GET /ping", func(w http.ResponseWriter, _ *http.Request) { w.WriteHeader(http.StatusOK) })
+	if err := SetupDrainRoutes(mux, drainer, log); err != nil {
+		t.Fatalf("SetupDrainRoutes returned err: %v", err)
+	}
+	handler := DrainAware(mux, drainer, log)
+
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/ready", nil))
+	if rec.Code != http.StatusOK {
+		t.Fatalf("expected ready before drain, got %d", rec.Code)
+	}
+
+	rec = httptest.NewRecorder()
+	handler.ServeHTTP(rec, httptest.NewRequest(http.MethodPost, "/admin/drain", nil))
+	if rec.Code != http.StatusAccepted {
+		t.Fatalf("expected 202, got %d", rec.Code)
+	}
+	var status models.DrainStatus
+	if err := json.NewDecoder(rec.Body).Decode(&status); err != nil {
+		t.Fatalf("failed to decode response: %v", err)
+	}
+	if !status.Draining || status.Since == nil || status.DelaySeconds != 15 {
+		t.Fatalf("unexpected status %+v", status)
+	}
+
+	rec = httptest.NewRecorder()
+	handler.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/ready", nil))
+	if rec.Code != http.StatusServiceUnavailable {
+		t.Fatalf("expected 503 while draining, got %d", rec.Code)
+	}
+
+	rec = httptest.NewRecorder()
+	handler.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/ping", nil))
+	if rec.Code != http.StatusOK || rec.Header().Get("Connection") != "close" {
+		t.Fatalf("expected requests to be served with Connection: close, got %d %q", rec.Code, rec.Header().Get("Connection"))
+	}
+}
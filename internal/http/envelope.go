@@ -0,0 +1,74 @@
+package http
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/cloudyy74/pr-reviewer-service/internal/models"
+)
+
+const (
+	headerRequestID        = "X-Request-Id"
+	headerResponseEnvelope = "X-Response-Envelope"
+	maxRequestIDLength     = 128
+)
+
+type requestMetaCtxKey struct{}
+
+type requestMeta struct {
+	id       string
+	envelope bool
+}
+
+type paginated interface {
+	Page() *models.Pagination
+}
+
+// ResponseEnvelope tags every request with an id, echoed in X-Request-Id,
+// and decides whether list endpoints wrap their body into the standard
+// envelope. enabled is the server default; a client may override it per
+// request with X-Response-Envelope, so existing consumers keep the legacy
+// shape until they opt in.
+func ResponseEnvelope(next http.Handler, enabled bool) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		meta := requestMeta{id: requestID(r), envelope: enabled}
+		if raw := r.Header.Get(headerResponseEnvelope); raw != "" {
+			if v, err := strconv.ParseBool(raw); err == nil {
+				meta.envelope = v
+			}
+		}
+		w.Header().Set(headerRequestID, meta.id)
+		next.ServeHTTP(w, r.WithContext(context.WithValue(r.Context(), requestMetaCtxKey{}, meta)))
+	})
+}
+
+func requestID(r *http.Request) string {
+	if id := strings.TrimSpace(r.Header.Get(headerRequestID)); id != "" && len(id) <= maxRequestIDLength {
+		return id
+	}
+	var b [16]byte
+	_, _ = rand.Read(b[:])
+	return hex.EncodeToString(b[:])
+}
+
+// responseList writes a list endpoint response, enveloped when the request
+// asked for it. Pagination is lifted into meta for responses that page.
+func (rtr *router) responseList(w http.ResponseWriter, r *http.Request, statusCode int, response any) {
+	meta, _ := r.Context().Value(requestMetaCtxKey{}).(requestMeta)
+	if !meta.envelope {
+		rtr.responseJSON(w, statusCode, response)
+		return
+	}
+	env := &models.Envelope{
+		Data: response,
+		Meta: models.EnvelopeMeta{RequestID: meta.id},
+	}
+	if p, ok := response.(paginated); ok {
+		env.Meta.Pagination = p.Page()
+	}
+	rtr.responseJSON(w, statusCode, env)
+}
@@ -0,0 +1,81 @@
+package http
+
+import (
+	"encoding/json"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestResponseEnvelope_OptIn(t *testing.T) {
+	mux := http.NewServeMux()
+	if err := SetupNotificationRoutes(mux, &fakeNotificationLog{}, slog.New(slog.NewTextHandler(io.Discard, nil))); err != nil {
+		t.Fatalf("SetupNotificationRoutes returned err: %v", err)
+	}
+	handler := ResponseEnvelope(mux, false)
+
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/admin/notifications", nil))
+	var legacy map[string]json.RawMessage
+	if err := json.NewDecoder(rec.Body).Decode(&legacy); err != nil {
+		t.Fatalf("decode legacy response: %v", err)
+	}
+	if _, ok := legacy["notifications"]; !ok {
+		t.Fatalf("expected legacy body, got %v", legacy)
+	}
+	if rec.Header().Get(headerRequestID) == "" {
+		t.Fatalf("expected generated request id")
+	}
+
+	req := httptest.NewRequest(http.MethodGet, "/admin/notifications", nil)
+	req.Header.Set(headerResponseEnvelope, "true")
+	req.Header.Set(headerRequestID, "req-1")
+	rec = httptest.NewRecorder()
+	handler.ServeHTTP(rec, req)
+	var env struct {
+		Data struct {
+			Notifications []json.RawMessage `json:"notifications"`
+		} `json:"data"`
+		Meta struct {
+			RequestID  string `json:"request_id"`
+			Pagination *struct {
+				Total int `json:"total"`
+			} `json:"pagination"`
+		} `json:"meta"`
+		Warnings []string `json:"warnings"`
+	}
+	if err := json.NewDecoder(rec.Body).Decode(&env); err != nil {
+		t.Fatalf("decode envelope: %v", err)
+	}
+	if env.Meta.RequestID != "req-1" || rec.Header().Get(headerRequestID) != "req-1" {
+		t.Fatalf("expected request id to be propagated, got %q", env.Meta.RequestID)
+	}
+	if env.Meta.Pagination == nil || env.Meta.Pagination.Total != 1 || len(env.Data.Notifications) != 1 {
+		t.Fatalf("unexpected envelope %+v", env)
+	}
+	if env.Warnings == nil {
+		t.Fatalf("expected warnings to be an empty array")
+	}
+}
+
+func TestResponseEnvelope_DefaultOnCanBeDisabled(t *testing.T) {
+	mux := http.NewServeMux()
+	if err := SetupNotificationRoutes(mux, &fakeNotificationLog{}, slog.New(slog.NewTextHandler(io.Discard, nil))); err != nil {
+		t.Fatalf("SetupNotificationRoutes returned err: %v", err)
+	}
+	handler := ResponseEnvelope(mux, true)
+
+	req := httptest.NewRequest(http.MethodGet, "/admin/notifications", nil)
+	req.Header.Set(headerResponseEnvelope, "false")
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, req)
+	var body map[string]json.RawMessage
+	if err := json.NewDecoder(rec.Body).Decode(&body); err != nil {
+		t.Fatalf("decode response: %v", err)
+	}
+	if _, ok := body["data"]; ok {
+		t.Fatalf("expected legacy body, got envelope")
+	}
+}
@@ -1,13 +1,226 @@
 package http
 
+import (
+	"context"
+	"net/http"
+
+	"github.com/cloudyy74/pr-reviewer-service/internal/chaos"
+	"github.com/cloudyy74/pr-reviewer-service/internal/oidc"
+	"github.com/cloudyy74/pr-reviewer-service/internal/reqsign"
+	"github.com/cloudyy74/pr-reviewer-service/internal/service"
+	"github.com/cloudyy74/pr-reviewer-service/internal/streamtoken"
+)
+
 const (
-	ErrCodeBadRequest  = "BAD_REQUEST"
-	ErrCodeInternal    = "INTERNAL"
-	ErrCodeValidation  = "VALIDATION"
-	ErrCodeNotFound    = "NOT_FOUND"
-	ErrCodePRExists    = "PR_EXISTS"
-	ErrCodePRMerged    = "PR_MERGED"
-	ErrCodeNotAssigned = "NOT_ASSIGNED"
-	ErrCodeNoCandidate = "NO_CANDIDATE"
-	ErrCodeTeamExists  = "TEAM_EXISTS"
+	ErrCodeBadRequest    = "BAD_REQUEST"
+	ErrCodeInternal      = "INTERNAL"
+	ErrCodeValidation    = "VALIDATION"
+	ErrCodeNotFound      = "NOT_FOUND"
+	ErrCodePRExists      = "PR_EXISTS"
+	ErrCodePRMerged      = "PR_MERGED"
+	ErrCodePRClosed      = "PR_CLOSED"
+	ErrCodeNotAssigned   = "NOT_ASSIGNED"
+	ErrCodeAssigned      = "ALREADY_ASSIGNED"
+	ErrCodeNoCandidate   = "NO_CANDIDATE"
+	ErrCodeTeamExists    = "TEAM_EXISTS"
+	ErrCodePRDuplicate   = "PR_DUPLICATE"
+	ErrCodeStatusMissing = "STATUS_MISSING"
+	ErrCodeUsernameTaken = "USERNAME_TAKEN"
+	ErrCodeAmbiguousUser = "AMBIGUOUS_USER"
+	ErrCodeOverloaded    = "OVERLOADED"
+	ErrCodeUnauthorized  = "UNAUTHORIZED"
+	ErrCodeForbidden     = "FORBIDDEN"
+	ErrCodeDeadline      = "DEADLINE_EXCEEDED"
+	ErrCodeOverCapacity  = "OVER_CAPACITY"
+	ErrCodeStalePreview  = "STALE_PREVIEW"
+	ErrCodeConflict      = "CONFLICT"
+	ErrCodeNotApproved   = "NOT_APPROVED"
+
+	ErrCodeUnsupportedMediaType = "UNSUPPORTED_MEDIA_TYPE"
+	ErrCodeMethodNotAllowed     = "METHOD_NOT_ALLOWED"
 )
+
+type errorCodeSpec struct {
+	code        string
+	status      int
+	description string
+	retryable   bool
+	// message replaces the wrapped error text in responses when set.
+	message string
+	errs    []error
+}
+
+var errorCatalog = []errorCodeSpec{
+	{
+		code:        ErrCodeBadRequest,
+		status:      http.StatusBadRequest,
+		description: "request body or parameters cannot be parsed",
+	},
+	{
+		code:        ErrCodeMethodNotAllowed,
+		status:      http.StatusMethodNotAllowed,
+		description: "path exists but does not accept this method, see the Allow header",
+	},
+	{
+		code:        ErrCodeUnsupportedMediaType,
+		status:      http.StatusUnsupportedMediaType,
+		description: "POST body is not sent as application/json in UTF-8",
+	},
+	{
+		code:        ErrCodeValidation,
+		status:      http.StatusBadRequest,
+		description: "request is well-formed but fails validation",
+		errs: []error{
+			service.ErrTeamValidation, service.ErrPRValidation, service.ErrUserValidation,
+			service.ErrStatsValidation, service.ErrNotificationValidation, service.ErrAuthValidation,
+			service.ErrIntegrationValidation, service.ErrExclusionValidation, service.ErrSnapshotValidation,
+			service.ErrEventLogValidation,
+			chaos.ErrInvalidConfig,
+		},
+	},
+	{
+		code:        ErrCodeTeamExists,
+		status:      http.StatusBadRequest,
+		description: "team with this name already exists",
+		message:     "team_name already exists",
+		errs:        []error{service.ErrTeamExists},
+	},
+	{
+		code:        ErrCodeNotFound,
+		status:      http.StatusNotFound,
+		description: "referenced team, user or pull request does not exist",
+		message:     "resource not found",
+		errs: []error{
+			service.ErrTeamNotFound, service.ErrPRTeamNotFound, service.ErrPRAuthorNotFound,
+			service.ErrPRNotFound, service.ErrUserNotFound, service.ErrExclusionNotFound,
+			service.ErrSnapshotNotFound, service.ErrAbsenceNotFound,
+		},
+	},
+	{
+		code:        ErrCodePRExists,
+		status:      http.StatusConflict,
+		description: "pull request with this id already exists; for an idempotent create, with a different payload",
+		errs:        []error{service.ErrPRAlreadyExists},
+	},
+	{
+		code:        ErrCodePRDuplicate,
+		status:      http.StatusConflict,
+		description: "pull request looks like a duplicate of an open one and the team rejects duplicates",
+		errs:        []error{service.ErrPRDuplicate},
+	},
+	{
+		code:        ErrCodePRMerged,
+		status:      http.StatusConflict,
+		description: "pull request is already merged and cannot be changed",
+		message:     "cannot reassign on merged PR",
+		errs:        []error{service.ErrPRMerged},
+	},
+	{
+		code:        ErrCodePRClosed,
+		status:      http.StatusConflict,
+		description: "pull request is closed without merging and cannot be changed",
+		message:     "cannot change a closed PR",
+		errs:        []error{service.ErrPRClosed},
+	},
+	{
+		code:        ErrCodeNotAssigned,
+		status:      http.StatusConflict,
+		description: "user is not assigned as a reviewer of the pull request",
+		message:     "reviewer is not assigned to this PR",
+		errs:        []error{service.ErrReviewerNotAssigned},
+	},
+	{
+		code:        ErrCodeAssigned,
+		status:      http.StatusConflict,
+		description: "user is already assigned as a reviewer of the pull request",
+		message:     "reviewer is already assigned to this PR",
+		errs:        []error{service.ErrReviewerAlreadyAssigned},
+	},
+	{
+		code:        ErrCodeNotApproved,
+		status:      http.StatusConflict,
+		description: "pull request cannot be merged until every reviewer approves it",
+		errs:        []error{service.ErrPRNotApproved},
+	},
+	{
+		code:        ErrCodeNoCandidate,
+		status:      http.StatusConflict,
+		description: "no active teammate can take the review",
+		retryable:   true,
+		message:     "no active replacement candidate in team",
+		errs:        []error{service.ErrNoReplacement},
+	},
+	{
+		code:        ErrCodeUsernameTaken,
+		status:      http.StatusConflict,
+		description: "username already belongs to another user",
+		errs:        []error{service.ErrUsernameTaken},
+	},
+	{
+		code:        ErrCodeAmbiguousUser,
+		status:      http.StatusConflict,
+		description: "username matches several users, pass user_id instead",
+		errs:        []error{service.ErrUsernameAmbiguous},
+	},
+	{
+		code:        ErrCodeOverCapacity,
+		status:      http.StatusConflict,
+		description: "target reviewer would exceed the open review capacity, pass force to override",
+		errs:        []error{service.ErrReviewerOverloaded},
+	},
+	{
+		code:        ErrCodeConflict,
+		status:      http.StatusConflict,
+		description: "user was changed by another request since the given version was read, fetch it and retry",
+		errs:        []error{service.ErrUserConflict},
+	},
+	{
+		code:        ErrCodeStalePreview,
+		status:      http.StatusConflict,
+		description: "data changed since the bulk operation was previewed, preview it again for a fresh change_set_token",
+		errs:        []error{service.ErrStalePreview},
+	},
+	{
+		code:        ErrCodeStatusMissing,
+		status:      http.StatusInternalServerError,
+		description: "pull request status is rejected by the database schema",
+		errs:        []error{service.ErrPRStatusMissing},
+	},
+	{
+		code:        ErrCodeUnauthorized,
+		status:      http.StatusUnauthorized,
+		description: "credentials, session, ID token, stream token or request signature are missing, invalid or expired",
+		errs: []error{
+			streamtoken.ErrInvalidToken, streamtoken.ErrExpiredToken,
+			service.ErrInvalidCredentials, service.ErrSessionInvalid,
+			oidc.ErrInvalidToken, oidc.ErrExpiredToken,
+			reqsign.ErrUnknownClient, reqsign.ErrInvalidSignature, reqsign.ErrStaleSignature, reqsign.ErrReplayedSignature,
+		},
+	},
+	{
+		code:        ErrCodeForbidden,
+		status:      http.StatusForbidden,
+		description: "token or session does not allow this action, or CSRF token is missing",
+		errs:        []error{service.ErrIdentityNotLinked, service.ErrImpersonationForbidden},
+	},
+	{
+		code:        ErrCodeOverloaded,
+		status:      http.StatusServiceUnavailable,
+		description: "database pool is saturated and low-priority requests are shed",
+		retryable:   true,
+	},
+	{
+		code:        ErrCodeDeadline,
+		status:      http.StatusGatewayTimeout,
+		description: "request deadline from X-Request-Deadline or X-Request-Timeout passed before the work finished",
+		retryable:   true,
+		message:     "request deadline exceeded",
+		errs:        []error{context.DeadlineExceeded},
+	},
+	{
+		code:        ErrCodeInternal,
+		status:      http.StatusInternalServerError,
+		description: "unexpected server error",
+		retryable:   true,
+	},
+}
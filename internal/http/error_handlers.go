@@ -0,0 +1,20 @@
+package http
+
+import (
+	"net/http"
+
+	"github.com/cloudyy74/pr-reviewer-service/internal/models"
+)
+
+func (rtr *router) getErrorCatalog(w http.ResponseWriter, r *http.Request) {
+	resp := models.ErrorCatalogResponse{Errors: make([]models.ErrorCodeInfo, 0, len(errorCatalog))}
+	for _, spec := range errorCatalog {
+		resp.Errors = append(resp.Errors, models.ErrorCodeInfo{
+			Code:        spec.code,
+			HTTPStatus:  spec.status,
+			Description: spec.description,
+			Retryable:   spec.retryable,
+		})
+	}
+	rtr.responseJSON(w, http.StatusOK, resp)
+}
@@ -0,0 +1,65 @@
+package http
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/cloudyy74/pr-reviewer-service/internal/models"
+	"github.com/cloudyy74/pr-reviewer-service/internal/service"
+)
+
+func TestGetErrorCatalog(t *testing.T) {
+	rtr := &router{log: slog.New(slog.NewTextHandler(io.Discard, nil))}
+
+	req := httptest.NewRequest(http.MethodGet, "/errors", nil)
+	rec := httptest.NewRecorder()
+
+	rtr.getErrorCatalog(rec, req)
+
+	if rec.Code != http.StatusOK {
+		t.Fatalf("expected status 200, got %d", rec.Code)
+	}
+	var resp models.ErrorCatalogResponse
+	if err := json.NewDecoder(rec.Body).Decode(&resp); err != nil {
+		t.Fatalf("failed to decode response: %v", err)
+	}
+	seen := make(map[string]models.ErrorCodeInfo, len(resp.Errors))
+	for _, info := range resp.Errors {
+		if _, ok := seen[info.Code]; ok {
+			t.Fatalf("duplicate code %s", info.Code)
+		}
+		if info.Description == "" {
+			t.Fatalf("code %s has no description", info.Code)
+		}
+		seen[info.Code] = info
+	}
+	if got := seen[ErrCodeNotFound]; got.HTTPStatus != http.StatusNotFound || got.Retryable {
+		t.Fatalf("unexpected NOT_FOUND entry: %+v", got)
+	}
+	if got := seen[ErrCodeInternal]; got.HTTPStatus != http.StatusInternalServerError || !got.Retryable {
+		t.Fatalf("unexpected INTERNAL entry: %+v", got)
+	}
+}
+
+func TestMapError_UsesCatalog(t *testing.T) {
+	rtr := &router{log: slog.New(slog.NewTextHandler(io.Discard, nil))}
+	for _, spec := range errorCatalog {
+		for _, target := range spec.errs {
+			got := rtr.mapError(fmt.Errorf("wrapped: %w", target))
+			if got.Code != spec.code {
+				t.Fatalf("%v: expected code %s, got %s", target, spec.code, got.Code)
+			}
+			if statusForCode(got.Code) != spec.status {
+				t.Fatalf("%s: expected status %d", spec.code, spec.status)
+			}
+		}
+	}
+	if got := rtr.mapError(fmt.Errorf("%w: bad", service.ErrPRValidation)); got.Message != "validation error: bad" {
+		t.Fatalf("unexpected validation message: %q", got.Message)
+	}
+}
@@ -5,11 +5,24 @@ import (
 	"errors"
 	"fmt"
 	"net/http"
+	"strconv"
 
+	"github.com/cloudyy74/pr-reviewer-service/internal/metrics"
 	"github.com/cloudyy74/pr-reviewer-service/internal/models"
-	"github.com/cloudyy74/pr-reviewer-service/internal/service"
 )
 
+// errorResponses counts error responses by catalog code so alerts can watch
+// codes such as NO_CANDIDATE rather than bare status classes.
+var errorResponses = metrics.NewCounterVec(
+	"pr_reviewer_error_responses_total",
+	"Error responses returned by the API, by error code and HTTP status.",
+	"code", "status",
+)
+
+func init() {
+	metrics.Default.Register(errorResponses)
+}
+
 type ResponseError struct {
 	Code    string `json:"code"`
 	Message string `json:"message"`
@@ -30,9 +43,15 @@ func newInternalError(msg string, args ...any) ResponseError {
 	return newResponseError(ErrCodeInternal, fmt.Sprintf(msg, args...))
 }
 
-func (rtr *router) handleError(w http.ResponseWriter, err error) {
+func (rtr *router) handleError(w http.ResponseWriter, r *http.Request, err error) {
 	respErr := rtr.mapError(err)
 	status := statusForCode(respErr.Code)
+	errorResponses.Inc(respErr.Code, strconv.Itoa(status))
+
+	if acceptsProblemJSON(r) {
+		writeProblem(w, r, status, respErr)
+		return
+	}
 
 	w.Header().Set("Content-Type", "application/json")
 	w.WriteHeader(status)
@@ -50,37 +69,32 @@ func (rtr *router) mapError(err error) ResponseError {
 		return respErr
 	}
 
-	switch {
-	case errors.Is(err, service.ErrTeamValidation), errors.Is(err, service.ErrPRValidation), errors.Is(err, service.ErrUserValidation):
-		return newResponseError(ErrCodeValidation, err.Error())
-	case errors.Is(err, service.ErrTeamExists):
-		return newResponseError(ErrCodeTeamExists, "team_name already exists")
-	case errors.Is(err, service.ErrTeamNotFound), errors.Is(err, service.ErrPRTeamNotFound),
-		errors.Is(err, service.ErrPRAuthorNotFound), errors.Is(err, service.ErrPRNotFound),
-		errors.Is(err, service.ErrUserNotFound):
-		return newResponseError(ErrCodeNotFound, "resource not found")
-	case errors.Is(err, service.ErrPRAlreadyExists):
-		return newResponseError(ErrCodePRExists, "pull request already exists")
-	case errors.Is(err, service.ErrPRMerged):
-		return newResponseError(ErrCodePRMerged, "cannot reassign on merged PR")
-	case errors.Is(err, service.ErrReviewerNotAssigned):
-		return newResponseError(ErrCodeNotAssigned, "reviewer is not assigned to this PR")
-	case errors.Is(err, service.ErrNoReplacement):
-		return newResponseError(ErrCodeNoCandidate, "no active replacement candidate in team")
-	default:
-		return newInternalError("internal error")
+	for _, spec := range errorCatalog {
+		for _, target := range spec.errs {
+			if !errors.Is(err, target) {
+				continue
+			}
+			if spec.message != "" {
+				return newResponseError(spec.code, spec.message)
+			}
+			return newResponseError(spec.code, err.Error())
+		}
 	}
+	return newInternalError("internal error")
 }
 
 func statusForCode(code string) int {
-	switch code {
-	case ErrCodeBadRequest, ErrCodeValidation, ErrCodeTeamExists:
-		return http.StatusBadRequest
-	case ErrCodeNotFound:
-		return http.StatusNotFound
-	case ErrCodePRExists, ErrCodePRMerged, ErrCodeNotAssigned, ErrCodeNoCandidate:
-		return http.StatusConflict
-	default:
-		return http.StatusInternalServerError
+	if spec, ok := specForCode(code); ok {
+		return spec.status
+	}
+	return http.StatusInternalServerError
+}
+
+func specForCode(code string) (errorCodeSpec, bool) {
+	for _, spec := range errorCatalog {
+		if spec.code == code {
+			return spec, true
+		}
 	}
+	return errorCodeSpec{}, false
 }
@@ -0,0 +1,59 @@
+package http
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/cloudyy74/pr-reviewer-service/internal/models"
+)
+
+type EventLog interface {
+	ListEvents(ctx context.Context, q models.EventLogQuery) (*models.EventLogResponse, error)
+}
+
+func SetupEventRoutes(mux *http.ServeMux, events EventLog, log *slog.Logger) error {
+	if mux == nil {
+		return errors.New("mux cannot be nil")
+	}
+	if events == nil {
+		return errors.New("event log cannot be nil")
+	}
+	if log == nil {
+		return errors.New("logger cannot be nil")
+	}
+	r := router{
+		events: events,
+		log:    log,
+	}
+	mux.HandleFunc("GET /admin/events", r.panicMiddleware(r.loggingMiddleware(r.listEvents)))
+	return nil
+}
+
+func (rtr *router) listEvents(w http.ResponseWriter, r *http.Request) {
+	query := r.URL.Query()
+	q := models.EventLogQuery{Topic: query.Get("topic")}
+	if raw := strings.TrimSpace(query.Get("since")); raw != "" {
+		since, err := strconv.ParseInt(raw, 10, 64)
+		if err != nil {
+			rtr.handleError(w, r, newResponseError(ErrCodeValidation, "since must be an integer"))
+			return
+		}
+		q.Since = since
+	}
+	var err error
+	if q.Limit, err = parseIntParam(query.Get("limit")); err != nil {
+		rtr.handleError(w, r, newResponseError(ErrCodeValidation, "limit must be an integer"))
+		return
+	}
+
+	resp, err := rtr.events.ListEvents(r.Context(), q)
+	if err != nil {
+		rtr.handleError(w, r, err)
+		return
+	}
+	rtr.responseJSON(w, http.StatusOK, resp)
+}
@@ -0,0 +1,55 @@
+package http
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/cloudyy74/pr-reviewer-service/internal/models"
+)
+
+type fakeEventLog struct {
+	got models.EventLogQuery
+}
+
+func (f *fakeEventLog) ListEvents(_ context.Context, q models.EventLogQuery) (*models.EventLogResponse, error) {
+	f.got = q
+	return &models.EventLogResponse{
+		Events:     []*models.LoggedEvent{{ID: q.Since + 1, Topic: models.TopicPRCreated, Payload: json.RawMessage(`{}`)}},
+		NextCursor: q.Since + 1,
+	}, nil
+}
+
+func TestListEvents(t *testing.T) {
+	mux := http.NewServeMux()
+	events := &fakeEventLog{}
+	if err := SetupEventRoutes(mux, events, slog.New(slog.NewTextHandler(io.Discard, nil))); err != nil {
+		t.Fatalf("SetupEventRoutes returned err: %v", err)
+	}
+
+	rec := httptest.NewRecorder()
+	mux.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/admin/events?since=41&topic=pr.created&limit=10", nil))
+	if rec.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d", rec.Code)
+	}
+	if events.got.Since != 41 || events.got.Topic != models.TopicPRCreated || events.got.Limit != 10 {
+		t.Fatalf("unexpected query: %+v", events.got)
+	}
+	var resp models.EventLogResponse
+	if err := json.NewDecoder(rec.Body).Decode(&resp); err != nil {
+		t.Fatalf("failed to decode response: %v", err)
+	}
+	if resp.NextCursor != 42 || len(resp.Events) != 1 {
+		t.Fatalf("unexpected response: %+v", resp)
+	}
+
+	rec = httptest.NewRecorder()
+	mux.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/admin/events?since=abc", nil))
+	if rec.Code != http.StatusBadRequest {
+		t.Fatalf("expected 400 for bad since, got %d", rec.Code)
+	}
+}
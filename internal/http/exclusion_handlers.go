@@ -0,0 +1,73 @@
+package http
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"log/slog"
+	"net/http"
+
+	"github.com/cloudyy74/pr-reviewer-service/internal/models"
+)
+
+type ExclusionService interface {
+	AddExclusion(ctx context.Context, req *models.AddReviewerExclusionRequest) (*models.ReviewerExclusion, error)
+	ListExclusions(ctx context.Context, userID string) (*models.ReviewerExclusionsResponse, error)
+	DeleteExclusion(ctx context.Context, req *models.DeleteReviewerExclusionRequest) error
+}
+
+func SetupExclusionRoutes(mux *http.ServeMux, exclusions ExclusionService, log *slog.Logger) error {
+	if mux == nil {
+		return errors.New("mux cannot be nil")
+	}
+	if exclusions == nil {
+		return errors.New("exclusion service cannot be nil")
+	}
+	if log == nil {
+		return errors.New("logger cannot be nil")
+	}
+	r := router{
+		exclusions: exclusions,
+		log:        log,
+	}
+	mux.HandleFunc("GET /admin/exclusions", r.panicMiddleware(r.loggingMiddleware(r.listExclusions)))
+	mux.HandleFunc("POST /admin/exclusions/add", r.panicMiddleware(r.loggingMiddleware(r.addExclusion)))
+	mux.HandleFunc("POST /admin/exclusions/delete", r.panicMiddleware(r.loggingMiddleware(r.deleteExclusion)))
+	return nil
+}
+
+func (rtr *router) listExclusions(w http.ResponseWriter, r *http.Request) {
+	resp, err := rtr.exclusions.ListExclusions(r.Context(), r.URL.Query().Get("user_id"))
+	if err != nil {
+		rtr.handleError(w, r, err)
+		return
+	}
+	rtr.responseJSON(w, http.StatusOK, resp)
+}
+
+func (rtr *router) addExclusion(w http.ResponseWriter, r *http.Request) {
+	var req models.AddReviewerExclusionRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		rtr.handleError(w, r, newResponseError(ErrCodeBadRequest, "bad json request"))
+		return
+	}
+	exclusion, err := rtr.exclusions.AddExclusion(r.Context(), &req)
+	if err != nil {
+		rtr.handleError(w, r, err)
+		return
+	}
+	rtr.responseJSON(w, http.StatusCreated, &models.ReviewerExclusionResponse{Exclusion: exclusion})
+}
+
+func (rtr *router) deleteExclusion(w http.ResponseWriter, r *http.Request) {
+	var req models.DeleteReviewerExclusionRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		rtr.handleError(w, r, newResponseError(ErrCodeBadRequest, "bad json request"))
+		return
+	}
+	if err := rtr.exclusions.DeleteExclusion(r.Context(), &req); err != nil {
+		rtr.handleError(w, r, err)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
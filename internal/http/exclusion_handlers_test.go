@@ -0,0 +1,57 @@
+package http
+
+import (
+	"context"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/cloudyy74/pr-reviewer-service/internal/models"
+	"github.com/cloudyy74/pr-reviewer-service/internal/service"
+)
+
+type fakeExclusionService struct {
+	listedFor string
+}
+
+func (f *fakeExclusionService) AddExclusion(_ context.Context, req *models.AddReviewerExclusionRequest) (*models.ReviewerExclusion, error) {
+	return &models.ReviewerExclusion{ReviewerID: req.ReviewerID, AuthorID: req.AuthorID, Mutual: req.Mutual}, nil
+}
+
+func (f *fakeExclusionService) ListExclusions(_ context.Context, userID string) (*models.ReviewerExclusionsResponse, error) {
+	f.listedFor = userID
+	return &models.ReviewerExclusionsResponse{}, nil
+}
+
+func (f *fakeExclusionService) DeleteExclusion(context.Context, *models.DeleteReviewerExclusionRequest) error {
+	return service.ErrExclusionNotFound
+}
+
+func TestExclusionRoutes(t *testing.T) {
+	mux := http.NewServeMux()
+	svc := &fakeExclusionService{}
+	if err := SetupExclusionRoutes(mux, svc, slog.New(slog.NewTextHandler(io.Discard, nil))); err != nil {
+		t.Fatalf("SetupExclusionRoutes returned err: %v", err)
+	}
+
+	rec := httptest.NewRecorder()
+	mux.ServeHTTP(rec, httptest.NewRequest(http.MethodPost, "/admin/exclusions/add", strings.NewReader(`{"reviewer_id":"u1","author_id":"u2","mutual":true}`)))
+	if rec.Code != http.StatusCreated || !strings.Contains(rec.Body.String(), `"mutual":true`) {
+		t.Fatalf("unexpected add response %d %s", rec.Code, rec.Body.String())
+	}
+
+	rec = httptest.NewRecorder()
+	mux.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/admin/exclusions?user_id=u1", nil))
+	if rec.Code != http.StatusOK || svc.listedFor != "u1" || !strings.Contains(rec.Body.String(), `"exclusions":[]`) {
+		t.Fatalf("unexpected list response %d %s", rec.Code, rec.Body.String())
+	}
+
+	rec = httptest.NewRecorder()
+	mux.ServeHTTP(rec, httptest.NewRequest(http.MethodPost, "/admin/exclusions/delete", strings.NewReader(`{"reviewer_id":"u1","author_id":"u2"}`)))
+	if rec.Code != http.StatusNotFound {
+		t.Fatalf("expected 404 for missing exclusion, got %d", rec.Code)
+	}
+}
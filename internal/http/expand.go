@@ -0,0 +1,159 @@
+package http
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"slices"
+	"strings"
+
+	"github.com/cloudyy74/pr-reviewer-service/internal/models"
+)
+
+const (
+	expandUsers   = "users"
+	expandAuthor  = "author"
+	expandOpenPRs = "open_prs"
+)
+
+type expanders[T any] map[string]func(ctx context.Context, resp T) error
+
+func (e expanders[T]) parse(r *http.Request) ([]string, error) {
+	var names []string
+	for _, raw := range r.URL.Query()["expand"] {
+		for _, name := range strings.Split(raw, ",") {
+			name = strings.TrimSpace(name)
+			if name == "" || slices.Contains(names, name) {
+				continue
+			}
+			if _, ok := e[name]; !ok {
+				return nil, newResponseError(ErrCodeValidation, fmt.Sprintf("unsupported expand value %q", name))
+			}
+			names = append(names, name)
+		}
+	}
+	slices.Sort(names)
+	return names, nil
+}
+
+func (e expanders[T]) apply(ctx context.Context, names []string, resp T) error {
+	for _, name := range names {
+		if err := e[name](ctx, resp); err != nil {
+			return fmt.Errorf("expand %s: %w", name, err)
+		}
+	}
+	return nil
+}
+
+func (rtr *router) prExpanders() expanders[*models.PRResponse] {
+	return expanders[*models.PRResponse]{
+		expandUsers: func(ctx context.Context, resp *models.PRResponse) (err error) {
+			resp.Users, err = rtr.userService.GetUsersByIDs(ctx, prUserIDs(&resp.PR))
+			return err
+		},
+		expandAuthor: func(ctx context.Context, resp *models.PRResponse) (err error) {
+			resp.Author, err = rtr.prAuthor(ctx, &resp.PR)
+			return err
+		},
+	}
+}
+
+func (rtr *router) reassignExpanders() expanders[*models.PRReassignResponse] {
+	return expanders[*models.PRReassignResponse]{
+		expandUsers: func(ctx context.Context, resp *models.PRReassignResponse) (err error) {
+			resp.Users, err = rtr.userService.GetUsersByIDs(ctx, prUserIDs(&resp.PR))
+			return err
+		},
+		expandAuthor: func(ctx context.Context, resp *models.PRReassignResponse) (err error) {
+			resp.Author, err = rtr.prAuthor(ctx, &resp.PR)
+			return err
+		},
+	}
+}
+
+func (rtr *router) reviewsExpanders() expanders[*models.UserReviewsResponse] {
+	return expanders[*models.UserReviewsResponse]{
+		expandUsers: func(ctx context.Context, resp *models.UserReviewsResponse) (err error) {
+			ids := []string{resp.UserID}
+			for _, pr := range resp.PullRequests {
+				ids = append(ids, pr.AuthorID)
+			}
+			resp.Users, err = rtr.userService.GetUsersByIDs(ctx, ids)
+			return err
+		},
+	}
+}
+
+func (rtr *router) historyExpanders() expanders[*models.AssignmentHistoryResponse] {
+	return expanders[*models.AssignmentHistoryResponse]{
+		expandUsers: func(ctx context.Context, resp *models.AssignmentHistoryResponse) (err error) {
+			ids := []string{resp.UserID}
+			for _, item := range resp.Assignments {
+				ids = append(ids, item.AuthorID)
+			}
+			resp.Users, err = rtr.userService.GetUsersByIDs(ctx, ids)
+			return err
+		},
+	}
+}
+
+func (rtr *router) statsExpanders() expanders[*models.AssignmentsStatsResponse] {
+	return expanders[*models.AssignmentsStatsResponse]{
+		expandUsers: func(ctx context.Context, resp *models.AssignmentsStatsResponse) error {
+			ids := make([]string, 0, len(resp.ByUser))
+			for _, stat := range resp.ByUser {
+				ids = append(ids, stat.UserID)
+			}
+			users, err := rtr.userService.GetUsersByIDs(ctx, ids)
+			if err != nil {
+				return err
+			}
+			usernames := make(map[string]string, len(users))
+			for _, u := range users {
+				usernames[u.ID] = u.Username
+			}
+			for _, stat := range resp.ByUser {
+				stat.Username = usernames[stat.UserID]
+			}
+			resp.Users = users
+			return nil
+		},
+	}
+}
+
+func (rtr *router) teamExpanders() expanders[*models.Team] {
+	return expanders[*models.Team]{
+		expandOpenPRs: func(ctx context.Context, team *models.Team) error {
+			count, err := rtr.teamService.CountOpenPRs(ctx, team.Name)
+			if err != nil {
+				return err
+			}
+			team.OpenPRs = &count
+			return nil
+		},
+	}
+}
+
+func (rtr *router) lookupUser(ctx context.Context, userID string) (*models.UserWithTeam, error) {
+	users, err := rtr.userService.GetUsersByIDs(ctx, []string{userID})
+	if err != nil {
+		return nil, err
+	}
+	if len(users) == 0 {
+		return nil, nil
+	}
+	return users[0], nil
+}
+
+// prAuthor reuses the author loaded alongside the pull request and only
+// falls back to a lookup for responses built without it.
+func (rtr *router) prAuthor(ctx context.Context, pr *models.PullRequest) (*models.UserWithTeam, error) {
+	if pr.Author != nil {
+		return pr.Author, nil
+	}
+	return rtr.lookupUser(ctx, pr.AuthorID)
+}
+
+func prUserIDs(pr *models.PullRequest) []string {
+	return append([]string{pr.AuthorID}, pr.Reviewers...)
+}
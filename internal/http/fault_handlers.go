@@ -0,0 +1,60 @@
+package http
+
+import (
+	"encoding/json"
+	"errors"
+	"log/slog"
+	"net/http"
+
+	"github.com/cloudyy74/pr-reviewer-service/internal/models"
+)
+
+type FaultInjector interface {
+	Faults() models.FaultConfig
+	SetFaults(models.FaultConfig) (models.FaultConfig, error)
+}
+
+func SetupFaultRoutes(mux *http.ServeMux, faults FaultInjector, log *slog.Logger) error {
+	if mux == nil {
+		return errors.New("mux cannot be nil")
+	}
+	if faults == nil {
+		return errors.New("fault injector cannot be nil")
+	}
+	if log == nil {
+		return errors.New("logger cannot be nil")
+	}
+	r := router{
+		faults: faults,
+		log:    log,
+	}
+	mux.HandleFunc("GET /admin/faults", r.panicMiddleware(r.loggingMiddleware(r.getFaults)))
+	mux.HandleFunc("POST /admin/faults", r.panicMiddleware(r.loggingMiddleware(r.setFaults)))
+	return nil
+}
+
+func (rtr *router) getFaults(w http.ResponseWriter, r *http.Request) {
+	rtr.responseJSON(w, http.StatusOK, rtr.faults.Faults())
+}
+
+func (rtr *router) setFaults(w http.ResponseWriter, r *http.Request) {
+	var req models.FaultConfig
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		rtr.handleError(w, r, newResponseError(ErrCodeBadRequest, "bad json request"))
+		return
+	}
+
+	cfg, err := rtr.faults.SetFaults(req)
+	if err != nil {
+		rtr.handleError(w, r, err)
+		return
+	}
+	rtr.log.Warn("fault injection updated",
+		slog.Bool("enabled", cfg.Enabled),
+		slog.Int("storage_delay_ms", cfg.StorageDelayMs),
+		slog.Int("storage_delay_percent", cfg.StorageDelayPercent),
+		slog.Int("storage_fail_percent", cfg.StorageFailPercent),
+		slog.Int("drop_notifications_percent", cfg.DropNotificationsPercent),
+	)
+	rtr.responseJSON(w, http.StatusOK, cfg)
+}
@@ -0,0 +1,81 @@
+package http
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/cloudyy74/pr-reviewer-service/internal/chaos"
+	"github.com/cloudyy74/pr-reviewer-service/internal/models"
+)
+
+type fakeFaultInjector struct {
+	cfg models.FaultConfig
+	err error
+}
+
+func (f *fakeFaultInjector) Faults() models.FaultConfig {
+	return f.cfg
+}
+
+func (f *fakeFaultInjector) SetFaults(cfg models.FaultConfig) (models.FaultConfig, error) {
+	if f.err != nil {
+		return models.FaultConfig{}, f.err
+	}
+	f.cfg = cfg
+	return cfg, nil
+}
+
+func TestFaultRoutes_SetAndGet(t *testing.T) {
+	mux := http.NewServeMux()
+	faults := &fakeFaultInjector{}
+	if err := SetupFaultRoutes(mux, faults, slog.New(slog.NewTextHandler(io.Discard, nil))); err != nil {
+		t.Fatalf("SetupFaultRoutes returned err: %v", err)
+	}
+
+	body := `{"enabled":true,"storage_fail_percent":25}`
+	rec := httptest.NewRecorder()
+	mux.ServeHTTP(rec, httptest.NewRequest(http.MethodPost, "/admin/faults", strings.NewReader(body)))
+	if rec.Code != http.StatusOK {
+		t.Fatalf("expected status 200, got %d", rec.Code)
+	}
+	if !faults.cfg.Enabled || faults.cfg.StorageFailPercent != 25 {
+		t.Fatalf("faults not applied: %+v", faults.cfg)
+	}
+
+	rec = httptest.NewRecorder()
+	mux.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/admin/faults", nil))
+	var got models.FaultConfig
+	if err := json.NewDecoder(rec.Body).Decode(&got); err != nil {
+		t.Fatalf("failed to decode response: %v", err)
+	}
+	if got != faults.cfg {
+		t.Fatalf("unexpected config: %+v", got)
+	}
+}
+
+func TestFaultRoutes_InvalidConfig(t *testing.T) {
+	mux := http.NewServeMux()
+	faults := &fakeFaultInjector{err: fmt.Errorf("%w: storage_fail_percent must be between 0 and 100", chaos.ErrInvalidConfig)}
+	if err := SetupFaultRoutes(mux, faults, slog.New(slog.NewTextHandler(io.Discard, nil))); err != nil {
+		t.Fatalf("SetupFaultRoutes returned err: %v", err)
+	}
+
+	rec := httptest.NewRecorder()
+	mux.ServeHTTP(rec, httptest.NewRequest(http.MethodPost, "/admin/faults", strings.NewReader(`{"storage_fail_percent":150}`)))
+	if rec.Code != http.StatusBadRequest {
+		t.Fatalf("expected status 400, got %d", rec.Code)
+	}
+	var resp models.ErrorResponse
+	if err := json.NewDecoder(rec.Body).Decode(&resp); err != nil {
+		t.Fatalf("failed to decode error response: %v", err)
+	}
+	if resp.Error.Code != ErrCodeValidation {
+		t.Fatalf("expected code %s, got %s", ErrCodeValidation, resp.Error.Code)
+	}
+}
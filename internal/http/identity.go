@@ -0,0 +1,47 @@
+package http
+
+import (
+	"context"
+	"log/slog"
+	"net/http"
+	"strings"
+
+	"github.com/cloudyy74/pr-reviewer-service/internal/models"
+)
+
+type IdentityResolver interface {
+	ResolveIdentity(ctx context.Context, rawToken string) (*models.UserWithTeam, error)
+}
+
+type identityCtxKey struct{}
+
+// UserFromContext returns the caller resolved from an ID token or a session
+// cookie.
+func UserFromContext(ctx context.Context) (*models.UserWithTeam, bool) {
+	if user, ok := ctx.Value(identityCtxKey{}).(*models.UserWithTeam); ok {
+		return user, true
+	}
+	if session, ok := SessionFromContext(ctx); ok {
+		return &session.User, true
+	}
+	return nil, false
+}
+
+// BearerIdentity maps a bearer ID token to an internal user. Requests without
+// the header are passed through untouched.
+func BearerIdentity(next http.Handler, resolver IdentityResolver, log *slog.Logger) http.Handler {
+	rtr := &router{log: log}
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		token, ok := strings.CutPrefix(r.Header.Get("Authorization"), "Bearer ")
+		if !ok || strings.TrimSpace(token) == "" {
+			next.ServeHTTP(w, r)
+			return
+		}
+		user, err := resolver.ResolveIdentity(r.Context(), strings.TrimSpace(token))
+		if err != nil {
+			rtr.handleError(w, r, err)
+			return
+		}
+		next.ServeHTTP(w, r.WithContext(context.WithValue(r.Context(), identityCtxKey{}, user)))
+	})
+}
@@ -0,0 +1,58 @@
+package http
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/cloudyy74/pr-reviewer-service/internal/models"
+	"github.com/cloudyy74/pr-reviewer-service/internal/oidc"
+)
+
+type fakeIdentityResolver struct{}
+
+func (fakeIdentityResolver) ResolveIdentity(_ context.Context, token string) (*models.UserWithTeam, error) {
+	if token != "good" {
+		return nil, oidc.ErrInvalidToken
+	}
+	return &models.UserWithTeam{User: models.User{ID: "u1", Username: "alice"}}, nil
+}
+
+func TestBearerIdentity_MyReviews(t *testing.T) {
+	svc := &fakePRService{
+		reviewsFn: func(_ context.Context, userID string) (*models.UserReviewsResponse, error) {
+			return &models.UserReviewsResponse{UserID: userID}, nil
+		},
+	}
+	rtr := newTestRouterWithPRService(svc)
+	h := BearerIdentity(http.HandlerFunc(rtr.getMyReviews), fakeIdentityResolver{}, rtr.log)
+
+	cases := []struct {
+		name   string
+		header string
+		status int
+	}{
+		{name: "valid token", header: "Bearer good", status: http.StatusOK},
+		{name: "invalid token", header: "Bearer bad", status: http.StatusUnauthorized},
+		{name: "no token", status: http.StatusUnauthorized},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodGet, "/users/me/reviews?user_id=u2", nil)
+			if tc.header != "" {
+				req.Header.Set("Authorization", tc.header)
+			}
+			rec := httptest.NewRecorder()
+			h.ServeHTTP(rec, req)
+
+			if rec.Code != tc.status {
+				t.Fatalf("expected status %d, got %d: %s", tc.status, rec.Code, rec.Body.String())
+			}
+			if tc.status == http.StatusOK && !strings.Contains(rec.Body.String(), `"user_id":"u1"`) {
+				t.Fatalf("expected reviews for u1, got %s", rec.Body.String())
+			}
+		})
+	}
+}
@@ -0,0 +1,80 @@
+package http
+
+import (
+	"context"
+	"log/slog"
+	"net/http"
+	"strings"
+
+	"github.com/cloudyy74/pr-reviewer-service/internal/models"
+)
+
+const impersonateHeader = "X-Impersonate-User"
+
+type Impersonator interface {
+	Impersonate(ctx context.Context, actorID, targetRef string) (*models.UserWithTeam, error)
+	RecordAudit(ctx context.Context, entry models.AuditEntry) error
+}
+
+type actorCtxKey struct{}
+
+// ActorFromContext returns the admin acting on behalf of the user returned by
+// UserFromContext, if the request is impersonated.
+func ActorFromContext(ctx context.Context) (*models.UserWithTeam, bool) {
+	actor, ok := ctx.Value(actorCtxKey{}).(*models.UserWithTeam)
+	return actor, ok
+}
+
+// Impersonate lets an authenticated admin act as another user via the
+// X-Impersonate-User header. Every impersonated request is written to the
+// audit trail with both the actor and the subject.
+func Impersonate(next http.Handler, impersonator Impersonator, log *slog.Logger) http.Handler {
+	rtr := &router{log: log}
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		target := strings.TrimSpace(r.Header.Get(impersonateHeader))
+		if target == "" {
+			next.ServeHTTP(w, r)
+			return
+		}
+		actor, ok := UserFromContext(r.Context())
+		if !ok {
+			rtr.handleError(w, r, newResponseError(ErrCodeUnauthorized, impersonateHeader+" requires an authenticated admin"))
+			return
+		}
+		subject, err := impersonator.Impersonate(r.Context(), actor.ID, target)
+		if err != nil {
+			rtr.handleError(w, r, err)
+			return
+		}
+
+		ctx := context.WithValue(r.Context(), actorCtxKey{}, actor)
+		ctx = context.WithValue(ctx, identityCtxKey{}, subject)
+		rec := &statusRecorder{ResponseWriter: w, status: http.StatusOK}
+		next.ServeHTTP(rec, r.WithContext(ctx))
+
+		entry := models.AuditEntry{
+			ActorID:   actor.ID,
+			SubjectID: subject.ID,
+			Method:    r.Method,
+			Path:      r.URL.Path,
+			Status:    rec.status,
+		}
+		if err := impersonator.RecordAudit(context.WithoutCancel(r.Context()), entry); err != nil {
+			log.Error("failed to record impersonation audit", slog.Any("error", err), slog.String("actor_id", actor.ID))
+		}
+	})
+}
+
+type statusRecorder struct {
+	http.ResponseWriter
+	status int
+}
+
+func (rec *statusRecorder) WriteHeader(status int) {
+	rec.status = status
+	rec.ResponseWriter.WriteHeader(status)
+}
+
+func (rec *statusRecorder) Unwrap() http.ResponseWriter {
+	return rec.ResponseWriter
+}
@@ -0,0 +1,76 @@
+package http
+
+import (
+	"context"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/cloudyy74/pr-reviewer-service/internal/models"
+	"github.com/cloudyy74/pr-reviewer-service/internal/service"
+)
+
+type fakeImpersonator struct {
+	entries []models.AuditEntry
+}
+
+func (f *fakeImpersonator) Impersonate(_ context.Context, actorID, targetRef string) (*models.UserWithTeam, error) {
+	if actorID != "admin" {
+		return nil, service.ErrImpersonationForbidden
+	}
+	return &models.UserWithTeam{User: models.User{ID: targetRef}}, nil
+}
+
+func (f *fakeImpersonator) RecordAudit(_ context.Context, entry models.AuditEntry) error {
+	f.entries = append(f.entries, entry)
+	return nil
+}
+
+func TestImpersonate(t *testing.T) {
+	log := slog.New(slog.NewTextHandler(io.Discard, nil))
+	impersonator := &fakeImpersonator{}
+	var seenUser, seenActor string
+	next := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		user, _ := UserFromContext(r.Context())
+		seenUser = user.ID
+		if actor, ok := ActorFromContext(r.Context()); ok {
+			seenActor = actor.ID
+		}
+		w.WriteHeader(http.StatusAccepted)
+	})
+	h := Impersonate(next, impersonator, log)
+
+	req := withTestIdentity(httptest.NewRequest(http.MethodPost, "/me/decline", nil), "admin")
+	req.Header.Set(impersonateHeader, "u1")
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, req)
+
+	if rec.Code != http.StatusAccepted || seenUser != "u1" || seenActor != "admin" {
+		t.Fatalf("expected request as u1 by admin, got status %d user %q actor %q", rec.Code, seenUser, seenActor)
+	}
+	want := models.AuditEntry{ActorID: "admin", SubjectID: "u1", Method: http.MethodPost, Path: "/me/decline", Status: http.StatusAccepted}
+	if len(impersonator.entries) != 1 || impersonator.entries[0] != want {
+		t.Fatalf("unexpected audit entries: %+v", impersonator.entries)
+	}
+
+	req = withTestIdentity(httptest.NewRequest(http.MethodGet, "/me", nil), "u2")
+	req.Header.Set(impersonateHeader, "u1")
+	rec = httptest.NewRecorder()
+	h.ServeHTTP(rec, req)
+	if rec.Code != http.StatusForbidden {
+		t.Fatalf("expected 403 for non-admin, got %d", rec.Code)
+	}
+
+	req = httptest.NewRequest(http.MethodGet, "/me", nil)
+	req.Header.Set(impersonateHeader, "u1")
+	rec = httptest.NewRecorder()
+	h.ServeHTTP(rec, req)
+	if rec.Code != http.StatusUnauthorized {
+		t.Fatalf("expected 401 without identity, got %d", rec.Code)
+	}
+	if len(impersonator.entries) != 1 {
+		t.Fatalf("expected rejected requests not to be audited, got %+v", impersonator.entries)
+	}
+}
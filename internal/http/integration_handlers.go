@@ -0,0 +1,47 @@
+package http
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"log/slog"
+	"net/http"
+
+	"github.com/cloudyy74/pr-reviewer-service/internal/models"
+)
+
+type IntegrationTokenService interface {
+	SetIntegrationToken(ctx context.Context, req *models.SetIntegrationTokenRequest) (*models.IntegrationToken, error)
+}
+
+func SetupIntegrationTokenRoutes(mux *http.ServeMux, tokens IntegrationTokenService, log *slog.Logger) error {
+	if mux == nil {
+		return errors.New("mux cannot be nil")
+	}
+	if tokens == nil {
+		return errors.New("integration token service cannot be nil")
+	}
+	if log == nil {
+		return errors.New("logger cannot be nil")
+	}
+	r := router{
+		integrationTokens: tokens,
+		log:               log,
+	}
+	mux.HandleFunc("POST /team/setIntegrationToken", r.panicMiddleware(r.loggingMiddleware(r.setIntegrationToken)))
+	return nil
+}
+
+func (rtr *router) setIntegrationToken(w http.ResponseWriter, r *http.Request) {
+	var req models.SetIntegrationTokenRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		rtr.handleError(w, r, newResponseError(ErrCodeBadRequest, "bad json request"))
+		return
+	}
+	info, err := rtr.integrationTokens.SetIntegrationToken(r.Context(), &req)
+	if err != nil {
+		rtr.handleError(w, r, err)
+		return
+	}
+	rtr.responseJSON(w, http.StatusOK, &models.IntegrationTokenResponse{Integration: *info})
+}
@@ -0,0 +1,47 @@
+package http
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/cloudyy74/pr-reviewer-service/internal/models"
+	"github.com/cloudyy74/pr-reviewer-service/internal/service"
+)
+
+type fakeIntegrationTokenService struct{}
+
+func (fakeIntegrationTokenService) SetIntegrationToken(_ context.Context, req *models.SetIntegrationTokenRequest) (*models.IntegrationToken, error) {
+	if req.Provider != models.IntegrationSlack {
+		return nil, service.ErrIntegrationValidation
+	}
+	return &models.IntegrationToken{TeamName: req.TeamName, Provider: req.Provider, KeyID: "k1"}, nil
+}
+
+func TestSetIntegrationToken(t *testing.T) {
+	mux := http.NewServeMux()
+	if err := SetupIntegrationTokenRoutes(mux, fakeIntegrationTokenService{}, slog.New(slog.NewTextHandler(io.Discard, nil))); err != nil {
+		t.Fatalf("SetupIntegrationTokenRoutes returned err: %v", err)
+	}
+
+	body := `{"team_name":"backend","provider":"slack","token":"xoxb-secret"}`
+	rec := httptest.NewRecorder()
+	mux.ServeHTTP(rec, httptest.NewRequest(http.MethodPost, "/team/setIntegrationToken", bytes.NewBufferString(body)))
+	if rec.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d: %s", rec.Code, rec.Body.String())
+	}
+	if strings.Contains(rec.Body.String(), "xoxb-secret") || !strings.Contains(rec.Body.String(), `"key_id":"k1"`) {
+		t.Fatalf("unexpected response %s", rec.Body.String())
+	}
+
+	rec = httptest.NewRecorder()
+	mux.ServeHTTP(rec, httptest.NewRequest(http.MethodPost, "/team/setIntegrationToken", bytes.NewBufferString(`{"provider":"email"}`)))
+	if rec.Code != http.StatusBadRequest {
+		t.Fatalf("expected 400, got %d", rec.Code)
+	}
+}
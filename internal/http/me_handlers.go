@@ -0,0 +1,94 @@
+package http
+
+import (
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+
+	"github.com/cloudyy74/pr-reviewer-service/internal/models"
+)
+
+func (rtr *router) currentUser(w http.ResponseWriter, r *http.Request) (*models.UserWithTeam, bool) {
+	user, ok := UserFromContext(r.Context())
+	if !ok {
+		rtr.handleError(w, r, newResponseError(ErrCodeUnauthorized, "bearer ID token or session is required"))
+		return nil, false
+	}
+	return user, true
+}
+
+func (rtr *router) getMe(w http.ResponseWriter, r *http.Request) {
+	user, ok := rtr.currentUser(w, r)
+	if !ok {
+		return
+	}
+	fresh, err := rtr.lookupUser(r.Context(), user.ID)
+	if err != nil {
+		rtr.handleError(w, r, err)
+		return
+	}
+	if fresh != nil {
+		user = fresh
+	}
+	rtr.responseJSON(w, http.StatusOK, &models.UserResponse{User: *user})
+}
+
+func (rtr *router) getMyReviews(w http.ResponseWriter, r *http.Request) {
+	user, ok := rtr.currentUser(w, r)
+	if !ok {
+		return
+	}
+	rtr.writeUserReviews(w, r, user.ID)
+}
+
+func (rtr *router) setMeUnavailable(w http.ResponseWriter, r *http.Request) {
+	user, ok := rtr.currentUser(w, r)
+	if !ok {
+		return
+	}
+	var req models.SetUnavailableRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil && !errors.Is(err, io.EOF) {
+		rtr.handleError(w, r, newResponseError(ErrCodeBadRequest, "bad json request"))
+		return
+	}
+	unavailable := req.Unavailable == nil || *req.Unavailable
+
+	resp, err := rtr.userService.SetUserActive(r.Context(), user.ID, !unavailable, 0)
+	if err != nil {
+		rtr.handleError(w, r, err)
+		return
+	}
+	rtr.responseJSON(w, http.StatusOK, resp)
+}
+
+func (rtr *router) declineMyReview(w http.ResponseWriter, r *http.Request) {
+	user, ok := rtr.currentUser(w, r)
+	if !ok {
+		return
+	}
+	exp := rtr.reassignExpanders()
+	expand, err := exp.parse(r)
+	if err != nil {
+		rtr.handleError(w, r, err)
+		return
+	}
+
+	var req models.DeclineReviewRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		rtr.handleError(w, r, newResponseError(ErrCodeBadRequest, "bad json request"))
+		return
+	}
+
+	resp, err := rtr.prService.DeclineReview(r.Context(), &models.PRDeclineRequest{ID: req.ID, UserID: user.ID, Reason: req.Reason})
+	if err != nil {
+		rtr.handleError(w, r, err)
+		return
+	}
+	if err := exp.apply(r.Context(), expand, resp); err != nil {
+		rtr.handleError(w, r, err)
+		return
+	}
+
+	rtr.responseJSON(w, http.StatusOK, resp)
+}
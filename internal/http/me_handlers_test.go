@@ -0,0 +1,79 @@
+package http
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/cloudyy74/pr-reviewer-service/internal/models"
+)
+
+func withTestIdentity(r *http.Request, userID string) *http.Request {
+	user := &models.UserWithTeam{User: models.User{ID: userID, IsActive: true}}
+	return r.WithContext(context.WithValue(r.Context(), identityCtxKey{}, user))
+}
+
+func TestMeUnavailable(t *testing.T) {
+	var gotID string
+	var gotActive bool
+	rtr := &router{
+		userService: &fakeUserService{
+			setFn: func(_ context.Context, userID string, isActive bool) (*models.UserResponse, error) {
+				gotID, gotActive = userID, isActive
+				return &models.UserResponse{User: models.UserWithTeam{User: models.User{ID: userID, IsActive: isActive}}}, nil
+			},
+		},
+		log: slog.New(slog.NewTextHandler(io.Discard, nil)),
+	}
+
+	cases := []struct {
+		body       string
+		wantActive bool
+	}{
+		{body: "", wantActive: false},
+		{body: `{"unavailable":true}`, wantActive: false},
+		{body: `{"unavailable":false}`, wantActive: true},
+	}
+	for _, tc := range cases {
+		req := withTestIdentity(httptest.NewRequest(http.MethodPost, "/me/unavailable", bytes.NewBufferString(tc.body)), "u1")
+		rec := httptest.NewRecorder()
+		rtr.setMeUnavailable(rec, req)
+
+		if rec.Code != http.StatusOK {
+			t.Fatalf("body %q: expected 200, got %d: %s", tc.body, rec.Code, rec.Body.String())
+		}
+		if gotID != "u1" || gotActive != tc.wantActive {
+			t.Fatalf("body %q: expected u1 active=%v, got %s active=%v", tc.body, tc.wantActive, gotID, gotActive)
+		}
+	}
+
+	rec := httptest.NewRecorder()
+	rtr.setMeUnavailable(rec, httptest.NewRequest(http.MethodPost, "/me/unavailable", nil))
+	if rec.Code != http.StatusUnauthorized {
+		t.Fatalf("expected 401 without identity, got %d", rec.Code)
+	}
+}
+
+func TestMeDecline(t *testing.T) {
+	svc := &fakePRService{
+		declineFn: func(_ context.Context, req *models.PRDeclineRequest) (*models.PRReassignResponse, error) {
+			if req.ID != "pr-1" || req.UserID != "u2" {
+				t.Fatalf("unexpected decline request: %+v", req)
+			}
+			return &models.PRReassignResponse{PR: models.PullRequest{ID: "pr-1", Reviewers: []string{"u3"}}, ReplacedBy: "u3"}, nil
+		},
+	}
+	rtr := newTestRouterWithPRService(svc)
+
+	req := withTestIdentity(httptest.NewRequest(http.MethodPost, "/me/decline", bytes.NewBufferString(`{"pull_request_id":"pr-1"}`)), "u2")
+	rec := httptest.NewRecorder()
+	rtr.declineMyReview(rec, req)
+
+	if rec.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d: %s", rec.Code, rec.Body.String())
+	}
+}
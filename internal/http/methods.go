@@ -0,0 +1,102 @@
+package http
+
+import (
+	"log/slog"
+	"net/http"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+)
+
+var probeMethods = []string{http.MethodGet, http.MethodHead, http.MethodPost, http.MethodPut, http.MethodPatch, http.MethodDelete}
+
+var defaultCORSHeaders = []string{
+	"Authorization", "Content-Type", "X-Api-Key", "X-CSRF-Token", "X-Impersonate-User",
+	"X-Request-Id", "X-Request-Deadline", "X-Request-Timeout", "Grpc-Timeout", "X-Response-Envelope",
+	"Idempotency-Key",
+}
+
+var corsExposedHeaders = []string{
+	"X-Request-Id", "Retry-After", "Deprecation", "Sunset", "Link", "X-Deprecated-Fields",
+}
+
+// CORSPolicy lists browser origins allowed to call the API. An empty
+// AllowedOrigins disables CORS headers; "*" allows any origin.
+type CORSPolicy struct {
+	AllowedOrigins   []string
+	AllowedHeaders   []string
+	AllowCredentials bool
+	MaxAge           time.Duration
+}
+
+func (p CORSPolicy) allows(origin string) bool {
+	return origin != "" && (slices.Contains(p.AllowedOrigins, "*") || slices.Contains(p.AllowedOrigins, origin))
+}
+
+// RouteMethods answers OPTIONS for every registered path, including CORS
+// preflight, and replaces the plain-text 404 and 405 of ServeMux with JSON
+// errors, the latter carrying the Allow header. HEAD is served by the GET
+// handlers through ServeMux itself; mux must be the mux next eventually
+// dispatches to.
+func RouteMethods(next http.Handler, mux *http.ServeMux, cors CORSPolicy, log *slog.Logger) http.Handler {
+	rtr := &router{log: log}
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if origin := r.Header.Get("Origin"); cors.allows(origin) {
+			h := w.Header()
+			h.Add("Vary", "Origin")
+			h.Set("Access-Control-Allow-Origin", origin)
+			h.Set("Access-Control-Expose-Headers", strings.Join(corsExposedHeaders, ", "))
+			if cors.AllowCredentials {
+				h.Set("Access-Control-Allow-Credentials", "true")
+			}
+		}
+		if r.Method != http.MethodOptions {
+			if _, pattern := mux.Handler(r); pattern != "" {
+				next.ServeHTTP(w, r)
+				return
+			}
+		}
+
+		allowed := allowedMethods(mux, r)
+		if len(allowed) == 0 {
+			rtr.handleError(w, r, newResponseError(ErrCodeNotFound, "no route for "+r.URL.Path))
+			return
+		}
+		allow := strings.Join(allowed, ", ")
+		w.Header().Set("Allow", allow)
+		if r.Method != http.MethodOptions {
+			rtr.handleError(w, r, newResponseError(ErrCodeMethodNotAllowed, "method "+r.Method+" is not allowed, use "+allow))
+			return
+		}
+		if r.Header.Get("Access-Control-Request-Method") != "" && w.Header().Get("Access-Control-Allow-Origin") != "" {
+			headers := cors.AllowedHeaders
+			if len(headers) == 0 {
+				headers = defaultCORSHeaders
+			}
+			w.Header().Set("Access-Control-Allow-Methods", allow)
+			w.Header().Set("Access-Control-Allow-Headers", strings.Join(headers, ", "))
+			if cors.MaxAge > 0 {
+				w.Header().Set("Access-Control-Max-Age", strconv.Itoa(int(cors.MaxAge.Seconds())))
+			}
+		}
+		w.WriteHeader(http.StatusNoContent)
+	})
+}
+
+// allowedMethods reports the methods registered on mux for the request path,
+// with OPTIONS appended. It returns nil for unknown paths.
+func allowedMethods(mux *http.ServeMux, r *http.Request) []string {
+	var allowed []string
+	for _, method := range probeMethods {
+		probe := r.Clone(r.Context())
+		probe.Method = method
+		if _, pattern := mux.Handler(probe); pattern != "" {
+			allowed = append(allowed, method)
+		}
+	}
+	if len(allowed) == 0 {
+		return nil
+	}
+	return append(allowed, http.MethodOptions)
+}
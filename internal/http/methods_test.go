@@ -0,0 +1,116 @@
+package http
+
+import (
+	"encoding/json"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/cloudyy74/pr-reviewer-service/internal/models"
+)
+
+func newMethodsHandler(cors CORSPolicy) http.Handler {
+	mux := http.NewServeMux()
+	ok := func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(`{"ok":true}`))
+	}
+	mux.HandleFunc("GET /team/get", ok)
+	mux.HandleFunc("POST /team/add", ok)
+	return RouteMethods(mux, mux, cors, slog.New(slog.NewTextHandler(io.Discard, nil)))
+}
+
+func TestRouteMethods_MethodNotAllowedIsJSON(t *testing.T) {
+	handler := newMethodsHandler(CORSPolicy{})
+
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, httptest.NewRequest(http.MethodDelete, "/team/get", nil))
+
+	if rec.Code != http.StatusMethodNotAllowed {
+		t.Fatalf("expected 405, got %d", rec.Code)
+	}
+	if got := rec.Header().Get("Allow"); got != "GET, HEAD, OPTIONS" {
+		t.Fatalf("unexpected Allow %q", got)
+	}
+	if ct := rec.Header().Get("Content-Type"); ct != "application/json" || !strings.Contains(rec.Body.String(), ErrCodeMethodNotAllowed) {
+		t.Fatalf("expected JSON error, got %q %s", ct, rec.Body.String())
+	}
+}
+
+func TestRouteMethods_NotFoundIsJSON(t *testing.T) {
+	handler := newMethodsHandler(CORSPolicy{})
+
+	for _, method := range []string{http.MethodGet, http.MethodPost, http.MethodOptions} {
+		rec := httptest.NewRecorder()
+		handler.ServeHTTP(rec, httptest.NewRequest(method, "/missing", nil))
+		if rec.Code != http.StatusNotFound {
+			t.Fatalf("%s: expected 404, got %d", method, rec.Code)
+		}
+		var resp models.ErrorResponse
+		if err := json.NewDecoder(rec.Body).Decode(&resp); err != nil {
+			t.Fatalf("%s: decode error response: %v", method, err)
+		}
+		if resp.Error.Code != ErrCodeNotFound || resp.Error.Message != "no route for /missing" {
+			t.Fatalf("%s: unexpected error %+v", method, resp.Error)
+		}
+	}
+}
+
+func TestRouteMethods_HeadAndOptions(t *testing.T) {
+	handler := newMethodsHandler(CORSPolicy{})
+
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, httptest.NewRequest(http.MethodHead, "/team/get", nil))
+	if rec.Code != http.StatusOK {
+		t.Fatalf("expected HEAD to be served by GET handler, got %d", rec.Code)
+	}
+
+	rec = httptest.NewRecorder()
+	handler.ServeHTTP(rec, httptest.NewRequest(http.MethodOptions, "/team/add", nil))
+	if rec.Code != http.StatusNoContent || rec.Header().Get("Allow") != "POST, OPTIONS" {
+		t.Fatalf("unexpected OPTIONS response %d %q", rec.Code, rec.Header().Get("Allow"))
+	}
+	if rec.Header().Get("Access-Control-Allow-Origin") != "" {
+		t.Fatalf("expected no CORS headers without allowed origins")
+	}
+}
+
+func TestRouteMethods_CORSPreflight(t *testing.T) {
+	handler := newMethodsHandler(CORSPolicy{
+		AllowedOrigins:   []string{"https://ui.example.com"},
+		AllowCredentials: true,
+		MaxAge:           10 * time.Minute,
+	})
+
+	req := httptest.NewRequest(http.MethodOptions, "/team/add", nil)
+	req.Header.Set("Origin", "https://ui.example.com")
+	req.Header.Set("Access-Control-Request-Method", http.MethodPost)
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, req)
+
+	h := rec.Header()
+	if rec.Code != http.StatusNoContent {
+		t.Fatalf("expected 204, got %d", rec.Code)
+	}
+	if h.Get("Access-Control-Allow-Origin") != "https://ui.example.com" || h.Get("Access-Control-Allow-Credentials") != "true" {
+		t.Fatalf("unexpected CORS headers %v", h)
+	}
+	if h.Get("Access-Control-Allow-Methods") != "POST, OPTIONS" || h.Get("Access-Control-Max-Age") != "600" {
+		t.Fatalf("unexpected preflight headers %v", h)
+	}
+	if !strings.Contains(h.Get("Access-Control-Allow-Headers"), "X-CSRF-Token") {
+		t.Fatalf("expected default allowed headers, got %q", h.Get("Access-Control-Allow-Headers"))
+	}
+
+	req = httptest.NewRequest(http.MethodGet, "/team/get", nil)
+	req.Header.Set("Origin", "https://evil.example.com")
+	rec = httptest.NewRecorder()
+	handler.ServeHTTP(rec, req)
+	if rec.Code != http.StatusOK || rec.Header().Get("Access-Control-Allow-Origin") != "" {
+		t.Fatalf("expected request from unknown origin without CORS headers, got %d %v", rec.Code, rec.Header())
+	}
+}
@@ -0,0 +1,26 @@
+package http
+
+import (
+	"errors"
+	"log/slog"
+	"net/http"
+
+	"github.com/cloudyy74/pr-reviewer-service/internal/metrics"
+)
+
+// SetupMetricsRoutes serves registry on GET /metrics. Scrapes are not logged
+// to keep the request log readable.
+func SetupMetricsRoutes(mux *http.ServeMux, registry *metrics.Registry, log *slog.Logger) error {
+	if mux == nil {
+		return errors.New("mux cannot be nil")
+	}
+	if registry == nil {
+		return errors.New("metrics registry cannot be nil")
+	}
+	if log == nil {
+		return errors.New("logger cannot be nil")
+	}
+	r := router{log: log}
+	mux.HandleFunc("GET /metrics", r.panicMiddleware(registry.Handler().ServeHTTP))
+	return nil
+}
@@ -0,0 +1,33 @@
+package http
+
+import (
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/cloudyy74/pr-reviewer-service/internal/metrics"
+	"github.com/cloudyy74/pr-reviewer-service/internal/service"
+)
+
+func TestHandleError_CountsErrorCodes(t *testing.T) {
+	before := errorResponses.Value(ErrCodeNoCandidate, "409")
+	rtr := &router{log: slog.New(slog.NewTextHandler(io.Discard, nil))}
+	rtr.handleError(httptest.NewRecorder(), httptest.NewRequest(http.MethodPost, "/pullRequest/reassign", nil), service.ErrNoReplacement)
+
+	if got := errorResponses.Value(ErrCodeNoCandidate, "409"); got != before+1 {
+		t.Fatalf("expected NO_CANDIDATE counter to grow by one, got %d -> %d", before, got)
+	}
+
+	mux := http.NewServeMux()
+	if err := SetupMetricsRoutes(mux, metrics.Default, rtr.log); err != nil {
+		t.Fatalf("SetupMetricsRoutes returned err: %v", err)
+	}
+	rec := httptest.NewRecorder()
+	mux.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/metrics", nil))
+	if !strings.Contains(rec.Body.String(), `pr_reviewer_error_responses_total{code="NO_CANDIDATE",status="409"}`) {
+		t.Fatalf("expected error counter in exposition, got %s", rec.Body.String())
+	}
+}
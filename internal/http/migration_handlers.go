@@ -0,0 +1,41 @@
+package http
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"net/http"
+
+	"github.com/cloudyy74/pr-reviewer-service/internal/models"
+)
+
+type OnlineMigrationMonitor interface {
+	Status(ctx context.Context) (*models.OnlineMigrationsResponse, error)
+}
+
+func SetupMigrationRoutes(mux *http.ServeMux, migrations OnlineMigrationMonitor, log *slog.Logger) error {
+	if mux == nil {
+		return errors.New("mux cannot be nil")
+	}
+	if migrations == nil {
+		return errors.New("online migration monitor cannot be nil")
+	}
+	if log == nil {
+		return errors.New("logger cannot be nil")
+	}
+	r := router{
+		migrations: migrations,
+		log:        log,
+	}
+	mux.HandleFunc("GET /admin/migrations", r.panicMiddleware(r.loggingMiddleware(r.getOnlineMigrations)))
+	return nil
+}
+
+func (rtr *router) getOnlineMigrations(w http.ResponseWriter, r *http.Request) {
+	status, err := rtr.migrations.Status(r.Context())
+	if err != nil {
+		rtr.handleError(w, r, err)
+		return
+	}
+	rtr.responseJSON(w, http.StatusOK, status)
+}
@@ -0,0 +1,47 @@
+package http
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/cloudyy74/pr-reviewer-service/internal/models"
+)
+
+type fakeMigrationMonitor struct {
+	resp *models.OnlineMigrationsResponse
+}
+
+func (f fakeMigrationMonitor) Status(context.Context) (*models.OnlineMigrationsResponse, error) {
+	return f.resp, nil
+}
+
+func TestGetOnlineMigrations(t *testing.T) {
+	mux := http.NewServeMux()
+	monitor := fakeMigrationMonitor{resp: &models.OnlineMigrationsResponse{Migrations: []*models.OnlineMigrationStatus{{
+		Name:            "pr_status_enum",
+		ConfiguredPhase: "cutover",
+		ActivePhase:     "dual_write",
+		Remaining:       7,
+	}}}}
+	if err := SetupMigrationRoutes(mux, monitor, slog.New(slog.NewTextHandler(io.Discard, nil))); err != nil {
+		t.Fatalf("SetupMigrationRoutes returned err: %v", err)
+	}
+
+	rec := httptest.NewRecorder()
+	mux.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/admin/migrations", nil))
+	if rec.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d", rec.Code)
+	}
+	var resp models.OnlineMigrationsResponse
+	if err := json.NewDecoder(rec.Body).Decode(&resp); err != nil {
+		t.Fatalf("failed to decode response: %v", err)
+	}
+	if len(resp.Migrations) != 1 || resp.Migrations[0].ActivePhase != "dual_write" || resp.Migrations[0].Remaining != 7 {
+		t.Fatalf("unexpected response: %+v", resp.Migrations)
+	}
+}
@@ -0,0 +1,77 @@
+package http
+
+import (
+	"encoding/json"
+	"reflect"
+)
+
+var rawMessageType = reflect.TypeFor[json.RawMessage]()
+
+// normalizeResponse replaces nil slices reachable from response with empty
+// ones so every list in a response body is encoded as [] rather than null.
+// Values passed by value are copied before normalization; the returned value
+// is the one to encode.
+func normalizeResponse(response any) any {
+	if response == nil {
+		return nil
+	}
+	v := reflect.ValueOf(response)
+	if v.Kind() != reflect.Pointer {
+		cp := reflect.New(v.Type()).Elem()
+		cp.Set(v)
+		v = cp
+	}
+	n := sliceNormalizer{seen: make(map[uintptr]struct{})}
+	n.walk(v)
+	return v.Interface()
+}
+
+type sliceNormalizer struct {
+	seen map[uintptr]struct{}
+}
+
+func (n sliceNormalizer) walk(v reflect.Value) {
+	switch v.Kind() {
+	case reflect.Pointer:
+		if v.IsNil() {
+			return
+		}
+		if _, ok := n.seen[v.Pointer()]; ok {
+			return
+		}
+		n.seen[v.Pointer()] = struct{}{}
+		n.walk(v.Elem())
+	case reflect.Interface:
+		if !v.IsNil() {
+			n.walk(v.Elem())
+		}
+	case reflect.Struct:
+		for i := range v.NumField() {
+			if v.Type().Field(i).IsExported() {
+				n.walk(v.Field(i))
+			}
+		}
+	case reflect.Slice:
+		if v.Type() == rawMessageType || v.Type().Elem().Kind() == reflect.Uint8 {
+			return
+		}
+		if v.IsNil() {
+			if v.CanSet() {
+				v.Set(reflect.MakeSlice(v.Type(), 0, 0))
+			}
+			return
+		}
+		for i := range v.Len() {
+			n.walk(v.Index(i))
+		}
+	case reflect.Array:
+		for i := range v.Len() {
+			n.walk(v.Index(i))
+		}
+	case reflect.Map:
+		iter := v.MapRange()
+		for iter.Next() {
+			n.walk(iter.Value())
+		}
+	}
+}
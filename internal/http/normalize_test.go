@@ -0,0 +1,115 @@
+package http
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/cloudyy74/pr-reviewer-service/internal/models"
+)
+
+func nilSlicePath(v reflect.Value, path string) string {
+	switch v.Kind() {
+	case reflect.Pointer, reflect.Interface:
+		if !v.IsNil() {
+			return nilSlicePath(v.Elem(), path)
+		}
+	case reflect.Struct:
+		for i := range v.NumField() {
+			if f := v.Type().Field(i); f.IsExported() {
+				if p := nilSlicePath(v.Field(i), path+"."+f.Name); p != "" {
+					return p
+				}
+			}
+		}
+	case reflect.Slice:
+		if v.Type().Elem().Kind() == reflect.Uint8 {
+			return ""
+		}
+		if v.IsNil() {
+			return path
+		}
+		for i := range v.Len() {
+			if p := nilSlicePath(v.Index(i), path+"[]"); p != "" {
+				return p
+			}
+		}
+	}
+	return ""
+}
+
+func TestNormalizeResponse_ResponseContracts(t *testing.T) {
+	responses := []any{
+		&models.ErrorCatalogResponse{},
+		&models.NotificationListResponse{},
+		&models.NeedReviewersResponse{},
+		&models.PRResponse{},
+		&models.UserReviewsResponse{},
+		&models.PRReassignAllResponse{Results: []*models.PRReassignResult{{}}},
+		&models.TransferAssignmentsResponse{},
+		&models.PRReassignResponse{},
+		&models.AssignmentsStatsResponse{},
+		&models.AwaitAssignmentResponse{},
+		&models.AssignmentHistoryResponse{},
+		&models.SchemaReport{},
+		&models.OnlineMigrationsResponse{},
+		&models.TimeseriesResponse{},
+		&models.AnomaliesResponse{},
+		&models.HeatmapResponse{},
+		&models.ThroughputResponse{},
+		&models.CompletionResponse{},
+		&models.TeamResponse{},
+		&models.TeamDeactivateResponse{},
+		&models.Team{},
+		models.LoadMetrics{},
+	}
+	for _, resp := range responses {
+		name := reflect.TypeOf(resp).String()
+		out := normalizeResponse(resp)
+		if p := nilSlicePath(reflect.ValueOf(out), name); p != "" {
+			t.Fatalf("%s is still nil after normalization", p)
+		}
+		if _, err := json.Marshal(out); err != nil {
+			t.Fatalf("marshal %s: %v", name, err)
+		}
+	}
+}
+
+func TestNormalizeResponse_KeepsRawJSON(t *testing.T) {
+	out := normalizeResponse(struct {
+		Payload json.RawMessage `json:"payload"`
+		Items   []string        `json:"items"`
+	}{})
+	body, err := json.Marshal(out)
+	if err != nil {
+		t.Fatalf("marshal: %v", err)
+	}
+	if string(body) != `{"payload":null,"items":[]}` {
+		t.Fatalf("unexpected body %s", body)
+	}
+}
+
+func TestGetTeam_EmptyMembersEncodeAsArray(t *testing.T) {
+	svc := &fakeTeamService{
+		getFn: func(context.Context, string) ([]*models.User, error) {
+			return nil, nil
+		},
+	}
+	rtr := newTestRouterWithTeamService(svc)
+
+	req := httptest.NewRequest(http.MethodGet, "/team/get?team_name=backend", nil)
+	rec := httptest.NewRecorder()
+
+	rtr.getTeam(rec, req)
+
+	if rec.Code != http.StatusOK {
+		t.Fatalf("expected status 200, got %d", rec.Code)
+	}
+	if body := rec.Body.String(); !strings.Contains(body, `"members":[]`) || strings.Contains(body, "null") {
+		t.Fatalf("expected empty members array, got %s", body)
+	}
+}
@@ -0,0 +1,66 @@
+package http
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"net/http"
+
+	"github.com/cloudyy74/pr-reviewer-service/internal/models"
+)
+
+type NotificationLog interface {
+	ListNotifications(ctx context.Context, q models.NotificationQuery) (*models.NotificationListResponse, error)
+}
+
+func SetupNotificationRoutes(mux *http.ServeMux, notifications NotificationLog, log *slog.Logger) error {
+	if mux == nil {
+		return errors.New("mux cannot be nil")
+	}
+	if notifications == nil {
+		return errors.New("notification log cannot be nil")
+	}
+	if log == nil {
+		return errors.New("logger cannot be nil")
+	}
+	r := router{
+		notifications: notifications,
+		log:           log,
+	}
+	mux.HandleFunc("GET /admin/notifications", r.panicMiddleware(r.loggingMiddleware(r.listNotifications)))
+	return nil
+}
+
+func (rtr *router) listNotifications(w http.ResponseWriter, r *http.Request) {
+	query := r.URL.Query()
+	q := models.NotificationQuery{
+		UserID:        query.Get("user_id"),
+		PullRequestID: query.Get("pull_request_id"),
+		Channel:       query.Get("channel"),
+		Status:        query.Get("status"),
+	}
+	var err error
+	if q.From, err = parseTimeParam(query.Get("from")); err != nil {
+		rtr.handleError(w, r, newResponseError(ErrCodeValidation, "from must be RFC3339 timestamp or YYYY-MM-DD date"))
+		return
+	}
+	if q.To, err = parseTimeParam(query.Get("to")); err != nil {
+		rtr.handleError(w, r, newResponseError(ErrCodeValidation, "to must be RFC3339 timestamp or YYYY-MM-DD date"))
+		return
+	}
+	if q.Limit, err = parseIntParam(query.Get("limit")); err != nil {
+		rtr.handleError(w, r, newResponseError(ErrCodeValidation, "limit must be an integer"))
+		return
+	}
+	if q.Offset, err = parseIntParam(query.Get("offset")); err != nil {
+		rtr.handleError(w, r, newResponseError(ErrCodeValidation, "offset must be an integer"))
+		return
+	}
+
+	resp, err := rtr.notifications.ListNotifications(r.Context(), q)
+	if err != nil {
+		rtr.handleError(w, r, err)
+		return
+	}
+	rtr.responseList(w, r, http.StatusOK, resp)
+}
@@ -0,0 +1,56 @@
+package http
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/cloudyy74/pr-reviewer-service/internal/models"
+)
+
+type fakeNotificationLog struct {
+	got models.NotificationQuery
+}
+
+func (f *fakeNotificationLog) ListNotifications(_ context.Context, q models.NotificationQuery) (*models.NotificationListResponse, error) {
+	f.got = q
+	return &models.NotificationListResponse{
+		Notifications: []*models.NotificationRecord{{ID: 1, UserID: q.UserID, Status: models.NotificationFailed, Attempts: 2}},
+		Total:         1,
+		Limit:         50,
+	}, nil
+}
+
+func TestListNotifications(t *testing.T) {
+	mux := http.NewServeMux()
+	notifications := &fakeNotificationLog{}
+	if err := SetupNotificationRoutes(mux, notifications, slog.New(slog.NewTextHandler(io.Discard, nil))); err != nil {
+		t.Fatalf("SetupNotificationRoutes returned err: %v", err)
+	}
+
+	rec := httptest.NewRecorder()
+	mux.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/admin/notifications?user_id=u1&pull_request_id=pr-1&status=FAILED&from=2025-03-01", nil))
+	if rec.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d", rec.Code)
+	}
+	if notifications.got.UserID != "u1" || notifications.got.PullRequestID != "pr-1" || notifications.got.From == nil {
+		t.Fatalf("unexpected query: %+v", notifications.got)
+	}
+	var resp models.NotificationListResponse
+	if err := json.NewDecoder(rec.Body).Decode(&resp); err != nil {
+		t.Fatalf("failed to decode response: %v", err)
+	}
+	if resp.Total != 1 || resp.Notifications[0].Attempts != 2 {
+		t.Fatalf("unexpected response: %+v", resp)
+	}
+
+	rec = httptest.NewRecorder()
+	mux.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/admin/notifications?limit=abc", nil))
+	if rec.Code != http.StatusBadRequest {
+		t.Fatalf("expected 400 for bad limit, got %d", rec.Code)
+	}
+}
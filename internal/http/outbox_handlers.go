@@ -0,0 +1,41 @@
+package http
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"net/http"
+
+	"github.com/cloudyy74/pr-reviewer-service/internal/models"
+)
+
+type OutboxMonitor interface {
+	Stats(ctx context.Context) (*models.OutboxStats, error)
+}
+
+func SetupOutboxRoutes(mux *http.ServeMux, outbox OutboxMonitor, log *slog.Logger) error {
+	if mux == nil {
+		return errors.New("mux cannot be nil")
+	}
+	if outbox == nil {
+		return errors.New("outbox monitor cannot be nil")
+	}
+	if log == nil {
+		return errors.New("logger cannot be nil")
+	}
+	r := router{
+		outbox: outbox,
+		log:    log,
+	}
+	mux.HandleFunc("GET /admin/outbox", r.panicMiddleware(r.loggingMiddleware(r.getOutboxStats)))
+	return nil
+}
+
+func (rtr *router) getOutboxStats(w http.ResponseWriter, r *http.Request) {
+	stats, err := rtr.outbox.Stats(r.Context())
+	if err != nil {
+		rtr.handleError(w, r, err)
+		return
+	}
+	rtr.responseJSON(w, http.StatusOK, stats)
+}
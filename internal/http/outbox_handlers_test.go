@@ -0,0 +1,60 @@
+package http
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/cloudyy74/pr-reviewer-service/internal/models"
+)
+
+type fakeOutboxMonitor struct {
+	stats *models.OutboxStats
+	err   error
+}
+
+func (f fakeOutboxMonitor) Stats(context.Context) (*models.OutboxStats, error) {
+	return f.stats, f.err
+}
+
+func TestGetOutboxStats(t *testing.T) {
+	mux := http.NewServeMux()
+	monitor := fakeOutboxMonitor{stats: &models.OutboxStats{
+		OutboxBacklog: models.OutboxBacklog{Pending: 3, Retrying: 1},
+		LagSeconds:    12.5,
+		Delivered:     40,
+	}}
+	if err := SetupOutboxRoutes(mux, monitor, slog.New(slog.NewTextHandler(io.Discard, nil))); err != nil {
+		t.Fatalf("SetupOutboxRoutes returned err: %v", err)
+	}
+
+	rec := httptest.NewRecorder()
+	mux.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/admin/outbox", nil))
+	if rec.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d", rec.Code)
+	}
+	var resp models.OutboxStats
+	if err := json.NewDecoder(rec.Body).Decode(&resp); err != nil {
+		t.Fatalf("failed to decode response: %v", err)
+	}
+	if resp.Pending != 3 || resp.Retrying != 1 || resp.LagSeconds != 12.5 || resp.Delivered != 40 {
+		t.Fatalf("unexpected stats: %+v", resp)
+	}
+}
+
+func TestGetOutboxStats_Error(t *testing.T) {
+	mux := http.NewServeMux()
+	if err := SetupOutboxRoutes(mux, fakeOutboxMonitor{err: errors.New("db down")}, slog.New(slog.NewTextHandler(io.Discard, nil))); err != nil {
+		t.Fatalf("SetupOutboxRoutes returned err: %v", err)
+	}
+	rec := httptest.NewRecorder()
+	mux.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/admin/outbox", nil))
+	if rec.Code != http.StatusInternalServerError {
+		t.Fatalf("expected 500, got %d", rec.Code)
+	}
+}
@@ -0,0 +1,142 @@
+package http
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+	"log/slog"
+	"net/http"
+	"regexp"
+	"strings"
+)
+
+const (
+	defaultPayloadLogBytes = 4096
+	redactedValue          = "[REDACTED]"
+)
+
+type payloadLogger struct {
+	next     http.Handler
+	log      *slog.Logger
+	redact   map[string]struct{}
+	redactRe *regexp.Regexp
+	maxBytes int
+}
+
+func PayloadLogger(next http.Handler, log *slog.Logger, redactFields []string, maxBytes int) http.Handler {
+	if maxBytes <= 0 {
+		maxBytes = defaultPayloadLogBytes
+	}
+	p := &payloadLogger{
+		next:     next,
+		log:      log,
+		redact:   make(map[string]struct{}, len(redactFields)),
+		maxBytes: maxBytes,
+	}
+	quoted := make([]string, 0, len(redactFields))
+	for _, field := range redactFields {
+		field = strings.ToLower(strings.TrimSpace(field))
+		if field == "" {
+			continue
+		}
+		p.redact[field] = struct{}{}
+		quoted = append(quoted, regexp.QuoteMeta(field))
+	}
+	if len(quoted) > 0 {
+		p.redactRe = regexp.MustCompile(`(?i)("(?:` + strings.Join(quoted, "|") + `)"\s*:\s*)("(?:[^"\\]|\\.)*"?|[^,}\]\s]+)`)
+	}
+	return p
+}
+
+func (p *payloadLogger) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	var reqBody []byte
+	reqTruncated := false
+	if r.Body != nil {
+		head, err := io.ReadAll(io.LimitReader(r.Body, int64(p.maxBytes)+1))
+		if err != nil {
+			p.log.Debug("cannot read request body for logging", slog.Any("error", err))
+		}
+		r.Body = struct {
+			io.Reader
+			io.Closer
+		}{io.MultiReader(bytes.NewReader(head), r.Body), r.Body}
+		reqBody, reqTruncated = p.capped(head)
+	}
+
+	rec := &payloadRecorder{ResponseWriter: w, status: http.StatusOK, limit: p.maxBytes + 1}
+	p.next.ServeHTTP(rec, r)
+	respBody, respTruncated := p.capped(rec.body.Bytes())
+
+	p.log.Debug("http payload",
+		slog.String("method", r.Method),
+		slog.String("path", r.URL.Path),
+		slog.Int("status", rec.status),
+		slog.String("request_body", p.redactBody(reqBody, reqTruncated)),
+		slog.Bool("request_truncated", reqTruncated),
+		slog.String("response_body", p.redactBody(respBody, respTruncated)),
+		slog.Bool("response_truncated", respTruncated),
+	)
+}
+
+func (p *payloadLogger) capped(body []byte) ([]byte, bool) {
+	if len(body) > p.maxBytes {
+		return body[:p.maxBytes], true
+	}
+	return body, false
+}
+
+func (p *payloadLogger) redactBody(body []byte, truncated bool) string {
+	if len(body) == 0 || len(p.redact) == 0 {
+		return string(body)
+	}
+	if !truncated {
+		var v any
+		if err := json.Unmarshal(body, &v); err == nil {
+			if out, err := json.Marshal(p.redactValue(v)); err == nil {
+				return string(out)
+			}
+		}
+	}
+	return p.redactRe.ReplaceAllString(string(body), `${1}"`+redactedValue+`"`)
+}
+
+func (p *payloadLogger) redactValue(v any) any {
+	switch val := v.(type) {
+	case map[string]any:
+		for key, item := range val {
+			if _, ok := p.redact[strings.ToLower(key)]; ok {
+				val[key] = redactedValue
+				continue
+			}
+			val[key] = p.redactValue(item)
+		}
+	case []any:
+		for i, item := range val {
+			val[i] = p.redactValue(item)
+		}
+	}
+	return v
+}
+
+type payloadRecorder struct {
+	http.ResponseWriter
+	status int
+	body   bytes.Buffer
+	limit  int
+}
+
+func (rec *payloadRecorder) WriteHeader(status int) {
+	rec.status = status
+	rec.ResponseWriter.WriteHeader(status)
+}
+
+func (rec *payloadRecorder) Write(b []byte) (int, error) {
+	if room := rec.limit - rec.body.Len(); room > 0 {
+		rec.body.Write(b[:min(room, len(b))])
+	}
+	return rec.ResponseWriter.Write(b)
+}
+
+func (rec *payloadRecorder) Unwrap() http.ResponseWriter {
+	return rec.ResponseWriter
+}
@@ -0,0 +1,68 @@
+package http
+
+import (
+	"bytes"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestPayloadLogger_RedactsAndPassesBody(t *testing.T) {
+	var logs bytes.Buffer
+	log := slog.New(slog.NewTextHandler(&logs, &slog.HandlerOptions{Level: slog.LevelDebug}))
+
+	var seen string
+	next := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		seen = string(body)
+		w.WriteHeader(http.StatusCreated)
+		_, _ = w.Write([]byte(`{"user":{"id":"u1","Token":"abc"}}`))
+	})
+	h := PayloadLogger(next, log, []string{"token", "password"}, 0)
+
+	reqBody := `{"user_id":"u1","password":"hunter2"}`
+	req := httptest.NewRequest(http.MethodPost, "/users/setIsActive", strings.NewReader(reqBody))
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, req)
+
+	if seen != reqBody {
+		t.Fatalf("handler got modified body %q", seen)
+	}
+	if rec.Code != http.StatusCreated || !strings.Contains(rec.Body.String(), `"Token":"abc"`) {
+		t.Fatalf("response must not be altered: %d %s", rec.Code, rec.Body.String())
+	}
+	out := logs.String()
+	if strings.Contains(out, "hunter2") || strings.Contains(out, "abc") {
+		t.Fatalf("secret leaked into logs: %s", out)
+	}
+	if !strings.Contains(out, "status=201") || !strings.Contains(out, redactedValue) {
+		t.Fatalf("unexpected log output: %s", out)
+	}
+}
+
+func TestPayloadLogger_TruncatesLargeBodies(t *testing.T) {
+	var logs bytes.Buffer
+	log := slog.New(slog.NewTextHandler(&logs, &slog.HandlerOptions{Level: slog.LevelDebug}))
+
+	var seen int
+	next := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		seen = len(body)
+	})
+	h := PayloadLogger(next, log, []string{"secret"}, 16)
+
+	reqBody := `{"secret":"s3cr3t-value","padding":"` + strings.Repeat("x", 64) + `"}`
+	req := httptest.NewRequest(http.MethodPost, "/hooks", strings.NewReader(reqBody))
+	h.ServeHTTP(httptest.NewRecorder(), req)
+
+	if seen != len(reqBody) {
+		t.Fatalf("handler read %d bytes, want %d", seen, len(reqBody))
+	}
+	out := logs.String()
+	if strings.Contains(out, "s3cr3t") || !strings.Contains(out, "request_truncated=true") {
+		t.Fatalf("unexpected log output: %s", out)
+	}
+}
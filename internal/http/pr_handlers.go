@@ -3,84 +3,693 @@ package http
 import (
 	"context"
 	"encoding/json"
+	"errors"
+	"io"
+	"log/slog"
 	"net/http"
+	"strconv"
 	"strings"
+	"time"
 
 	"github.com/cloudyy74/pr-reviewer-service/internal/models"
 )
 
 type PRService interface {
-	CreatePR(context.Context, *models.PRCreateRequest) (*models.PullRequest, error)
+	CreatePR(context.Context, *models.PRCreateRequest) (*models.PRResponse, error)
+	GetPR(context.Context, string) (*models.PullRequest, error)
+	ListPRs(context.Context, models.PRListQuery) (*models.PRListResponse, error)
 	GetUserReviews(context.Context, string) (*models.UserReviewsResponse, error)
 	MergePR(context.Context, *models.PRMergeRequest) (*models.PullRequest, error)
+	ClosePR(context.Context, *models.PRCloseRequest) (*models.PullRequest, error)
+	UpdatePR(context.Context, *models.PRUpdateRequest) (*models.PullRequest, error)
+	AddReviewer(context.Context, *models.PRAddReviewerRequest) (*models.PullRequest, error)
+	RemoveReviewer(context.Context, *models.PRRemoveReviewerRequest) (*models.PullRequest, error)
 	ReassignReviewer(context.Context, *models.PRReassignRequest) (*models.PRReassignResponse, error)
+	DeclineReview(context.Context, *models.PRDeclineRequest) (*models.PRReassignResponse, error)
+	ReassignAll(context.Context, *models.PRReassignAllRequest) (*models.PRReassignAllResponse, error)
+	BackfillReviewers(context.Context, *models.BackfillReviewersRequest) (*models.BackfillReviewersResponse, error)
+	TransferAssignments(context.Context, *models.TransferAssignmentsRequest) (*models.TransferAssignmentsResponse, error)
+	RebalanceAssignments(context.Context, *models.RebalanceAssignmentsRequest) (*models.RebalanceAssignmentsResponse, error)
 	GetAssignmentsStats(context.Context) (*models.AssignmentsStatsResponse, error)
+	GetPRsNeedingReviewers(context.Context, string) (*models.NeedReviewersResponse, error)
+	AwaitAssignment(context.Context, string, time.Duration) (*models.AwaitAssignmentResponse, error)
+	AcknowledgeReview(context.Context, *models.PRAcknowledgeRequest) (*models.PullRequest, error)
+	ApproveReview(context.Context, *models.PRApproveRequest) (*models.PullRequest, error)
+	GetAssignmentHistory(context.Context, models.AssignmentHistoryQuery) (*models.AssignmentHistoryResponse, error)
+	PreviewAssignment(context.Context, string) (*models.AssignmentPreviewResponse, error)
+	BatchGetPRs(context.Context, []string) (*models.PRBatchGetResponse, error)
+	GetAgenda(context.Context, string) (*models.AgendaResponse, error)
 }
 
+const awaitWriteSlack = 5 * time.Second
+
+const idempotencyKeyHeader = "Idempotency-Key"
+
 func (rtr *router) createPR(w http.ResponseWriter, r *http.Request) {
+	exp := rtr.prExpanders()
+	expand, err := exp.parse(r)
+	if err != nil {
+		rtr.handleError(w, r, err)
+		return
+	}
+
 	var req models.PRCreateRequest
 	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
-		rtr.handleError(w, newResponseError(ErrCodeBadRequest, "bad json request"))
+		rtr.handleError(w, r, newResponseError(ErrCodeBadRequest, "bad json request"))
 		return
 	}
 
-	pr, err := rtr.prService.CreatePR(r.Context(), &req)
+	// Signed clients relay provider webhooks, which may be redelivered. The
+	// pull request id is the natural key, so the Idempotency-Key value
+	// itself is not stored and an explicit key without an id is rejected
+	// by the service.
+	if strings.TrimSpace(r.Header.Get(idempotencyKeyHeader)) != "" {
+		req.Idempotent = true
+	}
+	if _, signed := SignedClientFromContext(r.Context()); signed && strings.TrimSpace(req.ID) != "" {
+		req.Idempotent = true
+	}
+
+	resp, err := rtr.prService.CreatePR(r.Context(), &req)
 	if err != nil {
-		rtr.handleError(w, err)
+		rtr.handleError(w, r, err)
+		return
+	}
+	if err := exp.apply(r.Context(), expand, resp); err != nil {
+		rtr.handleError(w, r, err)
 		return
 	}
 
-	rtr.responseJSON(w, http.StatusCreated, &models.PRResponse{PR: *pr})
+	status := http.StatusCreated
+	if resp.Existing {
+		status = http.StatusOK
+	}
+	rtr.responseJSON(w, status, resp)
 }
 
 func (rtr *router) getUserReviews(w http.ResponseWriter, r *http.Request) {
-	userID := strings.TrimSpace(r.URL.Query().Get("user_id"))
+	rtr.writeUserReviews(w, r, strings.TrimSpace(r.URL.Query().Get("user_id")))
+}
+
+func (rtr *router) writeUserReviews(w http.ResponseWriter, r *http.Request, userID string) {
+	exp := rtr.reviewsExpanders()
+	expand, err := exp.parse(r)
+	if err != nil {
+		rtr.handleError(w, r, err)
+		return
+	}
+
 	resp, err := rtr.prService.GetUserReviews(r.Context(), userID)
 	if err != nil {
-		rtr.handleError(w, err)
+		rtr.handleError(w, r, err)
 		return
 	}
+	if err := exp.apply(r.Context(), expand, resp); err != nil {
+		rtr.handleError(w, r, err)
+		return
+	}
+
+	rtr.responseList(w, r, http.StatusOK, resp)
+}
+
+func (rtr *router) getUserAgenda(w http.ResponseWriter, r *http.Request) {
+	resp, err := rtr.prService.GetAgenda(r.Context(), strings.TrimSpace(r.URL.Query().Get("user_id")))
+	if err != nil {
+		rtr.handleError(w, r, err)
+		return
+	}
+	rtr.responseList(w, r, http.StatusOK, resp)
+}
 
+func (rtr *router) getPR(w http.ResponseWriter, r *http.Request) {
+	exp := rtr.prExpanders()
+	expand, err := exp.parse(r)
+	if err != nil {
+		rtr.handleError(w, r, err)
+		return
+	}
+
+	pr, err := rtr.prService.GetPR(r.Context(), r.URL.Query().Get("pull_request_id"))
+	if err != nil {
+		rtr.handleError(w, r, err)
+		return
+	}
+
+	resp := &models.PRResponse{PR: *pr}
+	if err := exp.apply(r.Context(), expand, resp); err != nil {
+		rtr.handleError(w, r, err)
+		return
+	}
 	rtr.responseJSON(w, http.StatusOK, resp)
 }
 
+func (rtr *router) listPRs(w http.ResponseWriter, r *http.Request) {
+	query := r.URL.Query()
+	q := models.PRListQuery{
+		Status:     query.Get("status"),
+		AuthorID:   query.Get("author_id"),
+		ReviewerID: query.Get("reviewer_id"),
+		TeamName:   query.Get("team_name"),
+	}
+	// label may be repeated or hold a comma-separated list.
+	for _, v := range query["label"] {
+		q.Labels = append(q.Labels, strings.Split(v, ",")...)
+	}
+	var err error
+	for _, p := range []struct {
+		name string
+		dst  **time.Time
+	}{
+		{"created_from", &q.CreatedFrom},
+		{"created_to", &q.CreatedTo},
+		{"merged_from", &q.MergedFrom},
+		{"merged_to", &q.MergedTo},
+	} {
+		if *p.dst, err = parseTimeParam(query.Get(p.name)); err != nil {
+			rtr.handleError(w, r, newResponseError(ErrCodeValidation, p.name+" must be RFC3339 timestamp or YYYY-MM-DD date"))
+			return
+		}
+	}
+	if q.Overdue, err = parseBoolParam(query.Get("overdue")); err != nil {
+		rtr.handleError(w, r, newResponseError(ErrCodeValidation, "overdue must be a boolean"))
+		return
+	}
+	if q.Limit, err = parseIntParam(query.Get("limit")); err != nil {
+		rtr.handleError(w, r, newResponseError(ErrCodeValidation, "limit must be an integer"))
+		return
+	}
+	if q.Offset, err = parseIntParam(query.Get("offset")); err != nil {
+		rtr.handleError(w, r, newResponseError(ErrCodeValidation, "offset must be an integer"))
+		return
+	}
+
+	resp, err := rtr.prService.ListPRs(r.Context(), q)
+	if err != nil {
+		rtr.handleError(w, r, err)
+		return
+	}
+	rtr.responseList(w, r, http.StatusOK, resp)
+}
+
 func (rtr *router) mergePR(w http.ResponseWriter, r *http.Request) {
+	exp := rtr.prExpanders()
+	expand, err := exp.parse(r)
+	if err != nil {
+		rtr.handleError(w, r, err)
+		return
+	}
+
 	var req models.PRMergeRequest
 	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
-		rtr.handleError(w, newResponseError(ErrCodeBadRequest, "bad json request"))
+		rtr.handleError(w, r, newResponseError(ErrCodeBadRequest, "bad json request"))
 		return
 	}
 
 	pr, err := rtr.prService.MergePR(r.Context(), &req)
 	if err != nil {
-		rtr.handleError(w, err)
+		rtr.handleError(w, r, err)
+		return
+	}
+
+	resp := &models.PRResponse{PR: *pr}
+	if err := exp.apply(r.Context(), expand, resp); err != nil {
+		rtr.handleError(w, r, err)
+		return
+	}
+	rtr.responseJSON(w, http.StatusOK, resp)
+}
+
+func (rtr *router) closePR(w http.ResponseWriter, r *http.Request) {
+	exp := rtr.prExpanders()
+	expand, err := exp.parse(r)
+	if err != nil {
+		rtr.handleError(w, r, err)
+		return
+	}
+
+	var req models.PRCloseRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		rtr.handleError(w, r, newResponseError(ErrCodeBadRequest, "bad json request"))
+		return
+	}
+
+	pr, err := rtr.prService.ClosePR(r.Context(), &req)
+	if err != nil {
+		rtr.handleError(w, r, err)
+		return
+	}
+
+	resp := &models.PRResponse{PR: *pr}
+	if err := exp.apply(r.Context(), expand, resp); err != nil {
+		rtr.handleError(w, r, err)
+		return
+	}
+	rtr.responseJSON(w, http.StatusOK, resp)
+}
+
+func (rtr *router) updatePR(w http.ResponseWriter, r *http.Request) {
+	exp := rtr.prExpanders()
+	expand, err := exp.parse(r)
+	if err != nil {
+		rtr.handleError(w, r, err)
+		return
+	}
+
+	var req models.PRUpdateRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		rtr.handleError(w, r, newResponseError(ErrCodeBadRequest, "bad json request"))
+		return
+	}
+
+	pr, err := rtr.prService.UpdatePR(r.Context(), &req)
+	if err != nil {
+		rtr.handleError(w, r, err)
+		return
+	}
+
+	resp := &models.PRResponse{PR: *pr}
+	if err := exp.apply(r.Context(), expand, resp); err != nil {
+		rtr.handleError(w, r, err)
+		return
+	}
+	rtr.responseJSON(w, http.StatusOK, resp)
+}
+
+func (rtr *router) addReviewer(w http.ResponseWriter, r *http.Request) {
+	exp := rtr.prExpanders()
+	expand, err := exp.parse(r)
+	if err != nil {
+		rtr.handleError(w, r, err)
+		return
+	}
+
+	var req models.PRAddReviewerRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		rtr.handleError(w, r, newResponseError(ErrCodeBadRequest, "bad json request"))
+		return
+	}
+
+	pr, err := rtr.prService.AddReviewer(r.Context(), &req)
+	if err != nil {
+		rtr.handleError(w, r, err)
+		return
+	}
+
+	resp := &models.PRResponse{PR: *pr}
+	if err := exp.apply(r.Context(), expand, resp); err != nil {
+		rtr.handleError(w, r, err)
+		return
+	}
+	rtr.responseJSON(w, http.StatusOK, resp)
+}
+
+func (rtr *router) removeReviewer(w http.ResponseWriter, r *http.Request) {
+	exp := rtr.prExpanders()
+	expand, err := exp.parse(r)
+	if err != nil {
+		rtr.handleError(w, r, err)
 		return
 	}
 
-	rtr.responseJSON(w, http.StatusOK, &models.PRResponse{PR: *pr})
+	var req models.PRRemoveReviewerRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		rtr.handleError(w, r, newResponseError(ErrCodeBadRequest, "bad json request"))
+		return
+	}
+
+	pr, err := rtr.prService.RemoveReviewer(r.Context(), &req)
+	if err != nil {
+		rtr.handleError(w, r, err)
+		return
+	}
+
+	resp := &models.PRResponse{PR: *pr}
+	if err := exp.apply(r.Context(), expand, resp); err != nil {
+		rtr.handleError(w, r, err)
+		return
+	}
+	rtr.responseJSON(w, http.StatusOK, resp)
 }
 
 func (rtr *router) reassignPR(w http.ResponseWriter, r *http.Request) {
+	exp := rtr.reassignExpanders()
+	expand, err := exp.parse(r)
+	if err != nil {
+		rtr.handleError(w, r, err)
+		return
+	}
+
 	var req models.PRReassignRequest
 	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
-		rtr.handleError(w, newResponseError(ErrCodeBadRequest, "bad json request"))
+		rtr.handleError(w, r, newResponseError(ErrCodeBadRequest, "bad json request"))
 		return
 	}
 
 	resp, err := rtr.prService.ReassignReviewer(r.Context(), &req)
 	if err != nil {
-		rtr.handleError(w, err)
+		rtr.handleError(w, r, err)
+		return
+	}
+	if err := exp.apply(r.Context(), expand, resp); err != nil {
+		rtr.handleError(w, r, err)
+		return
+	}
+
+	rtr.responseJSON(w, http.StatusOK, resp)
+}
+
+// declinePR takes the reviewer off the pull request and assigns a
+// replacement. Without user_id the authenticated user declines.
+func (rtr *router) declinePR(w http.ResponseWriter, r *http.Request) {
+	exp := rtr.reassignExpanders()
+	expand, err := exp.parse(r)
+	if err != nil {
+		rtr.handleError(w, r, err)
+		return
+	}
+
+	var req models.PRDeclineRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		rtr.handleError(w, r, newResponseError(ErrCodeBadRequest, "bad json request"))
+		return
+	}
+	if req.UserID == "" {
+		if user, ok := UserFromContext(r.Context()); ok {
+			req.UserID = user.ID
+		}
+	}
+
+	resp, err := rtr.prService.DeclineReview(r.Context(), &req)
+	if err != nil {
+		rtr.handleError(w, r, err)
+		return
+	}
+	if err := exp.apply(r.Context(), expand, resp); err != nil {
+		rtr.handleError(w, r, err)
+		return
+	}
+
+	rtr.responseJSON(w, http.StatusOK, resp)
+}
+
+func (rtr *router) reassignAll(w http.ResponseWriter, r *http.Request) {
+	var req models.PRReassignAllRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		rtr.handleError(w, r, newResponseError(ErrCodeBadRequest, "bad json request"))
+		return
+	}
+
+	resp, err := rtr.prService.ReassignAll(r.Context(), &req)
+	if err != nil {
+		rtr.handleError(w, r, err)
+		return
+	}
+	rtr.mapResultErrors(resp.Results)
+
+	rtr.responseJSON(w, http.StatusOK, resp)
+}
+
+func (rtr *router) transferAssignments(w http.ResponseWriter, r *http.Request) {
+	var req models.TransferAssignmentsRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		rtr.handleError(w, r, newResponseError(ErrCodeBadRequest, "bad json request"))
+		return
+	}
+
+	resp, err := rtr.prService.TransferAssignments(r.Context(), &req)
+	if err != nil {
+		rtr.handleError(w, r, err)
+		return
+	}
+	rtr.mapResultErrors(resp.Results)
+
+	rtr.responseJSON(w, http.StatusOK, resp)
+}
+
+func (rtr *router) rebalanceAssignments(w http.ResponseWriter, r *http.Request) {
+	var req models.RebalanceAssignmentsRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		rtr.handleError(w, r, newResponseError(ErrCodeBadRequest, "bad json request"))
+		return
+	}
+
+	resp, err := rtr.prService.RebalanceAssignments(r.Context(), &req)
+	if err != nil {
+		rtr.handleError(w, r, err)
+		return
+	}
+	rtr.responseJSON(w, http.StatusOK, resp)
+}
+
+func (rtr *router) backfillReviewers(w http.ResponseWriter, r *http.Request) {
+	var req models.BackfillReviewersRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil && !errors.Is(err, io.EOF) {
+		rtr.handleError(w, r, newResponseError(ErrCodeBadRequest, "bad json request"))
+		return
+	}
+
+	resp, err := rtr.prService.BackfillReviewers(r.Context(), &req)
+	if err != nil {
+		rtr.handleError(w, r, err)
+		return
+	}
+	rtr.mapBackfillErrors(resp.Results)
+
+	rtr.responseJSON(w, http.StatusOK, resp)
+}
+
+func (rtr *router) mapResultErrors(results []*models.PRReassignResult) {
+	for _, result := range results {
+		if result.Err != nil {
+			respErr := rtr.mapError(result.Err)
+			result.Error = &models.Error{Code: respErr.Code, Message: respErr.Message}
+		}
+	}
+}
+
+func (rtr *router) mapBackfillErrors(results []*models.PRBackfillResult) {
+	for _, result := range results {
+		if result.Err != nil {
+			respErr := rtr.mapError(result.Err)
+			result.Error = &models.Error{Code: respErr.Code, Message: respErr.Message}
+		}
+	}
+}
+
+func (rtr *router) getPRsNeedingReviewers(w http.ResponseWriter, r *http.Request) {
+	resp, err := rtr.prService.GetPRsNeedingReviewers(r.Context(), r.URL.Query().Get("team_name"))
+	if err != nil {
+		rtr.handleError(w, r, err)
+		return
+	}
+	rtr.responseList(w, r, http.StatusOK, resp)
+}
+
+func (rtr *router) batchGetPRs(w http.ResponseWriter, r *http.Request) {
+	var req models.PRBatchGetRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		rtr.handleError(w, r, newResponseError(ErrCodeBadRequest, "bad json request"))
+		return
+	}
+
+	resp, err := rtr.prService.BatchGetPRs(r.Context(), req.IDs)
+	if err != nil {
+		rtr.handleError(w, r, err)
+		return
+	}
+	rtr.responseJSON(w, http.StatusOK, resp)
+}
+
+func (rtr *router) previewAssignment(w http.ResponseWriter, r *http.Request) {
+	resp, err := rtr.prService.PreviewAssignment(r.Context(), r.URL.Query().Get("author_id"))
+	if err != nil {
+		rtr.handleError(w, r, err)
+		return
+	}
+	rtr.responseJSON(w, http.StatusOK, resp)
+}
+
+func (rtr *router) acknowledgeReview(w http.ResponseWriter, r *http.Request) {
+	exp := rtr.prExpanders()
+	expand, err := exp.parse(r)
+	if err != nil {
+		rtr.handleError(w, r, err)
+		return
+	}
+
+	var req models.PRAcknowledgeRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		rtr.handleError(w, r, newResponseError(ErrCodeBadRequest, "bad json request"))
+		return
+	}
+
+	pr, err := rtr.prService.AcknowledgeReview(r.Context(), &req)
+	if err != nil {
+		rtr.handleError(w, r, err)
 		return
 	}
 
+	resp := &models.PRResponse{PR: *pr}
+	if err := exp.apply(r.Context(), expand, resp); err != nil {
+		rtr.handleError(w, r, err)
+		return
+	}
+	rtr.responseJSON(w, http.StatusOK, resp)
+}
+
+func (rtr *router) approveReview(w http.ResponseWriter, r *http.Request) {
+	exp := rtr.prExpanders()
+	expand, err := exp.parse(r)
+	if err != nil {
+		rtr.handleError(w, r, err)
+		return
+	}
+
+	var req models.PRApproveRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		rtr.handleError(w, r, newResponseError(ErrCodeBadRequest, "bad json request"))
+		return
+	}
+
+	pr, err := rtr.prService.ApproveReview(r.Context(), &req)
+	if err != nil {
+		rtr.handleError(w, r, err)
+		return
+	}
+
+	resp := &models.PRResponse{PR: *pr}
+	if err := exp.apply(r.Context(), expand, resp); err != nil {
+		rtr.handleError(w, r, err)
+		return
+	}
 	rtr.responseJSON(w, http.StatusOK, resp)
 }
 
 func (rtr *router) getAssignmentsStats(w http.ResponseWriter, r *http.Request) {
+	exp := rtr.statsExpanders()
+	expand, err := exp.parse(r)
+	if err != nil {
+		rtr.handleError(w, r, err)
+		return
+	}
+
 	stats, err := rtr.prService.GetAssignmentsStats(r.Context())
 	if err != nil {
-		rtr.handleError(w, err)
+		rtr.handleError(w, r, err)
+		return
+	}
+	if err := exp.apply(r.Context(), expand, stats); err != nil {
+		rtr.handleError(w, r, err)
 		return
 	}
 	rtr.responseJSON(w, http.StatusOK, stats)
 }
+
+func (rtr *router) awaitAssignment(w http.ResponseWriter, r *http.Request) {
+	userID := strings.TrimSpace(r.URL.Query().Get("user_id"))
+	timeout, err := parseTimeout(r.URL.Query().Get("timeout"))
+	if err != nil {
+		rtr.handleError(w, r, newResponseError(ErrCodeValidation, "timeout must be a duration like 30s"))
+		return
+	}
+
+	rc := http.NewResponseController(w)
+	if err := rc.SetWriteDeadline(time.Now().Add(maxAwaitDeadline(timeout))); err != nil {
+		rtr.log.Debug("cannot extend write deadline", slog.Any("error", err))
+	}
+
+	resp, err := rtr.prService.AwaitAssignment(r.Context(), userID, timeout)
+	if err != nil {
+		rtr.handleError(w, r, err)
+		return
+	}
+	rtr.responseJSON(w, http.StatusOK, resp)
+}
+
+func (rtr *router) getAssignmentHistory(w http.ResponseWriter, r *http.Request) {
+	exp := rtr.historyExpanders()
+	expand, err := exp.parse(r)
+	if err != nil {
+		rtr.handleError(w, r, err)
+		return
+	}
+
+	query := r.URL.Query()
+	q := models.AssignmentHistoryQuery{UserID: strings.TrimSpace(query.Get("user_id"))}
+	if q.From, err = parseTimeParam(query.Get("from")); err != nil {
+		rtr.handleError(w, r, newResponseError(ErrCodeValidation, "from must be RFC3339 timestamp or YYYY-MM-DD date"))
+		return
+	}
+	if q.To, err = parseTimeParam(query.Get("to")); err != nil {
+		rtr.handleError(w, r, newResponseError(ErrCodeValidation, "to must be RFC3339 timestamp or YYYY-MM-DD date"))
+		return
+	}
+	if q.Limit, err = parseIntParam(query.Get("limit")); err != nil {
+		rtr.handleError(w, r, newResponseError(ErrCodeValidation, "limit must be an integer"))
+		return
+	}
+	if q.Offset, err = parseIntParam(query.Get("offset")); err != nil {
+		rtr.handleError(w, r, newResponseError(ErrCodeValidation, "offset must be an integer"))
+		return
+	}
+
+	resp, err := rtr.prService.GetAssignmentHistory(r.Context(), q)
+	if err != nil {
+		rtr.handleError(w, r, err)
+		return
+	}
+	if err := exp.apply(r.Context(), expand, resp); err != nil {
+		rtr.handleError(w, r, err)
+		return
+	}
+	rtr.responseList(w, r, http.StatusOK, resp)
+}
+
+func parseTimeParam(raw string) (*time.Time, error) {
+	raw = strings.TrimSpace(raw)
+	if raw == "" {
+		return nil, nil
+	}
+	t, err := time.Parse(time.RFC3339, raw)
+	if err != nil {
+		t, err = time.Parse(time.DateOnly, raw)
+		if err != nil {
+			return nil, err
+		}
+	}
+	return &t, nil
+}
+
+func parseBoolParam(raw string) (bool, error) {
+	raw = strings.TrimSpace(raw)
+	if raw == "" {
+		return false, nil
+	}
+	return strconv.ParseBool(raw)
+}
+
+func parseIntParam(raw string) (int, error) {
+	raw = strings.TrimSpace(raw)
+	if raw == "" {
+		return 0, nil
+	}
+	return strconv.Atoi(raw)
+}
+
+func parseTimeout(raw string) (time.Duration, error) {
+	raw = strings.TrimSpace(raw)
+	if raw == "" {
+		return 0, nil
+	}
+	if seconds, err := strconv.Atoi(raw); err == nil {
+		return time.Duration(seconds) * time.Second, nil
+	}
+	return time.ParseDuration(raw)
+}
+
+func maxAwaitDeadline(timeout time.Duration) time.Duration {
+	if timeout <= 0 || timeout > time.Minute {
+		timeout = time.Minute
+	}
+	return timeout + awaitWriteSlack
+}
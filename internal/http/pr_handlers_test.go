@@ -10,22 +10,78 @@ import (
 	"log/slog"
 	"net/http"
 	"net/http/httptest"
+	"slices"
 	"strings"
 	"testing"
+	"time"
 
 	"github.com/cloudyy74/pr-reviewer-service/internal/models"
 	"github.com/cloudyy74/pr-reviewer-service/internal/service"
 )
 
 type fakePRService struct {
-	createFn   func(ctx context.Context, req *models.PRCreateRequest) (*models.PullRequest, error)
-	reviewsFn  func(ctx context.Context, userID string) (*models.UserReviewsResponse, error)
-	mergeFn    func(ctx context.Context, req *models.PRMergeRequest) (*models.PullRequest, error)
-	reassignFn func(ctx context.Context, req *models.PRReassignRequest) (*models.PRReassignResponse, error)
-	statsFn    func(ctx context.Context) (*models.AssignmentsStatsResponse, error)
+	createFn      func(ctx context.Context, req *models.PRCreateRequest) (*models.PRResponse, error)
+	reviewsFn     func(ctx context.Context, userID string) (*models.UserReviewsResponse, error)
+	mergeFn       func(ctx context.Context, req *models.PRMergeRequest) (*models.PullRequest, error)
+	closeFn       func(ctx context.Context, req *models.PRCloseRequest) (*models.PullRequest, error)
+	updateFn      func(ctx context.Context, req *models.PRUpdateRequest) (*models.PullRequest, error)
+	addFn         func(ctx context.Context, req *models.PRAddReviewerRequest) (*models.PullRequest, error)
+	removeFn      func(ctx context.Context, req *models.PRRemoveReviewerRequest) (*models.PullRequest, error)
+	getFn         func(ctx context.Context, prID string) (*models.PullRequest, error)
+	reassignFn    func(ctx context.Context, req *models.PRReassignRequest) (*models.PRReassignResponse, error)
+	reassignAllFn func(ctx context.Context, req *models.PRReassignAllRequest) (*models.PRReassignAllResponse, error)
+	declineFn     func(ctx context.Context, req *models.PRDeclineRequest) (*models.PRReassignResponse, error)
+	backfillFn    func(ctx context.Context, req *models.BackfillReviewersRequest) (*models.BackfillReviewersResponse, error)
+	transferFn    func(ctx context.Context, req *models.TransferAssignmentsRequest) (*models.TransferAssignmentsResponse, error)
+	needingFn     func(ctx context.Context, teamName string) (*models.NeedReviewersResponse, error)
+	statsFn       func(ctx context.Context) (*models.AssignmentsStatsResponse, error)
+	awaitFn       func(ctx context.Context, userID string, timeout time.Duration) (*models.AwaitAssignmentResponse, error)
+	ackFn         func(ctx context.Context, req *models.PRAcknowledgeRequest) (*models.PullRequest, error)
+	historyFn     func(ctx context.Context, q models.AssignmentHistoryQuery) (*models.AssignmentHistoryResponse, error)
+	listFn        func(ctx context.Context, q models.PRListQuery) (*models.PRListResponse, error)
+	previewFn     func(ctx context.Context, authorID string) (*models.AssignmentPreviewResponse, error)
+	rebalanceFn   func(ctx context.Context, req *models.RebalanceAssignmentsRequest) (*models.RebalanceAssignmentsResponse, error)
+	batchGetFn    func(ctx context.Context, prIDs []string) (*models.PRBatchGetResponse, error)
+	agendaFn      func(ctx context.Context, userID string) (*models.AgendaResponse, error)
+	approveFn     func(ctx context.Context, req *models.PRApproveRequest) (*models.PullRequest, error)
 }
 
-func (f *fakePRService) CreatePR(ctx context.Context, req *models.PRCreateRequest) (*models.PullRequest, error) {
+func (f *fakePRService) ApproveReview(ctx context.Context, req *models.PRApproveRequest) (*models.PullRequest, error) {
+	if f.approveFn == nil {
+		return nil, errors.New("not implemented")
+	}
+	return f.approveFn(ctx, req)
+}
+
+func (f *fakePRService) GetAgenda(ctx context.Context, userID string) (*models.AgendaResponse, error) {
+	if f.agendaFn == nil {
+		return nil, errors.New("not implemented")
+	}
+	return f.agendaFn(ctx, userID)
+}
+
+func (f *fakePRService) BatchGetPRs(ctx context.Context, prIDs []string) (*models.PRBatchGetResponse, error) {
+	if f.batchGetFn == nil {
+		return nil, errors.New("not implemented")
+	}
+	return f.batchGetFn(ctx, prIDs)
+}
+
+func (f *fakePRService) RebalanceAssignments(ctx context.Context, req *models.RebalanceAssignmentsRequest) (*models.RebalanceAssignmentsResponse, error) {
+	if f.rebalanceFn == nil {
+		return nil, errors.New("not implemented")
+	}
+	return f.rebalanceFn(ctx, req)
+}
+
+func (f *fakePRService) PreviewAssignment(ctx context.Context, authorID string) (*models.AssignmentPreviewResponse, error) {
+	if f.previewFn == nil {
+		return nil, errors.New("not implemented")
+	}
+	return f.previewFn(ctx, authorID)
+}
+
+func (f *fakePRService) CreatePR(ctx context.Context, req *models.PRCreateRequest) (*models.PRResponse, error) {
 	if f.createFn == nil {
 		return nil, errors.New("not implemented")
 	}
@@ -46,6 +102,48 @@ func (f *fakePRService) MergePR(ctx context.Context, req *models.PRMergeRequest)
 	return f.mergeFn(ctx, req)
 }
 
+func (f *fakePRService) GetPR(ctx context.Context, prID string) (*models.PullRequest, error) {
+	if f.getFn == nil {
+		return nil, errors.New("not implemented")
+	}
+	return f.getFn(ctx, prID)
+}
+
+func (f *fakePRService) ListPRs(ctx context.Context, q models.PRListQuery) (*models.PRListResponse, error) {
+	if f.listFn == nil {
+		return nil, errors.New("not implemented")
+	}
+	return f.listFn(ctx, q)
+}
+
+func (f *fakePRService) ClosePR(ctx context.Context, req *models.PRCloseRequest) (*models.PullRequest, error) {
+	if f.closeFn == nil {
+		return nil, errors.New("not implemented")
+	}
+	return f.closeFn(ctx, req)
+}
+
+func (f *fakePRService) UpdatePR(ctx context.Context, req *models.PRUpdateRequest) (*models.PullRequest, error) {
+	if f.updateFn == nil {
+		return nil, errors.New("not implemented")
+	}
+	return f.updateFn(ctx, req)
+}
+
+func (f *fakePRService) AddReviewer(ctx context.Context, req *models.PRAddReviewerRequest) (*models.PullRequest, error) {
+	if f.addFn == nil {
+		return nil, errors.New("not implemented")
+	}
+	return f.addFn(ctx, req)
+}
+
+func (f *fakePRService) RemoveReviewer(ctx context.Context, req *models.PRRemoveReviewerRequest) (*models.PullRequest, error) {
+	if f.removeFn == nil {
+		return nil, errors.New("not implemented")
+	}
+	return f.removeFn(ctx, req)
+}
+
 func (f *fakePRService) ReassignReviewer(ctx context.Context, req *models.PRReassignRequest) (*models.PRReassignResponse, error) {
 	if f.reassignFn == nil {
 		return nil, errors.New("not implemented")
@@ -53,6 +151,41 @@ func (f *fakePRService) ReassignReviewer(ctx context.Context, req *models.PRReas
 	return f.reassignFn(ctx, req)
 }
 
+func (f *fakePRService) DeclineReview(ctx context.Context, req *models.PRDeclineRequest) (*models.PRReassignResponse, error) {
+	if f.declineFn == nil {
+		return nil, errors.New("not implemented")
+	}
+	return f.declineFn(ctx, req)
+}
+
+func (f *fakePRService) ReassignAll(ctx context.Context, req *models.PRReassignAllRequest) (*models.PRReassignAllResponse, error) {
+	if f.reassignAllFn == nil {
+		return nil, errors.New("not implemented")
+	}
+	return f.reassignAllFn(ctx, req)
+}
+
+func (f *fakePRService) BackfillReviewers(ctx context.Context, req *models.BackfillReviewersRequest) (*models.BackfillReviewersResponse, error) {
+	if f.backfillFn == nil {
+		return nil, errors.New("not implemented")
+	}
+	return f.backfillFn(ctx, req)
+}
+
+func (f *fakePRService) TransferAssignments(ctx context.Context, req *models.TransferAssignmentsRequest) (*models.TransferAssignmentsResponse, error) {
+	if f.transferFn == nil {
+		return nil, errors.New("not implemented")
+	}
+	return f.transferFn(ctx, req)
+}
+
+func (f *fakePRService) GetPRsNeedingReviewers(ctx context.Context, teamName string) (*models.NeedReviewersResponse, error) {
+	if f.needingFn == nil {
+		return nil, errors.New("not implemented")
+	}
+	return f.needingFn(ctx, teamName)
+}
+
 func (f *fakePRService) GetAssignmentsStats(ctx context.Context) (*models.AssignmentsStatsResponse, error) {
 	if f.statsFn == nil {
 		return nil, errors.New("not implemented")
@@ -60,6 +193,27 @@ func (f *fakePRService) GetAssignmentsStats(ctx context.Context) (*models.Assign
 	return f.statsFn(ctx)
 }
 
+func (f *fakePRService) AwaitAssignment(ctx context.Context, userID string, timeout time.Duration) (*models.AwaitAssignmentResponse, error) {
+	if f.awaitFn == nil {
+		return nil, errors.New("not implemented")
+	}
+	return f.awaitFn(ctx, userID, timeout)
+}
+
+func (f *fakePRService) AcknowledgeReview(ctx context.Context, req *models.PRAcknowledgeRequest) (*models.PullRequest, error) {
+	if f.ackFn == nil {
+		return nil, errors.New("not implemented")
+	}
+	return f.ackFn(ctx, req)
+}
+
+func (f *fakePRService) GetAssignmentHistory(ctx context.Context, q models.AssignmentHistoryQuery) (*models.AssignmentHistoryResponse, error) {
+	if f.historyFn == nil {
+		return nil, errors.New("not implemented")
+	}
+	return f.historyFn(ctx, q)
+}
+
 func newTestRouterWithPRService(svc PRService) *router {
 	return &router{
 		prService: svc,
@@ -75,11 +229,11 @@ func TestCreatePR_Success(t *testing.T) {
 		Status:   models.StatusOpen,
 	}
 	svc := &fakePRService{
-		createFn: func(ctx context.Context, req *models.PRCreateRequest) (*models.PullRequest, error) {
+		createFn: func(ctx context.Context, req *models.PRCreateRequest) (*models.PRResponse, error) {
 			if req.ID != "123" {
 				t.Fatalf("expected ID 123, got %s", req.ID)
 			}
-			return want, nil
+			return &models.PRResponse{PR: *want}, nil
 		},
 	}
 	rtr := newTestRouterWithPRService(svc)
@@ -102,9 +256,66 @@ func TestCreatePR_Success(t *testing.T) {
 	}
 }
 
+func TestCreatePR_SignedRedelivery(t *testing.T) {
+	svc := &fakePRService{
+		createFn: func(_ context.Context, req *models.PRCreateRequest) (*models.PRResponse, error) {
+			if !req.Idempotent {
+				t.Fatalf("expected a signed create to be idempotent")
+			}
+			return &models.PRResponse{PR: models.PullRequest{ID: "123"}, Existing: true}, nil
+		},
+	}
+	rtr := newTestRouterWithPRService(svc)
+
+	body := `{"pull_request_id":"123","pull_request_name":"Fix bug","author_id":"u1"}`
+	req := httptest.NewRequest(http.MethodPost, "/pullRequest/create", bytes.NewBufferString(body))
+	req = req.WithContext(context.WithValue(req.Context(), signedClientCtxKey{}, "github-relay"))
+	rec := httptest.NewRecorder()
+
+	rtr.createPR(rec, req)
+
+	if rec.Code != http.StatusOK {
+		t.Fatalf("expected status 200 for an existing pull request, got %d", rec.Code)
+	}
+}
+
+func TestCreatePR_Idempotent(t *testing.T) {
+	var idempotent bool
+	svc := &fakePRService{
+		createFn: func(_ context.Context, req *models.PRCreateRequest) (*models.PRResponse, error) {
+			idempotent = req.Idempotent
+			return &models.PRResponse{PR: models.PullRequest{ID: "123"}, Existing: req.Idempotent}, nil
+		},
+	}
+	rtr := newTestRouterWithPRService(svc)
+
+	cases := []struct {
+		name   string
+		body   string
+		key    string
+		want   bool
+		status int
+	}{
+		{"plain", `{"pull_request_id":"123"}`, "", false, http.StatusCreated},
+		{"header", `{"pull_request_id":"123"}`, "retry-7f3a", true, http.StatusOK},
+		{"body flag", `{"pull_request_id":"123","idempotent":true}`, "", true, http.StatusOK},
+	}
+	for _, tc := range cases {
+		req := httptest.NewRequest(http.MethodPost, "/pullRequest/create", strings.NewReader(tc.body))
+		if tc.key != "" {
+			req.Header.Set(idempotencyKeyHeader, tc.key)
+		}
+		rec := httptest.NewRecorder()
+		rtr.createPR(rec, req)
+		if idempotent != tc.want || rec.Code != tc.status {
+			t.Fatalf("%s: expected idempotent=%v and %d, got %v and %d", tc.name, tc.want, tc.status, idempotent, rec.Code)
+		}
+	}
+}
+
 func TestCreatePR_BadJSON(t *testing.T) {
 	svc := &fakePRService{
-		createFn: func(context.Context, *models.PRCreateRequest) (*models.PullRequest, error) {
+		createFn: func(context.Context, *models.PRCreateRequest) (*models.PRResponse, error) {
 			t.Fatalf("service should not be called")
 			return nil, nil
 		},
@@ -134,7 +345,7 @@ func TestCreatePR_BadJSON(t *testing.T) {
 func TestCreatePR_ValidationError(t *testing.T) {
 	valErr := fmt.Errorf("%w: pull_request_id is required", service.ErrPRValidation)
 	svc := &fakePRService{
-		createFn: func(context.Context, *models.PRCreateRequest) (*models.PullRequest, error) {
+		createFn: func(context.Context, *models.PRCreateRequest) (*models.PRResponse, error) {
 			return nil, valErr
 		},
 	}
@@ -171,7 +382,7 @@ func TestCreatePR_NotFoundErrors(t *testing.T) {
 	for _, tc := range cases {
 		t.Run(tc.name, func(t *testing.T) {
 			svc := &fakePRService{
-				createFn: func(context.Context, *models.PRCreateRequest) (*models.PullRequest, error) {
+				createFn: func(context.Context, *models.PRCreateRequest) (*models.PRResponse, error) {
 					return nil, tc.err
 				},
 			}
@@ -189,9 +400,34 @@ func TestCreatePR_NotFoundErrors(t *testing.T) {
 	}
 }
 
+func TestCreatePR_Duplicate(t *testing.T) {
+	svc := &fakePRService{
+		createFn: func(context.Context, *models.PRCreateRequest) (*models.PRResponse, error) {
+			return nil, fmt.Errorf("%w: similar to open pr-1", service.ErrPRDuplicate)
+		},
+	}
+	rtr := newTestRouterWithPRService(svc)
+
+	req := httptest.NewRequest(http.MethodPost, "/pullRequest/create", bytes.NewBufferString(`{"pull_request_id":"2","pull_request_name":"a","author_id":"u1"}`))
+	rec := httptest.NewRecorder()
+
+	rtr.createPR(rec, req)
+
+	if rec.Code != http.StatusConflict {
+		t.Fatalf("expected status 409, got %d", rec.Code)
+	}
+	var resp models.ErrorResponse
+	if err := json.NewDecoder(rec.Body).Decode(&resp); err != nil {
+		t.Fatalf("decode error response: %v", err)
+	}
+	if resp.Error.Code != ErrCodePRDuplicate {
+		t.Fatalf("expected code %s, got %s", ErrCodePRDuplicate, resp.Error.Code)
+	}
+}
+
 func TestCreatePR_AlreadyExists(t *testing.T) {
 	svc := &fakePRService{
-		createFn: func(context.Context, *models.PRCreateRequest) (*models.PullRequest, error) {
+		createFn: func(context.Context, *models.PRCreateRequest) (*models.PRResponse, error) {
 			return nil, service.ErrPRAlreadyExists
 		},
 	}
@@ -219,7 +455,7 @@ func TestCreatePR_AlreadyExists(t *testing.T) {
 
 func TestCreatePR_InternalError(t *testing.T) {
 	svc := &fakePRService{
-		createFn: func(context.Context, *models.PRCreateRequest) (*models.PullRequest, error) {
+		createFn: func(context.Context, *models.PRCreateRequest) (*models.PRResponse, error) {
 			return nil, errors.New("db down")
 		},
 	}
@@ -447,6 +683,31 @@ func TestMergePR_InternalError(t *testing.T) {
 	}
 }
 
+func TestMergePR_StatusMissing(t *testing.T) {
+	svc := &fakePRService{
+		mergeFn: func(context.Context, *models.PRMergeRequest) (*models.PullRequest, error) {
+			return nil, fmt.Errorf("merge pr transaction: %w: MERGED", service.ErrPRStatusMissing)
+		},
+	}
+	rtr := newTestRouterWithPRService(svc)
+
+	req := httptest.NewRequest(http.MethodPost, "/pullRequest/merge", bytes.NewBufferString(`{"pull_request_id":"pr1"}`))
+	rec := httptest.NewRecorder()
+
+	rtr.mergePR(rec, req)
+
+	if rec.Code != http.StatusInternalServerError {
+		t.Fatalf("expected status 500, got %d", rec.Code)
+	}
+	var resp models.ErrorResponse
+	if err := json.NewDecoder(rec.Body).Decode(&resp); err != nil {
+		t.Fatalf("decode response: %v", err)
+	}
+	if resp.Error.Code != ErrCodeStatusMissing {
+		t.Fatalf("expected STATUS_MISSING, got %q", resp.Error.Code)
+	}
+}
+
 func TestReassignPR_Success(t *testing.T) {
 	resp := &models.PRReassignResponse{
 		PR:         models.PullRequest{ID: "pr1"},
@@ -661,3 +922,888 @@ func TestGetAssignmentsStats_Error(t *testing.T) {
 		t.Fatalf("expected code %s, got %s", ErrCodeInternal, resp.Error.Code)
 	}
 }
+
+func TestAwaitAssignment_Success(t *testing.T) {
+	svc := &fakePRService{
+		awaitFn: func(_ context.Context, userID string, timeout time.Duration) (*models.AwaitAssignmentResponse, error) {
+			if timeout != 15*time.Second {
+				t.Fatalf("expected timeout 15s, got %s", timeout)
+			}
+			return &models.AwaitAssignmentResponse{
+				UserID:     userID,
+				Assignment: &models.AssignmentEvent{UserID: userID, PullRequestID: "pr-1"},
+			}, nil
+		},
+	}
+	rtr := newTestRouterWithPRService(svc)
+
+	req := httptest.NewRequest(http.MethodGet, "/users/awaitAssignment?user_id=u1&timeout=15s", nil)
+	rec := httptest.NewRecorder()
+
+	rtr.awaitAssignment(rec, req)
+
+	if rec.Code != http.StatusOK {
+		t.Fatalf("expected status 200, got %d", rec.Code)
+	}
+	var resp models.AwaitAssignmentResponse
+	if err := json.NewDecoder(rec.Body).Decode(&resp); err != nil {
+		t.Fatalf("decode response: %v", err)
+	}
+	if resp.Assignment == nil || resp.Assignment.PullRequestID != "pr-1" {
+		t.Fatalf("unexpected response: %#v", resp)
+	}
+}
+
+func TestAwaitAssignment_InvalidTimeout(t *testing.T) {
+	svc := &fakePRService{
+		awaitFn: func(context.Context, string, time.Duration) (*models.AwaitAssignmentResponse, error) {
+			t.Fatalf("service should not be called")
+			return nil, nil
+		},
+	}
+	rtr := newTestRouterWithPRService(svc)
+
+	req := httptest.NewRequest(http.MethodGet, "/users/awaitAssignment?user_id=u1&timeout=soon", nil)
+	rec := httptest.NewRecorder()
+
+	rtr.awaitAssignment(rec, req)
+
+	if rec.Code != http.StatusBadRequest {
+		t.Fatalf("expected status 400, got %d", rec.Code)
+	}
+}
+
+func TestParseTimeout(t *testing.T) {
+	cases := map[string]time.Duration{
+		"":    0,
+		"30":  30 * time.Second,
+		"45s": 45 * time.Second,
+		"1m":  time.Minute,
+	}
+	for raw, want := range cases {
+		got, err := parseTimeout(raw)
+		if err != nil {
+			t.Fatalf("parseTimeout(%q) returned err: %v", raw, err)
+		}
+		if got != want {
+			t.Fatalf("parseTimeout(%q) = %s, want %s", raw, got, want)
+		}
+	}
+}
+
+func TestAcknowledgeReview_Success(t *testing.T) {
+	svc := &fakePRService{
+		ackFn: func(_ context.Context, req *models.PRAcknowledgeRequest) (*models.PullRequest, error) {
+			if req.ID != "pr-1" || req.UserID != "u2" {
+				t.Fatalf("unexpected request: %#v", req)
+			}
+			return &models.PullRequest{ID: "pr-1", Reviewers: []string{"u2"}}, nil
+		},
+	}
+	rtr := newTestRouterWithPRService(svc)
+
+	req := httptest.NewRequest(http.MethodPost, "/pullRequest/acknowledge", bytes.NewBufferString(`{"pull_request_id":"pr-1","user_id":"u2"}`))
+	rec := httptest.NewRecorder()
+
+	rtr.acknowledgeReview(rec, req)
+
+	if rec.Code != http.StatusOK {
+		t.Fatalf("expected status 200, got %d", rec.Code)
+	}
+}
+
+func TestAcknowledgeReview_NotAssigned(t *testing.T) {
+	svc := &fakePRService{
+		ackFn: func(context.Context, *models.PRAcknowledgeRequest) (*models.PullRequest, error) {
+			return nil, service.ErrReviewerNotAssigned
+		},
+	}
+	rtr := newTestRouterWithPRService(svc)
+
+	req := httptest.NewRequest(http.MethodPost, "/pullRequest/acknowledge", bytes.NewBufferString(`{"pull_request_id":"pr-1","user_id":"u9"}`))
+	rec := httptest.NewRecorder()
+
+	rtr.acknowledgeReview(rec, req)
+
+	if rec.Code != http.StatusConflict {
+		t.Fatalf("expected status 409, got %d", rec.Code)
+	}
+}
+
+func TestApproveReview(t *testing.T) {
+	svc := &fakePRService{
+		approveFn: func(_ context.Context, req *models.PRApproveRequest) (*models.PullRequest, error) {
+			if req.UserID == "u9" {
+				return nil, service.ErrReviewerNotAssigned
+			}
+			return &models.PullRequest{ID: req.ID, ReviewerDetails: []*models.ReviewerDetail{{UserID: req.UserID, ReviewState: req.State}}}, nil
+		},
+	}
+	rtr := newTestRouterWithPRService(svc)
+
+	rec := httptest.NewRecorder()
+	rtr.approveReview(rec, httptest.NewRequest(http.MethodPost, "/pullRequest/approve", bytes.NewBufferString(`{"pull_request_id":"pr-1","user_id":"u2","state":"APPROVED"}`)))
+	if rec.Code != http.StatusOK || !strings.Contains(rec.Body.String(), `"review_state":"APPROVED"`) {
+		t.Fatalf("unexpected response %d %s", rec.Code, rec.Body.String())
+	}
+
+	rec = httptest.NewRecorder()
+	rtr.approveReview(rec, httptest.NewRequest(http.MethodPost, "/pullRequest/approve", bytes.NewBufferString(`{"pull_request_id":"pr-1","user_id":"u9"}`)))
+	if rec.Code != http.StatusConflict {
+		t.Fatalf("expected status 409, got %d", rec.Code)
+	}
+}
+
+func TestMergePR_NotApproved(t *testing.T) {
+	svc := &fakePRService{
+		mergeFn: func(context.Context, *models.PRMergeRequest) (*models.PullRequest, error) {
+			return nil, fmt.Errorf("%w: waiting for u3", service.ErrPRNotApproved)
+		},
+	}
+	rtr := newTestRouterWithPRService(svc)
+
+	rec := httptest.NewRecorder()
+	rtr.mergePR(rec, httptest.NewRequest(http.MethodPost, "/pullRequest/merge", bytes.NewBufferString(`{"pull_request_id":"pr-1"}`)))
+	if rec.Code != http.StatusConflict || !strings.Contains(rec.Body.String(), ErrCodeNotApproved) {
+		t.Fatalf("expected 409 NOT_APPROVED, got %d %s", rec.Code, rec.Body.String())
+	}
+}
+
+func TestClosePR(t *testing.T) {
+	svc := &fakePRService{
+		closeFn: func(_ context.Context, req *models.PRCloseRequest) (*models.PullRequest, error) {
+			if req.ID == "merged" {
+				return nil, service.ErrPRMerged
+			}
+			return &models.PullRequest{ID: req.ID, Status: models.StatusClosed}, nil
+		},
+	}
+	rtr := newTestRouterWithPRService(svc)
+
+	rec := httptest.NewRecorder()
+	rtr.closePR(rec, httptest.NewRequest(http.MethodPost, "/pullRequest/close", bytes.NewBufferString(`{"pull_request_id":"pr-1"}`)))
+	if rec.Code != http.StatusOK || !strings.Contains(rec.Body.String(), `"status":"CLOSED"`) {
+		t.Fatalf("unexpected response %d %s", rec.Code, rec.Body.String())
+	}
+
+	rec = httptest.NewRecorder()
+	rtr.closePR(rec, httptest.NewRequest(http.MethodPost, "/pullRequest/close", bytes.NewBufferString(`{"pull_request_id":"merged"}`)))
+	if rec.Code != http.StatusConflict || !strings.Contains(rec.Body.String(), ErrCodePRMerged) {
+		t.Fatalf("expected 409 PR_MERGED, got %d %s", rec.Code, rec.Body.String())
+	}
+}
+
+func TestUpdatePR(t *testing.T) {
+	svc := &fakePRService{
+		updateFn: func(_ context.Context, req *models.PRUpdateRequest) (*models.PullRequest, error) {
+			switch req.ID {
+			case "closed":
+				return nil, service.ErrPRClosed
+			case "bad":
+				return nil, service.ErrPRValidation
+			}
+			return &models.PullRequest{ID: req.ID, Title: *req.Title, Labels: *req.Labels, Status: models.StatusOpen}, nil
+		},
+	}
+	rtr := newTestRouterWithPRService(svc)
+
+	rec := httptest.NewRecorder()
+	body := `{"pull_request_id":"pr-1","pull_request_name":"renamed","labels":["backend"]}`
+	rtr.updatePR(rec, httptest.NewRequest(http.MethodPost, "/pullRequest/update", bytes.NewBufferString(body)))
+	if rec.Code != http.StatusOK || !strings.Contains(rec.Body.String(), `"pull_request_name":"renamed"`) || !strings.Contains(rec.Body.String(), `"labels":["backend"]`) {
+		t.Fatalf("unexpected response %d %s", rec.Code, rec.Body.String())
+	}
+
+	rec = httptest.NewRecorder()
+	rtr.updatePR(rec, httptest.NewRequest(http.MethodPost, "/pullRequest/update", bytes.NewBufferString(`{"pull_request_id":"closed"}`)))
+	if rec.Code != http.StatusConflict || !strings.Contains(rec.Body.String(), ErrCodePRClosed) {
+		t.Fatalf("expected 409 PR_CLOSED, got %d %s", rec.Code, rec.Body.String())
+	}
+
+	rec = httptest.NewRecorder()
+	rtr.updatePR(rec, httptest.NewRequest(http.MethodPost, "/pullRequest/update", bytes.NewBufferString(`{"pull_request_id":"bad"}`)))
+	if rec.Code != http.StatusBadRequest {
+		t.Fatalf("expected 400 for invalid update, got %d %s", rec.Code, rec.Body.String())
+	}
+}
+
+func TestAddRemoveReviewer(t *testing.T) {
+	svc := &fakePRService{
+		addFn: func(_ context.Context, req *models.PRAddReviewerRequest) (*models.PullRequest, error) {
+			if req.UserID == "u1" {
+				return nil, service.ErrReviewerAlreadyAssigned
+			}
+			return &models.PullRequest{ID: req.ID, Status: models.StatusOpen, Reviewers: []string{"u1", req.UserID}}, nil
+		},
+		removeFn: func(_ context.Context, req *models.PRRemoveReviewerRequest) (*models.PullRequest, error) {
+			if req.UserID != "u1" {
+				return nil, service.ErrReviewerNotAssigned
+			}
+			return &models.PullRequest{ID: req.ID, Status: models.StatusOpen, Reviewers: []string{}, NeedMore: true}, nil
+		},
+	}
+	rtr := newTestRouterWithPRService(svc)
+
+	rec := httptest.NewRecorder()
+	rtr.addReviewer(rec, httptest.NewRequest(http.MethodPost, "/pullRequest/addReviewer", bytes.NewBufferString(`{"pull_request_id":"pr-1","user_id":"u2"}`)))
+	if rec.Code != http.StatusOK || !strings.Contains(rec.Body.String(), `"assigned_reviewers":["u1","u2"]`) {
+		t.Fatalf("unexpected response %d %s", rec.Code, rec.Body.String())
+	}
+
+	rec = httptest.NewRecorder()
+	rtr.addReviewer(rec, httptest.NewRequest(http.MethodPost, "/pullRequest/addReviewer", bytes.NewBufferString(`{"pull_request_id":"pr-1","user_id":"u1"}`)))
+	if rec.Code != http.StatusConflict || !strings.Contains(rec.Body.String(), ErrCodeAssigned) {
+		t.Fatalf("expected 409 ALREADY_ASSIGNED, got %d %s", rec.Code, rec.Body.String())
+	}
+
+	rec = httptest.NewRecorder()
+	rtr.removeReviewer(rec, httptest.NewRequest(http.MethodPost, "/pullRequest/removeReviewer", bytes.NewBufferString(`{"pull_request_id":"pr-1","user_id":"u1"}`)))
+	if rec.Code != http.StatusOK || !strings.Contains(rec.Body.String(), `"needMoreReviewers":true`) {
+		t.Fatalf("unexpected response %d %s", rec.Code, rec.Body.String())
+	}
+
+	rec = httptest.NewRecorder()
+	rtr.removeReviewer(rec, httptest.NewRequest(http.MethodPost, "/pullRequest/removeReviewer", bytes.NewBufferString(`{"pull_request_id":"pr-1","user_id":"u9"}`)))
+	if rec.Code != http.StatusConflict || !strings.Contains(rec.Body.String(), ErrCodeNotAssigned) {
+		t.Fatalf("expected 409 NOT_ASSIGNED, got %d %s", rec.Code, rec.Body.String())
+	}
+}
+
+func TestGetPR(t *testing.T) {
+	merged := time.Date(2025, 3, 1, 10, 0, 0, 0, time.UTC)
+	svc := &fakePRService{
+		getFn: func(_ context.Context, prID string) (*models.PullRequest, error) {
+			if prID != "pr-1" {
+				return nil, service.ErrPRNotFound
+			}
+			return &models.PullRequest{
+				ID:              prID,
+				Status:          models.StatusMerged,
+				Reviewers:       []string{"u2"},
+				ReviewerDetails: []*models.ReviewerDetail{{UserID: "u2", ReviewState: models.ReviewStateApproved}},
+				MergedAt:        &merged,
+				NeedMore:        true,
+			}, nil
+		},
+	}
+	rtr := newTestRouterWithPRService(svc)
+
+	rec := httptest.NewRecorder()
+	rtr.getPR(rec, httptest.NewRequest(http.MethodGet, "/pullRequest/get?pull_request_id=pr-1", nil))
+	if rec.Code != http.StatusOK {
+		t.Fatalf("unexpected response %d %s", rec.Code, rec.Body.String())
+	}
+	for _, want := range []string{`"review_state":"APPROVED"`, `"mergedAt":"2025-03-01T10:00:00Z"`, `"needMoreReviewers":true`} {
+		if !strings.Contains(rec.Body.String(), want) {
+			t.Fatalf("expected %s in %s", want, rec.Body.String())
+		}
+	}
+
+	rec = httptest.NewRecorder()
+	rtr.getPR(rec, httptest.NewRequest(http.MethodGet, "/pullRequest/get?pull_request_id=ghost", nil))
+	if rec.Code != http.StatusNotFound {
+		t.Fatalf("expected 404, got %d %s", rec.Code, rec.Body.String())
+	}
+}
+
+func TestReassignPR_Closed(t *testing.T) {
+	svc := &fakePRService{
+		reassignFn: func(context.Context, *models.PRReassignRequest) (*models.PRReassignResponse, error) {
+			return nil, service.ErrPRClosed
+		},
+	}
+	rtr := newTestRouterWithPRService(svc)
+
+	rec := httptest.NewRecorder()
+	rtr.reassignPR(rec, httptest.NewRequest(http.MethodPost, "/pullRequest/reassign", bytes.NewBufferString(`{"pull_request_id":"pr-1","old_reviewer_id":"u2"}`)))
+	if rec.Code != http.StatusConflict || !strings.Contains(rec.Body.String(), ErrCodePRClosed) {
+		t.Fatalf("expected 409 PR_CLOSED, got %d %s", rec.Code, rec.Body.String())
+	}
+}
+
+func TestListPRs(t *testing.T) {
+	var got models.PRListQuery
+	svc := &fakePRService{
+		listFn: func(_ context.Context, q models.PRListQuery) (*models.PRListResponse, error) {
+			got = q
+			return &models.PRListResponse{
+				PullRequests: []*models.PullRequest{{ID: "pr-1", Status: models.StatusMerged}},
+				Total:        3,
+				Limit:        1,
+				Offset:       2,
+			}, nil
+		},
+	}
+	rtr := newTestRouterWithPRService(svc)
+
+	rec := httptest.NewRecorder()
+	rtr.listPRs(rec, httptest.NewRequest(http.MethodGet, "/pullRequest/list?status=MERGED&reviewer_id=u2&team_name=backend&merged_from=2025-01-01&merged_to=2025-02-01T00:00:00Z&label=bug,urgent&label=backend&overdue=true&limit=1&offset=2", nil))
+	if rec.Code != http.StatusOK {
+		t.Fatalf("unexpected response %d %s", rec.Code, rec.Body.String())
+	}
+	if got.Status != models.StatusMerged || got.ReviewerID != "u2" || got.TeamName != "backend" || got.MergedFrom == nil || got.MergedTo == nil || got.Limit != 1 || got.Offset != 2 || !slices.Equal(got.Labels, []string{"bug", "urgent", "backend"}) || !got.Overdue {
+		t.Fatalf("unexpected query: %#v", got)
+	}
+	var resp models.PRListResponse
+	if err := json.NewDecoder(rec.Body).Decode(&resp); err != nil {
+		t.Fatalf("failed to decode response: %v", err)
+	}
+	if resp.Total != 3 || len(resp.PullRequests) != 1 || resp.PullRequests[0].ID != "pr-1" {
+		t.Fatalf("unexpected response: %+v", resp)
+	}
+
+	rec = httptest.NewRecorder()
+	rtr.listPRs(rec, httptest.NewRequest(http.MethodGet, "/pullRequest/list?created_from=yesterday", nil))
+	if rec.Code != http.StatusBadRequest || !strings.Contains(rec.Body.String(), "created_from") {
+		t.Fatalf("expected 400 for bad created_from, got %d %s", rec.Code, rec.Body.String())
+	}
+	rec = httptest.NewRecorder()
+	rtr.listPRs(rec, httptest.NewRequest(http.MethodGet, "/pullRequest/list?overdue=soon", nil))
+	if rec.Code != http.StatusBadRequest || !strings.Contains(rec.Body.String(), "overdue") {
+		t.Fatalf("expected 400 for bad overdue, got %d %s", rec.Code, rec.Body.String())
+	}
+}
+
+func TestGetAssignmentHistory_Success(t *testing.T) {
+	svc := &fakePRService{
+		historyFn: func(_ context.Context, q models.AssignmentHistoryQuery) (*models.AssignmentHistoryResponse, error) {
+			if q.UserID != "u1" || q.Limit != 10 || q.Offset != 20 {
+				t.Fatalf("unexpected query: %#v", q)
+			}
+			if q.From == nil || !q.From.Equal(time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)) {
+				t.Fatalf("unexpected from: %v", q.From)
+			}
+			if q.To == nil || !q.To.Equal(time.Date(2025, 2, 1, 12, 0, 0, 0, time.UTC)) {
+				t.Fatalf("unexpected to: %v", q.To)
+			}
+			return &models.AssignmentHistoryResponse{
+				UserID: "u1",
+				Assignments: []*models.AssignmentHistoryItem{
+					{PullRequestID: "pr-1", Outcome: models.OutcomeMerged},
+				},
+				Total: 21,
+				Limit: 10,
+			}, nil
+		},
+	}
+	rtr := newTestRouterWithPRService(svc)
+
+	req := httptest.NewRequest(http.MethodGet, "/users/assignmentHistory?user_id=u1&from=2025-01-01&to=2025-02-01T12:00:00Z&limit=10&offset=20", nil)
+	rec := httptest.NewRecorder()
+
+	rtr.getAssignmentHistory(rec, req)
+
+	if rec.Code != http.StatusOK {
+		t.Fatalf("expected status 200, got %d", rec.Code)
+	}
+	var resp models.AssignmentHistoryResponse
+	if err := json.NewDecoder(rec.Body).Decode(&resp); err != nil {
+		t.Fatalf("decode response: %v", err)
+	}
+	if resp.Total != 21 || len(resp.Assignments) != 1 || resp.Assignments[0].Outcome != models.OutcomeMerged {
+		t.Fatalf("unexpected response: %#v", resp)
+	}
+}
+
+func TestGetAssignmentHistory_InvalidParams(t *testing.T) {
+	rtr := newTestRouterWithPRService(&fakePRService{})
+
+	for _, target := range []string{
+		"/users/assignmentHistory?user_id=u1&from=yesterday",
+		"/users/assignmentHistory?user_id=u1&limit=ten",
+	} {
+		req := httptest.NewRequest(http.MethodGet, target, nil)
+		rec := httptest.NewRecorder()
+
+		rtr.getAssignmentHistory(rec, req)
+
+		if rec.Code != http.StatusBadRequest {
+			t.Fatalf("%s: expected status 400, got %d", target, rec.Code)
+		}
+	}
+}
+
+func TestGetUserReviews_ExpandUsers(t *testing.T) {
+	prSvc := &fakePRService{
+		reviewsFn: func(context.Context, string) (*models.UserReviewsResponse, error) {
+			return &models.UserReviewsResponse{
+				UserID:       "u1",
+				PullRequests: []*models.PullRequestShort{{ID: "pr1", AuthorID: "u2"}},
+			}, nil
+		},
+	}
+	userSvc := &fakeUserService{
+		getByIDsFn: func(_ context.Context, userIDs []string) ([]*models.UserWithTeam, error) {
+			if len(userIDs) != 2 || userIDs[0] != "u1" || userIDs[1] != "u2" {
+				t.Fatalf("unexpected user ids: %v", userIDs)
+			}
+			return []*models.UserWithTeam{
+				{User: models.User{ID: "u1", Username: "alice", IsActive: true}},
+				{User: models.User{ID: "u2", Username: "bob"}},
+			}, nil
+		},
+	}
+	rtr := newTestRouterWithPRService(prSvc)
+	rtr.userService = userSvc
+
+	req := httptest.NewRequest(http.MethodGet, "/users/getReview?user_id=u1&expand=users", nil)
+	rec := httptest.NewRecorder()
+
+	rtr.getUserReviews(rec, req)
+
+	if rec.Code != http.StatusOK {
+		t.Fatalf("expected status 200, got %d", rec.Code)
+	}
+	var resp models.UserReviewsResponse
+	if err := json.NewDecoder(rec.Body).Decode(&resp); err != nil {
+		t.Fatalf("decode response: %v", err)
+	}
+	if len(resp.Users) != 2 || resp.Users[1].Username != "bob" {
+		t.Fatalf("unexpected users: %#v", resp.Users)
+	}
+}
+
+func TestGetAssignmentsStats_UnknownExpand(t *testing.T) {
+	rtr := newTestRouterWithPRService(&fakePRService{})
+
+	req := httptest.NewRequest(http.MethodGet, "/stats/assignments?expand=teams", nil)
+	rec := httptest.NewRecorder()
+
+	rtr.getAssignmentsStats(rec, req)
+
+	if rec.Code != http.StatusBadRequest {
+		t.Fatalf("expected status 400, got %d", rec.Code)
+	}
+}
+
+func TestCreatePR_WithoutExpandOmitsUsers(t *testing.T) {
+	svc := &fakePRService{
+		createFn: func(context.Context, *models.PRCreateRequest) (*models.PRResponse, error) {
+			return &models.PRResponse{PR: models.PullRequest{ID: "pr-1", AuthorID: "u1"}}, nil
+		},
+	}
+	rtr := newTestRouterWithPRService(svc)
+
+	req := httptest.NewRequest(http.MethodPost, "/pullRequest/create", bytes.NewBufferString(`{"pull_request_id":"pr-1","pull_request_name":"t","author_id":"u1"}`))
+	rec := httptest.NewRecorder()
+
+	rtr.createPR(rec, req)
+
+	if rec.Code != http.StatusCreated {
+		t.Fatalf("expected status 201, got %d", rec.Code)
+	}
+	if strings.Contains(rec.Body.String(), `"users"`) {
+		t.Fatalf("expected users to be omitted, got %s", rec.Body.String())
+	}
+}
+
+func TestGetAssignmentsStats_ExpandUsersFillsUsernames(t *testing.T) {
+	prSvc := &fakePRService{
+		statsFn: func(context.Context) (*models.AssignmentsStatsResponse, error) {
+			return &models.AssignmentsStatsResponse{
+				ByUser: []*models.UserAssignmentsStat{{UserID: "u1", Assignments: 2}},
+				ByPR:   []*models.PRAssignmentsStat{},
+			}, nil
+		},
+	}
+	userSvc := &fakeUserService{
+		getByIDsFn: func(context.Context, []string) ([]*models.UserWithTeam, error) {
+			return []*models.UserWithTeam{{User: models.User{ID: "u1", Username: "alice"}}}, nil
+		},
+	}
+	rtr := newTestRouterWithPRService(prSvc)
+	rtr.userService = userSvc
+
+	req := httptest.NewRequest(http.MethodGet, "/stats/assignments?expand=users", nil)
+	rec := httptest.NewRecorder()
+
+	rtr.getAssignmentsStats(rec, req)
+
+	if rec.Code != http.StatusOK {
+		t.Fatalf("expected status 200, got %d", rec.Code)
+	}
+	var resp models.AssignmentsStatsResponse
+	if err := json.NewDecoder(rec.Body).Decode(&resp); err != nil {
+		t.Fatalf("decode response: %v", err)
+	}
+	if resp.ByUser[0].Username != "alice" {
+		t.Fatalf("expected username to be filled, got %#v", resp.ByUser[0])
+	}
+}
+
+func TestMergePR_ExpandAuthor(t *testing.T) {
+	prSvc := &fakePRService{
+		mergeFn: func(context.Context, *models.PRMergeRequest) (*models.PullRequest, error) {
+			return &models.PullRequest{ID: "pr-1", AuthorID: "u1", Status: models.StatusMerged}, nil
+		},
+	}
+	userSvc := &fakeUserService{
+		getByIDsFn: func(_ context.Context, userIDs []string) ([]*models.UserWithTeam, error) {
+			if len(userIDs) != 1 || userIDs[0] != "u1" {
+				t.Fatalf("unexpected user ids: %v", userIDs)
+			}
+			return []*models.UserWithTeam{{User: models.User{ID: "u1", Username: "alice"}, TeamName: "backend"}}, nil
+		},
+	}
+	rtr := newTestRouterWithPRService(prSvc)
+	rtr.userService = userSvc
+
+	req := httptest.NewRequest(http.MethodPost, "/pullRequest/merge?expand=author", bytes.NewBufferString(`{"pull_request_id":"pr-1"}`))
+	rec := httptest.NewRecorder()
+
+	rtr.mergePR(rec, req)
+
+	if rec.Code != http.StatusOK {
+		t.Fatalf("expected status 200, got %d", rec.Code)
+	}
+	var resp models.PRResponse
+	if err := json.NewDecoder(rec.Body).Decode(&resp); err != nil {
+		t.Fatalf("decode response: %v", err)
+	}
+	if resp.Author == nil || resp.Author.Username != "alice" || resp.Users != nil {
+		t.Fatalf("unexpected expansion: %#v", resp)
+	}
+}
+
+func TestMergePR_ExpandAuthorUsesJoinedAuthor(t *testing.T) {
+	prSvc := &fakePRService{
+		mergeFn: func(context.Context, *models.PRMergeRequest) (*models.PullRequest, error) {
+			return &models.PullRequest{
+				ID:       "pr-1",
+				AuthorID: "u1",
+				Status:   models.StatusMerged,
+				Author:   &models.UserWithTeam{User: models.User{ID: "u1", Username: "alice", IsActive: true}, TeamName: "backend"},
+			}, nil
+		},
+	}
+	rtr := newTestRouterWithPRService(prSvc)
+	rtr.userService = &fakeUserService{
+		getByIDsFn: func(context.Context, []string) ([]*models.UserWithTeam, error) {
+			t.Fatalf("author must not be looked up again")
+			return nil, nil
+		},
+	}
+
+	req := httptest.NewRequest(http.MethodPost, "/pullRequest/merge?expand=author", bytes.NewBufferString(`{"pull_request_id":"pr-1"}`))
+	rec := httptest.NewRecorder()
+
+	rtr.mergePR(rec, req)
+
+	if rec.Code != http.StatusOK {
+		t.Fatalf("expected status 200, got %d", rec.Code)
+	}
+	var resp models.PRResponse
+	if err := json.NewDecoder(rec.Body).Decode(&resp); err != nil {
+		t.Fatalf("decode response: %v", err)
+	}
+	if resp.Author == nil || resp.Author.Username != "alice" || resp.Author.TeamName != "backend" || !resp.Author.IsActive {
+		t.Fatalf("unexpected author: %#v", resp.Author)
+	}
+}
+
+func TestReassignAll_MapsPerPRErrors(t *testing.T) {
+	svc := &fakePRService{
+		reassignAllFn: func(_ context.Context, req *models.PRReassignAllRequest) (*models.PRReassignAllResponse, error) {
+			if req.OldUserID != "u1" {
+				t.Fatalf("unexpected old_user_id %q", req.OldUserID)
+			}
+			return &models.PRReassignAllResponse{
+				OldUserID:  "u1",
+				Reassigned: 1,
+				Failed:     1,
+				Results: []*models.PRReassignResult{
+					{PullRequestID: "pr1", Outcome: models.ReassignOutcomeReassigned, ReplacedBy: "u2"},
+					{PullRequestID: "pr2", Outcome: models.ReassignOutcomeFailed, Err: service.ErrNoReplacement},
+				},
+			}, nil
+		},
+	}
+	rtr := newTestRouterWithPRService(svc)
+
+	req := httptest.NewRequest(http.MethodPost, "/pullRequest/reassignAll", strings.NewReader(`{"old_user_id":"u1"}`))
+	rec := httptest.NewRecorder()
+	rtr.reassignAll(rec, req)
+
+	if rec.Code != http.StatusOK {
+		t.Fatalf("expected status 200, got %d", rec.Code)
+	}
+	var resp models.PRReassignAllResponse
+	if err := json.NewDecoder(rec.Body).Decode(&resp); err != nil {
+		t.Fatalf("failed to decode response: %v", err)
+	}
+	if len(resp.Results) != 2 || resp.Results[0].Error != nil {
+		t.Fatalf("unexpected results: %+v", resp.Results)
+	}
+	if got := resp.Results[1].Error; got == nil || got.Code != ErrCodeNoCandidate {
+		t.Fatalf("expected NO_CANDIDATE for pr2, got %+v", got)
+	}
+}
+
+func TestTransferAssignments_OverCapacity(t *testing.T) {
+	svc := &fakePRService{
+		transferFn: func(_ context.Context, req *models.TransferAssignmentsRequest) (*models.TransferAssignmentsResponse, error) {
+			if req.FromUserID != "u1" || req.ToUserID != "u2" || len(req.PullRequestIDs) != 1 {
+				t.Fatalf("unexpected request %+v", req)
+			}
+			return nil, fmt.Errorf("%w: u2 would have 6 open reviews, capacity is 5", service.ErrReviewerOverloaded)
+		},
+	}
+	rtr := newTestRouterWithPRService(svc)
+
+	body := `{"from_user_id":"u1","to_user_id":"u2","pull_request_ids":["pr1"]}`
+	rec := httptest.NewRecorder()
+	rtr.transferAssignments(rec, httptest.NewRequest(http.MethodPost, "/users/transferAssignments", strings.NewReader(body)))
+
+	if rec.Code != http.StatusConflict {
+		t.Fatalf("expected status 409, got %d", rec.Code)
+	}
+	var resp models.ErrorResponse
+	if err := json.NewDecoder(rec.Body).Decode(&resp); err != nil {
+		t.Fatalf("failed to decode response: %v", err)
+	}
+	if resp.Error.Code != ErrCodeOverCapacity {
+		t.Fatalf("expected %s, got %s", ErrCodeOverCapacity, resp.Error.Code)
+	}
+}
+
+func TestGetPRsNeedingReviewers_TeamFilter(t *testing.T) {
+	svc := &fakePRService{
+		needingFn: func(_ context.Context, teamName string) (*models.NeedReviewersResponse, error) {
+			if teamName != "backend" {
+				t.Fatalf("unexpected team %q", teamName)
+			}
+			return &models.NeedReviewersResponse{
+				TeamName:          teamName,
+				RequiredReviewers: 2,
+				PullRequests:      []*models.PRNeedingReviewers{{ID: "pr1", TeamName: "backend", Reviewers: 1, Missing: 1}},
+			}, nil
+		},
+	}
+	rtr := newTestRouterWithPRService(svc)
+
+	rec := httptest.NewRecorder()
+	rtr.getPRsNeedingReviewers(rec, httptest.NewRequest(http.MethodGet, "/pullRequest/needReviewers?team_name=backend", nil))
+	if rec.Code != http.StatusOK {
+		t.Fatalf("expected status 200, got %d", rec.Code)
+	}
+	var resp models.NeedReviewersResponse
+	if err := json.NewDecoder(rec.Body).Decode(&resp); err != nil {
+		t.Fatalf("failed to decode response: %v", err)
+	}
+	if len(resp.PullRequests) != 1 || resp.PullRequests[0].Missing != 1 {
+		t.Fatalf("unexpected response: %+v", resp)
+	}
+}
+
+func TestBackfillReviewers_MapsPerPRErrors(t *testing.T) {
+	svc := &fakePRService{
+		backfillFn: func(_ context.Context, req *models.BackfillReviewersRequest) (*models.BackfillReviewersResponse, error) {
+			if req.TeamName != "backend" {
+				t.Fatalf("unexpected team_name %q", req.TeamName)
+			}
+			return &models.BackfillReviewersResponse{
+				TeamName: "backend",
+				Filled:   1,
+				Failed:   1,
+				Results: []*models.PRBackfillResult{
+					{PullRequestID: "pr1", Outcome: models.BackfillOutcomeFilled, Added: []string{"u2"}},
+					{PullRequestID: "pr2", Outcome: models.BackfillOutcomeFailed, Err: service.ErrNoReplacement},
+				},
+			}, nil
+		},
+	}
+	rtr := newTestRouterWithPRService(svc)
+
+	req := httptest.NewRequest(http.MethodPost, "/pullRequest/backfillReviewers", bytes.NewBufferString(`{"team_name":"backend"}`))
+	rec := httptest.NewRecorder()
+	rtr.backfillReviewers(rec, req)
+
+	if rec.Code != http.StatusOK {
+		t.Fatalf("expected status 200, got %d", rec.Code)
+	}
+	var resp models.BackfillReviewersResponse
+	if err := json.NewDecoder(rec.Body).Decode(&resp); err != nil {
+		t.Fatalf("decode response: %v", err)
+	}
+	if resp.Filled != 1 || resp.Results[1].Error == nil || resp.Results[1].Error.Code != ErrCodeNoCandidate {
+		t.Fatalf("unexpected response %+v", resp.Results[1])
+	}
+	if resp.Results[1].Added == nil {
+		t.Fatalf("expected added_reviewers to be an empty array")
+	}
+}
+
+func TestBatchGetPRs(t *testing.T) {
+	svc := &fakePRService{
+		batchGetFn: func(_ context.Context, prIDs []string) (*models.PRBatchGetResponse, error) {
+			if len(prIDs) == 0 {
+				return nil, fmt.Errorf("%w: pull_request_ids is required", service.ErrPRValidation)
+			}
+			return &models.PRBatchGetResponse{
+				PullRequests: []*models.PullRequest{{ID: "pr-1", Status: models.StatusOpen, Reviewers: []string{"u2"}}},
+				Missing:      []string{"pr-404"},
+			}, nil
+		},
+	}
+	rtr := newTestRouterWithPRService(svc)
+
+	rec := httptest.NewRecorder()
+	rtr.batchGetPRs(rec, httptest.NewRequest(http.MethodPost, "/pullRequest/batchGet", strings.NewReader(`{"pull_request_ids":["pr-1","pr-404"]}`)))
+	if rec.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d %s", rec.Code, rec.Body.String())
+	}
+	var resp models.PRBatchGetResponse
+	if err := json.NewDecoder(rec.Body).Decode(&resp); err != nil {
+		t.Fatalf("decode response: %v", err)
+	}
+	if len(resp.PullRequests) != 1 || resp.PullRequests[0].ID != "pr-1" || len(resp.Missing) != 1 || resp.Missing[0] != "pr-404" {
+		t.Fatalf("unexpected response %+v", resp)
+	}
+
+	rec = httptest.NewRecorder()
+	rtr.batchGetPRs(rec, httptest.NewRequest(http.MethodPost, "/pullRequest/batchGet", strings.NewReader(`{}`)))
+	if rec.Code != http.StatusBadRequest {
+		t.Fatalf("expected 400 for empty ids, got %d", rec.Code)
+	}
+}
+
+func TestGetUserAgenda(t *testing.T) {
+	svc := &fakePRService{
+		agendaFn: func(_ context.Context, userID string) (*models.AgendaResponse, error) {
+			if userID == "ghost" {
+				return nil, service.ErrUserNotFound
+			}
+			return &models.AgendaResponse{UserID: userID, Items: []*models.AgendaItem{
+				{PullRequestID: "pr-1", Kind: models.AgendaOverdue},
+				{PullRequestID: "pr-2", Kind: models.AgendaNew},
+			}}, nil
+		},
+	}
+	rtr := newTestRouterWithPRService(svc)
+
+	rec := httptest.NewRecorder()
+	rtr.getUserAgenda(rec, httptest.NewRequest(http.MethodGet, "/users/agenda?user_id=u1", nil))
+	if rec.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d %s", rec.Code, rec.Body.String())
+	}
+	var resp models.AgendaResponse
+	if err := json.NewDecoder(rec.Body).Decode(&resp); err != nil {
+		t.Fatalf("decode response: %v", err)
+	}
+	if resp.UserID != "u1" || len(resp.Items) != 2 || resp.Items[0].Kind != models.AgendaOverdue {
+		t.Fatalf("unexpected response %+v", resp)
+	}
+
+	rec = httptest.NewRecorder()
+	rtr.getUserAgenda(rec, httptest.NewRequest(http.MethodGet, "/users/agenda?user_id=ghost", nil))
+	if rec.Code != http.StatusNotFound {
+		t.Fatalf("expected 404 for unknown user, got %d", rec.Code)
+	}
+}
+
+func TestPreviewAssignment(t *testing.T) {
+	svc := &fakePRService{
+		previewFn: func(_ context.Context, authorID string) (*models.AssignmentPreviewResponse, error) {
+			if authorID == "ghost" {
+				return nil, service.ErrPRAuthorNotFound
+			}
+			return &models.AssignmentPreviewResponse{
+				AuthorID: authorID,
+				TeamName: "backend",
+				Candidates: []*models.AssignmentCandidate{
+					{UserID: "u2", TeamName: "backend", Reason: models.PickReasonRandom},
+				},
+			}, nil
+		},
+	}
+	rtr := newTestRouterWithPRService(svc)
+
+	rec := httptest.NewRecorder()
+	rtr.previewAssignment(rec, httptest.NewRequest(http.MethodGet, "/pullRequest/previewAssignment?author_id=u1", nil))
+	if rec.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d: %s", rec.Code, rec.Body.String())
+	}
+	var resp models.AssignmentPreviewResponse
+	if err := json.NewDecoder(rec.Body).Decode(&resp); err != nil {
+		t.Fatalf("decode response: %v", err)
+	}
+	if resp.AuthorID != "u1" || len(resp.Candidates) != 1 || resp.Candidates[0].Reason != models.PickReasonRandom {
+		t.Fatalf("unexpected response %+v", resp)
+	}
+
+	rec = httptest.NewRecorder()
+	rtr.previewAssignment(rec, httptest.NewRequest(http.MethodGet, "/pullRequest/previewAssignment?author_id=ghost", nil))
+	if rec.Code != http.StatusNotFound {
+		t.Fatalf("expected 404 for unknown author, got %d", rec.Code)
+	}
+}
+
+func TestRebalanceAssignments(t *testing.T) {
+	svc := &fakePRService{
+		rebalanceFn: func(_ context.Context, req *models.RebalanceAssignmentsRequest) (*models.RebalanceAssignmentsResponse, error) {
+			if req.TeamName == "ghost" {
+				return nil, service.ErrPRTeamNotFound
+			}
+			if req.ChangeSetToken == "rebalance.old" {
+				return nil, service.ErrStalePreview
+			}
+			return &models.RebalanceAssignmentsResponse{
+				TeamName: req.TeamName,
+				Moves:    []*models.RebalanceMove{{PullRequestID: "pr-1", FromUserID: "u1", ToUserID: "u3"}},
+			}, nil
+		},
+	}
+	rtr := newTestRouterWithPRService(svc)
+
+	rec := httptest.NewRecorder()
+	rtr.rebalanceAssignments(rec, httptest.NewRequest(http.MethodPost, "/admin/rebalanceAssignments", strings.NewReader(`{"team_name":"backend"}`)))
+	if rec.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d: %s", rec.Code, rec.Body.String())
+	}
+	var resp models.RebalanceAssignmentsResponse
+	if err := json.NewDecoder(rec.Body).Decode(&resp); err != nil {
+		t.Fatalf("decode response: %v", err)
+	}
+	if resp.TeamName != "backend" || len(resp.Moves) != 1 || resp.Moves[0].ToUserID != "u3" {
+		t.Fatalf("unexpected response %+v", resp)
+	}
+
+	rec = httptest.NewRecorder()
+	rtr.rebalanceAssignments(rec, httptest.NewRequest(http.MethodPost, "/admin/rebalanceAssignments", strings.NewReader(`{"team_name":"ghost"}`)))
+	if rec.Code != http.StatusNotFound {
+		t.Fatalf("expected 404 for unknown team, got %d", rec.Code)
+	}
+
+	rec = httptest.NewRecorder()
+	rtr.rebalanceAssignments(rec, httptest.NewRequest(http.MethodPost, "/admin/rebalanceAssignments", strings.NewReader(`{"team_name":"backend","change_set_token":"rebalance.old"}`)))
+	if rec.Code != http.StatusConflict || !strings.Contains(rec.Body.String(), ErrCodeStalePreview) {
+		t.Fatalf("expected 409 STALE_PREVIEW, got %d: %s", rec.Code, rec.Body.String())
+	}
+}
+
+func TestDeclinePR(t *testing.T) {
+	svc := &fakePRService{
+		declineFn: func(_ context.Context, req *models.PRDeclineRequest) (*models.PRReassignResponse, error) {
+			if req.ID != "pr-1" || req.UserID != "u2" || req.Reason != "on vacation" {
+				t.Fatalf("unexpected decline request: %+v", req)
+			}
+			return &models.PRReassignResponse{PR: models.PullRequest{ID: "pr-1", Reviewers: []string{"u3"}}, ReplacedBy: "u3"}, nil
+		},
+	}
+	rtr := newTestRouterWithPRService(svc)
+
+	body := `{"pull_request_id":"pr-1","reason":"on vacation"}`
+	req := withTestIdentity(httptest.NewRequest(http.MethodPost, "/pullRequest/decline", strings.NewReader(body)), "u2")
+	rec := httptest.NewRecorder()
+	rtr.declinePR(rec, req)
+	if rec.Code != http.StatusOK || !strings.Contains(rec.Body.String(), `"replaced_by":"u3"`) {
+		t.Fatalf("unexpected response %d: %s", rec.Code, rec.Body.String())
+	}
+
+	body = `{"pull_request_id":"pr-1","user_id":"u2","reason":"on vacation"}`
+	rec = httptest.NewRecorder()
+	rtr.declinePR(rec, httptest.NewRequest(http.MethodPost, "/pullRequest/decline", strings.NewReader(body)))
+	if rec.Code != http.StatusOK {
+		t.Fatalf("expected 200 with explicit user_id, got %d: %s", rec.Code, rec.Body.String())
+	}
+}
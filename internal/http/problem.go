@@ -0,0 +1,52 @@
+package http
+
+import (
+	"encoding/json"
+	"mime"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/cloudyy74/pr-reviewer-service/internal/models"
+)
+
+const (
+	problemContentType = "application/problem+json"
+	problemTypeBase    = "/errors#"
+)
+
+func acceptsProblemJSON(r *http.Request) bool {
+	if r == nil {
+		return false
+	}
+	for _, accept := range r.Header.Values("Accept") {
+		for _, part := range strings.Split(accept, ",") {
+			mediaType, params, err := mime.ParseMediaType(strings.TrimSpace(part))
+			if err != nil || mediaType != problemContentType {
+				continue
+			}
+			if q, err := strconv.ParseFloat(params["q"], 64); err == nil && q <= 0 {
+				continue
+			}
+			return true
+		}
+	}
+	return false
+}
+
+func writeProblem(w http.ResponseWriter, r *http.Request, status int, respErr ResponseError) {
+	title := http.StatusText(status)
+	if spec, ok := specForCode(respErr.Code); ok && spec.description != "" {
+		title = spec.description
+	}
+	w.Header().Set("Content-Type", problemContentType)
+	w.WriteHeader(status)
+	_ = json.NewEncoder(w).Encode(&models.Problem{
+		Type:     problemTypeBase + respErr.Code,
+		Title:    title,
+		Status:   status,
+		Detail:   respErr.Message,
+		Instance: r.URL.Path,
+		Code:     respErr.Code,
+	})
+}
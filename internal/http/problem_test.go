@@ -0,0 +1,63 @@
+package http
+
+import (
+	"encoding/json"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/cloudyy74/pr-reviewer-service/internal/models"
+	"github.com/cloudyy74/pr-reviewer-service/internal/service"
+)
+
+func TestHandleError_ProblemJSON(t *testing.T) {
+	rtr := &router{log: slog.New(slog.NewTextHandler(io.Discard, nil))}
+
+	req := httptest.NewRequest(http.MethodGet, "/team/get?team_name=x", nil)
+	req.Header.Set("Accept", "application/json;q=0.5, application/problem+json")
+	rec := httptest.NewRecorder()
+
+	rtr.handleError(rec, req, service.ErrTeamNotFound)
+
+	if rec.Code != http.StatusNotFound {
+		t.Fatalf("expected status 404, got %d", rec.Code)
+	}
+	if ct := rec.Header().Get("Content-Type"); ct != problemContentType {
+		t.Fatalf("unexpected content type %q", ct)
+	}
+	var got models.Problem
+	if err := json.NewDecoder(rec.Body).Decode(&got); err != nil {
+		t.Fatalf("failed to decode problem: %v", err)
+	}
+	if got.Type != "/errors#NOT_FOUND" || got.Status != http.StatusNotFound || got.Code != ErrCodeNotFound ||
+		got.Detail != "resource not found" || got.Instance != "/team/get" || got.Title == "" {
+		t.Fatalf("unexpected problem: %+v", got)
+	}
+}
+
+func TestHandleError_DefaultFormat(t *testing.T) {
+	rtr := &router{log: slog.New(slog.NewTextHandler(io.Discard, nil))}
+
+	for _, accept := range []string{"", "application/json", "application/problem+json;q=0"} {
+		req := httptest.NewRequest(http.MethodGet, "/team/get", nil)
+		if accept != "" {
+			req.Header.Set("Accept", accept)
+		}
+		rec := httptest.NewRecorder()
+
+		rtr.handleError(rec, req, service.ErrTeamNotFound)
+
+		if ct := rec.Header().Get("Content-Type"); ct != "application/json" {
+			t.Fatalf("accept %q: unexpected content type %q", accept, ct)
+		}
+		var got models.ErrorResponse
+		if err := json.NewDecoder(rec.Body).Decode(&got); err != nil {
+			t.Fatalf("failed to decode error: %v", err)
+		}
+		if got.Error.Code != ErrCodeNotFound {
+			t.Fatalf("accept %q: unexpected error %+v", accept, got)
+		}
+	}
+}
@@ -8,10 +8,31 @@ import (
 )
 
 type router struct {
-	teamService TeamService
-	userService UserService
-	prService   PRService
-	log         *slog.Logger
+	teamService       TeamService
+	userService       UserService
+	prService         PRService
+	statsService      StatsService
+	schemaService     SchemaService
+	faults            FaultInjector
+	shedder           LoadShedder
+	outbox            OutboxMonitor
+	events            EventLog
+	alerts            AlertMonitor
+	notifications     NotificationLog
+	streamTokens      StreamTokenService
+	calendarTokens    StreamTokenService
+	calendarVerifier  StreamTokenVerifier
+	calendarFeeds     CalendarFeedService
+	sessions          SessionService
+	sessionCookie     SessionCookie
+	passwordAdmins    map[string]struct{}
+	integrationTokens IntegrationTokenService
+	migrations        OnlineMigrationMonitor
+	drainer           Drainer
+	deprecations      DeprecationTracker
+	exclusions        ExclusionService
+	snapshots         SnapshotService
+	log               *slog.Logger
 }
 
 func SetupRouter(
@@ -20,6 +41,8 @@ func SetupRouter(
 	teamService TeamService,
 	userService UserService,
 	prService PRService,
+	statsService StatsService,
+	schemaService SchemaService,
 	log *slog.Logger,
 ) error {
 	if port == "" {
@@ -37,32 +60,85 @@ func SetupRouter(
 	if prService == nil {
 		return errors.New("pr service cannot be nil")
 	}
+	if statsService == nil {
+		return errors.New("stats service cannot be nil")
+	}
+	if schemaService == nil {
+		return errors.New("schema service cannot be nil")
+	}
 	if log == nil {
 		return errors.New("logger cannot be nil")
 	}
 	r := router{
-		teamService: teamService,
-		userService: userService,
-		prService:   prService,
-		log:         log,
+		teamService:   teamService,
+		userService:   userService,
+		prService:     prService,
+		statsService:  statsService,
+		schemaService: schemaService,
+		log:           log,
 	}
 	mux.HandleFunc("GET /ping", r.panicMiddleware(r.loggingMiddleware(r.ping)))
+	mux.HandleFunc("GET /ready", r.panicMiddleware(r.loggingMiddleware(r.ready)))
+	mux.HandleFunc("GET /admin/schema", r.panicMiddleware(r.loggingMiddleware(r.getSchema)))
+	mux.HandleFunc("POST /admin/rebalanceAssignments", r.panicMiddleware(r.loggingMiddleware(r.rebalanceAssignments)))
+	mux.HandleFunc("GET /errors", r.panicMiddleware(r.loggingMiddleware(r.getErrorCatalog)))
 	mux.HandleFunc("POST /team/add", r.panicMiddleware(r.loggingMiddleware(r.createTeam)))
 	mux.HandleFunc("GET /team/get", r.panicMiddleware(r.loggingMiddleware(r.getTeam)))
 	mux.HandleFunc("POST /team/deactivate", r.panicMiddleware(r.loggingMiddleware(r.deactivateTeamUsers)))
+	mux.HandleFunc("GET /team/history", r.panicMiddleware(r.loggingMiddleware(r.getTeamHistory)))
+	mux.HandleFunc("GET /team/getSettings", r.panicMiddleware(r.loggingMiddleware(r.getTeamSettings)))
+	mux.HandleFunc("POST /team/setSettings", r.panicMiddleware(r.loggingMiddleware(r.setTeamSettings)))
 	mux.HandleFunc("POST /users/setIsActive", r.panicMiddleware(r.loggingMiddleware(r.setUserActive)))
+	mux.HandleFunc("POST /users/setWeight", r.panicMiddleware(r.loggingMiddleware(r.setUserWeight)))
+	mux.HandleFunc("POST /users/setWorkingHours", r.panicMiddleware(r.loggingMiddleware(r.setWorkingHours)))
+	mux.HandleFunc("GET /users/getNotificationSettings", r.panicMiddleware(r.loggingMiddleware(r.getNotificationSettings)))
+	mux.HandleFunc("POST /users/setNotificationSettings", r.panicMiddleware(r.loggingMiddleware(r.setNotificationSettings)))
+	mux.HandleFunc("POST /users/setAbsence", r.panicMiddleware(r.loggingMiddleware(r.setAbsence)))
+	mux.HandleFunc("DELETE /users/setAbsence", r.panicMiddleware(r.loggingMiddleware(r.deleteAbsence)))
+	mux.HandleFunc("POST /users/batchGet", r.panicMiddleware(r.loggingMiddleware(r.batchGetUsers)))
+	mux.HandleFunc("GET /users/getByUsername", r.panicMiddleware(r.loggingMiddleware(r.getUserByUsername)))
 	mux.HandleFunc("GET /users/getReview", r.panicMiddleware(r.loggingMiddleware(r.getUserReviews)))
+	mux.HandleFunc("GET /users/agenda", r.panicMiddleware(r.loggingMiddleware(r.getUserAgenda)))
+	mux.HandleFunc("GET /users/me/reviews", r.panicMiddleware(r.loggingMiddleware(r.getMyReviews)))
+	mux.HandleFunc("GET /me", r.panicMiddleware(r.loggingMiddleware(r.getMe)))
+	mux.HandleFunc("GET /me/reviews", r.panicMiddleware(r.loggingMiddleware(r.getMyReviews)))
+	mux.HandleFunc("POST /me/unavailable", r.panicMiddleware(r.loggingMiddleware(r.setMeUnavailable)))
+	mux.HandleFunc("POST /me/decline", r.panicMiddleware(r.loggingMiddleware(r.declineMyReview)))
+	mux.HandleFunc("POST /users/transferAssignments", r.panicMiddleware(r.loggingMiddleware(r.transferAssignments)))
+	mux.HandleFunc("GET /users/assignmentHistory", r.panicMiddleware(r.loggingMiddleware(r.getAssignmentHistory)))
+	mux.HandleFunc("GET /users/awaitAssignment", r.panicMiddleware(r.loggingMiddleware(r.awaitAssignment)))
 	mux.HandleFunc("POST /pullRequest/create", r.panicMiddleware(r.loggingMiddleware(r.createPR)))
+	mux.HandleFunc("GET /pullRequest/get", r.panicMiddleware(r.loggingMiddleware(r.getPR)))
+	mux.HandleFunc("GET /pullRequest/list", r.panicMiddleware(r.loggingMiddleware(r.listPRs)))
 	mux.HandleFunc("POST /pullRequest/merge", r.panicMiddleware(r.loggingMiddleware(r.mergePR)))
+	mux.HandleFunc("POST /pullRequest/close", r.panicMiddleware(r.loggingMiddleware(r.closePR)))
+	mux.HandleFunc("POST /pullRequest/update", r.panicMiddleware(r.loggingMiddleware(r.updatePR)))
+	mux.HandleFunc("POST /pullRequest/addReviewer", r.panicMiddleware(r.loggingMiddleware(r.addReviewer)))
+	mux.HandleFunc("POST /pullRequest/removeReviewer", r.panicMiddleware(r.loggingMiddleware(r.removeReviewer)))
 	mux.HandleFunc("POST /pullRequest/reassign", r.panicMiddleware(r.loggingMiddleware(r.reassignPR)))
+	mux.HandleFunc("POST /pullRequest/decline", r.panicMiddleware(r.loggingMiddleware(r.declinePR)))
+	mux.HandleFunc("POST /pullRequest/reassignAll", r.panicMiddleware(r.loggingMiddleware(r.reassignAll)))
+	mux.HandleFunc("POST /pullRequest/backfillReviewers", r.panicMiddleware(r.loggingMiddleware(r.backfillReviewers)))
+	mux.HandleFunc("POST /pullRequest/batchGet", r.panicMiddleware(r.loggingMiddleware(r.batchGetPRs)))
+	mux.HandleFunc("GET /pullRequest/previewAssignment", r.panicMiddleware(r.loggingMiddleware(r.previewAssignment)))
+	mux.HandleFunc("GET /pullRequest/needReviewers", r.panicMiddleware(r.loggingMiddleware(r.getPRsNeedingReviewers)))
+	mux.HandleFunc("POST /pullRequest/acknowledge", r.panicMiddleware(r.loggingMiddleware(r.acknowledgeReview)))
+	mux.HandleFunc("POST /pullRequest/approve", r.panicMiddleware(r.loggingMiddleware(r.approveReview)))
 	mux.HandleFunc("GET /stats/assignments", r.panicMiddleware(r.loggingMiddleware(r.getAssignmentsStats)))
+	mux.HandleFunc("GET /stats/summary", r.panicMiddleware(r.loggingMiddleware(r.getStatsSummary)))
+	mux.HandleFunc("GET /stats/timeseries", r.panicMiddleware(r.loggingMiddleware(r.getStatsTimeseries)))
+	mux.HandleFunc("GET /stats/anomalies", r.panicMiddleware(r.loggingMiddleware(r.getStatsAnomalies)))
+	mux.HandleFunc("GET /stats/heatmap", r.panicMiddleware(r.loggingMiddleware(r.getStatsHeatmap)))
+	mux.HandleFunc("GET /stats/throughput", r.panicMiddleware(r.loggingMiddleware(r.getStatsThroughput)))
+	mux.HandleFunc("GET /stats/completion", r.panicMiddleware(r.loggingMiddleware(r.getStatsCompletion)))
+	mux.HandleFunc("GET /stats/labels", r.panicMiddleware(r.loggingMiddleware(r.getStatsLabels)))
 	return nil
 }
 
 func (rtr *router) responseJSON(w http.ResponseWriter, statusCode int, response any) {
 	w.Header().Set("Content-Type", "application/json")
 	w.WriteHeader(statusCode)
-	if err := json.NewEncoder(w).Encode(response); err != nil {
+	if err := json.NewEncoder(w).Encode(normalizeResponse(response)); err != nil {
 		rtr.log.Error("failed to encode response", slog.Any("error", err))
 	}
 }
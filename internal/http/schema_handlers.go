@@ -0,0 +1,37 @@
+package http
+
+import (
+	"context"
+	"log/slog"
+	"net/http"
+	"strings"
+
+	"github.com/cloudyy74/pr-reviewer-service/internal/models"
+)
+
+type SchemaService interface {
+	Check(context.Context) (*models.SchemaReport, error)
+}
+
+func (rtr *router) getSchema(w http.ResponseWriter, r *http.Request) {
+	report, err := rtr.schemaService.Check(r.Context())
+	if err != nil {
+		rtr.handleError(w, r, err)
+		return
+	}
+	rtr.responseJSON(w, http.StatusOK, report)
+}
+
+func (rtr *router) ready(w http.ResponseWriter, r *http.Request) {
+	report, err := rtr.schemaService.Check(r.Context())
+	if err != nil {
+		rtr.log.Error("readiness check failed", slog.Any("error", err))
+		rtr.responseJSON(w, http.StatusServiceUnavailable, models.PingResponse{Status: "unavailable", Message: "schema check failed"})
+		return
+	}
+	if !report.OK {
+		rtr.responseJSON(w, http.StatusServiceUnavailable, models.PingResponse{Status: "unavailable", Message: strings.Join(report.Problems, "; ")})
+		return
+	}
+	rtr.responseJSON(w, http.StatusOK, models.PingResponse{Status: "ok", Message: "ready"})
+}
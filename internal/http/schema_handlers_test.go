@@ -0,0 +1,100 @@
+package http
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/cloudyy74/pr-reviewer-service/internal/models"
+)
+
+type fakeSchemaService struct {
+	checkFn func(ctx context.Context) (*models.SchemaReport, error)
+}
+
+func (f *fakeSchemaService) Check(ctx context.Context) (*models.SchemaReport, error) {
+	if f.checkFn == nil {
+		return nil, errors.New("not implemented")
+	}
+	return f.checkFn(ctx)
+}
+
+func newTestRouterWithSchemaService(svc SchemaService) *router {
+	return &router{
+		schemaService: svc,
+		log:           slog.New(slog.NewTextHandler(io.Discard, nil)),
+	}
+}
+
+func TestGetSchema_ReturnsReport(t *testing.T) {
+	svc := &fakeSchemaService{
+		checkFn: func(context.Context) (*models.SchemaReport, error) {
+			return &models.SchemaReport{ExpectedVersion: 7, CurrentVersion: 6, Problems: []string{"table assignment_anomalies is missing"}}, nil
+		},
+	}
+	rtr := newTestRouterWithSchemaService(svc)
+
+	req := httptest.NewRequest(http.MethodGet, "/admin/schema", nil)
+	rec := httptest.NewRecorder()
+
+	rtr.getSchema(rec, req)
+
+	if rec.Code != http.StatusOK {
+		t.Fatalf("expected status 200, got %d", rec.Code)
+	}
+	var resp models.SchemaReport
+	if err := json.NewDecoder(rec.Body).Decode(&resp); err != nil {
+		t.Fatalf("decode response: %v", err)
+	}
+	if resp.OK || resp.CurrentVersion != 6 || len(resp.Problems) != 1 {
+		t.Fatalf("unexpected response: %#v", resp)
+	}
+}
+
+func TestReady_FailsOnSchemaDrift(t *testing.T) {
+	svc := &fakeSchemaService{
+		checkFn: func(context.Context) (*models.SchemaReport, error) {
+			return &models.SchemaReport{Problems: []string{"database is at migration 6, expected 7: run pending migrations"}}, nil
+		},
+	}
+	rtr := newTestRouterWithSchemaService(svc)
+
+	req := httptest.NewRequest(http.MethodGet, "/ready", nil)
+	rec := httptest.NewRecorder()
+
+	rtr.ready(rec, req)
+
+	if rec.Code != http.StatusServiceUnavailable {
+		t.Fatalf("expected status 503, got %d", rec.Code)
+	}
+	var resp models.PingResponse
+	if err := json.NewDecoder(rec.Body).Decode(&resp); err != nil {
+		t.Fatalf("decode response: %v", err)
+	}
+	if resp.Message != "database is at migration 6, expected 7: run pending migrations" {
+		t.Fatalf("unexpected message: %q", resp.Message)
+	}
+}
+
+func TestReady_OK(t *testing.T) {
+	svc := &fakeSchemaService{
+		checkFn: func(context.Context) (*models.SchemaReport, error) {
+			return &models.SchemaReport{OK: true}, nil
+		},
+	}
+	rtr := newTestRouterWithSchemaService(svc)
+
+	req := httptest.NewRequest(http.MethodGet, "/ready", nil)
+	rec := httptest.NewRecorder()
+
+	rtr.ready(rec, req)
+
+	if rec.Code != http.StatusOK {
+		t.Fatalf("expected status 200, got %d", rec.Code)
+	}
+}
@@ -0,0 +1,194 @@
+package http
+
+import (
+	"context"
+	"crypto/subtle"
+	"encoding/json"
+	"errors"
+	"log/slog"
+	"net/http"
+	"time"
+
+	"github.com/cloudyy74/pr-reviewer-service/internal/models"
+)
+
+const csrfHeader = "X-CSRF-Token"
+
+type SessionService interface {
+	Login(ctx context.Context, username, password string) (string, *models.Session, error)
+	Logout(ctx context.Context, token string) error
+	Authenticate(ctx context.Context, token string) (*models.Session, error)
+	SetPassword(ctx context.Context, userID, password string) error
+	ResolveUserRef(ctx context.Context, ref string) (string, error)
+}
+
+type SessionCookie struct {
+	Name   string
+	Secure bool
+}
+
+type sessionCtxKey struct{}
+
+func SessionFromContext(ctx context.Context) (*models.Session, bool) {
+	session, ok := ctx.Value(sessionCtxKey{}).(*models.Session)
+	return session, ok
+}
+
+// SessionAuth authenticates browser requests carrying a session cookie and
+// enforces the CSRF header on unsafe methods. Requests without the cookie are
+// passed through untouched so server-to-server clients keep working.
+func SessionAuth(next http.Handler, sessions SessionService, cookie SessionCookie, log *slog.Logger) http.Handler {
+	rtr := &router{log: log, sessionCookie: cookie}
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		c, err := r.Cookie(cookie.Name)
+		if err != nil || c.Value == "" {
+			next.ServeHTTP(w, r)
+			return
+		}
+		isLogin := r.Method == http.MethodPost && r.URL.Path == "/auth/login"
+		session, err := sessions.Authenticate(r.Context(), c.Value)
+		if err != nil {
+			if isLogin {
+				next.ServeHTTP(w, r)
+				return
+			}
+			rtr.clearSessionCookie(w)
+			rtr.handleError(w, r, err)
+			return
+		}
+		if !isLogin && !isSafeMethod(r.Method) {
+			got := r.Header.Get(csrfHeader)
+			if got == "" || subtle.ConstantTimeCompare([]byte(got), []byte(session.CSRFToken)) != 1 {
+				rtr.handleError(w, r, newResponseError(ErrCodeForbidden, "missing or invalid "+csrfHeader+" header"))
+				return
+			}
+		}
+		next.ServeHTTP(w, r.WithContext(context.WithValue(r.Context(), sessionCtxKey{}, session)))
+	})
+}
+
+func isSafeMethod(method string) bool {
+	return method == http.MethodGet || method == http.MethodHead || method == http.MethodOptions
+}
+
+// SetupSessionRoutes registers the password login endpoints. admins may set
+// any user's password; everyone else needs a signed client to do so.
+func SetupSessionRoutes(mux *http.ServeMux, sessions SessionService, cookie SessionCookie, admins []string, log *slog.Logger) error {
+	if mux == nil {
+		return errors.New("mux cannot be nil")
+	}
+	if sessions == nil {
+		return errors.New("session service cannot be nil")
+	}
+	if cookie.Name == "" {
+		return errors.New("session cookie name cannot be empty")
+	}
+	if log == nil {
+		return errors.New("logger cannot be nil")
+	}
+	r := router{
+		sessions:       sessions,
+		sessionCookie:  cookie,
+		passwordAdmins: make(map[string]struct{}, len(admins)),
+		log:            log,
+	}
+	for _, id := range admins {
+		r.passwordAdmins[id] = struct{}{}
+	}
+	mux.HandleFunc("POST /auth/login", r.panicMiddleware(r.loggingMiddleware(r.login)))
+	mux.HandleFunc("POST /auth/logout", r.panicMiddleware(r.loggingMiddleware(r.logout)))
+	mux.HandleFunc("GET /auth/session", r.panicMiddleware(r.loggingMiddleware(r.getSession)))
+	mux.HandleFunc("POST /auth/setPassword", r.panicMiddleware(r.loggingMiddleware(r.setPassword)))
+	return nil
+}
+
+func (rtr *router) login(w http.ResponseWriter, r *http.Request) {
+	var req models.LoginRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		rtr.handleError(w, r, newResponseError(ErrCodeBadRequest, "bad json request"))
+		return
+	}
+	token, session, err := rtr.sessions.Login(r.Context(), req.Username, req.Password)
+	if err != nil {
+		rtr.handleError(w, r, err)
+		return
+	}
+	http.SetCookie(w, &http.Cookie{
+		Name:     rtr.sessionCookie.Name,
+		Value:    token,
+		Path:     "/",
+		Expires:  session.ExpiresAt,
+		HttpOnly: true,
+		Secure:   rtr.sessionCookie.Secure,
+		SameSite: http.SameSiteLaxMode,
+	})
+	rtr.responseJSON(w, http.StatusOK, session)
+}
+
+func (rtr *router) logout(w http.ResponseWriter, r *http.Request) {
+	if _, ok := SessionFromContext(r.Context()); !ok {
+		rtr.handleError(w, r, newResponseError(ErrCodeUnauthorized, "no active session"))
+		return
+	}
+	c, err := r.Cookie(rtr.sessionCookie.Name)
+	if err == nil {
+		if err := rtr.sessions.Logout(r.Context(), c.Value); err != nil {
+			rtr.handleError(w, r, err)
+			return
+		}
+	}
+	rtr.clearSessionCookie(w)
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (rtr *router) getSession(w http.ResponseWriter, r *http.Request) {
+	session, ok := SessionFromContext(r.Context())
+	if !ok {
+		rtr.handleError(w, r, newResponseError(ErrCodeUnauthorized, "no active session"))
+		return
+	}
+	rtr.responseJSON(w, http.StatusOK, session)
+}
+
+func (rtr *router) setPassword(w http.ResponseWriter, r *http.Request) {
+	var req models.SetPasswordRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		rtr.handleError(w, r, newResponseError(ErrCodeBadRequest, "bad json request"))
+		return
+	}
+	if _, signed := SignedClientFromContext(r.Context()); !signed {
+		caller, ok := UserFromContext(r.Context())
+		if !ok {
+			rtr.handleError(w, r, newResponseError(ErrCodeUnauthorized, "setting a password requires authentication"))
+			return
+		}
+		userID, err := rtr.sessions.ResolveUserRef(r.Context(), req.UserID)
+		if err != nil {
+			rtr.handleError(w, r, err)
+			return
+		}
+		if _, admin := rtr.passwordAdmins[caller.ID]; !admin && userID != caller.ID {
+			rtr.handleError(w, r, newResponseError(ErrCodeForbidden, "users can only change their own password"))
+			return
+		}
+		req.UserID = userID
+	}
+	if err := rtr.sessions.SetPassword(r.Context(), req.UserID, req.Password); err != nil {
+		rtr.handleError(w, r, err)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (rtr *router) clearSessionCookie(w http.ResponseWriter) {
+	http.SetCookie(w, &http.Cookie{
+		Name:     rtr.sessionCookie.Name,
+		Value:    "",
+		Path:     "/",
+		Expires:  time.Unix(0, 0),
+		MaxAge:   -1,
+		HttpOnly: true,
+		Secure:   rtr.sessionCookie.Secure,
+		SameSite: http.SameSiteLaxMode,
+	})
+}
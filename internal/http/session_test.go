@@ -0,0 +1,205 @@
+package http
+
+import (
+	"context"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/cloudyy74/pr-reviewer-service/internal/models"
+	"github.com/cloudyy74/pr-reviewer-service/internal/service"
+)
+
+type fakeSessionService struct {
+	sessions map[string]*models.Session
+	password map[string]string
+}
+
+func (f *fakeSessionService) Login(_ context.Context, username, password string) (string, *models.Session, error) {
+	if username != "alice" || password != "secret-pass" {
+		return "", nil, service.ErrInvalidCredentials
+	}
+	session := &models.Session{User: models.UserWithTeam{User: models.User{ID: "u1"}}, CSRFToken: "csrf", ExpiresAt: time.Now().Add(time.Hour)}
+	f.sessions["tok"] = session
+	return "tok", session, nil
+}
+
+func (f *fakeSessionService) Logout(_ context.Context, token string) error {
+	delete(f.sessions, token)
+	return nil
+}
+
+func (f *fakeSessionService) Authenticate(_ context.Context, token string) (*models.Session, error) {
+	session, ok := f.sessions[token]
+	if !ok {
+		return nil, service.ErrSessionInvalid
+	}
+	return session, nil
+}
+
+func (f *fakeSessionService) SetPassword(_ context.Context, userID, password string) error {
+	f.password[userID] = password
+	return nil
+}
+
+func (f *fakeSessionService) ResolveUserRef(_ context.Context, ref string) (string, error) {
+	switch ref {
+	case "@alice":
+		return "u1", nil
+	case "@ghost":
+		return "", service.ErrUserNotFound
+	}
+	return ref, nil
+}
+
+func newSessionTestHandler(t *testing.T) (http.Handler, *fakeSessionService) {
+	t.Helper()
+	sessions := &fakeSessionService{sessions: map[string]*models.Session{}, password: map[string]string{}}
+	log := slog.New(slog.NewTextHandler(io.Discard, nil))
+	cookie := SessionCookie{Name: "session", Secure: true}
+	mux := http.NewServeMux()
+	if err := SetupSessionRoutes(mux, sessions, cookie, []string{"admin"}, log); err != nil {
+		t.Fatalf("SetupSessionRoutes returned err: %v", err)
+	}
+	mux.HandleFunc("POST /pullRequest/merge", func(w http.ResponseWriter, r *http.Request) { w.WriteHeader(http.StatusOK) })
+	return SessionAuth(mux, sessions, cookie, log), sessions
+}
+
+func TestSessionLoginFlow(t *testing.T) {
+	h, sessions := newSessionTestHandler(t)
+
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, httptest.NewRequest(http.MethodPost, "/auth/login", strings.NewReader(`{"username":"alice","password":"nope"}`)))
+	if rec.Code != http.StatusUnauthorized {
+		t.Fatalf("expected 401 for bad password, got %d", rec.Code)
+	}
+
+	rec = httptest.NewRecorder()
+	h.ServeHTTP(rec, httptest.NewRequest(http.MethodPost, "/auth/login", strings.NewReader(`{"username":"alice","password":"secret-pass"}`)))
+	if rec.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d", rec.Code)
+	}
+	cookies := rec.Result().Cookies()
+	if len(cookies) != 1 || cookies[0].Value != "tok" || !cookies[0].HttpOnly || !cookies[0].Secure {
+		t.Fatalf("unexpected cookies: %+v", cookies)
+	}
+	if strings.Contains(rec.Body.String(), `"tok"`) {
+		t.Fatalf("session token must not be returned in the body: %s", rec.Body.String())
+	}
+
+	withCookie := func(req *http.Request) *http.Request {
+		req.AddCookie(&http.Cookie{Name: "session", Value: "tok"})
+		return req
+	}
+
+	rec = httptest.NewRecorder()
+	h.ServeHTTP(rec, withCookie(httptest.NewRequest(http.MethodGet, "/auth/session", nil)))
+	if rec.Code != http.StatusOK || !strings.Contains(rec.Body.String(), `"csrf_token":"csrf"`) {
+		t.Fatalf("unexpected session response: %d %s", rec.Code, rec.Body.String())
+	}
+
+	rec = httptest.NewRecorder()
+	h.ServeHTTP(rec, withCookie(httptest.NewRequest(http.MethodPost, "/pullRequest/merge", strings.NewReader(`{}`))))
+	if rec.Code != http.StatusForbidden {
+		t.Fatalf("expected 403 without CSRF header, got %d", rec.Code)
+	}
+
+	req := withCookie(httptest.NewRequest(http.MethodPost, "/pullRequest/merge", strings.NewReader(`{}`)))
+	req.Header.Set(csrfHeader, "csrf")
+	rec = httptest.NewRecorder()
+	h.ServeHTTP(rec, req)
+	if rec.Code != http.StatusOK {
+		t.Fatalf("expected 200 with CSRF header, got %d", rec.Code)
+	}
+
+	req = withCookie(httptest.NewRequest(http.MethodPost, "/auth/setPassword", strings.NewReader(`{"user_id":"u2","password":"hijacked!"}`)))
+	req.Header.Set(csrfHeader, "csrf")
+	rec = httptest.NewRecorder()
+	h.ServeHTTP(rec, req)
+	if rec.Code != http.StatusForbidden || sessions.password["u2"] != "" {
+		t.Fatalf("expected 403 when changing another user's password, got %d", rec.Code)
+	}
+
+	req = withCookie(httptest.NewRequest(http.MethodPost, "/auth/logout", nil))
+	req.Header.Set(csrfHeader, "csrf")
+	rec = httptest.NewRecorder()
+	h.ServeHTTP(rec, req)
+	if rec.Code != http.StatusNoContent || len(sessions.sessions) != 0 {
+		t.Fatalf("expected logout to drop session, got %d", rec.Code)
+	}
+
+	rec = httptest.NewRecorder()
+	h.ServeHTTP(rec, withCookie(httptest.NewRequest(http.MethodGet, "/auth/session", nil)))
+	if rec.Code != http.StatusUnauthorized {
+		t.Fatalf("expected 401 after logout, got %d", rec.Code)
+	}
+}
+
+func TestSessionAuth_PassesRequestsWithoutCookie(t *testing.T) {
+	h, _ := newSessionTestHandler(t)
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, httptest.NewRequest(http.MethodPost, "/pullRequest/merge", strings.NewReader(`{}`)))
+	if rec.Code != http.StatusOK {
+		t.Fatalf("expected server-to-server request to pass, got %d", rec.Code)
+	}
+}
+
+func TestSetPassword_Authorization(t *testing.T) {
+	h, sessions := newSessionTestHandler(t)
+	asUser := func(req *http.Request, id string) *http.Request {
+		user := &models.UserWithTeam{User: models.User{ID: id}}
+		return req.WithContext(context.WithValue(req.Context(), identityCtxKey{}, user))
+	}
+	newReq := func(userID string) *http.Request {
+		return httptest.NewRequest(http.MethodPost, "/auth/setPassword", strings.NewReader(`{"user_id":"`+userID+`","password":"new-password"}`))
+	}
+
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, newReq("admin"))
+	if rec.Code != http.StatusUnauthorized || sessions.password["admin"] != "" {
+		t.Fatalf("expected 401 for anonymous caller, got %d", rec.Code)
+	}
+
+	rec = httptest.NewRecorder()
+	h.ServeHTTP(rec, asUser(newReq("u2"), "u1"))
+	if rec.Code != http.StatusForbidden || sessions.password["u2"] != "" {
+		t.Fatalf("expected 403 when changing another user's password, got %d", rec.Code)
+	}
+
+	rec = httptest.NewRecorder()
+	h.ServeHTTP(rec, asUser(newReq("u1"), "u1"))
+	if rec.Code != http.StatusNoContent || sessions.password["u1"] != "new-password" {
+		t.Fatalf("expected own password change to succeed, got %d", rec.Code)
+	}
+
+	delete(sessions.password, "u1")
+	rec = httptest.NewRecorder()
+	h.ServeHTTP(rec, asUser(newReq("@alice"), "u1"))
+	if rec.Code != http.StatusNoContent || sessions.password["u1"] != "new-password" {
+		t.Fatalf("expected own password change by username to succeed, got %d", rec.Code)
+	}
+
+	rec = httptest.NewRecorder()
+	h.ServeHTTP(rec, asUser(newReq("@ghost"), "u1"))
+	if rec.Code != http.StatusNotFound {
+		t.Fatalf("expected 404 for an unknown username, got %d", rec.Code)
+	}
+
+	rec = httptest.NewRecorder()
+	h.ServeHTTP(rec, asUser(newReq("u2"), "admin"))
+	if rec.Code != http.StatusNoContent || sessions.password["u2"] != "new-password" {
+		t.Fatalf("expected admin to set another user's password, got %d", rec.Code)
+	}
+
+	req := newReq("u3")
+	req = req.WithContext(context.WithValue(req.Context(), signedClientCtxKey{}, "provisioner"))
+	rec = httptest.NewRecorder()
+	h.ServeHTTP(rec, req)
+	if rec.Code != http.StatusNoContent || sessions.password["u3"] != "new-password" {
+		t.Fatalf("expected signed client to set a password, got %d", rec.Code)
+	}
+}
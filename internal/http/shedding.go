@@ -0,0 +1,76 @@
+package http
+
+import (
+	"errors"
+	"log/slog"
+	"net/http"
+	"strings"
+
+	"github.com/cloudyy74/pr-reviewer-service/internal/models"
+)
+
+type LoadShedder interface {
+	Shedding() bool
+	Rejected()
+	Metrics() models.LoadMetrics
+}
+
+var lowPriorityPaths = []string{
+	"/stats/",
+	"/users/getReview",
+	"/users/me/reviews",
+	"/me/reviews",
+	"/users/assignmentHistory",
+	"/pullRequest/needReviewers",
+	"/pullRequest/list",
+	"/pullRequest/previewAssignment",
+	"/team/get",
+	"/team/history",
+}
+
+func ShedLowPriority(next http.Handler, shedder LoadShedder, log *slog.Logger) http.Handler {
+	rtr := &router{log: log}
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if shedder.Shedding() && isLowPriority(r) {
+			shedder.Rejected()
+			w.Header().Set("Retry-After", "1")
+			rtr.handleError(w, r, newResponseError(ErrCodeOverloaded, "service is overloaded, retry later"))
+			return
+		}
+		next.ServeHTTP(w, r)
+	})
+}
+
+func isLowPriority(r *http.Request) bool {
+	if r.Method != http.MethodGet {
+		return false
+	}
+	for _, path := range lowPriorityPaths {
+		if (strings.HasSuffix(path, "/") && strings.HasPrefix(r.URL.Path, path)) || r.URL.Path == path {
+			return true
+		}
+	}
+	return false
+}
+
+func SetupLoadRoutes(mux *http.ServeMux, shedder LoadShedder, log *slog.Logger) error {
+	if mux == nil {
+		return errors.New("mux cannot be nil")
+	}
+	if shedder == nil {
+		return errors.New("load shedder cannot be nil")
+	}
+	if log == nil {
+		return errors.New("logger cannot be nil")
+	}
+	r := router{
+		shedder: shedder,
+		log:     log,
+	}
+	mux.HandleFunc("GET /admin/load", r.panicMiddleware(r.loggingMiddleware(r.getLoad)))
+	return nil
+}
+
+func (rtr *router) getLoad(w http.ResponseWriter, r *http.Request) {
+	rtr.responseJSON(w, http.StatusOK, rtr.shedder.Metrics())
+}
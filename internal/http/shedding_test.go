@@ -0,0 +1,75 @@
+package http
+
+import (
+	"encoding/json"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/cloudyy74/pr-reviewer-service/internal/models"
+)
+
+type fakeShedder struct {
+	shedding bool
+	rejected int
+}
+
+func (f *fakeShedder) Shedding() bool { return f.shedding }
+
+func (f *fakeShedder) Rejected() { f.rejected++ }
+
+func (f *fakeShedder) Metrics() models.LoadMetrics {
+	return models.LoadMetrics{Shedding: f.shedding, ShedRequests: int64(f.rejected)}
+}
+
+func TestShedLowPriority(t *testing.T) {
+	shedder := &fakeShedder{shedding: true}
+	next := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	})
+	h := ShedLowPriority(next, shedder, slog.New(slog.NewTextHandler(io.Discard, nil)))
+
+	cases := []struct {
+		method string
+		path   string
+		want   int
+	}{
+		{http.MethodGet, "/stats/summary", http.StatusServiceUnavailable},
+		{http.MethodGet, "/users/getReview", http.StatusServiceUnavailable},
+		{http.MethodGet, "/team/getSettings", http.StatusOK},
+		{http.MethodPost, "/pullRequest/create", http.StatusOK},
+		{http.MethodPost, "/pullRequest/merge", http.StatusOK},
+	}
+	for _, tc := range cases {
+		rec := httptest.NewRecorder()
+		h.ServeHTTP(rec, httptest.NewRequest(tc.method, tc.path, nil))
+		if rec.Code != tc.want {
+			t.Fatalf("%s %s: expected %d, got %d", tc.method, tc.path, tc.want, rec.Code)
+		}
+		if tc.want != http.StatusServiceUnavailable {
+			continue
+		}
+		if rec.Header().Get("Retry-After") == "" {
+			t.Fatalf("%s: expected Retry-After header", tc.path)
+		}
+		var resp models.ErrorResponse
+		if err := json.NewDecoder(rec.Body).Decode(&resp); err != nil {
+			t.Fatalf("failed to decode error response: %v", err)
+		}
+		if resp.Error.Code != ErrCodeOverloaded {
+			t.Fatalf("expected code %s, got %s", ErrCodeOverloaded, resp.Error.Code)
+		}
+	}
+	if shedder.rejected != 2 {
+		t.Fatalf("expected 2 rejected requests, got %d", shedder.rejected)
+	}
+
+	shedder.shedding = false
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/stats/summary", nil))
+	if rec.Code != http.StatusOK {
+		t.Fatalf("expected request to pass when not shedding, got %d", rec.Code)
+	}
+}
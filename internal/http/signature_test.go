@@ -0,0 +1,142 @@
+package http
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/cloudyy74/pr-reviewer-service/internal/models"
+	"github.com/cloudyy74/pr-reviewer-service/internal/reqsign"
+)
+
+const testSigningSecret = "0123456789abcdef0123456789abcdef"
+
+func TestVerifySignature(t *testing.T) {
+	verifier, err := reqsign.NewVerifier(time.Minute)
+	if err != nil {
+		t.Fatalf("NewVerifier returned err: %v", err)
+	}
+	if err := verifier.SetSecret("billing", testSigningSecret); err != nil {
+		t.Fatalf("SetSecret returned err: %v", err)
+	}
+	var gotClient, gotBody string
+	next := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		gotClient, _ = SignedClientFromContext(r.Context())
+		body, _ := io.ReadAll(r.Body)
+		gotBody = string(body)
+		w.WriteHeader(http.StatusOK)
+	})
+	log := slog.New(slog.NewTextHandler(io.Discard, nil))
+	h := VerifySignature(next, verifier, nil, true, log)
+
+	ts := strconv.FormatInt(time.Now().Unix(), 10)
+	body := `{"pull_request_id":"pr-1"}`
+	signed := func(secret, target, body string) *http.Request {
+		req := httptest.NewRequest(http.MethodPost, target, strings.NewReader(body))
+		req.Header.Set(clientIDHeader, "billing")
+		req.Header.Set(timestampHeader, ts)
+		req.Header.Set(signatureHeader, reqsign.Sign(secret, ts, http.MethodPost, target, []byte(body)))
+		return req
+	}
+
+	cases := []struct {
+		name   string
+		req    *http.Request
+		want   int
+		client string
+	}{
+		{"signed", signed(testSigningSecret, "/pullRequest/merge?expand=author", body), http.StatusOK, "billing"},
+		{"wrong secret", signed("fedcba9876543210fedcba9876543210", "/pullRequest/merge", body), http.StatusUnauthorized, ""},
+		{"unsigned", httptest.NewRequest(http.MethodPost, "/pullRequest/merge", strings.NewReader(body)), http.StatusUnauthorized, ""},
+		{"unsigned probe", httptest.NewRequest(http.MethodGet, "/ping", nil), http.StatusOK, ""},
+		{"unsigned session user", httptest.NewRequest(http.MethodGet, "/me", nil).WithContext(
+			context.WithValue(context.Background(), identityCtxKey{}, &models.UserWithTeam{User: models.User{ID: "u1"}}),
+		), http.StatusOK, ""},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			gotClient, gotBody = "", ""
+			rec := httptest.NewRecorder()
+			h.ServeHTTP(rec, tc.req)
+			if rec.Code != tc.want {
+				t.Fatalf("expected %d, got %d %s", tc.want, rec.Code, rec.Body.String())
+			}
+			if rec.Code != http.StatusOK {
+				var resp models.ErrorResponse
+				if err := json.NewDecoder(rec.Body).Decode(&resp); err != nil || resp.Error.Code != ErrCodeUnauthorized {
+					t.Fatalf("expected UNAUTHORIZED, got %+v, %v", resp, err)
+				}
+				return
+			}
+			if gotClient != tc.client {
+				t.Fatalf("expected client %q, got %q", tc.client, gotClient)
+			}
+			if tc.client != "" && gotBody != body {
+				t.Fatalf("expected the body to be passed on, got %q", gotBody)
+			}
+		})
+	}
+}
+
+type fakeNonceStore struct {
+	seen map[string]time.Time
+}
+
+func (f *fakeNonceStore) RememberNonce(_ context.Context, clientID, nonce string, expiresAt time.Time) (bool, error) {
+	if _, ok := f.seen[clientID+"/"+nonce]; ok {
+		return false, nil
+	}
+	f.seen[clientID+"/"+nonce] = expiresAt
+	return true, nil
+}
+
+func TestVerifySignature_RejectsReplays(t *testing.T) {
+	verifier, err := reqsign.NewVerifier(time.Minute)
+	if err != nil {
+		t.Fatalf("NewVerifier returned err: %v", err)
+	}
+	if err := verifier.SetSecret("billing", testSigningSecret); err != nil {
+		t.Fatalf("SetSecret returned err: %v", err)
+	}
+	nonces := &fakeNonceStore{seen: make(map[string]time.Time)}
+	next := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) { w.WriteHeader(http.StatusOK) })
+	h := VerifySignature(next, verifier, nonces, false, slog.New(slog.NewTextHandler(io.Discard, nil)))
+
+	now := time.Now()
+	ts := strconv.FormatInt(now.Unix(), 10)
+	send := func(body string) int {
+		req := httptest.NewRequest(http.MethodPost, "/pullRequest/create", strings.NewReader(body))
+		req.Header.Set(clientIDHeader, "billing")
+		req.Header.Set(timestampHeader, ts)
+		req.Header.Set(signatureHeader, reqsign.Sign(testSigningSecret, ts, http.MethodPost, "/pullRequest/create", []byte(body)))
+		rec := httptest.NewRecorder()
+		h.ServeHTTP(rec, req)
+		return rec.Code
+	}
+
+	before := signatureRejections.Value("replay")
+	if code := send(`{"pull_request_id":"pr-1"}`); code != http.StatusOK {
+		t.Fatalf("expected the first delivery to pass, got %d", code)
+	}
+	if code := send(`{"pull_request_id":"pr-1"}`); code != http.StatusUnauthorized {
+		t.Fatalf("expected the replay to be rejected, got %d", code)
+	}
+	if code := send(`{"pull_request_id":"pr-2"}`); code != http.StatusOK {
+		t.Fatalf("expected another delivery to pass, got %d", code)
+	}
+	if got := signatureRejections.Value("replay"); got != before+1 {
+		t.Fatalf("expected one replay rejection, got %d", got-before)
+	}
+	for key, expiresAt := range nonces.seen {
+		if !expiresAt.Equal(time.Unix(now.Unix(), 0).Add(time.Minute)) {
+			t.Fatalf("unexpected expiry of %s: %v", key, expiresAt)
+		}
+	}
+}
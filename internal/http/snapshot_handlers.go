@@ -0,0 +1,78 @@
+package http
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"log/slog"
+	"net/http"
+
+	"github.com/cloudyy74/pr-reviewer-service/internal/models"
+)
+
+type SnapshotService interface {
+	CreateSnapshot(ctx context.Context, req *models.CreateSnapshotRequest, audit models.AuditEntry) (*models.AssignmentSnapshot, error)
+	RestoreSnapshot(ctx context.Context, req *models.RestoreSnapshotRequest, audit models.AuditEntry) (*models.RestoreSnapshotResponse, error)
+}
+
+func SetupSnapshotRoutes(mux *http.ServeMux, snapshots SnapshotService, log *slog.Logger) error {
+	if mux == nil {
+		return errors.New("mux cannot be nil")
+	}
+	if snapshots == nil {
+		return errors.New("snapshot service cannot be nil")
+	}
+	if log == nil {
+		return errors.New("logger cannot be nil")
+	}
+	r := router{
+		snapshots: snapshots,
+		log:       log,
+	}
+	mux.HandleFunc("POST /admin/snapshot", r.panicMiddleware(r.loggingMiddleware(r.createSnapshot)))
+	mux.HandleFunc("POST /admin/restore", r.panicMiddleware(r.loggingMiddleware(r.restoreSnapshot)))
+	return nil
+}
+
+func (rtr *router) createSnapshot(w http.ResponseWriter, r *http.Request) {
+	req := models.CreateSnapshotRequest{}
+	if r.ContentLength != 0 {
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			rtr.handleError(w, r, newResponseError(ErrCodeBadRequest, "bad json request"))
+			return
+		}
+	}
+	snapshot, err := rtr.snapshots.CreateSnapshot(r.Context(), &req, snapshotAudit(r, http.StatusCreated))
+	if err != nil {
+		rtr.handleError(w, r, err)
+		return
+	}
+	rtr.responseJSON(w, http.StatusCreated, snapshot)
+}
+
+func (rtr *router) restoreSnapshot(w http.ResponseWriter, r *http.Request) {
+	var req models.RestoreSnapshotRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		rtr.handleError(w, r, newResponseError(ErrCodeBadRequest, "bad json request"))
+		return
+	}
+	resp, err := rtr.snapshots.RestoreSnapshot(r.Context(), &req, snapshotAudit(r, http.StatusOK))
+	if err != nil {
+		rtr.handleError(w, r, err)
+		return
+	}
+	rtr.responseJSON(w, http.StatusOK, resp)
+}
+
+// snapshotAudit prepares the audit entry for a successful call. The actor is
+// the admin behind an impersonated request, or the caller otherwise; without
+// authentication it is left empty.
+func snapshotAudit(r *http.Request, status int) models.AuditEntry {
+	entry := models.AuditEntry{Method: r.Method, Path: r.URL.Path, Status: status}
+	if actor, ok := ActorFromContext(r.Context()); ok {
+		entry.ActorID = actor.ID
+	} else if user, ok := UserFromContext(r.Context()); ok {
+		entry.ActorID = user.ID
+	}
+	return entry
+}
@@ -0,0 +1,62 @@
+package http
+
+import (
+	"context"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/cloudyy74/pr-reviewer-service/internal/models"
+	"github.com/cloudyy74/pr-reviewer-service/internal/service"
+)
+
+type fakeSnapshotService struct {
+	audits []models.AuditEntry
+}
+
+func (f *fakeSnapshotService) CreateSnapshot(_ context.Context, req *models.CreateSnapshotRequest, audit models.AuditEntry) (*models.AssignmentSnapshot, error) {
+	f.audits = append(f.audits, audit)
+	return &models.AssignmentSnapshot{ID: 1, Label: req.Label}, nil
+}
+
+func (f *fakeSnapshotService) RestoreSnapshot(_ context.Context, req *models.RestoreSnapshotRequest, audit models.AuditEntry) (*models.RestoreSnapshotResponse, error) {
+	f.audits = append(f.audits, audit)
+	if req.SnapshotID != 1 {
+		return nil, service.ErrSnapshotNotFound
+	}
+	return &models.RestoreSnapshotResponse{SnapshotID: 1, Restored: 2}, nil
+}
+
+func TestSnapshotRoutes(t *testing.T) {
+	mux := http.NewServeMux()
+	svc := &fakeSnapshotService{}
+	if err := SetupSnapshotRoutes(mux, svc, slog.New(slog.NewTextHandler(io.Discard, nil))); err != nil {
+		t.Fatalf("SetupSnapshotRoutes returned err: %v", err)
+	}
+
+	rec := httptest.NewRecorder()
+	mux.ServeHTTP(rec, httptest.NewRequest(http.MethodPost, "/admin/snapshot", nil))
+	if rec.Code != http.StatusCreated || !strings.Contains(rec.Body.String(), `"snapshot_id":1`) {
+		t.Fatalf("unexpected snapshot response %d %s", rec.Code, rec.Body.String())
+	}
+
+	req := httptest.NewRequest(http.MethodPost, "/admin/restore", strings.NewReader(`{"snapshot_id":1}`))
+	req = req.WithContext(context.WithValue(req.Context(), identityCtxKey{}, &models.UserWithTeam{User: models.User{ID: "admin"}}))
+	rec = httptest.NewRecorder()
+	mux.ServeHTTP(rec, req)
+	if rec.Code != http.StatusOK || !strings.Contains(rec.Body.String(), `"restored_count":2`) {
+		t.Fatalf("unexpected restore response %d %s", rec.Code, rec.Body.String())
+	}
+	if got := svc.audits[1]; got.ActorID != "admin" || got.Method != http.MethodPost || got.Path != "/admin/restore" || got.Status != http.StatusOK {
+		t.Fatalf("unexpected audit entry %+v", got)
+	}
+
+	rec = httptest.NewRecorder()
+	mux.ServeHTTP(rec, httptest.NewRequest(http.MethodPost, "/admin/restore", strings.NewReader(`{"snapshot_id":5}`)))
+	if rec.Code != http.StatusNotFound {
+		t.Fatalf("expected 404 for missing snapshot, got %d", rec.Code)
+	}
+}
@@ -0,0 +1,168 @@
+package http
+
+import (
+	"context"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/cloudyy74/pr-reviewer-service/internal/models"
+)
+
+type StatsService interface {
+	GetSummary(context.Context) (*models.StatsSummary, error)
+	GetTimeseries(context.Context, models.TimeseriesQuery) (*models.TimeseriesResponse, error)
+	GetAnomalies(ctx context.Context, weeks int) (*models.AnomaliesResponse, error)
+	GetHeatmap(context.Context, models.HeatmapQuery) (*models.HeatmapResponse, error)
+	GetThroughput(ctx context.Context, weeks int, asOf *time.Time) (*models.ThroughputResponse, error)
+	GetCompletionRates(context.Context, models.CompletionQuery) (*models.CompletionResponse, error)
+	GetLabelStats(context.Context, models.LabelStatsQuery) (*models.LabelStatsResponse, error)
+}
+
+func (rtr *router) getStatsSummary(w http.ResponseWriter, r *http.Request) {
+	summary, err := rtr.statsService.GetSummary(r.Context())
+	if err != nil {
+		rtr.handleError(w, r, err)
+		return
+	}
+	rtr.responseJSON(w, http.StatusOK, summary)
+}
+
+func (rtr *router) getStatsTimeseries(w http.ResponseWriter, r *http.Request) {
+	query := r.URL.Query()
+	q := models.TimeseriesQuery{
+		Metric:   strings.TrimSpace(query.Get("metric")),
+		Interval: strings.TrimSpace(query.Get("interval")),
+	}
+
+	var err error
+	if q.From, err = parseTimeParam(query.Get("from")); err != nil {
+		rtr.handleError(w, r, newResponseError(ErrCodeValidation, "from must be RFC3339 timestamp or YYYY-MM-DD date"))
+		return
+	}
+	if q.To, err = parseTimeParam(query.Get("to")); err != nil {
+		rtr.handleError(w, r, newResponseError(ErrCodeValidation, "to must be RFC3339 timestamp or YYYY-MM-DD date"))
+		return
+	}
+	if q.AsOf, err = parseTimeParam(query.Get("as_of")); err != nil {
+		rtr.handleError(w, r, newResponseError(ErrCodeValidation, "as_of must be RFC3339 timestamp or YYYY-MM-DD date"))
+		return
+	}
+
+	resp, err := rtr.statsService.GetTimeseries(r.Context(), q)
+	if err != nil {
+		rtr.handleError(w, r, err)
+		return
+	}
+	rtr.responseJSON(w, http.StatusOK, resp)
+}
+
+func (rtr *router) getStatsAnomalies(w http.ResponseWriter, r *http.Request) {
+	weeks, err := parseIntParam(r.URL.Query().Get("weeks"))
+	if err != nil {
+		rtr.handleError(w, r, newResponseError(ErrCodeValidation, "weeks must be an integer"))
+		return
+	}
+
+	resp, err := rtr.statsService.GetAnomalies(r.Context(), weeks)
+	if err != nil {
+		rtr.handleError(w, r, err)
+		return
+	}
+	rtr.responseJSON(w, http.StatusOK, resp)
+}
+
+func (rtr *router) getStatsHeatmap(w http.ResponseWriter, r *http.Request) {
+	query := r.URL.Query()
+	q := models.HeatmapQuery{TeamName: strings.TrimSpace(query.Get("team_name"))}
+
+	var err error
+	if q.From, err = parseTimeParam(query.Get("from")); err != nil {
+		rtr.handleError(w, r, newResponseError(ErrCodeValidation, "from must be RFC3339 timestamp or YYYY-MM-DD date"))
+		return
+	}
+	if q.To, err = parseTimeParam(query.Get("to")); err != nil {
+		rtr.handleError(w, r, newResponseError(ErrCodeValidation, "to must be RFC3339 timestamp or YYYY-MM-DD date"))
+		return
+	}
+	if q.AsOf, err = parseTimeParam(query.Get("as_of")); err != nil {
+		rtr.handleError(w, r, newResponseError(ErrCodeValidation, "as_of must be RFC3339 timestamp or YYYY-MM-DD date"))
+		return
+	}
+
+	resp, err := rtr.statsService.GetHeatmap(r.Context(), q)
+	if err != nil {
+		rtr.handleError(w, r, err)
+		return
+	}
+	rtr.responseJSON(w, http.StatusOK, resp)
+}
+
+func (rtr *router) getStatsThroughput(w http.ResponseWriter, r *http.Request) {
+	query := r.URL.Query()
+	weeks, err := parseIntParam(query.Get("weeks"))
+	if err != nil {
+		rtr.handleError(w, r, newResponseError(ErrCodeValidation, "weeks must be an integer"))
+		return
+	}
+	asOf, err := parseTimeParam(query.Get("as_of"))
+	if err != nil {
+		rtr.handleError(w, r, newResponseError(ErrCodeValidation, "as_of must be RFC3339 timestamp or YYYY-MM-DD date"))
+		return
+	}
+
+	resp, err := rtr.statsService.GetThroughput(r.Context(), weeks, asOf)
+	if err != nil {
+		rtr.handleError(w, r, err)
+		return
+	}
+	rtr.responseJSON(w, http.StatusOK, resp)
+}
+
+func (rtr *router) getStatsCompletion(w http.ResponseWriter, r *http.Request) {
+	query := r.URL.Query()
+	q := models.CompletionQuery{TeamName: strings.TrimSpace(query.Get("team_name"))}
+
+	var err error
+	if q.From, err = parseTimeParam(query.Get("from")); err != nil {
+		rtr.handleError(w, r, newResponseError(ErrCodeValidation, "from must be RFC3339 timestamp or YYYY-MM-DD date"))
+		return
+	}
+	if q.To, err = parseTimeParam(query.Get("to")); err != nil {
+		rtr.handleError(w, r, newResponseError(ErrCodeValidation, "to must be RFC3339 timestamp or YYYY-MM-DD date"))
+		return
+	}
+	if q.AsOf, err = parseTimeParam(query.Get("as_of")); err != nil {
+		rtr.handleError(w, r, newResponseError(ErrCodeValidation, "as_of must be RFC3339 timestamp or YYYY-MM-DD date"))
+		return
+	}
+
+	resp, err := rtr.statsService.GetCompletionRates(r.Context(), q)
+	if err != nil {
+		rtr.handleError(w, r, err)
+		return
+	}
+	rtr.responseJSON(w, http.StatusOK, resp)
+}
+
+func (rtr *router) getStatsLabels(w http.ResponseWriter, r *http.Request) {
+	query := r.URL.Query()
+	q := models.LabelStatsQuery{TeamName: strings.TrimSpace(query.Get("team_name"))}
+
+	var err error
+	if q.From, err = parseTimeParam(query.Get("from")); err != nil {
+		rtr.handleError(w, r, newResponseError(ErrCodeValidation, "from must be RFC3339 timestamp or YYYY-MM-DD date"))
+		return
+	}
+	if q.To, err = parseTimeParam(query.Get("to")); err != nil {
+		rtr.handleError(w, r, newResponseError(ErrCodeValidation, "to must be RFC3339 timestamp or YYYY-MM-DD date"))
+		return
+	}
+
+	resp, err := rtr.statsService.GetLabelStats(r.Context(), q)
+	if err != nil {
+		rtr.handleError(w, r, err)
+		return
+	}
+	rtr.responseJSON(w, http.StatusOK, resp)
+}
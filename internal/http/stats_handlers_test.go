@@ -0,0 +1,364 @@
+package http
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/cloudyy74/pr-reviewer-service/internal/models"
+	"github.com/cloudyy74/pr-reviewer-service/internal/service"
+)
+
+type fakeStatsService struct {
+	summaryFn    func(ctx context.Context) (*models.StatsSummary, error)
+	timeseriesFn func(ctx context.Context, q models.TimeseriesQuery) (*models.TimeseriesResponse, error)
+	anomaliesFn  func(ctx context.Context, weeks int) (*models.AnomaliesResponse, error)
+	heatmapFn    func(ctx context.Context, q models.HeatmapQuery) (*models.HeatmapResponse, error)
+	throughputFn func(ctx context.Context, weeks int, asOf *time.Time) (*models.ThroughputResponse, error)
+	completionFn func(ctx context.Context, q models.CompletionQuery) (*models.CompletionResponse, error)
+	labelsFn     func(ctx context.Context, q models.LabelStatsQuery) (*models.LabelStatsResponse, error)
+}
+
+func (f *fakeStatsService) GetLabelStats(ctx context.Context, q models.LabelStatsQuery) (*models.LabelStatsResponse, error) {
+	if f.labelsFn == nil {
+		return nil, errors.New("not implemented")
+	}
+	return f.labelsFn(ctx, q)
+}
+
+func (f *fakeStatsService) GetCompletionRates(ctx context.Context, q models.CompletionQuery) (*models.CompletionResponse, error) {
+	if f.completionFn == nil {
+		return nil, errors.New("not implemented")
+	}
+	return f.completionFn(ctx, q)
+}
+
+func (f *fakeStatsService) GetThroughput(ctx context.Context, weeks int, asOf *time.Time) (*models.ThroughputResponse, error) {
+	if f.throughputFn == nil {
+		return nil, errors.New("not implemented")
+	}
+	return f.throughputFn(ctx, weeks, asOf)
+}
+
+func (f *fakeStatsService) GetHeatmap(ctx context.Context, q models.HeatmapQuery) (*models.HeatmapResponse, error) {
+	if f.heatmapFn == nil {
+		return nil, errors.New("not implemented")
+	}
+	return f.heatmapFn(ctx, q)
+}
+
+func (f *fakeStatsService) GetAnomalies(ctx context.Context, weeks int) (*models.AnomaliesResponse, error) {
+	if f.anomaliesFn == nil {
+		return nil, errors.New("not implemented")
+	}
+	return f.anomaliesFn(ctx, weeks)
+}
+
+func (f *fakeStatsService) GetTimeseries(ctx context.Context, q models.TimeseriesQuery) (*models.TimeseriesResponse, error) {
+	if f.timeseriesFn == nil {
+		return nil, errors.New("not implemented")
+	}
+	return f.timeseriesFn(ctx, q)
+}
+
+func (f *fakeStatsService) GetSummary(ctx context.Context) (*models.StatsSummary, error) {
+	if f.summaryFn == nil {
+		return nil, errors.New("not implemented")
+	}
+	return f.summaryFn(ctx)
+}
+
+func newTestRouterWithStatsService(svc StatsService) *router {
+	return &router{
+		statsService: svc,
+		log:          slog.New(slog.NewTextHandler(io.Discard, nil)),
+	}
+}
+
+func TestGetStatsSummary_Success(t *testing.T) {
+	svc := &fakeStatsService{
+		summaryFn: func(context.Context) (*models.StatsSummary, error) {
+			return &models.StatsSummary{
+				OpenPRs:             4,
+				PRsNeedingReviewers: 1,
+				BusiestReviewer:     &models.ReviewerLoad{UserID: "u1", OpenAssignments: 3},
+				TeamsOverCapacity:   []*models.TeamLoad{},
+			}, nil
+		},
+	}
+	rtr := newTestRouterWithStatsService(svc)
+
+	req := httptest.NewRequest(http.MethodGet, "/stats/summary", nil)
+	rec := httptest.NewRecorder()
+
+	rtr.getStatsSummary(rec, req)
+
+	if rec.Code != http.StatusOK {
+		t.Fatalf("expected status 200, got %d", rec.Code)
+	}
+	var resp models.StatsSummary
+	if err := json.NewDecoder(rec.Body).Decode(&resp); err != nil {
+		t.Fatalf("decode response: %v", err)
+	}
+	if resp.OpenPRs != 4 || resp.BusiestReviewer == nil || resp.BusiestReviewer.UserID != "u1" {
+		t.Fatalf("unexpected response: %#v", resp)
+	}
+}
+
+func TestGetStatsSummary_Error(t *testing.T) {
+	svc := &fakeStatsService{
+		summaryFn: func(context.Context) (*models.StatsSummary, error) {
+			return nil, errors.New("boom")
+		},
+	}
+	rtr := newTestRouterWithStatsService(svc)
+
+	req := httptest.NewRequest(http.MethodGet, "/stats/summary", nil)
+	rec := httptest.NewRecorder()
+
+	rtr.getStatsSummary(rec, req)
+
+	if rec.Code != http.StatusInternalServerError {
+		t.Fatalf("expected status 500, got %d", rec.Code)
+	}
+}
+
+func TestGetStatsTimeseries_Success(t *testing.T) {
+	svc := &fakeStatsService{
+		timeseriesFn: func(_ context.Context, q models.TimeseriesQuery) (*models.TimeseriesResponse, error) {
+			if q.Metric != models.MetricPRsMerged || q.Interval != models.IntervalWeek || q.From == nil || q.To != nil {
+				t.Fatalf("unexpected query: %#v", q)
+			}
+			return &models.TimeseriesResponse{
+				Metric:   q.Metric,
+				Interval: q.Interval,
+				Series: []*models.TeamSeries{
+					{TeamName: "backend", Points: []*models.TimeseriesPoint{{BucketStart: *q.From, Count: 2}}},
+				},
+			}, nil
+		},
+	}
+	rtr := newTestRouterWithStatsService(svc)
+
+	req := httptest.NewRequest(http.MethodGet, "/stats/timeseries?metric=prs_merged&interval=week&from=2025-01-06", nil)
+	rec := httptest.NewRecorder()
+
+	rtr.getStatsTimeseries(rec, req)
+
+	if rec.Code != http.StatusOK {
+		t.Fatalf("expected status 200, got %d", rec.Code)
+	}
+	var resp models.TimeseriesResponse
+	if err := json.NewDecoder(rec.Body).Decode(&resp); err != nil {
+		t.Fatalf("decode response: %v", err)
+	}
+	if len(resp.Series) != 1 || !resp.Series[0].Points[0].BucketStart.Equal(time.Date(2025, 1, 6, 0, 0, 0, 0, time.UTC)) {
+		t.Fatalf("unexpected response: %#v", resp)
+	}
+}
+
+func TestGetStatsTimeseries_ValidationError(t *testing.T) {
+	svc := &fakeStatsService{
+		timeseriesFn: func(context.Context, models.TimeseriesQuery) (*models.TimeseriesResponse, error) {
+			return nil, service.ErrStatsValidation
+		},
+	}
+	rtr := newTestRouterWithStatsService(svc)
+
+	req := httptest.NewRequest(http.MethodGet, "/stats/timeseries?metric=unknown", nil)
+	rec := httptest.NewRecorder()
+
+	rtr.getStatsTimeseries(rec, req)
+
+	if rec.Code != http.StatusBadRequest {
+		t.Fatalf("expected status 400, got %d", rec.Code)
+	}
+}
+
+func TestGetStatsAnomalies_Success(t *testing.T) {
+	svc := &fakeStatsService{
+		anomaliesFn: func(_ context.Context, weeks int) (*models.AnomaliesResponse, error) {
+			if weeks != 8 {
+				t.Fatalf("unexpected weeks: %d", weeks)
+			}
+			return &models.AnomaliesResponse{
+				Threshold: 0.5,
+				Anomalies: []*models.AssignmentAnomaly{{TeamName: "backend", UserID: "u1", Share: 0.8}},
+			}, nil
+		},
+	}
+	rtr := newTestRouterWithStatsService(svc)
+
+	req := httptest.NewRequest(http.MethodGet, "/stats/anomalies?weeks=8", nil)
+	rec := httptest.NewRecorder()
+
+	rtr.getStatsAnomalies(rec, req)
+
+	if rec.Code != http.StatusOK {
+		t.Fatalf("expected status 200, got %d", rec.Code)
+	}
+	var resp models.AnomaliesResponse
+	if err := json.NewDecoder(rec.Body).Decode(&resp); err != nil {
+		t.Fatalf("decode response: %v", err)
+	}
+	if len(resp.Anomalies) != 1 || resp.Anomalies[0].UserID != "u1" {
+		t.Fatalf("unexpected response: %#v", resp)
+	}
+}
+
+func TestGetStatsAnomalies_InvalidWeeks(t *testing.T) {
+	rtr := newTestRouterWithStatsService(&fakeStatsService{})
+
+	req := httptest.NewRequest(http.MethodGet, "/stats/anomalies?weeks=abc", nil)
+	rec := httptest.NewRecorder()
+
+	rtr.getStatsAnomalies(rec, req)
+
+	if rec.Code != http.StatusBadRequest {
+		t.Fatalf("expected status 400, got %d", rec.Code)
+	}
+}
+
+func TestGetStatsHeatmap_Success(t *testing.T) {
+	svc := &fakeStatsService{
+		heatmapFn: func(_ context.Context, q models.HeatmapQuery) (*models.HeatmapResponse, error) {
+			if q.TeamName != "backend" || q.From != nil || q.To == nil {
+				t.Fatalf("unexpected query: %#v", q)
+			}
+			heatmap := &models.TeamHeatmap{TeamName: "backend", Total: 3}
+			heatmap.Cells[2][14] = 3
+			return &models.HeatmapResponse{Teams: []*models.TeamHeatmap{heatmap}}, nil
+		},
+	}
+	rtr := newTestRouterWithStatsService(svc)
+
+	req := httptest.NewRequest(http.MethodGet, "/stats/heatmap?team_name=backend&to=2025-03-31", nil)
+	rec := httptest.NewRecorder()
+
+	rtr.getStatsHeatmap(rec, req)
+
+	if rec.Code != http.StatusOK {
+		t.Fatalf("expected status 200, got %d", rec.Code)
+	}
+	var resp models.HeatmapResponse
+	if err := json.NewDecoder(rec.Body).Decode(&resp); err != nil {
+		t.Fatalf("decode response: %v", err)
+	}
+	if len(resp.Teams) != 1 || resp.Teams[0].Cells[2][14] != 3 {
+		t.Fatalf("unexpected response: %#v", resp)
+	}
+}
+
+func TestGetStatsThroughput_Success(t *testing.T) {
+	svc := &fakeStatsService{
+		throughputFn: func(_ context.Context, weeks int, asOf *time.Time) (*models.ThroughputResponse, error) {
+			if weeks != 0 || asOf != nil {
+				t.Fatalf("unexpected args: %d %v", weeks, asOf)
+			}
+			return &models.ThroughputResponse{
+				Weeks: 4,
+				Teams: []*models.TeamThroughput{{TeamName: "backend", Merged: 7, PreviousMerged: 5}},
+			}, nil
+		},
+	}
+	rtr := newTestRouterWithStatsService(svc)
+
+	req := httptest.NewRequest(http.MethodGet, "/stats/throughput", nil)
+	rec := httptest.NewRecorder()
+
+	rtr.getStatsThroughput(rec, req)
+
+	if rec.Code != http.StatusOK {
+		t.Fatalf("expected status 200, got %d", rec.Code)
+	}
+	var resp models.ThroughputResponse
+	if err := json.NewDecoder(rec.Body).Decode(&resp); err != nil {
+		t.Fatalf("decode response: %v", err)
+	}
+	if resp.Weeks != 4 || len(resp.Teams) != 1 || resp.Teams[0].Merged != 7 {
+		t.Fatalf("unexpected response: %#v", resp)
+	}
+}
+
+func TestGetStatsCompletion_Success(t *testing.T) {
+	rate := 0.5
+	svc := &fakeStatsService{
+		completionFn: func(_ context.Context, q models.CompletionQuery) (*models.CompletionResponse, error) {
+			if q.TeamName != "backend" || q.From == nil || q.AsOf == nil || !q.AsOf.Equal(time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)) {
+				t.Fatalf("unexpected query: %#v", q)
+			}
+			return &models.CompletionResponse{
+				Users: []*models.UserCompletion{{UserID: "u1", Assigned: 2, Merged: 1, Declined: 1, CompletionRate: &rate}},
+			}, nil
+		},
+	}
+	rtr := newTestRouterWithStatsService(svc)
+
+	req := httptest.NewRequest(http.MethodGet, "/stats/completion?team_name=backend&from=2025-03-01&as_of=2025-01-01", nil)
+	rec := httptest.NewRecorder()
+
+	rtr.getStatsCompletion(rec, req)
+
+	if rec.Code != http.StatusOK {
+		t.Fatalf("expected status 200, got %d", rec.Code)
+	}
+	var resp models.CompletionResponse
+	if err := json.NewDecoder(rec.Body).Decode(&resp); err != nil {
+		t.Fatalf("decode response: %v", err)
+	}
+	if len(resp.Users) != 1 || resp.Users[0].CompletionRate == nil || *resp.Users[0].CompletionRate != 0.5 {
+		t.Fatalf("unexpected response: %#v", resp)
+	}
+}
+
+func TestGetStatsCompletion_InvalidFrom(t *testing.T) {
+	rtr := newTestRouterWithStatsService(&fakeStatsService{})
+
+	req := httptest.NewRequest(http.MethodGet, "/stats/completion?from=yesterday", nil)
+	rec := httptest.NewRecorder()
+
+	rtr.getStatsCompletion(rec, req)
+
+	if rec.Code != http.StatusBadRequest {
+		t.Fatalf("expected status 400, got %d", rec.Code)
+	}
+}
+
+func TestGetStatsLabels(t *testing.T) {
+	svc := &fakeStatsService{
+		labelsFn: func(_ context.Context, q models.LabelStatsQuery) (*models.LabelStatsResponse, error) {
+			if q.TeamName != "backend" || q.From == nil || q.To != nil {
+				t.Fatalf("unexpected query: %#v", q)
+			}
+			return &models.LabelStatsResponse{
+				Labels: []*models.LabelStats{{Label: "bug", Open: 2, Merged: 1}},
+			}, nil
+		},
+	}
+	rtr := newTestRouterWithStatsService(svc)
+
+	rec := httptest.NewRecorder()
+	rtr.getStatsLabels(rec, httptest.NewRequest(http.MethodGet, "/stats/labels?team_name=backend&from=2025-03-01", nil))
+	if rec.Code != http.StatusOK {
+		t.Fatalf("expected status 200, got %d", rec.Code)
+	}
+	var resp models.LabelStatsResponse
+	if err := json.NewDecoder(rec.Body).Decode(&resp); err != nil {
+		t.Fatalf("decode response: %v", err)
+	}
+	if len(resp.Labels) != 1 || resp.Labels[0].Label != "bug" || resp.Labels[0].AvgMergeHours != nil {
+		t.Fatalf("unexpected response: %#v", resp)
+	}
+
+	rec = httptest.NewRecorder()
+	rtr.getStatsLabels(rec, httptest.NewRequest(http.MethodGet, "/stats/labels?to=tomorrow", nil))
+	if rec.Code != http.StatusBadRequest {
+		t.Fatalf("expected status 400, got %d", rec.Code)
+	}
+}
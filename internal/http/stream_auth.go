@@ -0,0 +1,102 @@
+package http
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"log/slog"
+	"net/http"
+	"slices"
+	"strings"
+
+	"github.com/cloudyy74/pr-reviewer-service/internal/models"
+)
+
+const streamTokenParam = "stream_token"
+
+type StreamTokenService interface {
+	IssueStreamToken(ctx context.Context, userID string) (*models.StreamToken, error)
+}
+
+type StreamTokenVerifier interface {
+	Verify(token string) (string, error)
+}
+
+var streamingPaths = []string{
+	"/users/awaitAssignment",
+}
+
+func RequireStreamToken(next http.Handler, verifier StreamTokenVerifier, required bool, log *slog.Logger) http.Handler {
+	rtr := &router{log: log}
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if !slices.Contains(streamingPaths, r.URL.Path) {
+			next.ServeHTTP(w, r)
+			return
+		}
+		token := streamToken(r)
+		if token == "" {
+			if required {
+				rtr.handleError(w, r, newResponseError(ErrCodeUnauthorized, "stream token is required"))
+				return
+			}
+			next.ServeHTTP(w, r)
+			return
+		}
+		userID, err := verifier.Verify(token)
+		if err != nil {
+			rtr.handleError(w, r, err)
+			return
+		}
+
+		query := r.URL.Query()
+		if requested := strings.TrimSpace(query.Get("user_id")); requested != "" && requested != userID {
+			rtr.handleError(w, r, newResponseError(ErrCodeForbidden, "stream token was issued for another user"))
+			return
+		}
+		query.Set("user_id", userID)
+		query.Del(streamTokenParam)
+		r = r.Clone(r.Context())
+		r.URL.RawQuery = query.Encode()
+		r.Header.Del("Authorization")
+		next.ServeHTTP(w, r)
+	})
+}
+
+func streamToken(r *http.Request) string {
+	if token, ok := strings.CutPrefix(r.Header.Get("Authorization"), "Bearer "); ok {
+		return strings.TrimSpace(token)
+	}
+	return strings.TrimSpace(r.URL.Query().Get(streamTokenParam))
+}
+
+func SetupStreamTokenRoutes(mux *http.ServeMux, tokens StreamTokenService, log *slog.Logger) error {
+	if mux == nil {
+		return errors.New("mux cannot be nil")
+	}
+	if tokens == nil {
+		return errors.New("stream token service cannot be nil")
+	}
+	if log == nil {
+		return errors.New("logger cannot be nil")
+	}
+	r := router{
+		streamTokens: tokens,
+		log:          log,
+	}
+	mux.HandleFunc("POST /auth/streamToken", r.panicMiddleware(r.loggingMiddleware(r.issueStreamToken)))
+	return nil
+}
+
+func (rtr *router) issueStreamToken(w http.ResponseWriter, r *http.Request) {
+	var req models.StreamTokenRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		rtr.handleError(w, r, newResponseError(ErrCodeBadRequest, "bad json request"))
+		return
+	}
+	token, err := rtr.streamTokens.IssueStreamToken(r.Context(), req.UserID)
+	if err != nil {
+		rtr.handleError(w, r, err)
+		return
+	}
+	rtr.responseJSON(w, http.StatusOK, token)
+}
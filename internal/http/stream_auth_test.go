@@ -0,0 +1,119 @@
+package http
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/cloudyy74/pr-reviewer-service/internal/models"
+	"github.com/cloudyy74/pr-reviewer-service/internal/streamtoken"
+)
+
+type fakeVerifier map[string]string
+
+func (f fakeVerifier) Verify(token string) (string, error) {
+	if token == "expired" {
+		return "", streamtoken.ErrExpiredToken
+	}
+	userID, ok := f[token]
+	if !ok {
+		return "", streamtoken.ErrInvalidToken
+	}
+	return userID, nil
+}
+
+func TestRequireStreamToken(t *testing.T) {
+	var gotQuery string
+	next := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		gotQuery = r.URL.RawQuery
+		w.WriteHeader(http.StatusOK)
+	})
+	log := slog.New(slog.NewTextHandler(io.Discard, nil))
+	h := RequireStreamToken(next, fakeVerifier{"good": "u1"}, true, log)
+
+	cases := []struct {
+		name   string
+		target string
+		auth   string
+		want   int
+		code   string
+	}{
+		{"query token", "/users/awaitAssignment?stream_token=good&timeout=5s", "", http.StatusOK, ""},
+		{"bearer token", "/users/awaitAssignment?user_id=u1", "Bearer good", http.StatusOK, ""},
+		{"missing token", "/users/awaitAssignment?user_id=u1", "", http.StatusUnauthorized, ErrCodeUnauthorized},
+		{"expired token", "/users/awaitAssignment?stream_token=expired", "", http.StatusUnauthorized, ErrCodeUnauthorized},
+		{"forged token", "/users/awaitAssignment?stream_token=forged", "", http.StatusUnauthorized, ErrCodeUnauthorized},
+		{"other user", "/users/awaitAssignment?stream_token=good&user_id=u2", "", http.StatusForbidden, ErrCodeForbidden},
+		{"not streaming", "/users/getReview?user_id=u2", "", http.StatusOK, ""},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			gotQuery = ""
+			req := httptest.NewRequest(http.MethodGet, tc.target, nil)
+			if tc.auth != "" {
+				req.Header.Set("Authorization", tc.auth)
+			}
+			rec := httptest.NewRecorder()
+			h.ServeHTTP(rec, req)
+			if rec.Code != tc.want {
+				t.Fatalf("expected %d, got %d", tc.want, rec.Code)
+			}
+			if tc.code != "" {
+				var resp models.ErrorResponse
+				if err := json.NewDecoder(rec.Body).Decode(&resp); err != nil {
+					t.Fatalf("failed to decode error: %v", err)
+				}
+				if resp.Error.Code != tc.code {
+					t.Fatalf("expected code %s, got %s", tc.code, resp.Error.Code)
+				}
+			}
+		})
+	}
+
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/users/awaitAssignment?stream_token=good&timeout=5s", nil))
+	if strings.Contains(gotQuery, "stream_token") || !strings.Contains(gotQuery, "user_id=u1") {
+		t.Fatalf("unexpected forwarded query: %q", gotQuery)
+	}
+}
+
+func TestRequireStreamToken_Optional(t *testing.T) {
+	next := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) { w.WriteHeader(http.StatusOK) })
+	h := RequireStreamToken(next, fakeVerifier{}, false, slog.New(slog.NewTextHandler(io.Discard, nil)))
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/users/awaitAssignment?user_id=u1", nil))
+	if rec.Code != http.StatusOK {
+		t.Fatalf("expected request without token to pass, got %d", rec.Code)
+	}
+}
+
+type fakeStreamTokens struct{}
+
+func (fakeStreamTokens) IssueStreamToken(_ context.Context, userID string) (*models.StreamToken, error) {
+	return &models.StreamToken{Token: "signed", UserID: userID, ExpiresAt: time.Unix(100, 0)}, nil
+}
+
+func TestIssueStreamToken(t *testing.T) {
+	mux := http.NewServeMux()
+	if err := SetupStreamTokenRoutes(mux, fakeStreamTokens{}, slog.New(slog.NewTextHandler(io.Discard, nil))); err != nil {
+		t.Fatalf("SetupStreamTokenRoutes returned err: %v", err)
+	}
+	rec := httptest.NewRecorder()
+	mux.ServeHTTP(rec, httptest.NewRequest(http.MethodPost, "/auth/streamToken", strings.NewReader(`{"user_id":"u1"}`)))
+	if rec.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d", rec.Code)
+	}
+	var resp models.StreamToken
+	if err := json.NewDecoder(rec.Body).Decode(&resp); err != nil {
+		t.Fatalf("failed to decode response: %v", err)
+	}
+	if resp.Token != "signed" || resp.UserID != "u1" {
+		t.Fatalf("unexpected response: %+v", resp)
+	}
+}
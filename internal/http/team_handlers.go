@@ -12,18 +12,23 @@ type TeamService interface {
 	CreateTeam(context.Context, *models.Team) (*models.Team, error)
 	GetTeamUsers(context.Context, string) ([]*models.User, error)
 	DeactivateTeamUsers(context.Context, string) (*models.TeamDeactivateResponse, error)
+	GetTeamSettings(context.Context, string) (*models.TeamSettings, error)
+	SetTeamSettings(context.Context, *models.TeamSettings) (*models.TeamSettings, error)
+	CountOpenPRs(context.Context, string) (int, error)
+	GetTeamStats(context.Context, string) (*models.TeamStats, error)
+	GetMembershipHistory(context.Context, models.MembershipHistoryQuery) (*models.MembershipHistoryResponse, error)
 }
 
 func (rtr *router) createTeam(w http.ResponseWriter, r *http.Request) {
 	var team models.Team
 	if err := json.NewDecoder(r.Body).Decode(&team); err != nil {
-		rtr.handleError(w, newResponseError(ErrCodeBadRequest, "bad json request"))
+		rtr.handleError(w, r, newResponseError(ErrCodeBadRequest, "bad json request"))
 		return
 	}
 
 	createdTeam, err := rtr.teamService.CreateTeam(r.Context(), &team)
 	if err != nil {
-		rtr.handleError(w, err)
+		rtr.handleError(w, r, err)
 		return
 	}
 
@@ -34,10 +39,23 @@ func (rtr *router) createTeam(w http.ResponseWriter, r *http.Request) {
 }
 
 func (rtr *router) getTeam(w http.ResponseWriter, r *http.Request) {
+	exp := rtr.teamExpanders()
+	expand, err := exp.parse(r)
+	if err != nil {
+		rtr.handleError(w, r, err)
+		return
+	}
+
+	includeStats, err := parseBoolParam(r.URL.Query().Get("include_stats"))
+	if err != nil {
+		rtr.handleError(w, r, newResponseError(ErrCodeValidation, "include_stats must be a boolean"))
+		return
+	}
+
 	teamName := r.URL.Query().Get("team_name")
 	users, err := rtr.teamService.GetTeamUsers(r.Context(), teamName)
 	if err != nil {
-		rtr.handleError(w, err)
+		rtr.handleError(w, r, err)
 		return
 	}
 
@@ -45,19 +63,82 @@ func (rtr *router) getTeam(w http.ResponseWriter, r *http.Request) {
 		Name:    teamName,
 		Members: users,
 	}
+	if includeStats {
+		if response.Stats, err = rtr.teamService.GetTeamStats(r.Context(), teamName); err != nil {
+			rtr.handleError(w, r, err)
+			return
+		}
+	}
+	if err := exp.apply(r.Context(), expand, response); err != nil {
+		rtr.handleError(w, r, err)
+		return
+	}
 	rtr.responseJSON(w, http.StatusOK, response)
 }
 
 func (rtr *router) deactivateTeamUsers(w http.ResponseWriter, r *http.Request) {
 	var req models.TeamDeactivateRequest
 	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
-		rtr.handleError(w, newResponseError(ErrCodeBadRequest, "bad json request"))
+		rtr.handleError(w, r, newResponseError(ErrCodeBadRequest, "bad json request"))
 		return
 	}
 	resp, err := rtr.teamService.DeactivateTeamUsers(r.Context(), req.TeamName)
 	if err != nil {
-		rtr.handleError(w, err)
+		rtr.handleError(w, r, err)
 		return
 	}
 	rtr.responseJSON(w, http.StatusOK, resp)
 }
+
+func (rtr *router) getTeamSettings(w http.ResponseWriter, r *http.Request) {
+	teamName := r.URL.Query().Get("team_name")
+	settings, err := rtr.teamService.GetTeamSettings(r.Context(), teamName)
+	if err != nil {
+		rtr.handleError(w, r, err)
+		return
+	}
+	rtr.responseJSON(w, http.StatusOK, &models.TeamSettingsResponse{Settings: *settings})
+}
+
+func (rtr *router) setTeamSettings(w http.ResponseWriter, r *http.Request) {
+	var req models.TeamSettings
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		rtr.handleError(w, r, newResponseError(ErrCodeBadRequest, "bad json request"))
+		return
+	}
+	settings, err := rtr.teamService.SetTeamSettings(r.Context(), &req)
+	if err != nil {
+		rtr.handleError(w, r, err)
+		return
+	}
+	rtr.responseJSON(w, http.StatusOK, &models.TeamSettingsResponse{Settings: *settings})
+}
+
+func (rtr *router) getTeamHistory(w http.ResponseWriter, r *http.Request) {
+	query := r.URL.Query()
+	q := models.MembershipHistoryQuery{TeamName: query.Get("team_name")}
+	var err error
+	if q.From, err = parseTimeParam(query.Get("from")); err != nil {
+		rtr.handleError(w, r, newResponseError(ErrCodeValidation, "from must be RFC3339 timestamp or YYYY-MM-DD date"))
+		return
+	}
+	if q.To, err = parseTimeParam(query.Get("to")); err != nil {
+		rtr.handleError(w, r, newResponseError(ErrCodeValidation, "to must be RFC3339 timestamp or YYYY-MM-DD date"))
+		return
+	}
+	if q.Limit, err = parseIntParam(query.Get("limit")); err != nil {
+		rtr.handleError(w, r, newResponseError(ErrCodeValidation, "limit must be an integer"))
+		return
+	}
+	if q.Offset, err = parseIntParam(query.Get("offset")); err != nil {
+		rtr.handleError(w, r, newResponseError(ErrCodeValidation, "offset must be an integer"))
+		return
+	}
+
+	resp, err := rtr.teamService.GetMembershipHistory(r.Context(), q)
+	if err != nil {
+		rtr.handleError(w, r, err)
+		return
+	}
+	rtr.responseList(w, r, http.StatusOK, resp)
+}
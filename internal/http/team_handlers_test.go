@@ -20,6 +20,18 @@ type fakeTeamService struct {
 	createFn     func(ctx context.Context, team *models.Team) (*models.Team, error)
 	getFn        func(ctx context.Context, teamName string) ([]*models.User, error)
 	deactivateFn func(ctx context.Context, teamName string) (*models.TeamDeactivateResponse, error)
+	getSetFn     func(ctx context.Context, teamName string) (*models.TeamSettings, error)
+	setSetFn     func(ctx context.Context, settings *models.TeamSettings) (*models.TeamSettings, error)
+	countOpenFn  func(ctx context.Context, teamName string) (int, error)
+	statsFn      func(ctx context.Context, teamName string) (*models.TeamStats, error)
+	historyFn    func(ctx context.Context, q models.MembershipHistoryQuery) (*models.MembershipHistoryResponse, error)
+}
+
+func (f *fakeTeamService) GetMembershipHistory(ctx context.Context, q models.MembershipHistoryQuery) (*models.MembershipHistoryResponse, error) {
+	if f.historyFn == nil {
+		return nil, errors.New("not implemented")
+	}
+	return f.historyFn(ctx, q)
 }
 
 func (f *fakeTeamService) CreateTeam(ctx context.Context, team *models.Team) (*models.Team, error) {
@@ -43,6 +55,34 @@ func (f *fakeTeamService) DeactivateTeamUsers(ctx context.Context, teamName stri
 	return f.deactivateFn(ctx, teamName)
 }
 
+func (f *fakeTeamService) GetTeamSettings(ctx context.Context, teamName string) (*models.TeamSettings, error) {
+	if f.getSetFn == nil {
+		return nil, errors.New("not implemented")
+	}
+	return f.getSetFn(ctx, teamName)
+}
+
+func (f *fakeTeamService) SetTeamSettings(ctx context.Context, settings *models.TeamSettings) (*models.TeamSettings, error) {
+	if f.setSetFn == nil {
+		return nil, errors.New("not implemented")
+	}
+	return f.setSetFn(ctx, settings)
+}
+
+func (f *fakeTeamService) CountOpenPRs(ctx context.Context, teamName string) (int, error) {
+	if f.countOpenFn == nil {
+		return 0, errors.New("not implemented")
+	}
+	return f.countOpenFn(ctx, teamName)
+}
+
+func (f *fakeTeamService) GetTeamStats(ctx context.Context, teamName string) (*models.TeamStats, error) {
+	if f.statsFn == nil {
+		return nil, errors.New("not implemented")
+	}
+	return f.statsFn(ctx, teamName)
+}
+
 func newTestRouterWithTeamService(svc TeamService) *router {
 	return &router{
 		teamService: svc,
@@ -423,3 +463,180 @@ func TestDeactivateTeamUsers_Internal(t *testing.T) {
 		t.Fatalf("expected status 500, got %d", rec.Code)
 	}
 }
+
+func TestGetTeamSettings_Success(t *testing.T) {
+	svc := &fakeTeamService{
+		getSetFn: func(_ context.Context, teamName string) (*models.TeamSettings, error) {
+			return &models.TeamSettings{TeamName: teamName, StrictDuplicateCheck: true}, nil
+		},
+	}
+	rtr := newTestRouterWithTeamService(svc)
+
+	req := httptest.NewRequest(http.MethodGet, "/team/getSettings?team_name=backend", nil)
+	rec := httptest.NewRecorder()
+
+	rtr.getTeamSettings(rec, req)
+
+	if rec.Code != http.StatusOK {
+		t.Fatalf("expected status 200, got %d", rec.Code)
+	}
+	var resp models.TeamSettingsResponse
+	if err := json.NewDecoder(rec.Body).Decode(&resp); err != nil {
+		t.Fatalf("decode response: %v", err)
+	}
+	if resp.Settings.TeamName != "backend" || !resp.Settings.StrictDuplicateCheck {
+		t.Fatalf("unexpected settings: %#v", resp.Settings)
+	}
+}
+
+func TestSetTeamSettings_NotFound(t *testing.T) {
+	svc := &fakeTeamService{
+		setSetFn: func(context.Context, *models.TeamSettings) (*models.TeamSettings, error) {
+			return nil, service.ErrTeamNotFound
+		},
+	}
+	rtr := newTestRouterWithTeamService(svc)
+
+	req := httptest.NewRequest(http.MethodPost, "/team/setSettings", bytes.NewBufferString(`{"team_name":"backend","strict_duplicate_check":true}`))
+	rec := httptest.NewRecorder()
+
+	rtr.setTeamSettings(rec, req)
+
+	if rec.Code != http.StatusNotFound {
+		t.Fatalf("expected status 404, got %d", rec.Code)
+	}
+}
+
+func TestSetTeamSettings_BadJSON(t *testing.T) {
+	rtr := newTestRouterWithTeamService(&fakeTeamService{})
+
+	req := httptest.NewRequest(http.MethodPost, "/team/setSettings", bytes.NewBufferString(`{bad`))
+	rec := httptest.NewRecorder()
+
+	rtr.setTeamSettings(rec, req)
+
+	if rec.Code != http.StatusBadRequest {
+		t.Fatalf("expected status 400, got %d", rec.Code)
+	}
+}
+
+func TestGetTeam_ExpandOpenPRs(t *testing.T) {
+	svc := &fakeTeamService{
+		getFn: func(context.Context, string) ([]*models.User, error) {
+			return []*models.User{{ID: "u1"}}, nil
+		},
+		countOpenFn: func(_ context.Context, teamName string) (int, error) {
+			if teamName != "backend" {
+				t.Fatalf("unexpected team %q", teamName)
+			}
+			return 5, nil
+		},
+	}
+	rtr := newTestRouterWithTeamService(svc)
+
+	req := httptest.NewRequest(http.MethodGet, "/team/get?team_name=backend&expand=open_prs", nil)
+	rec := httptest.NewRecorder()
+
+	rtr.getTeam(rec, req)
+
+	if rec.Code != http.StatusOK {
+		t.Fatalf("expected status 200, got %d", rec.Code)
+	}
+	var resp models.Team
+	if err := json.NewDecoder(rec.Body).Decode(&resp); err != nil {
+		t.Fatalf("decode response: %v", err)
+	}
+	if resp.OpenPRs == nil || *resp.OpenPRs != 5 {
+		t.Fatalf("unexpected open prs: %v", resp.OpenPRs)
+	}
+}
+
+func TestGetTeam_UnsupportedExpand(t *testing.T) {
+	rtr := newTestRouterWithTeamService(&fakeTeamService{})
+
+	req := httptest.NewRequest(http.MethodGet, "/team/get?team_name=backend&expand=users", nil)
+	rec := httptest.NewRecorder()
+
+	rtr.getTeam(rec, req)
+
+	if rec.Code != http.StatusBadRequest {
+		t.Fatalf("expected status 400, got %d", rec.Code)
+	}
+}
+
+func TestGetTeam_IncludeStats(t *testing.T) {
+	svc := &fakeTeamService{
+		getFn: func(context.Context, string) ([]*models.User, error) {
+			return []*models.User{{ID: "u1", IsActive: true}}, nil
+		},
+		statsFn: func(context.Context, string) (*models.TeamStats, error) {
+			return &models.TeamStats{MembersCount: 1, ActiveMembers: 1, OpenPRsAuthored: 2, OpenAssignments: 3}, nil
+		},
+	}
+	rtr := newTestRouterWithTeamService(svc)
+
+	req := httptest.NewRequest(http.MethodGet, "/team/get?team_name=backend&include_stats=true", nil)
+	rec := httptest.NewRecorder()
+
+	rtr.getTeam(rec, req)
+
+	if rec.Code != http.StatusOK {
+		t.Fatalf("expected status 200, got %d", rec.Code)
+	}
+	var resp models.Team
+	if err := json.NewDecoder(rec.Body).Decode(&resp); err != nil {
+		t.Fatalf("decode response: %v", err)
+	}
+	if resp.Stats == nil || resp.Stats.OpenAssignments != 3 {
+		t.Fatalf("unexpected stats: %#v", resp.Stats)
+	}
+}
+
+func TestGetTeam_IncludeStatsInvalid(t *testing.T) {
+	rtr := newTestRouterWithTeamService(&fakeTeamService{})
+
+	req := httptest.NewRequest(http.MethodGet, "/team/get?team_name=backend&include_stats=maybe", nil)
+	rec := httptest.NewRecorder()
+
+	rtr.getTeam(rec, req)
+
+	if rec.Code != http.StatusBadRequest {
+		t.Fatalf("expected status 400, got %d", rec.Code)
+	}
+}
+
+func TestGetTeamHistory(t *testing.T) {
+	svc := &fakeTeamService{
+		historyFn: func(_ context.Context, q models.MembershipHistoryQuery) (*models.MembershipHistoryResponse, error) {
+			if q.TeamName != "backend" || q.Limit != 5 || q.From == nil || q.To != nil {
+				t.Fatalf("unexpected query %+v", q)
+			}
+			return &models.MembershipHistoryResponse{
+				TeamName: q.TeamName,
+				Changes:  []*models.MembershipChange{{TeamName: "backend", UserID: "u1", Change: models.MembershipJoined}},
+				Total:    1,
+				Limit:    q.Limit,
+			}, nil
+		},
+	}
+	rtr := newTestRouterWithTeamService(svc)
+
+	rec := httptest.NewRecorder()
+	rtr.getTeamHistory(rec, httptest.NewRequest(http.MethodGet, "/team/history?team_name=backend&from=2025-01-01&limit=5", nil))
+	if rec.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d: %s", rec.Code, rec.Body.String())
+	}
+	var resp models.MembershipHistoryResponse
+	if err := json.NewDecoder(rec.Body).Decode(&resp); err != nil {
+		t.Fatalf("decode response: %v", err)
+	}
+	if len(resp.Changes) != 1 || resp.Changes[0].Change != models.MembershipJoined {
+		t.Fatalf("unexpected response %+v", resp)
+	}
+
+	rec = httptest.NewRecorder()
+	rtr.getTeamHistory(rec, httptest.NewRequest(http.MethodGet, "/team/history?team_name=backend&limit=x", nil))
+	if rec.Code != http.StatusBadRequest {
+		t.Fatalf("expected 400 for bad limit, got %d", rec.Code)
+	}
+}
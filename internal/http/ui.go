@@ -0,0 +1,42 @@
+package http
+
+import (
+	"embed"
+	"errors"
+	"io/fs"
+	"log/slog"
+	"net/http"
+)
+
+//go:embed ui
+var uiFiles embed.FS
+
+// uiCSP keeps the dashboard to its own scripts and the API of this server.
+const uiCSP = "default-src 'self'; frame-ancestors 'none'"
+
+// SetupUIRoutes serves the embedded dashboard under /ui/. The page is static
+// and reads the JSON API from the browser, so it sees exactly what the
+// caller's session or token may see.
+func SetupUIRoutes(mux *http.ServeMux, log *slog.Logger) error {
+	if mux == nil {
+		return errors.New("mux cannot be nil")
+	}
+	if log == nil {
+		return errors.New("logger cannot be nil")
+	}
+	files, err := fs.Sub(uiFiles, "ui")
+	if err != nil {
+		return err
+	}
+	r := router{log: log}
+	static := http.StripPrefix("/ui/", http.FileServerFS(files))
+	mux.HandleFunc("GET /ui", r.panicMiddleware(func(w http.ResponseWriter, req *http.Request) {
+		http.Redirect(w, req, "/ui/", http.StatusMovedPermanently)
+	}))
+	mux.HandleFunc("GET /ui/", r.panicMiddleware(func(w http.ResponseWriter, req *http.Request) {
+		w.Header().Set("Content-Security-Policy", uiCSP)
+		w.Header().Set("X-Content-Type-Options", "nosniff")
+		static.ServeHTTP(w, req)
+	}))
+	return nil
+}
@@ -0,0 +1,130 @@
+"use strict";
+
+// The dashboard only reads the public JSON API. Lists are requested without
+// the response envelope so the page works whatever the server default is.
+async function getJSON(path) {
+  const resp = await fetch(path, {
+    credentials: "same-origin",
+    headers: { "Accept": "application/json", "X-Response-Envelope": "false" },
+  });
+  const body = await resp.json().catch(() => null);
+  if (!resp.ok) {
+    const message = body && body.error ? body.error.message : resp.statusText;
+    throw new Error(message || ("HTTP " + resp.status));
+  }
+  return body;
+}
+
+function cell(text, className) {
+  const td = document.createElement("td");
+  td.textContent = text;
+  if (className) {
+    td.className = className;
+  }
+  return td;
+}
+
+function fillTable(id, rows) {
+  const tbody = document.querySelector("#" + id + " tbody");
+  tbody.replaceChildren(...rows.map((cells) => {
+    const tr = document.createElement("tr");
+    tr.append(...cells);
+    return tr;
+  }));
+}
+
+function showError(id, err) {
+  const el = document.getElementById(id);
+  el.hidden = !err;
+  el.textContent = err ? err.message : "";
+}
+
+async function loadSummary() {
+  try {
+    const s = await getJSON("/stats/summary");
+    const items = [
+      ["Открытых PR", s.open_prs],
+      ["Ждут ревьюверов", s.prs_needing_reviewers],
+      ["Назначение сегодня, с", Math.round(s.avg_assignment_latency_seconds_today)],
+      ["Самый загруженный", s.busiest_reviewer ? s.busiest_reviewer.username + " (" + s.busiest_reviewer.open_assignments + ")" : "—"],
+      ["Команд сверх нормы", (s.teams_over_capacity || []).length],
+    ];
+    document.getElementById("summary").replaceChildren(...items.map(([label, value]) => {
+      const div = document.createElement("div");
+      const dt = document.createElement("dt");
+      const dd = document.createElement("dd");
+      dt.textContent = label;
+      dd.textContent = value;
+      div.append(dt, dd);
+      return div;
+    }));
+    showError("summary-error", null);
+  } catch (err) {
+    showError("summary-error", err);
+  }
+}
+
+async function loadTeam(team) {
+  const hint = document.getElementById("load-hint");
+  if (!team) {
+    fillTable("load", []);
+    hint.hidden = false;
+    return;
+  }
+  hint.hidden = true;
+  try {
+    const [teamResp, stats] = await Promise.all([
+      getJSON("/team/get?team_name=" + encodeURIComponent(team)),
+      getJSON("/stats/assignments"),
+    ]);
+    const counts = new Map((stats.assignments_by_user || []).map((u) => [u.user_id, u.assignments_count]));
+    const members = [...teamResp.members].sort((a, b) => (counts.get(b.user_id) || 0) - (counts.get(a.user_id) || 0));
+    fillTable("load", members.map((m) => [
+      cell(m.username || m.user_id),
+      cell(m.is_active ? "да" : "нет"),
+      cell(String(counts.get(m.user_id) || 0), "num"),
+    ]));
+    showError("load-error", null);
+  } catch (err) {
+    fillTable("load", []);
+    showError("load-error", err);
+  }
+}
+
+async function loadNeedReviewers(team) {
+  const path = "/pullRequest/needReviewers" + (team ? "?team_name=" + encodeURIComponent(team) : "");
+  try {
+    const resp = await getJSON(path);
+    const prs = resp.pull_requests || [];
+    fillTable("need-reviewers", prs.map((pr) => [
+      cell(pr.pull_request_id),
+      cell(pr.pull_request_name),
+      cell(pr.author_id),
+      cell(pr.team_name),
+      cell(String(pr.reviewers_count), "num"),
+      cell(String(pr.missing_reviewers), "num"),
+    ]));
+    document.getElementById("need-reviewers-hint").hidden = prs.length > 0;
+    showError("need-reviewers-error", null);
+  } catch (err) {
+    fillTable("need-reviewers", []);
+    showError("need-reviewers-error", err);
+  }
+}
+
+function refresh() {
+  const team = new URLSearchParams(location.search).get("team") || "";
+  document.getElementById("team").value = team;
+  loadSummary();
+  loadTeam(team);
+  loadNeedReviewers(team);
+}
+
+document.getElementById("team-form").addEventListener("submit", (event) => {
+  event.preventDefault();
+  const team = document.getElementById("team").value.trim();
+  history.pushState(null, "", team ? "?team=" + encodeURIComponent(team) : location.pathname);
+  refresh();
+});
+window.addEventListener("popstate", refresh);
+refresh();
@@ -0,0 +1,38 @@
+package http
+
+import (
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestSetupUIRoutes(t *testing.T) {
+	mux := http.NewServeMux()
+	if err := SetupUIRoutes(mux, slog.New(slog.NewTextHandler(io.Discard, nil))); err != nil {
+		t.Fatalf("SetupUIRoutes returned err: %v", err)
+	}
+
+	rec := httptest.NewRecorder()
+	mux.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/ui", nil))
+	if rec.Code != http.StatusMovedPermanently || rec.Header().Get("Location") != "/ui/" {
+		t.Fatalf("expected redirect to /ui/, got %d %q", rec.Code, rec.Header().Get("Location"))
+	}
+
+	rec = httptest.NewRecorder()
+	mux.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/ui/", nil))
+	if rec.Code != http.StatusOK || !strings.Contains(rec.Body.String(), `<script src="app.js">`) {
+		t.Fatalf("unexpected index response %d %s", rec.Code, rec.Body.String())
+	}
+	if rec.Header().Get("Content-Security-Policy") != uiCSP {
+		t.Fatalf("expected content security policy on the dashboard")
+	}
+
+	rec = httptest.NewRecorder()
+	mux.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/ui/app.js", nil))
+	if rec.Code != http.StatusOK || !strings.HasPrefix(rec.Header().Get("Content-Type"), "text/javascript") {
+		t.Fatalf("unexpected script response %d %q", rec.Code, rec.Header().Get("Content-Type"))
+	}
+}
@@ -4,25 +4,145 @@ import (
 	"context"
 	"encoding/json"
 	"net/http"
+	"strconv"
+	"strings"
 
 	"github.com/cloudyy74/pr-reviewer-service/internal/models"
 )
 
 type UserService interface {
-	SetUserActive(context.Context, string, bool) (*models.UserResponse, error)
+	SetUserActive(ctx context.Context, userID string, isActive bool, version int64) (*models.UserResponse, error)
+	SetUserWeight(ctx context.Context, userID string, weight float64, version int64) (*models.UserResponse, error)
+	SetWorkingHours(ctx context.Context, userID string, hours models.WorkingHours, version int64) (*models.UserResponse, error)
+	GetUsersByIDs(context.Context, []string) ([]*models.UserWithTeam, error)
+	BatchGetUsers(context.Context, []string) (*models.UsersBatchGetResponse, error)
+	SetAbsence(context.Context, *models.SetAbsenceRequest) (*models.Absence, error)
+	DeleteAbsence(ctx context.Context, id int64) error
+	GetUserByUsername(context.Context, string) (*models.UserResponse, error)
+	GetNotificationSettings(context.Context, string) (*models.NotificationSettings, error)
+	SetNotificationSettings(context.Context, *models.NotificationSettings) (*models.NotificationSettings, error)
 }
 
 func (rtr *router) setUserActive(w http.ResponseWriter, r *http.Request) {
 	var req models.SetActiveRequest
 	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
-		rtr.handleError(w, newResponseError(ErrCodeBadRequest, "bad json request"))
+		rtr.handleError(w, r, newResponseError(ErrCodeBadRequest, "bad json request"))
 		return
 	}
 
-	resp, err := rtr.userService.SetUserActive(r.Context(), req.ID, req.IsActive)
+	resp, err := rtr.userService.SetUserActive(r.Context(), req.ID, req.IsActive, req.Version)
 	if err != nil {
-		rtr.handleError(w, err)
+		rtr.handleError(w, r, err)
 		return
 	}
+	rtr.mapResultErrors(resp.Reassigned)
+	rtr.mapBackfillErrors(resp.Backfilled)
 	rtr.responseJSON(w, http.StatusOK, resp)
 }
+
+func (rtr *router) setUserWeight(w http.ResponseWriter, r *http.Request) {
+	var req models.SetWeightRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		rtr.handleError(w, r, newResponseError(ErrCodeBadRequest, "bad json request"))
+		return
+	}
+
+	resp, err := rtr.userService.SetUserWeight(r.Context(), req.ID, req.Weight, req.Version)
+	if err != nil {
+		rtr.handleError(w, r, err)
+		return
+	}
+	rtr.responseJSON(w, http.StatusOK, resp)
+}
+
+func (rtr *router) setWorkingHours(w http.ResponseWriter, r *http.Request) {
+	var req models.SetWorkingHoursRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		rtr.handleError(w, r, newResponseError(ErrCodeBadRequest, "bad json request"))
+		return
+	}
+
+	resp, err := rtr.userService.SetWorkingHours(r.Context(), req.ID, req.WorkingHours, req.Version)
+	if err != nil {
+		rtr.handleError(w, r, err)
+		return
+	}
+	rtr.responseJSON(w, http.StatusOK, resp)
+}
+
+func (rtr *router) batchGetUsers(w http.ResponseWriter, r *http.Request) {
+	var req models.UsersBatchGetRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		rtr.handleError(w, r, newResponseError(ErrCodeBadRequest, "bad json request"))
+		return
+	}
+
+	resp, err := rtr.userService.BatchGetUsers(r.Context(), req.UserIDs)
+	if err != nil {
+		rtr.handleError(w, r, err)
+		return
+	}
+	rtr.responseJSON(w, http.StatusOK, resp)
+}
+
+func (rtr *router) setAbsence(w http.ResponseWriter, r *http.Request) {
+	var req models.SetAbsenceRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		rtr.handleError(w, r, newResponseError(ErrCodeBadRequest, "bad json request"))
+		return
+	}
+
+	absence, err := rtr.userService.SetAbsence(r.Context(), &req)
+	if err != nil {
+		rtr.handleError(w, r, err)
+		return
+	}
+	rtr.responseJSON(w, http.StatusCreated, &models.AbsenceResponse{Absence: *absence})
+}
+
+func (rtr *router) deleteAbsence(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.ParseInt(r.URL.Query().Get("absence_id"), 10, 64)
+	if err != nil {
+		rtr.handleError(w, r, newResponseError(ErrCodeBadRequest, "absence_id must be an integer"))
+		return
+	}
+	if err := rtr.userService.DeleteAbsence(r.Context(), id); err != nil {
+		rtr.handleError(w, r, err)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (rtr *router) getUserByUsername(w http.ResponseWriter, r *http.Request) {
+	username := strings.TrimSpace(r.URL.Query().Get("username"))
+	resp, err := rtr.userService.GetUserByUsername(r.Context(), username)
+	if err != nil {
+		rtr.handleError(w, r, err)
+		return
+	}
+	rtr.responseJSON(w, http.StatusOK, resp)
+}
+
+func (rtr *router) getNotificationSettings(w http.ResponseWriter, r *http.Request) {
+	userID := r.URL.Query().Get("user_id")
+	settings, err := rtr.userService.GetNotificationSettings(r.Context(), userID)
+	if err != nil {
+		rtr.handleError(w, r, err)
+		return
+	}
+	rtr.responseJSON(w, http.StatusOK, &models.NotificationSettingsResponse{Settings: *settings})
+}
+
+func (rtr *router) setNotificationSettings(w http.ResponseWriter, r *http.Request) {
+	var req models.NotificationSettings
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		rtr.handleError(w, r, newResponseError(ErrCodeBadRequest, "bad json request"))
+		return
+	}
+	settings, err := rtr.userService.SetNotificationSettings(r.Context(), &req)
+	if err != nil {
+		rtr.handleError(w, r, err)
+		return
+	}
+	rtr.responseJSON(w, http.StatusOK, &models.NotificationSettingsResponse{Settings: *settings})
+}
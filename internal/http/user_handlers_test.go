@@ -18,16 +18,89 @@ import (
 )
 
 type fakeUserService struct {
-	setFn func(ctx context.Context, userID string, isActive bool) (*models.UserResponse, error)
+	setFn       func(ctx context.Context, userID string, isActive bool) (*models.UserResponse, error)
+	setWeightFn func(ctx context.Context, userID string, weight float64) (*models.UserResponse, error)
+	setHoursFn  func(ctx context.Context, userID string, hours models.WorkingHours) (*models.UserResponse, error)
+	getByIDsFn  func(ctx context.Context, userIDs []string) ([]*models.UserWithTeam, error)
+	getByNameFn func(ctx context.Context, username string) (*models.UserResponse, error)
+	batchGetFn  func(ctx context.Context, userIDs []string) (*models.UsersBatchGetResponse, error)
+	absenceFn   func(ctx context.Context, req *models.SetAbsenceRequest) (*models.Absence, error)
+	deletedID   int64
+	setNotifyFn func(ctx context.Context, settings *models.NotificationSettings) (*models.NotificationSettings, error)
+	gotVersion  int64
 }
 
-func (f *fakeUserService) SetUserActive(ctx context.Context, userID string, isActive bool) (*models.UserResponse, error) {
+func (f *fakeUserService) SetUserActive(ctx context.Context, userID string, isActive bool, version int64) (*models.UserResponse, error) {
+	f.gotVersion = version
 	if f.setFn == nil {
 		return nil, errors.New("not implemented")
 	}
 	return f.setFn(ctx, userID, isActive)
 }
 
+func (f *fakeUserService) SetUserWeight(ctx context.Context, userID string, weight float64, version int64) (*models.UserResponse, error) {
+	f.gotVersion = version
+	if f.setWeightFn == nil {
+		return nil, errors.New("not implemented")
+	}
+	return f.setWeightFn(ctx, userID, weight)
+}
+
+func (f *fakeUserService) SetWorkingHours(ctx context.Context, userID string, hours models.WorkingHours, version int64) (*models.UserResponse, error) {
+	f.gotVersion = version
+	if f.setHoursFn == nil {
+		return nil, errors.New("not implemented")
+	}
+	return f.setHoursFn(ctx, userID, hours)
+}
+
+func (f *fakeUserService) GetUsersByIDs(ctx context.Context, userIDs []string) ([]*models.UserWithTeam, error) {
+	if f.getByIDsFn == nil {
+		return nil, errors.New("not implemented")
+	}
+	return f.getByIDsFn(ctx, userIDs)
+}
+
+func (f *fakeUserService) BatchGetUsers(ctx context.Context, userIDs []string) (*models.UsersBatchGetResponse, error) {
+	if f.batchGetFn == nil {
+		return nil, errors.New("not implemented")
+	}
+	return f.batchGetFn(ctx, userIDs)
+}
+
+func (f *fakeUserService) SetAbsence(ctx context.Context, req *models.SetAbsenceRequest) (*models.Absence, error) {
+	if f.absenceFn == nil {
+		return nil, errors.New("not implemented")
+	}
+	return f.absenceFn(ctx, req)
+}
+
+func (f *fakeUserService) DeleteAbsence(_ context.Context, id int64) error {
+	if id != 1 {
+		return service.ErrAbsenceNotFound
+	}
+	f.deletedID = id
+	return nil
+}
+
+func (f *fakeUserService) GetUserByUsername(ctx context.Context, username string) (*models.UserResponse, error) {
+	if f.getByNameFn == nil {
+		return nil, errors.New("not implemented")
+	}
+	return f.getByNameFn(ctx, username)
+}
+
+func (f *fakeUserService) GetNotificationSettings(_ context.Context, userID string) (*models.NotificationSettings, error) {
+	return &models.NotificationSettings{UserID: userID}, nil
+}
+
+func (f *fakeUserService) SetNotificationSettings(ctx context.Context, settings *models.NotificationSettings) (*models.NotificationSettings, error) {
+	if f.setNotifyFn == nil {
+		return nil, errors.New("not implemented")
+	}
+	return f.setNotifyFn(ctx, settings)
+}
+
 func newTestRouterWithUserService(svc UserService) *router {
 	return &router{
 		userService: svc,
@@ -194,3 +267,223 @@ func TestSetUserActive_InternalError(t *testing.T) {
 		t.Fatalf("expected message internal error, got %s", resp.Error.Message)
 	}
 }
+
+func TestGetUserByUsername_Success(t *testing.T) {
+	svc := &fakeUserService{
+		getByNameFn: func(_ context.Context, username string) (*models.UserResponse, error) {
+			if username != "alice" {
+				t.Fatalf("expected username alice, got %q", username)
+			}
+			return &models.UserResponse{User: models.UserWithTeam{User: models.User{ID: "u1", Username: "alice"}, TeamName: "backend"}}, nil
+		},
+	}
+	rtr := newTestRouterWithUserService(svc)
+
+	req := httptest.NewRequest(http.MethodGet, "/users/getByUsername?username=alice", nil)
+	rec := httptest.NewRecorder()
+
+	rtr.getUserByUsername(rec, req)
+
+	if rec.Code != http.StatusOK {
+		t.Fatalf("expected status 200, got %d", rec.Code)
+	}
+	var got models.UserResponse
+	if err := json.NewDecoder(rec.Body).Decode(&got); err != nil {
+		t.Fatalf("failed to decode response: %v", err)
+	}
+	if got.User.ID != "u1" {
+		t.Fatalf("unexpected response: %+v", got)
+	}
+}
+
+func TestGetUserByUsername_Ambiguous(t *testing.T) {
+	svc := &fakeUserService{
+		getByNameFn: func(context.Context, string) (*models.UserResponse, error) {
+			return nil, service.ErrUsernameAmbiguous
+		},
+	}
+	rtr := newTestRouterWithUserService(svc)
+
+	req := httptest.NewRequest(http.MethodGet, "/users/getByUsername?username=bob", nil)
+	rec := httptest.NewRecorder()
+
+	rtr.getUserByUsername(rec, req)
+
+	if rec.Code != http.StatusConflict {
+		t.Fatalf("expected status 409, got %d", rec.Code)
+	}
+	var resp models.ErrorResponse
+	if err := json.NewDecoder(rec.Body).Decode(&resp); err != nil {
+		t.Fatalf("failed to decode error response: %v", err)
+	}
+	if resp.Error.Code != ErrCodeAmbiguousUser {
+		t.Fatalf("expected code %s, got %s", ErrCodeAmbiguousUser, resp.Error.Code)
+	}
+}
+
+func TestSetNotificationSettings(t *testing.T) {
+	svc := &fakeUserService{
+		setNotifyFn: func(_ context.Context, settings *models.NotificationSettings) (*models.NotificationSettings, error) {
+			if settings.UserID != "u1" || settings.DigestWindowMinutes != 15 {
+				t.Fatalf("unexpected settings: %+v", settings)
+			}
+			return settings, nil
+		},
+	}
+	rtr := newTestRouterWithUserService(svc)
+
+	body := strings.NewReader(`{"user_id":"u1","digest_window_minutes":15}`)
+	req := httptest.NewRequest(http.MethodPost, "/users/setNotificationSettings", body)
+	rec := httptest.NewRecorder()
+
+	rtr.setNotificationSettings(rec, req)
+
+	if rec.Code != http.StatusOK {
+		t.Fatalf("expected status 200, got %d", rec.Code)
+	}
+	var got models.NotificationSettingsResponse
+	if err := json.NewDecoder(rec.Body).Decode(&got); err != nil {
+		t.Fatalf("failed to decode response: %v", err)
+	}
+	if got.Settings.DigestWindowMinutes != 15 {
+		t.Fatalf("unexpected response: %+v", got)
+	}
+}
+
+func TestSetUserWeight(t *testing.T) {
+	svc := &fakeUserService{
+		setWeightFn: func(_ context.Context, userID string, weight float64) (*models.UserResponse, error) {
+			if weight <= 0 {
+				return nil, fmt.Errorf("%w: weight must be greater than 0", service.ErrUserValidation)
+			}
+			return &models.UserResponse{User: models.UserWithTeam{User: models.User{ID: userID, Weight: weight}}}, nil
+		},
+	}
+	rtr := newTestRouterWithUserService(svc)
+
+	rec := httptest.NewRecorder()
+	rtr.setUserWeight(rec, httptest.NewRequest(http.MethodPost, "/users/setWeight", strings.NewReader(`{"user_id":"u1","weight":2}`)))
+	if rec.Code != http.StatusOK || !strings.Contains(rec.Body.String(), `"weight":2`) {
+		t.Fatalf("unexpected response %d %s", rec.Code, rec.Body.String())
+	}
+
+	rec = httptest.NewRecorder()
+	rtr.setUserWeight(rec, httptest.NewRequest(http.MethodPost, "/users/setWeight", strings.NewReader(`{"user_id":"u1","weight":0}`)))
+	if rec.Code != http.StatusBadRequest {
+		t.Fatalf("expected 400 for invalid weight, got %d", rec.Code)
+	}
+}
+
+func TestSetWorkingHours(t *testing.T) {
+	svc := &fakeUserService{
+		setHoursFn: func(_ context.Context, userID string, hours models.WorkingHours) (*models.UserResponse, error) {
+			if hours.Timezone == "Mars/Olympus" {
+				return nil, fmt.Errorf("%w: unknown timezone", service.ErrUserValidation)
+			}
+			return &models.UserResponse{User: models.UserWithTeam{User: models.User{ID: userID, WorkingHours: &hours}}}, nil
+		},
+	}
+	rtr := newTestRouterWithUserService(svc)
+
+	rec := httptest.NewRecorder()
+	body := `{"user_id":"u1","timezone":"Europe/Berlin","start":"09:00","end":"17:30","version":3}`
+	rtr.setWorkingHours(rec, httptest.NewRequest(http.MethodPost, "/users/setWorkingHours", strings.NewReader(body)))
+	if rec.Code != http.StatusOK || !strings.Contains(rec.Body.String(), `"working_hours":{"timezone":"Europe/Berlin","start":"09:00","end":"17:30"}`) {
+		t.Fatalf("unexpected response %d %s", rec.Code, rec.Body.String())
+	}
+	if svc.gotVersion != 3 {
+		t.Fatalf("expected version 3 to reach the service, got %d", svc.gotVersion)
+	}
+
+	rec = httptest.NewRecorder()
+	rtr.setWorkingHours(rec, httptest.NewRequest(http.MethodPost, "/users/setWorkingHours", strings.NewReader(`{"user_id":"u1","timezone":"Mars/Olympus"}`)))
+	if rec.Code != http.StatusBadRequest {
+		t.Fatalf("expected 400 for unknown timezone, got %d", rec.Code)
+	}
+}
+
+func TestSetUserActive_VersionConflict(t *testing.T) {
+	svc := &fakeUserService{
+		setFn: func(context.Context, string, bool) (*models.UserResponse, error) {
+			return nil, fmt.Errorf("%w: u1 is no longer at version 2", service.ErrUserConflict)
+		},
+	}
+	rtr := newTestRouterWithUserService(svc)
+
+	rec := httptest.NewRecorder()
+	rtr.setUserActive(rec, httptest.NewRequest(http.MethodPost, "/users/setIsActive", strings.NewReader(`{"user_id":"u1","is_active":false,"version":2}`)))
+	if rec.Code != http.StatusConflict || !strings.Contains(rec.Body.String(), ErrCodeConflict) {
+		t.Fatalf("expected 409 CONFLICT, got %d %s", rec.Code, rec.Body.String())
+	}
+	if svc.gotVersion != 2 {
+		t.Fatalf("expected version 2 to reach the service, got %d", svc.gotVersion)
+	}
+}
+
+func TestBatchGetUsers(t *testing.T) {
+	svc := &fakeUserService{
+		batchGetFn: func(_ context.Context, userIDs []string) (*models.UsersBatchGetResponse, error) {
+			if len(userIDs) == 0 {
+				return nil, fmt.Errorf("%w: user_ids is required", service.ErrUserValidation)
+			}
+			return &models.UsersBatchGetResponse{
+				Users:   []*models.UserWithTeam{{User: models.User{ID: "u1", Username: "alice", IsActive: true}, TeamName: "backend"}},
+				Missing: []string{"ghost"},
+			}, nil
+		},
+	}
+	rtr := newTestRouterWithUserService(svc)
+
+	rec := httptest.NewRecorder()
+	rtr.batchGetUsers(rec, httptest.NewRequest(http.MethodPost, "/users/batchGet", strings.NewReader(`{"user_ids":["u1","ghost"]}`)))
+	if rec.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d %s", rec.Code, rec.Body.String())
+	}
+	var resp models.UsersBatchGetResponse
+	if err := json.NewDecoder(rec.Body).Decode(&resp); err != nil {
+		t.Fatalf("decode response: %v", err)
+	}
+	if len(resp.Users) != 1 || resp.Users[0].ID != "u1" || len(resp.Missing) != 1 || resp.Missing[0] != "ghost" {
+		t.Fatalf("unexpected response %+v", resp)
+	}
+
+	rec = httptest.NewRecorder()
+	rtr.batchGetUsers(rec, httptest.NewRequest(http.MethodPost, "/users/batchGet", strings.NewReader(`{"user_ids":[]}`)))
+	if rec.Code != http.StatusBadRequest {
+		t.Fatalf("expected 400 for empty ids, got %d", rec.Code)
+	}
+}
+
+func TestSetAbsence(t *testing.T) {
+	svc := &fakeUserService{
+		absenceFn: func(_ context.Context, req *models.SetAbsenceRequest) (*models.Absence, error) {
+			return &models.Absence{ID: 1, UserID: req.UserID, StartDate: req.StartDate, EndDate: req.EndDate}, nil
+		},
+	}
+	rtr := newTestRouterWithUserService(svc)
+
+	rec := httptest.NewRecorder()
+	body := `{"user_id":"u1","start_date":"2026-12-24","end_date":"2027-01-02"}`
+	rtr.setAbsence(rec, httptest.NewRequest(http.MethodPost, "/users/setAbsence", strings.NewReader(body)))
+	if rec.Code != http.StatusCreated || !strings.Contains(rec.Body.String(), `"absence_id":1`) {
+		t.Fatalf("unexpected response %d %s", rec.Code, rec.Body.String())
+	}
+
+	rec = httptest.NewRecorder()
+	rtr.deleteAbsence(rec, httptest.NewRequest(http.MethodDelete, "/users/setAbsence?absence_id=1", nil))
+	if rec.Code != http.StatusNoContent || svc.deletedID != 1 {
+		t.Fatalf("expected 204, got %d %s", rec.Code, rec.Body.String())
+	}
+
+	rec = httptest.NewRecorder()
+	rtr.deleteAbsence(rec, httptest.NewRequest(http.MethodDelete, "/users/setAbsence?absence_id=2", nil))
+	if rec.Code != http.StatusNotFound {
+		t.Fatalf("expected 404 for unknown absence, got %d", rec.Code)
+	}
+
+	rec = httptest.NewRecorder()
+	rtr.deleteAbsence(rec, httptest.NewRequest(http.MethodDelete, "/users/setAbsence?absence_id=x", nil))
+	if rec.Code != http.StatusBadRequest {
+		t.Fatalf("expected 400 for bad id, got %d", rec.Code)
+	}
+}
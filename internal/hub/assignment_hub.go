@@ -0,0 +1,59 @@
+package hub
+
+import (
+	"sync"
+
+	"github.com/cloudyy74/pr-reviewer-service/internal/models"
+)
+
+type AssignmentHub struct {
+	mu   sync.Mutex
+	subs map[string]map[chan models.AssignmentEvent]struct{}
+}
+
+func NewAssignmentHub() *AssignmentHub {
+	return &AssignmentHub{
+		subs: make(map[string]map[chan models.AssignmentEvent]struct{}),
+	}
+}
+
+func (h *AssignmentHub) Subscribe(userID string) (<-chan models.AssignmentEvent, func()) {
+	ch := make(chan models.AssignmentEvent, 1)
+
+	h.mu.Lock()
+	if h.subs[userID] == nil {
+		h.subs[userID] = make(map[chan models.AssignmentEvent]struct{})
+	}
+	h.subs[userID][ch] = struct{}{}
+	h.mu.Unlock()
+
+	var once sync.Once
+	unsubscribe := func() {
+		once.Do(func() {
+			h.mu.Lock()
+			defer h.mu.Unlock()
+			delete(h.subs[userID], ch)
+			if len(h.subs[userID]) == 0 {
+				delete(h.subs, userID)
+			}
+		})
+	}
+	return ch, unsubscribe
+}
+
+func (h *AssignmentHub) Publish(event models.AssignmentEvent) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	for ch := range h.subs[event.UserID] {
+		select {
+		case ch <- event:
+		default:
+		}
+	}
+}
+
+func (h *AssignmentHub) Subscribers(userID string) int {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	return len(h.subs[userID])
+}
@@ -0,0 +1,59 @@
+package hub
+
+import (
+	"testing"
+	"time"
+
+	"github.com/cloudyy74/pr-reviewer-service/internal/models"
+)
+
+func TestAssignmentHub_PublishDeliversToSubscriber(t *testing.T) {
+	h := NewAssignmentHub()
+	events, unsubscribe := h.Subscribe("u1")
+	defer unsubscribe()
+
+	h.Publish(models.AssignmentEvent{UserID: "u2", PullRequestID: "pr-0"})
+	h.Publish(models.AssignmentEvent{UserID: "u1", PullRequestID: "pr-1"})
+
+	select {
+	case ev := <-events:
+		if ev.PullRequestID != "pr-1" {
+			t.Fatalf("unexpected event: %#v", ev)
+		}
+	case <-time.After(time.Second):
+		t.Fatalf("expected event to be delivered")
+	}
+}
+
+func TestAssignmentHub_Unsubscribe(t *testing.T) {
+	h := NewAssignmentHub()
+	_, unsubscribe := h.Subscribe("u1")
+	if h.Subscribers("u1") != 1 {
+		t.Fatalf("expected 1 subscriber")
+	}
+	unsubscribe()
+	unsubscribe()
+	if h.Subscribers("u1") != 0 {
+		t.Fatalf("expected no subscribers after unsubscribe")
+	}
+	h.Publish(models.AssignmentEvent{UserID: "u1"})
+}
+
+func TestAssignmentHub_PublishDoesNotBlock(t *testing.T) {
+	h := NewAssignmentHub()
+	_, unsubscribe := h.Subscribe("u1")
+	defer unsubscribe()
+
+	done := make(chan struct{})
+	go func() {
+		for range 3 {
+			h.Publish(models.AssignmentEvent{UserID: "u1"})
+		}
+		close(done)
+	}()
+	select {
+	case <-done:
+	case <-time.After(time.Second):
+		t.Fatalf("publish blocked on a slow subscriber")
+	}
+}
@@ -0,0 +1,83 @@
+// Package integration fans pull request events out to outbound connectors
+// such as Jira or chat tools. Connectors live in their own packages,
+// implement Integration and are registered per team in a Registry, which is
+// plugged into the outbox relay as its publisher.
+package integration
+
+import (
+	"context"
+
+	"github.com/cloudyy74/pr-reviewer-service/internal/models"
+)
+
+// AssignedEvent reports reviewers newly assigned to a pull request. When the
+// assignment replaces another reviewer, ReplacedID names the reviewer that
+// was taken off.
+type AssignedEvent struct {
+	TeamName    string
+	PullRequest *models.PullRequest
+	ReviewerIDs []string
+	ReplacedID  string
+}
+
+type MergedEvent struct {
+	TeamName    string
+	PullRequest *models.PullRequest
+}
+
+// EscalatedEvent reports a reviewer that did not acknowledge the assignment
+// in time and was replaced. It follows the AssignedEvent of the replacement.
+type EscalatedEvent struct {
+	TeamName      string
+	PullRequest   *models.PullRequest
+	OldReviewerID string
+	NewReviewerID string
+}
+
+// Integration is an outbound connector. Events are delivered at least once,
+// so handlers must tolerate seeing the same event again; a returned error
+// makes the outbox retry the event for every integration of the team.
+type Integration interface {
+	Name() string
+	OnAssigned(ctx context.Context, event AssignedEvent) error
+	OnMerged(ctx context.Context, event MergedEvent) error
+	OnEscalated(ctx context.Context, event EscalatedEvent) error
+}
+
+type ReviewedEvent struct {
+	TeamName    string
+	PullRequest *models.PullRequest
+	ReviewerID  string
+	State       string
+}
+
+type ClosedEvent struct {
+	TeamName    string
+	PullRequest *models.PullRequest
+}
+
+// ReviewerRemovedEvent reports a reviewer taken off a pull request without a
+// replacement.
+type ReviewerRemovedEvent struct {
+	TeamName    string
+	PullRequest *models.PullRequest
+	ReviewerID  string
+}
+
+// ReviewListener is implemented by integrations that also follow reviewer
+// verdicts.
+type ReviewListener interface {
+	OnReviewed(ctx context.Context, event ReviewedEvent) error
+}
+
+// CloseListener is implemented by integrations that also follow pull
+// requests closed without merging.
+type CloseListener interface {
+	OnClosed(ctx context.Context, event ClosedEvent) error
+}
+
+// RemoveListener is implemented by integrations that also follow reviewers
+// removed without a replacement.
+type RemoveListener interface {
+	OnReviewerRemoved(ctx context.Context, event ReviewerRemovedEvent) error
+}
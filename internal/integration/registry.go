@@ -0,0 +1,153 @@
+package integration
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"strings"
+
+	"github.com/cloudyy74/pr-reviewer-service/internal/models"
+)
+
+type UserSource interface {
+	GetUserWithTeam(ctx context.Context, userID string) (*models.UserWithTeam, error)
+}
+
+type registration struct {
+	integration Integration
+	teams       map[string]struct{}
+}
+
+// Registry holds the integrations and the teams each one is enabled for. It
+// is an outbox publisher: every pull request event is decoded once and
+// handed to the integrations enabled for the author's team.
+type Registry struct {
+	users        UserSource
+	log          *slog.Logger
+	integrations []registration
+}
+
+func NewRegistry(users UserSource, log *slog.Logger) (*Registry, error) {
+	if users == nil {
+		return nil, errors.New("user source cannot be nil")
+	}
+	if log == nil {
+		return nil, errors.New("logger cannot be nil")
+	}
+	return &Registry{users: users, log: log}, nil
+}
+
+// Register enables the integration for the given teams, or for every team
+// when none are given. Names must be unique.
+func (r *Registry) Register(in Integration, teams ...string) error {
+	if in == nil {
+		return errors.New("integration cannot be nil")
+	}
+	name := in.Name()
+	if name == "" {
+		return errors.New("integration name cannot be empty")
+	}
+	for _, reg := range r.integrations {
+		if reg.integration.Name() == name {
+			return fmt.Errorf("integration %s is registered twice", name)
+		}
+	}
+	reg := registration{integration: in}
+	if len(teams) > 0 {
+		reg.teams = make(map[string]struct{}, len(teams))
+		for _, team := range teams {
+			reg.teams[strings.ToLower(strings.TrimSpace(team))] = struct{}{}
+		}
+	}
+	r.integrations = append(r.integrations, reg)
+	return nil
+}
+
+func (r *Registry) Len() int {
+	return len(r.integrations)
+}
+
+func (r *Registry) Publish(ctx context.Context, event *models.OutboxEvent) error {
+	switch event.Topic {
+	case models.TopicPRCreated, models.TopicPRReviewersAdded, models.TopicPRReviewerReplaced,
+		models.TopicPRReviewerRemoved, models.TopicPRReviewed, models.TopicPRMerged, models.TopicPRClosed:
+	default:
+		return nil
+	}
+	var payload models.PREvent
+	if err := json.Unmarshal(event.Payload, &payload); err != nil || payload.PullRequest == nil {
+		r.log.Error("skipping malformed pull request event", slog.Int64("event_id", event.ID), slog.Any("error", err))
+		return nil
+	}
+	pr := payload.PullRequest
+
+	author, err := r.users.GetUserWithTeam(ctx, pr.AuthorID)
+	if err != nil {
+		return fmt.Errorf("get author: %w", err)
+	}
+	team := author.TeamName
+
+	var errs []error
+	for _, reg := range r.integrations {
+		if reg.teams != nil {
+			if _, ok := reg.teams[strings.ToLower(team)]; !ok {
+				continue
+			}
+		}
+		if err := deliver(ctx, reg.integration, event.Topic, team, &payload); err != nil {
+			r.log.Error("integration failed to handle event",
+				slog.String("integration", reg.integration.Name()),
+				slog.Int64("event_id", event.ID),
+				slog.String("topic", event.Topic),
+				slog.String("pr_id", pr.ID),
+				slog.Any("error", err),
+			)
+			errs = append(errs, fmt.Errorf("%s: %w", reg.integration.Name(), err))
+		}
+	}
+	return errors.Join(errs...)
+}
+
+func deliver(ctx context.Context, in Integration, topic, team string, payload *models.PREvent) error {
+	pr := payload.PullRequest
+	switch topic {
+	case models.TopicPRCreated:
+		return in.OnAssigned(ctx, AssignedEvent{TeamName: team, PullRequest: pr, ReviewerIDs: pr.Reviewers})
+	case models.TopicPRReviewersAdded:
+		return in.OnAssigned(ctx, AssignedEvent{TeamName: team, PullRequest: pr, ReviewerIDs: payload.AddedIDs})
+	case models.TopicPRReviewerReplaced:
+		assigned := AssignedEvent{TeamName: team, PullRequest: pr, ReplacedID: payload.OldReviewerID}
+		if payload.NewReviewerID != "" {
+			assigned.ReviewerIDs = []string{payload.NewReviewerID}
+		}
+		if err := in.OnAssigned(ctx, assigned); err != nil {
+			return err
+		}
+		if payload.Reason != models.EventAckTimeout {
+			return nil
+		}
+		return in.OnEscalated(ctx, EscalatedEvent{
+			TeamName:      team,
+			PullRequest:   pr,
+			OldReviewerID: payload.OldReviewerID,
+			NewReviewerID: payload.NewReviewerID,
+		})
+	case models.TopicPRReviewerRemoved:
+		if l, ok := in.(RemoveListener); ok {
+			return l.OnReviewerRemoved(ctx, ReviewerRemovedEvent{TeamName: team, PullRequest: pr, ReviewerID: payload.ReviewerID})
+		}
+	case models.TopicPRMerged:
+		return in.OnMerged(ctx, MergedEvent{TeamName: team, PullRequest: pr})
+	case models.TopicPRReviewed:
+		if l, ok := in.(ReviewListener); ok {
+			return l.OnReviewed(ctx, ReviewedEvent{TeamName: team, PullRequest: pr, ReviewerID: payload.ReviewerID, State: payload.ReviewState})
+		}
+	case models.TopicPRClosed:
+		if l, ok := in.(CloseListener); ok {
+			return l.OnClosed(ctx, ClosedEvent{TeamName: team, PullRequest: pr})
+		}
+	}
+	return nil
+}
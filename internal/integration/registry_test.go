@@ -0,0 +1,144 @@
+package integration
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"log/slog"
+	"slices"
+	"testing"
+
+	"github.com/cloudyy74/pr-reviewer-service/internal/models"
+)
+
+type teamUsers map[string]string
+
+func (u teamUsers) GetUserWithTeam(_ context.Context, userID string) (*models.UserWithTeam, error) {
+	return &models.UserWithTeam{User: models.User{ID: userID}, TeamName: u[userID]}, nil
+}
+
+type recorder struct {
+	name  string
+	calls []string
+	err   error
+}
+
+func (r *recorder) Name() string { return r.name }
+
+func (r *recorder) OnAssigned(_ context.Context, e AssignedEvent) error {
+	r.calls = append(r.calls, "assigned:"+e.TeamName+":"+e.ReplacedID+">"+e.ReviewerIDs[0])
+	return r.err
+}
+
+func (r *recorder) OnMerged(_ context.Context, e MergedEvent) error {
+	r.calls = append(r.calls, "merged:"+e.PullRequest.ID)
+	return r.err
+}
+
+func (r *recorder) OnEscalated(_ context.Context, e EscalatedEvent) error {
+	r.calls = append(r.calls, "escalated:"+e.OldReviewerID+">"+e.NewReviewerID)
+	return r.err
+}
+
+func outboxEvent(t *testing.T, topic string, payload models.PREvent) *models.OutboxEvent {
+	t.Helper()
+	raw, err := json.Marshal(payload)
+	if err != nil {
+		t.Fatalf("marshal payload: %v", err)
+	}
+	return &models.OutboxEvent{ID: 1, Topic: topic, AggregateID: payload.PullRequest.ID, Payload: raw}
+}
+
+func newTestRegistry(t *testing.T) *Registry {
+	t.Helper()
+	r, err := NewRegistry(teamUsers{"author": "Payments", "outsider": "mobile"}, slog.New(slog.NewTextHandler(io.Discard, nil)))
+	if err != nil {
+		t.Fatalf("NewRegistry returned err: %v", err)
+	}
+	return r
+}
+
+func TestRegistry_DispatchesToTeamIntegrations(t *testing.T) {
+	r := newTestRegistry(t)
+	scoped := &recorder{name: "scoped"}
+	global := &recorder{name: "global"}
+	if err := r.Register(scoped, "payments"); err != nil {
+		t.Fatalf("Register returned err: %v", err)
+	}
+	if err := r.Register(global); err != nil {
+		t.Fatalf("Register returned err: %v", err)
+	}
+	if err := r.Register(&recorder{name: "scoped"}); err == nil {
+		t.Fatalf("expected duplicate name to be rejected")
+	}
+	ctx := context.Background()
+	pr := &models.PullRequest{ID: "pr1", AuthorID: "author", Reviewers: []string{"u1"}}
+
+	events := []*models.OutboxEvent{
+		outboxEvent(t, models.TopicPRCreated, models.PREvent{PullRequest: pr}),
+		outboxEvent(t, models.TopicPRReviewerReplaced, models.PREvent{PullRequest: pr, OldReviewerID: "u1", NewReviewerID: "u2", Reason: models.EventAckTimeout}),
+		outboxEvent(t, models.TopicPRReviewed, models.PREvent{PullRequest: pr, ReviewerID: "u2"}),
+		outboxEvent(t, models.TopicPRMerged, models.PREvent{PullRequest: pr}),
+		outboxEvent(t, models.TopicPRCreated, models.PREvent{PullRequest: &models.PullRequest{ID: "pr2", AuthorID: "outsider", Reviewers: []string{"u9"}}}),
+	}
+	for _, e := range events {
+		if err := r.Publish(ctx, e); err != nil {
+			t.Fatalf("Publish %s returned err: %v", e.Topic, err)
+		}
+	}
+
+	want := []string{"assigned:Payments:>u1", "assigned:Payments:u1>u2", "escalated:u1>u2", "merged:pr1"}
+	if !slices.Equal(scoped.calls, want) {
+		t.Fatalf("expected scoped calls %v, got %v", want, scoped.calls)
+	}
+	if len(global.calls) != len(want)+1 {
+		t.Fatalf("expected global integration to see every team, got %v", global.calls)
+	}
+}
+
+type removeRecorder struct {
+	recorder
+}
+
+func (r *removeRecorder) OnReviewerRemoved(_ context.Context, e ReviewerRemovedEvent) error {
+	r.calls = append(r.calls, "removed:"+e.TeamName+":"+e.ReviewerID)
+	return r.err
+}
+
+func TestRegistry_DeliversReviewerRemoval(t *testing.T) {
+	r := newTestRegistry(t)
+	plain := &recorder{name: "plain"}
+	listener := &removeRecorder{recorder: recorder{name: "listener"}}
+	r.Register(plain)
+	r.Register(listener)
+
+	pr := &models.PullRequest{ID: "pr1", AuthorID: "author", Reviewers: []string{"u2"}}
+	event := outboxEvent(t, models.TopicPRReviewerRemoved, models.PREvent{PullRequest: pr, ReviewerID: "u1"})
+	if err := r.Publish(context.Background(), event); err != nil {
+		t.Fatalf("Publish returned err: %v", err)
+	}
+	if len(plain.calls) != 0 {
+		t.Fatalf("expected a removal not to be reported as an assignment, got %v", plain.calls)
+	}
+	if want := []string{"removed:Payments:u1"}; !slices.Equal(listener.calls, want) {
+		t.Fatalf("expected calls %v, got %v", want, listener.calls)
+	}
+}
+
+func TestRegistry_ReturnsFailuresForRetry(t *testing.T) {
+	r := newTestRegistry(t)
+	failing := &recorder{name: "failing", err: errors.New("unavailable")}
+	healthy := &recorder{name: "healthy"}
+	r.Register(failing)
+	r.Register(healthy)
+
+	pr := &models.PullRequest{ID: "pr1", AuthorID: "author"}
+	err := r.Publish(context.Background(), outboxEvent(t, models.TopicPRMerged, models.PREvent{PullRequest: pr}))
+	if err == nil {
+		t.Fatalf("expected failure to be returned")
+	}
+	if len(healthy.calls) != 1 {
+		t.Fatalf("expected the other integration to still be called, got %v", healthy.calls)
+	}
+}
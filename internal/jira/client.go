@@ -0,0 +1,127 @@
+package jira
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+)
+
+// errPermanent marks a Jira response that will not change on retry, such as
+// a rejected field or a missing project.
+var errPermanent = errors.New("jira rejected the request")
+
+// client is a minimal Jira REST v2 client. With a user it authenticates with
+// basic auth (Jira Cloud e-mail and API token), otherwise the token is sent
+// as a bearer personal access token (Jira Server and Data Center).
+type client struct {
+	baseURL string
+	user    string
+	token   string
+	http    *http.Client
+}
+
+type issueFields struct {
+	Project     keyRef  `json:"project"`
+	Parent      keyRef  `json:"parent"`
+	IssueType   nameRef `json:"issuetype"`
+	Summary     string  `json:"summary"`
+	Description string  `json:"description,omitempty"`
+}
+
+type keyRef struct {
+	Key string `json:"key"`
+}
+
+type nameRef struct {
+	Name string `json:"name"`
+}
+
+type transition struct {
+	ID   string `json:"id"`
+	Name string `json:"name"`
+}
+
+func (c *client) createIssue(ctx context.Context, fields issueFields) (string, error) {
+	var created struct {
+		Key string `json:"key"`
+	}
+	if err := c.do(ctx, http.MethodPost, "/rest/api/2/issue", map[string]any{"fields": fields}, &created); err != nil {
+		return "", fmt.Errorf("create issue: %w", err)
+	}
+	if created.Key == "" {
+		return "", errors.New("create issue: response has no key")
+	}
+	return created.Key, nil
+}
+
+// transition moves the issue through the transition with the given name. It
+// reports false when the issue has no such transition, which is the case once
+// it is already in the target status.
+func (c *client) transition(ctx context.Context, issueKey, name string) (bool, error) {
+	path := "/rest/api/2/issue/" + url.PathEscape(issueKey) + "/transitions"
+	var available struct {
+		Transitions []transition `json:"transitions"`
+	}
+	if err := c.do(ctx, http.MethodGet, path, nil, &available); err != nil {
+		return false, fmt.Errorf("get transitions of %s: %w", issueKey, err)
+	}
+	for _, t := range available.Transitions {
+		if strings.EqualFold(t.Name, name) {
+			body := map[string]any{"transition": map[string]string{"id": t.ID}}
+			if err := c.do(ctx, http.MethodPost, path, body, nil); err != nil {
+				return false, fmt.Errorf("transition %s: %w", issueKey, err)
+			}
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+func (c *client) do(ctx context.Context, method, path string, body, dst any) error {
+	var reader io.Reader
+	if body != nil {
+		raw, err := json.Marshal(body)
+		if err != nil {
+			return err
+		}
+		reader = bytes.NewReader(raw)
+	}
+	req, err := http.NewRequestWithContext(ctx, method, c.baseURL+path, reader)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Accept", "application/json")
+	if body != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+	if c.user != "" {
+		req.SetBasicAuth(c.user, c.token)
+	} else {
+		req.Header.Set("Authorization", "Bearer "+c.token)
+	}
+
+	resp, err := c.http.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		detail, _ := io.ReadAll(io.LimitReader(resp.Body, 512))
+		err := fmt.Errorf("unexpected status %d from %s %s: %s", resp.StatusCode, method, path, strings.TrimSpace(string(detail)))
+		if resp.StatusCode >= 400 && resp.StatusCode < 500 &&
+			resp.StatusCode != http.StatusRequestTimeout && resp.StatusCode != http.StatusTooManyRequests {
+			return fmt.Errorf("%w: %w", errPermanent, err)
+		}
+		return err
+	}
+	if dst == nil || resp.StatusCode == http.StatusNoContent {
+		return nil
+	}
+	return json.NewDecoder(resp.Body).Decode(dst)
+}
@@ -0,0 +1,288 @@
+package jira
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"regexp"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/cloudyy74/pr-reviewer-service/internal/integration"
+	"github.com/cloudyy74/pr-reviewer-service/internal/models"
+)
+
+const (
+	defaultIssueType      = "Sub-task"
+	defaultDoneTransition = "Done"
+)
+
+// issueKeyRe finds a Jira issue key such as PAY-42 in a pull request title.
+var issueKeyRe = regexp.MustCompile(`\b[A-Z][A-Z0-9_]+-[0-9]+\b`)
+
+// Team maps the pull requests of one team to a Jira project. Sub-tasks are
+// created under the issue named in the pull request title, or under
+// ParentIssue when the title names none.
+type Team struct {
+	TeamName         string
+	ProjectKey       string
+	ParentIssue      string
+	IssueType        string
+	DoneTransition   string
+	CancelTransition string
+}
+
+type Config struct {
+	BaseURL string
+	User    string
+	Token   string
+	Teams   []Team
+}
+
+type TaskStore interface {
+	GetOpenJiraTasks(ctx context.Context, prID string) ([]*models.JiraTask, error)
+	AddJiraTask(ctx context.Context, task *models.JiraTask) error
+	MarkJiraTaskDone(ctx context.Context, issueKey string, at time.Time) error
+}
+
+// Connector mirrors reviewer assignments into Jira as an integration: a
+// sub-task is created for every assignment, and transitioned when the
+// reviewer submits a verdict, when the pull request is merged or closed, or
+// when the reviewer is replaced. Failed calls are returned so the outbox
+// retries them with backoff; requests Jira rejects outright are logged and
+// dropped instead of being retried forever.
+type Connector struct {
+	client *client
+	teams  map[string]Team
+	tasks  TaskStore
+	log    *slog.Logger
+	now    func() time.Time
+}
+
+func NewConnector(cfg Config, tasks TaskStore, httpClient *http.Client, log *slog.Logger) (*Connector, error) {
+	baseURL := strings.TrimSuffix(strings.TrimSpace(cfg.BaseURL), "/")
+	if baseURL == "" {
+		return nil, errors.New("jira base url cannot be empty")
+	}
+	if cfg.Token == "" {
+		return nil, errors.New("jira token cannot be empty")
+	}
+	if tasks == nil {
+		return nil, errors.New("task store cannot be nil")
+	}
+	if log == nil {
+		return nil, errors.New("logger cannot be nil")
+	}
+	if httpClient == nil {
+		httpClient = &http.Client{Timeout: 10 * time.Second}
+	}
+
+	teams := make(map[string]Team, len(cfg.Teams))
+	for _, t := range cfg.Teams {
+		t.TeamName = strings.TrimSpace(t.TeamName)
+		t.ProjectKey = strings.TrimSpace(t.ProjectKey)
+		if t.TeamName == "" || t.ProjectKey == "" {
+			return nil, errors.New("jira team mapping needs team_name and project_key")
+		}
+		key := strings.ToLower(t.TeamName)
+		if _, dup := teams[key]; dup {
+			return nil, fmt.Errorf("jira team %s is mapped twice", t.TeamName)
+		}
+		if t.IssueType == "" {
+			t.IssueType = defaultIssueType
+		}
+		if t.DoneTransition == "" {
+			t.DoneTransition = defaultDoneTransition
+		}
+		teams[key] = t
+	}
+
+	return &Connector{
+		client: &client{baseURL: baseURL, user: cfg.User, token: cfg.Token, http: httpClient},
+		teams:  teams,
+		tasks:  tasks,
+		log:    log,
+		now:    time.Now,
+	}, nil
+}
+
+func (c *Connector) Name() string {
+	return "jira"
+}
+
+// TeamNames lists the mapped teams, which are the teams to register the
+// connector for.
+func (c *Connector) TeamNames() []string {
+	names := make([]string, 0, len(c.teams))
+	for _, t := range c.teams {
+		names = append(names, t.TeamName)
+	}
+	return names
+}
+
+func (c *Connector) OnAssigned(ctx context.Context, event integration.AssignedEvent) error {
+	team, ok := c.team(event.TeamName)
+	if !ok {
+		return nil
+	}
+	var err error
+	if event.ReplacedID != "" {
+		err = c.finishTasks(ctx, event.PullRequest.ID, event.ReplacedID, team.CancelTransition)
+	}
+	if err == nil {
+		err = c.createTasks(ctx, team, event.PullRequest, event.ReviewerIDs)
+	}
+	return c.settle(err, "assigned", event.PullRequest.ID)
+}
+
+func (c *Connector) OnReviewed(ctx context.Context, event integration.ReviewedEvent) error {
+	team, ok := c.team(event.TeamName)
+	if !ok {
+		return nil
+	}
+	err := c.finishTasks(ctx, event.PullRequest.ID, event.ReviewerID, team.DoneTransition)
+	return c.settle(err, "reviewed", event.PullRequest.ID)
+}
+
+func (c *Connector) OnMerged(ctx context.Context, event integration.MergedEvent) error {
+	team, ok := c.team(event.TeamName)
+	if !ok {
+		return nil
+	}
+	err := c.finishTasks(ctx, event.PullRequest.ID, "", team.DoneTransition)
+	return c.settle(err, "merged", event.PullRequest.ID)
+}
+
+func (c *Connector) OnClosed(ctx context.Context, event integration.ClosedEvent) error {
+	team, ok := c.team(event.TeamName)
+	if !ok {
+		return nil
+	}
+	err := c.finishTasks(ctx, event.PullRequest.ID, "", team.CancelTransition)
+	return c.settle(err, "closed", event.PullRequest.ID)
+}
+
+func (c *Connector) OnReviewerRemoved(ctx context.Context, event integration.ReviewerRemovedEvent) error {
+	team, ok := c.team(event.TeamName)
+	if !ok {
+		return nil
+	}
+	err := c.finishTasks(ctx, event.PullRequest.ID, event.ReviewerID, team.CancelTransition)
+	return c.settle(err, "removed", event.PullRequest.ID)
+}
+
+// OnEscalated does nothing: the sub-task of the reviewer who timed out is
+// already cancelled by the replacement assignment.
+func (c *Connector) OnEscalated(context.Context, integration.EscalatedEvent) error {
+	return nil
+}
+
+func (c *Connector) team(name string) (Team, bool) {
+	team, ok := c.teams[strings.ToLower(name)]
+	return team, ok
+}
+
+// settle drops errors Jira will keep returning, so the outbox does not retry
+// them.
+func (c *Connector) settle(err error, event, prID string) error {
+	if errors.Is(err, errPermanent) {
+		c.log.Error("jira rejected review task update, not retrying",
+			slog.String("event", event),
+			slog.String("pr_id", prID),
+			slog.Any("error", err),
+		)
+		return nil
+	}
+	return err
+}
+
+// createTasks creates a sub-task for every reviewer that has no open one
+// yet, so a retried event only creates the missing ones.
+func (c *Connector) createTasks(ctx context.Context, team Team, pr *models.PullRequest, reviewerIDs []string) error {
+	if len(reviewerIDs) == 0 {
+		return nil
+	}
+	parent := issueKeyRe.FindString(pr.Title)
+	if parent == "" {
+		parent = team.ParentIssue
+	}
+	if parent == "" {
+		c.log.Warn("no parent issue for jira review tasks",
+			slog.String("pr_id", pr.ID),
+			slog.String("team", team.TeamName),
+		)
+		return nil
+	}
+
+	open, err := c.tasks.GetOpenJiraTasks(ctx, pr.ID)
+	if err != nil {
+		return err
+	}
+	for _, reviewerID := range reviewerIDs {
+		if slices.ContainsFunc(open, func(t *models.JiraTask) bool { return t.UserID == reviewerID }) {
+			continue
+		}
+		key, err := c.client.createIssue(ctx, issueFields{
+			Project:     keyRef{Key: team.ProjectKey},
+			Parent:      keyRef{Key: parent},
+			IssueType:   nameRef{Name: team.IssueType},
+			Summary:     fmt.Sprintf("Review %s by %s", pr.Title, reviewerName(pr, reviewerID)),
+			Description: fmt.Sprintf("Pull request %s by %s is assigned to %s for review.", pr.ID, pr.AuthorID, reviewerID),
+		})
+		if err != nil {
+			return err
+		}
+		if err := c.tasks.AddJiraTask(ctx, &models.JiraTask{IssueKey: key, PullRequestID: pr.ID, UserID: reviewerID}); err != nil {
+			return err
+		}
+		c.log.Info("jira review task created",
+			slog.String("issue_key", key),
+			slog.String("pr_id", pr.ID),
+			slog.String("user_id", reviewerID),
+		)
+	}
+	return nil
+}
+
+// finishTasks applies the transition to the open sub-tasks of the pull
+// request, or of one reviewer when userID is set. Without a transition the
+// sub-tasks are only forgotten and stay as they are in Jira.
+func (c *Connector) finishTasks(ctx context.Context, prID, userID, transitionName string) error {
+	open, err := c.tasks.GetOpenJiraTasks(ctx, prID)
+	if err != nil {
+		return err
+	}
+	for _, task := range open {
+		if userID != "" && task.UserID != userID {
+			continue
+		}
+		if transitionName != "" {
+			moved, err := c.client.transition(ctx, task.IssueKey, transitionName)
+			if err != nil {
+				return err
+			}
+			if !moved {
+				c.log.Info("jira review task has no such transition, leaving it as is",
+					slog.String("issue_key", task.IssueKey),
+					slog.String("transition", transitionName),
+				)
+			}
+		}
+		if err := c.tasks.MarkJiraTaskDone(ctx, task.IssueKey, c.now()); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func reviewerName(pr *models.PullRequest, userID string) string {
+	for _, d := range pr.ReviewerDetails {
+		if d.UserID == userID && d.Username != "" {
+			return d.Username
+		}
+	}
+	return userID
+}
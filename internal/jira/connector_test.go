@@ -0,0 +1,218 @@
+package jira
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"slices"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/cloudyy74/pr-reviewer-service/internal/integration"
+	"github.com/cloudyy74/pr-reviewer-service/internal/models"
+)
+
+type memoryTasks struct {
+	mu    sync.Mutex
+	tasks []*models.JiraTask
+}
+
+func (m *memoryTasks) GetOpenJiraTasks(_ context.Context, prID string) ([]*models.JiraTask, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	var open []*models.JiraTask
+	for _, t := range m.tasks {
+		if t.PullRequestID == prID && t.DoneAt == nil {
+			open = append(open, t)
+		}
+	}
+	return open, nil
+}
+
+func (m *memoryTasks) AddJiraTask(_ context.Context, task *models.JiraTask) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.tasks = append(m.tasks, task)
+	return nil
+}
+
+func (m *memoryTasks) MarkJiraTaskDone(_ context.Context, issueKey string, at time.Time) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	for _, t := range m.tasks {
+		if t.IssueKey == issueKey {
+			t.DoneAt = &at
+		}
+	}
+	return nil
+}
+
+type teamUsers map[string]string
+
+func (u teamUsers) GetUserWithTeam(_ context.Context, userID string) (*models.UserWithTeam, error) {
+	return &models.UserWithTeam{User: models.User{ID: userID}, TeamName: u[userID]}, nil
+}
+
+type fakeJira struct {
+	server      *httptest.Server
+	created     []issueFields
+	transitions []string
+	createCode  int
+}
+
+func newFakeJira(t *testing.T) *fakeJira {
+	t.Helper()
+	f := &fakeJira{createCode: http.StatusCreated}
+	mux := http.NewServeMux()
+	mux.HandleFunc("POST /rest/api/2/issue", func(w http.ResponseWriter, r *http.Request) {
+		if user, pass, ok := r.BasicAuth(); !ok || user != "bot@example.com" || pass != "secret" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		if f.createCode != http.StatusCreated {
+			w.WriteHeader(f.createCode)
+			return
+		}
+		var body struct {
+			Fields issueFields `json:"fields"`
+		}
+		json.NewDecoder(r.Body).Decode(&body)
+		f.created = append(f.created, body.Fields)
+		w.WriteHeader(http.StatusCreated)
+		json.NewEncoder(w).Encode(map[string]string{"key": "PAY-" + string(rune('0'+len(f.created)))})
+	})
+	mux.HandleFunc("GET /rest/api/2/issue/{key}/transitions", func(w http.ResponseWriter, r *http.Request) {
+		json.NewEncoder(w).Encode(map[string]any{"transitions": []transition{{ID: "31", Name: "Done"}, {ID: "41", Name: "Won't Do"}}})
+	})
+	mux.HandleFunc("POST /rest/api/2/issue/{key}/transitions", func(w http.ResponseWriter, r *http.Request) {
+		var body struct {
+			Transition struct {
+				ID string `json:"id"`
+			} `json:"transition"`
+		}
+		json.NewDecoder(r.Body).Decode(&body)
+		f.transitions = append(f.transitions, r.PathValue("key")+":"+body.Transition.ID)
+		w.WriteHeader(http.StatusNoContent)
+	})
+	f.server = httptest.NewServer(mux)
+	t.Cleanup(f.server.Close)
+	return f
+}
+
+// newTestConnector registers the connector in an integration registry, so
+// the tests go through the same dispatch as the outbox relay.
+func newTestConnector(t *testing.T, f *fakeJira, tasks *memoryTasks) *integration.Registry {
+	t.Helper()
+	log := slog.New(slog.NewTextHandler(io.Discard, nil))
+	c, err := NewConnector(
+		Config{
+			BaseURL: f.server.URL + "/",
+			User:    "bot@example.com",
+			Token:   "secret",
+			Teams:   []Team{{TeamName: "Payments", ProjectKey: "PAY", ParentIssue: "PAY-1", CancelTransition: "Won't Do"}},
+		},
+		tasks,
+		nil,
+		log,
+	)
+	if err != nil {
+		t.Fatalf("NewConnector returned err: %v", err)
+	}
+	registry, err := integration.NewRegistry(teamUsers{"author": "payments", "outsider": "mobile"}, log)
+	if err != nil {
+		t.Fatalf("NewRegistry returned err: %v", err)
+	}
+	if err := registry.Register(c, c.TeamNames()...); err != nil {
+		t.Fatalf("Register returned err: %v", err)
+	}
+	return registry
+}
+
+func prEvent(t *testing.T, topic string, payload models.PREvent) *models.OutboxEvent {
+	t.Helper()
+	raw, err := json.Marshal(payload)
+	if err != nil {
+		t.Fatalf("marshal payload: %v", err)
+	}
+	return &models.OutboxEvent{ID: 1, Topic: topic, AggregateID: payload.PullRequest.ID, Payload: raw}
+}
+
+func TestConnector_Lifecycle(t *testing.T) {
+	f := newFakeJira(t)
+	tasks := &memoryTasks{}
+	c := newTestConnector(t, f, tasks)
+	ctx := context.Background()
+	pr := &models.PullRequest{
+		ID:              "pr1",
+		Title:           "PAY-77 refund flow",
+		AuthorID:        "author",
+		Reviewers:       []string{"u1", "u2"},
+		ReviewerDetails: []*models.ReviewerDetail{{UserID: "u1", Username: "alice"}, {UserID: "u2", Username: "bob"}},
+	}
+
+	created := prEvent(t, models.TopicPRCreated, models.PREvent{PullRequest: pr})
+	if err := c.Publish(ctx, created); err != nil {
+		t.Fatalf("Publish created returned err: %v", err)
+	}
+	if err := c.Publish(ctx, created); err != nil {
+		t.Fatalf("retried Publish returned err: %v", err)
+	}
+	if len(f.created) != 2 {
+		t.Fatalf("expected one sub-task per reviewer, got %d", len(f.created))
+	}
+	if got := f.created[0]; got.Parent.Key != "PAY-77" || got.Project.Key != "PAY" || got.IssueType.Name != defaultIssueType || got.Summary != "Review PAY-77 refund flow by alice" {
+		t.Fatalf("unexpected sub-task: %+v", got)
+	}
+
+	replaced := prEvent(t, models.TopicPRReviewerReplaced, models.PREvent{PullRequest: pr, OldReviewerID: "u2", NewReviewerID: "u3"})
+	if err := c.Publish(ctx, replaced); err != nil {
+		t.Fatalf("Publish replaced returned err: %v", err)
+	}
+	reviewed := prEvent(t, models.TopicPRReviewed, models.PREvent{PullRequest: pr, ReviewerID: "u1", ReviewState: models.ReviewStateApproved})
+	if err := c.Publish(ctx, reviewed); err != nil {
+		t.Fatalf("Publish reviewed returned err: %v", err)
+	}
+	removed := prEvent(t, models.TopicPRReviewerRemoved, models.PREvent{PullRequest: pr, ReviewerID: "u3"})
+	if err := c.Publish(ctx, removed); err != nil {
+		t.Fatalf("Publish removed returned err: %v", err)
+	}
+	if err := c.Publish(ctx, prEvent(t, models.TopicPRMerged, models.PREvent{PullRequest: pr})); err != nil {
+		t.Fatalf("Publish merged returned err: %v", err)
+	}
+
+	want := []string{"PAY-2:41", "PAY-1:31", "PAY-3:41"}
+	if !slices.Equal(f.transitions, want) {
+		t.Fatalf("expected transitions %v, got %v", want, f.transitions)
+	}
+	if open, _ := tasks.GetOpenJiraTasks(ctx, "pr1"); len(open) != 0 {
+		t.Fatalf("expected no open tasks, got %d", len(open))
+	}
+}
+
+func TestConnector_SkipsUnmappedTeamsAndDropsRejectedRequests(t *testing.T) {
+	f := newFakeJira(t)
+	c := newTestConnector(t, f, &memoryTasks{})
+	ctx := context.Background()
+
+	unmapped := &models.PullRequest{ID: "pr2", Title: "MOB-1 fix", AuthorID: "outsider", Reviewers: []string{"u1"}}
+	if err := c.Publish(ctx, prEvent(t, models.TopicPRCreated, models.PREvent{PullRequest: unmapped})); err != nil {
+		t.Fatalf("Publish returned err: %v", err)
+	}
+	if len(f.created) != 0 {
+		t.Fatalf("expected no sub-task for an unmapped team")
+	}
+
+	pr := &models.PullRequest{ID: "pr3", Title: "no key", AuthorID: "author", Reviewers: []string{"u1"}}
+	f.createCode = http.StatusBadRequest
+	if err := c.Publish(ctx, prEvent(t, models.TopicPRCreated, models.PREvent{PullRequest: pr})); err != nil {
+		t.Fatalf("expected rejected request to be dropped, got %v", err)
+	}
+	f.createCode = http.StatusServiceUnavailable
+	if err := c.Publish(ctx, prEvent(t, models.TopicPRCreated, models.PREvent{PullRequest: pr})); err == nil {
+		t.Fatalf("expected unavailable jira to be retried")
+	}
+}
@@ -0,0 +1,165 @@
+// Package metrics exposes counters in the Prometheus text exposition format
+// without pulling in the client library.
+package metrics
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"net/http"
+	"slices"
+	"strings"
+	"sync"
+)
+
+const contentType = "text/plain; version=0.0.4; charset=utf-8"
+
+// Default is the registry served on GET /metrics.
+var Default = NewRegistry()
+
+type collector interface {
+	write(w *bufio.Writer)
+	gather() []Sample
+}
+
+// Sample is the current value of one series.
+type Sample struct {
+	Name   string
+	Labels map[string]string
+	Value  float64
+}
+
+type Registry struct {
+	mu         sync.Mutex
+	collectors []collector
+}
+
+func NewRegistry() *Registry {
+	return &Registry{}
+}
+
+func (r *Registry) Register(c *CounterVec) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.collectors = append(r.collectors, c)
+}
+
+// Write writes every registered metric in registration order.
+func (r *Registry) Write(w io.Writer) error {
+	r.mu.Lock()
+	collectors := slices.Clone(r.collectors)
+	r.mu.Unlock()
+
+	bw := bufio.NewWriter(w)
+	for _, c := range collectors {
+		c.write(bw)
+	}
+	return bw.Flush()
+}
+
+// Gather returns the current value of every series, for evaluating rules in
+// process.
+func (r *Registry) Gather() []Sample {
+	r.mu.Lock()
+	collectors := slices.Clone(r.collectors)
+	r.mu.Unlock()
+
+	var samples []Sample
+	for _, c := range collectors {
+		samples = append(samples, c.gather()...)
+	}
+	return samples
+}
+
+func (r *Registry) Handler() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		w.Header().Set("Content-Type", contentType)
+		_ = r.Write(w)
+	})
+}
+
+// CounterVec is a counter partitioned by a fixed set of labels.
+type CounterVec struct {
+	name   string
+	help   string
+	labels []string
+
+	mu     sync.Mutex
+	values map[string]uint64
+	keys   map[string][]string
+}
+
+func NewCounterVec(name, help string, labels ...string) *CounterVec {
+	return &CounterVec{
+		name:   name,
+		help:   help,
+		labels: labels,
+		values: make(map[string]uint64),
+		keys:   make(map[string][]string),
+	}
+}
+
+// Inc adds one to the series identified by labelValues, given in the order
+// the labels were declared.
+func (c *CounterVec) Inc(labelValues ...string) {
+	if len(labelValues) != len(c.labels) {
+		panic(fmt.Sprintf("metrics: %s expects %d label values, got %d", c.name, len(c.labels), len(labelValues)))
+	}
+	key := strings.Join(labelValues, "\xff")
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if _, ok := c.keys[key]; !ok {
+		c.keys[key] = slices.Clone(labelValues)
+	}
+	c.values[key]++
+}
+
+func (c *CounterVec) Value(labelValues ...string) uint64 {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.values[strings.Join(labelValues, "\xff")]
+}
+
+func (c *CounterVec) write(w *bufio.Writer) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	fmt.Fprintf(w, "# HELP %s %s\n# TYPE %s counter\n", c.name, c.help, c.name)
+	keys := make([]string, 0, len(c.keys))
+	for key := range c.keys {
+		keys = append(keys, key)
+	}
+	slices.Sort(keys)
+	for _, key := range keys {
+		w.WriteString(c.name)
+		w.WriteByte('{')
+		for i, label := range c.labels {
+			if i > 0 {
+				w.WriteByte(',')
+			}
+			fmt.Fprintf(w, "%s=\"%s\"", label, escapeLabel(c.keys[key][i]))
+		}
+		fmt.Fprintf(w, "} %d\n", c.values[key])
+	}
+}
+
+func (c *CounterVec) gather() []Sample {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	samples := make([]Sample, 0, len(c.keys))
+	for key, values := range c.keys {
+		labels := make(map[string]string, len(c.labels))
+		for i, label := range c.labels {
+			labels[label] = values[i]
+		}
+		samples = append(samples, Sample{Name: c.name, Labels: labels, Value: float64(c.values[key])})
+	}
+	return samples
+}
+
+var labelEscaper = strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`)
+
+func escapeLabel(v string) string {
+	return labelEscaper.Replace(v)
+}
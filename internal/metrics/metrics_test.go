@@ -0,0 +1,44 @@
+package metrics
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestRegistry_WritesCounters(t *testing.T) {
+	reg := NewRegistry()
+	c := NewCounterVec("errors_total", "Errors by code.", "code", "status")
+	reg.Register(c)
+	c.Inc("NO_CANDIDATE", "409")
+	c.Inc("NO_CANDIDATE", "409")
+	c.Inc(`BAD"CODE`, "400")
+
+	rec := httptest.NewRecorder()
+	reg.Handler().ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/metrics", nil))
+
+	want := `# HELP errors_total Errors by code.
+# TYPE errors_total counter
+errors_total{code="BAD\"CODE",status="400"} 1
+errors_total{code="NO_CANDIDATE",status="409"} 2
+`
+	if got := rec.Body.String(); got != want {
+		t.Fatalf("unexpected exposition:\n%s", got)
+	}
+	if c.Value("NO_CANDIDATE", "409") != 2 {
+		t.Fatalf("unexpected value %d", c.Value("NO_CANDIDATE", "409"))
+	}
+
+	samples := reg.Gather()
+	if len(samples) != 2 {
+		t.Fatalf("expected 2 samples, got %d", len(samples))
+	}
+	for _, s := range samples {
+		if s.Name != "errors_total" || s.Labels["status"] == "" {
+			t.Fatalf("unexpected sample %+v", s)
+		}
+		if s.Labels["code"] == "NO_CANDIDATE" && s.Value != 2 {
+			t.Fatalf("unexpected NO_CANDIDATE sample %+v", s)
+		}
+	}
+}
@@ -0,0 +1,31 @@
+package models
+
+import "time"
+
+const (
+	AlertPending  = "PENDING"
+	AlertFiring   = "FIRING"
+	AlertResolved = "RESOLVED"
+)
+
+// Alert is the state of one alert rule for one matching series.
+type Alert struct {
+	Rule       string            `json:"rule"`
+	Severity   string            `json:"severity,omitempty"`
+	State      string            `json:"state"`
+	Expr       string            `json:"expr"`
+	Labels     map[string]string `json:"labels,omitempty"`
+	Value      float64           `json:"value"`
+	Threshold  float64           `json:"threshold"`
+	Since      time.Time         `json:"since"`
+	ResolvedAt *time.Time        `json:"resolved_at,omitempty"`
+}
+
+type AlertNotification struct {
+	UserID string `json:"user_id"`
+	Alert  Alert  `json:"alert"`
+}
+
+type AlertsResponse struct {
+	Alerts []*Alert `json:"alerts"`
+}
@@ -0,0 +1,9 @@
+package models
+
+type AuditEntry struct {
+	ActorID   string `json:"actor_id"`
+	SubjectID string `json:"subject_id"`
+	Method    string `json:"method"`
+	Path      string `json:"path"`
+	Status    int    `json:"status"`
+}
@@ -0,0 +1,23 @@
+package models
+
+import "time"
+
+type CalendarToken struct {
+	Token     string    `json:"token"`
+	UserID    string    `json:"user_id"`
+	ExpiresAt time.Time `json:"expires_at"`
+	FeedURL   string    `json:"feed_url"`
+}
+
+type CalendarEvent struct {
+	UID         string
+	Summary     string
+	Description string
+	Start       time.Time
+	End         time.Time
+}
+
+type CalendarFeed struct {
+	UserID string
+	Events []*CalendarEvent
+}
@@ -0,0 +1,28 @@
+package models
+
+import "time"
+
+type DeprecationNotice struct {
+	Route  string     `json:"route"`
+	Field  string     `json:"field,omitempty"`
+	Since  *time.Time `json:"since,omitempty"`
+	Sunset *time.Time `json:"sunset,omitempty"`
+	Link   string     `json:"link,omitempty"`
+}
+
+type DeprecationCaller struct {
+	Key        string    `json:"key"`
+	Count      int64     `json:"count"`
+	LastUsedAt time.Time `json:"last_used_at"`
+}
+
+type DeprecationUsage struct {
+	DeprecationNotice
+	Total      int64                `json:"total"`
+	LastUsedAt *time.Time           `json:"last_used_at,omitempty"`
+	Callers    []*DeprecationCaller `json:"callers"`
+}
+
+type DeprecationsResponse struct {
+	Deprecations []*DeprecationUsage `json:"deprecations"`
+}
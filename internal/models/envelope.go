@@ -0,0 +1,34 @@
+package models
+
+type Envelope struct {
+	Data     any          `json:"data"`
+	Meta     EnvelopeMeta `json:"meta"`
+	Warnings []string     `json:"warnings"`
+}
+
+type EnvelopeMeta struct {
+	RequestID  string      `json:"request_id"`
+	Pagination *Pagination `json:"pagination,omitempty"`
+}
+
+type Pagination struct {
+	Total  int `json:"total"`
+	Limit  int `json:"limit"`
+	Offset int `json:"offset"`
+}
+
+func (r *NotificationListResponse) Page() *Pagination {
+	return &Pagination{Total: r.Total, Limit: r.Limit, Offset: r.Offset}
+}
+
+func (r *PRListResponse) Page() *Pagination {
+	return &Pagination{Total: r.Total, Limit: r.Limit, Offset: r.Offset}
+}
+
+func (r *AssignmentHistoryResponse) Page() *Pagination {
+	return &Pagination{Total: r.Total, Limit: r.Limit, Offset: r.Offset}
+}
+
+func (r *MembershipHistoryResponse) Page() *Pagination {
+	return &Pagination{Total: r.Total, Limit: r.Limit, Offset: r.Offset}
+}
@@ -8,3 +8,23 @@ type Error struct {
 type ErrorResponse struct {
 	Error Error `json:"error"`
 }
+
+type ErrorCodeInfo struct {
+	Code        string `json:"code"`
+	HTTPStatus  int    `json:"http_status"`
+	Description string `json:"description"`
+	Retryable   bool   `json:"retryable"`
+}
+
+type ErrorCatalogResponse struct {
+	Errors []ErrorCodeInfo `json:"errors"`
+}
+
+type Problem struct {
+	Type     string `json:"type"`
+	Title    string `json:"title"`
+	Status   int    `json:"status"`
+	Detail   string `json:"detail,omitempty"`
+	Instance string `json:"instance,omitempty"`
+	Code     string `json:"code"`
+}
@@ -0,0 +1,33 @@
+package models
+
+import "time"
+
+// ReviewerExclusion forbids ReviewerID from reviewing pull requests authored
+// by AuthorID. A mutual exclusion also forbids the reverse direction.
+type ReviewerExclusion struct {
+	ReviewerID string    `json:"reviewer_id"`
+	AuthorID   string    `json:"author_id"`
+	Mutual     bool      `json:"mutual"`
+	Reason     string    `json:"reason,omitempty"`
+	CreatedAt  time.Time `json:"created_at"`
+}
+
+type AddReviewerExclusionRequest struct {
+	ReviewerID string `json:"reviewer_id"`
+	AuthorID   string `json:"author_id"`
+	Mutual     bool   `json:"mutual"`
+	Reason     string `json:"reason"`
+}
+
+type DeleteReviewerExclusionRequest struct {
+	ReviewerID string `json:"reviewer_id"`
+	AuthorID   string `json:"author_id"`
+}
+
+type ReviewerExclusionResponse struct {
+	Exclusion *ReviewerExclusion `json:"exclusion"`
+}
+
+type ReviewerExclusionsResponse struct {
+	Exclusions []*ReviewerExclusion `json:"exclusions"`
+}
@@ -0,0 +1,9 @@
+package models
+
+type FaultConfig struct {
+	Enabled                  bool `json:"enabled"`
+	StorageDelayMs           int  `json:"storage_delay_ms"`
+	StorageDelayPercent      int  `json:"storage_delay_percent"`
+	StorageFailPercent       int  `json:"storage_fail_percent"`
+	DropNotificationsPercent int  `json:"drop_notifications_percent"`
+}
@@ -0,0 +1,8 @@
+package models
+
+type IdentityClaims struct {
+	Issuer            string
+	Subject           string
+	Email             string
+	PreferredUsername string
+}
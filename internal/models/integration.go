@@ -0,0 +1,26 @@
+package models
+
+import "time"
+
+const (
+	IntegrationSlack    = "slack"
+	IntegrationGitHub   = "github"
+	IntegrationTelegram = "telegram"
+)
+
+type SetIntegrationTokenRequest struct {
+	TeamName string `json:"team_name"`
+	Provider string `json:"provider"`
+	Token    string `json:"token"`
+}
+
+type IntegrationToken struct {
+	TeamName  string    `json:"team_name"`
+	Provider  string    `json:"provider"`
+	KeyID     string    `json:"key_id"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+type IntegrationTokenResponse struct {
+	Integration IntegrationToken `json:"integration"`
+}
@@ -0,0 +1,12 @@
+package models
+
+import "time"
+
+// JiraTask links a reviewer assignment to the Jira sub-task created for it.
+type JiraTask struct {
+	IssueKey      string
+	PullRequestID string
+	UserID        string
+	CreatedAt     time.Time
+	DoneAt        *time.Time
+}
@@ -0,0 +1,13 @@
+package models
+
+type LoadMetrics struct {
+	Shedding         bool    `json:"shedding"`
+	AvgPoolWaitMs    float64 `json:"avg_pool_wait_ms"`
+	ThresholdMs      float64 `json:"threshold_ms"`
+	ShedRequests     int64   `json:"shed_requests"`
+	SheddingSwitches int64   `json:"shedding_switches"`
+	PoolOpen         int     `json:"pool_open"`
+	PoolInUse        int     `json:"pool_in_use"`
+	PoolMaxOpen      int     `json:"pool_max_open"`
+	PoolWaitCount    int64   `json:"pool_wait_count"`
+}
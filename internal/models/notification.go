@@ -0,0 +1,71 @@
+package models
+
+import "time"
+
+const (
+	NotificationSent   = "SENT"
+	NotificationFailed = "FAILED"
+)
+
+const (
+	NotificationEventAssignment = "ASSIGNMENT"
+	NotificationEventDigest     = "DIGEST"
+	NotificationEventAlert      = "ALERT"
+)
+
+type NotificationSettings struct {
+	UserID              string `json:"user_id"`
+	DigestWindowMinutes int    `json:"digest_window_minutes"`
+}
+
+type NotificationSettingsResponse struct {
+	Settings NotificationSettings `json:"settings"`
+}
+
+type AssignmentDigest struct {
+	UserID      string            `json:"user_id"`
+	Assignments []AssignmentEvent `json:"assignments"`
+	From        time.Time         `json:"from"`
+	To          time.Time         `json:"to"`
+}
+
+type NotificationAttempt struct {
+	Key            string
+	UserID         string
+	Channel        string
+	Event          string
+	PullRequestIDs []string
+	Status         string
+	Error          string
+}
+
+type NotificationRecord struct {
+	ID             int64     `json:"id"`
+	UserID         string    `json:"user_id"`
+	Channel        string    `json:"channel"`
+	Event          string    `json:"event"`
+	PullRequestIDs []string  `json:"pull_request_ids"`
+	Status         string    `json:"status"`
+	Attempts       int       `json:"attempts"`
+	LastError      string    `json:"last_error,omitempty"`
+	CreatedAt      time.Time `json:"created_at"`
+	UpdatedAt      time.Time `json:"updated_at"`
+}
+
+type NotificationQuery struct {
+	UserID        string
+	PullRequestID string
+	Channel       string
+	Status        string
+	From          *time.Time
+	To            *time.Time
+	Limit         int
+	Offset        int
+}
+
+type NotificationListResponse struct {
+	Notifications []*NotificationRecord `json:"notifications"`
+	Total         int                   `json:"total"`
+	Limit         int                   `json:"limit"`
+	Offset        int                   `json:"offset"`
+}
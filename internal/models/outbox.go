@@ -0,0 +1,95 @@
+package models
+
+import (
+	"encoding/json"
+	"time"
+)
+
+const (
+	TopicPRCreated          = "pr.created"
+	TopicPRMerged           = "pr.merged"
+	TopicPRClosed           = "pr.closed"
+	TopicPRUpdated          = "pr.updated"
+	TopicPRReviewerReplaced = "pr.reviewer_replaced"
+	TopicPRReviewerRemoved  = "pr.reviewer_removed"
+	TopicPRReviewersAdded   = "pr.reviewers_added"
+	TopicPRReviewed         = "pr.reviewed"
+
+	TopicTeamCreated       = "team.created"
+	TopicMemberAdded       = "member.added"
+	TopicMemberRemoved     = "member.removed"
+	TopicMemberDeactivated = "member.deactivated"
+)
+
+type OutboxEvent struct {
+	ID          int64           `json:"id"`
+	Topic       string          `json:"topic"`
+	AggregateID string          `json:"aggregate_id"`
+	Payload     json.RawMessage `json:"payload"`
+	CreatedAt   time.Time       `json:"created_at"`
+	Attempts    int             `json:"attempts"`
+}
+
+type PREvent struct {
+	PullRequest   *PullRequest `json:"pull_request"`
+	OldReviewerID string       `json:"old_reviewer_id,omitempty"`
+	NewReviewerID string       `json:"new_reviewer_id,omitempty"`
+	AddedIDs      []string     `json:"added_reviewer_ids,omitempty"`
+	ReviewerID    string       `json:"reviewer_id,omitempty"`
+	ReviewState   string       `json:"review_state,omitempty"`
+	Reason        string       `json:"reason,omitempty"`
+	OccurredAt    time.Time    `json:"occurred_at"`
+}
+
+// TeamEvent is the payload of team and membership topics. Member events
+// carry the user; a member.removed caused by a move names the new team in
+// MovedTo, and the matching member.added names the old one in MovedFrom.
+type TeamEvent struct {
+	TeamName   string    `json:"team_name"`
+	UserID     string    `json:"user_id,omitempty"`
+	Username   string    `json:"username,omitempty"`
+	IsActive   *bool     `json:"is_active,omitempty"`
+	MemberIDs  []string  `json:"member_ids,omitempty"`
+	MovedFrom  string    `json:"moved_from,omitempty"`
+	MovedTo    string    `json:"moved_to,omitempty"`
+	Reason     string    `json:"reason,omitempty"`
+	OccurredAt time.Time `json:"occurred_at"`
+}
+
+// LoggedEvent is an outbox event as exported to analytics: delivered or not,
+// events are never deleted, so the outbox doubles as an append-only log.
+type LoggedEvent struct {
+	ID          int64           `json:"id"`
+	Topic       string          `json:"topic"`
+	AggregateID string          `json:"aggregate_id"`
+	Payload     json.RawMessage `json:"payload"`
+	CreatedAt   time.Time       `json:"created_at"`
+}
+
+type EventLogQuery struct {
+	Since int64
+	Topic string
+	Limit int
+}
+
+// EventLogResponse is one page of the event log. NextCursor is the id of the
+// last returned event, to be passed as since for the next page; it equals
+// since when the page is empty.
+type EventLogResponse struct {
+	Events     []*LoggedEvent `json:"events"`
+	NextCursor int64          `json:"next_cursor"`
+	HasMore    bool           `json:"has_more"`
+}
+
+type OutboxBacklog struct {
+	Pending         int        `json:"pending"`
+	Retrying        int        `json:"retrying"`
+	OldestPendingAt *time.Time `json:"oldest_pending_at,omitempty"`
+}
+
+type OutboxStats struct {
+	OutboxBacklog
+	LagSeconds float64 `json:"lag_seconds"`
+	Delivered  int64   `json:"delivered"`
+	Failed     int64   `json:"failed"`
+}
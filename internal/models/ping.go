@@ -1,6 +1,14 @@
 package models
 
+import "time"
+
 type PingResponse struct {
 	Status  string `json:"status"`
 	Message string `json:"message"`
 }
+
+type DrainStatus struct {
+	Draining     bool       `json:"draining"`
+	Since        *time.Time `json:"since,omitempty"`
+	DelaySeconds float64    `json:"delay_seconds"`
+}
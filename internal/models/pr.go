@@ -5,15 +5,99 @@ import "time"
 const (
 	StatusOpen   = "OPEN"
 	StatusMerged = "MERGED"
+	StatusClosed = "CLOSED"
+)
+
+// Priorities order review queues; urgent pull requests also bypass reviewer
+// capacity limits.
+const (
+	PriorityLow    = "LOW"
+	PriorityNormal = "NORMAL"
+	PriorityHigh   = "HIGH"
+	PriorityUrgent = "URGENT"
+)
+
+const (
+	EventAssigned         = "ASSIGNED"
+	EventAcknowledged     = "ACKNOWLEDGED"
+	EventReassigned       = "REASSIGNED"
+	EventAckTimeout       = "ACK_TIMEOUT"
+	EventDeclined         = "DECLINED"
+	EventApproved         = "APPROVED"
+	EventChangesRequested = "CHANGES_REQUESTED"
+	EventRemoved          = "REMOVED"
+)
+
+const (
+	ReviewerStateAssigned     = "ASSIGNED"
+	ReviewerStateAcknowledged = "ACKNOWLEDGED"
+)
+
+// Review states record the outcome of a review, separately from whether the
+// assignment was acknowledged.
+const (
+	ReviewStatePending          = "PENDING"
+	ReviewStateApproved         = "APPROVED"
+	ReviewStateChangesRequested = "CHANGES_REQUESTED"
+)
+
+const (
+	BackfillOutcomeFilled  = "FILLED"
+	BackfillOutcomePartial = "PARTIAL"
+	BackfillOutcomeFailed  = "FAILED"
+)
+
+const (
+	ReassignOutcomeReassigned = "REASSIGNED"
+	ReassignOutcomeFailed     = "FAILED"
+	ReassignOutcomeSkipped    = "SKIPPED"
+)
+
+const (
+	OutcomePending    = "PENDING"
+	OutcomeMerged     = "MERGED"
+	OutcomeReassigned = "REASSIGNED"
+	OutcomeDeclined   = "DECLINED"
+	OutcomeClosed     = "CLOSED"
+	OutcomeRemoved    = "REMOVED"
 )
 
 type PullRequest struct {
-	ID        string     `json:"pull_request_id"`
-	Title     string     `json:"pull_request_name"`
-	AuthorID  string     `json:"author_id"`
-	Status    string     `json:"status"`
-	Reviewers []string   `json:"assigned_reviewers"`
-	MergedAt  *time.Time `json:"mergedAt,omitempty"`
+	ID              string            `json:"pull_request_id"`
+	Title           string            `json:"pull_request_name"`
+	Description     string            `json:"description,omitempty"`
+	RepositoryURL   string            `json:"repository_url,omitempty"`
+	Branch          string            `json:"branch,omitempty"`
+	Labels          []string          `json:"labels,omitempty"`
+	Priority        string            `json:"priority,omitempty"`
+	AuthorID        string            `json:"author_id"`
+	Status          string            `json:"status"`
+	Deadline        *time.Time        `json:"deadline,omitempty"`
+	Overdue         bool              `json:"overdue"`
+	Reviewers       []string          `json:"assigned_reviewers"`
+	ReviewerDetails []*ReviewerDetail `json:"reviewers"`
+	NeedMore        bool              `json:"needMoreReviewers"`
+	CreatedAt       *time.Time        `json:"createdAt,omitempty"`
+	MergedAt        *time.Time        `json:"mergedAt,omitempty"`
+	ClosedAt        *time.Time        `json:"closedAt,omitempty"`
+	Author          *UserWithTeam     `json:"-"`
+}
+
+// IsOverdue reports whether an open pull request has passed its review
+// deadline.
+func (pr *PullRequest) IsOverdue(now time.Time) bool {
+	return pr.Status == StatusOpen && pr.Deadline != nil && now.After(*pr.Deadline)
+}
+
+type ReviewerDetail struct {
+	UserID         string     `json:"user_id"`
+	Username       string     `json:"username"`
+	AssignedAt     time.Time  `json:"assigned_at"`
+	State          string     `json:"state"`
+	AcknowledgedAt *time.Time `json:"acknowledged_at,omitempty"`
+	ReviewState    string     `json:"review_state"`
+	ReviewedAt     *time.Time `json:"reviewed_at,omitempty"`
+	BorrowedFrom   string     `json:"borrowed_from,omitempty"`
 }
 
 type PullRequestShort struct {
@@ -21,39 +105,247 @@ type PullRequestShort struct {
 	Title    string `json:"pull_request_name"`
 	AuthorID string `json:"author_id"`
 	Status   string `json:"status"`
+	Priority string `json:"priority,omitempty"`
+	AgeDays  int    `json:"age_days"`
+}
+
+type PRNeedingReviewers struct {
+	ID        string `json:"pull_request_id"`
+	Title     string `json:"pull_request_name"`
+	AuthorID  string `json:"author_id"`
+	TeamName  string `json:"team_name"`
+	Reviewers int    `json:"reviewers_count"`
+	Missing   int    `json:"missing_reviewers"`
+}
+
+type NeedReviewersResponse struct {
+	TeamName          string                `json:"team_name,omitempty"`
+	RequiredReviewers int                   `json:"required_reviewers"`
+	PullRequests      []*PRNeedingReviewers `json:"pull_requests"`
+}
+
+type BackfillReviewersRequest struct {
+	TeamName string `json:"team_name"`
+}
+
+type PRBackfillResult struct {
+	PullRequestID string   `json:"pull_request_id"`
+	Outcome       string   `json:"outcome"`
+	Added         []string `json:"added_reviewers"`
+	Error         *Error   `json:"error,omitempty"`
+	Err           error    `json:"-"`
+}
+
+type BackfillReviewersResponse struct {
+	TeamName string              `json:"team_name,omitempty"`
+	Filled   int                 `json:"filled"`
+	Partial  int                 `json:"partial"`
+	Failed   int                 `json:"failed"`
+	Results  []*PRBackfillResult `json:"results"`
 }
 
 type PRCreateRequest struct {
-	ID       string `json:"pull_request_id"`
-	Title    string `json:"pull_request_name"`
-	AuthorID string `json:"author_id"`
+	ID            string   `json:"pull_request_id"`
+	Title         string   `json:"pull_request_name"`
+	AuthorID      string   `json:"author_id"`
+	Description   string   `json:"description,omitempty"`
+	RepositoryURL string   `json:"repository_url,omitempty"`
+	Branch        string   `json:"branch,omitempty"`
+	Labels        []string `json:"labels,omitempty"`
+	Priority      string   `json:"priority,omitempty"`
+	// Deadline overrides the review SLA of the author's team.
+	Deadline *time.Time `json:"deadline,omitempty"`
+	// Components and Paths are hints matched against the team's required
+	// reviewer rules; they are not stored.
+	Components []string `json:"components,omitempty"`
+	Paths      []string `json:"paths,omitempty"`
+	// Idempotent makes a create of an existing pull request with the same
+	// payload return that pull request instead of failing, so retries and
+	// redelivered webhooks do not conflict. Always set for signed internal
+	// clients and for requests with an Idempotency-Key header.
+	Idempotent bool `json:"idempotent,omitempty"`
+}
+
+// PRUpdateRequest changes the metadata of an open pull request. Fields left
+// out stay as they are; an empty labels list removes all labels.
+type PRUpdateRequest struct {
+	ID          string    `json:"pull_request_id"`
+	Title       *string   `json:"pull_request_name"`
+	Description *string   `json:"description"`
+	Labels      *[]string `json:"labels"`
 }
 
 type PRResponse struct {
-	PR PullRequest `json:"pr"`
+	PR       PullRequest     `json:"pr"`
+	Warnings []string        `json:"warnings,omitempty"`
+	Author   *UserWithTeam   `json:"author,omitempty"`
+	Users    []*UserWithTeam `json:"users,omitempty"`
+	// Existing is set when an idempotent create found the pull request
+	// already created by an earlier delivery.
+	Existing bool `json:"-"`
+}
+
+const (
+	AgendaOverdue  = "OVERDUE"
+	AgendaReReview = "RE_REVIEW"
+	AgendaNew      = "NEW"
+)
+
+// AgendaItem is an open review on a reviewer's daily agenda. DueAt is set
+// for unacknowledged assignments; AssignedToday is in the reviewer's own
+// timezone.
+type AgendaItem struct {
+	PullRequestID  string     `json:"pull_request_id"`
+	Title          string     `json:"pull_request_name"`
+	AuthorID       string     `json:"author_id"`
+	Kind           string     `json:"kind"`
+	AssignedAt     time.Time  `json:"assigned_at"`
+	AcknowledgedAt *time.Time `json:"acknowledged_at,omitempty"`
+	DueAt          *time.Time `json:"due_at,omitempty"`
+	AgeDays        int        `json:"age_days"`
+	AssignedToday  bool       `json:"-"`
+}
+
+type AgendaResponse struct {
+	UserID string        `json:"user_id"`
+	Items  []*AgendaItem `json:"items"`
 }
 
 type UserReviewsResponse struct {
 	UserID       string              `json:"user_id"`
 	PullRequests []*PullRequestShort `json:"pull_requests"`
+	Users        []*UserWithTeam     `json:"users,omitempty"`
+}
+
+type PRBatchGetRequest struct {
+	IDs []string `json:"pull_request_ids"`
+}
+
+// PRBatchGetResponse lists the pull requests found, ordered by id, and the
+// requested ids that do not exist, in request order.
+type PRBatchGetResponse struct {
+	PullRequests []*PullRequest `json:"pull_requests"`
+	Missing      []string       `json:"missing"`
 }
 
 type PRMergeRequest struct {
 	ID string `json:"pull_request_id"`
 }
 
+type PRCloseRequest struct {
+	ID string `json:"pull_request_id"`
+}
+
 type PRReassignRequest struct {
 	ID            string `json:"pull_request_id"`
 	OldReviewerID string `json:"old_reviewer_id"`
 }
 
+type PRDeclineRequest struct {
+	ID     string `json:"pull_request_id"`
+	UserID string `json:"user_id"`
+	Reason string `json:"reason,omitempty"`
+}
+
+type PRAddReviewerRequest struct {
+	ID     string `json:"pull_request_id"`
+	UserID string `json:"user_id"`
+}
+
+type PRRemoveReviewerRequest struct {
+	ID     string `json:"pull_request_id"`
+	UserID string `json:"user_id"`
+	Reason string `json:"reason,omitempty"`
+}
+
+type PRReassignAllRequest struct {
+	OldUserID      string `json:"old_user_id"`
+	Preview        bool   `json:"preview"`
+	ChangeSetToken string `json:"change_set_token,omitempty"`
+}
+
+type PRReassignResult struct {
+	PullRequestID string       `json:"pull_request_id"`
+	Outcome       string       `json:"outcome"`
+	ReplacedBy    string       `json:"replaced_by,omitempty"`
+	Error         *Error       `json:"error,omitempty"`
+	Err           error        `json:"-"`
+	PR            *PullRequest `json:"-"`
+}
+
+type PRReassignAllResponse struct {
+	OldUserID      string              `json:"old_user_id"`
+	Preview        bool                `json:"preview"`
+	ChangeSetToken string              `json:"change_set_token"`
+	Reassigned     int                 `json:"reassigned"`
+	Failed         int                 `json:"failed"`
+	Results        []*PRReassignResult `json:"results"`
+}
+
+type TransferAssignmentsRequest struct {
+	FromUserID     string   `json:"from_user_id"`
+	ToUserID       string   `json:"to_user_id"`
+	PullRequestIDs []string `json:"pull_request_ids,omitempty"`
+	Force          bool     `json:"force"`
+}
+
+type TransferAssignmentsResponse struct {
+	FromUserID  string              `json:"from_user_id"`
+	ToUserID    string              `json:"to_user_id"`
+	Transferred int                 `json:"transferred"`
+	Skipped     int                 `json:"skipped"`
+	Results     []*PRReassignResult `json:"results"`
+}
+
+type RebalanceAssignmentsRequest struct {
+	TeamName       string `json:"team_name"`
+	Preview        bool   `json:"preview"`
+	ChangeSetToken string `json:"change_set_token,omitempty"`
+}
+
+type RebalanceMove struct {
+	PullRequestID string `json:"pull_request_id"`
+	FromUserID    string `json:"from_user_id"`
+	ToUserID      string `json:"to_user_id"`
+}
+
+type RebalanceAssignmentsResponse struct {
+	TeamName       string           `json:"team_name"`
+	Preview        bool             `json:"preview"`
+	ChangeSetToken string           `json:"change_set_token"`
+	Before         []*ReviewerLoad  `json:"before"`
+	After          []*ReviewerLoad  `json:"after"`
+	Moves          []*RebalanceMove `json:"moves"`
+}
+
+type PRAcknowledgeRequest struct {
+	ID     string `json:"pull_request_id"`
+	UserID string `json:"user_id"`
+}
+
+// PRApproveRequest records a reviewer's verdict; State defaults to APPROVED.
+type PRApproveRequest struct {
+	ID     string `json:"pull_request_id"`
+	UserID string `json:"user_id"`
+	State  string `json:"state,omitempty"`
+}
+
+type ReviewerAssignment struct {
+	PullRequestID string    `json:"pull_request_id"`
+	UserID        string    `json:"user_id"`
+	AssignedAt    time.Time `json:"assigned_at"`
+}
+
 type PRReassignResponse struct {
-	PR         PullRequest `json:"pr"`
-	ReplacedBy string      `json:"replaced_by"`
+	PR         PullRequest     `json:"pr"`
+	ReplacedBy string          `json:"replaced_by"`
+	Author     *UserWithTeam   `json:"author,omitempty"`
+	Users      []*UserWithTeam `json:"users,omitempty"`
 }
 
 type UserAssignmentsStat struct {
 	UserID      string `json:"user_id"`
+	Username    string `json:"username,omitempty"`
 	Assignments int    `json:"assignments_count"`
 }
 
@@ -65,4 +357,101 @@ type PRAssignmentsStat struct {
 type AssignmentsStatsResponse struct {
 	ByUser []*UserAssignmentsStat `json:"assignments_by_user"`
 	ByPR   []*PRAssignmentsStat   `json:"assignments_by_pr"`
+	Users  []*UserWithTeam        `json:"users,omitempty"`
+}
+
+type AssignmentEvent struct {
+	UserID          string    `json:"user_id"`
+	PullRequestID   string    `json:"pull_request_id"`
+	PullRequestName string    `json:"pull_request_name"`
+	AuthorID        string    `json:"author_id"`
+	AssignedAt      time.Time `json:"assigned_at"`
+}
+
+type AwaitAssignmentResponse struct {
+	UserID     string           `json:"user_id"`
+	Assignment *AssignmentEvent `json:"assignment"`
+	TimedOut   bool             `json:"timed_out"`
+}
+
+type AssignmentHistoryQuery struct {
+	UserID string
+	From   *time.Time
+	To     *time.Time
+	Limit  int
+	Offset int
+}
+
+// PRListQuery filters pull requests. Empty fields and nil times do not
+// filter; date ranges include From and exclude To. Labels keeps pull
+// requests carrying all of the given labels, Overdue open ones past their
+// deadline.
+type PRListQuery struct {
+	Status      string
+	AuthorID    string
+	ReviewerID  string
+	TeamName    string
+	CreatedFrom *time.Time
+	CreatedTo   *time.Time
+	MergedFrom  *time.Time
+	MergedTo    *time.Time
+	Labels      []string
+	Overdue     bool
+	Limit       int
+	Offset      int
+}
+
+type PRListResponse struct {
+	PullRequests []*PullRequest `json:"pull_requests"`
+	Total        int            `json:"total"`
+	Limit        int            `json:"limit"`
+	Offset       int            `json:"offset"`
+}
+
+type AssignmentHistoryItem struct {
+	PullRequestID   string     `json:"pull_request_id"`
+	PullRequestName string     `json:"pull_request_name"`
+	AuthorID        string     `json:"author_id"`
+	AssignedAt      time.Time  `json:"assigned_at"`
+	Outcome         string     `json:"outcome"`
+	Reason          string     `json:"reason,omitempty"`
+	EndedAt         *time.Time `json:"ended_at,omitempty"`
+}
+
+type AssignmentHistoryResponse struct {
+	UserID      string                   `json:"user_id"`
+	Assignments []*AssignmentHistoryItem `json:"assignments"`
+	Total       int                      `json:"total"`
+	Limit       int                      `json:"limit"`
+	Offset      int                      `json:"offset"`
+	Users       []*UserWithTeam          `json:"users,omitempty"`
+}
+
+const (
+	PickReasonRandom       = "RANDOM"
+	PickReasonLeastLoaded  = "LEAST_LOADED"
+	PickReasonRotation     = "ROTATION"
+	PickReasonWorkingHours = "WORKING_HOURS"
+	PickReasonRequired     = "REQUIRED"
+	PickReasonFallbackTeam = "FALLBACK_TEAM"
+)
+
+// AssignmentCandidate is a reviewer that selection would assign, with the
+// reason it was picked and the load it currently carries.
+type AssignmentCandidate struct {
+	UserID         string `json:"user_id"`
+	Username       string `json:"username"`
+	TeamName       string `json:"team_name"`
+	Reason         string `json:"reason"`
+	OpenReviews    int    `json:"open_reviews"`
+	RecentReviewer bool   `json:"recent_reviewer,omitempty"`
+}
+
+type AssignmentPreviewResponse struct {
+	AuthorID          string                 `json:"author_id"`
+	TeamName          string                 `json:"team_name"`
+	Strategy          string                 `json:"strategy"`
+	RequiredReviewers int                    `json:"required_reviewers"`
+	Candidates        []*AssignmentCandidate `json:"candidates"`
+	NeedMore          bool                   `json:"need_more_reviewers"`
 }
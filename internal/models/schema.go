@@ -0,0 +1,23 @@
+package models
+
+type SchemaReport struct {
+	OK              bool     `json:"ok"`
+	ExpectedVersion int      `json:"expected_version"`
+	CurrentVersion  int      `json:"current_version"`
+	Dirty           bool     `json:"dirty"`
+	Problems        []string `json:"problems"`
+}
+
+type OnlineMigrationStatus struct {
+	Name            string `json:"name"`
+	Table           string `json:"table"`
+	OldColumn       string `json:"old_column"`
+	NewColumn       string `json:"new_column"`
+	ConfiguredPhase string `json:"configured_phase"`
+	ActivePhase     string `json:"active_phase"`
+	Remaining       int    `json:"remaining"`
+}
+
+type OnlineMigrationsResponse struct {
+	Migrations []*OnlineMigrationStatus `json:"migrations"`
+}
@@ -0,0 +1,20 @@
+package models
+
+import "time"
+
+type LoginRequest struct {
+	Username string `json:"username"`
+	Password string `json:"password"`
+}
+
+type SetPasswordRequest struct {
+	UserID   string `json:"user_id"`
+	Password string `json:"password"`
+}
+
+type Session struct {
+	ID        string       `json:"-"`
+	User      UserWithTeam `json:"user"`
+	CSRFToken string       `json:"csrf_token"`
+	ExpiresAt time.Time    `json:"expires_at"`
+}
@@ -0,0 +1,39 @@
+package models
+
+import "time"
+
+// AssignmentSnapshot is a saved copy of the reviewer assignments and the
+// round-robin cursors that POST /admin/restore can roll back to.
+type AssignmentSnapshot struct {
+	ID              int64     `json:"snapshot_id"`
+	Label           string    `json:"label,omitempty"`
+	CreatedBy       string    `json:"created_by,omitempty"`
+	Reviewers       int       `json:"reviewers_count"`
+	RotationCursors int       `json:"rotation_cursors_count"`
+	CreatedAt       time.Time `json:"created_at"`
+}
+
+type CreateSnapshotRequest struct {
+	Label string `json:"label"`
+}
+
+type RestoreSnapshotRequest struct {
+	SnapshotID int64 `json:"snapshot_id"`
+}
+
+// SnapshotRestore lists the reviewer assignments a restore removed and
+// brought back. Only pull requests that existed when the snapshot was taken
+// and are still open are touched.
+type SnapshotRestore struct {
+	Removed         []ReviewerAssignment
+	Restored        []ReviewerAssignment
+	RotationCursors int
+}
+
+type RestoreSnapshotResponse struct {
+	SnapshotID      int64 `json:"snapshot_id"`
+	PullRequests    int   `json:"pull_requests_count"`
+	Removed         int   `json:"removed_count"`
+	Restored        int   `json:"restored_count"`
+	RotationCursors int   `json:"rotation_cursors_count"`
+}
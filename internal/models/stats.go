@@ -0,0 +1,209 @@
+package models
+
+import "time"
+
+const (
+	MetricAssignments = "assignments"
+	MetricPRsCreated  = "prs_created"
+	MetricPRsMerged   = "prs_merged"
+)
+
+const (
+	IntervalHour = "hour"
+	IntervalDay  = "day"
+	IntervalWeek = "week"
+)
+
+type ReviewerLoad struct {
+	UserID          string `json:"user_id"`
+	Username        string `json:"username"`
+	OpenAssignments int    `json:"open_assignments"`
+}
+
+type TeamLoad struct {
+	TeamName        string `json:"team_name"`
+	ActiveMembers   int    `json:"active_members_count"`
+	OpenAssignments int    `json:"open_assignments"`
+	Capacity        int    `json:"capacity"`
+}
+
+type StatsSummary struct {
+	OpenPRs                     int           `json:"open_prs"`
+	PRsNeedingReviewers         int           `json:"prs_needing_reviewers"`
+	AvgAssignmentLatencySeconds float64       `json:"avg_assignment_latency_seconds_today"`
+	BusiestReviewer             *ReviewerLoad `json:"busiest_reviewer"`
+	TeamsOverCapacity           []*TeamLoad   `json:"teams_over_capacity"`
+}
+
+type TimeseriesQuery struct {
+	Metric   string
+	Interval string
+	From     *time.Time
+	To       *time.Time
+	AsOf     *time.Time
+}
+
+type TeamBucketCount struct {
+	TeamName    string
+	BucketStart time.Time
+	Count       int
+}
+
+type TimeseriesPoint struct {
+	BucketStart time.Time `json:"bucket_start"`
+	Count       int       `json:"count"`
+}
+
+type TeamSeries struct {
+	TeamName string             `json:"team_name"`
+	Points   []*TimeseriesPoint `json:"points"`
+}
+
+type TimeseriesResponse struct {
+	Metric   string        `json:"metric"`
+	Interval string        `json:"interval"`
+	From     time.Time     `json:"from"`
+	To       time.Time     `json:"to"`
+	AsOf     *time.Time    `json:"as_of,omitempty"`
+	Series   []*TeamSeries `json:"series"`
+}
+
+type WeeklyAssignmentShare struct {
+	TeamName        string
+	UserID          string
+	WeekStart       time.Time
+	UserAssignments int
+	TeamAssignments int
+	ReviewersCount  int
+}
+
+type AssignmentAnomaly struct {
+	TeamName        string    `json:"team_name"`
+	UserID          string    `json:"user_id"`
+	WeekStart       time.Time `json:"week_start"`
+	UserAssignments int       `json:"user_assignments"`
+	TeamAssignments int       `json:"team_assignments"`
+	ReviewersCount  int       `json:"reviewers_count"`
+	Share           float64   `json:"share"`
+	Explanation     string    `json:"explanation"`
+	DetectedAt      time.Time `json:"detected_at"`
+}
+
+type AnomaliesResponse struct {
+	Threshold float64              `json:"share_threshold"`
+	Since     time.Time            `json:"since"`
+	Anomalies []*AssignmentAnomaly `json:"anomalies"`
+}
+
+type HeatmapQuery struct {
+	TeamName string
+	From     *time.Time
+	To       *time.Time
+	AsOf     *time.Time
+}
+
+type HeatmapCell struct {
+	TeamName string
+	Weekday  int
+	Hour     int
+	Count    int
+}
+
+type TeamHeatmap struct {
+	TeamName string     `json:"team_name"`
+	Total    int        `json:"total"`
+	Cells    [7][24]int `json:"cells"`
+}
+
+type HeatmapResponse struct {
+	From  time.Time      `json:"from"`
+	To    time.Time      `json:"to"`
+	AsOf  *time.Time     `json:"as_of,omitempty"`
+	Teams []*TeamHeatmap `json:"teams"`
+}
+
+type MergedCount struct {
+	TeamName  string
+	AuthorID  string
+	Username  string
+	WeekStart time.Time
+	Count     int
+}
+
+type TeamThroughput struct {
+	TeamName       string             `json:"team_name"`
+	Merged         int                `json:"merged"`
+	PreviousMerged int                `json:"previous_merged"`
+	ChangePercent  *float64           `json:"change_percent"`
+	Weekly         []*TimeseriesPoint `json:"weekly"`
+}
+
+type AuthorThroughput struct {
+	AuthorID       string   `json:"author_id"`
+	Username       string   `json:"username"`
+	TeamName       string   `json:"team_name"`
+	Merged         int      `json:"merged"`
+	PreviousMerged int      `json:"previous_merged"`
+	ChangePercent  *float64 `json:"change_percent"`
+}
+
+type ThroughputResponse struct {
+	Weeks        int                 `json:"weeks"`
+	From         time.Time           `json:"from"`
+	To           time.Time           `json:"to"`
+	PreviousFrom time.Time           `json:"previous_from"`
+	AsOf         *time.Time          `json:"as_of,omitempty"`
+	Teams        []*TeamThroughput   `json:"teams"`
+	Authors      []*AuthorThroughput `json:"authors"`
+}
+
+type CompletionQuery struct {
+	TeamName string
+	From     *time.Time
+	To       *time.Time
+	AsOf     *time.Time
+}
+
+type UserCompletion struct {
+	UserID         string   `json:"user_id"`
+	Username       string   `json:"username"`
+	TeamName       string   `json:"team_name"`
+	Assigned       int      `json:"assigned"`
+	Merged         int      `json:"merged"`
+	Reassigned     int      `json:"reassigned"`
+	Declined       int      `json:"declined"`
+	Closed         int      `json:"closed"`
+	Removed        int      `json:"removed"`
+	Pending        int      `json:"pending"`
+	CompletionRate *float64 `json:"completion_rate"`
+}
+
+type CompletionResponse struct {
+	From  time.Time         `json:"from"`
+	To    time.Time         `json:"to"`
+	AsOf  *time.Time        `json:"as_of,omitempty"`
+	Users []*UserCompletion `json:"users"`
+}
+
+type LabelStatsQuery struct {
+	TeamName string
+	From     *time.Time
+	To       *time.Time
+}
+
+// LabelStats counts the pull requests carrying a label: open ones now,
+// created and merged ones within the window.
+type LabelStats struct {
+	Label         string   `json:"label"`
+	Open          int      `json:"open"`
+	Created       int      `json:"created"`
+	Merged        int      `json:"merged"`
+	AvgMergeHours *float64 `json:"avg_merge_hours"`
+}
+
+type LabelStatsResponse struct {
+	From     time.Time     `json:"from"`
+	To       time.Time     `json:"to"`
+	TeamName string        `json:"team_name,omitempty"`
+	Labels   []*LabelStats `json:"labels"`
+}
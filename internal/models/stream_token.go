@@ -0,0 +1,13 @@
+package models
+
+import "time"
+
+type StreamTokenRequest struct {
+	UserID string `json:"user_id"`
+}
+
+type StreamToken struct {
+	Token     string    `json:"token"`
+	UserID    string    `json:"user_id"`
+	ExpiresAt time.Time `json:"expires_at"`
+}
@@ -1,8 +1,19 @@
 package models
 
+import "time"
+
 type Team struct {
-	Name    string  `json:"team_name"`
-	Members []*User `json:"members"`
+	Name    string     `json:"team_name"`
+	Members []*User    `json:"members"`
+	OpenPRs *int       `json:"open_pull_requests,omitempty"`
+	Stats   *TeamStats `json:"stats,omitempty"`
+}
+
+type TeamStats struct {
+	MembersCount    int `json:"members_count"`
+	ActiveMembers   int `json:"active_members_count"`
+	OpenPRsAuthored int `json:"open_prs_authored"`
+	OpenAssignments int `json:"open_assignments"`
 }
 
 type TeamResponse struct {
@@ -17,3 +28,58 @@ type TeamDeactivateResponse struct {
 	TeamName         string `json:"team_name"`
 	DeactivatedCount int    `json:"deactivated_count"`
 }
+
+type TeamSettings struct {
+	TeamName             string   `json:"team_name"`
+	StrictDuplicateCheck bool     `json:"strict_duplicate_check"`
+	AckTimeoutHours      int      `json:"ack_timeout_hours"`
+	ReviewSLAHours       int      `json:"review_sla_hours"`
+	FallbackTeams        []string `json:"fallback_teams"`
+	// RequiredReviewers are code-owner style rules: a pull request whose
+	// hints match a rule always gets the rule's user as a reviewer.
+	RequiredReviewers []RequiredReviewerRule `json:"required_reviewers"`
+}
+
+// RequiredReviewerRule matches a pull request that names Component among its
+// components (case-insensitive) or touches a path starting with PathPrefix.
+type RequiredReviewerRule struct {
+	Component  string `json:"component,omitempty"`
+	PathPrefix string `json:"path_prefix,omitempty"`
+	UserID     string `json:"user_id"`
+}
+
+type TeamSettingsResponse struct {
+	Settings TeamSettings `json:"settings"`
+}
+
+const (
+	MembershipJoined = "JOINED"
+	MembershipLeft   = "LEFT"
+)
+
+// MembershipChange records a user joining or leaving a team. For a move
+// between teams OtherTeam names the team on the other side.
+type MembershipChange struct {
+	ID        int64     `json:"id"`
+	TeamName  string    `json:"team_name"`
+	UserID    string    `json:"user_id"`
+	Change    string    `json:"change"`
+	OtherTeam string    `json:"other_team,omitempty"`
+	ChangedAt time.Time `json:"changed_at"`
+}
+
+type MembershipHistoryQuery struct {
+	TeamName string
+	From     *time.Time
+	To       *time.Time
+	Limit    int
+	Offset   int
+}
+
+type MembershipHistoryResponse struct {
+	TeamName string              `json:"team_name"`
+	Changes  []*MembershipChange `json:"changes"`
+	Total    int                 `json:"total"`
+	Limit    int                 `json:"limit"`
+	Offset   int                 `json:"offset"`
+}
@@ -4,6 +4,24 @@ type User struct {
 	ID       string `json:"user_id"`
 	Username string `json:"username"`
 	IsActive bool   `json:"is_active"`
+	// Weight scales how often random selection picks the user relative to
+	// teammates; 0 means unset and keeps the stored weight (1 by default).
+	Weight float64 `json:"weight,omitempty"`
+	// Version grows with every change of the user. Sending it back makes
+	// the update fail with a conflict if the user changed in between; 0
+	// skips the check.
+	Version int64 `json:"version,omitempty"`
+	// WorkingHours is set only by responses that change it.
+	WorkingHours *WorkingHours `json:"working_hours,omitempty"`
+}
+
+// WorkingHours is the daily window a user reviews in, as "HH:MM" local times
+// in Timezone (an IANA name). End before Start spans midnight, equal times
+// mean the whole day; both empty means the hours are unknown.
+type WorkingHours struct {
+	Timezone string `json:"timezone"`
+	Start    string `json:"start,omitempty"`
+	End      string `json:"end,omitempty"`
 }
 
 type UserWithTeam struct {
@@ -14,8 +32,64 @@ type UserWithTeam struct {
 type SetActiveRequest struct {
 	ID       string `json:"user_id"`
 	IsActive bool   `json:"is_active"`
+	Version  int64  `json:"version,omitempty"`
+}
+
+type SetWeightRequest struct {
+	ID      string  `json:"user_id"`
+	Weight  float64 `json:"weight"`
+	Version int64   `json:"version,omitempty"`
+}
+
+type SetWorkingHoursRequest struct {
+	ID string `json:"user_id"`
+	WorkingHours
+	Version int64 `json:"version,omitempty"`
+}
+
+type UsersBatchGetRequest struct {
+	UserIDs []string `json:"user_ids"`
+}
+
+// UsersBatchGetResponse lists the users found, ordered by id, and the
+// requested ids that do not exist, in request order.
+type UsersBatchGetResponse struct {
+	Users   []*UserWithTeam `json:"users"`
+	Missing []string        `json:"missing"`
 }
 
 type UserResponse struct {
-	User UserWithTeam `json:"user"`
+	User       UserWithTeam        `json:"user"`
+	Reassigned []*PRReassignResult `json:"reassigned,omitempty"`
+	Backfilled []*PRBackfillResult `json:"backfilled,omitempty"`
+}
+
+type SetUnavailableRequest struct {
+	Unavailable *bool `json:"unavailable"`
+}
+
+type DeclineReviewRequest struct {
+	ID     string `json:"pull_request_id"`
+	Reason string `json:"reason,omitempty"`
+}
+
+// Absence hides a user from reviewer selection between two dates, inclusive,
+// in the user's timezone, without deactivating them.
+type Absence struct {
+	ID        int64  `json:"absence_id"`
+	UserID    string `json:"user_id"`
+	StartDate string `json:"start_date"`
+	EndDate   string `json:"end_date"`
+	Reason    string `json:"reason,omitempty"`
+}
+
+type SetAbsenceRequest struct {
+	UserID    string `json:"user_id"`
+	StartDate string `json:"start_date"`
+	EndDate   string `json:"end_date"`
+	Reason    string `json:"reason,omitempty"`
+}
+
+type AbsenceResponse struct {
+	Absence Absence `json:"absence"`
 }
@@ -0,0 +1,224 @@
+package notify
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"sync"
+	"time"
+
+	"github.com/cloudyy74/pr-reviewer-service/internal/models"
+)
+
+const maxBufferedPerUser = 200
+
+type Notifier interface {
+	Publish(event models.AssignmentEvent)
+	Subscribe(userID string) (<-chan models.AssignmentEvent, func())
+}
+
+type Sender interface {
+	SendAssignment(ctx context.Context, event models.AssignmentEvent) error
+	SendDigest(ctx context.Context, digest models.AssignmentDigest) error
+	SendAlert(ctx context.Context, notification models.AlertNotification) error
+}
+
+type WindowSource interface {
+	DigestWindow(ctx context.Context, userID string) (time.Duration, error)
+}
+
+type buffer struct {
+	since  time.Time
+	events []models.AssignmentEvent
+}
+
+type Dispatcher struct {
+	Notifier
+	sender  Sender
+	windows WindowSource
+	log     *slog.Logger
+	now     func() time.Time
+
+	mu      sync.Mutex
+	buffers map[string]*buffer
+}
+
+func NewDispatcher(next Notifier, sender Sender, windows WindowSource, log *slog.Logger) (*Dispatcher, error) {
+	if next == nil {
+		return nil, errors.New("notifier cannot be nil")
+	}
+	if windows == nil {
+		return nil, errors.New("window source cannot be nil")
+	}
+	if log == nil {
+		return nil, errors.New("logger cannot be nil")
+	}
+	if sender == nil {
+		sender = NewLogSender(log)
+	}
+	return &Dispatcher{
+		Notifier: next,
+		sender:   sender,
+		windows:  windows,
+		log:      log,
+		now:      time.Now,
+		buffers:  make(map[string]*buffer),
+	}, nil
+}
+
+func (d *Dispatcher) Publish(event models.AssignmentEvent) {
+	d.Notifier.Publish(event)
+
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	buf := d.buffers[event.UserID]
+	if buf == nil {
+		buf = &buffer{since: d.now()}
+		d.buffers[event.UserID] = buf
+	}
+	buf.events = append(buf.events, event)
+}
+
+func (d *Dispatcher) Flush(ctx context.Context) (int, error) {
+	now := d.now()
+	d.mu.Lock()
+	userIDs := make([]string, 0, len(d.buffers))
+	for userID := range d.buffers {
+		userIDs = append(userIDs, userID)
+	}
+	d.mu.Unlock()
+
+	var (
+		sent int
+		errs []error
+	)
+	for _, userID := range userIDs {
+		window, err := d.windows.DigestWindow(ctx, userID)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("digest window for %s: %w", userID, err))
+			continue
+		}
+		buf := d.take(userID, window, now)
+		if buf == nil {
+			continue
+		}
+		n, err := d.send(ctx, userID, window, buf, now)
+		sent += n
+		if err != nil {
+			errs = append(errs, fmt.Errorf("notify %s: %w", userID, err))
+		}
+	}
+	return sent, errors.Join(errs...)
+}
+
+// SendAlert delivers an alert to every recipient right away: alerts are not
+// held back by digest windows. Failed deliveries are returned, not buffered.
+func (d *Dispatcher) SendAlert(ctx context.Context, alert models.Alert, userIDs []string) error {
+	var errs []error
+	for _, userID := range userIDs {
+		if err := d.sender.SendAlert(ctx, models.AlertNotification{UserID: userID, Alert: alert}); err != nil {
+			errs = append(errs, fmt.Errorf("alert %s to %s: %w", alert.Rule, userID, err))
+		}
+	}
+	return errors.Join(errs...)
+}
+
+func (d *Dispatcher) Pending() int {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	var n int
+	for _, buf := range d.buffers {
+		n += len(buf.events)
+	}
+	return n
+}
+
+func (d *Dispatcher) take(userID string, window time.Duration, now time.Time) *buffer {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	buf := d.buffers[userID]
+	if buf == nil {
+		return nil
+	}
+	if now.Before(buf.since.Add(window)) && len(buf.events) < maxBufferedPerUser {
+		return nil
+	}
+	delete(d.buffers, userID)
+	return buf
+}
+
+func (d *Dispatcher) send(ctx context.Context, userID string, window time.Duration, buf *buffer, now time.Time) (int, error) {
+	if window <= 0 {
+		for i, event := range buf.events {
+			if err := d.sender.SendAssignment(ctx, event); err != nil {
+				d.requeue(userID, buf.since, buf.events[i:])
+				return i, err
+			}
+		}
+		return len(buf.events), nil
+	}
+	err := d.sender.SendDigest(ctx, models.AssignmentDigest{
+		UserID:      userID,
+		Assignments: buf.events,
+		From:        buf.since,
+		To:          now,
+	})
+	if err != nil {
+		d.requeue(userID, buf.since, buf.events)
+		return 0, err
+	}
+	return 1, nil
+}
+
+func (d *Dispatcher) requeue(userID string, since time.Time, events []models.AssignmentEvent) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	buf := d.buffers[userID]
+	if buf == nil {
+		d.buffers[userID] = &buffer{since: since, events: events}
+		return
+	}
+	buf.since = since
+	buf.events = append(append([]models.AssignmentEvent{}, events...), buf.events...)
+}
+
+func NewLogSender(log *slog.Logger) Sender {
+	return logSender{log: log}
+}
+
+type logSender struct {
+	log *slog.Logger
+}
+
+func (s logSender) SendAssignment(_ context.Context, event models.AssignmentEvent) error {
+	s.log.Info(
+		"review assignment notification",
+		slog.String("user_id", event.UserID),
+		slog.String("pull_request_id", event.PullRequestID),
+	)
+	return nil
+}
+
+func (s logSender) SendDigest(_ context.Context, digest models.AssignmentDigest) error {
+	s.log.Info(
+		"review assignment digest",
+		slog.String("user_id", digest.UserID),
+		slog.Int("assignments", len(digest.Assignments)),
+		slog.Time("from", digest.From),
+		slog.Time("to", digest.To),
+	)
+	return nil
+}
+
+func (s logSender) SendAlert(_ context.Context, notification models.AlertNotification) error {
+	s.log.Warn(
+		"alert notification",
+		slog.String("user_id", notification.UserID),
+		slog.String("rule", notification.Alert.Rule),
+		slog.String("state", notification.Alert.State),
+		slog.String("expr", notification.Alert.Expr),
+		slog.Float64("value", notification.Alert.Value),
+	)
+	return nil
+}
@@ -0,0 +1,143 @@
+package notify
+
+import (
+	"context"
+	"errors"
+	"io"
+	"log/slog"
+	"testing"
+	"time"
+
+	"github.com/cloudyy74/pr-reviewer-service/internal/hub"
+	"github.com/cloudyy74/pr-reviewer-service/internal/models"
+)
+
+type fakeSender struct {
+	single  []models.AssignmentEvent
+	digests []models.AssignmentDigest
+	alerts  []models.AlertNotification
+	err     error
+}
+
+func (f *fakeSender) SendAssignment(_ context.Context, event models.AssignmentEvent) error {
+	if f.err != nil {
+		return f.err
+	}
+	f.single = append(f.single, event)
+	return nil
+}
+
+func (f *fakeSender) SendDigest(_ context.Context, digest models.AssignmentDigest) error {
+	if f.err != nil {
+		return f.err
+	}
+	f.digests = append(f.digests, digest)
+	return nil
+}
+
+func (f *fakeSender) SendAlert(_ context.Context, notification models.AlertNotification) error {
+	if f.err != nil {
+		return f.err
+	}
+	f.alerts = append(f.alerts, notification)
+	return nil
+}
+
+type fakeWindows map[string]time.Duration
+
+func (f fakeWindows) DigestWindow(_ context.Context, userID string) (time.Duration, error) {
+	return f[userID], nil
+}
+
+func newTestDispatcher(t *testing.T, sender Sender, windows WindowSource) (*Dispatcher, *time.Time) {
+	t.Helper()
+	d, err := NewDispatcher(hub.NewAssignmentHub(), sender, windows, slog.New(slog.NewTextHandler(io.Discard, nil)))
+	if err != nil {
+		t.Fatalf("NewDispatcher returned err: %v", err)
+	}
+	now := time.Date(2025, 3, 1, 10, 0, 0, 0, time.UTC)
+	d.now = func() time.Time { return now }
+	return d, &now
+}
+
+func TestDispatcher_DigestWindow(t *testing.T) {
+	sender := &fakeSender{}
+	d, now := newTestDispatcher(t, sender, fakeWindows{"u1": 15 * time.Minute})
+
+	d.Publish(models.AssignmentEvent{UserID: "u1", PullRequestID: "pr-1"})
+	*now = now.Add(5 * time.Minute)
+	d.Publish(models.AssignmentEvent{UserID: "u1", PullRequestID: "pr-2"})
+
+	if sent, err := d.Flush(context.Background()); err != nil || sent != 0 {
+		t.Fatalf("expected nothing sent before window ends, got %d, %v", sent, err)
+	}
+	*now = now.Add(10 * time.Minute)
+	if sent, err := d.Flush(context.Background()); err != nil || sent != 1 {
+		t.Fatalf("expected one digest, got %d, %v", sent, err)
+	}
+	if len(sender.digests) != 1 || len(sender.digests[0].Assignments) != 2 || len(sender.single) != 0 {
+		t.Fatalf("unexpected deliveries: %+v", sender)
+	}
+	if d.Pending() != 0 {
+		t.Fatalf("expected empty buffers, got %d", d.Pending())
+	}
+}
+
+func TestDispatcher_NoWindowSendsEachEvent(t *testing.T) {
+	sender := &fakeSender{}
+	d, _ := newTestDispatcher(t, sender, fakeWindows{})
+
+	events, unsubscribe := d.Subscribe("u2")
+	defer unsubscribe()
+	d.Publish(models.AssignmentEvent{UserID: "u2", PullRequestID: "pr-1"})
+	d.Publish(models.AssignmentEvent{UserID: "u2", PullRequestID: "pr-2"})
+	if got := <-events; got.PullRequestID != "pr-1" {
+		t.Fatalf("expected event forwarded to subscribers, got %+v", got)
+	}
+
+	if sent, err := d.Flush(context.Background()); err != nil || sent != 2 {
+		t.Fatalf("expected two messages, got %d, %v", sent, err)
+	}
+	if len(sender.single) != 2 || len(sender.digests) != 0 {
+		t.Fatalf("unexpected deliveries: %+v", sender)
+	}
+}
+
+func TestDispatcher_RequeuesOnFailure(t *testing.T) {
+	sender := &fakeSender{err: errors.New("smtp down")}
+	d, _ := newTestDispatcher(t, sender, fakeWindows{})
+
+	d.Publish(models.AssignmentEvent{UserID: "u1", PullRequestID: "pr-1"})
+	if _, err := d.Flush(context.Background()); err == nil {
+		t.Fatalf("expected send error")
+	}
+	if d.Pending() != 1 {
+		t.Fatalf("expected event to stay buffered, got %d", d.Pending())
+	}
+
+	sender.err = nil
+	if sent, err := d.Flush(context.Background()); err != nil || sent != 1 {
+		t.Fatalf("expected retry to succeed, got %d, %v", sent, err)
+	}
+}
+
+func TestDispatcher_SendAlertSkipsDigestWindow(t *testing.T) {
+	sender := &fakeSender{}
+	d, _ := newTestDispatcher(t, sender, fakeWindows{"u1": time.Hour})
+
+	alert := models.Alert{Rule: "no-candidates", State: models.AlertFiring}
+	if err := d.SendAlert(context.Background(), alert, []string{"u1", "u2"}); err != nil {
+		t.Fatalf("SendAlert returned err: %v", err)
+	}
+	if len(sender.alerts) != 2 || sender.alerts[0].UserID != "u1" || sender.alerts[1].Alert.Rule != "no-candidates" {
+		t.Fatalf("unexpected alert deliveries: %+v", sender.alerts)
+	}
+	if d.Pending() != 0 {
+		t.Fatalf("alerts must not be buffered, got %d pending", d.Pending())
+	}
+
+	sender.err = errors.New("smtp down")
+	if err := d.SendAlert(context.Background(), alert, []string{"u1"}); err == nil {
+		t.Fatalf("expected send error")
+	}
+}
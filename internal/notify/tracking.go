@@ -0,0 +1,78 @@
+package notify
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+
+	"github.com/cloudyy74/pr-reviewer-service/internal/models"
+)
+
+type Recorder interface {
+	RecordNotification(ctx context.Context, attempt models.NotificationAttempt) error
+}
+
+type trackingSender struct {
+	next     Sender
+	channel  string
+	recorder Recorder
+	log      *slog.Logger
+}
+
+func Track(next Sender, channel string, recorder Recorder, log *slog.Logger) Sender {
+	return trackingSender{
+		next:     next,
+		channel:  channel,
+		recorder: recorder,
+		log:      log,
+	}
+}
+
+func (t trackingSender) SendAssignment(ctx context.Context, event models.AssignmentEvent) error {
+	err := t.next.SendAssignment(ctx, event)
+	t.record(ctx, models.NotificationAttempt{
+		Key:            fmt.Sprintf("%s:assignment:%s:%s:%d", t.channel, event.UserID, event.PullRequestID, event.AssignedAt.UnixNano()),
+		UserID:         event.UserID,
+		Event:          models.NotificationEventAssignment,
+		PullRequestIDs: []string{event.PullRequestID},
+	}, err)
+	return err
+}
+
+func (t trackingSender) SendDigest(ctx context.Context, digest models.AssignmentDigest) error {
+	err := t.next.SendDigest(ctx, digest)
+	prIDs := make([]string, 0, len(digest.Assignments))
+	for _, event := range digest.Assignments {
+		prIDs = append(prIDs, event.PullRequestID)
+	}
+	t.record(ctx, models.NotificationAttempt{
+		Key:            fmt.Sprintf("%s:digest:%s:%d", t.channel, digest.UserID, digest.From.UnixNano()),
+		UserID:         digest.UserID,
+		Event:          models.NotificationEventDigest,
+		PullRequestIDs: prIDs,
+	}, err)
+	return err
+}
+
+func (t trackingSender) SendAlert(ctx context.Context, notification models.AlertNotification) error {
+	err := t.next.SendAlert(ctx, notification)
+	t.record(ctx, models.NotificationAttempt{
+		Key:            fmt.Sprintf("%s:alert:%s:%s:%s:%d", t.channel, notification.UserID, notification.Alert.Rule, notification.Alert.State, notification.Alert.Since.UnixNano()),
+		UserID:         notification.UserID,
+		Event:          models.NotificationEventAlert,
+		PullRequestIDs: []string{},
+	}, err)
+	return err
+}
+
+func (t trackingSender) record(ctx context.Context, attempt models.NotificationAttempt, sendErr error) {
+	attempt.Channel = t.channel
+	attempt.Status = models.NotificationSent
+	if sendErr != nil {
+		attempt.Status = models.NotificationFailed
+		attempt.Error = sendErr.Error()
+	}
+	if err := t.recorder.RecordNotification(ctx, attempt); err != nil {
+		t.log.Error("failed to record notification", slog.Any("error", err), slog.String("user_id", attempt.UserID))
+	}
+}
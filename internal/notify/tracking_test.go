@@ -0,0 +1,62 @@
+package notify
+
+import (
+	"context"
+	"errors"
+	"io"
+	"log/slog"
+	"testing"
+	"time"
+
+	"github.com/cloudyy74/pr-reviewer-service/internal/models"
+)
+
+type fakeRecorder struct {
+	attempts []models.NotificationAttempt
+}
+
+func (f *fakeRecorder) RecordNotification(_ context.Context, attempt models.NotificationAttempt) error {
+	f.attempts = append(f.attempts, attempt)
+	return nil
+}
+
+func TestTrack_RecordsAttempts(t *testing.T) {
+	sender := &fakeSender{err: errors.New("smtp down")}
+	recorder := &fakeRecorder{}
+	tracked := Track(sender, "email", recorder, slog.New(slog.NewTextHandler(io.Discard, nil)))
+
+	event := models.AssignmentEvent{UserID: "u1", PullRequestID: "pr-1", AssignedAt: time.Unix(100, 0)}
+	if err := tracked.SendAssignment(context.Background(), event); err == nil {
+		t.Fatalf("expected send error to be returned")
+	}
+	sender.err = nil
+	if err := tracked.SendAssignment(context.Background(), event); err != nil {
+		t.Fatalf("SendAssignment returned err: %v", err)
+	}
+	digest := models.AssignmentDigest{UserID: "u1", From: time.Unix(200, 0), Assignments: []models.AssignmentEvent{event}}
+	if err := tracked.SendDigest(context.Background(), digest); err != nil {
+		t.Fatalf("SendDigest returned err: %v", err)
+	}
+
+	if len(recorder.attempts) != 3 {
+		t.Fatalf("expected 3 recorded attempts, got %d", len(recorder.attempts))
+	}
+	failed, retried, sentDigest := recorder.attempts[0], recorder.attempts[1], recorder.attempts[2]
+	if failed.Status != models.NotificationFailed || failed.Error != "smtp down" || failed.Channel != "email" {
+		t.Fatalf("unexpected failed attempt: %+v", failed)
+	}
+	if retried.Status != models.NotificationSent || retried.Key != failed.Key {
+		t.Fatalf("retry should reuse the notification key: %+v", retried)
+	}
+	if sentDigest.Event != models.NotificationEventDigest || sentDigest.Key == failed.Key || sentDigest.PullRequestIDs[0] != "pr-1" {
+		t.Fatalf("unexpected digest attempt: %+v", sentDigest)
+	}
+
+	alert := models.AlertNotification{UserID: "u1", Alert: models.Alert{Rule: "open-prs", State: models.AlertFiring, Since: time.Unix(300, 0)}}
+	if err := tracked.SendAlert(context.Background(), alert); err != nil {
+		t.Fatalf("SendAlert returned err: %v", err)
+	}
+	if got := recorder.attempts[3]; got.Event != models.NotificationEventAlert || got.Status != models.NotificationSent || len(got.PullRequestIDs) != 0 {
+		t.Fatalf("unexpected alert attempt: %+v", got)
+	}
+}
@@ -0,0 +1,115 @@
+package oidc
+
+import (
+	"context"
+	"crypto"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"io"
+	"log/slog"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+type testProvider struct {
+	server *httptest.Server
+	key    *rsa.PrivateKey
+	hits   int
+}
+
+func newTestProvider(t *testing.T) *testProvider {
+	t.Helper()
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatalf("generate key: %v", err)
+	}
+	p := &testProvider{key: key}
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /.well-known/openid-configuration", func(w http.ResponseWriter, r *http.Request) {
+		json.NewEncoder(w).Encode(map[string]string{"issuer": p.server.URL, "jwks_uri": p.server.URL + "/jwks"})
+	})
+	mux.HandleFunc("GET /jwks", func(w http.ResponseWriter, r *http.Request) {
+		p.hits++
+		json.NewEncoder(w).Encode(map[string]any{"keys": []map[string]string{{
+			"kty": "RSA",
+			"kid": "k1",
+			"use": "sig",
+			"n":   base64.RawURLEncoding.EncodeToString(key.N.Bytes()),
+			"e":   base64.RawURLEncoding.EncodeToString(big.NewInt(int64(key.E)).Bytes()),
+		}}})
+	})
+	p.server = httptest.NewServer(mux)
+	t.Cleanup(p.server.Close)
+	return p
+}
+
+func (p *testProvider) sign(t *testing.T, kid string, claims map[string]any) string {
+	t.Helper()
+	head, _ := json.Marshal(map[string]string{"alg": "RS256", "kid": kid, "typ": "JWT"})
+	body, _ := json.Marshal(claims)
+	signing := base64.RawURLEncoding.EncodeToString(head) + "." + base64.RawURLEncoding.EncodeToString(body)
+	digest := sha256.Sum256([]byte(signing))
+	sig, err := rsa.SignPKCS1v15(rand.Reader, p.key, crypto.SHA256, digest[:])
+	if err != nil {
+		t.Fatalf("sign: %v", err)
+	}
+	return signing + "." + base64.RawURLEncoding.EncodeToString(sig)
+}
+
+func TestVerifier_Verify(t *testing.T) {
+	p := newTestProvider(t)
+	v, err := NewVerifier(p.server.URL, "pr-reviewer", p.server.Client(), slog.New(slog.NewTextHandler(io.Discard, nil)))
+	if err != nil {
+		t.Fatalf("NewVerifier returned err: %v", err)
+	}
+	now := time.Now()
+	valid := map[string]any{
+		"iss":                p.server.URL,
+		"sub":                "abc-123",
+		"aud":                []string{"other", "pr-reviewer"},
+		"exp":                now.Add(time.Hour).Unix(),
+		"preferred_username": "alice",
+	}
+
+	claims, err := v.Verify(context.Background(), p.sign(t, "k1", valid))
+	if err != nil {
+		t.Fatalf("Verify returned err: %v", err)
+	}
+	if claims.Subject != "abc-123" || claims.PreferredUsername != "alice" {
+		t.Fatalf("unexpected claims: %+v", claims)
+	}
+
+	expired := map[string]any{"iss": p.server.URL, "sub": "abc-123", "aud": "pr-reviewer", "exp": now.Add(-time.Hour).Unix()}
+	if _, err := v.Verify(context.Background(), p.sign(t, "k1", expired)); !errors.Is(err, ErrExpiredToken) {
+		t.Fatalf("expected ErrExpiredToken, got %v", err)
+	}
+	wrongAud := map[string]any{"iss": p.server.URL, "sub": "abc-123", "aud": "someone-else", "exp": now.Add(time.Hour).Unix()}
+	if _, err := v.Verify(context.Background(), p.sign(t, "k1", wrongAud)); !errors.Is(err, ErrInvalidToken) {
+		t.Fatalf("expected ErrInvalidToken for audience, got %v", err)
+	}
+	tampered := p.sign(t, "k1", valid)
+	tampered = tampered[:len(tampered)-4] + "AAAA"
+	if _, err := v.Verify(context.Background(), tampered); !errors.Is(err, ErrInvalidToken) {
+		t.Fatalf("expected ErrInvalidToken for bad signature, got %v", err)
+	}
+	if _, err := v.Verify(context.Background(), p.sign(t, "k2", valid)); !errors.Is(err, ErrInvalidToken) {
+		t.Fatalf("expected ErrInvalidToken for unknown key, got %v", err)
+	}
+	if p.hits != 1 {
+		t.Fatalf("expected keys to be refetched at most once a minute, got %d fetches", p.hits)
+	}
+	v.now = func() time.Time { return now.Add(2 * time.Minute) }
+	if _, err := v.Verify(context.Background(), p.sign(t, "k2", valid)); !errors.Is(err, ErrInvalidToken) {
+		t.Fatalf("expected ErrInvalidToken for unknown key, got %v", err)
+	}
+	if p.hits != 2 {
+		t.Fatalf("expected refetch for unknown key, got %d fetches", p.hits)
+	}
+}
@@ -0,0 +1,190 @@
+// Package piilog hides user identifiers in log records for deployments with
+// stricter privacy rules. Audit tables are not affected: they keep the
+// identifiers, the logs only get a stable hash or a placeholder.
+package piilog
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/url"
+	"strings"
+)
+
+const (
+	ModeOff    = "off"
+	ModeHash   = "hash"
+	ModeRedact = "redact"
+
+	redacted = "[REDACTED]"
+	// urlAttr is the attribute the request log writes the URL to; query
+	// parameters named like a masked field are masked inside it.
+	urlAttr = "url"
+)
+
+// DefaultFields are the attribute keys the service logs user identifiers
+// under.
+var DefaultFields = []string{
+	"user_id",
+	"username",
+	"author_id",
+	"reviewer_id",
+	"actor_id",
+	"subject",
+	"subject_id",
+	"target",
+	"replaced_by",
+	"remote_addr",
+}
+
+type Config struct {
+	Mode     string
+	Fields   []string
+	MinLevel slog.Level
+	// HashKey keys the hash, so short identifiers cannot be recovered by
+	// hashing every candidate. Without it a plain SHA-256 is used.
+	HashKey string
+}
+
+// Handler masks the configured attributes of records at MinLevel and above
+// and passes lower records through unchanged, so debug logs stay usable
+// where they are enabled. Attributes bound with WithAttrs are masked once,
+// on a second copy of the wrapped handler.
+type Handler struct {
+	plain  slog.Handler
+	masked slog.Handler
+	policy *policy
+}
+
+type policy struct {
+	fields   map[string]struct{}
+	minLevel slog.Level
+	mask     func(string) string
+}
+
+// New wraps next with the policy. With ModeOff it returns next as is.
+func New(next slog.Handler, cfg Config) (slog.Handler, error) {
+	if next == nil {
+		return nil, errors.New("handler cannot be nil")
+	}
+	p := &policy{minLevel: cfg.MinLevel}
+	switch strings.ToLower(strings.TrimSpace(cfg.Mode)) {
+	case "", ModeOff:
+		return next, nil
+	case ModeHash:
+		key := []byte(cfg.HashKey)
+		p.mask = func(v string) string { return hashValue(key, v) }
+	case ModeRedact:
+		p.mask = func(string) string { return redacted }
+	default:
+		return nil, fmt.Errorf("unknown pii log mode %q, use %s, %s or %s", cfg.Mode, ModeOff, ModeHash, ModeRedact)
+	}
+	fields := cfg.Fields
+	if len(fields) == 0 {
+		fields = DefaultFields
+	}
+	p.fields = make(map[string]struct{}, len(fields))
+	for _, f := range fields {
+		if f = strings.ToLower(strings.TrimSpace(f)); f != "" {
+			p.fields[f] = struct{}{}
+		}
+	}
+	return &Handler{plain: next, masked: next, policy: p}, nil
+}
+
+func (h *Handler) Enabled(ctx context.Context, level slog.Level) bool {
+	return h.plain.Enabled(ctx, level)
+}
+
+func (h *Handler) Handle(ctx context.Context, r slog.Record) error {
+	if r.Level < h.policy.minLevel {
+		return h.plain.Handle(ctx, r)
+	}
+	masked := slog.NewRecord(r.Time, r.Level, r.Message, r.PC)
+	r.Attrs(func(a slog.Attr) bool {
+		masked.AddAttrs(h.policy.attr(a))
+		return true
+	})
+	return h.masked.Handle(ctx, masked)
+}
+
+func (h *Handler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	masked := make([]slog.Attr, len(attrs))
+	for i, a := range attrs {
+		masked[i] = h.policy.attr(a)
+	}
+	return &Handler{plain: h.plain.WithAttrs(attrs), masked: h.masked.WithAttrs(masked), policy: h.policy}
+}
+
+func (h *Handler) WithGroup(name string) slog.Handler {
+	return &Handler{plain: h.plain.WithGroup(name), masked: h.masked.WithGroup(name), policy: h.policy}
+}
+
+func (p *policy) attr(a slog.Attr) slog.Attr {
+	a.Value = a.Value.Resolve()
+	if a.Value.Kind() == slog.KindGroup {
+		group := a.Value.Group()
+		masked := make([]slog.Attr, len(group))
+		for i, g := range group {
+			masked[i] = p.attr(g)
+		}
+		return slog.Attr{Key: a.Key, Value: slog.GroupValue(masked...)}
+	}
+	key := strings.ToLower(a.Key)
+	if key == urlAttr && a.Value.Kind() == slog.KindString {
+		return slog.String(a.Key, p.url(a.Value.String()))
+	}
+	if _, ok := p.fields[key]; !ok {
+		return a
+	}
+	if ids, ok := a.Value.Any().([]string); ok {
+		masked := make([]string, len(ids))
+		for i, id := range ids {
+			masked[i] = p.mask(id)
+		}
+		return slog.Any(a.Key, masked)
+	}
+	return slog.String(a.Key, p.mask(a.Value.String()))
+}
+
+// url masks query parameters in place, keeping their order and leaving the
+// masks unescaped so they read the same as in other attributes.
+func (p *policy) url(raw string) string {
+	path, query, ok := strings.Cut(raw, "?")
+	if !ok || query == "" {
+		return raw
+	}
+	params := strings.Split(query, "&")
+	for i, param := range params {
+		name, value, _ := strings.Cut(param, "=")
+		key, err := url.QueryUnescape(name)
+		if err != nil {
+			continue
+		}
+		if _, masked := p.fields[strings.ToLower(key)]; !masked {
+			continue
+		}
+		if v, err := url.QueryUnescape(value); err == nil {
+			value = v
+		}
+		params[i] = name + "=" + p.mask(value)
+	}
+	return path + "?" + strings.Join(params, "&")
+}
+
+func hashValue(key []byte, value string) string {
+	var sum []byte
+	if len(key) > 0 {
+		mac := hmac.New(sha256.New, key)
+		mac.Write([]byte(value))
+		sum = mac.Sum(nil)
+	} else {
+		s := sha256.Sum256([]byte(value))
+		sum = s[:]
+	}
+	return "h:" + hex.EncodeToString(sum[:6])
+}
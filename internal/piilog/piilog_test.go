@@ -0,0 +1,79 @@
+package piilog
+
+import (
+	"bytes"
+	"encoding/json"
+	"log/slog"
+	"strings"
+	"testing"
+)
+
+func newTestLogger(t *testing.T, cfg Config) (*slog.Logger, *bytes.Buffer) {
+	t.Helper()
+	var buf bytes.Buffer
+	handler, err := New(slog.NewJSONHandler(&buf, &slog.HandlerOptions{Level: slog.LevelDebug}), cfg)
+	if err != nil {
+		t.Fatalf("New returned err: %v", err)
+	}
+	return slog.New(handler), &buf
+}
+
+func lastRecord(t *testing.T, buf *bytes.Buffer) map[string]any {
+	t.Helper()
+	lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+	var record map[string]any
+	if err := json.Unmarshal([]byte(lines[len(lines)-1]), &record); err != nil {
+		t.Fatalf("decode record: %v", err)
+	}
+	return record
+}
+
+func TestHandler_Hash(t *testing.T) {
+	log, buf := newTestLogger(t, Config{Mode: ModeHash, MinLevel: slog.LevelInfo, HashKey: "pepper"})
+
+	log.With(slog.String("actor_id", "admin")).Info("reviewer added",
+		slog.String("pr_id", "pr-1"),
+		slog.String("user_id", "u1"),
+		slog.Group("old", slog.String("reviewer_id", "u2")),
+		slog.String("url", "/users/getReview?user_id=u1&limit=5"),
+	)
+	record := lastRecord(t, buf)
+	hashed := hashValue([]byte("pepper"), "u1")
+	if record["pr_id"] != "pr-1" || record["user_id"] != hashed || record["actor_id"] != hashValue([]byte("pepper"), "admin") {
+		t.Fatalf("unexpected record: %v", record)
+	}
+	if old := record["old"].(map[string]any); old["reviewer_id"] != hashValue([]byte("pepper"), "u2") {
+		t.Fatalf("expected grouped attribute to be hashed, got %v", old)
+	}
+	if record["url"] != "/users/getReview?user_id="+hashed+"&limit=5" {
+		t.Fatalf("unexpected url: %v", record["url"])
+	}
+	if hashed == hashValue(nil, "u1") || !strings.HasPrefix(hashed, "h:") {
+		t.Fatalf("expected a keyed hash, got %s", hashed)
+	}
+
+	log.Debug("candidates", slog.String("user_id", "u1"))
+	if record := lastRecord(t, buf); record["user_id"] != "u1" {
+		t.Fatalf("expected debug records below min level to stay intact, got %v", record)
+	}
+}
+
+func TestHandler_RedactCustomFields(t *testing.T) {
+	log, buf := newTestLogger(t, Config{Mode: ModeRedact, Fields: []string{"Email"}})
+
+	log.Warn("login", slog.String("email", "a@example.com"), slog.String("user_id", "u1"))
+	record := lastRecord(t, buf)
+	if record["email"] != redacted || record["user_id"] != "u1" {
+		t.Fatalf("unexpected record: %v", record)
+	}
+}
+
+func TestNew_Modes(t *testing.T) {
+	next := slog.NewTextHandler(&bytes.Buffer{}, nil)
+	if h, err := New(next, Config{Mode: ModeOff}); err != nil || h != next {
+		t.Fatalf("expected off to return the handler as is, got %v, %v", h, err)
+	}
+	if _, err := New(next, Config{Mode: "encrypt"}); err == nil {
+		t.Fatalf("expected error for unknown mode")
+	}
+}
@@ -0,0 +1,75 @@
+package reqsign
+
+import (
+	"errors"
+	"strconv"
+	"testing"
+	"time"
+)
+
+const (
+	testSecret  = "0123456789abcdef0123456789abcdef"
+	otherSecret = "fedcba9876543210fedcba9876543210"
+)
+
+func newTestVerifier(t *testing.T) (*Verifier, time.Time) {
+	t.Helper()
+	v, err := NewVerifier(5 * time.Minute)
+	if err != nil {
+		t.Fatalf("NewVerifier returned err: %v", err)
+	}
+	if err := v.SetSecret("billing", testSecret); err != nil {
+		t.Fatalf("SetSecret returned err: %v", err)
+	}
+	now := time.Date(2025, 3, 1, 10, 0, 0, 0, time.UTC)
+	v.now = func() time.Time { return now }
+	return v, now
+}
+
+func TestVerifier_Verify(t *testing.T) {
+	v, now := newTestVerifier(t)
+	ts := strconv.FormatInt(now.Unix(), 10)
+	body := []byte(`{"pull_request_id":"pr-1"}`)
+	sig := Sign(testSecret, ts, "POST", "/pullRequest/merge", body)
+
+	if err := v.Verify("billing", ts, sig, "POST", "/pullRequest/merge", body); err != nil {
+		t.Fatalf("expected valid signature, got %v", err)
+	}
+	stale := strconv.FormatInt(now.Add(-10*time.Minute).Unix(), 10)
+	cases := []struct {
+		name     string
+		client   string
+		ts       string
+		sig      string
+		uri      string
+		body     []byte
+		expected error
+	}{
+		{"unknown client", "crm", ts, sig, "/pullRequest/merge", body, ErrUnknownClient},
+		{"other uri", "billing", ts, sig, "/pullRequest/close", body, ErrInvalidSignature},
+		{"other body", "billing", ts, sig, "/pullRequest/merge", []byte(`{}`), ErrInvalidSignature},
+		{"not hex", "billing", ts, "zz", "/pullRequest/merge", body, ErrInvalidSignature},
+		{"stale", "billing", stale, Sign(testSecret, stale, "POST", "/pullRequest/merge", body), "/pullRequest/merge", body, ErrStaleSignature},
+	}
+	for _, tc := range cases {
+		if err := v.Verify(tc.client, tc.ts, tc.sig, "POST", tc.uri, tc.body); !errors.Is(err, tc.expected) {
+			t.Fatalf("%s: expected %v, got %v", tc.name, tc.expected, err)
+		}
+	}
+}
+
+func TestVerifier_SetSecretRotates(t *testing.T) {
+	v, now := newTestVerifier(t)
+	ts := strconv.FormatInt(now.Unix(), 10)
+	if err := v.SetSecret("billing", otherSecret); err != nil {
+		t.Fatalf("SetSecret returned err: %v", err)
+	}
+	for _, secret := range []string{testSecret, otherSecret} {
+		if err := v.Verify("billing", ts, Sign(secret, ts, "GET", "/ping", nil), "GET", "/ping", nil); err != nil {
+			t.Fatalf("expected both secrets to be accepted during rotation, got %v", err)
+		}
+	}
+	if err := v.SetSecret("billing", "short"); err == nil {
+		t.Fatalf("expected error for short secret")
+	}
+}
@@ -0,0 +1,85 @@
+package scheduler
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"sync"
+	"time"
+)
+
+type Job func(ctx context.Context) error
+
+type task struct {
+	name     string
+	interval time.Duration
+	run      Job
+}
+
+type Scheduler struct {
+	tasks  []task
+	cancel context.CancelFunc
+	wg     sync.WaitGroup
+	log    *slog.Logger
+}
+
+func New(log *slog.Logger) (*Scheduler, error) {
+	if log == nil {
+		return nil, errors.New("logger cannot be nil")
+	}
+	return &Scheduler{log: log}, nil
+}
+
+func (s *Scheduler) Add(name string, interval time.Duration, job Job) error {
+	if name == "" {
+		return errors.New("job name cannot be empty")
+	}
+	if interval <= 0 {
+		return errors.New("job interval must be positive")
+	}
+	if job == nil {
+		return errors.New("job cannot be nil")
+	}
+	s.tasks = append(s.tasks, task{name: name, interval: interval, run: job})
+	return nil
+}
+
+func (s *Scheduler) Start(ctx context.Context) {
+	ctx, s.cancel = context.WithCancel(ctx)
+	for _, t := range s.tasks {
+		s.wg.Add(1)
+		go s.loop(ctx, t)
+	}
+}
+
+func (s *Scheduler) Stop() {
+	if s.cancel != nil {
+		s.cancel()
+	}
+	s.wg.Wait()
+}
+
+func (s *Scheduler) loop(ctx context.Context, t task) {
+	defer s.wg.Done()
+	ticker := time.NewTicker(t.interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			s.runOnce(ctx, t)
+		}
+	}
+}
+
+func (s *Scheduler) runOnce(ctx context.Context, t task) {
+	defer func() {
+		if err := recover(); err != nil {
+			s.log.Error("scheduled job panicked", slog.String("job", t.name), slog.Any("error", err))
+		}
+	}()
+	if err := t.run(ctx); err != nil && !errors.Is(err, context.Canceled) {
+		s.log.Error("scheduled job failed", slog.String("job", t.name), slog.Any("error", err))
+	}
+}
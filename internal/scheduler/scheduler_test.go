@@ -0,0 +1,82 @@
+package scheduler
+
+import (
+	"context"
+	"errors"
+	"io"
+	"log/slog"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func testLogger() *slog.Logger {
+	return slog.New(slog.NewTextHandler(io.Discard, nil))
+}
+
+func TestScheduler_RunsJobsUntilStopped(t *testing.T) {
+	s, err := New(testLogger())
+	if err != nil {
+		t.Fatalf("New returned err: %v", err)
+	}
+	var runs atomic.Int32
+	if err := s.Add("count", 5*time.Millisecond, func(context.Context) error {
+		runs.Add(1)
+		return nil
+	}); err != nil {
+		t.Fatalf("Add returned err: %v", err)
+	}
+
+	s.Start(context.Background())
+	deadline := time.Now().Add(time.Second)
+	for runs.Load() < 2 {
+		if time.Now().After(deadline) {
+			t.Fatalf("job was not run periodically")
+		}
+		time.Sleep(time.Millisecond)
+	}
+	s.Stop()
+
+	stopped := runs.Load()
+	time.Sleep(20 * time.Millisecond)
+	if runs.Load() != stopped {
+		t.Fatalf("job kept running after Stop")
+	}
+}
+
+func TestScheduler_SurvivesFailingJobs(t *testing.T) {
+	s, err := New(testLogger())
+	if err != nil {
+		t.Fatalf("New returned err: %v", err)
+	}
+	var runs atomic.Int32
+	_ = s.Add("failing", 5*time.Millisecond, func(context.Context) error {
+		if runs.Add(1) == 1 {
+			panic("boom")
+		}
+		return errors.New("job error")
+	})
+
+	s.Start(context.Background())
+	defer s.Stop()
+	deadline := time.Now().Add(time.Second)
+	for runs.Load() < 3 {
+		if time.Now().After(deadline) {
+			t.Fatalf("scheduler stopped after a failing job")
+		}
+		time.Sleep(time.Millisecond)
+	}
+}
+
+func TestScheduler_AddValidation(t *testing.T) {
+	s, _ := New(testLogger())
+	if err := s.Add("", time.Second, func(context.Context) error { return nil }); err == nil {
+		t.Fatalf("expected error for empty name")
+	}
+	if err := s.Add("job", 0, func(context.Context) error { return nil }); err == nil {
+		t.Fatalf("expected error for zero interval")
+	}
+	if err := s.Add("job", time.Second, nil); err == nil {
+		t.Fatalf("expected error for nil job")
+	}
+}
@@ -0,0 +1,63 @@
+package secrets
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+)
+
+const (
+	filePrefix  = "file:"
+	vaultPrefix = "vault:"
+)
+
+var ErrVaultNotConfigured = errors.New("vault is not configured")
+
+// Resolver turns config values into secrets. A value is either used as is,
+// read from a file ("file:/run/secrets/db_url") or read from Vault
+// ("vault:secret/data/pr-reviewer#db_url").
+type Resolver struct {
+	vault *VaultClient
+}
+
+func NewResolver(vault *VaultClient) *Resolver {
+	return &Resolver{vault: vault}
+}
+
+func (r *Resolver) Resolve(ctx context.Context, ref string) (string, error) {
+	switch {
+	case strings.HasPrefix(ref, filePrefix):
+		path := strings.TrimPrefix(ref, filePrefix)
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return "", fmt.Errorf("read secret file: %w", err)
+		}
+		return strings.TrimRight(string(data), "\r\n"), nil
+	case strings.HasPrefix(ref, vaultPrefix):
+		if r.vault == nil {
+			return "", ErrVaultNotConfigured
+		}
+		path, field, ok := strings.Cut(strings.TrimPrefix(ref, vaultPrefix), "#")
+		if !ok || path == "" || field == "" {
+			return "", fmt.Errorf("vault reference %q must look like vault:<path>#<field>", ref)
+		}
+		return r.vault.Read(ctx, path, field)
+	default:
+		return ref, nil
+	}
+}
+
+// Load resolves ref and returns a secret that can be re-read later.
+func (r *Resolver) Load(ctx context.Context, ref string) (*Secret, error) {
+	value, err := r.Resolve(ctx, ref)
+	if err != nil {
+		return nil, err
+	}
+	return &Secret{ref: ref, resolver: r, value: value}, nil
+}
+
+func isDynamic(ref string) bool {
+	return strings.HasPrefix(ref, filePrefix) || strings.HasPrefix(ref, vaultPrefix)
+}
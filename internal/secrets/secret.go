@@ -0,0 +1,109 @@
+package secrets
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"maps"
+	"sync"
+)
+
+type Secret struct {
+	ref      string
+	resolver *Resolver
+
+	mu       sync.RWMutex
+	value    string
+	onChange []func(value string)
+}
+
+func (s *Secret) Value() string {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.value
+}
+
+// OnChange registers fn to be called with the new value after a refresh
+// picks up a different secret.
+func (s *Secret) OnChange(fn func(value string)) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.onChange = append(s.onChange, fn)
+}
+
+// Refresh re-reads the secret from its source and reports whether it changed.
+func (s *Secret) Refresh(ctx context.Context) (bool, error) {
+	if !isDynamic(s.ref) {
+		return false, nil
+	}
+	value, err := s.resolver.Resolve(ctx, s.ref)
+	if err != nil {
+		return false, err
+	}
+	s.mu.Lock()
+	if value == s.value {
+		s.mu.Unlock()
+		return false, nil
+	}
+	s.value = value
+	callbacks := append([]func(string){}, s.onChange...)
+	s.mu.Unlock()
+
+	for _, fn := range callbacks {
+		fn(value)
+	}
+	return true, nil
+}
+
+type Watcher struct {
+	mu      sync.Mutex
+	secrets map[string]*Secret
+	log     *slog.Logger
+}
+
+func NewWatcher(log *slog.Logger) (*Watcher, error) {
+	if log == nil {
+		return nil, errors.New("logger cannot be nil")
+	}
+	return &Watcher{
+		secrets: make(map[string]*Secret),
+		log:     log,
+	}, nil
+}
+
+// Watch adds a secret to the periodic re-read. Literal values never change
+// and are skipped.
+func (w *Watcher) Watch(name string, secret *Secret) {
+	if !isDynamic(secret.ref) {
+		return
+	}
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	w.secrets[name] = secret
+}
+
+func (w *Watcher) Len() int {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	return len(w.secrets)
+}
+
+func (w *Watcher) Refresh(ctx context.Context) error {
+	w.mu.Lock()
+	watched := maps.Clone(w.secrets)
+	w.mu.Unlock()
+
+	var errs []error
+	for name, secret := range watched {
+		changed, err := secret.Refresh(ctx)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("refresh secret %s: %w", name, err))
+			continue
+		}
+		if changed {
+			w.log.Info("secret changed", slog.String("name", name))
+		}
+	}
+	return errors.Join(errs...)
+}
@@ -0,0 +1,114 @@
+package secrets
+
+import (
+	"context"
+	"errors"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestResolver_File(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "db_url")
+	if err := os.WriteFile(path, []byte("postgres://u:p@db/app\n"), 0o600); err != nil {
+		t.Fatalf("write secret: %v", err)
+	}
+	r := NewResolver(nil)
+
+	got, err := r.Resolve(context.Background(), "file:"+path)
+	if err != nil || got != "postgres://u:p@db/app" {
+		t.Fatalf("expected trimmed file contents, got %q, %v", got, err)
+	}
+	if got, _ := r.Resolve(context.Background(), "plain"); got != "plain" {
+		t.Fatalf("expected literal value, got %q", got)
+	}
+	if _, err := r.Resolve(context.Background(), "vault:secret/data/app#key"); !errors.Is(err, ErrVaultNotConfigured) {
+		t.Fatalf("expected ErrVaultNotConfigured, got %v", err)
+	}
+}
+
+func TestResolver_Vault(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("X-Vault-Token") != "root" {
+			w.WriteHeader(http.StatusForbidden)
+			return
+		}
+		switch r.URL.Path {
+		case "/v1/secret/data/app":
+			io.WriteString(w, `{"data":{"data":{"db_url":"postgres://v2"},"metadata":{"version":3}}}`)
+		case "/v1/kv/app":
+			io.WriteString(w, `{"data":{"db_url":"postgres://v1"}}`)
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer srv.Close()
+
+	vault, err := NewVaultClient(srv.URL, "root", srv.Client())
+	if err != nil {
+		t.Fatalf("NewVaultClient returned err: %v", err)
+	}
+	r := NewResolver(vault)
+	ctx := context.Background()
+
+	if got, err := r.Resolve(ctx, "vault:secret/data/app#db_url"); err != nil || got != "postgres://v2" {
+		t.Fatalf("expected kv v2 value, got %q, %v", got, err)
+	}
+	if got, err := r.Resolve(ctx, "vault:kv/app#db_url"); err != nil || got != "postgres://v1" {
+		t.Fatalf("expected kv v1 value, got %q, %v", got, err)
+	}
+	if _, err := r.Resolve(ctx, "vault:kv/app#missing"); err == nil {
+		t.Fatal("expected error for missing field")
+	}
+	if _, err := r.Resolve(ctx, "vault:kv/other#db_url"); err == nil {
+		t.Fatal("expected error for missing secret")
+	}
+}
+
+func TestWatcher_Refresh(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "key")
+	if err := os.WriteFile(path, []byte("v1"), 0o600); err != nil {
+		t.Fatalf("write secret: %v", err)
+	}
+	r := NewResolver(nil)
+	ctx := context.Background()
+	secret, err := r.Load(ctx, "file:"+path)
+	if err != nil {
+		t.Fatalf("Load returned err: %v", err)
+	}
+	var seen []string
+	secret.OnChange(func(value string) { seen = append(seen, value) })
+
+	w, err := NewWatcher(slog.New(slog.NewTextHandler(io.Discard, nil)))
+	if err != nil {
+		t.Fatalf("NewWatcher returned err: %v", err)
+	}
+	w.Watch("key", secret)
+	literal, _ := r.Load(ctx, "inline")
+	w.Watch("inline", literal)
+	if w.Len() != 1 {
+		t.Fatalf("expected only file secret to be watched, got %d", w.Len())
+	}
+
+	if err := w.Refresh(ctx); err != nil || len(seen) != 0 {
+		t.Fatalf("expected no change, got %v, %v", seen, err)
+	}
+	if err := os.WriteFile(path, []byte("v2"), 0o600); err != nil {
+		t.Fatalf("write secret: %v", err)
+	}
+	if err := w.Refresh(ctx); err != nil {
+		t.Fatalf("Refresh returned err: %v", err)
+	}
+	if secret.Value() != "v2" || len(seen) != 1 || seen[0] != "v2" {
+		t.Fatalf("expected change to v2, got value %q, callbacks %v", secret.Value(), seen)
+	}
+
+	os.Remove(path)
+	if err := w.Refresh(ctx); err == nil || secret.Value() != "v2" {
+		t.Fatalf("expected error and kept value, got %q, %v", secret.Value(), err)
+	}
+}
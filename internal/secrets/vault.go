@@ -0,0 +1,78 @@
+package secrets
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+)
+
+type VaultClient struct {
+	addr   string
+	token  string
+	client *http.Client
+}
+
+func NewVaultClient(addr, token string, client *http.Client) (*VaultClient, error) {
+	addr = strings.TrimSuffix(strings.TrimSpace(addr), "/")
+	if addr == "" {
+		return nil, errors.New("vault address cannot be empty")
+	}
+	if token == "" {
+		return nil, errors.New("vault token cannot be empty")
+	}
+	if client == nil {
+		client = &http.Client{Timeout: 5 * time.Second}
+	}
+	return &VaultClient{
+		addr:   addr,
+		token:  token,
+		client: client,
+	}, nil
+}
+
+// Read returns a field of a KV secret. Both KV v1 and v2 (data nested under
+// data.data) layouts are supported.
+func (c *VaultClient) Read(ctx context.Context, path, field string) (string, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, c.addr+"/v1/"+strings.TrimPrefix(path, "/"), nil)
+	if err != nil {
+		return "", fmt.Errorf("build vault request: %w", err)
+	}
+	req.Header.Set("X-Vault-Token", c.token)
+	resp, err := c.client.Do(req)
+	if err != nil {
+		return "", fmt.Errorf("read vault secret %s: %w", path, err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return "", fmt.Errorf("read vault secret %s: unexpected status %d", path, resp.StatusCode)
+	}
+
+	var body struct {
+		Data map[string]json.RawMessage `json:"data"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&body); err != nil {
+		return "", fmt.Errorf("decode vault secret %s: %w", path, err)
+	}
+	data := body.Data
+	if nested, ok := data["data"]; ok {
+		if _, isMeta := data["metadata"]; isMeta {
+			data = nil
+			if err := json.Unmarshal(nested, &data); err != nil {
+				return "", fmt.Errorf("decode vault secret %s: %w", path, err)
+			}
+		}
+	}
+	raw, ok := data[field]
+	if !ok {
+		return "", fmt.Errorf("vault secret %s has no field %q", path, field)
+	}
+	var value string
+	if err := json.Unmarshal(raw, &value); err != nil {
+		return "", fmt.Errorf("vault secret %s field %q is not a string", path, field)
+	}
+	return value, nil
+}
@@ -0,0 +1,182 @@
+package service
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"log/slog"
+	"strings"
+	"time"
+
+	"github.com/cloudyy74/pr-reviewer-service/internal/models"
+	"github.com/cloudyy74/pr-reviewer-service/internal/storage"
+)
+
+const (
+	minPasswordLength = 8
+	sessionTokenBytes = 32
+)
+
+var (
+	ErrAuthValidation     = errors.New("validation error")
+	ErrInvalidCredentials = errors.New("invalid username or password")
+	ErrSessionInvalid     = errors.New("session is invalid or expired")
+)
+
+type AuthUserRepository interface {
+	GetUserWithTeam(ctx context.Context, userID string) (*models.UserWithTeam, error)
+	GetUsersByUsername(ctx context.Context, username string) ([]*models.UserWithTeam, error)
+}
+
+type SessionRepository interface {
+	SetPasswordHash(ctx context.Context, userID, hash string) error
+	GetPasswordHash(ctx context.Context, userID string) (string, error)
+	CreateSession(ctx context.Context, id, userID, csrfToken string, expiresAt time.Time) error
+	GetSession(ctx context.Context, id string) (*models.Session, error)
+	DeleteSession(ctx context.Context, id string) error
+}
+
+type AuthService struct {
+	users    AuthUserRepository
+	sessions SessionRepository
+	ttl      time.Duration
+	now      func() time.Time
+	log      *slog.Logger
+}
+
+func NewAuthService(users AuthUserRepository, sessions SessionRepository, ttl time.Duration, log *slog.Logger) (*AuthService, error) {
+	if users == nil {
+		return nil, errors.New("users repository cannot be nil")
+	}
+	if sessions == nil {
+		return nil, errors.New("sessions repository cannot be nil")
+	}
+	if ttl <= 0 {
+		return nil, errors.New("session ttl must be positive")
+	}
+	if log == nil {
+		return nil, errors.New("logger cannot be nil")
+	}
+	return &AuthService{
+		users:    users,
+		sessions: sessions,
+		ttl:      ttl,
+		now:      time.Now,
+		log:      log,
+	}, nil
+}
+
+func (s *AuthService) SetPassword(ctx context.Context, userID, password string) error {
+	userID, err := resolveUserRef(ctx, s.users, userID)
+	if err != nil {
+		return err
+	}
+	if userID == "" {
+		return fmt.Errorf("%w: user_id is required", ErrAuthValidation)
+	}
+	if len(password) < minPasswordLength {
+		return fmt.Errorf("%w: password must be at least %d characters", ErrAuthValidation, minPasswordLength)
+	}
+	if _, err := s.users.GetUserWithTeam(ctx, userID); err != nil {
+		if errors.Is(err, storage.ErrUserNotFound) {
+			return ErrUserNotFound
+		}
+		return fmt.Errorf("get user: %w", err)
+	}
+
+	hash, err := hashPassword(password)
+	if err != nil {
+		return fmt.Errorf("hash password: %w", err)
+	}
+	if err := s.sessions.SetPasswordHash(ctx, userID, hash); err != nil {
+		return fmt.Errorf("set password: %w", err)
+	}
+	return nil
+}
+
+func (s *AuthService) Login(ctx context.Context, username, password string) (string, *models.Session, error) {
+	username = strings.TrimPrefix(strings.TrimSpace(username), usernameRefPrefix)
+	if username == "" || password == "" {
+		return "", nil, fmt.Errorf("%w: username and password are required", ErrAuthValidation)
+	}
+
+	user, err := findUserByUsername(ctx, s.users, username)
+	if err != nil {
+		if errors.Is(err, ErrUserNotFound) {
+			return "", nil, ErrInvalidCredentials
+		}
+		return "", nil, err
+	}
+	hash, err := s.sessions.GetPasswordHash(ctx, user.ID)
+	if err != nil {
+		if errors.Is(err, storage.ErrCredentialsNotFound) {
+			return "", nil, ErrInvalidCredentials
+		}
+		return "", nil, fmt.Errorf("get credentials: %w", err)
+	}
+	ok, err := verifyPassword(hash, password)
+	if err != nil {
+		s.log.Error("verify password failed", slog.Any("error", err), slog.String("user_id", user.ID))
+		return "", nil, fmt.Errorf("verify password: %w", err)
+	}
+	if !ok {
+		return "", nil, ErrInvalidCredentials
+	}
+
+	token, err := randomToken()
+	if err != nil {
+		return "", nil, err
+	}
+	csrf, err := randomToken()
+	if err != nil {
+		return "", nil, err
+	}
+	session := &models.Session{
+		ID:        sessionID(token),
+		User:      *user,
+		CSRFToken: csrf,
+		ExpiresAt: s.now().Add(s.ttl),
+	}
+	if err := s.sessions.CreateSession(ctx, session.ID, user.ID, csrf, session.ExpiresAt); err != nil {
+		return "", nil, fmt.Errorf("create session: %w", err)
+	}
+	return token, session, nil
+}
+
+func (s *AuthService) Authenticate(ctx context.Context, token string) (*models.Session, error) {
+	if token == "" {
+		return nil, ErrSessionInvalid
+	}
+	session, err := s.sessions.GetSession(ctx, sessionID(token))
+	if err != nil {
+		if errors.Is(err, storage.ErrSessionNotFound) {
+			return nil, ErrSessionInvalid
+		}
+		return nil, fmt.Errorf("get session: %w", err)
+	}
+	return session, nil
+}
+
+func (s *AuthService) Logout(ctx context.Context, token string) error {
+	if err := s.sessions.DeleteSession(ctx, sessionID(token)); err != nil {
+		return fmt.Errorf("logout: %w", err)
+	}
+	return nil
+}
+
+func sessionID(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
+
+func randomToken() (string, error) {
+	buf := make([]byte, sessionTokenBytes)
+	if _, err := rand.Read(buf); err != nil {
+		return "", fmt.Errorf("generate token: %w", err)
+	}
+	return base64.RawURLEncoding.EncodeToString(buf), nil
+}
@@ -0,0 +1,113 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/cloudyy74/pr-reviewer-service/internal/models"
+	"github.com/cloudyy74/pr-reviewer-service/internal/storage"
+)
+
+type fakeSessionRepo struct {
+	hashes   map[string]string
+	sessions map[string]*models.Session
+}
+
+func newFakeSessionRepo() *fakeSessionRepo {
+	return &fakeSessionRepo{hashes: map[string]string{}, sessions: map[string]*models.Session{}}
+}
+
+func (f *fakeSessionRepo) SetPasswordHash(_ context.Context, userID, hash string) error {
+	f.hashes[userID] = hash
+	return nil
+}
+
+func (f *fakeSessionRepo) GetPasswordHash(_ context.Context, userID string) (string, error) {
+	hash, ok := f.hashes[userID]
+	if !ok {
+		return "", storage.ErrCredentialsNotFound
+	}
+	return hash, nil
+}
+
+func (f *fakeSessionRepo) CreateSession(_ context.Context, id, userID, csrf string, expiresAt time.Time) error {
+	f.sessions[id] = &models.Session{ID: id, User: models.UserWithTeam{User: models.User{ID: userID}}, CSRFToken: csrf, ExpiresAt: expiresAt}
+	return nil
+}
+
+func (f *fakeSessionRepo) GetSession(_ context.Context, id string) (*models.Session, error) {
+	session, ok := f.sessions[id]
+	if !ok {
+		return nil, storage.ErrSessionNotFound
+	}
+	return session, nil
+}
+
+func (f *fakeSessionRepo) DeleteSession(_ context.Context, id string) error {
+	delete(f.sessions, id)
+	return nil
+}
+
+func newTestAuthService(t *testing.T, sessions SessionRepository) *AuthService {
+	t.Helper()
+	users := &fakePRUserRepo{
+		getByUsernameFn: func(_ context.Context, username string) ([]*models.UserWithTeam, error) {
+			if username != "alice" {
+				return nil, nil
+			}
+			return []*models.UserWithTeam{{User: models.User{ID: "u1", Username: "alice"}}}, nil
+		},
+		getUserFn: func(_ context.Context, userID string) (*models.UserWithTeam, error) {
+			if userID != "u1" {
+				return nil, storage.ErrUserNotFound
+			}
+			return &models.UserWithTeam{User: models.User{ID: "u1", Username: "alice"}}, nil
+		},
+	}
+	service, err := NewAuthService(users, sessions, time.Hour, testLogger())
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	return service
+}
+
+func TestAuthService_LoginFlow(t *testing.T) {
+	repo := newFakeSessionRepo()
+	service := newTestAuthService(t, repo)
+	ctx := context.Background()
+
+	if err := service.SetPassword(ctx, "u1", "short"); !errors.Is(err, ErrAuthValidation) {
+		t.Fatalf("expected ErrAuthValidation for short password, got %v", err)
+	}
+	if err := service.SetPassword(ctx, "u1", "correct horse"); err != nil {
+		t.Fatalf("SetPassword returned err: %v", err)
+	}
+
+	if _, _, err := service.Login(ctx, "alice", "wrong password"); !errors.Is(err, ErrInvalidCredentials) {
+		t.Fatalf("expected ErrInvalidCredentials, got %v", err)
+	}
+	if _, _, err := service.Login(ctx, "mallory", "correct horse"); !errors.Is(err, ErrInvalidCredentials) {
+		t.Fatalf("expected ErrInvalidCredentials for unknown user, got %v", err)
+	}
+
+	token, session, err := service.Login(ctx, "alice", "correct horse")
+	if err != nil {
+		t.Fatalf("Login returned err: %v", err)
+	}
+	if token == "" || session.CSRFToken == "" || session.ID == token {
+		t.Fatalf("unexpected session: %q %+v", token, session)
+	}
+
+	got, err := service.Authenticate(ctx, token)
+	if err != nil || got.User.ID != "u1" {
+		t.Fatalf("Authenticate returned %+v, %v", got, err)
+	}
+	if err := service.Logout(ctx, token); err != nil {
+		t.Fatalf("Logout returned err: %v", err)
+	}
+	if _, err := service.Authenticate(ctx, token); !errors.Is(err, ErrSessionInvalid) {
+		t.Fatalf("expected ErrSessionInvalid after logout, got %v", err)
+	}
+}
@@ -0,0 +1,38 @@
+package service
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+)
+
+// ErrStalePreview is returned when a bulk operation is applied with a
+// change-set token that no longer matches the data it was previewed on.
+var ErrStalePreview = errors.New("preview is stale")
+
+// changeSetToken fingerprints what a bulk operation was planned from. The
+// token is stateless: apply recomputes it and compares, so nothing has to be
+// stored between the preview and the apply call.
+func changeSetToken(kind string, parts ...string) string {
+	h := sha256.New()
+	h.Write([]byte(kind))
+	for _, p := range parts {
+		h.Write([]byte{0})
+		h.Write([]byte(p))
+	}
+	return kind + "." + hex.EncodeToString(h.Sum(nil)[:16])
+}
+
+// checkChangeSet rejects an apply call without a token or with a token
+// computed from different data.
+func checkChangeSet(token, current string) error {
+	switch token {
+	case "":
+		return fmt.Errorf("%w: change_set_token is required, run a preview first", ErrPRValidation)
+	case current:
+		return nil
+	default:
+		return fmt.Errorf("%w: data changed since the preview, run it again", ErrStalePreview)
+	}
+}
@@ -0,0 +1,78 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"strings"
+	"time"
+
+	"github.com/cloudyy74/pr-reviewer-service/internal/models"
+)
+
+const (
+	defaultEventLogLimit = 100
+	maxEventLogLimit     = 1000
+	// eventLogSettle hides events of transactions that may still be
+	// committing, so a reader following the cursor does not skip them.
+	eventLogSettle = 5 * time.Second
+)
+
+var ErrEventLogValidation = errors.New("validation error")
+
+type EventLogRepository interface {
+	ListEvents(ctx context.Context, q models.EventLogQuery, settle time.Duration) ([]*models.LoggedEvent, error)
+}
+
+// EventLog exposes the outbox as an append-only log of business events for
+// incremental loads into a data warehouse.
+type EventLog struct {
+	events EventLogRepository
+	log    *slog.Logger
+}
+
+func NewEventLog(events EventLogRepository, log *slog.Logger) (*EventLog, error) {
+	if events == nil {
+		return nil, errors.New("event log repository cannot be nil")
+	}
+	if log == nil {
+		return nil, errors.New("logger cannot be nil")
+	}
+	return &EventLog{
+		events: events,
+		log:    log,
+	}, nil
+}
+
+// ListEvents returns the events after the q.Since cursor, oldest first.
+func (l *EventLog) ListEvents(ctx context.Context, q models.EventLogQuery) (*models.EventLogResponse, error) {
+	q.Topic = strings.TrimSpace(q.Topic)
+	if q.Since < 0 {
+		return nil, fmt.Errorf("%w: since cannot be negative", ErrEventLogValidation)
+	}
+	if q.Limit < 0 {
+		return nil, fmt.Errorf("%w: limit cannot be negative", ErrEventLogValidation)
+	}
+	if q.Limit == 0 {
+		q.Limit = defaultEventLogLimit
+	}
+	limit := min(q.Limit, maxEventLogLimit)
+	q.Limit = limit + 1
+
+	events, err := l.events.ListEvents(ctx, q, eventLogSettle)
+	if err != nil {
+		l.log.Error("list events failed", slog.Any("error", err))
+		return nil, fmt.Errorf("list events: %w", err)
+	}
+	resp := &models.EventLogResponse{NextCursor: q.Since}
+	if len(events) > limit {
+		events = events[:limit]
+		resp.HasMore = true
+	}
+	if len(events) > 0 {
+		resp.NextCursor = events[len(events)-1].ID
+	}
+	resp.Events = events
+	return resp, nil
+}
@@ -0,0 +1,60 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/cloudyy74/pr-reviewer-service/internal/models"
+)
+
+type fakeEventLogRepo struct {
+	events []*models.LoggedEvent
+	got    models.EventLogQuery
+}
+
+func (f *fakeEventLogRepo) ListEvents(_ context.Context, q models.EventLogQuery, _ time.Duration) ([]*models.LoggedEvent, error) {
+	f.got = q
+	var page []*models.LoggedEvent
+	for _, e := range f.events {
+		if e.ID > q.Since && len(page) < q.Limit {
+			page = append(page, e)
+		}
+	}
+	return page, nil
+}
+
+func TestEventLog_ListEvents(t *testing.T) {
+	repo := &fakeEventLogRepo{events: []*models.LoggedEvent{{ID: 3}, {ID: 5}, {ID: 9}}}
+	eventLog, err := NewEventLog(repo, testLogger())
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	ctx := context.Background()
+
+	resp, err := eventLog.ListEvents(ctx, models.EventLogQuery{Limit: 2, Topic: " pr.created "})
+	if err != nil {
+		t.Fatalf("ListEvents returned err: %v", err)
+	}
+	if len(resp.Events) != 2 || !resp.HasMore || resp.NextCursor != 5 || repo.got.Topic != "pr.created" {
+		t.Fatalf("unexpected first page: %+v", resp)
+	}
+
+	resp, err = eventLog.ListEvents(ctx, models.EventLogQuery{Since: resp.NextCursor, Limit: 2})
+	if err != nil {
+		t.Fatalf("ListEvents returned err: %v", err)
+	}
+	if len(resp.Events) != 1 || resp.HasMore || resp.NextCursor != 9 {
+		t.Fatalf("unexpected last page: %+v", resp)
+	}
+
+	resp, err = eventLog.ListEvents(ctx, models.EventLogQuery{Since: 9, Limit: 5000})
+	if err != nil || len(resp.Events) != 0 || resp.NextCursor != 9 || repo.got.Limit != maxEventLogLimit+1 {
+		t.Fatalf("unexpected empty page: %+v, %v", resp, err)
+	}
+
+	if _, err := eventLog.ListEvents(ctx, models.EventLogQuery{Since: -1}); !errors.Is(err, ErrEventLogValidation) {
+		t.Fatalf("expected validation error, got %v", err)
+	}
+}
@@ -0,0 +1,110 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"strings"
+
+	"github.com/cloudyy74/pr-reviewer-service/internal/models"
+	"github.com/cloudyy74/pr-reviewer-service/internal/storage"
+)
+
+var (
+	ErrExclusionValidation = errors.New("validation error")
+	ErrExclusionNotFound   = errors.New("reviewer exclusion not found")
+)
+
+type ExclusionRepository interface {
+	AddExclusion(ctx context.Context, e models.ReviewerExclusion) (*models.ReviewerExclusion, error)
+	ListExclusions(ctx context.Context, userID string) ([]*models.ReviewerExclusion, error)
+	DeleteExclusion(ctx context.Context, reviewerID, authorID string) error
+}
+
+// ExclusionService manages do-not-assign rules. The rules are enforced by
+// the reviewer pickers in storage, so they apply to creation, reassignment
+// and backfill alike.
+type ExclusionService struct {
+	exclusions ExclusionRepository
+	log        *slog.Logger
+}
+
+func NewExclusionService(exclusions ExclusionRepository, log *slog.Logger) (*ExclusionService, error) {
+	if exclusions == nil {
+		return nil, errors.New("exclusions repository cannot be nil")
+	}
+	if log == nil {
+		return nil, errors.New("logger cannot be nil")
+	}
+	return &ExclusionService{
+		exclusions: exclusions,
+		log:        log,
+	}, nil
+}
+
+func (s *ExclusionService) AddExclusion(ctx context.Context, req *models.AddReviewerExclusionRequest) (*models.ReviewerExclusion, error) {
+	if req == nil {
+		return nil, fmt.Errorf("%w: empty body", ErrExclusionValidation)
+	}
+	reviewerID, authorID, err := exclusionPair(req.ReviewerID, req.AuthorID)
+	if err != nil {
+		return nil, err
+	}
+	exclusion, err := s.exclusions.AddExclusion(ctx, models.ReviewerExclusion{
+		ReviewerID: reviewerID,
+		AuthorID:   authorID,
+		Mutual:     req.Mutual,
+		Reason:     strings.TrimSpace(req.Reason),
+	})
+	if err != nil {
+		if errors.Is(err, storage.ErrUserNotFound) {
+			return nil, ErrUserNotFound
+		}
+		return nil, fmt.Errorf("add reviewer exclusion: %w", err)
+	}
+	s.log.Info("reviewer exclusion added",
+		slog.String("reviewer_id", reviewerID),
+		slog.String("author_id", authorID),
+		slog.Bool("mutual", exclusion.Mutual),
+	)
+	return exclusion, nil
+}
+
+func (s *ExclusionService) ListExclusions(ctx context.Context, userID string) (*models.ReviewerExclusionsResponse, error) {
+	exclusions, err := s.exclusions.ListExclusions(ctx, strings.TrimSpace(userID))
+	if err != nil {
+		return nil, fmt.Errorf("list reviewer exclusions: %w", err)
+	}
+	return &models.ReviewerExclusionsResponse{Exclusions: exclusions}, nil
+}
+
+func (s *ExclusionService) DeleteExclusion(ctx context.Context, req *models.DeleteReviewerExclusionRequest) error {
+	if req == nil {
+		return fmt.Errorf("%w: empty body", ErrExclusionValidation)
+	}
+	reviewerID, authorID, err := exclusionPair(req.ReviewerID, req.AuthorID)
+	if err != nil {
+		return err
+	}
+	if err := s.exclusions.DeleteExclusion(ctx, reviewerID, authorID); err != nil {
+		if errors.Is(err, storage.ErrExclusionNotFound) {
+			return ErrExclusionNotFound
+		}
+		return fmt.Errorf("delete reviewer exclusion: %w", err)
+	}
+	s.log.Info("reviewer exclusion deleted", slog.String("reviewer_id", reviewerID), slog.String("author_id", authorID))
+	return nil
+}
+
+func exclusionPair(reviewerID, authorID string) (string, string, error) {
+	reviewerID = strings.TrimSpace(reviewerID)
+	authorID = strings.TrimSpace(authorID)
+	if reviewerID == "" || authorID == "" {
+		return "", "", fmt.Errorf("%w: reviewer_id and author_id are required", ErrExclusionValidation)
+	}
+	if reviewerID == authorID {
+		return "", "", fmt.Errorf("%w: reviewer_id and author_id must differ", ErrExclusionValidation)
+	}
+	return reviewerID, authorID, nil
+}
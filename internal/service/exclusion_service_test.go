@@ -0,0 +1,69 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/cloudyy74/pr-reviewer-service/internal/models"
+	"github.com/cloudyy74/pr-reviewer-service/internal/storage"
+)
+
+type fakeExclusionRepo struct {
+	added   []models.ReviewerExclusion
+	missing bool
+}
+
+func (f *fakeExclusionRepo) AddExclusion(_ context.Context, e models.ReviewerExclusion) (*models.ReviewerExclusion, error) {
+	f.added = append(f.added, e)
+	return &e, nil
+}
+
+func (f *fakeExclusionRepo) ListExclusions(context.Context, string) ([]*models.ReviewerExclusion, error) {
+	return nil, nil
+}
+
+func (f *fakeExclusionRepo) DeleteExclusion(context.Context, string, string) error {
+	if f.missing {
+		return storage.ErrExclusionNotFound
+	}
+	return nil
+}
+
+func TestExclusionService_AddExclusion(t *testing.T) {
+	repo := &fakeExclusionRepo{}
+	svc, err := NewExclusionService(repo, testLogger())
+	if err != nil {
+		t.Fatalf("NewExclusionService: %v", err)
+	}
+
+	ctx := context.Background()
+	for _, req := range []*models.AddReviewerExclusionRequest{
+		nil,
+		{ReviewerID: "u1"},
+		{ReviewerID: " u1 ", AuthorID: "u1"},
+	} {
+		if _, err := svc.AddExclusion(ctx, req); !errors.Is(err, ErrExclusionValidation) {
+			t.Fatalf("expected ErrExclusionValidation for %+v, got %v", req, err)
+		}
+	}
+
+	got, err := svc.AddExclusion(ctx, &models.AddReviewerExclusionRequest{ReviewerID: " u1", AuthorID: "u2 ", Mutual: true, Reason: " conflict "})
+	if err != nil {
+		t.Fatalf("AddExclusion: %v", err)
+	}
+	if got.ReviewerID != "u1" || got.AuthorID != "u2" || !got.Mutual || got.Reason != "conflict" {
+		t.Fatalf("unexpected exclusion %+v", got)
+	}
+}
+
+func TestExclusionService_DeleteMissing(t *testing.T) {
+	svc, err := NewExclusionService(&fakeExclusionRepo{missing: true}, testLogger())
+	if err != nil {
+		t.Fatalf("NewExclusionService: %v", err)
+	}
+	err = svc.DeleteExclusion(context.Background(), &models.DeleteReviewerExclusionRequest{ReviewerID: "u1", AuthorID: "u2"})
+	if !errors.Is(err, ErrExclusionNotFound) {
+		t.Fatalf("expected ErrExclusionNotFound, got %v", err)
+	}
+}
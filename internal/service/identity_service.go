@@ -0,0 +1,182 @@
+package service
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"log/slog"
+	"strings"
+
+	"github.com/cloudyy74/pr-reviewer-service/internal/models"
+	"github.com/cloudyy74/pr-reviewer-service/internal/storage"
+)
+
+const provisionedUserPrefix = "oidc-"
+
+var ErrIdentityNotLinked = errors.New("identity is not linked to a user")
+
+type TokenVerifier interface {
+	Verify(ctx context.Context, raw string) (*models.IdentityClaims, error)
+}
+
+type IdentityRepository interface {
+	GetUserIDByIdentity(ctx context.Context, issuer, subject string) (string, error)
+	LinkIdentity(ctx context.Context, issuer, subject, userID string) error
+}
+
+type IdentityUserRepository interface {
+	GetUserWithTeam(ctx context.Context, userID string) (*models.UserWithTeam, error)
+	GetUsersByUsername(ctx context.Context, username string) ([]*models.UserWithTeam, error)
+	UpsertUser(ctx context.Context, u models.User, teamName string) error
+	AddMembershipChanges(ctx context.Context, changes []models.MembershipChange) error
+}
+
+type IdentityOption func(*IdentityService)
+
+// WithAutoProvision creates a user in team for identities that match no
+// existing user instead of rejecting them.
+func WithAutoProvision(team string) IdentityOption {
+	return func(s *IdentityService) {
+		s.provisionTeam = strings.TrimSpace(team)
+	}
+}
+
+type IdentityService struct {
+	tx         txManager
+	verifier   TokenVerifier
+	identities IdentityRepository
+	users      IdentityUserRepository
+	log        *slog.Logger
+
+	provisionTeam string
+}
+
+func NewIdentityService(
+	tx txManager,
+	verifier TokenVerifier,
+	identities IdentityRepository,
+	users IdentityUserRepository,
+	log *slog.Logger,
+	opts ...IdentityOption,
+) (*IdentityService, error) {
+	if tx == nil {
+		return nil, errors.New("tx manager cannot be nil")
+	}
+	if verifier == nil {
+		return nil, errors.New("token verifier cannot be nil")
+	}
+	if identities == nil {
+		return nil, errors.New("identities repository cannot be nil")
+	}
+	if users == nil {
+		return nil, errors.New("users repository cannot be nil")
+	}
+	if log == nil {
+		return nil, errors.New("logger cannot be nil")
+	}
+	s := &IdentityService{
+		tx:         tx,
+		verifier:   verifier,
+		identities: identities,
+		users:      users,
+		log:        log,
+	}
+	for _, opt := range opts {
+		opt(s)
+	}
+	return s, nil
+}
+
+// ResolveIdentity verifies an ID token and returns the user it belongs to.
+// Unknown identities are linked by preferred_username first and provisioned
+// only when auto-provisioning is enabled.
+func (s *IdentityService) ResolveIdentity(ctx context.Context, rawToken string) (*models.UserWithTeam, error) {
+	claims, err := s.verifier.Verify(ctx, rawToken)
+	if err != nil {
+		return nil, err
+	}
+
+	var user *models.UserWithTeam
+	err = s.tx.Run(ctx, func(ctx context.Context) error {
+		userID, err := s.identities.GetUserIDByIdentity(ctx, claims.Issuer, claims.Subject)
+		switch {
+		case err == nil:
+			user, err = s.users.GetUserWithTeam(ctx, userID)
+			if errors.Is(err, storage.ErrUserNotFound) {
+				return ErrUserNotFound
+			}
+			return err
+		case !errors.Is(err, storage.ErrIdentityNotFound):
+			return err
+		}
+
+		user, err = s.matchOrProvision(ctx, claims)
+		if err != nil {
+			return err
+		}
+		return s.identities.LinkIdentity(ctx, claims.Issuer, claims.Subject, user.ID)
+	})
+	if err != nil {
+		if !errors.Is(err, ErrIdentityNotLinked) && !errors.Is(err, ErrUserNotFound) {
+			s.log.Error("resolve identity failed", slog.Any("error", err), slog.String("subject", claims.Subject))
+		}
+		return nil, fmt.Errorf("resolve identity: %w", err)
+	}
+	return user, nil
+}
+
+func (s *IdentityService) matchOrProvision(ctx context.Context, claims *models.IdentityClaims) (*models.UserWithTeam, error) {
+	if username := strings.TrimSpace(claims.PreferredUsername); username != "" {
+		user, err := findUserByUsername(ctx, s.users, username)
+		if err == nil {
+			s.log.Info("linked identity by username", slog.String("user_id", user.ID), slog.String("issuer", claims.Issuer))
+			return user, nil
+		}
+		if !errors.Is(err, ErrUserNotFound) && !errors.Is(err, ErrUsernameAmbiguous) {
+			return nil, err
+		}
+	}
+	if s.provisionTeam == "" {
+		return nil, ErrIdentityNotLinked
+	}
+
+	user := &models.UserWithTeam{
+		User: models.User{
+			ID:       provisionedUserID(claims),
+			Username: provisionedUsername(claims),
+			IsActive: true,
+		},
+		TeamName: s.provisionTeam,
+	}
+	var previousTeam string
+	if existing, err := s.users.GetUserWithTeam(ctx, user.ID); err == nil {
+		previousTeam = existing.TeamName
+	} else if !errors.Is(err, storage.ErrUserNotFound) {
+		return nil, err
+	}
+	if err := s.users.UpsertUser(ctx, user.User, user.TeamName); err != nil {
+		return nil, err
+	}
+	if err := s.users.AddMembershipChanges(ctx, membershipChanges(user.ID, previousTeam, user.TeamName)); err != nil {
+		return nil, err
+	}
+	s.log.Info("provisioned user for identity", slog.String("user_id", user.ID), slog.String("team_name", user.TeamName))
+	return user, nil
+}
+
+func provisionedUserID(claims *models.IdentityClaims) string {
+	sum := sha256.Sum256([]byte(claims.Issuer + "\x00" + claims.Subject))
+	return provisionedUserPrefix + hex.EncodeToString(sum[:8])
+}
+
+func provisionedUsername(claims *models.IdentityClaims) string {
+	if name := strings.TrimSpace(claims.PreferredUsername); name != "" {
+		return name
+	}
+	if local, _, ok := strings.Cut(claims.Email, "@"); ok && local != "" {
+		return local
+	}
+	return provisionedUserID(claims)
+}
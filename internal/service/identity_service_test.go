@@ -0,0 +1,139 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/cloudyy74/pr-reviewer-service/internal/models"
+	"github.com/cloudyy74/pr-reviewer-service/internal/storage"
+)
+
+type fakeTokenVerifier struct {
+	claims *models.IdentityClaims
+	err    error
+}
+
+func (f fakeTokenVerifier) Verify(context.Context, string) (*models.IdentityClaims, error) {
+	return f.claims, f.err
+}
+
+type fakeIdentityRepo struct {
+	links map[string]string
+}
+
+func (f *fakeIdentityRepo) GetUserIDByIdentity(_ context.Context, issuer, subject string) (string, error) {
+	userID, ok := f.links[issuer+"|"+subject]
+	if !ok {
+		return "", storage.ErrIdentityNotFound
+	}
+	return userID, nil
+}
+
+func (f *fakeIdentityRepo) LinkIdentity(_ context.Context, issuer, subject, userID string) error {
+	f.links[issuer+"|"+subject] = userID
+	return nil
+}
+
+type fakeIdentityUserRepo struct {
+	users   map[string]*models.UserWithTeam
+	changes []models.MembershipChange
+}
+
+func (f *fakeIdentityUserRepo) GetUserWithTeam(_ context.Context, userID string) (*models.UserWithTeam, error) {
+	u, ok := f.users[userID]
+	if !ok {
+		return nil, storage.ErrUserNotFound
+	}
+	return u, nil
+}
+
+func (f *fakeIdentityUserRepo) GetUsersByUsername(_ context.Context, username string) ([]*models.UserWithTeam, error) {
+	var found []*models.UserWithTeam
+	for _, u := range f.users {
+		if u.Username == username {
+			found = append(found, u)
+		}
+	}
+	return found, nil
+}
+
+func (f *fakeIdentityUserRepo) UpsertUser(_ context.Context, u models.User, teamName string) error {
+	f.users[u.ID] = &models.UserWithTeam{User: u, TeamName: teamName}
+	return nil
+}
+
+func (f *fakeIdentityUserRepo) AddMembershipChanges(_ context.Context, changes []models.MembershipChange) error {
+	f.changes = append(f.changes, changes...)
+	return nil
+}
+
+func TestIdentityService_ResolveIdentity(t *testing.T) {
+	claims := &models.IdentityClaims{Issuer: "https://idp", Subject: "sub-1", PreferredUsername: "alice"}
+
+	t.Run("links existing user by username", func(t *testing.T) {
+		identities := &fakeIdentityRepo{links: map[string]string{}}
+		users := &fakeIdentityUserRepo{users: map[string]*models.UserWithTeam{
+			"u1": {User: models.User{ID: "u1", Username: "alice"}, TeamName: "backend"},
+		}}
+		s, err := NewIdentityService(fakeTxManager{}, fakeTokenVerifier{claims: claims}, identities, users, testLogger())
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+
+		user, err := s.ResolveIdentity(context.Background(), "token")
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if user.ID != "u1" || identities.links["https://idp|sub-1"] != "u1" {
+			t.Fatalf("expected identity linked to u1, got %+v, links %v", user, identities.links)
+		}
+
+		users.users["u1"].Username = "renamed"
+		if user, err = s.ResolveIdentity(context.Background(), "token"); err != nil || user.ID != "u1" {
+			t.Fatalf("expected linked identity to resolve to u1, got %+v, %v", user, err)
+		}
+	})
+
+	t.Run("rejects unknown identity without provisioning", func(t *testing.T) {
+		identities := &fakeIdentityRepo{links: map[string]string{}}
+		users := &fakeIdentityUserRepo{users: map[string]*models.UserWithTeam{}}
+		s, _ := NewIdentityService(fakeTxManager{}, fakeTokenVerifier{claims: claims}, identities, users, testLogger())
+
+		if _, err := s.ResolveIdentity(context.Background(), "token"); !errors.Is(err, ErrIdentityNotLinked) {
+			t.Fatalf("expected ErrIdentityNotLinked, got %v", err)
+		}
+		if len(identities.links) != 0 {
+			t.Fatalf("expected no links, got %v", identities.links)
+		}
+	})
+
+	t.Run("provisions user when enabled", func(t *testing.T) {
+		identities := &fakeIdentityRepo{links: map[string]string{}}
+		users := &fakeIdentityUserRepo{users: map[string]*models.UserWithTeam{}}
+		s, _ := NewIdentityService(fakeTxManager{}, fakeTokenVerifier{claims: claims}, identities, users, testLogger(), WithAutoProvision("newcomers"))
+
+		user, err := s.ResolveIdentity(context.Background(), "token")
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if !strings.HasPrefix(user.ID, provisionedUserPrefix) || user.Username != "alice" || user.TeamName != "newcomers" || !user.IsActive {
+			t.Fatalf("unexpected provisioned user: %+v", user)
+		}
+		if _, ok := users.users[user.ID]; !ok || identities.links["https://idp|sub-1"] != user.ID {
+			t.Fatalf("expected user stored and linked, users %v, links %v", users.users, identities.links)
+		}
+		if len(users.changes) != 1 || users.changes[0].TeamName != "newcomers" || users.changes[0].Change != models.MembershipJoined {
+			t.Fatalf("expected join recorded in membership history, got %+v", users.changes)
+		}
+	})
+
+	t.Run("propagates verification errors", func(t *testing.T) {
+		verifyErr := errors.New("bad token")
+		s, _ := NewIdentityService(fakeTxManager{}, fakeTokenVerifier{err: verifyErr}, &fakeIdentityRepo{}, &fakeIdentityUserRepo{}, testLogger())
+		if _, err := s.ResolveIdentity(context.Background(), "token"); !errors.Is(err, verifyErr) {
+			t.Fatalf("expected verification error, got %v", err)
+		}
+	})
+}
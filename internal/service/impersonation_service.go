@@ -0,0 +1,87 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"slices"
+	"strings"
+
+	"github.com/cloudyy74/pr-reviewer-service/internal/models"
+	"github.com/cloudyy74/pr-reviewer-service/internal/storage"
+)
+
+var ErrImpersonationForbidden = errors.New("impersonation requires admin scope")
+
+type ImpersonationUserRepository interface {
+	GetUserWithTeam(ctx context.Context, userID string) (*models.UserWithTeam, error)
+	GetUsersByUsername(ctx context.Context, username string) ([]*models.UserWithTeam, error)
+}
+
+type AuditRepository interface {
+	RecordAudit(ctx context.Context, entry models.AuditEntry) error
+}
+
+type ImpersonationService struct {
+	users  ImpersonationUserRepository
+	audit  AuditRepository
+	admins []string
+	log    *slog.Logger
+}
+
+func NewImpersonationService(users ImpersonationUserRepository, audit AuditRepository, admins []string, log *slog.Logger) (*ImpersonationService, error) {
+	if users == nil {
+		return nil, errors.New("users repository cannot be nil")
+	}
+	if audit == nil {
+		return nil, errors.New("audit repository cannot be nil")
+	}
+	if len(admins) == 0 {
+		return nil, errors.New("admins cannot be empty")
+	}
+	if log == nil {
+		return nil, errors.New("logger cannot be nil")
+	}
+	return &ImpersonationService{
+		users:  users,
+		audit:  audit,
+		admins: admins,
+		log:    log,
+	}, nil
+}
+
+// Impersonate returns the user an admin actor wants to act as. The target
+// may be a user_id or an @username reference.
+func (s *ImpersonationService) Impersonate(ctx context.Context, actorID, targetRef string) (*models.UserWithTeam, error) {
+	if !slices.Contains(s.admins, actorID) {
+		s.log.Warn("impersonation denied", slog.String("actor_id", actorID), slog.String("target", targetRef))
+		return nil, ErrImpersonationForbidden
+	}
+	targetID, err := resolveUserRef(ctx, s.users, strings.TrimSpace(targetRef))
+	if err != nil {
+		return nil, err
+	}
+	if targetID == "" {
+		return nil, fmt.Errorf("%w: impersonated user is required", ErrUserValidation)
+	}
+	user, err := s.users.GetUserWithTeam(ctx, targetID)
+	if errors.Is(err, storage.ErrUserNotFound) {
+		return nil, ErrUserNotFound
+	}
+	if err != nil {
+		return nil, fmt.Errorf("get impersonated user: %w", err)
+	}
+	return user, nil
+}
+
+func (s *ImpersonationService) RecordAudit(ctx context.Context, entry models.AuditEntry) error {
+	s.log.Info("impersonated request",
+		slog.String("actor_id", entry.ActorID),
+		slog.String("subject_id", entry.SubjectID),
+		slog.String("method", entry.Method),
+		slog.String("path", entry.Path),
+		slog.Int("status", entry.Status),
+	)
+	return s.audit.RecordAudit(ctx, entry)
+}
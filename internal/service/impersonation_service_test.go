@@ -0,0 +1,54 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/cloudyy74/pr-reviewer-service/internal/models"
+)
+
+type fakeAuditRepo struct {
+	entries []models.AuditEntry
+}
+
+func (f *fakeAuditRepo) RecordAudit(_ context.Context, entry models.AuditEntry) error {
+	f.entries = append(f.entries, entry)
+	return nil
+}
+
+func TestImpersonationService_Impersonate(t *testing.T) {
+	users := &fakeIdentityUserRepo{users: map[string]*models.UserWithTeam{
+		"u1": {User: models.User{ID: "u1", Username: "alice"}, TeamName: "backend"},
+	}}
+	audit := &fakeAuditRepo{}
+	s, err := NewImpersonationService(users, audit, []string{"admin"}, testLogger())
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	ctx := context.Background()
+
+	for _, ref := range []string{"u1", "@alice"} {
+		user, err := s.Impersonate(ctx, "admin", ref)
+		if err != nil || user.ID != "u1" {
+			t.Fatalf("ref %q: expected u1, got %+v, %v", ref, user, err)
+		}
+	}
+	if _, err := s.Impersonate(ctx, "u1", "u1"); !errors.Is(err, ErrImpersonationForbidden) {
+		t.Fatalf("expected ErrImpersonationForbidden, got %v", err)
+	}
+	if _, err := s.Impersonate(ctx, "admin", "ghost"); !errors.Is(err, ErrUserNotFound) {
+		t.Fatalf("expected ErrUserNotFound, got %v", err)
+	}
+	if _, err := s.Impersonate(ctx, "admin", " "); !errors.Is(err, ErrUserValidation) {
+		t.Fatalf("expected ErrUserValidation, got %v", err)
+	}
+
+	entry := models.AuditEntry{ActorID: "admin", SubjectID: "u1", Method: "GET", Path: "/me", Status: 200}
+	if err := s.RecordAudit(ctx, entry); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(audit.entries) != 1 || audit.entries[0] != entry {
+		t.Fatalf("unexpected audit entries: %+v", audit.entries)
+	}
+}
@@ -0,0 +1,106 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"slices"
+	"strings"
+
+	"github.com/cloudyy74/pr-reviewer-service/internal/models"
+)
+
+var (
+	ErrIntegrationValidation = errors.New("validation error")
+
+	integrationProviders = []string{models.IntegrationSlack, models.IntegrationGitHub, models.IntegrationTelegram}
+)
+
+type IntegrationTokenRepository interface {
+	SetIntegrationToken(ctx context.Context, teamName, provider, token string) (*models.IntegrationToken, error)
+	ReencryptIntegrationTokens(ctx context.Context) (int, error)
+}
+
+type teamChecker interface {
+	ExistsTeam(context.Context, string) (bool, error)
+}
+
+type IntegrationTokenService struct {
+	tx     txManager
+	teams  teamChecker
+	tokens IntegrationTokenRepository
+	log    *slog.Logger
+}
+
+func NewIntegrationTokenService(tx txManager, teams teamChecker, tokens IntegrationTokenRepository, log *slog.Logger) (*IntegrationTokenService, error) {
+	if tx == nil {
+		return nil, errors.New("tx manager cannot be nil")
+	}
+	if teams == nil {
+		return nil, errors.New("teams repository cannot be nil")
+	}
+	if tokens == nil {
+		return nil, errors.New("integration tokens repository cannot be nil")
+	}
+	if log == nil {
+		return nil, errors.New("logger cannot be nil")
+	}
+	return &IntegrationTokenService{
+		tx:     tx,
+		teams:  teams,
+		tokens: tokens,
+		log:    log,
+	}, nil
+}
+
+func (s *IntegrationTokenService) SetIntegrationToken(ctx context.Context, req *models.SetIntegrationTokenRequest) (*models.IntegrationToken, error) {
+	if req == nil {
+		return nil, fmt.Errorf("%w: empty body", ErrIntegrationValidation)
+	}
+	req.TeamName = strings.TrimSpace(req.TeamName)
+	req.Provider = strings.ToLower(strings.TrimSpace(req.Provider))
+	if req.TeamName == "" {
+		return nil, fmt.Errorf("%w: team_name is required", ErrIntegrationValidation)
+	}
+	if !slices.Contains(integrationProviders, req.Provider) {
+		return nil, fmt.Errorf("%w: provider must be one of %s", ErrIntegrationValidation, strings.Join(integrationProviders, ", "))
+	}
+	if strings.TrimSpace(req.Token) == "" {
+		return nil, fmt.Errorf("%w: token is required", ErrIntegrationValidation)
+	}
+
+	var info *models.IntegrationToken
+	err := s.tx.Run(ctx, func(ctx context.Context) error {
+		exists, err := s.teams.ExistsTeam(ctx, req.TeamName)
+		if err != nil {
+			return fmt.Errorf("check team exists: %w", err)
+		}
+		if !exists {
+			return ErrTeamNotFound
+		}
+		info, err = s.tokens.SetIntegrationToken(ctx, req.TeamName, req.Provider, req.Token)
+		return err
+	})
+	if err != nil {
+		if !errors.Is(err, ErrTeamNotFound) {
+			s.log.Error("set integration token failed", slog.Any("error", err), slog.String("team", req.TeamName))
+		}
+		return nil, err
+	}
+	return info, nil
+}
+
+// RotateKeys re-encrypts stored tokens with the current primary key.
+func (s *IntegrationTokenService) RotateKeys(ctx context.Context) (int, error) {
+	var rotated int
+	err := s.tx.Run(ctx, func(ctx context.Context) (err error) {
+		rotated, err = s.tokens.ReencryptIntegrationTokens(ctx)
+		return err
+	})
+	if err != nil {
+		return 0, fmt.Errorf("rotate integration token keys: %w", err)
+	}
+	s.log.Info("integration tokens re-encrypted", slog.Int("count", rotated))
+	return rotated, nil
+}
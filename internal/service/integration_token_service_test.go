@@ -0,0 +1,64 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/cloudyy74/pr-reviewer-service/internal/models"
+)
+
+type fakeTeamChecker map[string]bool
+
+func (f fakeTeamChecker) ExistsTeam(_ context.Context, name string) (bool, error) {
+	return f[name], nil
+}
+
+type fakeIntegrationTokenRepo struct {
+	tokens map[string]string
+}
+
+func (f *fakeIntegrationTokenRepo) SetIntegrationToken(_ context.Context, teamName, provider, token string) (*models.IntegrationToken, error) {
+	f.tokens[teamName+"/"+provider] = token
+	return &models.IntegrationToken{TeamName: teamName, Provider: provider, KeyID: "k1"}, nil
+}
+
+func (f *fakeIntegrationTokenRepo) ReencryptIntegrationTokens(context.Context) (int, error) {
+	return len(f.tokens), nil
+}
+
+func TestIntegrationTokenService_SetIntegrationToken(t *testing.T) {
+	repo := &fakeIntegrationTokenRepo{tokens: map[string]string{}}
+	s, err := NewIntegrationTokenService(fakeTxManager{}, fakeTeamChecker{"backend": true}, repo, testLogger())
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	ctx := context.Background()
+
+	info, err := s.SetIntegrationToken(ctx, &models.SetIntegrationTokenRequest{TeamName: "backend", Provider: " Slack ", Token: "xoxb"})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if info.Provider != models.IntegrationSlack || repo.tokens["backend/slack"] != "xoxb" {
+		t.Fatalf("unexpected result %+v, stored %v", info, repo.tokens)
+	}
+
+	invalid := []*models.SetIntegrationTokenRequest{
+		nil,
+		{Provider: "slack", Token: "x"},
+		{TeamName: "backend", Provider: "email", Token: "x"},
+		{TeamName: "backend", Provider: "github", Token: " "},
+	}
+	for _, req := range invalid {
+		if _, err := s.SetIntegrationToken(ctx, req); !errors.Is(err, ErrIntegrationValidation) {
+			t.Fatalf("request %+v: expected ErrIntegrationValidation, got %v", req, err)
+		}
+	}
+	if _, err := s.SetIntegrationToken(ctx, &models.SetIntegrationTokenRequest{TeamName: "ghost", Provider: "github", Token: "x"}); !errors.Is(err, ErrTeamNotFound) {
+		t.Fatalf("expected ErrTeamNotFound, got %v", err)
+	}
+
+	if n, err := s.RotateKeys(ctx); err != nil || n != 1 {
+		t.Fatalf("expected 1 rotated token, got %d, %v", n, err)
+	}
+}
@@ -0,0 +1,67 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"strings"
+
+	"github.com/cloudyy74/pr-reviewer-service/internal/models"
+)
+
+var ErrNotificationValidation = errors.New("validation error")
+
+type NotificationRepository interface {
+	ListNotifications(ctx context.Context, q models.NotificationQuery) ([]*models.NotificationRecord, int, error)
+}
+
+type NotificationService struct {
+	notifications NotificationRepository
+	log           *slog.Logger
+}
+
+func NewNotificationService(notifications NotificationRepository, log *slog.Logger) (*NotificationService, error) {
+	if notifications == nil {
+		return nil, errors.New("notifications repository cannot be nil")
+	}
+	if log == nil {
+		return nil, errors.New("logger cannot be nil")
+	}
+	return &NotificationService{
+		notifications: notifications,
+		log:           log,
+	}, nil
+}
+
+func (s *NotificationService) ListNotifications(ctx context.Context, q models.NotificationQuery) (*models.NotificationListResponse, error) {
+	q.UserID = strings.TrimSpace(q.UserID)
+	q.PullRequestID = strings.TrimSpace(q.PullRequestID)
+	q.Channel = strings.TrimSpace(q.Channel)
+	q.Status = strings.ToUpper(strings.TrimSpace(q.Status))
+	if q.Status != "" && q.Status != models.NotificationSent && q.Status != models.NotificationFailed {
+		return nil, fmt.Errorf("%w: status must be %s or %s", ErrNotificationValidation, models.NotificationSent, models.NotificationFailed)
+	}
+	if q.From != nil && q.To != nil && !q.From.Before(*q.To) {
+		return nil, fmt.Errorf("%w: from must be before to", ErrNotificationValidation)
+	}
+	if q.Limit < 0 || q.Offset < 0 {
+		return nil, fmt.Errorf("%w: limit and offset cannot be negative", ErrNotificationValidation)
+	}
+	if q.Limit == 0 {
+		q.Limit = defaultHistoryLimit
+	}
+	q.Limit = min(q.Limit, maxHistoryLimit)
+
+	records, total, err := s.notifications.ListNotifications(ctx, q)
+	if err != nil {
+		s.log.Error("list notifications failed", slog.Any("error", err))
+		return nil, fmt.Errorf("list notifications: %w", err)
+	}
+	return &models.NotificationListResponse{
+		Notifications: records,
+		Total:         total,
+		Limit:         q.Limit,
+		Offset:        q.Offset,
+	}, nil
+}
@@ -0,0 +1,42 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/cloudyy74/pr-reviewer-service/internal/models"
+)
+
+type fakeNotificationRepo struct {
+	got models.NotificationQuery
+}
+
+func (f *fakeNotificationRepo) ListNotifications(_ context.Context, q models.NotificationQuery) ([]*models.NotificationRecord, int, error) {
+	f.got = q
+	return []*models.NotificationRecord{{ID: 1, UserID: q.UserID}}, 1, nil
+}
+
+func TestNotificationService_ListNotifications(t *testing.T) {
+	repo := &fakeNotificationRepo{}
+	service, err := NewNotificationService(repo, testLogger())
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	resp, err := service.ListNotifications(context.Background(), models.NotificationQuery{UserID: " u1 ", Status: "failed", Limit: 1000})
+	if err != nil {
+		t.Fatalf("ListNotifications returned err: %v", err)
+	}
+	if repo.got.UserID != "u1" || repo.got.Status != models.NotificationFailed || repo.got.Limit != maxHistoryLimit {
+		t.Fatalf("unexpected query: %+v", repo.got)
+	}
+	if resp.Total != 1 || len(resp.Notifications) != 1 {
+		t.Fatalf("unexpected response: %+v", resp)
+	}
+
+	_, err = service.ListNotifications(context.Background(), models.NotificationQuery{Status: "lost"})
+	if !errors.Is(err, ErrNotificationValidation) {
+		t.Fatalf("expected ErrNotificationValidation, got %v", err)
+	}
+}
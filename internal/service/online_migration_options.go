@@ -0,0 +1,11 @@
+package service
+
+type OnlineMigrationOption func(*OnlineMigrationService)
+
+func WithBackfillBatchSize(size int) OnlineMigrationOption {
+	return func(s *OnlineMigrationService) {
+		if size > 0 {
+			s.batchSize = size
+		}
+	}
+}
@@ -0,0 +1,148 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"sync"
+
+	"github.com/cloudyy74/pr-reviewer-service/internal/models"
+	"github.com/cloudyy74/pr-reviewer-service/internal/storage"
+)
+
+const defaultBackfillBatchSize = 500
+
+type OnlineMigrationRepository interface {
+	Backfill(ctx context.Context, m storage.ColumnMigration, batch int) (int64, error)
+	Remaining(ctx context.Context, m storage.ColumnMigration) (int, error)
+}
+
+// OnlineMigration binds a column migration to its configured phase and to
+// the hook that switches the storage layer between phases.
+type OnlineMigration struct {
+	storage.ColumnMigration
+	Phase storage.MigrationPhase
+	Apply func(storage.MigrationPhase)
+}
+
+type onlineMigrationState struct {
+	OnlineMigration
+	active storage.MigrationPhase
+}
+
+type OnlineMigrationService struct {
+	repo       OnlineMigrationRepository
+	log        *slog.Logger
+	batchSize  int
+	mu         sync.Mutex
+	migrations []*onlineMigrationState
+}
+
+func NewOnlineMigrationService(repo OnlineMigrationRepository, migrations []OnlineMigration, log *slog.Logger, opts ...OnlineMigrationOption) (*OnlineMigrationService, error) {
+	if repo == nil {
+		return nil, errors.New("online migration repository cannot be nil")
+	}
+	if log == nil {
+		return nil, errors.New("logger cannot be nil")
+	}
+	s := &OnlineMigrationService{
+		repo:      repo,
+		log:       log,
+		batchSize: defaultBackfillBatchSize,
+	}
+	for _, opt := range opts {
+		opt(s)
+	}
+	for _, m := range migrations {
+		state := &onlineMigrationState{OnlineMigration: m, active: m.Phase}
+		// Reads only move to the new column once the backfill has been
+		// verified complete, so a cutover starts out as a dual write.
+		if m.Phase == storage.MigrationPhaseCutover {
+			state.active = storage.MigrationPhaseDualWrite
+		}
+		state.apply()
+		s.migrations = append(s.migrations, state)
+	}
+	return s, nil
+}
+
+func (m *onlineMigrationState) apply() {
+	if m.Apply != nil {
+		m.Apply(m.active)
+	}
+}
+
+// Run backfills every migration that is writing both columns and promotes
+// configured cutovers once no out-of-sync rows are left.
+func (s *OnlineMigrationService) Run(ctx context.Context) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	var errs []error
+	for _, m := range s.migrations {
+		if !m.Phase.WritesNew() {
+			continue
+		}
+		if err := s.backfill(ctx, m); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return errors.Join(errs...)
+}
+
+func (s *OnlineMigrationService) backfill(ctx context.Context, m *onlineMigrationState) error {
+	var total int64
+	for {
+		n, err := s.repo.Backfill(ctx, m.ColumnMigration, s.batchSize)
+		if err != nil {
+			return err
+		}
+		total += n
+		if n < int64(s.batchSize) || ctx.Err() != nil {
+			break
+		}
+	}
+	if total > 0 {
+		s.log.Info("backfilled rows", slog.String("migration", m.Name), slog.Int64("rows", total))
+	}
+	if m.Phase != storage.MigrationPhaseCutover || m.active == storage.MigrationPhaseCutover {
+		return nil
+	}
+	remaining, err := s.repo.Remaining(ctx, m.ColumnMigration)
+	if err != nil {
+		return err
+	}
+	if remaining > 0 {
+		s.log.Warn("cutover postponed until backfill completes", slog.String("migration", m.Name), slog.Int("remaining", remaining))
+		return nil
+	}
+	m.active = storage.MigrationPhaseCutover
+	m.apply()
+	s.log.Info("online migration cut over", slog.String("migration", m.Name))
+	return nil
+}
+
+func (s *OnlineMigrationService) Status(ctx context.Context) (*models.OnlineMigrationsResponse, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	resp := &models.OnlineMigrationsResponse{Migrations: make([]*models.OnlineMigrationStatus, 0, len(s.migrations))}
+	for _, m := range s.migrations {
+		status := &models.OnlineMigrationStatus{
+			Name:            m.Name,
+			Table:           m.Table,
+			OldColumn:       m.OldColumn,
+			NewColumn:       m.NewColumn,
+			ConfiguredPhase: string(m.Phase),
+			ActivePhase:     string(m.active),
+		}
+		if m.Phase.WritesNew() {
+			remaining, err := s.repo.Remaining(ctx, m.ColumnMigration)
+			if err != nil {
+				return nil, fmt.Errorf("migration %s: %w", m.Name, err)
+			}
+			status.Remaining = remaining
+		}
+		resp.Migrations = append(resp.Migrations, status)
+	}
+	return resp, nil
+}
@@ -0,0 +1,75 @@
+package service
+
+import (
+	"context"
+	"testing"
+
+	"github.com/cloudyy74/pr-reviewer-service/internal/storage"
+)
+
+type fakeOnlineMigrationRepo struct {
+	pending int
+	batches int
+}
+
+func (f *fakeOnlineMigrationRepo) Backfill(_ context.Context, _ storage.ColumnMigration, batch int) (int64, error) {
+	f.batches++
+	n := min(batch, f.pending)
+	f.pending -= n
+	return int64(n), nil
+}
+
+func (f *fakeOnlineMigrationRepo) Remaining(context.Context, storage.ColumnMigration) (int, error) {
+	return f.pending, nil
+}
+
+func TestOnlineMigrationService_CutoverWaitsForBackfill(t *testing.T) {
+	repo := &fakeOnlineMigrationRepo{pending: 5}
+	var applied []storage.MigrationPhase
+	svc, err := NewOnlineMigrationService(repo, []OnlineMigration{{
+		ColumnMigration: storage.PRStatusEnumMigration,
+		Phase:           storage.MigrationPhaseCutover,
+		Apply:           func(p storage.MigrationPhase) { applied = append(applied, p) },
+	}}, testLogger(), WithBackfillBatchSize(2))
+	if err != nil {
+		t.Fatalf("NewOnlineMigrationService: %v", err)
+	}
+	if len(applied) != 1 || applied[0] != storage.MigrationPhaseDualWrite {
+		t.Fatalf("expected cutover to start as dual write, got %v", applied)
+	}
+
+	status, err := svc.Status(context.Background())
+	if err != nil {
+		t.Fatalf("Status: %v", err)
+	}
+	if got := status.Migrations[0]; got.ActivePhase != "dual_write" || got.Remaining != 5 {
+		t.Fatalf("unexpected status %+v", got)
+	}
+
+	if err := svc.Run(context.Background()); err != nil {
+		t.Fatalf("Run: %v", err)
+	}
+	if repo.batches != 3 {
+		t.Fatalf("expected 3 batches, got %d", repo.batches)
+	}
+	if len(applied) != 2 || applied[1] != storage.MigrationPhaseCutover {
+		t.Fatalf("expected cutover after backfill, got %v", applied)
+	}
+}
+
+func TestOnlineMigrationService_SkipsDisabledMigrations(t *testing.T) {
+	repo := &fakeOnlineMigrationRepo{pending: 5}
+	svc, err := NewOnlineMigrationService(repo, []OnlineMigration{{
+		ColumnMigration: storage.PRStatusEnumMigration,
+		Phase:           storage.MigrationPhaseOff,
+	}}, testLogger())
+	if err != nil {
+		t.Fatalf("NewOnlineMigrationService: %v", err)
+	}
+	if err := svc.Run(context.Background()); err != nil {
+		t.Fatalf("Run: %v", err)
+	}
+	if repo.batches != 0 {
+		t.Fatalf("expected no backfill, got %d batches", repo.batches)
+	}
+}
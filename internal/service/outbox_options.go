@@ -0,0 +1,24 @@
+package service
+
+import "time"
+
+type OutboxOption func(*OutboxRelay)
+
+func WithOutboxBatching(batchSize, workers int) OutboxOption {
+	return func(r *OutboxRelay) {
+		if batchSize > 0 {
+			r.batchSize = batchSize
+		}
+		if workers > 0 {
+			r.workers = workers
+		}
+	}
+}
+
+func WithOutboxLease(lease time.Duration) OutboxOption {
+	return func(r *OutboxRelay) {
+		if lease > 0 {
+			r.lease = lease
+		}
+	}
+}
@@ -0,0 +1,187 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/cloudyy74/pr-reviewer-service/internal/models"
+)
+
+const (
+	defaultOutboxBatchSize = 100
+	defaultOutboxWorkers   = 4
+	defaultOutboxLease     = 30 * time.Second
+	maxOutboxBackoff       = 5 * time.Minute
+)
+
+type OutboxWriter interface {
+	AddOutboxEvent(ctx context.Context, topic, aggregateID string, payload []byte) error
+}
+
+type OutboxRepository interface {
+	ClaimOutboxEvents(ctx context.Context, limit int, lease time.Duration) ([]*models.OutboxEvent, error)
+	MarkOutboxDelivered(ctx context.Context, id int64) error
+	MarkOutboxFailed(ctx context.Context, id int64, lastError string, retryAt time.Time) error
+	GetOutboxBacklog(ctx context.Context) (*models.OutboxBacklog, error)
+}
+
+type OutboxPublisher interface {
+	Publish(ctx context.Context, event *models.OutboxEvent) error
+}
+
+type OutboxRelay struct {
+	outbox    OutboxRepository
+	publisher OutboxPublisher
+	log       *slog.Logger
+	now       func() time.Time
+
+	batchSize int
+	workers   int
+	lease     time.Duration
+
+	delivered atomic.Int64
+	failed    atomic.Int64
+}
+
+func NewOutboxRelay(outbox OutboxRepository, publisher OutboxPublisher, log *slog.Logger, opts ...OutboxOption) (*OutboxRelay, error) {
+	if outbox == nil {
+		return nil, errors.New("outbox repository cannot be nil")
+	}
+	if log == nil {
+		return nil, errors.New("logger cannot be nil")
+	}
+	r := &OutboxRelay{
+		outbox:    outbox,
+		publisher: publisher,
+		log:       log,
+		now:       time.Now,
+		batchSize: defaultOutboxBatchSize,
+		workers:   defaultOutboxWorkers,
+		lease:     defaultOutboxLease,
+	}
+	for _, opt := range opts {
+		opt(r)
+	}
+	if r.publisher == nil {
+		r.publisher = logOutboxPublisher{log: log}
+	}
+	return r, nil
+}
+
+// RelayBatch claims up to batchSize due events and publishes them with a
+// bounded worker pool. Claimed rows are leased rather than locked for the
+// whole delivery, so several instances can relay the same table.
+func (r *OutboxRelay) RelayBatch(ctx context.Context) (int, error) {
+	events, err := r.outbox.ClaimOutboxEvents(ctx, r.batchSize, r.lease)
+	if err != nil {
+		return 0, fmt.Errorf("claim outbox events: %w", err)
+	}
+	if len(events) == 0 {
+		return 0, nil
+	}
+
+	queue := make(chan *models.OutboxEvent)
+	var delivered atomic.Int64
+	var wg sync.WaitGroup
+	for range min(r.workers, len(events)) {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for event := range queue {
+				if r.deliver(ctx, event) {
+					delivered.Add(1)
+				}
+			}
+		}()
+	}
+	for _, event := range events {
+		queue <- event
+	}
+	close(queue)
+	wg.Wait()
+
+	return int(delivered.Load()), nil
+}
+
+func (r *OutboxRelay) deliver(ctx context.Context, event *models.OutboxEvent) bool {
+	if err := r.publisher.Publish(ctx, event); err != nil {
+		r.failed.Add(1)
+		retryAt := r.now().Add(outboxBackoff(event.Attempts))
+		r.log.Warn("outbox event delivery failed",
+			slog.Int64("event_id", event.ID),
+			slog.String("topic", event.Topic),
+			slog.Int("attempts", event.Attempts),
+			slog.Time("retry_at", retryAt),
+			slog.Any("error", err),
+		)
+		if err := r.outbox.MarkOutboxFailed(ctx, event.ID, err.Error(), retryAt); err != nil {
+			r.log.Error("mark outbox event failed", slog.Any("error", err), slog.Int64("event_id", event.ID))
+		}
+		return false
+	}
+	if err := r.outbox.MarkOutboxDelivered(ctx, event.ID); err != nil {
+		r.log.Error("mark outbox event delivered", slog.Any("error", err), slog.Int64("event_id", event.ID))
+		return false
+	}
+	r.delivered.Add(1)
+	return true
+}
+
+func (r *OutboxRelay) Stats(ctx context.Context) (*models.OutboxStats, error) {
+	backlog, err := r.outbox.GetOutboxBacklog(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("get outbox backlog: %w", err)
+	}
+	stats := &models.OutboxStats{
+		OutboxBacklog: *backlog,
+		Delivered:     r.delivered.Load(),
+		Failed:        r.failed.Load(),
+	}
+	if backlog.OldestPendingAt != nil {
+		stats.LagSeconds = max(r.now().Sub(*backlog.OldestPendingAt).Seconds(), 0)
+	}
+	return stats, nil
+}
+
+func outboxBackoff(attempts int) time.Duration {
+	if attempts < 1 {
+		attempts = 1
+	}
+	if attempts > 10 {
+		return maxOutboxBackoff
+	}
+	return min(time.Second<<(attempts-1), maxOutboxBackoff)
+}
+
+type logOutboxPublisher struct {
+	log *slog.Logger
+}
+
+func (p logOutboxPublisher) Publish(_ context.Context, event *models.OutboxEvent) error {
+	p.log.Info("outbox event",
+		slog.Int64("event_id", event.ID),
+		slog.String("topic", event.Topic),
+		slog.String("aggregate_id", event.AggregateID),
+	)
+	return nil
+}
+
+func emitOutboxEvent(ctx context.Context, outbox OutboxWriter, topic, aggregateID string, payload any) error {
+	if outbox == nil {
+		return nil
+	}
+	body, err := json.Marshal(payload)
+	if err != nil {
+		return fmt.Errorf("marshal %s event: %w", topic, err)
+	}
+	if err := outbox.AddOutboxEvent(ctx, topic, aggregateID, body); err != nil {
+		return fmt.Errorf("add %s event: %w", topic, err)
+	}
+	return nil
+}
@@ -0,0 +1,139 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/cloudyy74/pr-reviewer-service/internal/models"
+)
+
+type fakeOutboxRepo struct {
+	mu        sync.Mutex
+	events    []*models.OutboxEvent
+	limit     int
+	lease     time.Duration
+	delivered []int64
+	failed    map[int64]time.Time
+	backlog   *models.OutboxBacklog
+}
+
+func (f *fakeOutboxRepo) ClaimOutboxEvents(_ context.Context, limit int, lease time.Duration) ([]*models.OutboxEvent, error) {
+	f.limit, f.lease = limit, lease
+	return f.events, nil
+}
+
+func (f *fakeOutboxRepo) MarkOutboxDelivered(_ context.Context, id int64) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.delivered = append(f.delivered, id)
+	return nil
+}
+
+func (f *fakeOutboxRepo) MarkOutboxFailed(_ context.Context, id int64, _ string, retryAt time.Time) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if f.failed == nil {
+		f.failed = make(map[int64]time.Time)
+	}
+	f.failed[id] = retryAt
+	return nil
+}
+
+func (f *fakeOutboxRepo) GetOutboxBacklog(context.Context) (*models.OutboxBacklog, error) {
+	if f.backlog == nil {
+		return &models.OutboxBacklog{}, nil
+	}
+	return f.backlog, nil
+}
+
+type fakeOutboxPublisher struct {
+	failIDs map[int64]bool
+}
+
+func (p fakeOutboxPublisher) Publish(_ context.Context, event *models.OutboxEvent) error {
+	if p.failIDs[event.ID] {
+		return errors.New("consumer unavailable")
+	}
+	return nil
+}
+
+func TestOutboxRelay_RelayBatch(t *testing.T) {
+	now := time.Date(2025, 3, 1, 10, 0, 0, 0, time.UTC)
+	repo := &fakeOutboxRepo{events: []*models.OutboxEvent{
+		{ID: 1, Topic: models.TopicPRCreated, Attempts: 1},
+		{ID: 2, Topic: models.TopicPRMerged, Attempts: 3},
+		{ID: 3, Topic: models.TopicPRMerged, Attempts: 1},
+	}}
+	relay, err := NewOutboxRelay(repo, fakeOutboxPublisher{failIDs: map[int64]bool{2: true}}, testLogger(),
+		WithOutboxBatching(50, 2), WithOutboxLease(time.Minute))
+	if err != nil {
+		t.Fatalf("NewOutboxRelay returned err: %v", err)
+	}
+	relay.now = func() time.Time { return now }
+
+	delivered, err := relay.RelayBatch(context.Background())
+	if err != nil {
+		t.Fatalf("RelayBatch returned err: %v", err)
+	}
+	if delivered != 2 || len(repo.delivered) != 2 {
+		t.Fatalf("expected 2 delivered events, got %d (%v)", delivered, repo.delivered)
+	}
+	if repo.limit != 50 || repo.lease != time.Minute {
+		t.Fatalf("unexpected claim params: %d %s", repo.limit, repo.lease)
+	}
+	if got := repo.failed[2]; !got.Equal(now.Add(4 * time.Second)) {
+		t.Fatalf("expected retry with backoff, got %v", got)
+	}
+
+	repo.backlog = &models.OutboxBacklog{Pending: 1, OldestPendingAt: &[]time.Time{now.Add(-90 * time.Second)}[0]}
+	stats, err := relay.Stats(context.Background())
+	if err != nil {
+		t.Fatalf("Stats returned err: %v", err)
+	}
+	if stats.Delivered != 2 || stats.Failed != 1 || stats.LagSeconds != 90 {
+		t.Fatalf("unexpected stats: %+v", stats)
+	}
+}
+
+func TestOutboxBackoff(t *testing.T) {
+	if got := outboxBackoff(1); got != time.Second {
+		t.Fatalf("unexpected first backoff: %s", got)
+	}
+	if got := outboxBackoff(30); got != maxOutboxBackoff {
+		t.Fatalf("expected capped backoff, got %s", got)
+	}
+}
+
+type fakeOutboxWriter struct {
+	topics     []string
+	aggregates []string
+}
+
+func (f *fakeOutboxWriter) AddOutboxEvent(_ context.Context, topic, aggregateID string, _ []byte) error {
+	f.topics = append(f.topics, topic)
+	f.aggregates = append(f.aggregates, aggregateID)
+	return nil
+}
+
+func TestPRService_MergePR_WritesOutboxEvent(t *testing.T) {
+	repo := &fakePRRepo{
+		getPRFn: func(_ context.Context, id string) (*models.PullRequest, error) {
+			return &models.PullRequest{ID: id, Status: models.StatusOpen}, nil
+		},
+		markMergedFn: func(context.Context, string, time.Time) error { return nil },
+	}
+	outbox := &fakeOutboxWriter{}
+	service, err := NewPRService(fakeTxManager{}, repo, &fakePRUserRepo{}, &fakePRTeamRepo{}, testLogger(), WithOutbox(outbox))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if _, err := service.MergePR(context.Background(), &models.PRMergeRequest{ID: "pr-1"}); err != nil {
+		t.Fatalf("MergePR returned err: %v", err)
+	}
+	if len(outbox.topics) != 1 || outbox.topics[0] != models.TopicPRMerged {
+		t.Fatalf("unexpected outbox events: %v", outbox.topics)
+	}
+}
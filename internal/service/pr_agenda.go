@@ -0,0 +1,74 @@
+package service
+
+import (
+	"cmp"
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"slices"
+	"time"
+
+	"github.com/cloudyy74/pr-reviewer-service/internal/models"
+	"github.com/cloudyy74/pr-reviewer-service/internal/storage"
+)
+
+// defaultAgendaDueHours is how long an unacknowledged assignment may wait
+// before it shows up as overdue when the author's team has no ack timeout.
+const defaultAgendaDueHours = 24
+
+var agendaRank = map[string]int{
+	models.AgendaOverdue:  0,
+	models.AgendaReReview: 1,
+	models.AgendaNew:      2,
+}
+
+// GetAgenda builds the daily agenda of a reviewer for standup bots: overdue
+// reviews first, then reviews they acknowledged earlier and still owe, then
+// assignments made today in their timezone, each group oldest first. Other
+// open assignments are not on the agenda.
+func (s *PRService) GetAgenda(ctx context.Context, userID string) (*models.AgendaResponse, error) {
+	userID, err := s.resolveUserRef(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	if userID == "" {
+		return nil, fmt.Errorf("%w: user_id is required", ErrPRValidation)
+	}
+
+	if _, err := s.users.GetUserWithTeam(ctx, userID); err != nil {
+		switch {
+		case errors.Is(err, storage.ErrUserNotFound):
+			return nil, ErrUserNotFound
+		default:
+			return nil, fmt.Errorf("get user: %w", err)
+		}
+	}
+
+	items, err := s.prs.GetReviewerAgenda(ctx, userID, defaultAgendaDueHours)
+	if err != nil {
+		s.log.Error("get reviewer agenda failed", slog.Any("error", err), slog.String("user_id", userID))
+		return nil, fmt.Errorf("get agenda: %w", err)
+	}
+
+	now := time.Now()
+	agenda := make([]*models.AgendaItem, 0, len(items))
+	for _, item := range items {
+		switch {
+		case item.DueAt != nil && item.DueAt.Before(now):
+			item.Kind = models.AgendaOverdue
+		case item.AcknowledgedAt != nil:
+			item.Kind = models.AgendaReReview
+		case item.AssignedToday:
+			item.Kind = models.AgendaNew
+		default:
+			continue
+		}
+		agenda = append(agenda, item)
+	}
+	slices.SortStableFunc(agenda, func(a, b *models.AgendaItem) int {
+		return cmp.Compare(agendaRank[a.Kind], agendaRank[b.Kind])
+	})
+
+	return &models.AgendaResponse{UserID: userID, Items: agenda}, nil
+}
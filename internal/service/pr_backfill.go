@@ -0,0 +1,123 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/cloudyy74/pr-reviewer-service/internal/models"
+	"github.com/cloudyy74/pr-reviewer-service/internal/storage"
+)
+
+// BackfillReviewers tops up open pull requests that have fewer than the
+// required reviewers with active teammates of the author, e.g. after members
+// join or come back. Each pull request is filled in its own transaction, so
+// one without candidates does not block the rest. An empty team name
+// covers every team.
+func (s *PRService) BackfillReviewers(ctx context.Context, req *models.BackfillReviewersRequest) (*models.BackfillReviewersResponse, error) {
+	if req == nil {
+		return nil, fmt.Errorf("%w: empty body", ErrPRValidation)
+	}
+	teamName := strings.TrimSpace(req.TeamName)
+	prs, err := s.prs.GetPRsNeedingReviewers(ctx, teamName, reviewersPerPR)
+	if err != nil {
+		return nil, fmt.Errorf("get prs needing reviewers: %w", err)
+	}
+
+	resp := &models.BackfillReviewersResponse{
+		TeamName: teamName,
+		Results:  make([]*models.PRBackfillResult, 0, len(prs)),
+	}
+	for _, need := range prs {
+		result := &models.PRBackfillResult{PullRequestID: need.ID, Added: make([]string, 0)}
+		pr, added, err := s.backfillInTx(ctx, need.ID)
+		switch {
+		case err != nil:
+			s.log.Warn("backfill reviewers failed", slog.Any("error", err), slog.String("pr_id", need.ID))
+			result.Outcome = models.BackfillOutcomeFailed
+			result.Err = err
+			resp.Failed++
+		case len(pr.Reviewers) < reviewersPerPR:
+			result.Outcome = models.BackfillOutcomePartial
+			result.Added = added
+			resp.Partial++
+		default:
+			result.Outcome = models.BackfillOutcomeFilled
+			result.Added = added
+			resp.Filled++
+		}
+		for _, id := range added {
+			s.publishAssignment(pr, id)
+		}
+		resp.Results = append(resp.Results, result)
+	}
+	return resp, nil
+}
+
+func (s *PRService) backfillInTx(ctx context.Context, prID string) (*models.PullRequest, []string, error) {
+	var (
+		pr    *models.PullRequest
+		added []string
+	)
+	err := s.tx.Run(ctx, func(ctx context.Context) error {
+		var err error
+		pr, err = s.prs.GetPR(ctx, prID)
+		if err != nil {
+			if errors.Is(err, storage.ErrPRNotFound) {
+				return ErrPRNotFound
+			}
+			return fmt.Errorf("get pr: %w", err)
+		}
+		if err := checkPROpen(pr); err != nil {
+			return err
+		}
+		author, err := s.getUser(ctx, pr.AuthorID)
+		if err != nil {
+			return err
+		}
+		teamName := strings.TrimSpace(author.TeamName)
+		if teamName == "" {
+			return ErrPRTeamNotFound
+		}
+
+		exclude := append([]string{pr.AuthorID}, pr.Reviewers...)
+		now := time.Now().UTC()
+		for len(pr.Reviewers) < reviewersPerPR {
+			candidate, err := s.users.GetRandomActiveTeammate(ctx, teamName, pr.AuthorID, exclude)
+			if errors.Is(err, storage.ErrNoCandidate) {
+				break
+			}
+			if err != nil {
+				return fmt.Errorf("get candidate: %w", err)
+			}
+			exclude = append(exclude, candidate.ID)
+			added = append(added, candidate.ID)
+			pr.Reviewers = append(pr.Reviewers, candidate.ID)
+			pr.ReviewerDetails = append(pr.ReviewerDetails, newReviewerDetail(candidate, now))
+		}
+		if len(added) == 0 {
+			return ErrNoReplacement
+		}
+		if err := s.prs.AddReviewers(ctx, pr.ID, added); err != nil {
+			return fmt.Errorf("add reviewers: %w", err)
+		}
+		if err := s.prs.AddAssignmentEvents(ctx, pr.ID, added, models.EventAssigned, "backfill"); err != nil {
+			return fmt.Errorf("record assigned events: %w", err)
+		}
+		setNeedMoreReviewers(pr)
+		return emitOutboxEvent(ctx, s.outbox, models.TopicPRReviewersAdded, pr.ID, models.PREvent{
+			PullRequest: pr,
+			AddedIDs:    slices.Clone(added),
+			Reason:      "backfill",
+			OccurredAt:  now,
+		})
+	})
+	if err != nil {
+		return nil, nil, err
+	}
+	return pr, added, nil
+}
@@ -0,0 +1,51 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"time"
+
+	"github.com/cloudyy74/pr-reviewer-service/internal/models"
+	"github.com/cloudyy74/pr-reviewer-service/internal/storage"
+)
+
+// calendarEventLength is how long a deadline event blocks in the calendar.
+const calendarEventLength = 30 * time.Minute
+
+// CalendarFeed lists the review deadlines of a reviewer for the ICS feed:
+// one event per open assignment that is not acknowledged yet, at the moment
+// it becomes overdue. Acknowledged assignments have no deadline and are left
+// out.
+func (s *PRService) CalendarFeed(ctx context.Context, userID string) (*models.CalendarFeed, error) {
+	if _, err := s.users.GetUserWithTeam(ctx, userID); err != nil {
+		switch {
+		case errors.Is(err, storage.ErrUserNotFound):
+			return nil, ErrUserNotFound
+		default:
+			return nil, fmt.Errorf("get user: %w", err)
+		}
+	}
+
+	items, err := s.prs.GetReviewerAgenda(ctx, userID, defaultAgendaDueHours)
+	if err != nil {
+		s.log.Error("get reviewer agenda failed", slog.Any("error", err), slog.String("user_id", userID))
+		return nil, fmt.Errorf("get agenda: %w", err)
+	}
+
+	feed := &models.CalendarFeed{UserID: userID, Events: make([]*models.CalendarEvent, 0, len(items))}
+	for _, item := range items {
+		if item.DueAt == nil {
+			continue
+		}
+		feed.Events = append(feed.Events, &models.CalendarEvent{
+			UID:         item.PullRequestID + "/" + userID + "/" + item.AssignedAt.UTC().Format("20060102T150405Z"),
+			Summary:     "Review due: " + item.Title,
+			Description: fmt.Sprintf("Pull request %s by %s, assigned %s.", item.PullRequestID, item.AuthorID, item.AssignedAt.UTC().Format(time.RFC3339)),
+			Start:       *item.DueAt,
+			End:         item.DueAt.Add(calendarEventLength),
+		})
+	}
+	return feed, nil
+}
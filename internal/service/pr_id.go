@@ -0,0 +1,46 @@
+package service
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"time"
+	"unicode/utf8"
+)
+
+const maxPRIDLength = 64
+
+func (s *PRService) validatePRID(prID string) error {
+	if utf8.RuneCountInString(prID) > s.idMaxLength {
+		return fmt.Errorf("%w: pull_request_id must be at most %d characters", ErrPRValidation, s.idMaxLength)
+	}
+	if s.idPattern != nil && !s.idPattern.MatchString(prID) {
+		return fmt.Errorf("%w: pull_request_id must match %s", ErrPRValidation, s.idPattern)
+	}
+	return nil
+}
+
+func newUUIDv7(now time.Time) (string, error) {
+	var b [16]byte
+	if _, err := rand.Read(b[6:]); err != nil {
+		return "", fmt.Errorf("read random bytes: %w", err)
+	}
+	ms := uint64(now.UnixMilli())
+	for i := range 6 {
+		b[i] = byte(ms >> (8 * (5 - i)))
+	}
+	b[6] = b[6]&0x0f | 0x70
+	b[8] = b[8]&0x3f | 0x80
+
+	var out [36]byte
+	hex.Encode(out[0:8], b[0:4])
+	out[8] = '-'
+	hex.Encode(out[9:13], b[4:6])
+	out[13] = '-'
+	hex.Encode(out[14:18], b[6:8])
+	out[18] = '-'
+	hex.Encode(out[19:23], b[8:10])
+	out[23] = '-'
+	hex.Encode(out[24:], b[10:])
+	return string(out[:]), nil
+}
@@ -0,0 +1,82 @@
+package service
+
+import "regexp"
+
+const defaultDuplicateThreshold = 0.85
+
+type PROption func(*PRService)
+
+func WithDuplicateCheck(threshold float64) PROption {
+	return func(s *PRService) {
+		if threshold <= 0 || threshold > 1 {
+			threshold = defaultDuplicateThreshold
+		}
+		s.duplicateCheck = true
+		s.duplicateThreshold = threshold
+	}
+}
+
+func WithAssignmentNotifier(notifier AssignmentNotifier) PROption {
+	return func(s *PRService) {
+		if notifier != nil {
+			s.assignments = notifier
+		}
+	}
+}
+
+func WithPRIDPolicy(maxLength int, pattern *regexp.Regexp) PROption {
+	return func(s *PRService) {
+		if maxLength > 0 && maxLength < maxPRIDLength {
+			s.idMaxLength = maxLength
+		}
+		s.idPattern = pattern
+	}
+}
+
+func WithGeneratedPRIDs() PROption {
+	return func(s *PRService) {
+		s.generateIDs = true
+	}
+}
+
+func WithOutbox(outbox OutboxWriter) PROption {
+	return func(s *PRService) {
+		s.outbox = outbox
+	}
+}
+
+// WithTransferCapacity limits how many open reviews a user may hold after an
+// explicit assignment transfer.
+func WithTransferCapacity(perReviewer int) PROption {
+	return func(s *PRService) {
+		if perReviewer > 0 {
+			s.transferCapacity = perReviewer
+		}
+	}
+}
+
+func WithReviewerStrategy(strategy string) PROption {
+	return func(s *PRService) {
+		if strategy != "" {
+			s.reviewerStrategy = strategy
+		}
+	}
+}
+
+// WithRequiredApprovals makes MergePR refuse pull requests until every
+// current reviewer has approved them.
+func WithRequiredApprovals() PROption {
+	return func(s *PRService) {
+		s.requireApprovals = true
+	}
+}
+
+// WithRecentReviewerMemory makes reviewer selection avoid people who reviewed
+// any of the author's last lastPRs pull requests while others are available.
+func WithRecentReviewerMemory(lastPRs int) PROption {
+	return func(s *PRService) {
+		if lastPRs > 0 {
+			s.recentReviewerPRs = lastPRs
+		}
+	}
+}
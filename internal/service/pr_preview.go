@@ -0,0 +1,90 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"strings"
+
+	"github.com/cloudyy74/pr-reviewer-service/internal/models"
+	"github.com/cloudyy74/pr-reviewer-service/internal/storage"
+)
+
+// errPreviewRollback ends the preview transaction so that nothing selection
+// touched, such as the round robin cursor, is committed.
+var errPreviewRollback = errors.New("assignment preview rolled back")
+
+// PreviewAssignment runs reviewer selection for a pull request by authorID
+// exactly as CreatePR would and reports who would be assigned and why,
+// without persisting anything. Random strategies give a different answer
+// on every call.
+func (s *PRService) PreviewAssignment(ctx context.Context, authorID string) (*models.AssignmentPreviewResponse, error) {
+	authorID, err := s.resolveUserRef(ctx, authorID)
+	if err != nil {
+		return nil, err
+	}
+	if authorID == "" {
+		return nil, fmt.Errorf("%w: author_id is required", ErrPRValidation)
+	}
+
+	var resp *models.AssignmentPreviewResponse
+	err = s.tx.Run(ctx, func(ctx context.Context) error {
+		author, err := s.users.GetUserWithTeam(ctx, authorID)
+		if err != nil {
+			switch {
+			case errors.Is(err, storage.ErrUserNotFound):
+				return ErrPRAuthorNotFound
+			default:
+				return fmt.Errorf("get author: %w", err)
+			}
+		}
+		teamName := strings.TrimSpace(author.TeamName)
+		if teamName == "" {
+			return ErrPRTeamNotFound
+		}
+
+		selected, err := s.selectReviewers(ctx, teamName, author.ID, nil)
+		if err != nil {
+			return err
+		}
+		resp = &models.AssignmentPreviewResponse{
+			AuthorID:          author.ID,
+			TeamName:          teamName,
+			Strategy:          s.reviewerStrategy,
+			RequiredReviewers: reviewersPerPR,
+			Candidates:        make([]*models.AssignmentCandidate, 0, len(selected)),
+			NeedMore:          len(selected) < reviewersPerPR,
+		}
+		for _, sel := range selected {
+			open, err := s.openReviewIDs(ctx, sel.user.ID)
+			if err != nil {
+				return err
+			}
+			candidateTeam := teamName
+			if sel.borrowedFrom != "" {
+				candidateTeam = sel.borrowedFrom
+			}
+			resp.Candidates = append(resp.Candidates, &models.AssignmentCandidate{
+				UserID:         sel.user.ID,
+				Username:       sel.user.Username,
+				TeamName:       candidateTeam,
+				Reason:         sel.reason,
+				OpenReviews:    len(open),
+				RecentReviewer: sel.recent,
+			})
+		}
+		return errPreviewRollback
+	})
+	if err != nil && !errors.Is(err, errPreviewRollback) {
+		switch {
+		case errors.Is(err, ErrPRAuthorNotFound),
+			errors.Is(err, ErrPRTeamNotFound):
+			return nil, err
+		default:
+			s.log.Error("preview assignment failed", slog.Any("error", err), slog.String("author_id", authorID))
+			return nil, fmt.Errorf("preview assignment: %w", err)
+		}
+	}
+	return resp, nil
+}
@@ -0,0 +1,223 @@
+package service
+
+import (
+	"cmp"
+	"context"
+	"errors"
+	"fmt"
+	"maps"
+	"slices"
+	"strings"
+
+	"github.com/cloudyy74/pr-reviewer-service/internal/models"
+)
+
+// RebalanceAssignments evens out open reviews among the active members of a
+// team in a single transaction. It repeatedly moves one review from the
+// most loaded member to the least loaded one who may take it (not the author,
+// not already a reviewer, not excluded) until loads differ by at most one or
+// no such move remains. Reviews held by people outside the team are left
+// alone.
+//
+// With Preview the moves are computed and rolled back, and the response
+// carries a change-set token over the loads and moves. Apply must pass that
+// token; it is recomputed in the apply transaction, so if the plan came out
+// different the transaction is rolled back with ErrStalePreview.
+func (s *PRService) RebalanceAssignments(ctx context.Context, req *models.RebalanceAssignmentsRequest) (*models.RebalanceAssignmentsResponse, error) {
+	if req == nil {
+		return nil, fmt.Errorf("%w: empty body", ErrPRValidation)
+	}
+	teamName := strings.TrimSpace(req.TeamName)
+	if teamName == "" {
+		return nil, fmt.Errorf("%w: team_name is required", ErrPRValidation)
+	}
+
+	resp := &models.RebalanceAssignmentsResponse{
+		TeamName: teamName,
+		Moves:    make([]*models.RebalanceMove, 0),
+	}
+	var moved []*models.PullRequest
+	err := s.tx.Run(ctx, func(ctx context.Context) error {
+		loads, err := s.users.GetTeamReviewLoad(ctx, teamName)
+		if err != nil {
+			return fmt.Errorf("get team review load: %w", err)
+		}
+		if len(loads) == 0 {
+			return fmt.Errorf("%w: %s has no active members", ErrPRTeamNotFound, teamName)
+		}
+		resp.Before = loads
+
+		r := &rebalancer{
+			s:         s,
+			load:      make(map[string]int, len(loads)),
+			prs:       make(map[string]*models.PullRequest),
+			excluded:  make(map[string][]string),
+			exhausted: make(map[string]bool),
+		}
+		for _, l := range loads {
+			r.load[l.UserID] = l.OpenAssignments
+		}
+		for {
+			move, err := r.next(ctx, loads)
+			if err != nil {
+				return err
+			}
+			if move == nil {
+				break
+			}
+			reason := fmt.Sprintf("rebalanced from %s to %s", move.FromUserID, move.to.UserID)
+			recipient := &models.User{ID: move.to.UserID, Username: move.to.Username, IsActive: true}
+			if err := s.applyReplacement(ctx, move.pr, move.FromUserID, recipient, models.EventReassigned, reason); err != nil {
+				return err
+			}
+			r.load[move.FromUserID]--
+			r.load[move.to.UserID]++
+			resp.Moves = append(resp.Moves, &move.RebalanceMove)
+			moved = append(moved, move.pr)
+		}
+
+		resp.After = make([]*models.ReviewerLoad, 0, len(loads))
+		for _, l := range loads {
+			after := *l
+			after.OpenAssignments = r.load[l.UserID]
+			resp.After = append(resp.After, &after)
+		}
+
+		resp.ChangeSetToken = rebalanceToken(teamName, resp.Before, resp.Moves)
+		if req.Preview {
+			return errPreviewRollback
+		}
+		return checkChangeSet(req.ChangeSetToken, resp.ChangeSetToken)
+	})
+	if req.Preview && errors.Is(err, errPreviewRollback) {
+		resp.Preview = true
+		return resp, nil
+	}
+	if err != nil {
+		switch {
+		case errors.Is(err, ErrPRValidation),
+			errors.Is(err, ErrPRTeamNotFound),
+			errors.Is(err, ErrReviewerNotAssigned),
+			errors.Is(err, ErrStalePreview):
+			return nil, err
+		default:
+			return nil, fmt.Errorf("rebalance assignments transaction: %w", err)
+		}
+	}
+
+	for i, pr := range moved {
+		s.publishAssignment(pr, resp.Moves[i].ToUserID)
+	}
+	return resp, nil
+}
+
+func rebalanceToken(teamName string, loads []*models.ReviewerLoad, moves []*models.RebalanceMove) string {
+	parts := make([]string, 0, 1+len(loads)+len(moves))
+	parts = append(parts, teamName)
+	for _, l := range loads {
+		parts = append(parts, fmt.Sprintf("%s=%d", l.UserID, l.OpenAssignments))
+	}
+	for _, m := range moves {
+		parts = append(parts, m.PullRequestID+":"+m.FromUserID+">"+m.ToUserID)
+	}
+	return changeSetToken("rebalance", parts...)
+}
+
+type rebalanceMove struct {
+	models.RebalanceMove
+	pr *models.PullRequest
+	to *models.ReviewerLoad
+}
+
+// rebalancer keeps the state of one rebalance: current loads, pull requests
+// already read and members that have nothing left to give away.
+type rebalancer struct {
+	s         *PRService
+	load      map[string]int
+	prs       map[string]*models.PullRequest
+	excluded  map[string][]string
+	exhausted map[string]bool
+}
+
+// next picks the next move, or returns nil when the team is balanced or no
+// further move is possible.
+func (r *rebalancer) next(ctx context.Context, loads []*models.ReviewerLoad) (*rebalanceMove, error) {
+	byLoad := slices.Clone(loads)
+	slices.SortStableFunc(byLoad, func(a, b *models.ReviewerLoad) int {
+		return cmp.Compare(r.load[a.UserID], r.load[b.UserID])
+	})
+	for {
+		var donor *models.ReviewerLoad
+		for i := len(byLoad) - 1; i >= 0; i-- {
+			if !r.exhausted[byLoad[i].UserID] {
+				donor = byLoad[i]
+				break
+			}
+		}
+		if donor == nil || r.load[donor.UserID]-r.load[byLoad[0].UserID] <= 1 {
+			return nil, nil
+		}
+
+		prIDs, err := r.s.openReviewIDs(ctx, donor.UserID)
+		if err != nil {
+			return nil, err
+		}
+		if err := r.fetch(ctx, prIDs); err != nil {
+			return nil, err
+		}
+		for _, to := range byLoad {
+			if r.load[donor.UserID]-r.load[to.UserID] <= 1 {
+				break
+			}
+			for _, prID := range prIDs {
+				pr := r.prs[prID]
+				ok, err := r.canTake(ctx, pr, to.UserID)
+				if err != nil {
+					return nil, err
+				}
+				if ok {
+					return &rebalanceMove{
+						RebalanceMove: models.RebalanceMove{PullRequestID: pr.ID, FromUserID: donor.UserID, ToUserID: to.UserID},
+						pr:            pr,
+						to:            to,
+					}, nil
+				}
+			}
+		}
+		r.exhausted[donor.UserID] = true
+	}
+}
+
+// fetch reads the pull requests among prIDs that are not cached yet in one
+// batch.
+func (r *rebalancer) fetch(ctx context.Context, prIDs []string) error {
+	missing := slices.DeleteFunc(slices.Clone(prIDs), func(id string) bool {
+		_, ok := r.prs[id]
+		return ok
+	})
+	if len(missing) == 0 {
+		return nil
+	}
+	prs, err := r.s.getPRsByID(ctx, missing)
+	if err != nil {
+		return err
+	}
+	maps.Copy(r.prs, prs)
+	return nil
+}
+
+func (r *rebalancer) canTake(ctx context.Context, pr *models.PullRequest, userID string) (bool, error) {
+	if pr.AuthorID == userID || slices.Contains(pr.Reviewers, userID) {
+		return false, nil
+	}
+	excluded, ok := r.excluded[pr.AuthorID]
+	if !ok {
+		var err error
+		excluded, err = r.s.users.GetExcludedReviewers(ctx, pr.AuthorID)
+		if err != nil {
+			return false, fmt.Errorf("get excluded reviewers: %w", err)
+		}
+		r.excluded[pr.AuthorID] = excluded
+	}
+	return !slices.Contains(excluded, userID), nil
+}
@@ -0,0 +1,194 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/cloudyy74/pr-reviewer-service/internal/models"
+	"github.com/cloudyy74/pr-reviewer-service/internal/storage"
+)
+
+const (
+	manualAssignReason  = "manual"
+	defaultRemoveReason = "removed manually"
+)
+
+// AddReviewer attaches the given user to an open pull request as a reviewer,
+// bypassing the reviewer strategy, e.g. when someone volunteers. The user
+// must be active, must not be the author or excluded by the author, and may
+// come from any team. Manual reviewers count like assigned ones, so a pull
+// request may end up with more than the usual two.
+func (s *PRService) AddReviewer(ctx context.Context, req *models.PRAddReviewerRequest) (*models.PullRequest, error) {
+	if req == nil {
+		return nil, fmt.Errorf("%w: empty body", ErrPRValidation)
+	}
+	prID := strings.TrimSpace(req.ID)
+	userID, err := s.resolveUserRef(ctx, req.UserID)
+	if err != nil {
+		return nil, err
+	}
+	if prID == "" {
+		return nil, fmt.Errorf("%w: pull_request_id is required", ErrPRValidation)
+	}
+	if userID == "" {
+		return nil, fmt.Errorf("%w: user_id is required", ErrPRValidation)
+	}
+
+	var pr *models.PullRequest
+	err = s.tx.Run(ctx, func(ctx context.Context) error {
+		pr, err = s.getOpenPR(ctx, prID)
+		if err != nil {
+			return err
+		}
+		user, err := s.getUser(ctx, userID)
+		if err != nil {
+			return err
+		}
+		if !user.IsActive {
+			return fmt.Errorf("%w: user %s is not active", ErrPRValidation, userID)
+		}
+		if userID == pr.AuthorID {
+			return fmt.Errorf("%w: the author cannot review their own pull request", ErrPRValidation)
+		}
+		if slices.Contains(pr.Reviewers, userID) {
+			return ErrReviewerAlreadyAssigned
+		}
+		excluded, err := s.users.GetExcludedReviewers(ctx, pr.AuthorID)
+		if err != nil {
+			return fmt.Errorf("get excluded reviewers: %w", err)
+		}
+		if slices.Contains(excluded, userID) {
+			return fmt.Errorf("%w: %s is excluded from reviewing pull requests of %s", ErrPRValidation, userID, pr.AuthorID)
+		}
+
+		if err := s.prs.AddReviewers(ctx, pr.ID, []string{userID}); err != nil {
+			if errors.Is(err, storage.ErrReviewerAssigned) {
+				return ErrReviewerAlreadyAssigned
+			}
+			return fmt.Errorf("add reviewer: %w", err)
+		}
+		if err := s.prs.AddAssignmentEvents(ctx, pr.ID, []string{userID}, models.EventAssigned, manualAssignReason); err != nil {
+			return fmt.Errorf("record assigned event: %w", err)
+		}
+		now := time.Now().UTC()
+		pr.Reviewers = append(pr.Reviewers, userID)
+		pr.ReviewerDetails = append(pr.ReviewerDetails, newReviewerDetail(&user.User, now))
+		setNeedMoreReviewers(pr)
+		return emitOutboxEvent(ctx, s.outbox, models.TopicPRReviewersAdded, pr.ID, models.PREvent{
+			PullRequest: pr,
+			AddedIDs:    []string{userID},
+			Reason:      manualAssignReason,
+			OccurredAt:  now,
+		})
+	})
+	if err != nil {
+		return nil, reviewerChangeError(err, "add reviewer")
+	}
+
+	s.log.Info("reviewer added manually", slog.String("pr_id", pr.ID), slog.String("user_id", userID))
+	s.publishAssignment(pr, userID)
+	return pr, nil
+}
+
+// RemoveReviewer detaches a reviewer from an open pull request without
+// picking a replacement. The assignment history records REMOVED with the
+// given reason; missing reviewers can be filled up later by backfill.
+func (s *PRService) RemoveReviewer(ctx context.Context, req *models.PRRemoveReviewerRequest) (*models.PullRequest, error) {
+	if req == nil {
+		return nil, fmt.Errorf("%w: empty body", ErrPRValidation)
+	}
+	prID := strings.TrimSpace(req.ID)
+	userID, err := s.resolveUserRef(ctx, req.UserID)
+	if err != nil {
+		return nil, err
+	}
+	if prID == "" {
+		return nil, fmt.Errorf("%w: pull_request_id is required", ErrPRValidation)
+	}
+	if userID == "" {
+		return nil, fmt.Errorf("%w: user_id is required", ErrPRValidation)
+	}
+	reason := strings.TrimSpace(req.Reason)
+	if len(reason) > maxDeclineReasonLength {
+		return nil, fmt.Errorf("%w: reason must be at most %d characters", ErrPRValidation, maxDeclineReasonLength)
+	}
+	if reason == "" {
+		reason = defaultRemoveReason
+	}
+
+	var pr *models.PullRequest
+	err = s.tx.Run(ctx, func(ctx context.Context) error {
+		pr, err = s.getOpenPR(ctx, prID)
+		if err != nil {
+			return err
+		}
+		if !slices.Contains(pr.Reviewers, userID) {
+			return ErrReviewerNotAssigned
+		}
+		if err := s.prs.RemoveReviewer(ctx, pr.ID, userID); err != nil {
+			if errors.Is(err, storage.ErrReviewerNotAssigned) {
+				return ErrReviewerNotAssigned
+			}
+			return fmt.Errorf("remove reviewer: %w", err)
+		}
+		if err := s.prs.AddAssignmentEvents(ctx, pr.ID, []string{userID}, models.EventRemoved, reason); err != nil {
+			return fmt.Errorf("record removed event: %w", err)
+		}
+		pr.Reviewers = slices.DeleteFunc(pr.Reviewers, func(id string) bool { return id == userID })
+		pr.ReviewerDetails = slices.DeleteFunc(pr.ReviewerDetails, func(d *models.ReviewerDetail) bool { return d.UserID == userID })
+		setNeedMoreReviewers(pr)
+		return emitOutboxEvent(ctx, s.outbox, models.TopicPRReviewerRemoved, pr.ID, models.PREvent{
+			PullRequest: pr,
+			ReviewerID:  userID,
+			Reason:      reason,
+			OccurredAt:  time.Now().UTC(),
+		})
+	})
+	if err != nil {
+		return nil, reviewerChangeError(err, "remove reviewer")
+	}
+
+	s.log.Info("reviewer removed manually",
+		slog.String("pr_id", pr.ID),
+		slog.String("user_id", userID),
+		slog.String("reason", reason),
+	)
+	return pr, nil
+}
+
+func (s *PRService) getOpenPR(ctx context.Context, prID string) (*models.PullRequest, error) {
+	pr, err := s.prs.GetPR(ctx, prID)
+	if err != nil {
+		switch {
+		case errors.Is(err, storage.ErrPRNotFound):
+			return nil, ErrPRNotFound
+		default:
+			s.log.Error("get pr failed", slog.Any("error", err), slog.String("pr_id", prID))
+			return nil, fmt.Errorf("get pr: %w", err)
+		}
+	}
+	if err := checkPROpen(pr); err != nil {
+		return nil, err
+	}
+	return pr, nil
+}
+
+func reviewerChangeError(err error, op string) error {
+	switch {
+	case errors.Is(err, ErrPRValidation),
+		errors.Is(err, ErrPRNotFound),
+		errors.Is(err, ErrUserNotFound),
+		errors.Is(err, ErrReviewerNotAssigned),
+		errors.Is(err, ErrReviewerAlreadyAssigned),
+		errors.Is(err, ErrPRMerged),
+		errors.Is(err, ErrPRClosed):
+		return err
+	default:
+		return fmt.Errorf("%s transaction: %w", op, err)
+	}
+}
@@ -5,51 +5,130 @@ import (
 	"errors"
 	"fmt"
 	"log/slog"
+	"regexp"
 	"slices"
 	"strings"
 	"time"
+	"unicode/utf8"
 
+	"github.com/cloudyy74/pr-reviewer-service/internal/hub"
+	"github.com/cloudyy74/pr-reviewer-service/internal/metrics"
 	"github.com/cloudyy74/pr-reviewer-service/internal/models"
 	"github.com/cloudyy74/pr-reviewer-service/internal/storage"
 )
 
-const reviewersPerPR = 2
+const (
+	ReviewerStrategyRandom       = "random"
+	ReviewerStrategyLoadBalanced = "load_balanced"
+	ReviewerStrategyRoundRobin   = "round_robin"
+	ReviewerStrategyWorkingHours = "working_hours"
+)
+
+const (
+	reviewersPerPR      = 2
+	defaultAwaitTimeout = 30 * time.Second
+	maxAwaitTimeout     = 60 * time.Second
+	defaultHistoryLimit = 50
+	maxHistoryLimit     = 100
+	// MaxBatchGetPRs bounds how many pull requests one BatchGetPRs call may
+	// ask for.
+	MaxBatchGetPRs = 100
+
+	maxDeclineReasonLength = 256
+	defaultDeclineReason   = "declined by reviewer"
+)
 
 var (
-	ErrPRValidation        = errors.New("validation error")
-	ErrPRAuthorNotFound    = errors.New("author not found")
-	ErrPRTeamNotFound      = errors.New("team not found")
-	ErrPRAlreadyExists     = errors.New("pull request already exists")
-	ErrPRNotFound          = errors.New("pull request not found")
-	ErrPRMerged            = errors.New("pull request already merged")
-	ErrReviewerNotAssigned = errors.New("reviewer not assigned")
-	ErrNoReplacement       = errors.New("no replacement candidate")
+	ErrPRValidation            = errors.New("validation error")
+	ErrPRAuthorNotFound        = errors.New("author not found")
+	ErrPRTeamNotFound          = errors.New("team not found")
+	ErrPRAlreadyExists         = errors.New("pull request already exists")
+	ErrPRNotFound              = errors.New("pull request not found")
+	ErrPRMerged                = errors.New("pull request already merged")
+	ErrPRClosed                = errors.New("pull request is closed")
+	ErrReviewerNotAssigned     = errors.New("reviewer not assigned")
+	ErrReviewerAlreadyAssigned = errors.New("reviewer already assigned")
+	ErrNoReplacement           = errors.New("no replacement candidate")
+	ErrPRDuplicate             = errors.New("duplicate pull request")
+	ErrPRStatusMissing         = errors.New("pull request status is not configured")
+	ErrReviewerOverloaded      = errors.New("reviewer is over capacity")
+	ErrPRNotApproved           = errors.New("pull request is not approved by all reviewers")
 )
 
 type PRRepository interface {
 	CreatePR(ctx context.Context, pr models.PullRequest) (*models.PullRequest, error)
 	AddReviewers(ctx context.Context, prID string, reviewerIDs []string) error
+	AddAssignmentEvents(ctx context.Context, prID string, userIDs []string, event, reason string) error
+	AcknowledgeReviewer(ctx context.Context, prID, userID string, at time.Time) (bool, error)
+	SetReviewState(ctx context.Context, prID, userID, state string, at time.Time) (bool, error)
+	GetExpiredAssignments(ctx context.Context, now time.Time) ([]*models.ReviewerAssignment, error)
+	GetAssignmentHistory(ctx context.Context, q models.AssignmentHistoryQuery) ([]*models.AssignmentHistoryItem, int, error)
 	GetReviewerPRs(ctx context.Context, userID string) ([]*models.PullRequestShort, error)
+	GetOpenPRsByAuthor(ctx context.Context, authorID string) ([]*models.PullRequestShort, error)
+	GetReviewerAgenda(ctx context.Context, userID string, defaultDueHours int) ([]*models.AgendaItem, error)
+	GetPRsNeedingReviewers(ctx context.Context, teamName string, minReviewers int) ([]*models.PRNeedingReviewers, error)
 	GetPR(ctx context.Context, prID string) (*models.PullRequest, error)
+	GetPRsByIDs(ctx context.Context, prIDs []string) ([]*models.PullRequest, error)
+	ListPRs(ctx context.Context, q models.PRListQuery) ([]*models.PullRequest, int, error)
+	UpdatePRDetails(ctx context.Context, prID, title, description string) error
+	SetPRLabels(ctx context.Context, prID string, labels []string) error
+	GetRecentReviewers(ctx context.Context, authorID string, lastPRs int) ([]string, error)
 	MarkPRMerged(ctx context.Context, prID string, mergedAt time.Time) error
+	MarkPRClosed(ctx context.Context, prID string, closedAt time.Time) error
 	ReplaceReviewer(ctx context.Context, prID, oldReviewerID, newReviewerID string) error
+	RemoveReviewer(ctx context.Context, prID, reviewerID string) error
 	GetAssignmentsStats(ctx context.Context) (*models.AssignmentsStatsResponse, error)
 }
 
 type PRUserRepository interface {
 	GetUserWithTeam(ctx context.Context, userID string) (*models.UserWithTeam, error)
 	GetActiveTeammates(ctx context.Context, teamName, excludeUserID string, limit int) ([]*models.User, error)
-	GetRandomActiveTeammate(ctx context.Context, teamName string, excludeIDs []string) (*models.User, error)
+	GetLeastLoadedTeammates(ctx context.Context, teamName, excludeUserID string, limit int) ([]*models.User, error)
+	GetNextRotationTeammates(ctx context.Context, teamName, excludeUserID string, limit int) ([]*models.User, error)
+	GetOverlappingTeammates(ctx context.Context, teamName, excludeUserID string, limit int) ([]*models.User, error)
+	GetRandomActiveTeammate(ctx context.Context, teamName, authorID string, excludeIDs []string) (*models.User, error)
+	GetUsersByUsername(ctx context.Context, username string) ([]*models.UserWithTeam, error)
+	GetTeamReviewLoad(ctx context.Context, teamName string) ([]*models.ReviewerLoad, error)
+	GetExcludedReviewers(ctx context.Context, authorID string) ([]string, error)
+}
+
+type PRTeamRepository interface {
+	GetTeamSettings(ctx context.Context, teamName string) (*models.TeamSettings, error)
+}
+
+type AssignmentNotifier interface {
+	Publish(event models.AssignmentEvent)
+	Subscribe(userID string) (<-chan models.AssignmentEvent, func())
 }
 
 type PRService struct {
-	tx    txManager
-	prs   PRRepository
-	users PRUserRepository
-	log   *slog.Logger
+	tx          txManager
+	prs         PRRepository
+	users       PRUserRepository
+	teams       PRTeamRepository
+	assignments AssignmentNotifier
+	outbox      OutboxWriter
+	log         *slog.Logger
+
+	duplicateCheck     bool
+	duplicateThreshold float64
+	idMaxLength        int
+	idPattern          *regexp.Regexp
+	generateIDs        bool
+	transferCapacity   int
+	reviewerStrategy   string
+	recentReviewerPRs  int
+	requireApprovals   bool
 }
 
-func NewPRService(tx txManager, prs PRRepository, users PRUserRepository, log *slog.Logger) (*PRService, error) {
+func NewPRService(
+	tx txManager,
+	prs PRRepository,
+	users PRUserRepository,
+	teams PRTeamRepository,
+	log *slog.Logger,
+	opts ...PROption,
+) (*PRService, error) {
 	if tx == nil {
 		return nil, errors.New("tx manager cannot be nil")
 	}
@@ -59,31 +138,118 @@ func NewPRService(tx txManager, prs PRRepository, users PRUserRepository, log *s
 	if users == nil {
 		return nil, errors.New("user repository cannot be nil")
 	}
+	if teams == nil {
+		return nil, errors.New("team repository cannot be nil")
+	}
 	if log == nil {
 		return nil, errors.New("logger cannot be nil")
 	}
-	return &PRService{tx: tx, prs: prs, users: users, log: log}, nil
+	s := &PRService{
+		tx:          tx,
+		prs:         prs,
+		users:       users,
+		teams:       teams,
+		assignments: hub.NewAssignmentHub(),
+		log:         log,
+		idMaxLength: maxPRIDLength,
+
+		reviewerStrategy: ReviewerStrategyRandom,
+	}
+	for _, opt := range opts {
+		opt(s)
+	}
+	return s, nil
 }
 
-func (s *PRService) CreatePR(ctx context.Context, req *models.PRCreateRequest) (*models.PullRequest, error) {
+func (s *PRService) CreatePR(ctx context.Context, req *models.PRCreateRequest) (*models.PRResponse, error) {
 	if req == nil {
 		return nil, fmt.Errorf("%w: empty body", ErrPRValidation)
 	}
 	prID := strings.TrimSpace(req.ID)
 	title := strings.TrimSpace(req.Title)
-	authorID := strings.TrimSpace(req.AuthorID)
+	authorID, err := s.resolveUserRef(ctx, req.AuthorID)
+	if err != nil {
+		if errors.Is(err, ErrUserNotFound) {
+			return nil, ErrPRAuthorNotFound
+		}
+		return nil, err
+	}
+	if prID == "" && req.Idempotent {
+		// A generated id differs on every retry, so there would be nothing
+		// to match a redelivery against.
+		return nil, fmt.Errorf("%w: pull_request_id is required for idempotent creates", ErrPRValidation)
+	}
+	if prID == "" && s.generateIDs {
+		generated, err := newUUIDv7(time.Now())
+		if err != nil {
+			return nil, fmt.Errorf("generate pull_request_id: %w", err)
+		}
+		prID = generated
+	}
 	if prID == "" {
 		return nil, fmt.Errorf("%w: pull_request_id is required", ErrPRValidation)
 	}
+	if err := s.validatePRID(prID); err != nil {
+		return nil, err
+	}
 	if title == "" {
 		return nil, fmt.Errorf("%w: pull_request_name is required", ErrPRValidation)
 	}
 	if authorID == "" {
 		return nil, fmt.Errorf("%w: author_id is required", ErrPRValidation)
 	}
+	description := strings.TrimSpace(req.Description)
+	if utf8.RuneCountInString(description) > maxPRDescriptionLength {
+		return nil, fmt.Errorf("%w: description must be at most %d characters", ErrPRValidation, maxPRDescriptionLength)
+	}
+	repositoryURL, err := normalizeRepositoryURL(req.RepositoryURL)
+	if err != nil {
+		return nil, err
+	}
+	branch, err := normalizeBranch(req.Branch)
+	if err != nil {
+		return nil, err
+	}
+	labels, err := normalizeLabels(req.Labels)
+	if err != nil {
+		return nil, err
+	}
+	priority, err := normalizePriority(req.Priority)
+	if err != nil {
+		return nil, err
+	}
+	var deadline *time.Time
+	if req.Deadline != nil {
+		d := req.Deadline.UTC()
+		deadline = &d
+	}
+	want := models.PullRequest{
+		ID:            prID,
+		Title:         title,
+		Description:   description,
+		RepositoryURL: repositoryURL,
+		Branch:        branch,
+		Labels:        labels,
+		Priority:      priority,
+		AuthorID:      authorID,
+		Deadline:      deadline,
+	}
+	if req.Idempotent {
+		// Checked before reviewer selection, so a redelivery does not fail
+		// on a team that has since run out of candidates.
+		if resp, err := s.existingPR(ctx, want); resp != nil || err != nil {
+			return resp, err
+		}
+	}
+	// Validated after the idempotent lookup: a redelivery may arrive once the
+	// deadline it carries has already passed.
+	if deadline != nil && !deadline.After(time.Now()) {
+		return nil, fmt.Errorf("%w: deadline must be in the future", ErrPRValidation)
+	}
 
 	var createdPR *models.PullRequest
-	err := s.tx.Run(ctx, func(ctx context.Context) error {
+	var warnings []string
+	err = s.tx.Run(ctx, func(ctx context.Context) error {
 		author, err := s.users.GetUserWithTeam(ctx, authorID)
 		if err != nil {
 			switch {
@@ -98,53 +264,247 @@ func (s *PRService) CreatePR(ctx context.Context, req *models.PRCreateRequest) (
 			return ErrPRTeamNotFound
 		}
 
-		teammates, err := s.users.GetActiveTeammates(ctx, teamName, author.ID, reviewersPerPR)
+		warnings, err = s.findDuplicates(ctx, prID, title, author.ID, teamName)
 		if err != nil {
-			return fmt.Errorf("get teammates: %w", err)
+			return err
 		}
-		reviewers := make([]string, 0, len(teammates))
-		for _, tm := range teammates {
-			reviewers = append(reviewers, tm.ID)
+
+		required, skipped, err := s.requiredReviewers(ctx, teamName, author.ID, req.Components, req.Paths)
+		if err != nil {
+			return err
+		}
+		warnings = append(warnings, skipped...)
+		selected, err := s.selectReviewers(ctx, teamName, author.ID, required)
+		if err != nil {
+			return err
+		}
+		assignedAt := time.Now().UTC()
+		reviewers := make([]string, 0, len(selected))
+		details := make([]*models.ReviewerDetail, 0, len(selected))
+		for _, sel := range selected {
+			reviewers = append(reviewers, sel.user.ID)
+			detail := newReviewerDetail(sel.user, assignedAt)
+			detail.BorrowedFrom = sel.borrowedFrom
+			details = append(details, detail)
 		}
 		pr := models.PullRequest{
-			ID:       prID,
-			Title:    title,
-			AuthorID: author.ID,
-			Status:   models.StatusOpen,
+			ID:            prID,
+			Title:         title,
+			Description:   description,
+			RepositoryURL: repositoryURL,
+			Branch:        branch,
+			Priority:      priority,
+			Deadline:      deadline,
+			AuthorID:      author.ID,
+			Status:        models.StatusOpen,
 		}
 		created, err := s.prs.CreatePR(ctx, pr)
 		if err != nil {
 			switch {
 			case errors.Is(err, storage.ErrPRExists):
 				return ErrPRAlreadyExists
+			case errors.Is(err, storage.ErrStatusNotFound):
+				return fmt.Errorf("%w: %s", ErrPRStatusMissing, pr.Status)
 			default:
 				return fmt.Errorf("create pr: %w", err)
 			}
 		}
+		if len(labels) > 0 {
+			if err := s.prs.SetPRLabels(ctx, created.ID, labels); err != nil {
+				return fmt.Errorf("set pr labels: %w", err)
+			}
+			created.Labels = labels
+		}
 		if err := s.prs.AddReviewers(ctx, created.ID, reviewers); err != nil {
 			return fmt.Errorf("add reviewers: %w", err)
 		}
+		if err := s.prs.AddAssignmentEvents(ctx, created.ID, reviewers, models.EventAssigned, ""); err != nil {
+			return fmt.Errorf("record assigned events: %w", err)
+		}
 		created.Reviewers = reviewers
+		created.ReviewerDetails = details
+		setNeedMoreReviewers(created)
+		created.Author = author
 		createdPR = created
-		return nil
+		return emitOutboxEvent(ctx, s.outbox, models.TopicPRCreated, created.ID, models.PREvent{
+			PullRequest: created,
+			OccurredAt:  assignedAt,
+		})
 	})
 	if err != nil {
 		switch {
+		case errors.Is(err, ErrPRAlreadyExists) && req.Idempotent:
+			// A concurrent delivery created it after the check above.
+			if resp, err := s.existingPR(ctx, want); resp != nil || err != nil {
+				return resp, err
+			}
+			return nil, ErrPRAlreadyExists
 		case errors.Is(err, ErrPRValidation),
 			errors.Is(err, ErrPRAuthorNotFound),
 			errors.Is(err, ErrPRTeamNotFound),
-			errors.Is(err, ErrPRAlreadyExists):
+			errors.Is(err, ErrPRAlreadyExists),
+			errors.Is(err, ErrPRDuplicate):
 			return nil, err
 		default:
 			s.log.Error("create pr transaction failed", slog.Any("error", err))
 			return nil, fmt.Errorf("create pr transaction: %w", err)
 		}
 	}
-	return createdPR, nil
+	for _, reviewerID := range createdPR.Reviewers {
+		s.publishAssignment(createdPR, reviewerID)
+	}
+	return &models.PRResponse{PR: *createdPR, Warnings: warnings}, nil
+}
+
+// existingPR looks up a pull request an idempotent create may have already
+// made. It returns nil if there is none, and ErrPRAlreadyExists if the stored
+// one differs from the request, which is a conflict rather than a retry.
+func (s *PRService) existingPR(ctx context.Context, want models.PullRequest) (*models.PRResponse, error) {
+	pr, err := s.prs.GetPR(ctx, want.ID)
+	if errors.Is(err, storage.ErrPRNotFound) {
+		return nil, nil
+	}
+	if err != nil {
+		s.log.Error("get existing pr failed", slog.Any("error", err), slog.String("pr_id", want.ID))
+		return nil, fmt.Errorf("get existing pr: %w", err)
+	}
+	if diff := createDiff(pr, &want); len(diff) > 0 {
+		return nil, fmt.Errorf("%w with different %s", ErrPRAlreadyExists, strings.Join(diff, ", "))
+	}
+	setNeedMoreReviewers(pr)
+	return &models.PRResponse{PR: *pr, Existing: true}, nil
+}
+
+// createDiff lists the create fields in which a stored pull request differs
+// from a normalized create request.
+func createDiff(pr, want *models.PullRequest) []string {
+	var diff []string
+	if pr.Title != want.Title {
+		diff = append(diff, "pull_request_name")
+	}
+	if pr.AuthorID != want.AuthorID {
+		diff = append(diff, "author_id")
+	}
+	if pr.Description != want.Description {
+		diff = append(diff, "description")
+	}
+	if pr.RepositoryURL != want.RepositoryURL {
+		diff = append(diff, "repository_url")
+	}
+	if pr.Branch != want.Branch {
+		diff = append(diff, "branch")
+	}
+	if !slices.Equal(slices.Sorted(slices.Values(pr.Labels)), want.Labels) {
+		diff = append(diff, "labels")
+	}
+	if pr.Priority != want.Priority {
+		diff = append(diff, "priority")
+	}
+	// A deadline left out of the request comes from the team SLA and is
+	// not compared.
+	if want.Deadline != nil && (pr.Deadline == nil || !pr.Deadline.Equal(*want.Deadline)) {
+		diff = append(diff, "deadline")
+	}
+	return diff
+}
+
+func (s *PRService) publishAssignment(pr *models.PullRequest, reviewerID string) {
+	s.assignments.Publish(models.AssignmentEvent{
+		UserID:          reviewerID,
+		PullRequestID:   pr.ID,
+		PullRequestName: pr.Title,
+		AuthorID:        pr.AuthorID,
+		AssignedAt:      time.Now().UTC(),
+	})
+}
+
+func (s *PRService) AwaitAssignment(ctx context.Context, userID string, timeout time.Duration) (*models.AwaitAssignmentResponse, error) {
+	userID, err := s.resolveUserRef(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	if userID == "" {
+		return nil, fmt.Errorf("%w: user_id is required", ErrPRValidation)
+	}
+	if timeout < 0 {
+		return nil, fmt.Errorf("%w: timeout must be positive", ErrPRValidation)
+	}
+	if timeout == 0 {
+		timeout = defaultAwaitTimeout
+	}
+	timeout = min(timeout, maxAwaitTimeout)
+
+	if _, err := s.users.GetUserWithTeam(ctx, userID); err != nil {
+		switch {
+		case errors.Is(err, storage.ErrUserNotFound):
+			return nil, ErrUserNotFound
+		default:
+			s.log.Error("get user info failed", slog.Any("error", err))
+			return nil, fmt.Errorf("get user: %w", err)
+		}
+	}
+
+	events, unsubscribe := s.assignments.Subscribe(userID)
+	defer unsubscribe()
+
+	timer := time.NewTimer(timeout)
+	defer timer.Stop()
+
+	select {
+	case event := <-events:
+		return &models.AwaitAssignmentResponse{UserID: userID, Assignment: &event}, nil
+	case <-timer.C:
+		return &models.AwaitAssignmentResponse{UserID: userID, TimedOut: true}, nil
+	case <-ctx.Done():
+		return nil, fmt.Errorf("await assignment: %w", ctx.Err())
+	}
+}
+
+func (s *PRService) findDuplicates(ctx context.Context, prID, title, authorID, teamName string) ([]string, error) {
+	if !s.duplicateCheck {
+		return nil, nil
+	}
+	openPRs, err := s.prs.GetOpenPRsByAuthor(ctx, authorID)
+	if err != nil {
+		return nil, fmt.Errorf("get author open prs: %w", err)
+	}
+
+	var duplicateIDs []string
+	var warnings []string
+	for _, pr := range openPRs {
+		if pr.ID == prID {
+			continue
+		}
+		if titleSimilarity(title, pr.Title) < s.duplicateThreshold {
+			continue
+		}
+		duplicateIDs = append(duplicateIDs, pr.ID)
+		warnings = append(warnings, fmt.Sprintf("possible duplicate of open pull request %s (%q)", pr.ID, pr.Title))
+	}
+	if len(duplicateIDs) == 0 {
+		return nil, nil
+	}
+
+	settings, err := s.teams.GetTeamSettings(ctx, teamName)
+	if err != nil {
+		switch {
+		case errors.Is(err, storage.ErrTeamNotFound):
+			return nil, ErrPRTeamNotFound
+		default:
+			return nil, fmt.Errorf("get team settings: %w", err)
+		}
+	}
+	if settings.StrictDuplicateCheck {
+		return nil, fmt.Errorf("%w: similar to open %s", ErrPRDuplicate, strings.Join(duplicateIDs, ", "))
+	}
+	return warnings, nil
 }
 
 func (s *PRService) GetUserReviews(ctx context.Context, userID string) (*models.UserReviewsResponse, error) {
-	userID = strings.TrimSpace(userID)
+	userID, err := s.resolveUserRef(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
 	if userID == "" {
 		return nil, fmt.Errorf("%w: user_id is required", ErrPRValidation)
 	}
@@ -174,6 +534,163 @@ func (s *PRService) GetUserReviews(ctx context.Context, userID string) (*models.
 	}, nil
 }
 
+func (s *PRService) GetAssignmentHistory(ctx context.Context, q models.AssignmentHistoryQuery) (*models.AssignmentHistoryResponse, error) {
+	userID, err := s.resolveUserRef(ctx, q.UserID)
+	if err != nil {
+		return nil, err
+	}
+	q.UserID = userID
+	if q.UserID == "" {
+		return nil, fmt.Errorf("%w: user_id is required", ErrPRValidation)
+	}
+	if q.From != nil && q.To != nil && !q.From.Before(*q.To) {
+		return nil, fmt.Errorf("%w: from must be before to", ErrPRValidation)
+	}
+	if q.Limit < 0 || q.Offset < 0 {
+		return nil, fmt.Errorf("%w: limit and offset cannot be negative", ErrPRValidation)
+	}
+	if q.Limit == 0 {
+		q.Limit = defaultHistoryLimit
+	}
+	q.Limit = min(q.Limit, maxHistoryLimit)
+
+	if _, err := s.users.GetUserWithTeam(ctx, q.UserID); err != nil {
+		switch {
+		case errors.Is(err, storage.ErrUserNotFound):
+			return nil, ErrUserNotFound
+		default:
+			s.log.Error("get user info failed", slog.Any("error", err))
+			return nil, fmt.Errorf("get user: %w", err)
+		}
+	}
+
+	items, total, err := s.prs.GetAssignmentHistory(ctx, q)
+	if err != nil {
+		return nil, fmt.Errorf("get assignment history: %w", err)
+	}
+	if items == nil {
+		items = make([]*models.AssignmentHistoryItem, 0)
+	}
+
+	return &models.AssignmentHistoryResponse{
+		UserID:      q.UserID,
+		Assignments: items,
+		Total:       total,
+		Limit:       q.Limit,
+		Offset:      q.Offset,
+	}, nil
+}
+
+func (s *PRService) GetPRsNeedingReviewers(ctx context.Context, teamName string) (*models.NeedReviewersResponse, error) {
+	teamName = strings.TrimSpace(teamName)
+	prs, err := s.prs.GetPRsNeedingReviewers(ctx, teamName, reviewersPerPR)
+	if err != nil {
+		return nil, fmt.Errorf("get prs needing reviewers: %w", err)
+	}
+	return &models.NeedReviewersResponse{
+		TeamName:          teamName,
+		RequiredReviewers: reviewersPerPR,
+		PullRequests:      prs,
+	}, nil
+}
+
+// GetPR reads one pull request with its reviewers and their review state.
+func (s *PRService) GetPR(ctx context.Context, prID string) (*models.PullRequest, error) {
+	prID = strings.TrimSpace(prID)
+	if prID == "" {
+		return nil, fmt.Errorf("%w: pull_request_id is required", ErrPRValidation)
+	}
+	pr, err := s.prs.GetPR(ctx, prID)
+	if err != nil {
+		switch {
+		case errors.Is(err, storage.ErrPRNotFound):
+			return nil, ErrPRNotFound
+		default:
+			s.log.Error("get pr failed", slog.Any("error", err), slog.String("pr_id", prID))
+			return nil, fmt.Errorf("get pr: %w", err)
+		}
+	}
+	setNeedMoreReviewers(pr)
+	return pr, nil
+}
+
+// ListPRs returns a page of pull requests, newest first, filtered by status,
+// author, reviewer, the author's team, created/merged date ranges and labels.
+func (s *PRService) ListPRs(ctx context.Context, q models.PRListQuery) (*models.PRListResponse, error) {
+	q.Status = strings.ToUpper(strings.TrimSpace(q.Status))
+	switch q.Status {
+	case "", models.StatusOpen, models.StatusMerged, models.StatusClosed:
+	default:
+		return nil, fmt.Errorf("%w: status must be %s, %s or %s", ErrPRValidation, models.StatusOpen, models.StatusMerged, models.StatusClosed)
+	}
+	q.AuthorID = strings.TrimSpace(q.AuthorID)
+	q.ReviewerID = strings.TrimSpace(q.ReviewerID)
+	q.TeamName = strings.TrimSpace(q.TeamName)
+	labels, err := normalizeLabels(q.Labels)
+	if err != nil {
+		return nil, err
+	}
+	q.Labels = labels
+	if q.CreatedFrom != nil && q.CreatedTo != nil && !q.CreatedFrom.Before(*q.CreatedTo) {
+		return nil, fmt.Errorf("%w: created_from must be before created_to", ErrPRValidation)
+	}
+	if q.MergedFrom != nil && q.MergedTo != nil && !q.MergedFrom.Before(*q.MergedTo) {
+		return nil, fmt.Errorf("%w: merged_from must be before merged_to", ErrPRValidation)
+	}
+	if q.Limit < 0 || q.Offset < 0 {
+		return nil, fmt.Errorf("%w: limit and offset cannot be negative", ErrPRValidation)
+	}
+	if q.Limit == 0 {
+		q.Limit = defaultHistoryLimit
+	}
+	q.Limit = min(q.Limit, maxHistoryLimit)
+
+	prs, total, err := s.prs.ListPRs(ctx, q)
+	if err != nil {
+		s.log.Error("list prs failed", slog.Any("error", err))
+		return nil, fmt.Errorf("list prs: %w", err)
+	}
+	for _, pr := range prs {
+		setNeedMoreReviewers(pr)
+	}
+	return &models.PRListResponse{
+		PullRequests: prs,
+		Total:        total,
+		Limit:        q.Limit,
+		Offset:       q.Offset,
+	}, nil
+}
+
+// BatchGetPRs reads up to MaxBatchGetPRs pull requests with their reviewers
+// and reports which of the requested ids do not exist. Blank and repeated ids
+// are ignored.
+func (s *PRService) BatchGetPRs(ctx context.Context, prIDs []string) (*models.PRBatchGetResponse, error) {
+	unique := uniqueIDs(prIDs)
+	if len(unique) == 0 {
+		return nil, fmt.Errorf("%w: pull_request_ids is required", ErrPRValidation)
+	}
+	if len(unique) > MaxBatchGetPRs {
+		return nil, fmt.Errorf("%w: at most %d pull_request_ids per request", ErrPRValidation, MaxBatchGetPRs)
+	}
+
+	prs, err := s.prs.GetPRsByIDs(ctx, unique)
+	if err != nil {
+		return nil, fmt.Errorf("get prs by ids: %w", err)
+	}
+	found := make(map[string]struct{}, len(prs))
+	for _, pr := range prs {
+		setNeedMoreReviewers(pr)
+		found[pr.ID] = struct{}{}
+	}
+	missing := make([]string, 0, len(unique)-len(prs))
+	for _, id := range unique {
+		if _, ok := found[id]; !ok {
+			missing = append(missing, id)
+		}
+	}
+	return &models.PRBatchGetResponse{PullRequests: prs, Missing: missing}, nil
+}
+
 func (s *PRService) GetAssignmentsStats(ctx context.Context) (*models.AssignmentsStatsResponse, error) {
 	stats, err := s.prs.GetAssignmentsStats(ctx)
 	if err != nil {
@@ -216,19 +733,40 @@ func (s *PRService) MergePR(ctx context.Context, req *models.PRMergeRequest) (*m
 			mergedPR = pr
 			return nil
 		}
+		if pr.Status == models.StatusClosed {
+			return ErrPRClosed
+		}
+		if s.requireApprovals {
+			var waiting []string
+			for _, detail := range pr.ReviewerDetails {
+				if detail.ReviewState != models.ReviewStateApproved {
+					waiting = append(waiting, detail.UserID)
+				}
+			}
+			if len(waiting) > 0 {
+				return fmt.Errorf("%w: waiting for %s", ErrPRNotApproved, strings.Join(waiting, ", "))
+			}
+		}
 		now := time.Now().UTC()
 		if err := s.prs.MarkPRMerged(ctx, prID, now); err != nil {
 			s.log.Error("mark pr merged failed", slog.Any("error", err), slog.String("pr_id", prID))
+			if errors.Is(err, storage.ErrStatusNotFound) {
+				return fmt.Errorf("%w: %s", ErrPRStatusMissing, models.StatusMerged)
+			}
 			return fmt.Errorf("mark pr merged: %w", err)
 		}
 		pr.Status = models.StatusMerged
 		pr.MergedAt = &now
+		pr.Overdue = false
 		mergedPR = pr
-		return nil
+		return emitOutboxEvent(ctx, s.outbox, models.TopicPRMerged, pr.ID, models.PREvent{
+			PullRequest: pr,
+			OccurredAt:  now,
+		})
 	})
 	if err != nil {
 		switch {
-		case errors.Is(err, ErrPRValidation), errors.Is(err, ErrPRNotFound):
+		case errors.Is(err, ErrPRValidation), errors.Is(err, ErrPRNotFound), errors.Is(err, ErrPRClosed), errors.Is(err, ErrPRNotApproved):
 			return nil, err
 		default:
 			return nil, fmt.Errorf("merge pr transaction: %w", err)
@@ -237,12 +775,88 @@ func (s *PRService) MergePR(ctx context.Context, req *models.PRMergeRequest) (*m
 	return mergedPR, nil
 }
 
+// ClosePR closes a pull request without merging it. The reviewers stay on
+// the pull request for history but stop counting as open load, and the
+// pull request can no longer be reassigned, reviewed or merged. Closing a
+// closed pull request returns it unchanged; a merged one cannot be closed.
+func (s *PRService) ClosePR(ctx context.Context, req *models.PRCloseRequest) (*models.PullRequest, error) {
+	if req == nil {
+		return nil, fmt.Errorf("%w: empty body", ErrPRValidation)
+	}
+	prID := strings.TrimSpace(req.ID)
+	if prID == "" {
+		return nil, fmt.Errorf("%w: pull_request_id is required", ErrPRValidation)
+	}
+
+	var closedPR *models.PullRequest
+	err := s.tx.Run(ctx, func(ctx context.Context) error {
+		pr, err := s.prs.GetPR(ctx, prID)
+		if err != nil {
+			switch {
+			case errors.Is(err, storage.ErrPRNotFound):
+				return ErrPRNotFound
+			default:
+				s.log.Error("get pr failed", slog.Any("error", err), slog.String("pr_id", prID))
+				return fmt.Errorf("get pr: %w", err)
+			}
+		}
+		switch pr.Status {
+		case models.StatusClosed:
+			closedPR = pr
+			return nil
+		case models.StatusMerged:
+			return ErrPRMerged
+		}
+		now := time.Now().UTC()
+		if err := s.prs.MarkPRClosed(ctx, prID, now); err != nil {
+			s.log.Error("mark pr closed failed", slog.Any("error", err), slog.String("pr_id", prID))
+			if errors.Is(err, storage.ErrStatusNotFound) {
+				return fmt.Errorf("%w: %s", ErrPRStatusMissing, models.StatusClosed)
+			}
+			return fmt.Errorf("mark pr closed: %w", err)
+		}
+		pr.Status = models.StatusClosed
+		pr.ClosedAt = &now
+		pr.Overdue = false
+		setNeedMoreReviewers(pr)
+		closedPR = pr
+		return emitOutboxEvent(ctx, s.outbox, models.TopicPRClosed, pr.ID, models.PREvent{
+			PullRequest: pr,
+			OccurredAt:  now,
+		})
+	})
+	if err != nil {
+		switch {
+		case errors.Is(err, ErrPRValidation), errors.Is(err, ErrPRNotFound), errors.Is(err, ErrPRMerged):
+			return nil, err
+		default:
+			return nil, fmt.Errorf("close pr transaction: %w", err)
+		}
+	}
+	return closedPR, nil
+}
+
+// checkPROpen rejects changes to the reviewers of a merged or closed pull
+// request.
+func checkPROpen(pr *models.PullRequest) error {
+	switch pr.Status {
+	case models.StatusMerged:
+		return ErrPRMerged
+	case models.StatusClosed:
+		return ErrPRClosed
+	}
+	return nil
+}
+
 func (s *PRService) ReassignReviewer(ctx context.Context, req *models.PRReassignRequest) (*models.PRReassignResponse, error) {
 	if req == nil {
 		return nil, fmt.Errorf("%w: empty body", ErrPRValidation)
 	}
 	prID := strings.TrimSpace(req.ID)
-	oldReviewerID := strings.TrimSpace(req.OldReviewerID)
+	oldReviewerID, err := s.resolveUserRef(ctx, req.OldReviewerID)
+	if err != nil {
+		return nil, err
+	}
 	if prID == "" {
 		return nil, fmt.Errorf("%w: pull_request_id is required", ErrPRValidation)
 	}
@@ -250,6 +864,168 @@ func (s *PRService) ReassignReviewer(ctx context.Context, req *models.PRReassign
 		return nil, fmt.Errorf("%w: old_reviewer_id is required", ErrPRValidation)
 	}
 
+	reassignResp, err := s.reassignInTx(ctx, prID, oldReviewerID, models.EventReassigned, "")
+	if err != nil {
+		return nil, err
+	}
+	s.publishAssignment(&reassignResp.PR, reassignResp.ReplacedBy)
+
+	return reassignResp, nil
+}
+
+// DeclineReview lets a reviewer take themselves off a pull request. A
+// replacement is picked exactly as in ReassignReviewer; the history records
+// DECLINED with the reviewer's reason instead of REASSIGNED.
+func (s *PRService) DeclineReview(ctx context.Context, req *models.PRDeclineRequest) (*models.PRReassignResponse, error) {
+	if req == nil {
+		return nil, fmt.Errorf("%w: empty body", ErrPRValidation)
+	}
+	prID := strings.TrimSpace(req.ID)
+	userID, err := s.resolveUserRef(ctx, req.UserID)
+	if err != nil {
+		return nil, err
+	}
+	if prID == "" {
+		return nil, fmt.Errorf("%w: pull_request_id is required", ErrPRValidation)
+	}
+	if userID == "" {
+		return nil, fmt.Errorf("%w: user_id is required", ErrPRValidation)
+	}
+	reason := strings.TrimSpace(req.Reason)
+	if len(reason) > maxDeclineReasonLength {
+		return nil, fmt.Errorf("%w: reason must be at most %d characters", ErrPRValidation, maxDeclineReasonLength)
+	}
+	if reason == "" {
+		reason = defaultDeclineReason
+	}
+
+	resp, err := s.reassignInTx(ctx, prID, userID, models.EventDeclined, reason)
+	if err != nil {
+		return nil, err
+	}
+	s.log.Info("review declined",
+		slog.String("pr_id", prID),
+		slog.String("user_id", userID),
+		slog.String("replaced_by", resp.ReplacedBy),
+	)
+	s.publishAssignment(&resp.PR, resp.ReplacedBy)
+	return resp, nil
+}
+
+// ReassignAll hands every open PR the user reviews to teammates. Each PR is
+// reassigned in its own transaction, so one PR without a candidate does not
+// roll back the others; per-PR outcomes are reported in the response.
+//
+// With Preview the reassignments run in a single transaction that is rolled
+// back, and the response carries a change-set token over the user's open
+// reviews and their reviewers. Apply must pass that token and fails with
+// ErrStalePreview if those reviews changed. Replacements are picked again on
+// apply, so random strategies may choose someone else than the preview did.
+func (s *PRService) ReassignAll(ctx context.Context, req *models.PRReassignAllRequest) (*models.PRReassignAllResponse, error) {
+	if req == nil {
+		return nil, fmt.Errorf("%w: empty body", ErrPRValidation)
+	}
+	oldUserID, err := s.resolveUserRef(ctx, req.OldUserID)
+	if err != nil {
+		return nil, err
+	}
+	if oldUserID == "" {
+		return nil, fmt.Errorf("%w: old_user_id is required", ErrPRValidation)
+	}
+	if _, err := s.getUser(ctx, oldUserID); err != nil {
+		return nil, err
+	}
+	if req.Preview {
+		return s.previewReassignAll(ctx, oldUserID)
+	}
+
+	token, ids, err := s.reassignAllToken(ctx, oldUserID)
+	if err != nil {
+		return nil, err
+	}
+	if err := checkChangeSet(req.ChangeSetToken, token); err != nil {
+		return nil, err
+	}
+
+	resp := &models.PRReassignAllResponse{
+		OldUserID:      oldUserID,
+		ChangeSetToken: token,
+		Results:        make([]*models.PRReassignResult, 0, len(ids)),
+	}
+	for _, id := range ids {
+		result := &models.PRReassignResult{PullRequestID: id}
+		reassigned, err := s.reassignInTx(ctx, id, oldUserID, models.EventReassigned, "bulk reassign")
+		if err != nil {
+			s.log.Warn("bulk reassign failed",
+				slog.Any("error", err),
+				slog.String("pr_id", id),
+				slog.String("user_id", oldUserID),
+			)
+			result.Outcome = models.ReassignOutcomeFailed
+			result.Err = err
+			resp.Failed++
+		} else {
+			result.Outcome = models.ReassignOutcomeReassigned
+			result.ReplacedBy = reassigned.ReplacedBy
+			resp.Reassigned++
+			s.publishAssignment(&reassigned.PR, reassigned.ReplacedBy)
+		}
+		resp.Results = append(resp.Results, result)
+	}
+	return resp, nil
+}
+
+func (s *PRService) previewReassignAll(ctx context.Context, oldUserID string) (*models.PRReassignAllResponse, error) {
+	resp := &models.PRReassignAllResponse{OldUserID: oldUserID, Preview: true}
+	err := s.tx.Run(ctx, func(ctx context.Context) error {
+		token, _, err := s.reassignAllToken(ctx, oldUserID)
+		if err != nil {
+			return err
+		}
+		results, err := s.ReassignOpenReviews(ctx, oldUserID, "bulk reassign")
+		if err != nil {
+			return err
+		}
+		resp.ChangeSetToken = token
+		resp.Results = results
+		return errPreviewRollback
+	})
+	if err != nil && !errors.Is(err, errPreviewRollback) {
+		return nil, fmt.Errorf("preview reassign all: %w", err)
+	}
+	for _, r := range resp.Results {
+		r.PR = nil
+		if r.Outcome == models.ReassignOutcomeReassigned {
+			resp.Reassigned++
+		} else {
+			resp.Failed++
+		}
+	}
+	return resp, nil
+}
+
+// reassignAllToken returns the change-set token of a bulk reassign together
+// with the open pull requests it covers, ordered by id.
+func (s *PRService) reassignAllToken(ctx context.Context, userID string) (string, []string, error) {
+	ids, err := s.openReviewIDs(ctx, userID)
+	if err != nil {
+		return "", nil, err
+	}
+	slices.Sort(ids)
+	prs, err := s.getPRsByID(ctx, ids)
+	if err != nil {
+		return "", nil, err
+	}
+	parts := make([]string, 0, 1+len(ids))
+	parts = append(parts, userID)
+	for _, id := range ids {
+		reviewers := slices.Sorted(slices.Values(prs[id].Reviewers))
+		parts = append(parts, id+"="+strings.Join(reviewers, ","))
+	}
+	return changeSetToken("reassignAll", parts...), ids, nil
+}
+
+func (s *PRService) reassignInTx(ctx context.Context, prID, oldReviewerID, event, reason string) (*models.PRReassignResponse, error) {
 	var reassignResp *models.PRReassignResponse
 	err := s.tx.Run(ctx, func(ctx context.Context) error {
 		pr, err := s.prs.GetPR(ctx, prID)
@@ -262,8 +1038,8 @@ func (s *PRService) ReassignReviewer(ctx context.Context, req *models.PRReassign
 				return fmt.Errorf("get pr: %w", err)
 			}
 		}
-		if pr.Status == models.StatusMerged {
-			return ErrPRMerged
+		if err := checkPROpen(pr); err != nil {
+			return err
 		}
 
 		assigned := slices.Contains(pr.Reviewers, oldReviewerID)
@@ -271,81 +1047,527 @@ func (s *PRService) ReassignReviewer(ctx context.Context, req *models.PRReassign
 			return ErrReviewerNotAssigned
 		}
 
-		reviewerUser, err := s.users.GetUserWithTeam(ctx, oldReviewerID)
+		replacementID, err := s.replaceReviewer(ctx, pr, oldReviewerID, event, reason)
 		if err != nil {
+			return err
+		}
+		setNeedMoreReviewers(pr)
+
+		reassignResp = &models.PRReassignResponse{
+			PR:         *pr,
+			ReplacedBy: replacementID,
+		}
+		return nil
+	})
+	if err != nil {
+		switch {
+		case errors.Is(err, ErrPRValidation),
+			errors.Is(err, ErrPRNotFound),
+			errors.Is(err, ErrUserNotFound),
+			errors.Is(err, ErrReviewerNotAssigned),
+			errors.Is(err, ErrNoReplacement),
+			errors.Is(err, ErrPRMerged),
+			errors.Is(err, ErrPRClosed),
+			errors.Is(err, ErrPRTeamNotFound):
+			return nil, err
+		default:
+			return nil, fmt.Errorf("reassign reviewer transaction: %w", err)
+		}
+	}
+	return reassignResp, nil
+}
+
+func (s *PRService) replaceReviewer(ctx context.Context, pr *models.PullRequest, oldReviewerID, event, reason string) (string, error) {
+	reviewerUser, err := s.users.GetUserWithTeam(ctx, oldReviewerID)
+	if err != nil {
+		switch {
+		case errors.Is(err, storage.ErrUserNotFound):
+			return "", ErrUserNotFound
+		default:
+			return "", fmt.Errorf("get reviewer: %w", err)
+		}
+	}
+	teamName := strings.TrimSpace(reviewerUser.TeamName)
+	if teamName == "" {
+		return "", ErrPRTeamNotFound
+	}
+
+	excludeIDs := make(map[string]struct{}, len(pr.Reviewers)+2)
+	excludeIDs[oldReviewerID] = struct{}{}
+	for _, reviewer := range pr.Reviewers {
+		excludeIDs[reviewer] = struct{}{}
+	}
+	authorID := strings.TrimSpace(pr.AuthorID)
+	if authorID != "" {
+		excludeIDs[authorID] = struct{}{}
+	}
+	excludeList := make([]string, 0, len(excludeIDs))
+	for id := range excludeIDs {
+		excludeList = append(excludeList, id)
+	}
+
+	replacement, err := s.users.GetRandomActiveTeammate(ctx, teamName, authorID, excludeList)
+	if err != nil {
+		switch {
+		case errors.Is(err, storage.ErrNoCandidate):
+			return "", ErrNoReplacement
+		default:
+			s.log.Error("get replacement failed", slog.Any("error", err), slog.String("team", teamName))
+			return "", fmt.Errorf("get replacement: %w", err)
+		}
+	}
+	if err := s.applyReplacement(ctx, pr, oldReviewerID, replacement, event, reason); err != nil {
+		return "", err
+	}
+	return replacement.ID, nil
+}
+
+func (s *PRService) applyReplacement(ctx context.Context, pr *models.PullRequest, oldReviewerID string, replacement *models.User, event, reason string) error {
+	if err := s.prs.ReplaceReviewer(ctx, pr.ID, oldReviewerID, replacement.ID); err != nil {
+		switch {
+		case errors.Is(err, storage.ErrReviewerNotAssigned):
+			return ErrReviewerNotAssigned
+		default:
+			return fmt.Errorf("replace reviewer: %w", err)
+		}
+	}
+	if err := s.prs.AddAssignmentEvents(ctx, pr.ID, []string{oldReviewerID}, event, reason); err != nil {
+		return fmt.Errorf("record %s event: %w", strings.ToLower(event), err)
+	}
+	if err := s.prs.AddAssignmentEvents(ctx, pr.ID, []string{replacement.ID}, models.EventAssigned, reason); err != nil {
+		return fmt.Errorf("record assigned event: %w", err)
+	}
+
+	for i, reviewer := range pr.Reviewers {
+		if reviewer == oldReviewerID {
+			pr.Reviewers[i] = replacement.ID
+			break
+		}
+	}
+	now := time.Now().UTC()
+	for i, detail := range pr.ReviewerDetails {
+		if detail.UserID == oldReviewerID {
+			pr.ReviewerDetails[i] = newReviewerDetail(replacement, now)
+			break
+		}
+	}
+	return emitOutboxEvent(ctx, s.outbox, models.TopicPRReviewerReplaced, pr.ID, models.PREvent{
+		PullRequest:   pr,
+		OldReviewerID: oldReviewerID,
+		NewReviewerID: replacement.ID,
+		Reason:        event,
+		OccurredAt:    now,
+	})
+}
+
+// setNeedMoreReviewers derives the flag from the live reviewer list, so it
+// stays correct after deactivations and reassignments without being stored.
+func setNeedMoreReviewers(pr *models.PullRequest) {
+	pr.NeedMore = pr.Status == models.StatusOpen && len(pr.Reviewers) < reviewersPerPR
+}
+
+// preferFreshReviewers keeps the candidates' order but moves those listed in
+// recent behind the rest, then trims the result to limit.
+func preferFreshReviewers(candidates []*models.User, recent []string, limit int) []*models.User {
+	if len(recent) > 0 {
+		candidates = slices.Clone(candidates)
+		slices.SortStableFunc(candidates, func(a, b *models.User) int {
+			aRecent, bRecent := slices.Contains(recent, a.ID), slices.Contains(recent, b.ID)
 			switch {
-			case errors.Is(err, storage.ErrUserNotFound):
-				return ErrUserNotFound
+			case aRecent == bRecent:
+				return 0
+			case bRecent:
+				return -1
 			default:
-				return fmt.Errorf("get reviewer: %w", err)
+				return 1
 			}
+		})
+	}
+	if len(candidates) > limit {
+		candidates = candidates[:limit]
+	}
+	return candidates
+}
+
+// selectedReviewer is a reviewer chosen for a new pull request together with
+// why selection picked them.
+type selectedReviewer struct {
+	user         *models.User
+	reason       string
+	borrowedFrom string
+	recent       bool
+}
+
+// selectReviewers runs reviewer selection for a new pull request by authorID
+// in teamName: required reviewers first, then the configured strategy within
+// the team for the remaining slots, then fallback teams when the team is
+// short. With round robin it moves the rotation cursor, so it must run inside
+// the caller's transaction.
+func (s *PRService) selectReviewers(ctx context.Context, teamName, authorID string, required []selectedReviewer) ([]selectedReviewer, error) {
+	slots := reviewersPerPR - len(required)
+	if slots <= 0 {
+		return required, nil
+	}
+	skip := make([]string, 0, len(required))
+	for _, r := range required {
+		skip = append(skip, r.user.ID)
+	}
+
+	pickReviewers, reason := s.users.GetActiveTeammates, models.PickReasonRandom
+	switch s.reviewerStrategy {
+	case ReviewerStrategyLoadBalanced:
+		pickReviewers, reason = s.users.GetLeastLoadedTeammates, models.PickReasonLeastLoaded
+	case ReviewerStrategyRoundRobin:
+		pickReviewers, reason = s.users.GetNextRotationTeammates, models.PickReasonRotation
+	case ReviewerStrategyWorkingHours:
+		pickReviewers, reason = s.users.GetOverlappingTeammates, models.PickReasonWorkingHours
+	}
+	// Recent reviewers are only de-prioritized: ask for enough extra
+	// candidates that fresh ones win whenever the team has them. The
+	// rotation cursor already spreads reviews, so round robin skips this.
+	var recent []string
+	if s.recentReviewerPRs > 0 && s.reviewerStrategy != ReviewerStrategyRoundRobin {
+		var err error
+		if recent, err = s.prs.GetRecentReviewers(ctx, authorID, s.recentReviewerPRs); err != nil {
+			return nil, fmt.Errorf("get recent reviewers: %w", err)
 		}
-		teamName := strings.TrimSpace(reviewerUser.TeamName)
-		if teamName == "" {
-			return ErrPRTeamNotFound
+	}
+	teammates, err := pickReviewers(ctx, teamName, authorID, slots+len(recent)+len(skip))
+	if err != nil {
+		return nil, fmt.Errorf("get teammates: %w", err)
+	}
+	teammates = slices.DeleteFunc(teammates, func(u *models.User) bool { return slices.Contains(skip, u.ID) })
+	teammates = preferFreshReviewers(teammates, recent, slots)
+	selected := make([]selectedReviewer, 0, reviewersPerPR)
+	selected = append(selected, required...)
+	for _, tm := range teammates {
+		selected = append(selected, selectedReviewer{user: tm, reason: reason, recent: slices.Contains(recent, tm.ID)})
+	}
+	if missing := reviewersPerPR - len(selected); missing > 0 {
+		borrowed, err := s.borrowReviewers(ctx, teamName, authorID, missing, skip)
+		if err != nil {
+			return nil, err
+		}
+		selected = append(selected, borrowed...)
+	}
+	return selected, nil
+}
+
+// requiredReviewers returns the users the team's required reviewer rules
+// demand for a pull request with the given hints, in rule order. A rule
+// naming the author is ignored; missing or inactive users cannot review, so
+// they are left out with a warning.
+func (s *PRService) requiredReviewers(ctx context.Context, teamName, authorID string, components, paths []string) ([]selectedReviewer, []string, error) {
+	if len(components) == 0 && len(paths) == 0 {
+		return nil, nil, nil
+	}
+	settings, err := s.teams.GetTeamSettings(ctx, teamName)
+	if err != nil {
+		switch {
+		case errors.Is(err, storage.ErrTeamNotFound):
+			return nil, nil, ErrPRTeamNotFound
+		default:
+			return nil, nil, fmt.Errorf("get team settings: %w", err)
+		}
+	}
+	var required []selectedReviewer
+	var warnings []string
+	seen := map[string]bool{authorID: true}
+	for _, rule := range settings.RequiredReviewers {
+		if seen[rule.UserID] || !requiredRuleMatches(rule, components, paths) {
+			continue
+		}
+		seen[rule.UserID] = true
+		u, err := s.users.GetUserWithTeam(ctx, rule.UserID)
+		if errors.Is(err, storage.ErrUserNotFound) || (err == nil && !u.IsActive) {
+			warnings = append(warnings, fmt.Sprintf("required reviewer %s is not active and was not assigned", rule.UserID))
+			continue
+		}
+		if err != nil {
+			return nil, nil, fmt.Errorf("get required reviewer: %w", err)
 		}
+		required = append(required, selectedReviewer{user: &u.User, reason: models.PickReasonRequired})
+	}
+	return required, warnings, nil
+}
 
-		excludeIDs := make(map[string]struct{}, len(pr.Reviewers)+2)
-		excludeIDs[oldReviewerID] = struct{}{}
-		for _, reviewer := range pr.Reviewers {
-			excludeIDs[reviewer] = struct{}{}
+func requiredRuleMatches(rule models.RequiredReviewerRule, components, paths []string) bool {
+	if rule.Component != "" {
+		for _, c := range components {
+			if strings.EqualFold(strings.TrimSpace(c), rule.Component) {
+				return true
+			}
 		}
-		authorID := strings.TrimSpace(pr.AuthorID)
-		if authorID != "" {
-			excludeIDs[authorID] = struct{}{}
+	}
+	if rule.PathPrefix != "" {
+		for _, p := range paths {
+			if strings.HasPrefix(strings.TrimLeft(strings.TrimSpace(p), "/"), rule.PathPrefix) {
+				return true
+			}
 		}
-		excludeList := make([]string, 0, len(excludeIDs))
-		for id := range excludeIDs {
-			excludeList = append(excludeList, id)
+	}
+	return false
+}
+
+// borrowReviewers picks up to missing active reviewers from the fallback
+// teams configured for teamName, in their configured order, other than the
+// users in skip. Teams without fallbacks borrow nobody.
+func (s *PRService) borrowReviewers(ctx context.Context, teamName, authorID string, missing int, skip []string) ([]selectedReviewer, error) {
+	settings, err := s.teams.GetTeamSettings(ctx, teamName)
+	if err != nil {
+		switch {
+		case errors.Is(err, storage.ErrTeamNotFound):
+			return nil, ErrPRTeamNotFound
+		default:
+			return nil, fmt.Errorf("get team settings: %w", err)
+		}
+	}
+	var borrowed []selectedReviewer
+	for _, buddy := range settings.FallbackTeams {
+		if missing <= 0 {
+			break
+		}
+		users, err := s.users.GetActiveTeammates(ctx, buddy, authorID, missing+len(skip))
+		if err != nil {
+			return nil, fmt.Errorf("get fallback reviewers from %s: %w", buddy, err)
+		}
+		users = slices.DeleteFunc(users, func(u *models.User) bool { return slices.Contains(skip, u.ID) })
+		users = users[:min(len(users), missing)]
+		for _, u := range users {
+			borrowed = append(borrowed, selectedReviewer{user: u, reason: models.PickReasonFallbackTeam, borrowedFrom: buddy})
 		}
+		missing -= len(users)
+	}
+	return borrowed, nil
+}
 
-		replacement, err := s.users.GetRandomActiveTeammate(ctx, teamName, excludeList)
+func newReviewerDetail(user *models.User, assignedAt time.Time) *models.ReviewerDetail {
+	return &models.ReviewerDetail{
+		UserID:      user.ID,
+		Username:    user.Username,
+		AssignedAt:  assignedAt,
+		State:       models.ReviewerStateAssigned,
+		ReviewState: models.ReviewStatePending,
+	}
+}
+
+func (s *PRService) AcknowledgeReview(ctx context.Context, req *models.PRAcknowledgeRequest) (*models.PullRequest, error) {
+	if req == nil {
+		return nil, fmt.Errorf("%w: empty body", ErrPRValidation)
+	}
+	prID := strings.TrimSpace(req.ID)
+	userID, err := s.resolveUserRef(ctx, req.UserID)
+	if err != nil {
+		return nil, err
+	}
+	if prID == "" {
+		return nil, fmt.Errorf("%w: pull_request_id is required", ErrPRValidation)
+	}
+	if userID == "" {
+		return nil, fmt.Errorf("%w: user_id is required", ErrPRValidation)
+	}
+
+	var ackedPR *models.PullRequest
+	err = s.tx.Run(ctx, func(ctx context.Context) error {
+		pr, err := s.prs.GetPR(ctx, prID)
 		if err != nil {
 			switch {
-			case errors.Is(err, storage.ErrNoCandidate):
-				return ErrNoReplacement
+			case errors.Is(err, storage.ErrPRNotFound):
+				return ErrPRNotFound
 			default:
-				s.log.Error("get replacement failed", slog.Any("error", err), slog.String("team", teamName))
-				return fmt.Errorf("get replacement: %w", err)
+				return fmt.Errorf("get pr: %w", err)
 			}
 		}
+		if err := checkPROpen(pr); err != nil {
+			return err
+		}
+		if !slices.Contains(pr.Reviewers, userID) {
+			return ErrReviewerNotAssigned
+		}
+		now := time.Now().UTC()
+		acked, err := s.prs.AcknowledgeReviewer(ctx, prID, userID, now)
+		if err != nil {
+			return fmt.Errorf("acknowledge reviewer: %w", err)
+		}
+		if acked {
+			if err := s.prs.AddAssignmentEvents(ctx, prID, []string{userID}, models.EventAcknowledged, ""); err != nil {
+				return fmt.Errorf("record acknowledged event: %w", err)
+			}
+			for _, detail := range pr.ReviewerDetails {
+				if detail.UserID == userID {
+					detail.State = models.ReviewerStateAcknowledged
+					detail.AcknowledgedAt = &now
+				}
+			}
+		}
+		setNeedMoreReviewers(pr)
+		ackedPR = pr
+		return nil
+	})
+	if err != nil {
+		switch {
+		case errors.Is(err, ErrPRNotFound), errors.Is(err, ErrReviewerNotAssigned):
+			return nil, err
+		default:
+			s.log.Error("acknowledge review transaction failed", slog.Any("error", err))
+			return nil, fmt.Errorf("acknowledge review transaction: %w", err)
+		}
+	}
+	return ackedPR, nil
+}
 
-		if err := s.prs.ReplaceReviewer(ctx, prID, oldReviewerID, replacement.ID); err != nil {
+// ApproveReview records the verdict of an assigned reviewer on an open pull
+// request: APPROVED by default, or CHANGES_REQUESTED. A verdict acknowledges
+// the assignment too; repeating the current verdict changes nothing.
+func (s *PRService) ApproveReview(ctx context.Context, req *models.PRApproveRequest) (*models.PullRequest, error) {
+	if req == nil {
+		return nil, fmt.Errorf("%w: empty body", ErrPRValidation)
+	}
+	prID := strings.TrimSpace(req.ID)
+	userID, err := s.resolveUserRef(ctx, req.UserID)
+	if err != nil {
+		return nil, err
+	}
+	if prID == "" {
+		return nil, fmt.Errorf("%w: pull_request_id is required", ErrPRValidation)
+	}
+	if userID == "" {
+		return nil, fmt.Errorf("%w: user_id is required", ErrPRValidation)
+	}
+	var event string
+	state := strings.ToUpper(strings.TrimSpace(req.State))
+	switch state {
+	case "", models.ReviewStateApproved:
+		state, event = models.ReviewStateApproved, models.EventApproved
+	case models.ReviewStateChangesRequested:
+		event = models.EventChangesRequested
+	default:
+		return nil, fmt.Errorf("%w: state must be %s or %s", ErrPRValidation, models.ReviewStateApproved, models.ReviewStateChangesRequested)
+	}
+
+	var reviewedPR *models.PullRequest
+	err = s.tx.Run(ctx, func(ctx context.Context) error {
+		pr, err := s.prs.GetPR(ctx, prID)
+		if err != nil {
 			switch {
-			case errors.Is(err, storage.ErrReviewerNotAssigned):
-				return ErrReviewerNotAssigned
+			case errors.Is(err, storage.ErrPRNotFound):
+				return ErrPRNotFound
 			default:
-				return fmt.Errorf("replace reviewer: %w", err)
+				return fmt.Errorf("get pr: %w", err)
 			}
 		}
+		if err := checkPROpen(pr); err != nil {
+			return err
+		}
+		i := slices.IndexFunc(pr.ReviewerDetails, func(d *models.ReviewerDetail) bool { return d.UserID == userID })
+		if i < 0 {
+			return ErrReviewerNotAssigned
+		}
+		detail := pr.ReviewerDetails[i]
 
-		for i, reviewer := range pr.Reviewers {
-			if reviewer == oldReviewerID {
-				pr.Reviewers[i] = replacement.ID
-				break
+		now := time.Now().UTC()
+		changed, err := s.prs.SetReviewState(ctx, prID, userID, state, now)
+		if err != nil {
+			return fmt.Errorf("set review state: %w", err)
+		}
+		if changed {
+			if detail.AcknowledgedAt == nil {
+				if err := s.prs.AddAssignmentEvents(ctx, prID, []string{userID}, models.EventAcknowledged, ""); err != nil {
+					return fmt.Errorf("record acknowledged event: %w", err)
+				}
+				detail.State = models.ReviewerStateAcknowledged
+				detail.AcknowledgedAt = &now
 			}
+			if err := s.prs.AddAssignmentEvents(ctx, prID, []string{userID}, event, ""); err != nil {
+				return fmt.Errorf("record review event: %w", err)
+			}
+			detail.ReviewState = state
+			detail.ReviewedAt = &now
 		}
-
-		reassignResp = &models.PRReassignResponse{
-			PR:         *pr,
-			ReplacedBy: replacement.ID,
+		setNeedMoreReviewers(pr)
+		reviewedPR = pr
+		if !changed {
+			return nil
 		}
-		return nil
+		return emitOutboxEvent(ctx, s.outbox, models.TopicPRReviewed, pr.ID, models.PREvent{
+			PullRequest: pr,
+			ReviewerID:  userID,
+			ReviewState: state,
+			OccurredAt:  now,
+		})
 	})
 	if err != nil {
 		switch {
-		case errors.Is(err, ErrPRValidation),
-			errors.Is(err, ErrPRNotFound),
-			errors.Is(err, ErrUserNotFound),
-			errors.Is(err, ErrReviewerNotAssigned),
-			errors.Is(err, ErrNoReplacement),
-			errors.Is(err, ErrPRMerged),
-			errors.Is(err, ErrPRTeamNotFound):
+		case errors.Is(err, ErrPRNotFound), errors.Is(err, ErrPRMerged), errors.Is(err, ErrPRClosed), errors.Is(err, ErrReviewerNotAssigned):
 			return nil, err
 		default:
-			return nil, fmt.Errorf("reassign reviewer transaction: %w", err)
+			s.log.Error("approve review transaction failed", slog.Any("error", err))
+			return nil, fmt.Errorf("approve review transaction: %w", err)
 		}
 	}
+	return reviewedPR, nil
+}
 
-	return reassignResp, nil
+// ackReassignSkipped counts unacknowledged reviews left in place because the
+// team has no one to hand them to. Such a review is retried on every tick,
+// so it is counted here instead of being logged as a warning each time.
+var ackReassignSkipped = metrics.NewCounterVec(
+	"pr_reviewer_ack_reassign_skipped_total",
+	"Unacknowledged reviews not reassigned for lack of a replacement.",
+)
+
+func init() {
+	metrics.Default.Register(ackReassignSkipped)
+}
+
+func (s *PRService) ReassignExpiredAcknowledgements(ctx context.Context) (int, error) {
+	expired, err := s.prs.GetExpiredAssignments(ctx, time.Now().UTC())
+	if err != nil {
+		return 0, fmt.Errorf("get expired assignments: %w", err)
+	}
+
+	reassigned := 0
+	for _, assignment := range expired {
+		var pr *models.PullRequest
+		var replacementID string
+		err := s.tx.Run(ctx, func(ctx context.Context) error {
+			var err error
+			pr, err = s.prs.GetPR(ctx, assignment.PullRequestID)
+			if err != nil {
+				return fmt.Errorf("get pr: %w", err)
+			}
+			if pr.Status != models.StatusOpen || !slices.Contains(pr.Reviewers, assignment.UserID) {
+				return nil
+			}
+			reason := fmt.Sprintf("not acknowledged since %s", assignment.AssignedAt.UTC().Format(time.RFC3339))
+			replacementID, err = s.replaceReviewer(ctx, pr, assignment.UserID, models.EventAckTimeout, reason)
+			return err
+		})
+		if errors.Is(err, ErrNoReplacement) {
+			ackReassignSkipped.Inc()
+			s.log.Debug("no replacement for unacknowledged review",
+				slog.String("pr_id", assignment.PullRequestID),
+				slog.String("user_id", assignment.UserID),
+			)
+			continue
+		}
+		if err != nil {
+			s.log.Warn("failed to reassign unacknowledged review",
+				slog.Any("error", err),
+				slog.String("pr_id", assignment.PullRequestID),
+				slog.String("user_id", assignment.UserID),
+			)
+			continue
+		}
+		if replacementID == "" {
+			continue
+		}
+		reassigned++
+		s.publishAssignment(pr, replacementID)
+	}
+
+	if reassigned > 0 {
+		s.log.Info("reassigned unacknowledged reviews", slog.Int("count", reassigned))
+	}
+	return reassigned, nil
 }
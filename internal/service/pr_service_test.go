@@ -6,9 +6,13 @@ import (
 	"fmt"
 	"io"
 	"log/slog"
+	"regexp"
+	"slices"
+	"strings"
 	"testing"
 	"time"
 
+	"github.com/cloudyy74/pr-reviewer-service/internal/hub"
 	"github.com/cloudyy74/pr-reviewer-service/internal/models"
 	"github.com/cloudyy74/pr-reviewer-service/internal/storage"
 )
@@ -23,10 +27,55 @@ type fakePRRepo struct {
 	createPRFn        func(context.Context, models.PullRequest) (*models.PullRequest, error)
 	addReviewersFn    func(context.Context, string, []string) error
 	getReviewerPRsFn  func(context.Context, string) ([]*models.PullRequestShort, error)
+	getAuthorOpenFn   func(context.Context, string) ([]*models.PullRequestShort, error)
+	getNeedingFn      func(context.Context, string, int) ([]*models.PRNeedingReviewers, error)
+	addEventsFn       func(context.Context, string, []string, string, string) error
+	acknowledgeFn     func(context.Context, string, string, time.Time) (bool, error)
+	getExpiredFn      func(context.Context, time.Time) ([]*models.ReviewerAssignment, error)
+	getHistoryFn      func(context.Context, models.AssignmentHistoryQuery) ([]*models.AssignmentHistoryItem, int, error)
+	listPRsFn         func(context.Context, models.PRListQuery) ([]*models.PullRequest, int, error)
+	updateDetailsFn   func(context.Context, string, string, string) error
+	setLabelsFn       func(context.Context, string, []string) error
 	getPRFn           func(context.Context, string) (*models.PullRequest, error)
 	markMergedFn      func(context.Context, string, time.Time) error
+	markClosedFn      func(context.Context, string, time.Time) error
 	replaceReviewerFn func(context.Context, string, string, string) error
+	removeReviewerFn  func(context.Context, string, string) error
 	getStatsFn        func(context.Context) (*models.AssignmentsStatsResponse, error)
+	getRecentFn       func(context.Context, string, int) ([]string, error)
+	getPRsByIDsFn     func(context.Context, []string) ([]*models.PullRequest, error)
+	getAgendaFn       func(context.Context, string, int) ([]*models.AgendaItem, error)
+	setReviewStateFn  func(context.Context, string, string, string, time.Time) (bool, error)
+}
+
+func (f *fakePRRepo) SetReviewState(ctx context.Context, prID, userID, state string, at time.Time) (bool, error) {
+	return f.setReviewStateFn(ctx, prID, userID, state, at)
+}
+
+func (f *fakePRRepo) GetReviewerAgenda(ctx context.Context, userID string, defaultDueHours int) ([]*models.AgendaItem, error) {
+	return f.getAgendaFn(ctx, userID, defaultDueHours)
+}
+
+func (f *fakePRRepo) GetPRsByIDs(ctx context.Context, prIDs []string) ([]*models.PullRequest, error) {
+	if f.getPRsByIDsFn != nil {
+		return f.getPRsByIDsFn(ctx, prIDs)
+	}
+	prs := make([]*models.PullRequest, 0, len(prIDs))
+	for _, id := range prIDs {
+		pr, err := f.getPRFn(ctx, id)
+		if errors.Is(err, storage.ErrPRNotFound) {
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+		prs = append(prs, pr)
+	}
+	return prs, nil
+}
+
+func (f *fakePRRepo) GetRecentReviewers(ctx context.Context, authorID string, lastPRs int) ([]string, error) {
+	return f.getRecentFn(ctx, authorID, lastPRs)
 }
 
 func (f *fakePRRepo) CreatePR(ctx context.Context, pr models.PullRequest) (*models.PullRequest, error) {
@@ -37,10 +86,49 @@ func (f *fakePRRepo) AddReviewers(ctx context.Context, prID string, reviewerIDs
 	return f.addReviewersFn(ctx, prID, reviewerIDs)
 }
 
+func (f *fakePRRepo) AddAssignmentEvents(ctx context.Context, prID string, userIDs []string, event, reason string) error {
+	if f.addEventsFn == nil {
+		return nil
+	}
+	return f.addEventsFn(ctx, prID, userIDs, event, reason)
+}
+
+func (f *fakePRRepo) AcknowledgeReviewer(ctx context.Context, prID, userID string, at time.Time) (bool, error) {
+	return f.acknowledgeFn(ctx, prID, userID, at)
+}
+
+func (f *fakePRRepo) GetExpiredAssignments(ctx context.Context, now time.Time) ([]*models.ReviewerAssignment, error) {
+	return f.getExpiredFn(ctx, now)
+}
+
+func (f *fakePRRepo) UpdatePRDetails(ctx context.Context, prID, title, description string) error {
+	return f.updateDetailsFn(ctx, prID, title, description)
+}
+
+func (f *fakePRRepo) SetPRLabels(ctx context.Context, prID string, labels []string) error {
+	return f.setLabelsFn(ctx, prID, labels)
+}
+
+func (f *fakePRRepo) ListPRs(ctx context.Context, q models.PRListQuery) ([]*models.PullRequest, int, error) {
+	return f.listPRsFn(ctx, q)
+}
+
+func (f *fakePRRepo) GetAssignmentHistory(ctx context.Context, q models.AssignmentHistoryQuery) ([]*models.AssignmentHistoryItem, int, error) {
+	return f.getHistoryFn(ctx, q)
+}
+
 func (f *fakePRRepo) GetReviewerPRs(ctx context.Context, userID string) ([]*models.PullRequestShort, error) {
 	return f.getReviewerPRsFn(ctx, userID)
 }
 
+func (f *fakePRRepo) GetOpenPRsByAuthor(ctx context.Context, authorID string) ([]*models.PullRequestShort, error) {
+	return f.getAuthorOpenFn(ctx, authorID)
+}
+
+func (f *fakePRRepo) GetPRsNeedingReviewers(ctx context.Context, teamName string, minReviewers int) ([]*models.PRNeedingReviewers, error) {
+	return f.getNeedingFn(ctx, teamName, minReviewers)
+}
+
 func (f *fakePRRepo) GetPR(ctx context.Context, prID string) (*models.PullRequest, error) {
 	return f.getPRFn(ctx, prID)
 }
@@ -49,10 +137,18 @@ func (f *fakePRRepo) MarkPRMerged(ctx context.Context, prID string, mergedAt tim
 	return f.markMergedFn(ctx, prID, mergedAt)
 }
 
+func (f *fakePRRepo) MarkPRClosed(ctx context.Context, prID string, closedAt time.Time) error {
+	return f.markClosedFn(ctx, prID, closedAt)
+}
+
 func (f *fakePRRepo) ReplaceReviewer(ctx context.Context, prID, oldReviewerID, newReviewerID string) error {
 	return f.replaceReviewerFn(ctx, prID, oldReviewerID, newReviewerID)
 }
 
+func (f *fakePRRepo) RemoveReviewer(ctx context.Context, prID, reviewerID string) error {
+	return f.removeReviewerFn(ctx, prID, reviewerID)
+}
+
 func (f *fakePRRepo) GetAssignmentsStats(ctx context.Context) (*models.AssignmentsStatsResponse, error) {
 	return f.getStatsFn(ctx)
 }
@@ -60,7 +156,13 @@ func (f *fakePRRepo) GetAssignmentsStats(ctx context.Context) (*models.Assignmen
 type fakePRUserRepo struct {
 	getUserFn       func(context.Context, string) (*models.UserWithTeam, error)
 	getTeammatesFn  func(context.Context, string, string, int) ([]*models.User, error)
-	getRandomMateFn func(context.Context, string, []string) (*models.User, error)
+	getLeastLoadFn  func(context.Context, string, string, int) ([]*models.User, error)
+	getRotationFn   func(context.Context, string, string, int) ([]*models.User, error)
+	getOverlapFn    func(context.Context, string, string, int) ([]*models.User, error)
+	getRandomMateFn func(context.Context, string, string, []string) (*models.User, error)
+	getByUsernameFn func(context.Context, string) ([]*models.UserWithTeam, error)
+	getTeamLoadFn   func(context.Context, string) ([]*models.ReviewerLoad, error)
+	excluded        map[string][]string
 }
 
 func (f *fakePRUserRepo) GetUserWithTeam(ctx context.Context, userID string) (*models.UserWithTeam, error) {
@@ -71,8 +173,49 @@ func (f *fakePRUserRepo) GetActiveTeammates(ctx context.Context, teamName, exclu
 	return f.getTeammatesFn(ctx, teamName, excludeUserID, limit)
 }
 
-func (f *fakePRUserRepo) GetRandomActiveTeammate(ctx context.Context, teamName string, excludeIDs []string) (*models.User, error) {
-	return f.getRandomMateFn(ctx, teamName, excludeIDs)
+func (f *fakePRUserRepo) GetLeastLoadedTeammates(ctx context.Context, teamName, excludeUserID string, limit int) ([]*models.User, error) {
+	return f.getLeastLoadFn(ctx, teamName, excludeUserID, limit)
+}
+
+func (f *fakePRUserRepo) GetNextRotationTeammates(ctx context.Context, teamName, excludeUserID string, limit int) ([]*models.User, error) {
+	return f.getRotationFn(ctx, teamName, excludeUserID, limit)
+}
+
+func (f *fakePRUserRepo) GetOverlappingTeammates(ctx context.Context, teamName, excludeUserID string, limit int) ([]*models.User, error) {
+	return f.getOverlapFn(ctx, teamName, excludeUserID, limit)
+}
+
+func (f *fakePRUserRepo) GetRandomActiveTeammate(ctx context.Context, teamName, authorID string, excludeIDs []string) (*models.User, error) {
+	return f.getRandomMateFn(ctx, teamName, authorID, excludeIDs)
+}
+
+func (f *fakePRUserRepo) GetUsersByUsername(ctx context.Context, username string) ([]*models.UserWithTeam, error) {
+	if f.getByUsernameFn == nil {
+		return nil, nil
+	}
+	return f.getByUsernameFn(ctx, username)
+}
+
+func (f *fakePRUserRepo) GetTeamReviewLoad(ctx context.Context, teamName string) ([]*models.ReviewerLoad, error) {
+	if f.getTeamLoadFn == nil {
+		return nil, nil
+	}
+	return f.getTeamLoadFn(ctx, teamName)
+}
+
+func (f *fakePRUserRepo) GetExcludedReviewers(_ context.Context, authorID string) ([]string, error) {
+	return f.excluded[authorID], nil
+}
+
+type fakePRTeamRepo struct {
+	getSettingsFn func(context.Context, string) (*models.TeamSettings, error)
+}
+
+func (f *fakePRTeamRepo) GetTeamSettings(ctx context.Context, teamName string) (*models.TeamSettings, error) {
+	if f.getSettingsFn == nil {
+		return &models.TeamSettings{TeamName: teamName}, nil
+	}
+	return f.getSettingsFn(ctx, teamName)
 }
 
 func testLogger() *slog.Logger {
@@ -80,7 +223,7 @@ func testLogger() *slog.Logger {
 }
 
 func TestNewPRService_ValidatesDependencies(t *testing.T) {
-	_, err := NewPRService(nil, nil, nil, nil)
+	_, err := NewPRService(nil, nil, nil, nil, nil)
 	if err == nil {
 		t.Fatalf("expected error when dependencies are nil")
 	}
@@ -106,11 +249,11 @@ func TestPRService_CreatePR_Success(t *testing.T) {
 			return &models.UserWithTeam{User: models.User{ID: userID}, TeamName: "backend"}, nil
 		},
 		getTeammatesFn: func(_ context.Context, teamName, exclude string, limit int) ([]*models.User, error) {
-			return []*models.User{{ID: "u2"}, {ID: "u3"}}, nil
+			return []*models.User{{ID: "u2", Username: "bob"}, {ID: "u3", Username: "carol"}}, nil
 		},
 		getRandomMateFn: nil,
 	}
-	service, err := NewPRService(fakeTxManager{}, repo, userRepo, testLogger())
+	service, err := NewPRService(fakeTxManager{}, repo, userRepo, &fakePRTeamRepo{}, testLogger())
 	if err != nil {
 		t.Fatalf("unexpected error: %v", err)
 	}
@@ -122,238 +265,2223 @@ func TestPRService_CreatePR_Success(t *testing.T) {
 	if err != nil {
 		t.Fatalf("CreatePR returned error: %v", err)
 	}
-	if pr == nil || pr.ID != "pr-1" {
+	if pr == nil || pr.PR.ID != "pr-1" {
 		t.Fatalf("expected created PR, got %#v", pr)
 	}
 	if len(receivedReviewers) != 2 {
 		t.Fatalf("expected 2 reviewers, got %v", receivedReviewers)
 	}
+	if len(pr.PR.ReviewerDetails) != 2 {
+		t.Fatalf("expected 2 reviewer details, got %#v", pr.PR.ReviewerDetails)
+	}
+	for _, detail := range pr.PR.ReviewerDetails {
+		if detail.State != models.ReviewerStateAssigned || detail.Username == "" || detail.AssignedAt.IsZero() {
+			t.Fatalf("unexpected reviewer detail: %#v", detail)
+		}
+	}
 }
 
-func TestPRService_CreatePR_AuthorNotFound(t *testing.T) {
-	repo := &fakePRRepo{}
+func TestPRService_CreatePR_Links(t *testing.T) {
+	var stored models.PullRequest
+	var labels []string
+	repo := &fakePRRepo{
+		createPRFn: func(_ context.Context, pr models.PullRequest) (*models.PullRequest, error) {
+			stored = pr
+			return &pr, nil
+		},
+		setLabelsFn: func(_ context.Context, _ string, l []string) error {
+			labels = l
+			return nil
+		},
+		addReviewersFn: func(context.Context, string, []string) error { return nil },
+	}
 	userRepo := &fakePRUserRepo{
-		getUserFn: func(_ context.Context, _ string) (*models.UserWithTeam, error) {
-			return nil, storage.ErrUserNotFound
+		getUserFn: func(_ context.Context, userID string) (*models.UserWithTeam, error) {
+			return &models.UserWithTeam{User: models.User{ID: userID}, TeamName: "backend"}, nil
+		},
+		getTeammatesFn: func(context.Context, string, string, int) ([]*models.User, error) {
+			return []*models.User{{ID: "u2"}}, nil
 		},
 	}
-	service, err := NewPRService(fakeTxManager{}, repo, userRepo, testLogger())
+	service, err := NewPRService(fakeTxManager{}, repo, userRepo, &fakePRTeamRepo{}, testLogger())
 	if err != nil {
 		t.Fatalf("unexpected error: %v", err)
 	}
-	_, err = service.CreatePR(context.Background(), &models.PRCreateRequest{ID: "p", Title: "t", AuthorID: "a"})
-	if !errors.Is(err, ErrPRAuthorNotFound) {
-		t.Fatalf("expected ErrPRAuthorNotFound, got %v", err)
+	ctx := context.Background()
+	deadline := time.Now().Add(48 * time.Hour)
+	past := time.Now().Add(-time.Hour)
+
+	resp, err := service.CreatePR(ctx, &models.PRCreateRequest{
+		ID:            "pr-1",
+		Title:         "Add search",
+		AuthorID:      "u1",
+		Description:   " Adds v2 search ",
+		RepositoryURL: " https://git.example.com/shop ",
+		Branch:        "feature/search",
+		Labels:        []string{"search", " backend ", "search"},
+		Priority:      " urgent ",
+		Deadline:      &deadline,
+	})
+	if err != nil {
+		t.Fatalf("CreatePR returned error: %v", err)
+	}
+	if stored.Description != "Adds v2 search" || stored.RepositoryURL != "https://git.example.com/shop" || stored.Branch != "feature/search" || stored.Priority != models.PriorityUrgent || stored.Deadline == nil || !stored.Deadline.Equal(deadline) {
+		t.Fatalf("unexpected stored pr: %+v", stored)
+	}
+	if !slices.Equal(labels, []string{"backend", "search"}) {
+		t.Fatalf("unexpected stored labels: %v", labels)
+	}
+	if resp.PR.Branch != "feature/search" || !slices.Equal(resp.PR.Labels, labels) {
+		t.Fatalf("unexpected response: %+v", resp.PR)
+	}
+
+	for name, req := range map[string]*models.PRCreateRequest{
+		"relative url":  {RepositoryURL: "shop/search"},
+		"ssh url":       {RepositoryURL: "git@git.example.com:shop.git"},
+		"branch spaces": {Branch: "my branch"},
+		"long branch":   {Branch: strings.Repeat("b", maxBranchLength+1)},
+		"long label":    {Labels: []string{strings.Repeat("l", maxPRLabelLength+1)}},
+		"priority":      {Priority: "blocker"},
+		"past deadline": {Deadline: &past},
+	} {
+		req.ID, req.Title, req.AuthorID = "pr-2", "title", "u1"
+		if _, err := service.CreatePR(ctx, req); !errors.Is(err, ErrPRValidation) {
+			t.Fatalf("%s: expected validation error, got %v", name, err)
+		}
 	}
 }
 
-func TestPRService_GetUserReviews_EmptyList(t *testing.T) {
+func TestPRService_CreatePR_Idempotent(t *testing.T) {
+	created := 0
+	deadline := time.Now().Add(-time.Hour).UTC()
 	repo := &fakePRRepo{
-		getReviewerPRsFn: func(_ context.Context, _ string) ([]*models.PullRequestShort, error) {
-			return nil, nil
+		getPRFn: func(_ context.Context, id string) (*models.PullRequest, error) {
+			if id != "pr-1" {
+				return nil, storage.ErrPRNotFound
+			}
+			return &models.PullRequest{
+				ID: "pr-1", Title: "Add search", AuthorID: "u1", Branch: "feature/search",
+				Labels: []string{"backend", "search"}, Priority: models.PriorityNormal,
+				Status: models.StatusOpen, Reviewers: []string{"u2"}, Deadline: &deadline,
+			}, nil
+		},
+		createPRFn: func(_ context.Context, pr models.PullRequest) (*models.PullRequest, error) {
+			created++
+			return &pr, nil
 		},
+		setLabelsFn:    func(context.Context, string, []string) error { return nil },
+		addReviewersFn: func(context.Context, string, []string) error { return nil },
 	}
 	userRepo := &fakePRUserRepo{
-		getUserFn: func(_ context.Context, _ string) (*models.UserWithTeam, error) {
-			return &models.UserWithTeam{TeamName: "backend"}, nil
+		getUserFn: func(_ context.Context, userID string) (*models.UserWithTeam, error) {
+			return &models.UserWithTeam{User: models.User{ID: userID}, TeamName: "backend"}, nil
+		},
+		getTeammatesFn: func(context.Context, string, string, int) ([]*models.User, error) {
+			return []*models.User{{ID: "u2"}}, nil
 		},
 	}
-	service, err := NewPRService(fakeTxManager{}, repo, userRepo, testLogger())
+	service, err := NewPRService(fakeTxManager{}, repo, userRepo, &fakePRTeamRepo{}, testLogger())
 	if err != nil {
 		t.Fatalf("unexpected error: %v", err)
 	}
-	resp, err := service.GetUserReviews(context.Background(), " u1 ")
-	if err != nil {
-		t.Fatalf("GetUserReviews returned error: %v", err)
+	ctx := context.Background()
+	req := func() *models.PRCreateRequest {
+		return &models.PRCreateRequest{
+			ID: "pr-1", Title: " Add search ", AuthorID: "u1", Branch: "feature/search",
+			Labels: []string{"search", "backend"}, Idempotent: true,
+		}
 	}
-	if len(resp.PullRequests) != 0 {
-		t.Fatalf("expected empty slice, got %d", len(resp.PullRequests))
+
+	resp, err := service.CreatePR(ctx, req())
+	if err != nil || !resp.Existing || !slices.Equal(resp.PR.Reviewers, []string{"u2"}) {
+		t.Fatalf("expected the existing pull request, got %+v, %v", resp, err)
+	}
+	if created != 0 {
+		t.Fatalf("expected no insert for a redelivery")
+	}
+
+	late := req()
+	late.Deadline = &deadline
+	if resp, err := service.CreatePR(ctx, late); err != nil || !resp.Existing {
+		t.Fatalf("expected a redelivery past its deadline to return the existing pull request, got %+v, %v", resp, err)
+	}
+
+	conflict := req()
+	conflict.Branch, conflict.Priority = "feature/other", "high"
+	_, err = service.CreatePR(ctx, conflict)
+	if !errors.Is(err, ErrPRAlreadyExists) || !strings.Contains(err.Error(), "branch, priority") {
+		t.Fatalf("expected a conflict naming the fields, got %v", err)
+	}
+
+	fresh := req()
+	fresh.ID = "pr-2"
+	if resp, err := service.CreatePR(ctx, fresh); err != nil || resp.Existing || created != 1 {
+		t.Fatalf("expected a new pull request, got %+v, %v", resp, err)
 	}
 }
 
-func TestPRService_MergePR_Idempotent(t *testing.T) {
-	marked := false
+func TestPRService_CreatePR_IdempotentRequiresID(t *testing.T) {
+	created := 0
 	repo := &fakePRRepo{
-		getPRFn: func(_ context.Context, _ string) (*models.PullRequest, error) {
-			return &models.PullRequest{ID: "pr", Status: models.StatusMerged}, nil
+		getPRFn: func(context.Context, string) (*models.PullRequest, error) {
+			return nil, storage.ErrPRNotFound
 		},
-		markMergedFn: func(_ context.Context, _ string, _ time.Time) error {
-			marked = true
-			return nil
+		createPRFn: func(_ context.Context, pr models.PullRequest) (*models.PullRequest, error) {
+			created++
+			return &pr, nil
 		},
+		setLabelsFn:    func(context.Context, string, []string) error { return nil },
+		addReviewersFn: func(context.Context, string, []string) error { return nil },
 	}
-	userRepo := &fakePRUserRepo{}
-	service, err := NewPRService(fakeTxManager{}, repo, userRepo, testLogger())
+	userRepo := &fakePRUserRepo{
+		getUserFn: func(_ context.Context, userID string) (*models.UserWithTeam, error) {
+			return &models.UserWithTeam{User: models.User{ID: userID}, TeamName: "backend"}, nil
+		},
+		getTeammatesFn: func(context.Context, string, string, int) ([]*models.User, error) {
+			return []*models.User{{ID: "u2"}}, nil
+		},
+	}
+	service, err := NewPRService(fakeTxManager{}, repo, userRepo, &fakePRTeamRepo{}, testLogger(), WithGeneratedPRIDs())
 	if err != nil {
 		t.Fatalf("unexpected error: %v", err)
 	}
-	pr, err := service.MergePR(context.Background(), &models.PRMergeRequest{ID: "pr"})
-	if err != nil {
-		t.Fatalf("MergePR returned error: %v", err)
+
+	// A retried create without an id must not mint a second pull request.
+	for range 2 {
+		_, err := service.CreatePR(context.Background(), &models.PRCreateRequest{Title: "Add search", AuthorID: "u1", Idempotent: true})
+		if !errors.Is(err, ErrPRValidation) {
+			t.Fatalf("expected validation error, got %v", err)
+		}
 	}
-	if pr.Status != models.StatusMerged {
-		t.Fatalf("expected status MERGED, got %s", pr.Status)
+	if created != 0 {
+		t.Fatalf("expected no pull request to be created, got %d", created)
 	}
-	if marked {
-		t.Fatalf("did not expect MarkPRMerged to be called for already merged PR")
+
+	if _, err := service.CreatePR(context.Background(), &models.PRCreateRequest{Title: "Add search", AuthorID: "u1"}); err != nil || created != 1 {
+		t.Fatalf("expected a generated id without the idempotent flag, got %v", err)
 	}
 }
 
-func TestPRService_MergePR_SetsTimestamp(t *testing.T) {
-	var captured time.Time
+func TestPRService_CreatePR_BorrowsFromFallbackTeams(t *testing.T) {
 	repo := &fakePRRepo{
-		getPRFn: func(_ context.Context, _ string) (*models.PullRequest, error) {
-			return &models.PullRequest{ID: "pr", Status: models.StatusOpen}, nil
+		createPRFn: func(_ context.Context, pr models.PullRequest) (*models.PullRequest, error) {
+			return &pr, nil
 		},
-		markMergedFn: func(_ context.Context, _ string, mergedAt time.Time) error {
-			captured = mergedAt
-			return nil
+		addReviewersFn: func(context.Context, string, []string) error { return nil },
+	}
+	var asked []string
+	userRepo := &fakePRUserRepo{
+		getUserFn: func(_ context.Context, userID string) (*models.UserWithTeam, error) {
+			return &models.UserWithTeam{User: models.User{ID: userID}, TeamName: "backend"}, nil
+		},
+		getTeammatesFn: func(_ context.Context, teamName, exclude string, limit int) ([]*models.User, error) {
+			asked = append(asked, fmt.Sprintf("%s:%s:%d", teamName, exclude, limit))
+			switch teamName {
+			case "backend":
+				return []*models.User{{ID: "u2", Username: "bob"}}, nil
+			case "platform":
+				return []*models.User{{ID: "p1", Username: "pat"}}, nil
+			}
+			return nil, nil
 		},
 	}
-	userRepo := &fakePRUserRepo{}
-	service, err := NewPRService(fakeTxManager{}, repo, userRepo, testLogger())
+	teams := &fakePRTeamRepo{
+		getSettingsFn: func(_ context.Context, teamName string) (*models.TeamSettings, error) {
+			return &models.TeamSettings{TeamName: teamName, FallbackTeams: []string{"empty", "platform", "frontend"}}, nil
+		},
+	}
+	service, err := NewPRService(fakeTxManager{}, repo, userRepo, teams, testLogger())
 	if err != nil {
 		t.Fatalf("unexpected error: %v", err)
 	}
-	pr, err := service.MergePR(context.Background(), &models.PRMergeRequest{ID: "pr"})
+	resp, err := service.CreatePR(context.Background(), &models.PRCreateRequest{ID: "pr-1", Title: "t", AuthorID: "u1"})
 	if err != nil {
-		t.Fatalf("MergePR returned error: %v", err)
+		t.Fatalf("CreatePR returned error: %v", err)
 	}
-	if pr.MergedAt == nil || captured.IsZero() || !pr.MergedAt.Equal(captured) {
-		t.Fatalf("expected merged timestamp propagated")
+	want := []string{"backend:u1:2", "empty:u1:1", "platform:u1:1"}
+	if !slices.Equal(asked, want) {
+		t.Fatalf("expected lookups %v, got %v", want, asked)
+	}
+	details := resp.PR.ReviewerDetails
+	if len(details) != 2 || details[0].BorrowedFrom != "" || details[1].UserID != "p1" || details[1].BorrowedFrom != "platform" {
+		t.Fatalf("unexpected reviewers: %+v %+v", details[0], details[len(details)-1])
+	}
+	if resp.PR.NeedMore {
+		t.Fatalf("expected borrowed reviewer to satisfy reviewersPerPR")
 	}
 }
 
-func TestPRService_ReassignReviewer_Success(t *testing.T) {
+func TestPRService_CreatePR_DeprioritizesRecentReviewers(t *testing.T) {
+	var reviewers []string
 	repo := &fakePRRepo{
-		getPRFn: func(_ context.Context, _ string) (*models.PullRequest, error) {
-			return &models.PullRequest{ID: "pr", AuthorID: "author", Status: models.StatusOpen, Reviewers: []string{"u2", "u3"}}, nil
+		createPRFn: func(_ context.Context, pr models.PullRequest) (*models.PullRequest, error) {
+			return &pr, nil
 		},
-		replaceReviewerFn: func(_ context.Context, _, oldID, newID string) error {
-			if oldID != "u2" || newID != "u4" {
-				return fmt.Errorf("unexpected replacement %s -> %s", oldID, newID)
-			}
+		addReviewersFn: func(_ context.Context, _ string, ids []string) error {
+			reviewers = ids
 			return nil
 		},
+		getRecentFn: func(_ context.Context, authorID string, lastPRs int) ([]string, error) {
+			if authorID != "u1" || lastPRs != 3 {
+				t.Fatalf("unexpected recent lookup %s %d", authorID, lastPRs)
+			}
+			return []string{"u2", "u3"}, nil
+		},
 	}
+	var asked int
 	userRepo := &fakePRUserRepo{
 		getUserFn: func(_ context.Context, userID string) (*models.UserWithTeam, error) {
 			return &models.UserWithTeam{User: models.User{ID: userID}, TeamName: "backend"}, nil
 		},
-		getRandomMateFn: func(_ context.Context, _ string, _ []string) (*models.User, error) {
-			return &models.User{ID: "u4"}, nil
+		getTeammatesFn: func(_ context.Context, _, _ string, limit int) ([]*models.User, error) {
+			asked = limit
+			return []*models.User{{ID: "u2"}, {ID: "u4"}, {ID: "u3"}}, nil
 		},
 	}
-	service, err := NewPRService(fakeTxManager{}, repo, userRepo, testLogger())
+	service, err := NewPRService(fakeTxManager{}, repo, userRepo, &fakePRTeamRepo{}, testLogger(), WithRecentReviewerMemory(3))
 	if err != nil {
 		t.Fatalf("unexpected error: %v", err)
 	}
-	resp, err := service.ReassignReviewer(context.Background(), &models.PRReassignRequest{ID: "pr", OldReviewerID: "u2"})
-	if err != nil {
-		t.Fatalf("ReassignReviewer returned error: %v", err)
+	if _, err := service.CreatePR(context.Background(), &models.PRCreateRequest{ID: "pr-1", Title: "t", AuthorID: "u1"}); err != nil {
+		t.Fatalf("CreatePR returned error: %v", err)
 	}
-	if resp.ReplacedBy != "u4" {
-		t.Fatalf("expected replacement u4, got %s", resp.ReplacedBy)
+	if asked != 4 {
+		t.Fatalf("expected extra candidates for recent reviewers, asked for %d", asked)
 	}
-	if resp.PR.Reviewers[0] != "u4" {
-		t.Fatalf("expected reviewers to be updated, got %v", resp.PR.Reviewers)
+	if !slices.Equal(reviewers, []string{"u4", "u2"}) {
+		t.Fatalf("expected fresh reviewer first, got %v", reviewers)
 	}
 }
 
-func TestPRService_ReassignReviewer_ExcludesAuthor(t *testing.T) {
+func TestPRService_CreatePR_IncludesRequiredReviewers(t *testing.T) {
+	var reviewers []string
 	repo := &fakePRRepo{
-		getPRFn: func(_ context.Context, _ string) (*models.PullRequest, error) {
-			return &models.PullRequest{
-				ID:        "pr",
-				AuthorID:  "author-1",
-				Status:    models.StatusOpen,
-				Reviewers: []string{"u1"},
-			}, nil
+		createPRFn: func(_ context.Context, pr models.PullRequest) (*models.PullRequest, error) {
+			return &pr, nil
+		},
+		addReviewersFn: func(_ context.Context, _ string, ids []string) error {
+			reviewers = ids
+			return nil
 		},
-		replaceReviewerFn: func(context.Context, string, string, string) error { return nil },
 	}
+	var asked int
 	userRepo := &fakePRUserRepo{
-		getUserFn: func(_ context.Context, _ string) (*models.UserWithTeam, error) {
-			return &models.UserWithTeam{TeamName: "backend"}, nil
+		getUserFn: func(_ context.Context, userID string) (*models.UserWithTeam, error) {
+			return &models.UserWithTeam{User: models.User{ID: userID, Username: userID, IsActive: userID != "gone"}, TeamName: "backend"}, nil
 		},
-		getRandomMateFn: func(_ context.Context, _ string, exclude []string) (*models.User, error) {
-			for _, id := range exclude {
-				if id == "author-1" {
-					return &models.User{ID: "u2"}, nil
-				}
-			}
-			return nil, fmt.Errorf("author not in exclude list: %v", exclude)
+		getTeammatesFn: func(_ context.Context, _, _ string, limit int) ([]*models.User, error) {
+			asked = limit
+			return []*models.User{{ID: "pay"}, {ID: "u2"}}, nil
+		},
+	}
+	teams := &fakePRTeamRepo{
+		getSettingsFn: func(_ context.Context, teamName string) (*models.TeamSettings, error) {
+			return &models.TeamSettings{TeamName: teamName, RequiredReviewers: []models.RequiredReviewerRule{
+				{Component: "payments", UserID: "pay"},
+				{PathPrefix: "docs/", UserID: "writer"},
+				{Component: "billing", UserID: "gone"},
+				{Component: "payments", UserID: "u1"},
+			}}, nil
 		},
 	}
-	service, err := NewPRService(fakeTxManager{}, repo, userRepo, testLogger())
+	service, err := NewPRService(fakeTxManager{}, repo, userRepo, teams, testLogger())
 	if err != nil {
 		t.Fatalf("unexpected error: %v", err)
 	}
-	if _, err := service.ReassignReviewer(context.Background(), &models.PRReassignRequest{ID: "pr", OldReviewerID: "u1"}); err != nil {
-		t.Fatalf("ReassignReviewer returned error: %v", err)
+	resp, err := service.CreatePR(context.Background(), &models.PRCreateRequest{
+		ID:         "pr-1",
+		Title:      "t",
+		AuthorID:   "u1",
+		Components: []string{" Payments ", "billing"},
+		Paths:      []string{"/cmd/main.go"},
+	})
+	if err != nil {
+		t.Fatalf("CreatePR returned error: %v", err)
+	}
+	if asked != 2 {
+		t.Fatalf("expected one slot plus the required reviewer to skip, asked for %d", asked)
+	}
+	if !slices.Equal(reviewers, []string{"pay", "u2"}) {
+		t.Fatalf("expected required reviewer first, got %v", reviewers)
+	}
+	if len(resp.Warnings) != 1 || !strings.Contains(resp.Warnings[0], "gone") {
+		t.Fatalf("expected warning about inactive required reviewer, got %v", resp.Warnings)
 	}
 }
 
-func TestPRService_GetAssignmentsStats_Success(t *testing.T) {
+func TestPRService_CreatePR_LoadBalancedStrategy(t *testing.T) {
+	var reviewers []string
 	repo := &fakePRRepo{
-		getStatsFn: func(context.Context) (*models.AssignmentsStatsResponse, error) {
-			return &models.AssignmentsStatsResponse{}, nil
+		createPRFn: func(_ context.Context, pr models.PullRequest) (*models.PullRequest, error) {
+			return &pr, nil
+		},
+		addReviewersFn: func(_ context.Context, _ string, ids []string) error {
+			reviewers = ids
+			return nil
 		},
 	}
-	userRepo := &fakePRUserRepo{}
-	service, err := NewPRService(fakeTxManager{}, repo, userRepo, testLogger())
+	userRepo := &fakePRUserRepo{
+		getUserFn: func(_ context.Context, userID string) (*models.UserWithTeam, error) {
+			return &models.UserWithTeam{User: models.User{ID: userID}, TeamName: "backend"}, nil
+		},
+		getTeammatesFn: func(context.Context, string, string, int) ([]*models.User, error) {
+			t.Fatalf("random strategy must not be used")
+			return nil, nil
+		},
+		getLeastLoadFn: func(_ context.Context, team, exclude string, limit int) ([]*models.User, error) {
+			if team != "backend" || exclude != "u1" || limit != reviewersPerPR {
+				t.Fatalf("unexpected args %s %s %d", team, exclude, limit)
+			}
+			return []*models.User{{ID: "u4"}, {ID: "u5"}}, nil
+		},
+	}
+	service, err := NewPRService(fakeTxManager{}, repo, userRepo, &fakePRTeamRepo{}, testLogger(), WithReviewerStrategy(ReviewerStrategyLoadBalanced))
 	if err != nil {
 		t.Fatalf("unexpected error: %v", err)
 	}
-	stats, err := service.GetAssignmentsStats(context.Background())
-	if err != nil {
-		t.Fatalf("GetAssignmentsStats returned error: %v", err)
+	if _, err := service.CreatePR(context.Background(), &models.PRCreateRequest{ID: "pr-1", Title: "t", AuthorID: "u1"}); err != nil {
+		t.Fatalf("CreatePR returned error: %v", err)
 	}
-	if stats.ByUser == nil || stats.ByPR == nil {
-		t.Fatalf("expected slices to be initialized")
+	if len(reviewers) != 2 || reviewers[0] != "u4" || reviewers[1] != "u5" {
+		t.Fatalf("expected least loaded reviewers, got %v", reviewers)
 	}
 }
 
-func TestPRService_GetAssignmentsStats_Error(t *testing.T) {
+func TestPRService_PreviewAssignment(t *testing.T) {
 	repo := &fakePRRepo{
-		getStatsFn: func(context.Context) (*models.AssignmentsStatsResponse, error) {
-			return nil, errors.New("db error")
+		getReviewerPRsFn: func(_ context.Context, userID string) ([]*models.PullRequestShort, error) {
+			if userID == "u4" {
+				return []*models.PullRequestShort{
+					{ID: "pr-1", Status: models.StatusOpen},
+					{ID: "pr-2", Status: models.StatusMerged},
+				}, nil
+			}
+			return nil, nil
 		},
 	}
-	userRepo := &fakePRUserRepo{}
-	service, err := NewPRService(fakeTxManager{}, repo, userRepo, testLogger())
+	userRepo := &fakePRUserRepo{
+		getUserFn: func(_ context.Context, userID string) (*models.UserWithTeam, error) {
+			return &models.UserWithTeam{User: models.User{ID: userID}, TeamName: "backend"}, nil
+		},
+		getLeastLoadFn: func(context.Context, string, string, int) ([]*models.User, error) {
+			return []*models.User{{ID: "u4", Username: "dave"}}, nil
+		},
+		getTeammatesFn: func(_ context.Context, team, _ string, _ int) ([]*models.User, error) {
+			if team != "platform" {
+				t.Fatalf("unexpected fallback team %s", team)
+			}
+			return []*models.User{{ID: "u9", Username: "zoe"}}, nil
+		},
+	}
+	teamRepo := &fakePRTeamRepo{
+		getSettingsFn: func(_ context.Context, team string) (*models.TeamSettings, error) {
+			return &models.TeamSettings{TeamName: team, FallbackTeams: []string{"platform"}}, nil
+		},
+	}
+	service, err := NewPRService(fakeTxManager{}, repo, userRepo, teamRepo, testLogger(), WithReviewerStrategy(ReviewerStrategyLoadBalanced))
 	if err != nil {
 		t.Fatalf("unexpected error: %v", err)
 	}
-	_, err = service.GetAssignmentsStats(context.Background())
-	if err == nil {
-		t.Fatalf("expected error")
+
+	resp, err := service.PreviewAssignment(context.Background(), "u1")
+	if err != nil {
+		t.Fatalf("PreviewAssignment returned error: %v", err)
+	}
+	if resp.TeamName != "backend" || resp.Strategy != ReviewerStrategyLoadBalanced || resp.NeedMore || len(resp.Candidates) != 2 {
+		t.Fatalf("unexpected preview %+v", resp)
+	}
+	first, second := resp.Candidates[0], resp.Candidates[1]
+	if first.UserID != "u4" || first.Reason != models.PickReasonLeastLoaded || first.OpenReviews != 1 || first.TeamName != "backend" {
+		t.Fatalf("unexpected first candidate %+v", first)
+	}
+	if second.UserID != "u9" || second.Reason != models.PickReasonFallbackTeam || second.TeamName != "platform" {
+		t.Fatalf("unexpected second candidate %+v", second)
 	}
 }
 
-func TestPRService_ReassignReviewer_NoCandidate(t *testing.T) {
+func TestPRService_CreatePR_RoundRobinStrategy(t *testing.T) {
+	var reviewers []string
 	repo := &fakePRRepo{
-		getPRFn: func(_ context.Context, _ string) (*models.PullRequest, error) {
-			return &models.PullRequest{ID: "pr", Status: models.StatusOpen, Reviewers: []string{"u2"}}, nil
+		createPRFn: func(_ context.Context, pr models.PullRequest) (*models.PullRequest, error) {
+			return &pr, nil
 		},
-		replaceReviewerFn: func(context.Context, string, string, string) error {
+		addReviewersFn: func(_ context.Context, _ string, ids []string) error {
+			reviewers = ids
 			return nil
 		},
 	}
+	userRepo := &fakePRUserRepo{
+		getUserFn: func(_ context.Context, userID string) (*models.UserWithTeam, error) {
+			return &models.UserWithTeam{User: models.User{ID: userID}, TeamName: "backend"}, nil
+		},
+		getRotationFn: func(_ context.Context, team, exclude string, limit int) ([]*models.User, error) {
+			if team != "backend" || exclude != "u1" || limit != reviewersPerPR {
+				t.Fatalf("unexpected args %s %s %d", team, exclude, limit)
+			}
+			return []*models.User{{ID: "u6"}, {ID: "u2"}}, nil
+		},
+	}
+	service, err := NewPRService(fakeTxManager{}, repo, userRepo, &fakePRTeamRepo{}, testLogger(), WithReviewerStrategy(ReviewerStrategyRoundRobin))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if _, err := service.CreatePR(context.Background(), &models.PRCreateRequest{ID: "pr-1", Title: "t", AuthorID: "u1"}); err != nil {
+		t.Fatalf("CreatePR returned error: %v", err)
+	}
+	if len(reviewers) != 2 || reviewers[0] != "u6" || reviewers[1] != "u2" {
+		t.Fatalf("expected rotation order, got %v", reviewers)
+	}
+}
+
+func TestPRService_CreatePR_WorkingHoursStrategy(t *testing.T) {
+	var reviewers []string
+	repo := &fakePRRepo{
+		createPRFn: func(_ context.Context, pr models.PullRequest) (*models.PullRequest, error) {
+			return &pr, nil
+		},
+		addReviewersFn: func(_ context.Context, _ string, ids []string) error {
+			reviewers = ids
+			return nil
+		},
+	}
+	userRepo := &fakePRUserRepo{
+		getUserFn: func(_ context.Context, userID string) (*models.UserWithTeam, error) {
+			return &models.UserWithTeam{User: models.User{ID: userID}, TeamName: "backend"}, nil
+		},
+		getOverlapFn: func(_ context.Context, team, exclude string, limit int) ([]*models.User, error) {
+			if team != "backend" || exclude != "u1" || limit != reviewersPerPR {
+				t.Fatalf("unexpected args %s %s %d", team, exclude, limit)
+			}
+			return []*models.User{{ID: "u4"}, {ID: "u2"}}, nil
+		},
+	}
+	service, err := NewPRService(fakeTxManager{}, repo, userRepo, &fakePRTeamRepo{}, testLogger(), WithReviewerStrategy(ReviewerStrategyWorkingHours))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if _, err := service.CreatePR(context.Background(), &models.PRCreateRequest{ID: "pr-1", Title: "t", AuthorID: "u1"}); err != nil {
+		t.Fatalf("CreatePR returned error: %v", err)
+	}
+	if len(reviewers) != 2 || reviewers[0] != "u4" || reviewers[1] != "u2" {
+		t.Fatalf("expected overlapping reviewers first, got %v", reviewers)
+	}
+}
+
+func TestPRService_CreatePR_FlagsMissingReviewers(t *testing.T) {
+	repo := &fakePRRepo{
+		createPRFn: func(_ context.Context, pr models.PullRequest) (*models.PullRequest, error) {
+			return &pr, nil
+		},
+		addReviewersFn: func(context.Context, string, []string) error { return nil },
+	}
+	userRepo := &fakePRUserRepo{
+		getUserFn: func(_ context.Context, userID string) (*models.UserWithTeam, error) {
+			return &models.UserWithTeam{User: models.User{ID: userID}, TeamName: "backend"}, nil
+		},
+		getTeammatesFn: func(context.Context, string, string, int) ([]*models.User, error) {
+			return []*models.User{{ID: "u2"}}, nil
+		},
+	}
+	service, err := NewPRService(fakeTxManager{}, repo, userRepo, &fakePRTeamRepo{}, testLogger())
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	resp, err := service.CreatePR(context.Background(), &models.PRCreateRequest{ID: "pr-1", Title: "t", AuthorID: "u1"})
+	if err != nil {
+		t.Fatalf("CreatePR returned error: %v", err)
+	}
+	if !resp.PR.NeedMore {
+		t.Fatalf("expected needMoreReviewers for a single reviewer, got %#v", resp.PR)
+	}
+
+	pr := &models.PullRequest{Status: models.StatusMerged}
+	setNeedMoreReviewers(pr)
+	if pr.NeedMore {
+		t.Fatalf("merged pull requests never need more reviewers")
+	}
+}
+
+func TestPRService_CreatePR_AuthorNotFound(t *testing.T) {
+	repo := &fakePRRepo{}
+	userRepo := &fakePRUserRepo{
+		getUserFn: func(_ context.Context, _ string) (*models.UserWithTeam, error) {
+			return nil, storage.ErrUserNotFound
+		},
+	}
+	service, err := NewPRService(fakeTxManager{}, repo, userRepo, &fakePRTeamRepo{}, testLogger())
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	_, err = service.CreatePR(context.Background(), &models.PRCreateRequest{ID: "p", Title: "t", AuthorID: "a"})
+	if !errors.Is(err, ErrPRAuthorNotFound) {
+		t.Fatalf("expected ErrPRAuthorNotFound, got %v", err)
+	}
+}
+
+func TestPRService_GetUserReviews_EmptyList(t *testing.T) {
+	repo := &fakePRRepo{
+		getReviewerPRsFn: func(_ context.Context, _ string) ([]*models.PullRequestShort, error) {
+			return nil, nil
+		},
+	}
 	userRepo := &fakePRUserRepo{
 		getUserFn: func(_ context.Context, _ string) (*models.UserWithTeam, error) {
 			return &models.UserWithTeam{TeamName: "backend"}, nil
 		},
-		getRandomMateFn: func(context.Context, string, []string) (*models.User, error) {
-			return nil, storage.ErrNoCandidate
+	}
+	service, err := NewPRService(fakeTxManager{}, repo, userRepo, &fakePRTeamRepo{}, testLogger())
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	resp, err := service.GetUserReviews(context.Background(), " u1 ")
+	if err != nil {
+		t.Fatalf("GetUserReviews returned error: %v", err)
+	}
+	if len(resp.PullRequests) != 0 {
+		t.Fatalf("expected empty slice, got %d", len(resp.PullRequests))
+	}
+}
+
+func TestPRService_GetUserReviews_ByUsername(t *testing.T) {
+	var requested string
+	repo := &fakePRRepo{
+		getReviewerPRsFn: func(_ context.Context, userID string) ([]*models.PullRequestShort, error) {
+			requested = userID
+			return nil, nil
+		},
+	}
+	userRepo := &fakePRUserRepo{
+		getUserFn: func(_ context.Context, _ string) (*models.UserWithTeam, error) {
+			return &models.UserWithTeam{TeamName: "backend"}, nil
+		},
+		getByUsernameFn: func(_ context.Context, username string) ([]*models.UserWithTeam, error) {
+			if username != "alice" {
+				t.Fatalf("unexpected username %q", username)
+			}
+			return []*models.UserWithTeam{{User: models.User{ID: "u1", Username: "alice"}}}, nil
 		},
 	}
-	service, err := NewPRService(fakeTxManager{}, repo, userRepo, testLogger())
+	service, err := NewPRService(fakeTxManager{}, repo, userRepo, &fakePRTeamRepo{}, testLogger())
 	if err != nil {
 		t.Fatalf("unexpected error: %v", err)
 	}
-	_, err = service.ReassignReviewer(context.Background(), &models.PRReassignRequest{ID: "pr", OldReviewerID: "u2"})
-	if !errors.Is(err, ErrNoReplacement) {
-		t.Fatalf("expected ErrNoReplacement, got %v", err)
+	resp, err := service.GetUserReviews(context.Background(), " @alice ")
+	if err != nil {
+		t.Fatalf("GetUserReviews returned error: %v", err)
+	}
+	if requested != "u1" || resp.UserID != "u1" {
+		t.Fatalf("expected username resolved to u1, got %q / %q", requested, resp.UserID)
+	}
+}
+
+func TestPRService_CreatePR_UnknownAuthorUsername(t *testing.T) {
+	service, err := NewPRService(fakeTxManager{}, &fakePRRepo{}, &fakePRUserRepo{}, &fakePRTeamRepo{}, testLogger())
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	_, err = service.CreatePR(context.Background(), &models.PRCreateRequest{
+		ID:       "pr-1",
+		Title:    "Add feature",
+		AuthorID: "@ghost",
+	})
+	if !errors.Is(err, ErrPRAuthorNotFound) {
+		t.Fatalf("expected ErrPRAuthorNotFound, got %v", err)
+	}
+}
+
+func TestPRService_MergePR_Idempotent(t *testing.T) {
+	marked := false
+	repo := &fakePRRepo{
+		getPRFn: func(_ context.Context, _ string) (*models.PullRequest, error) {
+			return &models.PullRequest{ID: "pr", Status: models.StatusMerged}, nil
+		},
+		markMergedFn: func(_ context.Context, _ string, _ time.Time) error {
+			marked = true
+			return nil
+		},
+	}
+	userRepo := &fakePRUserRepo{}
+	service, err := NewPRService(fakeTxManager{}, repo, userRepo, &fakePRTeamRepo{}, testLogger())
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	pr, err := service.MergePR(context.Background(), &models.PRMergeRequest{ID: "pr"})
+	if err != nil {
+		t.Fatalf("MergePR returned error: %v", err)
+	}
+	if pr.Status != models.StatusMerged {
+		t.Fatalf("expected status MERGED, got %s", pr.Status)
+	}
+	if marked {
+		t.Fatalf("did not expect MarkPRMerged to be called for already merged PR")
+	}
+}
+
+func TestPRService_ClosePR(t *testing.T) {
+	prs := map[string]*models.PullRequest{
+		"open":   {ID: "open", Status: models.StatusOpen, Reviewers: []string{"u2"}},
+		"closed": {ID: "closed", Status: models.StatusClosed},
+		"merged": {ID: "merged", Status: models.StatusMerged},
+	}
+	var marked []string
+	repo := &fakePRRepo{
+		getPRFn: func(_ context.Context, prID string) (*models.PullRequest, error) {
+			pr, ok := prs[prID]
+			if !ok {
+				return nil, storage.ErrPRNotFound
+			}
+			return pr, nil
+		},
+		markClosedFn: func(_ context.Context, prID string, _ time.Time) error {
+			marked = append(marked, prID)
+			return nil
+		},
+	}
+	service, err := NewPRService(fakeTxManager{}, repo, &fakePRUserRepo{}, &fakePRTeamRepo{}, testLogger())
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	pr, err := service.ClosePR(context.Background(), &models.PRCloseRequest{ID: "open"})
+	if err != nil {
+		t.Fatalf("ClosePR returned error: %v", err)
+	}
+	if pr.Status != models.StatusClosed || pr.ClosedAt == nil || pr.NeedMore {
+		t.Fatalf("unexpected closed pr: %+v", pr)
+	}
+	if _, err := service.ClosePR(context.Background(), &models.PRCloseRequest{ID: "closed"}); err != nil {
+		t.Fatalf("closing a closed pr returned error: %v", err)
+	}
+	if !slices.Equal(marked, []string{"open"}) {
+		t.Fatalf("expected only the open pr to be marked, got %v", marked)
+	}
+	if _, err := service.ClosePR(context.Background(), &models.PRCloseRequest{ID: "merged"}); !errors.Is(err, ErrPRMerged) {
+		t.Fatalf("expected ErrPRMerged, got %v", err)
+	}
+	if _, err := service.ClosePR(context.Background(), &models.PRCloseRequest{ID: "ghost"}); !errors.Is(err, ErrPRNotFound) {
+		t.Fatalf("expected ErrPRNotFound, got %v", err)
+	}
+	if got, err := service.GetPR(context.Background(), " open "); err != nil || got.Status != models.StatusClosed {
+		t.Fatalf("expected GetPR to return the closed pr, got %+v, %v", got, err)
+	}
+	if _, err := service.GetPR(context.Background(), "ghost"); !errors.Is(err, ErrPRNotFound) {
+		t.Fatalf("expected ErrPRNotFound from GetPR, got %v", err)
+	}
+
+	if _, err := service.MergePR(context.Background(), &models.PRMergeRequest{ID: "closed"}); !errors.Is(err, ErrPRClosed) {
+		t.Fatalf("expected merge of closed pr to fail with ErrPRClosed, got %v", err)
+	}
+	prs["closed"].Reviewers = []string{"u2"}
+	if _, err := service.ReassignReviewer(context.Background(), &models.PRReassignRequest{ID: "closed", OldReviewerID: "u2"}); !errors.Is(err, ErrPRClosed) {
+		t.Fatalf("expected reassign on closed pr to fail with ErrPRClosed, got %v", err)
+	}
+}
+
+func TestPRService_MergePR_SetsTimestamp(t *testing.T) {
+	var captured time.Time
+	repo := &fakePRRepo{
+		getPRFn: func(_ context.Context, _ string) (*models.PullRequest, error) {
+			return &models.PullRequest{ID: "pr", Status: models.StatusOpen}, nil
+		},
+		markMergedFn: func(_ context.Context, _ string, mergedAt time.Time) error {
+			captured = mergedAt
+			return nil
+		},
+	}
+	userRepo := &fakePRUserRepo{}
+	service, err := NewPRService(fakeTxManager{}, repo, userRepo, &fakePRTeamRepo{}, testLogger())
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	pr, err := service.MergePR(context.Background(), &models.PRMergeRequest{ID: "pr"})
+	if err != nil {
+		t.Fatalf("MergePR returned error: %v", err)
+	}
+	if pr.MergedAt == nil || captured.IsZero() || !pr.MergedAt.Equal(captured) {
+		t.Fatalf("expected merged timestamp propagated")
+	}
+}
+
+func TestPRService_MergePR_RequiresApprovals(t *testing.T) {
+	reviewers := []*models.ReviewerDetail{
+		{UserID: "u2", ReviewState: models.ReviewStateApproved},
+		{UserID: "u3", ReviewState: models.ReviewStateChangesRequested},
+	}
+	merged := false
+	repo := &fakePRRepo{
+		getPRFn: func(_ context.Context, prID string) (*models.PullRequest, error) {
+			return &models.PullRequest{ID: prID, Status: models.StatusOpen, ReviewerDetails: reviewers}, nil
+		},
+		markMergedFn: func(context.Context, string, time.Time) error {
+			merged = true
+			return nil
+		},
+	}
+	service, err := NewPRService(fakeTxManager{}, repo, &fakePRUserRepo{}, &fakePRTeamRepo{}, testLogger(), WithRequiredApprovals())
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	_, err = service.MergePR(context.Background(), &models.PRMergeRequest{ID: "pr"})
+	if !errors.Is(err, ErrPRNotApproved) || !strings.Contains(err.Error(), "u3") || merged {
+		t.Fatalf("expected ErrPRNotApproved naming u3, got %v (merged %v)", err, merged)
+	}
+
+	reviewers[1].ReviewState = models.ReviewStateApproved
+	if _, err := service.MergePR(context.Background(), &models.PRMergeRequest{ID: "pr"}); err != nil || !merged {
+		t.Fatalf("expected approved PR to merge, got %v", err)
+	}
+}
+
+func TestPRService_ReassignReviewer_Success(t *testing.T) {
+	repo := &fakePRRepo{
+		getPRFn: func(_ context.Context, _ string) (*models.PullRequest, error) {
+			return &models.PullRequest{ID: "pr", AuthorID: "author", Status: models.StatusOpen, Reviewers: []string{"u2", "u3"}}, nil
+		},
+		replaceReviewerFn: func(_ context.Context, _, oldID, newID string) error {
+			if oldID != "u2" || newID != "u4" {
+				return fmt.Errorf("unexpected replacement %s -> %s", oldID, newID)
+			}
+			return nil
+		},
+	}
+	userRepo := &fakePRUserRepo{
+		getUserFn: func(_ context.Context, userID string) (*models.UserWithTeam, error) {
+			return &models.UserWithTeam{User: models.User{ID: userID}, TeamName: "backend"}, nil
+		},
+		getRandomMateFn: func(_ context.Context, _, _ string, _ []string) (*models.User, error) {
+			return &models.User{ID: "u4"}, nil
+		},
+	}
+	service, err := NewPRService(fakeTxManager{}, repo, userRepo, &fakePRTeamRepo{}, testLogger())
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	resp, err := service.ReassignReviewer(context.Background(), &models.PRReassignRequest{ID: "pr", OldReviewerID: "u2"})
+	if err != nil {
+		t.Fatalf("ReassignReviewer returned error: %v", err)
+	}
+	if resp.ReplacedBy != "u4" {
+		t.Fatalf("expected replacement u4, got %s", resp.ReplacedBy)
+	}
+	if resp.PR.Reviewers[0] != "u4" {
+		t.Fatalf("expected reviewers to be updated, got %v", resp.PR.Reviewers)
+	}
+}
+
+func TestPRService_DeclineReview(t *testing.T) {
+	var events []string
+	repo := &fakePRRepo{
+		getPRFn: func(_ context.Context, _ string) (*models.PullRequest, error) {
+			return &models.PullRequest{ID: "pr", AuthorID: "author", Status: models.StatusOpen, Reviewers: []string{"u2", "u3"}}, nil
+		},
+		replaceReviewerFn: func(context.Context, string, string, string) error { return nil },
+		addEventsFn: func(_ context.Context, _ string, userIDs []string, event, reason string) error {
+			events = append(events, userIDs[0]+":"+event+":"+reason)
+			return nil
+		},
+	}
+	userRepo := &fakePRUserRepo{
+		getUserFn: func(_ context.Context, userID string) (*models.UserWithTeam, error) {
+			return &models.UserWithTeam{User: models.User{ID: userID}, TeamName: "backend"}, nil
+		},
+		getRandomMateFn: func(_ context.Context, _, _ string, _ []string) (*models.User, error) {
+			return &models.User{ID: "u4"}, nil
+		},
+	}
+	service, err := NewPRService(fakeTxManager{}, repo, userRepo, &fakePRTeamRepo{}, testLogger())
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	resp, err := service.DeclineReview(context.Background(), &models.PRDeclineRequest{ID: "pr", UserID: "u2", Reason: " too busy "})
+	if err != nil {
+		t.Fatalf("DeclineReview returned error: %v", err)
+	}
+	if resp.ReplacedBy != "u4" || resp.PR.Reviewers[0] != "u4" {
+		t.Fatalf("unexpected response: %+v", resp)
+	}
+	if !slices.Equal(events, []string{"u2:DECLINED:too busy", "u4:ASSIGNED:too busy"}) {
+		t.Fatalf("unexpected events: %v", events)
+	}
+
+	events = nil
+	if _, err := service.DeclineReview(context.Background(), &models.PRDeclineRequest{ID: "pr", UserID: "u2"}); err != nil {
+		t.Fatalf("DeclineReview without reason returned error: %v", err)
+	}
+	if events[0] != "u2:DECLINED:"+defaultDeclineReason {
+		t.Fatalf("expected default reason, got %v", events)
+	}
+	if _, err := service.DeclineReview(context.Background(), &models.PRDeclineRequest{ID: "pr"}); !errors.Is(err, ErrPRValidation) {
+		t.Fatalf("expected ErrPRValidation without user_id, got %v", err)
+	}
+	if _, err := service.DeclineReview(context.Background(), &models.PRDeclineRequest{ID: "pr", UserID: "u2", Reason: strings.Repeat("x", maxDeclineReasonLength+1)}); !errors.Is(err, ErrPRValidation) {
+		t.Fatalf("expected ErrPRValidation for long reason, got %v", err)
+	}
+}
+
+func TestPRService_ReassignReviewer_ExcludesAuthor(t *testing.T) {
+	repo := &fakePRRepo{
+		getPRFn: func(_ context.Context, _ string) (*models.PullRequest, error) {
+			return &models.PullRequest{
+				ID:        "pr",
+				AuthorID:  "author-1",
+				Status:    models.StatusOpen,
+				Reviewers: []string{"u1"},
+			}, nil
+		},
+		replaceReviewerFn: func(context.Context, string, string, string) error { return nil },
+	}
+	userRepo := &fakePRUserRepo{
+		getUserFn: func(_ context.Context, _ string) (*models.UserWithTeam, error) {
+			return &models.UserWithTeam{TeamName: "backend"}, nil
+		},
+		getRandomMateFn: func(_ context.Context, _, _ string, exclude []string) (*models.User, error) {
+			for _, id := range exclude {
+				if id == "author-1" {
+					return &models.User{ID: "u2"}, nil
+				}
+			}
+			return nil, fmt.Errorf("author not in exclude list: %v", exclude)
+		},
+	}
+	service, err := NewPRService(fakeTxManager{}, repo, userRepo, &fakePRTeamRepo{}, testLogger())
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if _, err := service.ReassignReviewer(context.Background(), &models.PRReassignRequest{ID: "pr", OldReviewerID: "u1"}); err != nil {
+		t.Fatalf("ReassignReviewer returned error: %v", err)
+	}
+}
+
+func TestPRService_ReassignAll_ReportsPerPROutcome(t *testing.T) {
+	repo := &fakePRRepo{
+		getReviewerPRsFn: func(_ context.Context, userID string) ([]*models.PullRequestShort, error) {
+			return []*models.PullRequestShort{
+				{ID: "pr1", Status: models.StatusOpen},
+				{ID: "pr2", Status: models.StatusMerged},
+				{ID: "pr3", Status: models.StatusOpen},
+			}, nil
+		},
+		getPRFn: func(_ context.Context, prID string) (*models.PullRequest, error) {
+			return &models.PullRequest{ID: prID, AuthorID: "author", Status: models.StatusOpen, Reviewers: []string{"u1"}}, nil
+		},
+		replaceReviewerFn: func(context.Context, string, string, string) error { return nil },
+	}
+	calls := 0
+	userRepo := &fakePRUserRepo{
+		getUserFn: func(_ context.Context, userID string) (*models.UserWithTeam, error) {
+			return &models.UserWithTeam{User: models.User{ID: userID}, TeamName: "backend"}, nil
+		},
+		getRandomMateFn: func(context.Context, string, string, []string) (*models.User, error) {
+			calls++
+			if calls == 2 {
+				return nil, storage.ErrNoCandidate
+			}
+			return &models.User{ID: "u2"}, nil
+		},
+	}
+	service, err := NewPRService(fakeTxManager{}, repo, userRepo, &fakePRTeamRepo{}, testLogger())
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	preview, err := service.ReassignAll(context.Background(), &models.PRReassignAllRequest{OldUserID: "u1", Preview: true})
+	if err != nil {
+		t.Fatalf("preview returned error: %v", err)
+	}
+	if !preview.Preview || preview.ChangeSetToken == "" || preview.Reassigned != 1 || preview.Failed != 1 {
+		t.Fatalf("unexpected preview: %+v", preview)
+	}
+	if _, err := service.ReassignAll(context.Background(), &models.PRReassignAllRequest{OldUserID: "u1", ChangeSetToken: "reassignAll.stale"}); !errors.Is(err, ErrStalePreview) {
+		t.Fatalf("expected ErrStalePreview, got %v", err)
+	}
+
+	calls = 0
+	resp, err := service.ReassignAll(context.Background(), &models.PRReassignAllRequest{OldUserID: "u1", ChangeSetToken: preview.ChangeSetToken})
+	if err != nil {
+		t.Fatalf("ReassignAll returned error: %v", err)
+	}
+	if resp.Reassigned != 1 || resp.Failed != 1 || len(resp.Results) != 2 {
+		t.Fatalf("unexpected response: %+v", resp)
+	}
+	if got := resp.Results[0]; got.PullRequestID != "pr1" || got.Outcome != models.ReassignOutcomeReassigned || got.ReplacedBy != "u2" {
+		t.Fatalf("unexpected first result: %+v", got)
+	}
+	if got := resp.Results[1]; got.PullRequestID != "pr3" || got.Outcome != models.ReassignOutcomeFailed || !errors.Is(got.Err, ErrNoReplacement) {
+		t.Fatalf("unexpected second result: %+v", got)
+	}
+}
+
+func TestPRService_GetAgenda(t *testing.T) {
+	now := time.Now()
+	past, future := now.Add(-time.Hour), now.Add(time.Hour)
+	repo := &fakePRRepo{
+		getAgendaFn: func(_ context.Context, userID string, defaultDueHours int) ([]*models.AgendaItem, error) {
+			if userID != "u1" || defaultDueHours != defaultAgendaDueHours {
+				t.Fatalf("unexpected agenda lookup %s %d", userID, defaultDueHours)
+			}
+			return []*models.AgendaItem{
+				{PullRequestID: "acked", AssignedAt: now.Add(-72 * time.Hour), AcknowledgedAt: &past},
+				{PullRequestID: "waiting", AssignedAt: now.Add(-2 * time.Hour), DueAt: &future},
+				{PullRequestID: "late", AssignedAt: now.Add(-25 * time.Hour), DueAt: &past},
+				{PullRequestID: "fresh", AssignedAt: now.Add(-time.Hour), DueAt: &future, AssignedToday: true},
+			}, nil
+		},
+	}
+	userRepo := &fakePRUserRepo{
+		getUserFn: func(_ context.Context, userID string) (*models.UserWithTeam, error) {
+			if userID != "u1" {
+				return nil, storage.ErrUserNotFound
+			}
+			return &models.UserWithTeam{User: models.User{ID: userID}}, nil
+		},
+	}
+	service, err := NewPRService(fakeTxManager{}, repo, userRepo, &fakePRTeamRepo{}, testLogger())
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	resp, err := service.GetAgenda(context.Background(), " u1 ")
+	if err != nil {
+		t.Fatalf("GetAgenda returned error: %v", err)
+	}
+	var got []string
+	for _, item := range resp.Items {
+		got = append(got, item.PullRequestID+":"+item.Kind)
+	}
+	want := []string{"late:OVERDUE", "acked:RE_REVIEW", "fresh:NEW"}
+	if !slices.Equal(got, want) {
+		t.Fatalf("expected agenda %v, got %v", want, got)
+	}
+
+	if _, err := service.GetAgenda(context.Background(), "ghost"); !errors.Is(err, ErrUserNotFound) {
+		t.Fatalf("expected ErrUserNotFound, got %v", err)
+	}
+	if _, err := service.GetAgenda(context.Background(), " "); !errors.Is(err, ErrPRValidation) {
+		t.Fatalf("expected ErrPRValidation, got %v", err)
+	}
+}
+
+func TestPRService_CalendarFeed(t *testing.T) {
+	assigned := time.Date(2026, 3, 1, 10, 0, 0, 0, time.UTC)
+	due := assigned.Add(24 * time.Hour)
+	repo := &fakePRRepo{
+		getAgendaFn: func(context.Context, string, int) ([]*models.AgendaItem, error) {
+			return []*models.AgendaItem{
+				{PullRequestID: "acked", Title: "Old", AssignedAt: assigned, AcknowledgedAt: &assigned},
+				{PullRequestID: "pr1", Title: "Fix login", AuthorID: "u2", AssignedAt: assigned, DueAt: &due},
+			}, nil
+		},
+	}
+	userRepo := &fakePRUserRepo{
+		getUserFn: func(_ context.Context, userID string) (*models.UserWithTeam, error) {
+			if userID != "u1" {
+				return nil, storage.ErrUserNotFound
+			}
+			return &models.UserWithTeam{User: models.User{ID: userID}}, nil
+		},
+	}
+	service, err := NewPRService(fakeTxManager{}, repo, userRepo, &fakePRTeamRepo{}, testLogger())
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	feed, err := service.CalendarFeed(context.Background(), "u1")
+	if err != nil {
+		t.Fatalf("CalendarFeed returned error: %v", err)
+	}
+	if len(feed.Events) != 1 {
+		t.Fatalf("expected one deadline, got %d", len(feed.Events))
+	}
+	if e := feed.Events[0]; e.Summary != "Review due: Fix login" || !e.Start.Equal(due) || !e.End.Equal(due.Add(calendarEventLength)) {
+		t.Fatalf("unexpected event: %+v", e)
+	}
+
+	if _, err := service.CalendarFeed(context.Background(), "ghost"); !errors.Is(err, ErrUserNotFound) {
+		t.Fatalf("expected ErrUserNotFound, got %v", err)
+	}
+}
+
+func TestPRService_ReassignOpenReviews(t *testing.T) {
+	var batches [][]string
+	repo := &fakePRRepo{
+		getReviewerPRsFn: func(context.Context, string) ([]*models.PullRequestShort, error) {
+			return []*models.PullRequestShort{
+				{ID: "pr1", Status: models.StatusOpen},
+				{ID: "pr2", Status: models.StatusMerged},
+				{ID: "pr3", Status: models.StatusOpen},
+			}, nil
+		},
+		getPRsByIDsFn: func(_ context.Context, prIDs []string) ([]*models.PullRequest, error) {
+			batches = append(batches, prIDs)
+			prs := make([]*models.PullRequest, 0, len(prIDs))
+			for _, id := range prIDs {
+				prs = append(prs, &models.PullRequest{ID: id, AuthorID: "author", Status: models.StatusOpen, Reviewers: []string{"u1", "u3"}})
+			}
+			return prs, nil
+		},
+		replaceReviewerFn: func(context.Context, string, string, string) error { return nil },
+	}
+	calls := 0
+	userRepo := &fakePRUserRepo{
+		getUserFn: func(_ context.Context, userID string) (*models.UserWithTeam, error) {
+			return &models.UserWithTeam{User: models.User{ID: userID}, TeamName: "backend"}, nil
+		},
+		getRandomMateFn: func(context.Context, string, string, []string) (*models.User, error) {
+			calls++
+			if calls == 2 {
+				return nil, storage.ErrNoCandidate
+			}
+			return &models.User{ID: "u2"}, nil
+		},
+	}
+	service, err := NewPRService(fakeTxManager{}, repo, userRepo, &fakePRTeamRepo{}, testLogger())
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	results, err := service.ReassignOpenReviews(context.Background(), "u1", "reviewer deactivated")
+	if err != nil {
+		t.Fatalf("ReassignOpenReviews returned error: %v", err)
+	}
+	if len(results) != 2 {
+		t.Fatalf("expected open PRs only, got %+v", results)
+	}
+	if len(batches) != 1 || !slices.Equal(batches[0], []string{"pr1", "pr3"}) {
+		t.Fatalf("expected open PRs to be loaded in one batch, got %v", batches)
+	}
+	if got := results[0]; got.Outcome != models.ReassignOutcomeReassigned || got.ReplacedBy != "u2" || got.PR == nil || got.PR.Reviewers[0] != "u2" {
+		t.Fatalf("unexpected first result: %+v", got)
+	}
+	if got := results[1]; got.PullRequestID != "pr3" || got.Outcome != models.ReassignOutcomeFailed || !errors.Is(got.Err, ErrNoReplacement) {
+		t.Fatalf("unexpected second result: %+v", got)
+	}
+}
+
+func TestPRService_BackfillReviewers(t *testing.T) {
+	prs := map[string]*models.PullRequest{
+		"pr1": {ID: "pr1", AuthorID: "author", Status: models.StatusOpen},
+		"pr2": {ID: "pr2", AuthorID: "u2", Status: models.StatusOpen},
+		"pr3": {ID: "pr3", AuthorID: "u1", Status: models.StatusOpen, Reviewers: []string{"u2"}},
+	}
+	added := map[string][]string{}
+	repo := &fakePRRepo{
+		getNeedingFn: func(_ context.Context, team string, min int) ([]*models.PRNeedingReviewers, error) {
+			if team != "backend" || min != reviewersPerPR {
+				t.Fatalf("unexpected args %q %d", team, min)
+			}
+			return []*models.PRNeedingReviewers{{ID: "pr1"}, {ID: "pr2"}, {ID: "pr3"}}, nil
+		},
+		getPRFn: func(_ context.Context, prID string) (*models.PullRequest, error) {
+			return prs[prID], nil
+		},
+		addReviewersFn: func(_ context.Context, prID string, ids []string) error {
+			added[prID] = ids
+			return nil
+		},
+	}
+	userRepo := &fakePRUserRepo{
+		getUserFn: func(_ context.Context, userID string) (*models.UserWithTeam, error) {
+			return &models.UserWithTeam{User: models.User{ID: userID}, TeamName: "backend"}, nil
+		},
+		getRandomMateFn: func(_ context.Context, _, _ string, exclude []string) (*models.User, error) {
+			for _, id := range []string{"u1", "u2"} {
+				if !slices.Contains(exclude, id) {
+					return &models.User{ID: id}, nil
+				}
+			}
+			return nil, storage.ErrNoCandidate
+		},
+	}
+	service, err := NewPRService(fakeTxManager{}, repo, userRepo, &fakePRTeamRepo{}, testLogger())
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	resp, err := service.BackfillReviewers(context.Background(), &models.BackfillReviewersRequest{TeamName: " backend "})
+	if err != nil {
+		t.Fatalf("BackfillReviewers returned error: %v", err)
+	}
+	if resp.Filled != 1 || resp.Partial != 1 || resp.Failed != 1 {
+		t.Fatalf("unexpected counts %+v", resp)
+	}
+	if got := resp.Results[0]; got.Outcome != models.BackfillOutcomeFilled || !slices.Equal(added["pr1"], []string{"u1", "u2"}) {
+		t.Fatalf("unexpected pr1 result %+v", got)
+	}
+	if got := resp.Results[1]; got.Outcome != models.BackfillOutcomePartial || !slices.Equal(got.Added, []string{"u1"}) {
+		t.Fatalf("unexpected pr2 result %+v", got)
+	}
+	if got := resp.Results[2]; got.Outcome != models.BackfillOutcomeFailed || !errors.Is(got.Err, ErrNoReplacement) {
+		t.Fatalf("unexpected pr3 result %+v", got)
+	}
+}
+
+func TestPRService_ReassignAll_Validation(t *testing.T) {
+	service, err := NewPRService(fakeTxManager{}, &fakePRRepo{}, &fakePRUserRepo{}, &fakePRTeamRepo{}, testLogger())
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if _, err := service.ReassignAll(context.Background(), &models.PRReassignAllRequest{}); !errors.Is(err, ErrPRValidation) {
+		t.Fatalf("expected ErrPRValidation, got %v", err)
+	}
+}
+
+func TestPRService_GetAssignmentsStats_Success(t *testing.T) {
+	repo := &fakePRRepo{
+		getStatsFn: func(context.Context) (*models.AssignmentsStatsResponse, error) {
+			return &models.AssignmentsStatsResponse{}, nil
+		},
+	}
+	userRepo := &fakePRUserRepo{}
+	service, err := NewPRService(fakeTxManager{}, repo, userRepo, &fakePRTeamRepo{}, testLogger())
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	stats, err := service.GetAssignmentsStats(context.Background())
+	if err != nil {
+		t.Fatalf("GetAssignmentsStats returned error: %v", err)
+	}
+	if stats.ByUser == nil || stats.ByPR == nil {
+		t.Fatalf("expected slices to be initialized")
+	}
+}
+
+func TestPRService_GetAssignmentsStats_Error(t *testing.T) {
+	repo := &fakePRRepo{
+		getStatsFn: func(context.Context) (*models.AssignmentsStatsResponse, error) {
+			return nil, errors.New("db error")
+		},
+	}
+	userRepo := &fakePRUserRepo{}
+	service, err := NewPRService(fakeTxManager{}, repo, userRepo, &fakePRTeamRepo{}, testLogger())
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	_, err = service.GetAssignmentsStats(context.Background())
+	if err == nil {
+		t.Fatalf("expected error")
+	}
+}
+
+func TestPRService_ReassignReviewer_NoCandidate(t *testing.T) {
+	repo := &fakePRRepo{
+		getPRFn: func(_ context.Context, _ string) (*models.PullRequest, error) {
+			return &models.PullRequest{ID: "pr", Status: models.StatusOpen, Reviewers: []string{"u2"}}, nil
+		},
+		replaceReviewerFn: func(context.Context, string, string, string) error {
+			return nil
+		},
+	}
+	userRepo := &fakePRUserRepo{
+		getUserFn: func(_ context.Context, _ string) (*models.UserWithTeam, error) {
+			return &models.UserWithTeam{TeamName: "backend"}, nil
+		},
+		getRandomMateFn: func(context.Context, string, string, []string) (*models.User, error) {
+			return nil, storage.ErrNoCandidate
+		},
+	}
+	service, err := NewPRService(fakeTxManager{}, repo, userRepo, &fakePRTeamRepo{}, testLogger())
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	_, err = service.ReassignReviewer(context.Background(), &models.PRReassignRequest{ID: "pr", OldReviewerID: "u2"})
+	if !errors.Is(err, ErrNoReplacement) {
+		t.Fatalf("expected ErrNoReplacement, got %v", err)
+	}
+}
+
+func newDuplicateCheckService(t *testing.T, strict bool) *PRService {
+	t.Helper()
+	repo := &fakePRRepo{
+		createPRFn: func(_ context.Context, pr models.PullRequest) (*models.PullRequest, error) {
+			return &pr, nil
+		},
+		addReviewersFn: func(context.Context, string, []string) error { return nil },
+		getAuthorOpenFn: func(context.Context, string) ([]*models.PullRequestShort, error) {
+			return []*models.PullRequestShort{
+				{ID: "pr-old", Title: "Add search endpoint", Status: models.StatusOpen},
+				{ID: "pr-other", Title: "Refactor storage", Status: models.StatusOpen},
+			}, nil
+		},
+	}
+	userRepo := &fakePRUserRepo{
+		getUserFn: func(_ context.Context, userID string) (*models.UserWithTeam, error) {
+			return &models.UserWithTeam{User: models.User{ID: userID}, TeamName: "backend"}, nil
+		},
+		getTeammatesFn: func(context.Context, string, string, int) ([]*models.User, error) {
+			return []*models.User{{ID: "u2"}}, nil
+		},
+	}
+	teamRepo := &fakePRTeamRepo{
+		getSettingsFn: func(_ context.Context, teamName string) (*models.TeamSettings, error) {
+			return &models.TeamSettings{TeamName: teamName, StrictDuplicateCheck: strict}, nil
+		},
+	}
+	service, err := NewPRService(fakeTxManager{}, repo, userRepo, teamRepo, testLogger(), WithDuplicateCheck(0.8))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	return service
+}
+
+func TestPRService_CreatePR_DuplicateWarning(t *testing.T) {
+	service := newDuplicateCheckService(t, false)
+	resp, err := service.CreatePR(context.Background(), &models.PRCreateRequest{
+		ID:       "pr-new",
+		Title:    "add search endpoint!",
+		AuthorID: "u1",
+	})
+	if err != nil {
+		t.Fatalf("CreatePR returned error: %v", err)
+	}
+	if len(resp.Warnings) != 1 {
+		t.Fatalf("expected 1 duplicate warning, got %v", resp.Warnings)
+	}
+}
+
+func TestPRService_CreatePR_DuplicateStrict(t *testing.T) {
+	service := newDuplicateCheckService(t, true)
+	_, err := service.CreatePR(context.Background(), &models.PRCreateRequest{
+		ID:       "pr-new",
+		Title:    "Add search endpoints",
+		AuthorID: "u1",
+	})
+	if !errors.Is(err, ErrPRDuplicate) {
+		t.Fatalf("expected ErrPRDuplicate, got %v", err)
+	}
+}
+
+func TestPRService_CreatePR_NoDuplicate(t *testing.T) {
+	service := newDuplicateCheckService(t, true)
+	resp, err := service.CreatePR(context.Background(), &models.PRCreateRequest{
+		ID:       "pr-new",
+		Title:    "Fix login redirect",
+		AuthorID: "u1",
+	})
+	if err != nil {
+		t.Fatalf("CreatePR returned error: %v", err)
+	}
+	if len(resp.Warnings) != 0 {
+		t.Fatalf("expected no warnings, got %v", resp.Warnings)
+	}
+}
+
+func TestTitleSimilarity(t *testing.T) {
+	cases := []struct {
+		a, b string
+		min  float64
+		max  float64
+	}{
+		{a: "Add search", b: "add   SEARCH", min: 1, max: 1},
+		{a: "Add search endpoint", b: "Add search endpoints", min: 0.9, max: 1},
+		{a: "Add search", b: "Fix login redirect", min: 0, max: 0.5},
+	}
+	for _, tc := range cases {
+		got := titleSimilarity(tc.a, tc.b)
+		if got < tc.min || got > tc.max {
+			t.Fatalf("titleSimilarity(%q, %q) = %f, want in [%f, %f]", tc.a, tc.b, got, tc.min, tc.max)
+		}
+	}
+}
+
+func TestPRService_AwaitAssignment_ReceivesNewAssignment(t *testing.T) {
+	repo := &fakePRRepo{
+		createPRFn: func(_ context.Context, pr models.PullRequest) (*models.PullRequest, error) {
+			return &pr, nil
+		},
+		addReviewersFn: func(context.Context, string, []string) error { return nil },
+	}
+	userRepo := &fakePRUserRepo{
+		getUserFn: func(_ context.Context, userID string) (*models.UserWithTeam, error) {
+			return &models.UserWithTeam{User: models.User{ID: userID}, TeamName: "backend"}, nil
+		},
+		getTeammatesFn: func(context.Context, string, string, int) ([]*models.User, error) {
+			return []*models.User{{ID: "u2"}}, nil
+		},
+	}
+	assignments := hub.NewAssignmentHub()
+	service, err := NewPRService(fakeTxManager{}, repo, userRepo, &fakePRTeamRepo{}, testLogger(), WithAssignmentNotifier(assignments))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	result := make(chan *models.AwaitAssignmentResponse, 1)
+	go func() {
+		resp, err := service.AwaitAssignment(context.Background(), "u2", 5*time.Second)
+		if err != nil {
+			t.Errorf("AwaitAssignment returned error: %v", err)
+		}
+		result <- resp
+	}()
+
+	deadline := time.Now().Add(time.Second)
+	for assignments.Subscribers("u2") == 0 {
+		if time.Now().After(deadline) {
+			t.Fatalf("subscriber was not registered")
+		}
+		time.Sleep(time.Millisecond)
+	}
+	if _, err := service.CreatePR(context.Background(), &models.PRCreateRequest{ID: "pr-1", Title: "t", AuthorID: "u1"}); err != nil {
+		t.Fatalf("CreatePR returned error: %v", err)
+	}
+
+	resp := <-result
+	if resp == nil || resp.TimedOut || resp.Assignment == nil || resp.Assignment.PullRequestID != "pr-1" {
+		t.Fatalf("unexpected await response: %#v", resp)
+	}
+}
+
+func TestPRService_AwaitAssignment_TimesOut(t *testing.T) {
+	userRepo := &fakePRUserRepo{
+		getUserFn: func(_ context.Context, userID string) (*models.UserWithTeam, error) {
+			return &models.UserWithTeam{User: models.User{ID: userID}}, nil
+		},
+	}
+	service, err := NewPRService(fakeTxManager{}, &fakePRRepo{}, userRepo, &fakePRTeamRepo{}, testLogger())
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	resp, err := service.AwaitAssignment(context.Background(), "u1", 10*time.Millisecond)
+	if err != nil {
+		t.Fatalf("AwaitAssignment returned error: %v", err)
+	}
+	if !resp.TimedOut || resp.Assignment != nil {
+		t.Fatalf("expected timeout response, got %#v", resp)
+	}
+}
+
+func TestPRService_AcknowledgeReview_RecordsEvent(t *testing.T) {
+	var recorded []string
+	repo := &fakePRRepo{
+		getPRFn: func(_ context.Context, prID string) (*models.PullRequest, error) {
+			return &models.PullRequest{
+				ID:              prID,
+				Status:          models.StatusOpen,
+				Reviewers:       []string{"u2"},
+				ReviewerDetails: []*models.ReviewerDetail{{UserID: "u2", State: models.ReviewerStateAssigned}},
+			}, nil
+		},
+		acknowledgeFn: func(context.Context, string, string, time.Time) (bool, error) {
+			return true, nil
+		},
+		addEventsFn: func(_ context.Context, _ string, userIDs []string, event, _ string) error {
+			recorded = append(recorded, event+":"+userIDs[0])
+			return nil
+		},
+	}
+	service, err := NewPRService(fakeTxManager{}, repo, &fakePRUserRepo{}, &fakePRTeamRepo{}, testLogger())
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	pr, err := service.AcknowledgeReview(context.Background(), &models.PRAcknowledgeRequest{ID: "pr", UserID: " u2 "})
+	if err != nil {
+		t.Fatalf("AcknowledgeReview returned error: %v", err)
+	}
+	if pr.ID != "pr" {
+		t.Fatalf("unexpected pr: %#v", pr)
+	}
+	if detail := pr.ReviewerDetails[0]; detail.State != models.ReviewerStateAcknowledged || detail.AcknowledgedAt == nil {
+		t.Fatalf("expected reviewer to be acknowledged, got %#v", detail)
+	}
+	if len(recorded) != 1 || recorded[0] != models.EventAcknowledged+":u2" {
+		t.Fatalf("unexpected recorded events: %v", recorded)
+	}
+}
+
+func TestPRService_ApproveReview(t *testing.T) {
+	var recorded []string
+	var savedState string
+	repo := &fakePRRepo{
+		getPRFn: func(_ context.Context, prID string) (*models.PullRequest, error) {
+			status := models.StatusOpen
+			if prID == "merged" {
+				status = models.StatusMerged
+			}
+			return &models.PullRequest{
+				ID:              prID,
+				Status:          status,
+				Reviewers:       []string{"u2"},
+				ReviewerDetails: []*models.ReviewerDetail{{UserID: "u2", State: models.ReviewerStateAssigned, ReviewState: models.ReviewStatePending}},
+			}, nil
+		},
+		setReviewStateFn: func(_ context.Context, _, userID, state string, _ time.Time) (bool, error) {
+			savedState = userID + ":" + state
+			return true, nil
+		},
+		addEventsFn: func(_ context.Context, _ string, userIDs []string, event, _ string) error {
+			recorded = append(recorded, event+":"+userIDs[0])
+			return nil
+		},
+	}
+	service, err := NewPRService(fakeTxManager{}, repo, &fakePRUserRepo{}, &fakePRTeamRepo{}, testLogger())
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	pr, err := service.ApproveReview(context.Background(), &models.PRApproveRequest{ID: "pr", UserID: "u2", State: "changes_requested"})
+	if err != nil {
+		t.Fatalf("ApproveReview returned error: %v", err)
+	}
+	detail := pr.ReviewerDetails[0]
+	if detail.ReviewState != models.ReviewStateChangesRequested || detail.ReviewedAt == nil || detail.State != models.ReviewerStateAcknowledged {
+		t.Fatalf("unexpected reviewer detail: %#v", detail)
+	}
+	if savedState != "u2:"+models.ReviewStateChangesRequested {
+		t.Fatalf("unexpected saved state %q", savedState)
+	}
+	if !slices.Equal(recorded, []string{models.EventAcknowledged + ":u2", models.EventChangesRequested + ":u2"}) {
+		t.Fatalf("unexpected recorded events: %v", recorded)
+	}
+
+	if _, err := service.ApproveReview(context.Background(), &models.PRApproveRequest{ID: "pr", UserID: "u2"}); err != nil || savedState != "u2:"+models.ReviewStateApproved {
+		t.Fatalf("expected APPROVED by default, got %q %v", savedState, err)
+	}
+	for _, tc := range []struct {
+		req  models.PRApproveRequest
+		want error
+	}{
+		{models.PRApproveRequest{ID: "pr", UserID: "u2", State: "LGTM"}, ErrPRValidation},
+		{models.PRApproveRequest{ID: "pr", UserID: "u9"}, ErrReviewerNotAssigned},
+		{models.PRApproveRequest{ID: "merged", UserID: "u2"}, ErrPRMerged},
+	} {
+		if _, err := service.ApproveReview(context.Background(), &tc.req); !errors.Is(err, tc.want) {
+			t.Fatalf("%+v: expected %v, got %v", tc.req, tc.want, err)
+		}
+	}
+}
+
+func TestPRService_AcknowledgeReview_NotAssigned(t *testing.T) {
+	repo := &fakePRRepo{
+		getPRFn: func(_ context.Context, prID string) (*models.PullRequest, error) {
+			return &models.PullRequest{ID: prID, Status: models.StatusOpen, Reviewers: []string{"u2"}}, nil
+		},
+	}
+	service, err := NewPRService(fakeTxManager{}, repo, &fakePRUserRepo{}, &fakePRTeamRepo{}, testLogger())
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	_, err = service.AcknowledgeReview(context.Background(), &models.PRAcknowledgeRequest{ID: "pr", UserID: "u3"})
+	if !errors.Is(err, ErrReviewerNotAssigned) {
+		t.Fatalf("expected ErrReviewerNotAssigned, got %v", err)
+	}
+}
+
+func TestPRService_AcknowledgeReview_RejectsFinishedPR(t *testing.T) {
+	repo := &fakePRRepo{
+		getPRFn: func(_ context.Context, prID string) (*models.PullRequest, error) {
+			status := models.StatusMerged
+			if prID == "closed" {
+				status = models.StatusClosed
+			}
+			return &models.PullRequest{ID: prID, Status: status, Reviewers: []string{"u2"}}, nil
+		},
+		acknowledgeFn: func(context.Context, string, string, time.Time) (bool, error) {
+			t.Fatal("acknowledge must not be recorded for a finished PR")
+			return false, nil
+		},
+	}
+	service, err := NewPRService(fakeTxManager{}, repo, &fakePRUserRepo{}, &fakePRTeamRepo{}, testLogger())
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	for id, want := range map[string]error{"merged": ErrPRMerged, "closed": ErrPRClosed} {
+		_, err := service.AcknowledgeReview(context.Background(), &models.PRAcknowledgeRequest{ID: id, UserID: "u2"})
+		if !errors.Is(err, want) {
+			t.Fatalf("%s: expected %v, got %v", id, want, err)
+		}
+	}
+}
+
+func TestPRService_ReassignExpiredAcknowledgements(t *testing.T) {
+	var recorded []string
+	replaced := map[string]string{}
+	repo := &fakePRRepo{
+		getExpiredFn: func(context.Context, time.Time) ([]*models.ReviewerAssignment, error) {
+			return []*models.ReviewerAssignment{
+				{PullRequestID: "pr-1", UserID: "u2", AssignedAt: time.Now().Add(-48 * time.Hour)},
+				{PullRequestID: "pr-merged", UserID: "u2", AssignedAt: time.Now().Add(-48 * time.Hour)},
+			}, nil
+		},
+		getPRFn: func(_ context.Context, prID string) (*models.PullRequest, error) {
+			status := models.StatusOpen
+			if prID == "pr-merged" {
+				status = models.StatusMerged
+			}
+			return &models.PullRequest{ID: prID, AuthorID: "u1", Status: status, Reviewers: []string{"u2", "u3"}}, nil
+		},
+		replaceReviewerFn: func(_ context.Context, prID, oldID, newID string) error {
+			replaced[prID] = oldID + "->" + newID
+			return nil
+		},
+		addEventsFn: func(_ context.Context, _ string, userIDs []string, event, _ string) error {
+			recorded = append(recorded, event+":"+userIDs[0])
+			return nil
+		},
+	}
+	userRepo := &fakePRUserRepo{
+		getUserFn: func(_ context.Context, userID string) (*models.UserWithTeam, error) {
+			return &models.UserWithTeam{User: models.User{ID: userID}, TeamName: "backend"}, nil
+		},
+		getRandomMateFn: func(_ context.Context, _, _ string, exclude []string) (*models.User, error) {
+			for _, id := range []string{"u1", "u2", "u3"} {
+				if !slices.Contains(exclude, id) {
+					t.Fatalf("expected %s to be excluded, got %v", id, exclude)
+				}
+			}
+			return &models.User{ID: "u4"}, nil
+		},
+	}
+	service, err := NewPRService(fakeTxManager{}, repo, userRepo, &fakePRTeamRepo{}, testLogger())
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	count, err := service.ReassignExpiredAcknowledgements(context.Background())
+	if err != nil {
+		t.Fatalf("ReassignExpiredAcknowledgements returned error: %v", err)
+	}
+	if count != 1 {
+		t.Fatalf("expected 1 reassignment, got %d", count)
+	}
+	if replaced["pr-1"] != "u2->u4" || len(replaced) != 1 {
+		t.Fatalf("unexpected replacements: %v", replaced)
+	}
+	want := []string{models.EventAckTimeout + ":u2", models.EventAssigned + ":u4"}
+	if !slices.Equal(recorded, want) {
+		t.Fatalf("expected events %v, got %v", want, recorded)
+	}
+}
+
+func TestPRService_ReassignExpiredAcknowledgements_NoCandidate(t *testing.T) {
+	repo := &fakePRRepo{
+		getExpiredFn: func(context.Context, time.Time) ([]*models.ReviewerAssignment, error) {
+			return []*models.ReviewerAssignment{{PullRequestID: "pr-1", UserID: "u2", AssignedAt: time.Now().Add(-48 * time.Hour)}}, nil
+		},
+		getPRFn: func(_ context.Context, prID string) (*models.PullRequest, error) {
+			return &models.PullRequest{ID: prID, AuthorID: "u1", Status: models.StatusOpen, Reviewers: []string{"u2"}}, nil
+		},
+	}
+	userRepo := &fakePRUserRepo{
+		getUserFn: func(_ context.Context, userID string) (*models.UserWithTeam, error) {
+			return &models.UserWithTeam{User: models.User{ID: userID}, TeamName: "backend"}, nil
+		},
+		getRandomMateFn: func(context.Context, string, string, []string) (*models.User, error) {
+			return nil, storage.ErrNoCandidate
+		},
+	}
+	var logs strings.Builder
+	log := slog.New(slog.NewTextHandler(&logs, nil))
+	service, err := NewPRService(fakeTxManager{}, repo, userRepo, &fakePRTeamRepo{}, log)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	before := ackReassignSkipped.Value()
+	for range 3 {
+		if count, err := service.ReassignExpiredAcknowledgements(context.Background()); err != nil || count != 0 {
+			t.Fatalf("expected no reassignment and no error, got %d, %v", count, err)
+		}
+	}
+	if got := ackReassignSkipped.Value() - before; got != 3 {
+		t.Fatalf("expected 3 skipped reassignments to be counted, got %d", got)
+	}
+	if logs.Len() != 0 {
+		t.Fatalf("expected a missing replacement not to be logged at info or above, got %q", logs.String())
+	}
+}
+
+func TestPRService_GetAssignmentHistory_DefaultsAndClamp(t *testing.T) {
+	var got models.AssignmentHistoryQuery
+	repo := &fakePRRepo{
+		getHistoryFn: func(_ context.Context, q models.AssignmentHistoryQuery) ([]*models.AssignmentHistoryItem, int, error) {
+			got = q
+			return nil, 0, nil
+		},
+	}
+	userRepo := &fakePRUserRepo{
+		getUserFn: func(_ context.Context, userID string) (*models.UserWithTeam, error) {
+			return &models.UserWithTeam{User: models.User{ID: userID}}, nil
+		},
+	}
+	service, err := NewPRService(fakeTxManager{}, repo, userRepo, &fakePRTeamRepo{}, testLogger())
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	resp, err := service.GetAssignmentHistory(context.Background(), models.AssignmentHistoryQuery{UserID: " u1 ", Limit: 1000, Offset: 5})
+	if err != nil {
+		t.Fatalf("GetAssignmentHistory returned error: %v", err)
+	}
+	if got.UserID != "u1" || got.Limit != maxHistoryLimit || got.Offset != 5 {
+		t.Fatalf("unexpected query: %#v", got)
+	}
+	if resp.Assignments == nil || resp.Limit != maxHistoryLimit {
+		t.Fatalf("unexpected response: %#v", resp)
+	}
+}
+
+func TestPRService_AddReviewer(t *testing.T) {
+	users := map[string]*models.UserWithTeam{
+		"author":    {User: models.User{ID: "author", IsActive: true}, TeamName: "backend"},
+		"u1":        {User: models.User{ID: "u1", IsActive: true}, TeamName: "backend"},
+		"volunteer": {User: models.User{ID: "volunteer", Username: "vera", IsActive: true}, TeamName: "mobile"},
+		"idle":      {User: models.User{ID: "idle", IsActive: false}, TeamName: "backend"},
+		"blocked":   {User: models.User{ID: "blocked", IsActive: true}, TeamName: "backend"},
+	}
+	var added, events []string
+	repo := &fakePRRepo{
+		getPRFn: func(_ context.Context, prID string) (*models.PullRequest, error) {
+			switch prID {
+			case "merged":
+				return &models.PullRequest{ID: prID, Status: models.StatusMerged}, nil
+			case "pr1":
+				return &models.PullRequest{ID: prID, AuthorID: "author", Status: models.StatusOpen, Reviewers: []string{"u1"}}, nil
+			}
+			return nil, storage.ErrPRNotFound
+		},
+		addReviewersFn: func(_ context.Context, _ string, ids []string) error {
+			added = append(added, ids...)
+			return nil
+		},
+		addEventsFn: func(_ context.Context, _ string, ids []string, event, reason string) error {
+			events = append(events, ids[0]+":"+event+":"+reason)
+			return nil
+		},
+	}
+	userRepo := &fakePRUserRepo{
+		getUserFn: func(_ context.Context, userID string) (*models.UserWithTeam, error) {
+			if u, ok := users[userID]; ok {
+				return u, nil
+			}
+			return nil, storage.ErrUserNotFound
+		},
+		excluded: map[string][]string{"author": {"blocked"}},
+	}
+	service, err := NewPRService(fakeTxManager{}, repo, userRepo, &fakePRTeamRepo{}, testLogger())
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	ctx := context.Background()
+
+	pr, err := service.AddReviewer(ctx, &models.PRAddReviewerRequest{ID: "pr1", UserID: "volunteer"})
+	if err != nil {
+		t.Fatalf("AddReviewer returned error: %v", err)
+	}
+	if !slices.Equal(pr.Reviewers, []string{"u1", "volunteer"}) || pr.NeedMore || pr.ReviewerDetails[0].Username != "vera" {
+		t.Fatalf("unexpected pr: %+v", pr)
+	}
+	if !slices.Equal(added, []string{"volunteer"}) || !slices.Equal(events, []string{"volunteer:ASSIGNED:manual"}) {
+		t.Fatalf("unexpected writes: added=%v events=%v", added, events)
+	}
+
+	for userID, want := range map[string]error{
+		"u1":      ErrReviewerAlreadyAssigned,
+		"author":  ErrPRValidation,
+		"idle":    ErrPRValidation,
+		"blocked": ErrPRValidation,
+		"ghost":   ErrUserNotFound,
+	} {
+		if _, err := service.AddReviewer(ctx, &models.PRAddReviewerRequest{ID: "pr1", UserID: userID}); !errors.Is(err, want) {
+			t.Fatalf("%s: expected %v, got %v", userID, want, err)
+		}
+	}
+	if _, err := service.AddReviewer(ctx, &models.PRAddReviewerRequest{ID: "merged", UserID: "volunteer"}); !errors.Is(err, ErrPRMerged) {
+		t.Fatalf("expected ErrPRMerged, got %v", err)
+	}
+}
+
+func TestPRService_RemoveReviewer(t *testing.T) {
+	var events []string
+	repo := &fakePRRepo{
+		getPRFn: func(_ context.Context, prID string) (*models.PullRequest, error) {
+			return &models.PullRequest{
+				ID:              prID,
+				AuthorID:        "author",
+				Status:          models.StatusOpen,
+				Reviewers:       []string{"u1", "u2"},
+				ReviewerDetails: []*models.ReviewerDetail{{UserID: "u1"}, {UserID: "u2"}},
+			}, nil
+		},
+		removeReviewerFn: func(context.Context, string, string) error { return nil },
+		addEventsFn: func(_ context.Context, _ string, ids []string, event, reason string) error {
+			events = append(events, ids[0]+":"+event+":"+reason)
+			return nil
+		},
+	}
+	outbox := &fakeOutboxWriter{}
+	service, err := NewPRService(fakeTxManager{}, repo, &fakePRUserRepo{}, &fakePRTeamRepo{}, testLogger(), WithOutbox(outbox))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	ctx := context.Background()
+
+	pr, err := service.RemoveReviewer(ctx, &models.PRRemoveReviewerRequest{ID: "pr1", UserID: "u1"})
+	if err != nil {
+		t.Fatalf("RemoveReviewer returned error: %v", err)
+	}
+	if !slices.Equal(pr.Reviewers, []string{"u2"}) || len(pr.ReviewerDetails) != 1 || !pr.NeedMore {
+		t.Fatalf("unexpected pr: %+v", pr)
+	}
+	if !slices.Equal(events, []string{"u1:REMOVED:" + defaultRemoveReason}) {
+		t.Fatalf("unexpected events: %v", events)
+	}
+	if !slices.Equal(outbox.topics, []string{models.TopicPRReviewerRemoved}) {
+		t.Fatalf("unexpected outbox events: %v", outbox.topics)
+	}
+	if _, err := service.RemoveReviewer(ctx, &models.PRRemoveReviewerRequest{ID: "pr1", UserID: "u3"}); !errors.Is(err, ErrReviewerNotAssigned) {
+		t.Fatalf("expected ErrReviewerNotAssigned, got %v", err)
+	}
+	if _, err := service.RemoveReviewer(ctx, &models.PRRemoveReviewerRequest{ID: "pr1"}); !errors.Is(err, ErrPRValidation) {
+		t.Fatalf("expected validation error, got %v", err)
+	}
+}
+
+func TestPRService_UpdatePR(t *testing.T) {
+	prs := map[string]*models.PullRequest{
+		"open":   {ID: "open", Title: "old", Description: "keep", Status: models.StatusOpen, Reviewers: []string{"u2"}},
+		"merged": {ID: "merged", Status: models.StatusMerged},
+		"racing": {ID: "racing", Status: models.StatusOpen},
+	}
+	var labelsSet []string
+	repo := &fakePRRepo{
+		getPRFn: func(_ context.Context, prID string) (*models.PullRequest, error) {
+			pr, ok := prs[prID]
+			if !ok {
+				return nil, storage.ErrPRNotFound
+			}
+			return pr, nil
+		},
+		updateDetailsFn: func(_ context.Context, prID, title, description string) error {
+			if prID == "racing" {
+				prs["racing"] = &models.PullRequest{ID: "racing", Status: models.StatusClosed}
+				return storage.ErrPRNotOpen
+			}
+			if title != "new" || description != "keep" {
+				t.Fatalf("unexpected details %q %q", title, description)
+			}
+			return nil
+		},
+		setLabelsFn: func(_ context.Context, _ string, labels []string) error {
+			labelsSet = labels
+			return nil
+		},
+	}
+	service, err := NewPRService(fakeTxManager{}, repo, &fakePRUserRepo{}, &fakePRTeamRepo{}, testLogger())
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	ctx := context.Background()
+	title := " new "
+	labels := []string{"urgent", " backend ", "", "urgent"}
+
+	pr, err := service.UpdatePR(ctx, &models.PRUpdateRequest{ID: "open", Title: &title, Labels: &labels})
+	if err != nil {
+		t.Fatalf("UpdatePR returned error: %v", err)
+	}
+	if pr.Title != "new" || pr.Description != "keep" || !slices.Equal(pr.Labels, []string{"backend", "urgent"}) || !slices.Equal(labelsSet, pr.Labels) {
+		t.Fatalf("unexpected updated pr: %+v", pr)
+	}
+
+	if _, err := service.UpdatePR(ctx, &models.PRUpdateRequest{ID: "merged", Title: &title}); !errors.Is(err, ErrPRMerged) {
+		t.Fatalf("expected ErrPRMerged, got %v", err)
+	}
+	if _, err := service.UpdatePR(ctx, &models.PRUpdateRequest{ID: "racing", Title: &title}); !errors.Is(err, ErrPRClosed) {
+		t.Fatalf("expected ErrPRClosed for a pr closed during the update, got %v", err)
+	}
+	if _, err := service.UpdatePR(ctx, &models.PRUpdateRequest{ID: "ghost", Title: &title}); !errors.Is(err, ErrPRNotFound) {
+		t.Fatalf("expected ErrPRNotFound, got %v", err)
+	}
+
+	blank := " "
+	tooMany := make([]string, maxPRLabels+1)
+	for i := range tooMany {
+		tooMany[i] = fmt.Sprintf("l%d", i)
+	}
+	for _, req := range []*models.PRUpdateRequest{
+		{ID: "open"},
+		{Title: &title},
+		{ID: "open", Title: &blank},
+		{ID: "open", Labels: &tooMany},
+	} {
+		if _, err := service.UpdatePR(ctx, req); !errors.Is(err, ErrPRValidation) {
+			t.Fatalf("expected validation error for %+v, got %v", req, err)
+		}
+	}
+}
+
+func TestPRService_ListPRs(t *testing.T) {
+	var got models.PRListQuery
+	repo := &fakePRRepo{
+		listPRsFn: func(_ context.Context, q models.PRListQuery) ([]*models.PullRequest, int, error) {
+			got = q
+			return []*models.PullRequest{{ID: "pr1", Status: models.StatusOpen}}, 7, nil
+		},
+	}
+	service, err := NewPRService(fakeTxManager{}, repo, &fakePRUserRepo{}, &fakePRTeamRepo{}, testLogger())
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	resp, err := service.ListPRs(context.Background(), models.PRListQuery{Status: " merged ", TeamName: " backend ", Labels: []string{"urgent", " bug", ""}, Limit: 1000})
+	if err != nil {
+		t.Fatalf("ListPRs returned error: %v", err)
+	}
+	if got.Status != models.StatusMerged || got.TeamName != "backend" || got.Limit != maxHistoryLimit || !slices.Equal(got.Labels, []string{"bug", "urgent"}) {
+		t.Fatalf("unexpected query: %#v", got)
+	}
+	if resp.Total != 7 || resp.Limit != maxHistoryLimit || !resp.PullRequests[0].NeedMore {
+		t.Fatalf("unexpected response: %#v", resp)
+	}
+
+	from := time.Now()
+	to := from.Add(-time.Hour)
+	for _, q := range []models.PRListQuery{
+		{Status: "DRAFT"},
+		{CreatedFrom: &from, CreatedTo: &to},
+		{MergedFrom: &from, MergedTo: &to},
+		{Offset: -1},
+	} {
+		if _, err := service.ListPRs(context.Background(), q); !errors.Is(err, ErrPRValidation) {
+			t.Fatalf("expected validation error for %#v, got %v", q, err)
+		}
+	}
+}
+
+func TestPRService_GetAssignmentHistory_Validation(t *testing.T) {
+	service, err := NewPRService(fakeTxManager{}, &fakePRRepo{}, &fakePRUserRepo{}, &fakePRTeamRepo{}, testLogger())
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	from := time.Now()
+	to := from.Add(-time.Hour)
+	cases := []models.AssignmentHistoryQuery{
+		{},
+		{UserID: "u1", From: &from, To: &to},
+		{UserID: "u1", Limit: -1},
+	}
+	for _, q := range cases {
+		if _, err := service.GetAssignmentHistory(context.Background(), q); !errors.Is(err, ErrPRValidation) {
+			t.Fatalf("expected ErrPRValidation for %#v, got %v", q, err)
+		}
+	}
+}
+
+func TestPRService_MergePR_StatusMissing(t *testing.T) {
+	repo := &fakePRRepo{
+		getPRFn: func(context.Context, string) (*models.PullRequest, error) {
+			return &models.PullRequest{ID: "pr1", Status: models.StatusOpen}, nil
+		},
+		markMergedFn: func(context.Context, string, time.Time) error {
+			return fmt.Errorf("wrap: %w", storage.ErrStatusNotFound)
+		},
+	}
+	service, err := NewPRService(fakeTxManager{}, repo, &fakePRUserRepo{}, &fakePRTeamRepo{}, testLogger())
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	_, err = service.MergePR(context.Background(), &models.PRMergeRequest{ID: "pr1"})
+	if !errors.Is(err, ErrPRStatusMissing) {
+		t.Fatalf("expected ErrPRStatusMissing, got %v", err)
+	}
+}
+
+func TestPRService_CreatePR_GeneratesID(t *testing.T) {
+	var insertedID string
+	repo := &fakePRRepo{
+		createPRFn: func(_ context.Context, pr models.PullRequest) (*models.PullRequest, error) {
+			insertedID = pr.ID
+			return &pr, nil
+		},
+		addReviewersFn: func(context.Context, string, []string) error { return nil },
+	}
+	userRepo := &fakePRUserRepo{
+		getUserFn: func(_ context.Context, userID string) (*models.UserWithTeam, error) {
+			return &models.UserWithTeam{User: models.User{ID: userID}, TeamName: "backend"}, nil
+		},
+		getTeammatesFn: func(context.Context, string, string, int) ([]*models.User, error) {
+			return nil, nil
+		},
+	}
+	service, err := NewPRService(fakeTxManager{}, repo, userRepo, &fakePRTeamRepo{}, testLogger(), WithGeneratedPRIDs())
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	resp, err := service.CreatePR(context.Background(), &models.PRCreateRequest{Title: "Add feature", AuthorID: "u1"})
+	if err != nil {
+		t.Fatalf("CreatePR returned error: %v", err)
+	}
+	if insertedID == "" || resp.PR.ID != insertedID || insertedID[14] != '7' {
+		t.Fatalf("expected generated UUIDv7, got %q", insertedID)
+	}
+}
+
+func TestPRService_CreatePR_IDPolicy(t *testing.T) {
+	service, err := NewPRService(
+		fakeTxManager{},
+		&fakePRRepo{},
+		&fakePRUserRepo{},
+		&fakePRTeamRepo{},
+		testLogger(),
+		WithPRIDPolicy(8, regexp.MustCompile(`^[a-z0-9-]+$`)),
+	)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	for _, id := range []string{"", "pr-123456789", "PR_1"} {
+		_, err := service.CreatePR(context.Background(), &models.PRCreateRequest{ID: id, Title: "t", AuthorID: "u1"})
+		if !errors.Is(err, ErrPRValidation) {
+			t.Fatalf("expected ErrPRValidation for %q, got %v", id, err)
+		}
+	}
+}
+
+func TestNewUUIDv7(t *testing.T) {
+	now := time.UnixMilli(0x0189_0000_0001)
+	id, err := newUUIDv7(now)
+	if err != nil {
+		t.Fatalf("newUUIDv7 returned error: %v", err)
+	}
+	if !regexp.MustCompile(`^01890000-0001-7[0-9a-f]{3}-[89ab][0-9a-f]{3}-[0-9a-f]{12}$`).MatchString(id) {
+		t.Fatalf("unexpected uuid: %s", id)
+	}
+}
+
+func newTransferService(t *testing.T, replaced *[]string, opts ...PROption) *PRService {
+	t.Helper()
+	repo := &fakePRRepo{
+		getReviewerPRsFn: func(_ context.Context, userID string) ([]*models.PullRequestShort, error) {
+			switch userID {
+			case "u1":
+				return []*models.PullRequestShort{
+					{ID: "pr1", Status: models.StatusOpen},
+					{ID: "pr2", Status: models.StatusOpen},
+					{ID: "pr3", Status: models.StatusOpen},
+					{ID: "pr4", Status: models.StatusMerged},
+					{ID: "pr5", Status: models.StatusOpen, Priority: models.PriorityUrgent},
+				}, nil
+			case "u2":
+				return []*models.PullRequestShort{{ID: "pr3", Status: models.StatusOpen}}, nil
+			}
+			return nil, nil
+		},
+		getPRFn: func(_ context.Context, prID string) (*models.PullRequest, error) {
+			pr := &models.PullRequest{ID: prID, AuthorID: "author", Status: models.StatusOpen, Reviewers: []string{"u1"}}
+			switch prID {
+			case "pr2":
+				pr.AuthorID = "u2"
+			case "pr3":
+				pr.Reviewers = []string{"u1", "u2"}
+			case "pr5":
+				pr.Priority = models.PriorityUrgent
+			}
+			return pr, nil
+		},
+		replaceReviewerFn: func(_ context.Context, prID, oldID, newID string) error {
+			*replaced = append(*replaced, prID+":"+oldID+"->"+newID)
+			return nil
+		},
+	}
+	userRepo := &fakePRUserRepo{
+		getUserFn: func(_ context.Context, userID string) (*models.UserWithTeam, error) {
+			team := "backend"
+			if userID == "u9" {
+				team = "frontend"
+			}
+			return &models.UserWithTeam{User: models.User{ID: userID, IsActive: true}, TeamName: team}, nil
+		},
+	}
+	service, err := NewPRService(fakeTxManager{}, repo, userRepo, &fakePRTeamRepo{}, testLogger(), opts...)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	return service
+}
+
+func TestPRService_TransferAssignments_SkipsIneligible(t *testing.T) {
+	var replaced []string
+	service := newTransferService(t, &replaced)
+
+	resp, err := service.TransferAssignments(context.Background(), &models.TransferAssignmentsRequest{FromUserID: "u1", ToUserID: "u2"})
+	if err != nil {
+		t.Fatalf("TransferAssignments returned error: %v", err)
+	}
+	if resp.Transferred != 2 || resp.Skipped != 2 {
+		t.Fatalf("unexpected counts: %+v", resp)
+	}
+	if !slices.Equal(replaced, []string{"pr1:u1->u2", "pr5:u1->u2"}) {
+		t.Fatalf("unexpected replacements %v", replaced)
+	}
+	for _, result := range resp.Results {
+		if result.PullRequestID != "pr1" && result.PullRequestID != "pr5" && (result.Outcome != models.ReassignOutcomeSkipped || !errors.Is(result.Err, ErrPRValidation)) {
+			t.Fatalf("unexpected result %+v", result)
+		}
+	}
+}
+
+func TestPRService_TransferAssignments_Validation(t *testing.T) {
+	var replaced []string
+	service := newTransferService(t, &replaced, WithTransferCapacity(1))
+	ctx := context.Background()
+
+	if _, err := service.TransferAssignments(ctx, &models.TransferAssignmentsRequest{FromUserID: "u1", ToUserID: "u9"}); !errors.Is(err, ErrPRValidation) {
+		t.Fatalf("expected team mismatch to fail validation, got %v", err)
+	}
+	if _, err := service.TransferAssignments(ctx, &models.TransferAssignmentsRequest{FromUserID: "u1", ToUserID: "u2"}); !errors.Is(err, ErrReviewerOverloaded) {
+		t.Fatalf("expected ErrReviewerOverloaded, got %v", err)
+	}
+	if len(replaced) != 0 {
+		t.Fatalf("expected no replacements, got %v", replaced)
+	}
+	if resp, err := service.TransferAssignments(ctx, &models.TransferAssignmentsRequest{FromUserID: "u1", ToUserID: "u2", PullRequestIDs: []string{"pr5"}}); err != nil || resp.Transferred != 1 {
+		t.Fatalf("expected an urgent pull request to bypass capacity, got %+v, %v", resp, err)
+	}
+	resp, err := service.TransferAssignments(ctx, &models.TransferAssignmentsRequest{FromUserID: "u1", ToUserID: "u2", PullRequestIDs: []string{"pr1", "pr4"}, Force: true})
+	if err != nil {
+		t.Fatalf("forced transfer returned error: %v", err)
+	}
+	if resp.Transferred != 1 || resp.Skipped != 1 || !errors.Is(resp.Results[0].Err, ErrReviewerNotAssigned) {
+		t.Fatalf("unexpected forced transfer response: %+v", resp)
+	}
+}
+
+// newRebalanceService returns a service over a fresh team where u1 holds four
+// reviews: u2 authored p1 and excludes u3.
+func newRebalanceService(t *testing.T) (*PRService, map[string][]string) {
+	t.Helper()
+	reviewers := map[string][]string{"p1": {"u1"}, "p2": {"u1"}, "p3": {"u1"}, "p4": {"u1"}, "p5": {"u4"}}
+	authors := map[string]string{"p1": "u2", "p2": "a2", "p3": "a3", "p4": "a4", "p5": "a5"}
+	repo := &fakePRRepo{
+		getReviewerPRsFn: func(_ context.Context, userID string) ([]*models.PullRequestShort, error) {
+			var prs []*models.PullRequestShort
+			for _, id := range []string{"p1", "p2", "p3", "p4", "p5"} {
+				if slices.Contains(reviewers[id], userID) {
+					prs = append(prs, &models.PullRequestShort{ID: id, Status: models.StatusOpen})
+				}
+			}
+			return prs, nil
+		},
+		getPRFn: func(_ context.Context, prID string) (*models.PullRequest, error) {
+			return &models.PullRequest{ID: prID, AuthorID: authors[prID], Status: models.StatusOpen, Reviewers: slices.Clone(reviewers[prID])}, nil
+		},
+		replaceReviewerFn: func(_ context.Context, prID, oldID, newID string) error {
+			i := slices.Index(reviewers[prID], oldID)
+			if i < 0 {
+				return storage.ErrReviewerNotAssigned
+			}
+			reviewers[prID][i] = newID
+			return nil
+		},
+	}
+	userRepo := &fakePRUserRepo{
+		getTeamLoadFn: func(_ context.Context, teamName string) ([]*models.ReviewerLoad, error) {
+			if teamName != "backend" {
+				return nil, nil
+			}
+			loads := make([]*models.ReviewerLoad, 0, 4)
+			for _, id := range []string{"u1", "u2", "u3", "u4"} {
+				l := &models.ReviewerLoad{UserID: id}
+				for _, r := range reviewers {
+					if slices.Contains(r, id) {
+						l.OpenAssignments++
+					}
+				}
+				loads = append(loads, l)
+			}
+			return loads, nil
+		},
+		excluded: map[string][]string{"u2": {"u3"}},
+	}
+	service, err := NewPRService(fakeTxManager{}, repo, userRepo, &fakePRTeamRepo{}, testLogger())
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	return service, reviewers
+}
+
+func TestPRService_RebalanceAssignments(t *testing.T) {
+	previewService, _ := newRebalanceService(t)
+	preview, err := previewService.RebalanceAssignments(context.Background(), &models.RebalanceAssignmentsRequest{TeamName: "backend", Preview: true})
+	if err != nil {
+		t.Fatalf("preview returned error: %v", err)
+	}
+	if !preview.Preview || preview.ChangeSetToken == "" || len(preview.Moves) != 2 {
+		t.Fatalf("unexpected preview %+v", preview)
+	}
+
+	service, reviewers := newRebalanceService(t)
+	resp, err := service.RebalanceAssignments(context.Background(), &models.RebalanceAssignmentsRequest{TeamName: " backend ", ChangeSetToken: preview.ChangeSetToken})
+	if err != nil {
+		t.Fatalf("RebalanceAssignments returned error: %v", err)
+	}
+	var moves []string
+	for _, m := range resp.Moves {
+		moves = append(moves, m.PullRequestID+":"+m.FromUserID+"->"+m.ToUserID)
+	}
+	if !slices.Equal(moves, []string{"p2:u1->u2", "p3:u1->u3"}) || resp.Preview {
+		t.Fatalf("unexpected moves %v", moves)
+	}
+	var after []int
+	for _, l := range resp.After {
+		after = append(after, l.OpenAssignments)
+	}
+	if !slices.Equal(after, []int{2, 1, 1, 1}) || resp.Before[0].OpenAssignments != 4 {
+		t.Fatalf("unexpected loads before %+v after %v", resp.Before[0], after)
+	}
+	if reviewers["p2"][0] != "u2" || reviewers["p3"][0] != "u3" {
+		t.Fatalf("moves were not applied: %v", reviewers)
+	}
+
+	if _, err := service.RebalanceAssignments(context.Background(), &models.RebalanceAssignmentsRequest{TeamName: "backend", ChangeSetToken: preview.ChangeSetToken}); !errors.Is(err, ErrStalePreview) {
+		t.Fatalf("expected ErrStalePreview after data changed, got %v", err)
+	}
+	if _, err := service.RebalanceAssignments(context.Background(), &models.RebalanceAssignmentsRequest{TeamName: "backend"}); !errors.Is(err, ErrPRValidation) {
+		t.Fatalf("expected ErrPRValidation without token, got %v", err)
+	}
+	if _, err := service.RebalanceAssignments(context.Background(), &models.RebalanceAssignmentsRequest{TeamName: "ghost", Preview: true}); !errors.Is(err, ErrPRTeamNotFound) {
+		t.Fatalf("expected ErrPRTeamNotFound, got %v", err)
+	}
+	if _, err := service.RebalanceAssignments(context.Background(), &models.RebalanceAssignmentsRequest{}); !errors.Is(err, ErrPRValidation) {
+		t.Fatalf("expected ErrPRValidation, got %v", err)
+	}
+}
+
+func TestPRService_BatchGetPRs(t *testing.T) {
+	calls := 0
+	repo := &fakePRRepo{
+		getPRsByIDsFn: func(_ context.Context, prIDs []string) ([]*models.PullRequest, error) {
+			calls++
+			if !slices.Equal(prIDs, []string{"pr-2", "ghost", "pr-1"}) {
+				t.Fatalf("unexpected requested ids: %v", prIDs)
+			}
+			return []*models.PullRequest{
+				{ID: "pr-1", Status: models.StatusOpen, Reviewers: []string{"u2"}},
+				{ID: "pr-2", Status: models.StatusMerged, Reviewers: []string{}},
+			}, nil
+		},
+	}
+	service, err := NewPRService(fakeTxManager{}, repo, &fakePRUserRepo{}, &fakePRTeamRepo{}, testLogger())
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	resp, err := service.BatchGetPRs(context.Background(), []string{"pr-2", "ghost", "pr-1", "pr-2", ""})
+	if err != nil {
+		t.Fatalf("BatchGetPRs returned error: %v", err)
+	}
+	if calls != 1 || len(resp.PullRequests) != 2 || !slices.Equal(resp.Missing, []string{"ghost"}) {
+		t.Fatalf("unexpected response after %d calls: %+v", calls, resp)
+	}
+	if !resp.PullRequests[0].NeedMore || resp.PullRequests[1].NeedMore {
+		t.Fatalf("expected only the open pr with one reviewer to need more reviewers")
+	}
+
+	if _, err := service.BatchGetPRs(context.Background(), nil); !errors.Is(err, ErrPRValidation) {
+		t.Fatalf("expected validation error for empty ids, got %v", err)
+	}
+	tooMany := make([]string, MaxBatchGetPRs+1)
+	for i := range tooMany {
+		tooMany[i] = fmt.Sprintf("pr-%d", i)
+	}
+	if _, err := service.BatchGetPRs(context.Background(), tooMany); !errors.Is(err, ErrPRValidation) {
+		t.Fatalf("expected validation error for too many ids, got %v", err)
 	}
 }
@@ -0,0 +1,246 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/cloudyy74/pr-reviewer-service/internal/models"
+	"github.com/cloudyy74/pr-reviewer-service/internal/storage"
+)
+
+// TransferAssignments moves open reviews from one user to a chosen teammate
+// in a single transaction. PRs the target cannot take (not assigned to the
+// source, authored by the target or already reviewed by them) are skipped
+// and reported; capacity is checked for the whole batch up front, and urgent
+// pull requests do not count against it.
+func (s *PRService) TransferAssignments(ctx context.Context, req *models.TransferAssignmentsRequest) (*models.TransferAssignmentsResponse, error) {
+	if req == nil {
+		return nil, fmt.Errorf("%w: empty body", ErrPRValidation)
+	}
+	fromID, err := s.resolveUserRef(ctx, req.FromUserID)
+	if err != nil {
+		return nil, err
+	}
+	toID, err := s.resolveUserRef(ctx, req.ToUserID)
+	if err != nil {
+		return nil, err
+	}
+	if fromID == "" || toID == "" {
+		return nil, fmt.Errorf("%w: from_user_id and to_user_id are required", ErrPRValidation)
+	}
+	if fromID == toID {
+		return nil, fmt.Errorf("%w: from_user_id and to_user_id must differ", ErrPRValidation)
+	}
+
+	resp := &models.TransferAssignmentsResponse{
+		FromUserID: fromID,
+		ToUserID:   toID,
+		Results:    make([]*models.PRReassignResult, 0),
+	}
+	var transferred []*models.PullRequest
+	err = s.tx.Run(ctx, func(ctx context.Context) error {
+		from, err := s.getUser(ctx, fromID)
+		if err != nil {
+			return err
+		}
+		to, err := s.getUser(ctx, toID)
+		if err != nil {
+			return err
+		}
+		if !to.IsActive {
+			return fmt.Errorf("%w: to_user_id %s is inactive", ErrPRValidation, toID)
+		}
+		if strings.TrimSpace(from.TeamName) == "" || to.TeamName != from.TeamName {
+			return fmt.Errorf("%w: to_user_id must be a member of team %s", ErrPRValidation, from.TeamName)
+		}
+
+		fromOpen, err := s.openReviewIDs(ctx, fromID)
+		if err != nil {
+			return err
+		}
+		selected := fromOpen
+		if len(req.PullRequestIDs) > 0 {
+			selected = make([]string, 0, len(req.PullRequestIDs))
+			for _, id := range req.PullRequestIDs {
+				if id = strings.TrimSpace(id); id != "" && !slices.Contains(selected, id) {
+					selected = append(selected, id)
+				}
+			}
+		}
+
+		loaded, err := s.getPRsByID(ctx, slices.DeleteFunc(slices.Clone(selected), func(id string) bool {
+			return !slices.Contains(fromOpen, id)
+		}))
+		if err != nil {
+			return err
+		}
+		prs := make([]*models.PullRequest, 0, len(selected))
+		for _, id := range selected {
+			if !slices.Contains(fromOpen, id) {
+				resp.Results = append(resp.Results, skippedTransfer(id, ErrReviewerNotAssigned))
+				continue
+			}
+			pr := loaded[id]
+			switch {
+			case pr.AuthorID == toID:
+				resp.Results = append(resp.Results, skippedTransfer(id, fmt.Errorf("%w: %s is the author", ErrPRValidation, toID)))
+			case slices.Contains(pr.Reviewers, toID):
+				resp.Results = append(resp.Results, skippedTransfer(id, fmt.Errorf("%w: %s already reviews this pull request", ErrPRValidation, toID)))
+			default:
+				prs = append(prs, pr)
+			}
+		}
+
+		regular := 0
+		for _, pr := range prs {
+			if pr.Priority != models.PriorityUrgent {
+				regular++
+			}
+		}
+		if s.transferCapacity > 0 && !req.Force && regular > 0 {
+			toOpen, err := s.openReviewIDs(ctx, toID)
+			if err != nil {
+				return err
+			}
+			if total := len(toOpen) + regular; total > s.transferCapacity {
+				return fmt.Errorf("%w: %s would have %d open reviews, capacity is %d", ErrReviewerOverloaded, toID, total, s.transferCapacity)
+			}
+		}
+
+		reason := fmt.Sprintf("transferred from %s to %s", fromID, toID)
+		for _, pr := range prs {
+			if err := s.applyReplacement(ctx, pr, fromID, &to.User, models.EventReassigned, reason); err != nil {
+				return err
+			}
+			resp.Results = append(resp.Results, &models.PRReassignResult{
+				PullRequestID: pr.ID,
+				Outcome:       models.ReassignOutcomeReassigned,
+				ReplacedBy:    toID,
+			})
+			transferred = append(transferred, pr)
+		}
+		return nil
+	})
+	if err != nil {
+		switch {
+		case errors.Is(err, ErrPRValidation),
+			errors.Is(err, ErrUserNotFound),
+			errors.Is(err, ErrReviewerNotAssigned),
+			errors.Is(err, ErrReviewerOverloaded):
+			return nil, err
+		default:
+			return nil, fmt.Errorf("transfer assignments transaction: %w", err)
+		}
+	}
+
+	resp.Transferred = len(transferred)
+	resp.Skipped = len(resp.Results) - resp.Transferred
+	for _, pr := range transferred {
+		s.publishAssignment(pr, toID)
+	}
+	return resp, nil
+}
+
+// ReassignOpenReviews replaces userID on every open pull request they
+// review, within the caller's transaction. Pull requests without an
+// available candidate keep the assignment and are reported as failed.
+func (s *PRService) ReassignOpenReviews(ctx context.Context, userID, reason string) ([]*models.PRReassignResult, error) {
+	ids, err := s.openReviewIDs(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	prs, err := s.getPRsByID(ctx, ids)
+	if err != nil {
+		return nil, err
+	}
+	results := make([]*models.PRReassignResult, 0, len(ids))
+	for _, id := range ids {
+		pr := prs[id]
+		replacementID, err := s.replaceReviewer(ctx, pr, userID, models.EventReassigned, reason)
+		if err != nil {
+			if errors.Is(err, ErrNoReplacement) || errors.Is(err, ErrPRTeamNotFound) {
+				results = append(results, &models.PRReassignResult{
+					PullRequestID: id,
+					Outcome:       models.ReassignOutcomeFailed,
+					Err:           err,
+				})
+				continue
+			}
+			return nil, err
+		}
+		setNeedMoreReviewers(pr)
+		results = append(results, &models.PRReassignResult{
+			PullRequestID: id,
+			Outcome:       models.ReassignOutcomeReassigned,
+			ReplacedBy:    replacementID,
+			PR:            pr,
+		})
+	}
+	return results, nil
+}
+
+// PublishReassignments notifies new reviewers once the transaction that
+// reassigned them has committed.
+func (s *PRService) PublishReassignments(results []*models.PRReassignResult) {
+	for _, r := range results {
+		if r.Outcome == models.ReassignOutcomeReassigned && r.PR != nil {
+			s.publishAssignment(r.PR, r.ReplacedBy)
+		}
+	}
+}
+
+func (s *PRService) getUser(ctx context.Context, userID string) (*models.UserWithTeam, error) {
+	user, err := s.users.GetUserWithTeam(ctx, userID)
+	if err != nil {
+		if errors.Is(err, storage.ErrUserNotFound) {
+			return nil, ErrUserNotFound
+		}
+		return nil, fmt.Errorf("get user: %w", err)
+	}
+	return user, nil
+}
+
+func (s *PRService) openReviewIDs(ctx context.Context, userID string) ([]string, error) {
+	prs, err := s.prs.GetReviewerPRs(ctx, userID)
+	if err != nil {
+		return nil, fmt.Errorf("get reviewer prs: %w", err)
+	}
+	ids := make([]string, 0, len(prs))
+	for _, pr := range prs {
+		if pr.Status == models.StatusOpen {
+			ids = append(ids, pr.ID)
+		}
+	}
+	return ids, nil
+}
+
+// getPRsByID loads the pull requests in ids, with their reviewers, in a
+// fixed number of queries instead of one GetPR per id. Like GetPR it fails
+// when one of them does not exist.
+func (s *PRService) getPRsByID(ctx context.Context, ids []string) (map[string]*models.PullRequest, error) {
+	prs, err := s.prs.GetPRsByIDs(ctx, ids)
+	if err != nil {
+		return nil, fmt.Errorf("get prs: %w", err)
+	}
+	byID := make(map[string]*models.PullRequest, len(prs))
+	for _, pr := range prs {
+		byID[pr.ID] = pr
+	}
+	for _, id := range ids {
+		if _, ok := byID[id]; !ok {
+			return nil, fmt.Errorf("get pr %s: %w", id, storage.ErrPRNotFound)
+		}
+	}
+	return byID, nil
+}
+
+func skippedTransfer(prID string, err error) *models.PRReassignResult {
+	return &models.PRReassignResult{
+		PullRequestID: prID,
+		Outcome:       models.ReassignOutcomeSkipped,
+		Err:           err,
+	}
+}
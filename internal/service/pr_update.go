@@ -0,0 +1,196 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/url"
+	"slices"
+	"strings"
+	"time"
+	"unicode"
+	"unicode/utf8"
+
+	"github.com/cloudyy74/pr-reviewer-service/internal/models"
+	"github.com/cloudyy74/pr-reviewer-service/internal/storage"
+)
+
+const (
+	maxPRTitleLength       = 256
+	maxPRDescriptionLength = 10000
+	maxPRLabels            = 20
+	maxPRLabelLength       = 64
+	maxRepositoryURLLength = 2048
+	maxBranchLength        = 255
+)
+
+// UpdatePR changes the title, description and labels of an open pull
+// request. Only the fields present in the request are changed. Merged and
+// closed pull requests are rejected with ErrPRMerged and ErrPRClosed, also
+// when they were finished while the update was running.
+func (s *PRService) UpdatePR(ctx context.Context, req *models.PRUpdateRequest) (*models.PullRequest, error) {
+	if req == nil {
+		return nil, fmt.Errorf("%w: empty body", ErrPRValidation)
+	}
+	prID := strings.TrimSpace(req.ID)
+	if prID == "" {
+		return nil, fmt.Errorf("%w: pull_request_id is required", ErrPRValidation)
+	}
+	if req.Title == nil && req.Description == nil && req.Labels == nil {
+		return nil, fmt.Errorf("%w: nothing to update, set pull_request_name, description or labels", ErrPRValidation)
+	}
+	var title, description string
+	if req.Title != nil {
+		title = strings.TrimSpace(*req.Title)
+		if title == "" {
+			return nil, fmt.Errorf("%w: pull_request_name cannot be empty", ErrPRValidation)
+		}
+		if utf8.RuneCountInString(title) > maxPRTitleLength {
+			return nil, fmt.Errorf("%w: pull_request_name must be at most %d characters", ErrPRValidation, maxPRTitleLength)
+		}
+	}
+	if req.Description != nil {
+		description = strings.TrimSpace(*req.Description)
+		if utf8.RuneCountInString(description) > maxPRDescriptionLength {
+			return nil, fmt.Errorf("%w: description must be at most %d characters", ErrPRValidation, maxPRDescriptionLength)
+		}
+	}
+	var labels []string
+	if req.Labels != nil {
+		var err error
+		if labels, err = normalizeLabels(*req.Labels); err != nil {
+			return nil, err
+		}
+	}
+
+	var updated *models.PullRequest
+	err := s.tx.Run(ctx, func(ctx context.Context) error {
+		pr, err := s.prs.GetPR(ctx, prID)
+		if err != nil {
+			switch {
+			case errors.Is(err, storage.ErrPRNotFound):
+				return ErrPRNotFound
+			default:
+				s.log.Error("get pr failed", slog.Any("error", err), slog.String("pr_id", prID))
+				return fmt.Errorf("get pr: %w", err)
+			}
+		}
+		if err := checkPROpen(pr); err != nil {
+			return err
+		}
+		if req.Title != nil {
+			pr.Title = title
+		}
+		if req.Description != nil {
+			pr.Description = description
+		}
+		if err := s.prs.UpdatePRDetails(ctx, pr.ID, pr.Title, pr.Description); err != nil {
+			if errors.Is(err, storage.ErrPRNotOpen) {
+				return s.finishedPRError(ctx, pr.ID)
+			}
+			return fmt.Errorf("update pr details: %w", err)
+		}
+		if req.Labels != nil {
+			if err := s.prs.SetPRLabels(ctx, pr.ID, labels); err != nil {
+				return fmt.Errorf("set pr labels: %w", err)
+			}
+			pr.Labels = labels
+		}
+		setNeedMoreReviewers(pr)
+		updated = pr
+		return emitOutboxEvent(ctx, s.outbox, models.TopicPRUpdated, pr.ID, models.PREvent{
+			PullRequest: pr,
+			OccurredAt:  time.Now().UTC(),
+		})
+	})
+	if err != nil {
+		switch {
+		case errors.Is(err, ErrPRValidation),
+			errors.Is(err, ErrPRNotFound),
+			errors.Is(err, ErrPRMerged),
+			errors.Is(err, ErrPRClosed):
+			return nil, err
+		default:
+			return nil, fmt.Errorf("update pr transaction: %w", err)
+		}
+	}
+	return updated, nil
+}
+
+// finishedPRError reports why a pull request that was open when read is not
+// open any more.
+func (s *PRService) finishedPRError(ctx context.Context, prID string) error {
+	pr, err := s.prs.GetPR(ctx, prID)
+	if err != nil {
+		return fmt.Errorf("get pr: %w", err)
+	}
+	if err := checkPROpen(pr); err != nil {
+		return err
+	}
+	return fmt.Errorf("pr %s is %s", prID, pr.Status)
+}
+
+// normalizeLabels trims labels, drops empty and repeated ones and sorts the
+// rest.
+func normalizeLabels(raw []string) ([]string, error) {
+	labels := make([]string, 0, len(raw))
+	for _, label := range raw {
+		label = strings.TrimSpace(label)
+		if label == "" {
+			continue
+		}
+		if utf8.RuneCountInString(label) > maxPRLabelLength {
+			return nil, fmt.Errorf("%w: labels must be at most %d characters", ErrPRValidation, maxPRLabelLength)
+		}
+		labels = append(labels, label)
+	}
+	slices.Sort(labels)
+	labels = slices.Compact(labels)
+	if len(labels) > maxPRLabels {
+		return nil, fmt.Errorf("%w: at most %d labels per pull request", ErrPRValidation, maxPRLabels)
+	}
+	return labels, nil
+}
+
+// normalizeRepositoryURL accepts an absolute http or https URL, which is
+// what clients link to.
+func normalizeRepositoryURL(raw string) (string, error) {
+	raw = strings.TrimSpace(raw)
+	if raw == "" {
+		return "", nil
+	}
+	if len(raw) > maxRepositoryURLLength {
+		return "", fmt.Errorf("%w: repository_url must be at most %d characters", ErrPRValidation, maxRepositoryURLLength)
+	}
+	u, err := url.Parse(raw)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return "", fmt.Errorf("%w: repository_url must be an absolute http or https url", ErrPRValidation)
+	}
+	return raw, nil
+}
+
+func normalizeBranch(raw string) (string, error) {
+	branch := strings.TrimSpace(raw)
+	if utf8.RuneCountInString(branch) > maxBranchLength {
+		return "", fmt.Errorf("%w: branch must be at most %d characters", ErrPRValidation, maxBranchLength)
+	}
+	if strings.IndexFunc(branch, unicode.IsSpace) >= 0 || strings.IndexFunc(branch, unicode.IsControl) >= 0 {
+		return "", fmt.Errorf("%w: branch cannot contain spaces", ErrPRValidation)
+	}
+	return branch, nil
+}
+
+// normalizePriority upper-cases the priority; an empty one is NORMAL.
+func normalizePriority(raw string) (string, error) {
+	priority := strings.ToUpper(strings.TrimSpace(raw))
+	switch priority {
+	case "":
+		return models.PriorityNormal, nil
+	case models.PriorityLow, models.PriorityNormal, models.PriorityHigh, models.PriorityUrgent:
+		return priority, nil
+	default:
+		return "", fmt.Errorf("%w: priority must be %s, %s, %s or %s", ErrPRValidation,
+			models.PriorityLow, models.PriorityNormal, models.PriorityHigh, models.PriorityUrgent)
+	}
+}
@@ -0,0 +1,103 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"slices"
+
+	"github.com/cloudyy74/pr-reviewer-service/internal/data"
+	"github.com/cloudyy74/pr-reviewer-service/internal/models"
+)
+
+const migrationsTable = "schema_migrations"
+
+type SchemaRepository interface {
+	GetColumns(ctx context.Context) (map[string][]string, error)
+	GetIndexes(ctx context.Context) ([]string, error)
+	GetMigrationVersion(ctx context.Context) (int, bool, error)
+}
+
+type SchemaService struct {
+	schema   SchemaRepository
+	expected *data.Schema
+	log      *slog.Logger
+}
+
+func NewSchemaService(schema SchemaRepository, log *slog.Logger) (*SchemaService, error) {
+	if schema == nil {
+		return nil, errors.New("schema repository cannot be nil")
+	}
+	if log == nil {
+		return nil, errors.New("logger cannot be nil")
+	}
+	expected, err := data.ExpectedSchema()
+	if err != nil {
+		return nil, fmt.Errorf("load expected schema: %w", err)
+	}
+	return &SchemaService{
+		schema:   schema,
+		expected: expected,
+		log:      log,
+	}, nil
+}
+
+func (s *SchemaService) Check(ctx context.Context) (*models.SchemaReport, error) {
+	report := &models.SchemaReport{
+		ExpectedVersion: s.expected.Version,
+		Problems:        make([]string, 0),
+	}
+
+	columns, err := s.schema.GetColumns(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("get columns: %w", err)
+	}
+	if _, ok := columns[migrationsTable]; !ok {
+		report.Problems = append(report.Problems, "table schema_migrations is missing: migrations have never been applied")
+	} else {
+		report.CurrentVersion, report.Dirty, err = s.schema.GetMigrationVersion(ctx)
+		if err != nil {
+			return nil, fmt.Errorf("get migration version: %w", err)
+		}
+		switch {
+		case report.Dirty:
+			report.Problems = append(report.Problems, fmt.Sprintf("migration %d is dirty: fix the failed migration and force the version", report.CurrentVersion))
+		case report.CurrentVersion < report.ExpectedVersion:
+			report.Problems = append(report.Problems, fmt.Sprintf("database is at migration %d, expected %d: run pending migrations", report.CurrentVersion, report.ExpectedVersion))
+		case report.CurrentVersion > report.ExpectedVersion:
+			report.Problems = append(report.Problems, fmt.Sprintf("database is at migration %d, newer than %d known to this build", report.CurrentVersion, report.ExpectedVersion))
+		}
+	}
+
+	tables := make([]string, 0, len(s.expected.Tables))
+	for table := range s.expected.Tables {
+		tables = append(tables, table)
+	}
+	slices.Sort(tables)
+	for _, table := range tables {
+		actual, ok := columns[table]
+		if !ok {
+			report.Problems = append(report.Problems, fmt.Sprintf("table %s is missing", table))
+			continue
+		}
+		for _, column := range s.expected.Tables[table] {
+			if !slices.Contains(actual, column) {
+				report.Problems = append(report.Problems, fmt.Sprintf("column %s.%s is missing", table, column))
+			}
+		}
+	}
+
+	indexes, err := s.schema.GetIndexes(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("get indexes: %w", err)
+	}
+	for _, index := range s.expected.Indexes {
+		if !slices.Contains(indexes, index) {
+			report.Problems = append(report.Problems, fmt.Sprintf("index %s is missing", index))
+		}
+	}
+
+	report.OK = len(report.Problems) == 0
+	return report, nil
+}
@@ -0,0 +1,105 @@
+package service
+
+import (
+	"context"
+	"slices"
+	"testing"
+
+	"github.com/cloudyy74/pr-reviewer-service/internal/data"
+)
+
+type fakeSchemaRepo struct {
+	columns map[string][]string
+	indexes []string
+	version int
+	dirty   bool
+}
+
+func (f *fakeSchemaRepo) GetColumns(context.Context) (map[string][]string, error) {
+	return f.columns, nil
+}
+
+func (f *fakeSchemaRepo) GetIndexes(context.Context) ([]string, error) {
+	return f.indexes, nil
+}
+
+func (f *fakeSchemaRepo) GetMigrationVersion(context.Context) (int, bool, error) {
+	return f.version, f.dirty, nil
+}
+
+func migratedSchemaRepo(t *testing.T) *fakeSchemaRepo {
+	t.Helper()
+	expected, err := data.ExpectedSchema()
+	if err != nil {
+		t.Fatalf("ExpectedSchema returned err: %v", err)
+	}
+	repo := &fakeSchemaRepo{
+		columns: map[string][]string{migrationsTable: {"version", "dirty"}},
+		indexes: slices.Clone(expected.Indexes),
+		version: expected.Version,
+	}
+	for table, columns := range expected.Tables {
+		repo.columns[table] = slices.Clone(columns)
+	}
+	return repo
+}
+
+func TestSchemaService_Check_OK(t *testing.T) {
+	service, err := NewSchemaService(migratedSchemaRepo(t), testLogger())
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	report, err := service.Check(context.Background())
+	if err != nil {
+		t.Fatalf("Check returned error: %v", err)
+	}
+	if !report.OK || len(report.Problems) != 0 {
+		t.Fatalf("expected healthy schema, got %#v", report)
+	}
+}
+
+func TestSchemaService_Check_ReportsDrift(t *testing.T) {
+	repo := migratedSchemaRepo(t)
+	repo.version--
+	repo.columns["users"] = []string{"id", "username", "team_name"}
+	delete(repo.columns, "assignment_anomalies")
+	service, err := NewSchemaService(repo, testLogger())
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	report, err := service.Check(context.Background())
+	if err != nil {
+		t.Fatalf("Check returned error: %v", err)
+	}
+	if report.OK {
+		t.Fatalf("expected drift to be reported")
+	}
+	for _, want := range []string{
+		"column users.is_active is missing",
+		"table assignment_anomalies is missing",
+	} {
+		if !slices.Contains(report.Problems, want) {
+			t.Fatalf("expected problem %q in %v", want, report.Problems)
+		}
+	}
+	if report.CurrentVersion != report.ExpectedVersion-1 {
+		t.Fatalf("unexpected versions: %#v", report)
+	}
+}
+
+func TestSchemaService_Check_NotMigrated(t *testing.T) {
+	service, err := NewSchemaService(&fakeSchemaRepo{columns: map[string][]string{}}, testLogger())
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	report, err := service.Check(context.Background())
+	if err != nil {
+		t.Fatalf("Check returned error: %v", err)
+	}
+	if report.OK || report.Problems[0] != "table schema_migrations is missing: migrations have never been applied" {
+		t.Fatalf("unexpected report: %#v", report)
+	}
+}
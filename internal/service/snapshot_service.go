@@ -0,0 +1,168 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"strconv"
+	"strings"
+
+	"github.com/cloudyy74/pr-reviewer-service/internal/models"
+	"github.com/cloudyy74/pr-reviewer-service/internal/storage"
+)
+
+var (
+	ErrSnapshotValidation = errors.New("validation error")
+	ErrSnapshotNotFound   = errors.New("assignment snapshot not found")
+)
+
+const maxSnapshotLabelLength = 128
+
+type SnapshotRepository interface {
+	CreateSnapshot(ctx context.Context, label, createdBy string) (*models.AssignmentSnapshot, error)
+	RestoreSnapshot(ctx context.Context, id int64) (*models.SnapshotRestore, error)
+}
+
+type SnapshotEventRepository interface {
+	AddAssignmentEvents(ctx context.Context, prID string, userIDs []string, event, reason string) error
+}
+
+// SnapshotService saves and restores reviewer assignments so that bulk
+// operations can be rolled back without restoring the database. Every
+// snapshot and restore is written to the audit log in the same transaction;
+// callers pass the entry without the subject, which is the snapshot.
+type SnapshotService struct {
+	tx        txManager
+	snapshots SnapshotRepository
+	events    SnapshotEventRepository
+	audit     AuditRepository
+	log       *slog.Logger
+}
+
+func NewSnapshotService(
+	tx txManager,
+	snapshots SnapshotRepository,
+	events SnapshotEventRepository,
+	audit AuditRepository,
+	log *slog.Logger,
+) (*SnapshotService, error) {
+	if tx == nil {
+		return nil, errors.New("tx manager cannot be nil")
+	}
+	if snapshots == nil {
+		return nil, errors.New("snapshots repository cannot be nil")
+	}
+	if events == nil {
+		return nil, errors.New("events repository cannot be nil")
+	}
+	if audit == nil {
+		return nil, errors.New("audit repository cannot be nil")
+	}
+	if log == nil {
+		return nil, errors.New("logger cannot be nil")
+	}
+	return &SnapshotService{
+		tx:        tx,
+		snapshots: snapshots,
+		events:    events,
+		audit:     audit,
+		log:       log,
+	}, nil
+}
+
+func (s *SnapshotService) CreateSnapshot(ctx context.Context, req *models.CreateSnapshotRequest, audit models.AuditEntry) (*models.AssignmentSnapshot, error) {
+	if req == nil {
+		return nil, fmt.Errorf("%w: empty body", ErrSnapshotValidation)
+	}
+	label := strings.TrimSpace(req.Label)
+	if len(label) > maxSnapshotLabelLength {
+		return nil, fmt.Errorf("%w: label must be at most %d characters", ErrSnapshotValidation, maxSnapshotLabelLength)
+	}
+
+	var snapshot *models.AssignmentSnapshot
+	err := s.tx.Run(ctx, func(ctx context.Context) error {
+		var err error
+		snapshot, err = s.snapshots.CreateSnapshot(ctx, label, audit.ActorID)
+		if err != nil {
+			return fmt.Errorf("create snapshot: %w", err)
+		}
+		return s.recordAudit(ctx, audit, snapshot.ID)
+	})
+	if err != nil {
+		return nil, fmt.Errorf("create snapshot transaction: %w", err)
+	}
+	s.log.Info("assignment snapshot created",
+		slog.Int64("snapshot_id", snapshot.ID),
+		slog.Int("reviewers", snapshot.Reviewers),
+		slog.String("actor_id", audit.ActorID),
+	)
+	return snapshot, nil
+}
+
+// RestoreSnapshot rolls reviewer assignments back to the snapshot. The
+// changes are recorded in assignment_events like any reassignment, so stats
+// and history stay consistent.
+func (s *SnapshotService) RestoreSnapshot(ctx context.Context, req *models.RestoreSnapshotRequest, audit models.AuditEntry) (*models.RestoreSnapshotResponse, error) {
+	if req == nil {
+		return nil, fmt.Errorf("%w: empty body", ErrSnapshotValidation)
+	}
+	if req.SnapshotID <= 0 {
+		return nil, fmt.Errorf("%w: snapshot_id is required", ErrSnapshotValidation)
+	}
+
+	resp := &models.RestoreSnapshotResponse{SnapshotID: req.SnapshotID}
+	err := s.tx.Run(ctx, func(ctx context.Context) error {
+		restore, err := s.snapshots.RestoreSnapshot(ctx, req.SnapshotID)
+		if err != nil {
+			if errors.Is(err, storage.ErrSnapshotNotFound) {
+				return ErrSnapshotNotFound
+			}
+			return fmt.Errorf("restore snapshot: %w", err)
+		}
+
+		reason := fmt.Sprintf("restored assignment snapshot %d", req.SnapshotID)
+		prs := make(map[string]struct{})
+		for _, change := range []struct {
+			assignments []models.ReviewerAssignment
+			event       string
+		}{
+			{restore.Removed, models.EventReassigned},
+			{restore.Restored, models.EventAssigned},
+		} {
+			for _, a := range change.assignments {
+				if err := s.events.AddAssignmentEvents(ctx, a.PullRequestID, []string{a.UserID}, change.event, reason); err != nil {
+					return fmt.Errorf("add assignment event: %w", err)
+				}
+				prs[a.PullRequestID] = struct{}{}
+			}
+		}
+
+		resp.PullRequests = len(prs)
+		resp.Removed = len(restore.Removed)
+		resp.Restored = len(restore.Restored)
+		resp.RotationCursors = restore.RotationCursors
+		return s.recordAudit(ctx, audit, req.SnapshotID)
+	})
+	if err != nil {
+		if errors.Is(err, ErrSnapshotNotFound) {
+			return nil, err
+		}
+		return nil, fmt.Errorf("restore snapshot transaction: %w", err)
+	}
+	s.log.Info("assignment snapshot restored",
+		slog.Int64("snapshot_id", req.SnapshotID),
+		slog.Int("removed", resp.Removed),
+		slog.Int("restored", resp.Restored),
+		slog.String("actor_id", audit.ActorID),
+	)
+	return resp, nil
+}
+
+func (s *SnapshotService) recordAudit(ctx context.Context, entry models.AuditEntry, snapshotID int64) error {
+	entry.SubjectID = "snapshot:" + strconv.FormatInt(snapshotID, 10)
+	if err := s.audit.RecordAudit(ctx, entry); err != nil {
+		return fmt.Errorf("record audit: %w", err)
+	}
+	return nil
+}
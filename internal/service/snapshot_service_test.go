@@ -0,0 +1,120 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/cloudyy74/pr-reviewer-service/internal/models"
+	"github.com/cloudyy74/pr-reviewer-service/internal/storage"
+)
+
+type fakeSnapshotRepo struct {
+	restore *models.SnapshotRestore
+	labels  []string
+}
+
+func (f *fakeSnapshotRepo) CreateSnapshot(_ context.Context, label, createdBy string) (*models.AssignmentSnapshot, error) {
+	f.labels = append(f.labels, label)
+	return &models.AssignmentSnapshot{ID: 7, Label: label, CreatedBy: createdBy, Reviewers: 3}, nil
+}
+
+func (f *fakeSnapshotRepo) RestoreSnapshot(context.Context, int64) (*models.SnapshotRestore, error) {
+	if f.restore == nil {
+		return nil, storage.ErrSnapshotNotFound
+	}
+	return f.restore, nil
+}
+
+type recordedEvent struct {
+	prID, userID, event string
+}
+
+type fakeSnapshotEvents struct {
+	events []recordedEvent
+}
+
+func (f *fakeSnapshotEvents) AddAssignmentEvents(_ context.Context, prID string, userIDs []string, event, _ string) error {
+	for _, id := range userIDs {
+		f.events = append(f.events, recordedEvent{prID: prID, userID: id, event: event})
+	}
+	return nil
+}
+
+func TestSnapshotService_CreateSnapshot(t *testing.T) {
+	repo := &fakeSnapshotRepo{}
+	audit := &fakeAuditRepo{}
+	svc, err := NewSnapshotService(fakeTxManager{}, repo, &fakeSnapshotEvents{}, audit, testLogger())
+	if err != nil {
+		t.Fatalf("NewSnapshotService: %v", err)
+	}
+
+	entry := models.AuditEntry{ActorID: "admin", Method: "POST", Path: "/admin/snapshot", Status: 201}
+	snapshot, err := svc.CreateSnapshot(context.Background(), &models.CreateSnapshotRequest{Label: " before rebalance "}, entry)
+	if err != nil {
+		t.Fatalf("CreateSnapshot: %v", err)
+	}
+	if snapshot.ID != 7 || snapshot.CreatedBy != "admin" || repo.labels[0] != "before rebalance" {
+		t.Fatalf("unexpected snapshot %+v", snapshot)
+	}
+	if len(audit.entries) != 1 || audit.entries[0].SubjectID != "snapshot:7" || audit.entries[0].Path != "/admin/snapshot" {
+		t.Fatalf("unexpected audit entries %+v", audit.entries)
+	}
+}
+
+func TestSnapshotService_RestoreSnapshot(t *testing.T) {
+	repo := &fakeSnapshotRepo{restore: &models.SnapshotRestore{
+		Removed:         []models.ReviewerAssignment{{PullRequestID: "pr-1", UserID: "u3"}},
+		Restored:        []models.ReviewerAssignment{{PullRequestID: "pr-1", UserID: "u2"}, {PullRequestID: "pr-2", UserID: "u2"}},
+		RotationCursors: 1,
+	}}
+	events := &fakeSnapshotEvents{}
+	audit := &fakeAuditRepo{}
+	svc, err := NewSnapshotService(fakeTxManager{}, repo, events, audit, testLogger())
+	if err != nil {
+		t.Fatalf("NewSnapshotService: %v", err)
+	}
+
+	resp, err := svc.RestoreSnapshot(context.Background(), &models.RestoreSnapshotRequest{SnapshotID: 7}, models.AuditEntry{ActorID: "admin"})
+	if err != nil {
+		t.Fatalf("RestoreSnapshot: %v", err)
+	}
+	if resp.PullRequests != 2 || resp.Removed != 1 || resp.Restored != 2 || resp.RotationCursors != 1 {
+		t.Fatalf("unexpected response %+v", resp)
+	}
+	want := []recordedEvent{
+		{"pr-1", "u3", models.EventReassigned},
+		{"pr-1", "u2", models.EventAssigned},
+		{"pr-2", "u2", models.EventAssigned},
+	}
+	if len(events.events) != len(want) {
+		t.Fatalf("unexpected events %+v", events.events)
+	}
+	for i := range want {
+		if events.events[i] != want[i] {
+			t.Fatalf("event %d: want %+v, got %+v", i, want[i], events.events[i])
+		}
+	}
+	if len(audit.entries) != 1 || audit.entries[0].SubjectID != "snapshot:7" {
+		t.Fatalf("unexpected audit entries %+v", audit.entries)
+	}
+}
+
+func TestSnapshotService_RestoreSnapshot_Errors(t *testing.T) {
+	audit := &fakeAuditRepo{}
+	svc, err := NewSnapshotService(fakeTxManager{}, &fakeSnapshotRepo{}, &fakeSnapshotEvents{}, audit, testLogger())
+	if err != nil {
+		t.Fatalf("NewSnapshotService: %v", err)
+	}
+
+	ctx := context.Background()
+	if _, err := svc.RestoreSnapshot(ctx, &models.RestoreSnapshotRequest{}, models.AuditEntry{}); !errors.Is(err, ErrSnapshotValidation) {
+		t.Fatalf("expected ErrSnapshotValidation, got %v", err)
+	}
+	if _, err := svc.RestoreSnapshot(ctx, &models.RestoreSnapshotRequest{SnapshotID: 9}, models.AuditEntry{}); !errors.Is(err, ErrSnapshotNotFound) {
+		t.Fatalf("expected ErrSnapshotNotFound, got %v", err)
+	}
+	if len(audit.entries) != 0 {
+		t.Fatalf("expected no audit entries for failed restores, got %+v", audit.entries)
+	}
+}
@@ -0,0 +1,30 @@
+package service
+
+type StatsOption func(*StatsService)
+
+func WithReviewerCapacity(perMember int) StatsOption {
+	return func(s *StatsService) {
+		if perMember > 0 {
+			s.reviewerCapacity = perMember
+		}
+	}
+}
+
+func WithAnomalyThreshold(share float64, minAssignments int) StatsOption {
+	return func(s *StatsService) {
+		if share > 0 && share < 1 {
+			s.anomalyShareThreshold = share
+		}
+		if minAssignments > 0 {
+			s.anomalyMinAssignments = minAssignments
+		}
+	}
+}
+
+func WithAnomalyAlerter(alerter AnomalyAlerter) StatsOption {
+	return func(s *StatsService) {
+		if alerter != nil {
+			s.alerter = alerter
+		}
+	}
+}
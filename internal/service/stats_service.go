@@ -0,0 +1,528 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/cloudyy74/pr-reviewer-service/internal/models"
+)
+
+const (
+	defaultReviewerCapacity      = 5
+	maxTimeseriesBuckets         = 1000
+	defaultAnomalyShareThreshold = 0.5
+	defaultAnomalyMinAssignments = 5
+	anomalyLookbackWeeks         = 4
+	defaultAnomalyWeeks          = 4
+	maxAnomalyWeeks              = 52
+	defaultHeatmapDays           = 28
+	defaultThroughputWeeks       = 4
+	maxThroughputWeeks           = 26
+	defaultCompletionDays        = 30
+)
+
+var ErrStatsValidation = errors.New("validation error")
+
+var defaultTimeseriesBuckets = map[string]int{
+	models.IntervalHour: 48,
+	models.IntervalDay:  30,
+	models.IntervalWeek: 12,
+}
+
+type StatsRepository interface {
+	GetOpenPRCounts(ctx context.Context, minReviewers int) (int, int, error)
+	GetAvgAssignmentLatency(ctx context.Context, since time.Time) (float64, error)
+	GetBusiestReviewer(ctx context.Context) (*models.ReviewerLoad, error)
+	GetTeamsOverCapacity(ctx context.Context, perMember int) ([]*models.TeamLoad, error)
+	GetTimeseries(ctx context.Context, metric, interval string, from, to time.Time, asOf *time.Time) ([]*models.TeamBucketCount, error)
+	GetWeeklyAssignmentShares(ctx context.Context, from, to time.Time) ([]*models.WeeklyAssignmentShare, error)
+	SaveAnomaly(ctx context.Context, anomaly *models.AssignmentAnomaly) (bool, error)
+	GetAnomalies(ctx context.Context, since time.Time) ([]*models.AssignmentAnomaly, error)
+	GetAssignmentHeatmap(ctx context.Context, teamName string, from, to time.Time, asOf *time.Time) ([]*models.HeatmapCell, error)
+	GetWeeklyMergedCounts(ctx context.Context, from, to time.Time, asOf *time.Time) ([]*models.MergedCount, error)
+	GetCompletionCounts(ctx context.Context, teamName string, from, to time.Time, asOf *time.Time) ([]*models.UserCompletion, error)
+	GetLabelStats(ctx context.Context, teamName string, from, to time.Time) ([]*models.LabelStats, error)
+}
+
+type AnomalyAlerter interface {
+	AlertAnomaly(ctx context.Context, anomaly *models.AssignmentAnomaly)
+}
+
+type StatsService struct {
+	stats StatsRepository
+	log   *slog.Logger
+
+	reviewerCapacity      int
+	anomalyShareThreshold float64
+	anomalyMinAssignments int
+	alerter               AnomalyAlerter
+	now                   func() time.Time
+}
+
+func NewStatsService(stats StatsRepository, log *slog.Logger, opts ...StatsOption) (*StatsService, error) {
+	if stats == nil {
+		return nil, errors.New("stats repository cannot be nil")
+	}
+	if log == nil {
+		return nil, errors.New("logger cannot be nil")
+	}
+	s := &StatsService{
+		stats:                 stats,
+		log:                   log,
+		reviewerCapacity:      defaultReviewerCapacity,
+		anomalyShareThreshold: defaultAnomalyShareThreshold,
+		anomalyMinAssignments: defaultAnomalyMinAssignments,
+		now:                   time.Now,
+	}
+	for _, opt := range opts {
+		opt(s)
+	}
+	if s.alerter == nil {
+		s.alerter = logAnomalyAlerter{log: log}
+	}
+	return s, nil
+}
+
+func (s *StatsService) GetSummary(ctx context.Context) (*models.StatsSummary, error) {
+	open, needReviewers, err := s.stats.GetOpenPRCounts(ctx, reviewersPerPR)
+	if err != nil {
+		return nil, fmt.Errorf("get open pr counts: %w", err)
+	}
+
+	today := s.now().UTC().Truncate(24 * time.Hour)
+	latency, err := s.stats.GetAvgAssignmentLatency(ctx, today)
+	if err != nil {
+		return nil, fmt.Errorf("get assignment latency: %w", err)
+	}
+
+	busiest, err := s.stats.GetBusiestReviewer(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("get busiest reviewer: %w", err)
+	}
+
+	overCapacity, err := s.stats.GetTeamsOverCapacity(ctx, s.reviewerCapacity)
+	if err != nil {
+		return nil, fmt.Errorf("get teams over capacity: %w", err)
+	}
+	if overCapacity == nil {
+		overCapacity = make([]*models.TeamLoad, 0)
+	}
+
+	return &models.StatsSummary{
+		OpenPRs:                     open,
+		PRsNeedingReviewers:         needReviewers,
+		AvgAssignmentLatencySeconds: latency,
+		BusiestReviewer:             busiest,
+		TeamsOverCapacity:           overCapacity,
+	}, nil
+}
+
+func (s *StatsService) GetTimeseries(ctx context.Context, q models.TimeseriesQuery) (*models.TimeseriesResponse, error) {
+	metric := strings.TrimSpace(q.Metric)
+	if metric == "" {
+		metric = models.MetricAssignments
+	}
+	switch metric {
+	case models.MetricAssignments, models.MetricPRsCreated, models.MetricPRsMerged:
+	default:
+		return nil, fmt.Errorf("%w: unsupported metric %q", ErrStatsValidation, metric)
+	}
+	interval := strings.TrimSpace(q.Interval)
+	if interval == "" {
+		interval = models.IntervalDay
+	}
+	buckets, ok := defaultTimeseriesBuckets[interval]
+	if !ok {
+		return nil, fmt.Errorf("%w: unsupported interval %q", ErrStatsValidation, interval)
+	}
+
+	to := s.now().UTC()
+	if q.To != nil {
+		to = q.To.UTC()
+	}
+	from := truncateToInterval(to, interval)
+	for range buckets - 1 {
+		from = previousBucket(from, interval)
+	}
+	if q.From != nil {
+		from = q.From.UTC()
+	}
+	if !from.Before(to) {
+		return nil, fmt.Errorf("%w: from must be before to", ErrStatsValidation)
+	}
+
+	starts := bucketStarts(from, to, interval)
+	if len(starts) > maxTimeseriesBuckets {
+		return nil, fmt.Errorf("%w: range produces more than %d buckets", ErrStatsValidation, maxTimeseriesBuckets)
+	}
+
+	counts, err := s.stats.GetTimeseries(ctx, metric, interval, from, to, utcTime(q.AsOf))
+	if err != nil {
+		return nil, fmt.Errorf("get timeseries: %w", err)
+	}
+
+	byTeam := make(map[string]map[time.Time]int)
+	var teams []string
+	for _, c := range counts {
+		if _, ok := byTeam[c.TeamName]; !ok {
+			byTeam[c.TeamName] = make(map[time.Time]int)
+			teams = append(teams, c.TeamName)
+		}
+		byTeam[c.TeamName][c.BucketStart.UTC()] += c.Count
+	}
+
+	series := make([]*models.TeamSeries, 0, len(teams))
+	for _, team := range teams {
+		points := make([]*models.TimeseriesPoint, 0, len(starts))
+		for _, start := range starts {
+			points = append(points, &models.TimeseriesPoint{BucketStart: start, Count: byTeam[team][start]})
+		}
+		series = append(series, &models.TeamSeries{TeamName: team, Points: points})
+	}
+
+	return &models.TimeseriesResponse{
+		Metric:   metric,
+		Interval: interval,
+		From:     from,
+		To:       to,
+		AsOf:     utcTime(q.AsOf),
+		Series:   series,
+	}, nil
+}
+
+func truncateToInterval(t time.Time, interval string) time.Time {
+	t = t.UTC()
+	switch interval {
+	case models.IntervalHour:
+		return t.Truncate(time.Hour)
+	case models.IntervalWeek:
+		day := time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, time.UTC)
+		offset := (int(day.Weekday()) + 6) % 7
+		return day.AddDate(0, 0, -offset)
+	default:
+		return time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, time.UTC)
+	}
+}
+
+func nextBucket(t time.Time, interval string) time.Time {
+	switch interval {
+	case models.IntervalHour:
+		return t.Add(time.Hour)
+	case models.IntervalWeek:
+		return t.AddDate(0, 0, 7)
+	default:
+		return t.AddDate(0, 0, 1)
+	}
+}
+
+func previousBucket(t time.Time, interval string) time.Time {
+	switch interval {
+	case models.IntervalHour:
+		return t.Add(-time.Hour)
+	case models.IntervalWeek:
+		return t.AddDate(0, 0, -7)
+	default:
+		return t.AddDate(0, 0, -1)
+	}
+}
+
+func bucketStarts(from, to time.Time, interval string) []time.Time {
+	var starts []time.Time
+	for start := truncateToInterval(from, interval); start.Before(to); start = nextBucket(start, interval) {
+		starts = append(starts, start)
+		if len(starts) > maxTimeseriesBuckets {
+			break
+		}
+	}
+	return starts
+}
+
+func (s *StatsService) DetectAnomalies(ctx context.Context) ([]*models.AssignmentAnomaly, error) {
+	currentWeek := truncateToInterval(s.now(), models.IntervalWeek)
+	from := currentWeek.AddDate(0, 0, -7*anomalyLookbackWeeks)
+	shares, err := s.stats.GetWeeklyAssignmentShares(ctx, from, currentWeek)
+	if err != nil {
+		return nil, fmt.Errorf("get weekly assignment shares: %w", err)
+	}
+
+	detected := make([]*models.AssignmentAnomaly, 0)
+	for _, share := range shares {
+		if share.TeamAssignments < s.anomalyMinAssignments {
+			continue
+		}
+		ratio := float64(share.UserAssignments) / float64(share.TeamAssignments)
+		if ratio <= s.anomalyShareThreshold {
+			continue
+		}
+		anomaly := &models.AssignmentAnomaly{
+			TeamName:        share.TeamName,
+			UserID:          share.UserID,
+			WeekStart:       share.WeekStart.UTC(),
+			UserAssignments: share.UserAssignments,
+			TeamAssignments: share.TeamAssignments,
+			ReviewersCount:  share.ReviewersCount,
+			Share:           ratio,
+			Explanation:     s.explainAnomaly(share, ratio),
+		}
+		created, err := s.stats.SaveAnomaly(ctx, anomaly)
+		if err != nil {
+			return nil, fmt.Errorf("save anomaly: %w", err)
+		}
+		if !created {
+			continue
+		}
+		s.alerter.AlertAnomaly(ctx, anomaly)
+		detected = append(detected, anomaly)
+	}
+
+	return detected, nil
+}
+
+func (s *StatsService) GetAnomalies(ctx context.Context, weeks int) (*models.AnomaliesResponse, error) {
+	if weeks == 0 {
+		weeks = defaultAnomalyWeeks
+	}
+	if weeks < 0 || weeks > maxAnomalyWeeks {
+		return nil, fmt.Errorf("%w: weeks must be between 1 and %d", ErrStatsValidation, maxAnomalyWeeks)
+	}
+
+	since := truncateToInterval(s.now(), models.IntervalWeek).AddDate(0, 0, -7*weeks)
+	anomalies, err := s.stats.GetAnomalies(ctx, since)
+	if err != nil {
+		return nil, fmt.Errorf("get anomalies: %w", err)
+	}
+
+	return &models.AnomaliesResponse{
+		Threshold: s.anomalyShareThreshold,
+		Since:     since,
+		Anomalies: anomalies,
+	}, nil
+}
+
+func (s *StatsService) GetHeatmap(ctx context.Context, q models.HeatmapQuery) (*models.HeatmapResponse, error) {
+	to := s.now().UTC()
+	if q.To != nil {
+		to = q.To.UTC()
+	}
+	from := to.AddDate(0, 0, -defaultHeatmapDays)
+	if q.From != nil {
+		from = q.From.UTC()
+	}
+	if !from.Before(to) {
+		return nil, fmt.Errorf("%w: from must be before to", ErrStatsValidation)
+	}
+
+	cells, err := s.stats.GetAssignmentHeatmap(ctx, strings.TrimSpace(q.TeamName), from, to, utcTime(q.AsOf))
+	if err != nil {
+		return nil, fmt.Errorf("get assignment heatmap: %w", err)
+	}
+
+	teams := make([]*models.TeamHeatmap, 0)
+	byTeam := make(map[string]*models.TeamHeatmap)
+	for _, cell := range cells {
+		if cell.Weekday < 0 || cell.Weekday > 6 || cell.Hour < 0 || cell.Hour > 23 {
+			continue
+		}
+		heatmap, ok := byTeam[cell.TeamName]
+		if !ok {
+			heatmap = &models.TeamHeatmap{TeamName: cell.TeamName}
+			byTeam[cell.TeamName] = heatmap
+			teams = append(teams, heatmap)
+		}
+		heatmap.Cells[cell.Weekday][cell.Hour] += cell.Count
+		heatmap.Total += cell.Count
+	}
+
+	return &models.HeatmapResponse{
+		From:  from,
+		To:    to,
+		AsOf:  utcTime(q.AsOf),
+		Teams: teams,
+	}, nil
+}
+
+func (s *StatsService) GetThroughput(ctx context.Context, weeks int, asOf *time.Time) (*models.ThroughputResponse, error) {
+	if weeks == 0 {
+		weeks = defaultThroughputWeeks
+	}
+	if weeks < 0 || weeks > maxThroughputWeeks {
+		return nil, fmt.Errorf("%w: weeks must be between 1 and %d", ErrStatsValidation, maxThroughputWeeks)
+	}
+
+	to := truncateToInterval(s.now(), models.IntervalWeek)
+	from := to.AddDate(0, 0, -7*weeks)
+	previousFrom := from.AddDate(0, 0, -7*weeks)
+	counts, err := s.stats.GetWeeklyMergedCounts(ctx, previousFrom, to, utcTime(asOf))
+	if err != nil {
+		return nil, fmt.Errorf("get merged counts: %w", err)
+	}
+
+	starts := bucketStarts(from, to, models.IntervalWeek)
+	teams := make([]*models.TeamThroughput, 0)
+	teamWeeks := make(map[string]map[time.Time]int)
+	byTeam := make(map[string]*models.TeamThroughput)
+	authors := make([]*models.AuthorThroughput, 0)
+	byAuthor := make(map[string]*models.AuthorThroughput)
+	for _, c := range counts {
+		team, ok := byTeam[c.TeamName]
+		if !ok {
+			team = &models.TeamThroughput{TeamName: c.TeamName}
+			byTeam[c.TeamName] = team
+			teamWeeks[c.TeamName] = make(map[time.Time]int)
+			teams = append(teams, team)
+		}
+		author, ok := byAuthor[c.AuthorID]
+		if !ok {
+			author = &models.AuthorThroughput{AuthorID: c.AuthorID, Username: c.Username, TeamName: c.TeamName}
+			byAuthor[c.AuthorID] = author
+			authors = append(authors, author)
+		}
+
+		week := c.WeekStart.UTC()
+		if week.Before(from) {
+			team.PreviousMerged += c.Count
+			author.PreviousMerged += c.Count
+			continue
+		}
+		team.Merged += c.Count
+		author.Merged += c.Count
+		teamWeeks[c.TeamName][week] += c.Count
+	}
+
+	for _, team := range teams {
+		team.ChangePercent = changePercent(team.Merged, team.PreviousMerged)
+		team.Weekly = make([]*models.TimeseriesPoint, 0, len(starts))
+		for _, start := range starts {
+			team.Weekly = append(team.Weekly, &models.TimeseriesPoint{BucketStart: start, Count: teamWeeks[team.TeamName][start]})
+		}
+	}
+	for _, author := range authors {
+		author.ChangePercent = changePercent(author.Merged, author.PreviousMerged)
+	}
+	slices.SortStableFunc(authors, func(a, b *models.AuthorThroughput) int {
+		return b.Merged - a.Merged
+	})
+
+	return &models.ThroughputResponse{
+		Weeks:        weeks,
+		From:         from,
+		To:           to,
+		PreviousFrom: previousFrom,
+		AsOf:         utcTime(asOf),
+		Teams:        teams,
+		Authors:      authors,
+	}, nil
+}
+
+func (s *StatsService) GetCompletionRates(ctx context.Context, q models.CompletionQuery) (*models.CompletionResponse, error) {
+	to := s.now().UTC()
+	if q.To != nil {
+		to = q.To.UTC()
+	}
+	from := to.AddDate(0, 0, -defaultCompletionDays)
+	if q.From != nil {
+		from = q.From.UTC()
+	}
+	if !from.Before(to) {
+		return nil, fmt.Errorf("%w: from must be before to", ErrStatsValidation)
+	}
+
+	users, err := s.stats.GetCompletionCounts(ctx, strings.TrimSpace(q.TeamName), from, to, utcTime(q.AsOf))
+	if err != nil {
+		return nil, fmt.Errorf("get completion counts: %w", err)
+	}
+	for _, u := range users {
+		if resolved := u.Merged + u.Reassigned + u.Declined; resolved > 0 {
+			rate := float64(u.Merged) / float64(resolved)
+			u.CompletionRate = &rate
+		}
+	}
+
+	return &models.CompletionResponse{
+		From:  from,
+		To:    to,
+		AsOf:  utcTime(q.AsOf),
+		Users: users,
+	}, nil
+}
+
+// GetLabelStats breaks labeled pull requests down by label over the window,
+// the last 30 days by default. A pull request with several labels counts
+// under each of them.
+func (s *StatsService) GetLabelStats(ctx context.Context, q models.LabelStatsQuery) (*models.LabelStatsResponse, error) {
+	to := s.now().UTC()
+	if q.To != nil {
+		to = q.To.UTC()
+	}
+	from := to.AddDate(0, 0, -defaultCompletionDays)
+	if q.From != nil {
+		from = q.From.UTC()
+	}
+	if !from.Before(to) {
+		return nil, fmt.Errorf("%w: from must be before to", ErrStatsValidation)
+	}
+	teamName := strings.TrimSpace(q.TeamName)
+
+	labels, err := s.stats.GetLabelStats(ctx, teamName, from, to)
+	if err != nil {
+		return nil, fmt.Errorf("get label stats: %w", err)
+	}
+	return &models.LabelStatsResponse{
+		From:     from,
+		To:       to,
+		TeamName: teamName,
+		Labels:   labels,
+	}, nil
+}
+
+// utcTime converts an optional as_of point to UTC; nil keeps stats on the
+// users' current teams.
+func utcTime(t *time.Time) *time.Time {
+	if t == nil {
+		return nil
+	}
+	u := t.UTC()
+	return &u
+}
+
+func changePercent(current, previous int) *float64 {
+	if previous == 0 {
+		return nil
+	}
+	change := float64(current-previous) / float64(previous) * 100
+	return &change
+}
+
+func (s *StatsService) explainAnomaly(share *models.WeeklyAssignmentShare, ratio float64) string {
+	return fmt.Sprintf(
+		"%s received %d of %d assignments (%.0f%%) in team %s during the week of %s, above the %.0f%% threshold; assignments that week went to %d reviewer(s)",
+		share.UserID,
+		share.UserAssignments,
+		share.TeamAssignments,
+		ratio*100,
+		share.TeamName,
+		share.WeekStart.UTC().Format(time.DateOnly),
+		s.anomalyShareThreshold*100,
+		share.ReviewersCount,
+	)
+}
+
+type logAnomalyAlerter struct {
+	log *slog.Logger
+}
+
+func (a logAnomalyAlerter) AlertAnomaly(_ context.Context, anomaly *models.AssignmentAnomaly) {
+	a.log.Warn(
+		"assignment anomaly detected",
+		slog.String("team_name", anomaly.TeamName),
+		slog.String("user_id", anomaly.UserID),
+		slog.Time("week_start", anomaly.WeekStart),
+		slog.Float64("share", anomaly.Share),
+		slog.String("explanation", anomaly.Explanation),
+	)
+}
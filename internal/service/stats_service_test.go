@@ -0,0 +1,450 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/cloudyy74/pr-reviewer-service/internal/models"
+)
+
+type fakeStatsRepo struct {
+	openCountsFn   func(context.Context, int) (int, int, error)
+	latencyFn      func(context.Context, time.Time) (float64, error)
+	busiestFn      func(context.Context) (*models.ReviewerLoad, error)
+	overCapacityFn func(context.Context, int) ([]*models.TeamLoad, error)
+	timeseriesFn   func(context.Context, string, string, time.Time, time.Time, *time.Time) ([]*models.TeamBucketCount, error)
+	sharesFn       func(context.Context, time.Time, time.Time) ([]*models.WeeklyAssignmentShare, error)
+	saveAnomalyFn  func(context.Context, *models.AssignmentAnomaly) (bool, error)
+	anomaliesFn    func(context.Context, time.Time) ([]*models.AssignmentAnomaly, error)
+	heatmapFn      func(context.Context, string, time.Time, time.Time, *time.Time) ([]*models.HeatmapCell, error)
+	mergedFn       func(context.Context, time.Time, time.Time, *time.Time) ([]*models.MergedCount, error)
+	completionFn   func(context.Context, string, time.Time, time.Time, *time.Time) ([]*models.UserCompletion, error)
+	labelsFn       func(context.Context, string, time.Time, time.Time) ([]*models.LabelStats, error)
+}
+
+func (f *fakeStatsRepo) GetLabelStats(ctx context.Context, teamName string, from, to time.Time) ([]*models.LabelStats, error) {
+	if f.labelsFn == nil {
+		return nil, nil
+	}
+	return f.labelsFn(ctx, teamName, from, to)
+}
+
+func (f *fakeStatsRepo) GetCompletionCounts(ctx context.Context, teamName string, from, to time.Time, asOf *time.Time) ([]*models.UserCompletion, error) {
+	if f.completionFn == nil {
+		return nil, nil
+	}
+	return f.completionFn(ctx, teamName, from, to, asOf)
+}
+
+func (f *fakeStatsRepo) GetWeeklyMergedCounts(ctx context.Context, from, to time.Time, asOf *time.Time) ([]*models.MergedCount, error) {
+	if f.mergedFn == nil {
+		return nil, nil
+	}
+	return f.mergedFn(ctx, from, to, asOf)
+}
+
+func (f *fakeStatsRepo) GetAssignmentHeatmap(ctx context.Context, teamName string, from, to time.Time, asOf *time.Time) ([]*models.HeatmapCell, error) {
+	if f.heatmapFn == nil {
+		return nil, nil
+	}
+	return f.heatmapFn(ctx, teamName, from, to, asOf)
+}
+
+func (f *fakeStatsRepo) GetWeeklyAssignmentShares(ctx context.Context, from, to time.Time) ([]*models.WeeklyAssignmentShare, error) {
+	if f.sharesFn == nil {
+		return nil, nil
+	}
+	return f.sharesFn(ctx, from, to)
+}
+
+func (f *fakeStatsRepo) SaveAnomaly(ctx context.Context, anomaly *models.AssignmentAnomaly) (bool, error) {
+	if f.saveAnomalyFn == nil {
+		return true, nil
+	}
+	return f.saveAnomalyFn(ctx, anomaly)
+}
+
+func (f *fakeStatsRepo) GetAnomalies(ctx context.Context, since time.Time) ([]*models.AssignmentAnomaly, error) {
+	if f.anomaliesFn == nil {
+		return nil, nil
+	}
+	return f.anomaliesFn(ctx, since)
+}
+
+type fakeAnomalyAlerter struct {
+	alerts []*models.AssignmentAnomaly
+}
+
+func (f *fakeAnomalyAlerter) AlertAnomaly(_ context.Context, anomaly *models.AssignmentAnomaly) {
+	f.alerts = append(f.alerts, anomaly)
+}
+
+func (f *fakeStatsRepo) GetTimeseries(ctx context.Context, metric, interval string, from, to time.Time, asOf *time.Time) ([]*models.TeamBucketCount, error) {
+	if f.timeseriesFn == nil {
+		return nil, nil
+	}
+	return f.timeseriesFn(ctx, metric, interval, from, to, asOf)
+}
+
+func (f *fakeStatsRepo) GetOpenPRCounts(ctx context.Context, minReviewers int) (int, int, error) {
+	if f.openCountsFn == nil {
+		return 0, 0, nil
+	}
+	return f.openCountsFn(ctx, minReviewers)
+}
+
+func (f *fakeStatsRepo) GetAvgAssignmentLatency(ctx context.Context, since time.Time) (float64, error) {
+	if f.latencyFn == nil {
+		return 0, nil
+	}
+	return f.latencyFn(ctx, since)
+}
+
+func (f *fakeStatsRepo) GetBusiestReviewer(ctx context.Context) (*models.ReviewerLoad, error) {
+	if f.busiestFn == nil {
+		return nil, nil
+	}
+	return f.busiestFn(ctx)
+}
+
+func (f *fakeStatsRepo) GetTeamsOverCapacity(ctx context.Context, perMember int) ([]*models.TeamLoad, error) {
+	if f.overCapacityFn == nil {
+		return nil, nil
+	}
+	return f.overCapacityFn(ctx, perMember)
+}
+
+func TestNewStatsService_Validation(t *testing.T) {
+	if _, err := NewStatsService(nil, nil); err == nil {
+		t.Fatalf("expected error when dependencies are nil")
+	}
+}
+
+func TestStatsService_GetSummary(t *testing.T) {
+	now := time.Date(2025, 3, 4, 15, 30, 0, 0, time.UTC)
+	repo := &fakeStatsRepo{
+		openCountsFn: func(_ context.Context, minReviewers int) (int, int, error) {
+			if minReviewers != reviewersPerPR {
+				t.Fatalf("unexpected min reviewers %d", minReviewers)
+			}
+			return 10, 3, nil
+		},
+		latencyFn: func(_ context.Context, since time.Time) (float64, error) {
+			if !since.Equal(time.Date(2025, 3, 4, 0, 0, 0, 0, time.UTC)) {
+				t.Fatalf("expected start of day, got %v", since)
+			}
+			return 42, nil
+		},
+		busiestFn: func(context.Context) (*models.ReviewerLoad, error) {
+			return &models.ReviewerLoad{UserID: "u1", OpenAssignments: 4}, nil
+		},
+		overCapacityFn: func(_ context.Context, perMember int) ([]*models.TeamLoad, error) {
+			if perMember != 2 {
+				t.Fatalf("expected capacity 2, got %d", perMember)
+			}
+			return nil, nil
+		},
+	}
+	service, err := NewStatsService(repo, testLogger(), WithReviewerCapacity(2))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	service.now = func() time.Time { return now }
+
+	summary, err := service.GetSummary(context.Background())
+	if err != nil {
+		t.Fatalf("GetSummary returned error: %v", err)
+	}
+	if summary.OpenPRs != 10 || summary.PRsNeedingReviewers != 3 || summary.AvgAssignmentLatencySeconds != 42 {
+		t.Fatalf("unexpected summary: %#v", summary)
+	}
+	if summary.BusiestReviewer == nil || summary.BusiestReviewer.UserID != "u1" {
+		t.Fatalf("unexpected busiest reviewer: %#v", summary.BusiestReviewer)
+	}
+	if summary.TeamsOverCapacity == nil {
+		t.Fatalf("expected empty teams list, got nil")
+	}
+}
+
+func TestStatsService_GetSummary_Error(t *testing.T) {
+	repo := &fakeStatsRepo{
+		busiestFn: func(context.Context) (*models.ReviewerLoad, error) {
+			return nil, errors.New("db down")
+		},
+	}
+	service, err := NewStatsService(repo, testLogger())
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if _, err := service.GetSummary(context.Background()); err == nil {
+		t.Fatalf("expected error")
+	}
+}
+
+func TestStatsService_GetTimeseries_ZeroFillsBuckets(t *testing.T) {
+	now := time.Date(2025, 3, 5, 10, 0, 0, 0, time.UTC)
+	repo := &fakeStatsRepo{
+		timeseriesFn: func(_ context.Context, metric, interval string, from, to time.Time, _ *time.Time) ([]*models.TeamBucketCount, error) {
+			if metric != models.MetricAssignments || interval != models.IntervalDay {
+				t.Fatalf("unexpected metric/interval: %s/%s", metric, interval)
+			}
+			if !from.Equal(time.Date(2025, 3, 3, 0, 0, 0, 0, time.UTC)) || !to.Equal(now) {
+				t.Fatalf("unexpected range: %v - %v", from, to)
+			}
+			return []*models.TeamBucketCount{
+				{TeamName: "backend", BucketStart: time.Date(2025, 3, 4, 0, 0, 0, 0, time.UTC), Count: 2},
+			}, nil
+		},
+	}
+	service, err := NewStatsService(repo, testLogger())
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	service.now = func() time.Time { return now }
+	from := time.Date(2025, 3, 3, 0, 0, 0, 0, time.UTC)
+
+	resp, err := service.GetTimeseries(context.Background(), models.TimeseriesQuery{From: &from})
+	if err != nil {
+		t.Fatalf("GetTimeseries returned error: %v", err)
+	}
+	if len(resp.Series) != 1 {
+		t.Fatalf("expected one series, got %#v", resp.Series)
+	}
+	points := resp.Series[0].Points
+	if len(points) != 3 || points[0].Count != 0 || points[1].Count != 2 || points[2].Count != 0 {
+		t.Fatalf("unexpected points: %+v %+v %+v", points[0], points[1], points[2])
+	}
+}
+
+func TestStatsService_GetTimeseries_Validation(t *testing.T) {
+	service, err := NewStatsService(&fakeStatsRepo{}, testLogger())
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	from := time.Date(2000, 1, 1, 0, 0, 0, 0, time.UTC)
+	cases := []models.TimeseriesQuery{
+		{Metric: "latency"},
+		{Interval: "minute"},
+		{Interval: models.IntervalHour, From: &from},
+	}
+	for _, q := range cases {
+		if _, err := service.GetTimeseries(context.Background(), q); !errors.Is(err, ErrStatsValidation) {
+			t.Fatalf("expected ErrStatsValidation for %#v, got %v", q, err)
+		}
+	}
+}
+
+func TestTruncateToInterval_Week(t *testing.T) {
+	sunday := time.Date(2025, 3, 9, 18, 0, 0, 0, time.UTC)
+	got := truncateToInterval(sunday, models.IntervalWeek)
+	if !got.Equal(time.Date(2025, 3, 3, 0, 0, 0, 0, time.UTC)) {
+		t.Fatalf("expected monday, got %v", got)
+	}
+}
+
+func TestStatsService_DetectAnomalies(t *testing.T) {
+	now := time.Date(2025, 3, 12, 9, 0, 0, 0, time.UTC)
+	week := time.Date(2025, 3, 3, 0, 0, 0, 0, time.UTC)
+	repo := &fakeStatsRepo{
+		sharesFn: func(_ context.Context, from, to time.Time) ([]*models.WeeklyAssignmentShare, error) {
+			if !to.Equal(time.Date(2025, 3, 10, 0, 0, 0, 0, time.UTC)) || !from.Equal(to.AddDate(0, 0, -28)) {
+				t.Fatalf("unexpected range: %v - %v", from, to)
+			}
+			return []*models.WeeklyAssignmentShare{
+				{TeamName: "backend", UserID: "u1", WeekStart: week, UserAssignments: 8, TeamAssignments: 10, ReviewersCount: 2},
+				{TeamName: "backend", UserID: "u2", WeekStart: week, UserAssignments: 2, TeamAssignments: 10, ReviewersCount: 2},
+				{TeamName: "mobile", UserID: "u3", WeekStart: week, UserAssignments: 3, TeamAssignments: 3, ReviewersCount: 1},
+				{TeamName: "web", UserID: "u4", WeekStart: week, UserAssignments: 6, TeamAssignments: 6, ReviewersCount: 1},
+			}, nil
+		},
+		saveAnomalyFn: func(_ context.Context, a *models.AssignmentAnomaly) (bool, error) {
+			return a.UserID != "u4", nil
+		},
+	}
+	alerter := &fakeAnomalyAlerter{}
+	service, err := NewStatsService(repo, testLogger(), WithAnomalyAlerter(alerter))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	service.now = func() time.Time { return now }
+
+	detected, err := service.DetectAnomalies(context.Background())
+	if err != nil {
+		t.Fatalf("DetectAnomalies returned error: %v", err)
+	}
+	if len(detected) != 1 || detected[0].UserID != "u1" || detected[0].Share != 0.8 {
+		t.Fatalf("unexpected anomalies: %#v", detected)
+	}
+	if !strings.Contains(detected[0].Explanation, "8 of 10 assignments (80%)") {
+		t.Fatalf("unexpected explanation: %q", detected[0].Explanation)
+	}
+	if len(alerter.alerts) != 1 || alerter.alerts[0] != detected[0] {
+		t.Fatalf("expected one alert, got %#v", alerter.alerts)
+	}
+}
+
+func TestStatsService_GetAnomalies_Validation(t *testing.T) {
+	service, err := NewStatsService(&fakeStatsRepo{}, testLogger())
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	for _, weeks := range []int{-1, maxAnomalyWeeks + 1} {
+		if _, err := service.GetAnomalies(context.Background(), weeks); !errors.Is(err, ErrStatsValidation) {
+			t.Fatalf("expected ErrStatsValidation for weeks=%d, got %v", weeks, err)
+		}
+	}
+}
+
+func TestStatsService_GetHeatmap(t *testing.T) {
+	now := time.Date(2025, 3, 31, 12, 0, 0, 0, time.UTC)
+	repo := &fakeStatsRepo{
+		heatmapFn: func(_ context.Context, teamName string, from, to time.Time, _ *time.Time) ([]*models.HeatmapCell, error) {
+			if teamName != "backend" || !to.Equal(now) || !from.Equal(now.AddDate(0, 0, -28)) {
+				t.Fatalf("unexpected args: %q %v %v", teamName, from, to)
+			}
+			return []*models.HeatmapCell{
+				{TeamName: "backend", Weekday: 0, Hour: 9, Count: 4},
+				{TeamName: "backend", Weekday: 6, Hour: 23, Count: 1},
+			}, nil
+		},
+	}
+	service, err := NewStatsService(repo, testLogger())
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	service.now = func() time.Time { return now }
+
+	resp, err := service.GetHeatmap(context.Background(), models.HeatmapQuery{TeamName: " backend "})
+	if err != nil {
+		t.Fatalf("GetHeatmap returned error: %v", err)
+	}
+	if len(resp.Teams) != 1 || resp.Teams[0].Total != 5 {
+		t.Fatalf("unexpected heatmap: %#v", resp.Teams)
+	}
+	if resp.Teams[0].Cells[0][9] != 4 || resp.Teams[0].Cells[6][23] != 1 {
+		t.Fatalf("unexpected cells: %v", resp.Teams[0].Cells)
+	}
+}
+
+func TestStatsService_GetThroughput(t *testing.T) {
+	now := time.Date(2025, 3, 19, 12, 0, 0, 0, time.UTC)
+	weekStart := time.Date(2025, 3, 17, 0, 0, 0, 0, time.UTC)
+	asOf := time.Date(2025, 3, 1, 12, 0, 0, 0, time.FixedZone("MSK", 3*60*60))
+	repo := &fakeStatsRepo{
+		mergedFn: func(_ context.Context, from, to time.Time, gotAsOf *time.Time) ([]*models.MergedCount, error) {
+			if !to.Equal(weekStart) || !from.Equal(weekStart.AddDate(0, 0, -28)) {
+				t.Fatalf("unexpected range: %v - %v", from, to)
+			}
+			if gotAsOf == nil || !gotAsOf.Equal(asOf) || gotAsOf.Location() != time.UTC {
+				t.Fatalf("expected as_of %v in UTC, got %v", asOf, gotAsOf)
+			}
+			return []*models.MergedCount{
+				{TeamName: "backend", AuthorID: "u1", Username: "Alice", WeekStart: from, Count: 4},
+				{TeamName: "backend", AuthorID: "u1", Username: "Alice", WeekStart: weekStart.AddDate(0, 0, -7), Count: 3},
+				{TeamName: "backend", AuthorID: "u2", Username: "Bob", WeekStart: weekStart.AddDate(0, 0, -14), Count: 5},
+			}, nil
+		},
+	}
+	service, err := NewStatsService(repo, testLogger())
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	service.now = func() time.Time { return now }
+
+	resp, err := service.GetThroughput(context.Background(), 2, &asOf)
+	if err != nil {
+		t.Fatalf("GetThroughput returned error: %v", err)
+	}
+	if resp.AsOf == nil || !resp.AsOf.Equal(asOf) {
+		t.Fatalf("expected as_of echoed, got %v", resp.AsOf)
+	}
+	if len(resp.Teams) != 1 {
+		t.Fatalf("unexpected teams: %#v", resp.Teams)
+	}
+	team := resp.Teams[0]
+	if team.Merged != 8 || team.PreviousMerged != 4 || team.ChangePercent == nil || *team.ChangePercent != 100 {
+		t.Fatalf("unexpected team throughput: %#v", team)
+	}
+	if len(team.Weekly) != 2 || team.Weekly[0].Count != 5 || team.Weekly[1].Count != 3 {
+		t.Fatalf("unexpected weekly points: %+v %+v", team.Weekly[0], team.Weekly[1])
+	}
+	if len(resp.Authors) != 2 || resp.Authors[0].AuthorID != "u2" || resp.Authors[0].ChangePercent != nil {
+		t.Fatalf("unexpected authors: %#v", resp.Authors)
+	}
+	if resp.Authors[1].Merged != 3 || resp.Authors[1].PreviousMerged != 4 {
+		t.Fatalf("unexpected author throughput: %#v", resp.Authors[1])
+	}
+}
+
+func TestStatsService_GetThroughput_Validation(t *testing.T) {
+	service, err := NewStatsService(&fakeStatsRepo{}, testLogger())
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if _, err := service.GetThroughput(context.Background(), maxThroughputWeeks+1, nil); !errors.Is(err, ErrStatsValidation) {
+		t.Fatalf("expected ErrStatsValidation, got %v", err)
+	}
+}
+
+func TestStatsService_GetCompletionRates(t *testing.T) {
+	repo := &fakeStatsRepo{
+		completionFn: func(_ context.Context, teamName string, _, _ time.Time, _ *time.Time) ([]*models.UserCompletion, error) {
+			if teamName != "backend" {
+				t.Fatalf("unexpected team: %q", teamName)
+			}
+			return []*models.UserCompletion{
+				{UserID: "u1", Assigned: 5, Merged: 3, Reassigned: 1, Pending: 1},
+				{UserID: "u2", Assigned: 2, Pending: 2},
+			}, nil
+		},
+	}
+	service, err := NewStatsService(repo, testLogger())
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	resp, err := service.GetCompletionRates(context.Background(), models.CompletionQuery{TeamName: "backend"})
+	if err != nil {
+		t.Fatalf("GetCompletionRates returned error: %v", err)
+	}
+	if resp.Users[0].CompletionRate == nil || *resp.Users[0].CompletionRate != 0.75 {
+		t.Fatalf("unexpected completion rate: %v", resp.Users[0].CompletionRate)
+	}
+	if resp.Users[1].CompletionRate != nil {
+		t.Fatalf("expected nil rate without resolved assignments, got %v", *resp.Users[1].CompletionRate)
+	}
+}
+
+func TestStatsService_GetLabelStats(t *testing.T) {
+	now := time.Date(2025, 3, 31, 12, 0, 0, 0, time.UTC)
+	repo := &fakeStatsRepo{
+		labelsFn: func(_ context.Context, teamName string, from, to time.Time) ([]*models.LabelStats, error) {
+			if teamName != "backend" || !to.Equal(now) || !from.Equal(now.AddDate(0, 0, -30)) {
+				t.Fatalf("unexpected args: %q %v %v", teamName, from, to)
+			}
+			return []*models.LabelStats{{Label: "bug", Open: 1}}, nil
+		},
+	}
+	service, err := NewStatsService(repo, testLogger())
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	service.now = func() time.Time { return now }
+
+	resp, err := service.GetLabelStats(context.Background(), models.LabelStatsQuery{TeamName: " backend "})
+	if err != nil {
+		t.Fatalf("GetLabelStats returned error: %v", err)
+	}
+	if resp.TeamName != "backend" || len(resp.Labels) != 1 || resp.Labels[0].Label != "bug" {
+		t.Fatalf("unexpected response: %#v", resp)
+	}
+
+	from := now
+	if _, err := service.GetLabelStats(context.Background(), models.LabelStatsQuery{From: &from}); !errors.Is(err, ErrStatsValidation) {
+		t.Fatalf("expected ErrStatsValidation, got %v", err)
+	}
+}
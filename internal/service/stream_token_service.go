@@ -0,0 +1,69 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"time"
+
+	"github.com/cloudyy74/pr-reviewer-service/internal/models"
+)
+
+type StreamTokenIssuer interface {
+	Issue(userID string) (string, time.Time)
+}
+
+type StreamTokenUserRepository interface {
+	GetUsersByIDs(context.Context, []string) ([]*models.UserWithTeam, error)
+	GetUsersByUsername(context.Context, string) ([]*models.UserWithTeam, error)
+}
+
+type StreamTokenService struct {
+	users  StreamTokenUserRepository
+	issuer StreamTokenIssuer
+	log    *slog.Logger
+}
+
+func NewStreamTokenService(users StreamTokenUserRepository, issuer StreamTokenIssuer, log *slog.Logger) (*StreamTokenService, error) {
+	if users == nil {
+		return nil, errors.New("users repository cannot be nil")
+	}
+	if issuer == nil {
+		return nil, errors.New("token issuer cannot be nil")
+	}
+	if log == nil {
+		return nil, errors.New("logger cannot be nil")
+	}
+	return &StreamTokenService{
+		users:  users,
+		issuer: issuer,
+		log:    log,
+	}, nil
+}
+
+func (s *StreamTokenService) IssueStreamToken(ctx context.Context, userID string) (*models.StreamToken, error) {
+	userID, err := resolveUserRef(ctx, s.users, userID)
+	if err != nil {
+		return nil, err
+	}
+	if userID == "" {
+		return nil, fmt.Errorf("%w: user_id is required", ErrUserValidation)
+	}
+
+	users, err := s.users.GetUsersByIDs(ctx, []string{userID})
+	if err != nil {
+		s.log.Error("get user for stream token failed", slog.Any("error", err), slog.String("user_id", userID))
+		return nil, fmt.Errorf("get user: %w", err)
+	}
+	if len(users) == 0 {
+		return nil, ErrUserNotFound
+	}
+
+	token, expiresAt := s.issuer.Issue(userID)
+	return &models.StreamToken{
+		Token:     token,
+		UserID:    userID,
+		ExpiresAt: expiresAt,
+	}, nil
+}
@@ -0,0 +1,49 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/cloudyy74/pr-reviewer-service/internal/models"
+)
+
+type fakeTokenIssuer struct{}
+
+func (fakeTokenIssuer) Issue(userID string) (string, time.Time) {
+	return "token-" + userID, time.Unix(100, 0)
+}
+
+func TestStreamTokenService_IssueStreamToken(t *testing.T) {
+	repo := &fakeUserSetRepo{
+		getByIDsFn: func(_ context.Context, ids []string) ([]*models.UserWithTeam, error) {
+			if ids[0] != "u1" {
+				return nil, nil
+			}
+			return []*models.UserWithTeam{{User: models.User{ID: "u1"}}}, nil
+		},
+		getByUsernameFn: func(context.Context, string) ([]*models.UserWithTeam, error) {
+			return []*models.UserWithTeam{{User: models.User{ID: "u1", Username: "alice"}}}, nil
+		},
+	}
+	service, err := NewStreamTokenService(repo, fakeTokenIssuer{}, testLogger())
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	token, err := service.IssueStreamToken(context.Background(), "@alice")
+	if err != nil {
+		t.Fatalf("IssueStreamToken returned err: %v", err)
+	}
+	if token.Token != "token-u1" || token.UserID != "u1" {
+		t.Fatalf("unexpected token: %+v", token)
+	}
+
+	if _, err := service.IssueStreamToken(context.Background(), "ghost"); !errors.Is(err, ErrUserNotFound) {
+		t.Fatalf("expected ErrUserNotFound, got %v", err)
+	}
+	if _, err := service.IssueStreamToken(context.Background(), " "); !errors.Is(err, ErrUserValidation) {
+		t.Fatalf("expected ErrUserValidation, got %v", err)
+	}
+}
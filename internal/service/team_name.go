@@ -0,0 +1,31 @@
+package service
+
+import (
+	"strings"
+	"unicode"
+)
+
+const (
+	TeamNamePreserve = "preserve"
+	TeamNameLower    = "lower"
+	TeamNameSlug     = "slug"
+)
+
+func (s *TeamService) canonicalTeamName(name string) string {
+	name = strings.TrimSpace(name)
+	switch s.nameNormalization {
+	case TeamNameLower:
+		return strings.ToLower(name)
+	case TeamNameSlug:
+		return slugify(name)
+	default:
+		return name
+	}
+}
+
+func slugify(name string) string {
+	fields := strings.FieldsFunc(strings.ToLower(name), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+	return strings.Join(fields, "-")
+}
@@ -0,0 +1,25 @@
+package service
+
+type TeamOption func(*TeamService)
+
+func WithTeamNameNormalization(mode string) TeamOption {
+	return func(s *TeamService) {
+		if mode != "" {
+			s.nameNormalization = mode
+		}
+	}
+}
+
+// WithTeamOutbox records team.created and member events in the outbox within
+// the transaction that changes the team.
+func WithTeamOutbox(outbox OutboxWriter) TeamOption {
+	return func(s *TeamService) {
+		s.outbox = outbox
+	}
+}
+
+func WithUniqueUsernames() TeamOption {
+	return func(s *TeamService) {
+		s.uniqueUsernames = true
+	}
+}
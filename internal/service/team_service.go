@@ -5,7 +5,9 @@ import (
 	"errors"
 	"fmt"
 	"log/slog"
+	"slices"
 	"strings"
+	"time"
 
 	"github.com/cloudyy74/pr-reviewer-service/internal/models"
 	"github.com/cloudyy74/pr-reviewer-service/internal/storage"
@@ -17,25 +19,48 @@ var (
 	ErrTeamNotFound   = errors.New("team not found")
 )
 
+const (
+	maxRequiredComponentLength  = 128
+	maxRequiredPathPrefixLength = 256
+)
+
 type TeamRepository interface {
 	CreateTeam(context.Context, string) error
 	ExistsTeam(context.Context, string) (bool, error)
+	GetTeamSettings(context.Context, string) (*models.TeamSettings, error)
+	UpsertTeamSettings(context.Context, models.TeamSettings) error
+	CountOpenPRs(context.Context, string) (int, error)
+	GetTeamStats(context.Context, string) (*models.TeamStats, error)
+	GetMembershipHistory(context.Context, models.MembershipHistoryQuery) ([]*models.MembershipChange, int, error)
 }
 
 type TeamUsersRepository interface {
 	UpsertUser(context.Context, models.User, string) error
 	GetUsersByTeam(context.Context, string) ([]*models.User, error)
-	DeactivateTeamUsers(context.Context, string) (int64, error)
+	DeactivateTeamUsers(context.Context, string) ([]string, error)
+	GetUsersByUsername(context.Context, string) ([]*models.UserWithTeam, error)
+	GetUsersByIDs(context.Context, []string) ([]*models.UserWithTeam, error)
+	AddMembershipChanges(context.Context, []models.MembershipChange) error
 }
 
 type TeamService struct {
-	tx    txManager
-	teams TeamRepository
-	users TeamUsersRepository
-	log   *slog.Logger
+	tx     txManager
+	teams  TeamRepository
+	users  TeamUsersRepository
+	outbox OutboxWriter
+	log    *slog.Logger
+
+	nameNormalization string
+	uniqueUsernames   bool
 }
 
-func NewTeamService(tx txManager, teams TeamRepository, users TeamUsersRepository, log *slog.Logger) (*TeamService, error) {
+func NewTeamService(
+	tx txManager,
+	teams TeamRepository,
+	users TeamUsersRepository,
+	log *slog.Logger,
+	opts ...TeamOption,
+) (*TeamService, error) {
 	if tx == nil {
 		return nil, errors.New("tx manager cannot be nil")
 	}
@@ -48,19 +73,29 @@ func NewTeamService(tx txManager, teams TeamRepository, users TeamUsersRepositor
 	if log == nil {
 		return nil, errors.New("logger cannot be nil")
 	}
-	return &TeamService{
-		tx:    tx,
-		users: users,
-		teams: teams,
-		log:   log,
-	}, nil
+	s := &TeamService{
+		tx:                tx,
+		users:             users,
+		teams:             teams,
+		log:               log,
+		nameNormalization: TeamNamePreserve,
+	}
+	for _, opt := range opts {
+		opt(s)
+	}
+	switch s.nameNormalization {
+	case TeamNamePreserve, TeamNameLower, TeamNameSlug:
+	default:
+		return nil, fmt.Errorf("unknown team name normalization %q", s.nameNormalization)
+	}
+	return s, nil
 }
 
 func (s *TeamService) CreateTeam(ctx context.Context, team *models.Team) (*models.Team, error) {
 	if team == nil {
 		return nil, fmt.Errorf("%w: empty body", ErrTeamValidation)
 	}
-	team.Name = strings.TrimSpace(team.Name)
+	team.Name = s.canonicalTeamName(team.Name)
 	if team.Name == "" {
 		return nil, fmt.Errorf("%w: team_name is required", ErrTeamValidation)
 	}
@@ -69,6 +104,7 @@ func (s *TeamService) CreateTeam(ctx context.Context, team *models.Team) (*model
 		team.Members = []*models.User{}
 	}
 	seen := make(map[string]struct{}, len(team.Members))
+	usernames := make(map[string]string, len(team.Members))
 	uniq := make([]*models.User, 0, len(team.Members))
 	for _, m := range team.Members {
 		if m == nil {
@@ -79,10 +115,19 @@ func (s *TeamService) CreateTeam(ctx context.Context, team *models.Team) (*model
 		if m.ID == "" || m.Username == "" {
 			return nil, fmt.Errorf("%w: member requires user_id and username", ErrTeamValidation)
 		}
+		if m.Weight != 0 {
+			if err := validateReviewWeight(m.Weight); err != nil {
+				return nil, fmt.Errorf("%w: member %s: %w", ErrTeamValidation, m.ID, err)
+			}
+		}
 		if _, ok := seen[m.ID]; ok {
 			continue
 		}
+		if id, ok := usernames[m.Username]; ok && s.uniqueUsernames && id != m.ID {
+			return nil, fmt.Errorf("%w: %q", ErrUsernameTaken, m.Username)
+		}
 		seen[m.ID] = struct{}{}
+		usernames[m.Username] = m.ID
 		uniq = append(uniq, m)
 	}
 	team.Members = uniq
@@ -95,24 +140,146 @@ func (s *TeamService) CreateTeam(ctx context.Context, team *models.Team) (*model
 			return fmt.Errorf("service create team: %w", err)
 		}
 
+		previousTeams, versions, err := s.previousTeams(ctx, team.Members)
+		if err != nil {
+			return err
+		}
+		var changes []models.MembershipChange
 		for _, m := range team.Members {
-			if err := s.users.UpsertUser(ctx, *m, team.Name); err != nil {
+			if err := s.checkUsernameFree(ctx, m); err != nil {
+				return err
+			}
+			// Without a version from the caller the member is overwritten only
+			// if nobody changed it since it was read above.
+			u := *m
+			if u.Version == 0 {
+				u.Version = versions[m.ID]
+			}
+			if err := s.users.UpsertUser(ctx, u, team.Name); err != nil {
+				if errors.Is(err, storage.ErrUserVersionChanged) {
+					return fmt.Errorf("%w: %s is no longer at version %d", ErrUserConflict, m.ID, u.Version)
+				}
 				return fmt.Errorf("service upsert user: %w", err)
 			}
+			changes = append(changes, membershipChanges(m.ID, previousTeams[m.ID], team.Name)...)
+		}
+		if err := s.users.AddMembershipChanges(ctx, changes); err != nil {
+			return fmt.Errorf("service add membership changes: %w", err)
 		}
 
-		return nil
+		return s.emitTeamCreated(ctx, team, previousTeams)
 	})
 	if err != nil {
-		s.log.Error("create team transaction failed", slog.Any("error", err))
+		if !errors.Is(err, ErrUsernameTaken) && !errors.Is(err, ErrUserConflict) {
+			s.log.Error("create team transaction failed", slog.Any("error", err))
+		}
 		return nil, fmt.Errorf("error in transcation: %w", err)
 	}
 
 	return team, nil
 }
 
+// previousTeams maps members that already exist to their current team, so
+// moves can be recorded once the members are upserted, and to their version.
+func (s *TeamService) previousTeams(ctx context.Context, members []*models.User) (map[string]string, map[string]int64, error) {
+	if len(members) == 0 {
+		return nil, nil, nil
+	}
+	ids := make([]string, 0, len(members))
+	for _, m := range members {
+		ids = append(ids, m.ID)
+	}
+	existing, err := s.users.GetUsersByIDs(ctx, ids)
+	if err != nil {
+		return nil, nil, fmt.Errorf("service get users by ids: %w", err)
+	}
+	teams := make(map[string]string, len(existing))
+	versions := make(map[string]int64, len(existing))
+	for _, u := range existing {
+		teams[u.ID] = u.TeamName
+		versions[u.ID] = u.Version
+	}
+	return teams, versions, nil
+}
+
+// membershipChanges lists the history entries for userID moving from team
+// from, empty for a new user, to team to.
+func membershipChanges(userID, from, to string) []models.MembershipChange {
+	if from == to {
+		return nil
+	}
+	joined := models.MembershipChange{TeamName: to, UserID: userID, Change: models.MembershipJoined, OtherTeam: from}
+	if from == "" {
+		return []models.MembershipChange{joined}
+	}
+	return []models.MembershipChange{
+		{TeamName: from, UserID: userID, Change: models.MembershipLeft, OtherTeam: to},
+		joined,
+	}
+}
+
+func (s *TeamService) emitTeamCreated(ctx context.Context, team *models.Team, previousTeams map[string]string) error {
+	if s.outbox == nil {
+		return nil
+	}
+	now := time.Now().UTC()
+	memberIDs := make([]string, 0, len(team.Members))
+	for _, m := range team.Members {
+		memberIDs = append(memberIDs, m.ID)
+	}
+	if err := emitOutboxEvent(ctx, s.outbox, models.TopicTeamCreated, team.Name, models.TeamEvent{
+		TeamName:   team.Name,
+		MemberIDs:  memberIDs,
+		OccurredAt: now,
+	}); err != nil {
+		return err
+	}
+	for _, m := range team.Members {
+		from := previousTeams[m.ID]
+		if from != "" {
+			if err := emitOutboxEvent(ctx, s.outbox, models.TopicMemberRemoved, from, models.TeamEvent{
+				TeamName:   from,
+				UserID:     m.ID,
+				Username:   m.Username,
+				MovedTo:    team.Name,
+				Reason:     "moved to another team",
+				OccurredAt: now,
+			}); err != nil {
+				return err
+			}
+		}
+		if err := emitOutboxEvent(ctx, s.outbox, models.TopicMemberAdded, team.Name, models.TeamEvent{
+			TeamName:   team.Name,
+			UserID:     m.ID,
+			Username:   m.Username,
+			IsActive:   &m.IsActive,
+			MovedFrom:  from,
+			OccurredAt: now,
+		}); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (s *TeamService) checkUsernameFree(ctx context.Context, m *models.User) error {
+	if !s.uniqueUsernames {
+		return nil
+	}
+	owners, err := s.users.GetUsersByUsername(ctx, m.Username)
+	if err != nil {
+		return fmt.Errorf("service get users by username: %w", err)
+	}
+	for _, owner := range owners {
+		if owner.ID != m.ID {
+			return fmt.Errorf("%w: %q", ErrUsernameTaken, m.Username)
+		}
+	}
+	return nil
+}
+
 func (s *TeamService) GetTeamUsers(ctx context.Context, teamName string) ([]*models.User, error) {
-	teamName = strings.TrimSpace(teamName)
+	teamName = s.canonicalTeamName(teamName)
 	if teamName == "" {
 		return nil, fmt.Errorf("%w: team_name is required", ErrTeamValidation)
 	}
@@ -136,7 +303,7 @@ func (s *TeamService) GetTeamUsers(ctx context.Context, teamName string) ([]*mod
 }
 
 func (s *TeamService) DeactivateTeamUsers(ctx context.Context, teamName string) (*models.TeamDeactivateResponse, error) {
-	teamName = strings.TrimSpace(teamName)
+	teamName = s.canonicalTeamName(teamName)
 	if teamName == "" {
 		return nil, fmt.Errorf("%w: team_name is required", ErrTeamValidation)
 	}
@@ -150,14 +317,25 @@ func (s *TeamService) DeactivateTeamUsers(ctx context.Context, teamName string)
 		if !exists {
 			return ErrTeamNotFound
 		}
-		count, err := s.users.DeactivateTeamUsers(ctx, teamName)
+		ids, err := s.users.DeactivateTeamUsers(ctx, teamName)
 		if err != nil {
 			s.log.Error("deactivate team users failed", slog.Any("error", err), slog.String("team", teamName))
 			return fmt.Errorf("deactivate team users: %w", err)
 		}
 		resp = &models.TeamDeactivateResponse{
 			TeamName:         teamName,
-			DeactivatedCount: int(count),
+			DeactivatedCount: len(ids),
+		}
+		now := time.Now().UTC()
+		for _, id := range ids {
+			if err := emitOutboxEvent(ctx, s.outbox, models.TopicMemberDeactivated, teamName, models.TeamEvent{
+				TeamName:   teamName,
+				UserID:     id,
+				Reason:     "team deactivated",
+				OccurredAt: now,
+			}); err != nil {
+				return err
+			}
 		}
 		return nil
 	})
@@ -173,3 +351,182 @@ func (s *TeamService) DeactivateTeamUsers(ctx context.Context, teamName string)
 
 	return resp, nil
 }
+
+func (s *TeamService) GetTeamSettings(ctx context.Context, teamName string) (*models.TeamSettings, error) {
+	teamName = s.canonicalTeamName(teamName)
+	if teamName == "" {
+		return nil, fmt.Errorf("%w: team_name is required", ErrTeamValidation)
+	}
+
+	settings, err := s.teams.GetTeamSettings(ctx, teamName)
+	if err != nil {
+		switch {
+		case errors.Is(err, storage.ErrTeamNotFound):
+			return nil, ErrTeamNotFound
+		default:
+			s.log.Error("get team settings failed", slog.Any("error", err), slog.String("team", teamName))
+			return nil, fmt.Errorf("get team settings: %w", err)
+		}
+	}
+	return settings, nil
+}
+
+func (s *TeamService) SetTeamSettings(ctx context.Context, settings *models.TeamSettings) (*models.TeamSettings, error) {
+	if settings == nil {
+		return nil, fmt.Errorf("%w: empty body", ErrTeamValidation)
+	}
+	settings.TeamName = s.canonicalTeamName(settings.TeamName)
+	if settings.TeamName == "" {
+		return nil, fmt.Errorf("%w: team_name is required", ErrTeamValidation)
+	}
+	if settings.AckTimeoutHours < 0 {
+		return nil, fmt.Errorf("%w: ack_timeout_hours cannot be negative", ErrTeamValidation)
+	}
+	if settings.ReviewSLAHours < 0 {
+		return nil, fmt.Errorf("%w: review_sla_hours cannot be negative", ErrTeamValidation)
+	}
+	fallbacks := make([]string, 0, len(settings.FallbackTeams))
+	for _, buddy := range settings.FallbackTeams {
+		buddy = s.canonicalTeamName(buddy)
+		switch {
+		case buddy == "":
+			return nil, fmt.Errorf("%w: fallback_teams cannot contain empty names", ErrTeamValidation)
+		case buddy == settings.TeamName:
+			return nil, fmt.Errorf("%w: team cannot be its own fallback", ErrTeamValidation)
+		case !slices.Contains(fallbacks, buddy):
+			fallbacks = append(fallbacks, buddy)
+		}
+	}
+	settings.FallbackTeams = fallbacks
+	rules := make([]models.RequiredReviewerRule, 0, len(settings.RequiredReviewers))
+	for _, rule := range settings.RequiredReviewers {
+		rule = normalizeRequiredReviewerRule(rule)
+		switch {
+		case rule.UserID == "":
+			return nil, fmt.Errorf("%w: required_reviewers need a user_id", ErrTeamValidation)
+		case rule.Component == "" && rule.PathPrefix == "":
+			return nil, fmt.Errorf("%w: required reviewer %s needs a component or a path_prefix", ErrTeamValidation, rule.UserID)
+		case len(rule.Component) > maxRequiredComponentLength || len(rule.PathPrefix) > maxRequiredPathPrefixLength:
+			return nil, fmt.Errorf("%w: component must be at most %d and path_prefix at most %d characters", ErrTeamValidation, maxRequiredComponentLength, maxRequiredPathPrefixLength)
+		case !slices.Contains(rules, rule):
+			rules = append(rules, rule)
+		}
+	}
+	settings.RequiredReviewers = rules
+
+	err := s.tx.Run(ctx, func(ctx context.Context) error {
+		exists, err := s.teams.ExistsTeam(ctx, settings.TeamName)
+		if err != nil {
+			return fmt.Errorf("cant check is team exist: %w", err)
+		}
+		if !exists {
+			return ErrTeamNotFound
+		}
+		for _, buddy := range settings.FallbackTeams {
+			exists, err := s.teams.ExistsTeam(ctx, buddy)
+			if err != nil {
+				return fmt.Errorf("cant check is team exist: %w", err)
+			}
+			if !exists {
+				return fmt.Errorf("%w: fallback team %s does not exist", ErrTeamValidation, buddy)
+			}
+		}
+		if err := s.teams.UpsertTeamSettings(ctx, *settings); err != nil {
+			if errors.Is(err, storage.ErrUserNotFound) {
+				return fmt.Errorf("%w: %w", ErrTeamValidation, err)
+			}
+			return fmt.Errorf("upsert team settings: %w", err)
+		}
+		return nil
+	})
+	if err != nil {
+		switch {
+		case errors.Is(err, ErrTeamNotFound), errors.Is(err, ErrTeamValidation):
+			return nil, err
+		default:
+			s.log.Error("set team settings transaction failed", slog.Any("error", err))
+			return nil, fmt.Errorf("error in transcation: %w", err)
+		}
+	}
+
+	return settings, nil
+}
+
+// normalizeRequiredReviewerRule puts a rule in the form CreatePR matches
+// against: components are compared lowercased and paths without a leading
+// slash.
+func normalizeRequiredReviewerRule(rule models.RequiredReviewerRule) models.RequiredReviewerRule {
+	return models.RequiredReviewerRule{
+		Component:  strings.ToLower(strings.TrimSpace(rule.Component)),
+		PathPrefix: strings.TrimLeft(strings.TrimSpace(rule.PathPrefix), "/"),
+		UserID:     strings.TrimSpace(rule.UserID),
+	}
+}
+
+func (s *TeamService) CountOpenPRs(ctx context.Context, teamName string) (int, error) {
+	teamName = s.canonicalTeamName(teamName)
+	if teamName == "" {
+		return 0, fmt.Errorf("%w: team_name is required", ErrTeamValidation)
+	}
+
+	count, err := s.teams.CountOpenPRs(ctx, teamName)
+	if err != nil {
+		s.log.Error("count team open prs failed", slog.Any("error", err), slog.String("team", teamName))
+		return 0, fmt.Errorf("count team open prs: %w", err)
+	}
+	return count, nil
+}
+
+func (s *TeamService) GetTeamStats(ctx context.Context, teamName string) (*models.TeamStats, error) {
+	teamName = s.canonicalTeamName(teamName)
+	if teamName == "" {
+		return nil, fmt.Errorf("%w: team_name is required", ErrTeamValidation)
+	}
+
+	stats, err := s.teams.GetTeamStats(ctx, teamName)
+	if err != nil {
+		s.log.Error("get team stats failed", slog.Any("error", err), slog.String("team", teamName))
+		return nil, fmt.Errorf("get team stats: %w", err)
+	}
+	return stats, nil
+}
+
+// GetMembershipHistory returns who joined and left the team, newest first.
+func (s *TeamService) GetMembershipHistory(ctx context.Context, q models.MembershipHistoryQuery) (*models.MembershipHistoryResponse, error) {
+	q.TeamName = s.canonicalTeamName(q.TeamName)
+	if q.TeamName == "" {
+		return nil, fmt.Errorf("%w: team_name is required", ErrTeamValidation)
+	}
+	if q.From != nil && q.To != nil && !q.From.Before(*q.To) {
+		return nil, fmt.Errorf("%w: from must be before to", ErrTeamValidation)
+	}
+	if q.Limit < 0 || q.Offset < 0 {
+		return nil, fmt.Errorf("%w: limit and offset cannot be negative", ErrTeamValidation)
+	}
+	if q.Limit == 0 {
+		q.Limit = defaultHistoryLimit
+	}
+	q.Limit = min(q.Limit, maxHistoryLimit)
+
+	exists, err := s.teams.ExistsTeam(ctx, q.TeamName)
+	if err != nil {
+		s.log.Error("exists team check failed", slog.Any("error", err), slog.String("team", q.TeamName))
+		return nil, fmt.Errorf("cant check is team exist: %w", err)
+	}
+	if !exists {
+		return nil, ErrTeamNotFound
+	}
+
+	changes, total, err := s.teams.GetMembershipHistory(ctx, q)
+	if err != nil {
+		s.log.Error("get membership history failed", slog.Any("error", err), slog.String("team", q.TeamName))
+		return nil, fmt.Errorf("get membership history: %w", err)
+	}
+	return &models.MembershipHistoryResponse{
+		TeamName: q.TeamName,
+		Changes:  changes,
+		Total:    total,
+		Limit:    q.Limit,
+		Offset:   q.Offset,
+	}, nil
+}
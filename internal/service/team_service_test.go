@@ -5,6 +5,8 @@ import (
 	"errors"
 	"io"
 	"log/slog"
+	"slices"
+	"strings"
 	"testing"
 
 	"github.com/cloudyy74/pr-reviewer-service/internal/models"
@@ -23,8 +25,17 @@ func (f fakeTeamTx) Run(ctx context.Context, fn func(context.Context) error) err
 }
 
 type fakeTeamsRepo struct {
-	createFn func(context.Context, string) error
-	existsFn func(context.Context, string) (bool, error)
+	createFn      func(context.Context, string) error
+	existsFn      func(context.Context, string) (bool, error)
+	getSettingsFn func(context.Context, string) (*models.TeamSettings, error)
+	upsertSetFn   func(context.Context, models.TeamSettings) error
+	countOpenFn   func(context.Context, string) (int, error)
+	getStatsFn    func(context.Context, string) (*models.TeamStats, error)
+	historyFn     func(context.Context, models.MembershipHistoryQuery) ([]*models.MembershipChange, int, error)
+}
+
+func (f *fakeTeamsRepo) GetMembershipHistory(ctx context.Context, q models.MembershipHistoryQuery) ([]*models.MembershipChange, int, error) {
+	return f.historyFn(ctx, q)
 }
 
 func (f *fakeTeamsRepo) CreateTeam(ctx context.Context, name string) error {
@@ -41,10 +52,53 @@ func (f *fakeTeamsRepo) ExistsTeam(ctx context.Context, name string) (bool, erro
 	return false, nil
 }
 
+func (f *fakeTeamsRepo) GetTeamSettings(ctx context.Context, name string) (*models.TeamSettings, error) {
+	if f.getSettingsFn != nil {
+		return f.getSettingsFn(ctx, name)
+	}
+	return &models.TeamSettings{TeamName: name}, nil
+}
+
+func (f *fakeTeamsRepo) UpsertTeamSettings(ctx context.Context, settings models.TeamSettings) error {
+	if f.upsertSetFn != nil {
+		return f.upsertSetFn(ctx, settings)
+	}
+	return nil
+}
+
+func (f *fakeTeamsRepo) CountOpenPRs(ctx context.Context, name string) (int, error) {
+	if f.countOpenFn != nil {
+		return f.countOpenFn(ctx, name)
+	}
+	return 0, nil
+}
+
+func (f *fakeTeamsRepo) GetTeamStats(ctx context.Context, name string) (*models.TeamStats, error) {
+	if f.getStatsFn != nil {
+		return f.getStatsFn(ctx, name)
+	}
+	return &models.TeamStats{}, nil
+}
+
 type fakeTeamUsersRepo struct {
 	upsertFn     func(context.Context, models.User, string) error
 	getUsersFn   func(context.Context, string) ([]*models.User, error)
-	deactivateFn func(context.Context, string) (int64, error)
+	deactivateFn func(context.Context, string) ([]string, error)
+	getByNameFn  func(context.Context, string) ([]*models.UserWithTeam, error)
+	getByIDsFn   func(context.Context, []string) ([]*models.UserWithTeam, error)
+	changes      []models.MembershipChange
+}
+
+func (f *fakeTeamUsersRepo) AddMembershipChanges(_ context.Context, changes []models.MembershipChange) error {
+	f.changes = append(f.changes, changes...)
+	return nil
+}
+
+func (f *fakeTeamUsersRepo) GetUsersByIDs(ctx context.Context, ids []string) ([]*models.UserWithTeam, error) {
+	if f.getByIDsFn != nil {
+		return f.getByIDsFn(ctx, ids)
+	}
+	return nil, nil
 }
 
 func (f *fakeTeamUsersRepo) UpsertUser(ctx context.Context, u models.User, teamName string) error {
@@ -61,11 +115,18 @@ func (f *fakeTeamUsersRepo) GetUsersByTeam(ctx context.Context, teamName string)
 	return nil, nil
 }
 
-func (f *fakeTeamUsersRepo) DeactivateTeamUsers(ctx context.Context, teamName string) (int64, error) {
+func (f *fakeTeamUsersRepo) GetUsersByUsername(ctx context.Context, username string) ([]*models.UserWithTeam, error) {
+	if f.getByNameFn != nil {
+		return f.getByNameFn(ctx, username)
+	}
+	return nil, nil
+}
+
+func (f *fakeTeamUsersRepo) DeactivateTeamUsers(ctx context.Context, teamName string) ([]string, error) {
 	if f.deactivateFn != nil {
 		return f.deactivateFn(ctx, teamName)
 	}
-	return 0, nil
+	return nil, nil
 }
 
 func teamTestLogger() *slog.Logger {
@@ -128,6 +189,38 @@ func TestTeamService_CreateTeam_Success(t *testing.T) {
 	}
 }
 
+func TestTeamService_CreateTeam_UserChangedConcurrently(t *testing.T) {
+	var gotVersion int64
+	service, err := NewTeamService(
+		fakeTeamTx{},
+		&fakeTeamsRepo{createFn: func(context.Context, string) error { return nil }},
+		&fakeTeamUsersRepo{
+			getByIDsFn: func(context.Context, []string) ([]*models.UserWithTeam, error) {
+				return []*models.UserWithTeam{{User: models.User{ID: "u1", Version: 7}, TeamName: "frontend"}}, nil
+			},
+			upsertFn: func(_ context.Context, u models.User, _ string) error {
+				gotVersion = u.Version
+				return storage.ErrUserVersionChanged
+			},
+		},
+		teamTestLogger(),
+	)
+	if err != nil {
+		t.Fatalf("NewTeamService returned err: %v", err)
+	}
+
+	_, err = service.CreateTeam(context.Background(), &models.Team{
+		Name:    "backend",
+		Members: []*models.User{{ID: "u1", Username: "alice", IsActive: true}},
+	})
+	if !errors.Is(err, ErrUserConflict) {
+		t.Fatalf("expected ErrUserConflict, got %v", err)
+	}
+	if gotVersion != 7 {
+		t.Fatalf("expected upsert to compare against version 7, got %d", gotVersion)
+	}
+}
+
 func TestTeamService_CreateTeam_TeamExists(t *testing.T) {
 	service, err := NewTeamService(
 		fakeTeamTx{},
@@ -152,6 +245,126 @@ func TestTeamService_CreateTeam_TeamExists(t *testing.T) {
 	}
 }
 
+func TestNewTeamService_UnknownNameNormalization(t *testing.T) {
+	_, err := NewTeamService(
+		fakeTeamTx{},
+		&fakeTeamsRepo{},
+		&fakeTeamUsersRepo{},
+		teamTestLogger(),
+		WithTeamNameNormalization("upper"),
+	)
+	if err == nil {
+		t.Fatalf("expected error for unknown normalization mode")
+	}
+}
+
+func TestTeamService_CreateTeam_NameNormalization(t *testing.T) {
+	cases := []struct {
+		mode string
+		in   string
+		want string
+	}{
+		{mode: TeamNamePreserve, in: " Backend ", want: "Backend"},
+		{mode: TeamNameLower, in: " Backend ", want: "backend"},
+		{mode: TeamNameSlug, in: "  Backend Team / Core!", want: "backend-team-core"},
+	}
+	for _, tc := range cases {
+		var createdTeam string
+		service, err := NewTeamService(
+			fakeTeamTx{},
+			&fakeTeamsRepo{
+				createFn: func(_ context.Context, name string) error {
+					createdTeam = name
+					return nil
+				},
+			},
+			&fakeTeamUsersRepo{},
+			teamTestLogger(),
+			WithTeamNameNormalization(tc.mode),
+		)
+		if err != nil {
+			t.Fatalf("NewTeamService returned err: %v", err)
+		}
+
+		team, err := service.CreateTeam(context.Background(), &models.Team{Name: tc.in})
+		if err != nil {
+			t.Fatalf("%s: CreateTeam returned err: %v", tc.mode, err)
+		}
+		if team.Name != tc.want || createdTeam != tc.want {
+			t.Fatalf("%s: expected %q, got %q (repo %q)", tc.mode, tc.want, team.Name, createdTeam)
+		}
+	}
+}
+
+func TestTeamService_GetTeamUsers_SlugName(t *testing.T) {
+	var requested string
+	service, err := NewTeamService(
+		fakeTeamTx{},
+		&fakeTeamsRepo{
+			existsFn: func(_ context.Context, name string) (bool, error) {
+				requested = name
+				return true, nil
+			},
+		},
+		&fakeTeamUsersRepo{},
+		teamTestLogger(),
+		WithTeamNameNormalization(TeamNameSlug),
+	)
+	if err != nil {
+		t.Fatalf("NewTeamService returned err: %v", err)
+	}
+
+	if _, err := service.GetTeamUsers(context.Background(), "Backend Team"); err != nil {
+		t.Fatalf("GetTeamUsers returned err: %v", err)
+	}
+	if requested != "backend-team" {
+		t.Fatalf("expected slug lookup, got %q", requested)
+	}
+}
+
+func TestTeamService_CreateTeam_UniqueUsernames(t *testing.T) {
+	service, err := NewTeamService(
+		fakeTeamTx{},
+		&fakeTeamsRepo{},
+		&fakeTeamUsersRepo{
+			getByNameFn: func(_ context.Context, username string) ([]*models.UserWithTeam, error) {
+				if username == "alice" {
+					return []*models.UserWithTeam{{User: models.User{ID: "u9", Username: "alice"}}}, nil
+				}
+				return nil, nil
+			},
+		},
+		teamTestLogger(),
+		WithUniqueUsernames(),
+	)
+	if err != nil {
+		t.Fatalf("NewTeamService returned err: %v", err)
+	}
+
+	_, err = service.CreateTeam(context.Background(), &models.Team{
+		Name:    "backend",
+		Members: []*models.User{{ID: "u1", Username: "alice"}},
+	})
+	if !errors.Is(err, ErrUsernameTaken) {
+		t.Fatalf("expected ErrUsernameTaken, got %v", err)
+	}
+
+	_, err = service.CreateTeam(context.Background(), &models.Team{
+		Name:    "frontend",
+		Members: []*models.User{{ID: "u2", Username: "bob"}, {ID: "u3", Username: "bob"}},
+	})
+	if !errors.Is(err, ErrUsernameTaken) {
+		t.Fatalf("expected ErrUsernameTaken for duplicate in request, got %v", err)
+	}
+
+	if _, err := service.CreateTeam(context.Background(), &models.Team{
+		Name:    "platform",
+		Members: []*models.User{{ID: "u9", Username: "alice"}},
+	}); err != nil {
+		t.Fatalf("CreateTeam returned err for the username owner: %v", err)
+	}
+}
+
 func TestTeamService_CreateTeam_Validation(t *testing.T) {
 	service, err := NewTeamService(
 		fakeTeamTx{},
@@ -235,7 +448,7 @@ func TestTeamService_DeactivateTeamUsers_Success(t *testing.T) {
 			existsFn: func(context.Context, string) (bool, error) { return true, nil },
 		},
 		&fakeTeamUsersRepo{
-			deactivateFn: func(context.Context, string) (int64, error) { return 4, nil },
+			deactivateFn: func(context.Context, string) ([]string, error) { return []string{"u1", "u2", "u3", "u4"}, nil },
 		},
 		teamTestLogger(),
 	)
@@ -287,7 +500,7 @@ func TestTeamService_DeactivateTeamUsers_Error(t *testing.T) {
 			existsFn: func(context.Context, string) (bool, error) { return true, nil },
 		},
 		&fakeTeamUsersRepo{
-			deactivateFn: func(context.Context, string) (int64, error) { return 0, errors.New("db err") },
+			deactivateFn: func(context.Context, string) ([]string, error) { return nil, errors.New("db err") },
 		},
 		teamTestLogger(),
 	)
@@ -322,3 +535,321 @@ func TestTeamService_GetTeamUsers_Validation(t *testing.T) {
 		t.Fatalf("expected ErrTeamValidation, got %v", err)
 	}
 }
+
+func TestTeamService_SetTeamSettings_Success(t *testing.T) {
+	var saved models.TeamSettings
+	service, err := NewTeamService(
+		fakeTeamTx{},
+		&fakeTeamsRepo{
+			existsFn: func(context.Context, string) (bool, error) { return true, nil },
+			upsertSetFn: func(_ context.Context, settings models.TeamSettings) error {
+				saved = settings
+				return nil
+			},
+		},
+		&fakeTeamUsersRepo{},
+		teamTestLogger(),
+	)
+	if err != nil {
+		t.Fatalf("NewTeamService returned err: %v", err)
+	}
+
+	settings, err := service.SetTeamSettings(context.Background(), &models.TeamSettings{TeamName: " backend ", StrictDuplicateCheck: true})
+	if err != nil {
+		t.Fatalf("SetTeamSettings returned err: %v", err)
+	}
+	if settings.TeamName != "backend" || saved.TeamName != "backend" || !saved.StrictDuplicateCheck {
+		t.Fatalf("unexpected saved settings: %#v", saved)
+	}
+}
+
+func TestTeamService_SetTeamSettings_FallbackTeams(t *testing.T) {
+	var saved models.TeamSettings
+	service, err := NewTeamService(
+		fakeTeamTx{},
+		&fakeTeamsRepo{
+			existsFn: func(_ context.Context, name string) (bool, error) { return name != "ghost", nil },
+			upsertSetFn: func(_ context.Context, settings models.TeamSettings) error {
+				saved = settings
+				return nil
+			},
+		},
+		&fakeTeamUsersRepo{},
+		teamTestLogger(),
+	)
+	if err != nil {
+		t.Fatalf("NewTeamService returned err: %v", err)
+	}
+
+	ctx := context.Background()
+	if _, err := service.SetTeamSettings(ctx, &models.TeamSettings{TeamName: "backend", FallbackTeams: []string{" platform", "frontend", "platform "}}); err != nil {
+		t.Fatalf("SetTeamSettings returned err: %v", err)
+	}
+	if len(saved.FallbackTeams) != 2 || saved.FallbackTeams[0] != "platform" || saved.FallbackTeams[1] != "frontend" {
+		t.Fatalf("unexpected fallback teams: %v", saved.FallbackTeams)
+	}
+	for _, fallbacks := range [][]string{{"backend"}, {""}, {"ghost"}} {
+		_, err := service.SetTeamSettings(ctx, &models.TeamSettings{TeamName: "backend", FallbackTeams: fallbacks})
+		if !errors.Is(err, ErrTeamValidation) {
+			t.Fatalf("expected ErrTeamValidation for %q, got %v", fallbacks, err)
+		}
+	}
+}
+
+func TestTeamService_SetTeamSettings_RequiredReviewers(t *testing.T) {
+	var saved models.TeamSettings
+	service, err := NewTeamService(
+		fakeTeamTx{},
+		&fakeTeamsRepo{
+			existsFn: func(context.Context, string) (bool, error) { return true, nil },
+			upsertSetFn: func(_ context.Context, settings models.TeamSettings) error {
+				saved = settings
+				return nil
+			},
+		},
+		&fakeTeamUsersRepo{},
+		teamTestLogger(),
+	)
+	if err != nil {
+		t.Fatalf("NewTeamService returned err: %v", err)
+	}
+
+	ctx := context.Background()
+	_, err = service.SetTeamSettings(ctx, &models.TeamSettings{TeamName: "backend", RequiredReviewers: []models.RequiredReviewerRule{
+		{Component: " Payments ", UserID: " u1 "},
+		{Component: "payments", UserID: "u1"},
+		{PathPrefix: "/internal/billing/", UserID: "u2"},
+	}})
+	if err != nil {
+		t.Fatalf("SetTeamSettings returned err: %v", err)
+	}
+	want := []models.RequiredReviewerRule{
+		{Component: "payments", UserID: "u1"},
+		{PathPrefix: "internal/billing/", UserID: "u2"},
+	}
+	if !slices.Equal(saved.RequiredReviewers, want) {
+		t.Fatalf("unexpected rules: %+v", saved.RequiredReviewers)
+	}
+	for _, rule := range []models.RequiredReviewerRule{
+		{Component: "payments"},
+		{UserID: "u1"},
+		{Component: strings.Repeat("c", 129), UserID: "u1"},
+	} {
+		_, err := service.SetTeamSettings(ctx, &models.TeamSettings{TeamName: "backend", RequiredReviewers: []models.RequiredReviewerRule{rule}})
+		if !errors.Is(err, ErrTeamValidation) {
+			t.Fatalf("expected ErrTeamValidation for %+v, got %v", rule, err)
+		}
+	}
+}
+
+func TestTeamService_SetTeamSettings_NotFound(t *testing.T) {
+	service, err := NewTeamService(
+		fakeTeamTx{},
+		&fakeTeamsRepo{
+			existsFn: func(context.Context, string) (bool, error) { return false, nil },
+		},
+		&fakeTeamUsersRepo{},
+		teamTestLogger(),
+	)
+	if err != nil {
+		t.Fatalf("NewTeamService returned err: %v", err)
+	}
+
+	_, err = service.SetTeamSettings(context.Background(), &models.TeamSettings{TeamName: "backend"})
+	if !errors.Is(err, ErrTeamNotFound) {
+		t.Fatalf("expected ErrTeamNotFound, got %v", err)
+	}
+}
+
+func TestTeamService_GetTeamSettings_NotFound(t *testing.T) {
+	service, err := NewTeamService(
+		fakeTeamTx{},
+		&fakeTeamsRepo{
+			getSettingsFn: func(context.Context, string) (*models.TeamSettings, error) {
+				return nil, storage.ErrTeamNotFound
+			},
+		},
+		&fakeTeamUsersRepo{},
+		teamTestLogger(),
+	)
+	if err != nil {
+		t.Fatalf("NewTeamService returned err: %v", err)
+	}
+
+	_, err = service.GetTeamSettings(context.Background(), "backend")
+	if !errors.Is(err, ErrTeamNotFound) {
+		t.Fatalf("expected ErrTeamNotFound, got %v", err)
+	}
+}
+
+func TestTeamService_SetTeamSettings_NegativeAckTimeout(t *testing.T) {
+	service, err := NewTeamService(fakeTeamTx{}, &fakeTeamsRepo{}, &fakeTeamUsersRepo{}, teamTestLogger())
+	if err != nil {
+		t.Fatalf("NewTeamService returned err: %v", err)
+	}
+
+	_, err = service.SetTeamSettings(context.Background(), &models.TeamSettings{TeamName: "backend", AckTimeoutHours: -1})
+	if !errors.Is(err, ErrTeamValidation) {
+		t.Fatalf("expected ErrTeamValidation, got %v", err)
+	}
+	_, err = service.SetTeamSettings(context.Background(), &models.TeamSettings{TeamName: "backend", ReviewSLAHours: -1})
+	if !errors.Is(err, ErrTeamValidation) {
+		t.Fatalf("expected ErrTeamValidation for review_sla_hours, got %v", err)
+	}
+}
+
+func TestTeamService_CountOpenPRs(t *testing.T) {
+	service, err := NewTeamService(
+		fakeTeamTx{},
+		&fakeTeamsRepo{
+			countOpenFn: func(_ context.Context, name string) (int, error) {
+				if name != "backend" {
+					t.Fatalf("unexpected team name %q", name)
+				}
+				return 3, nil
+			},
+		},
+		&fakeTeamUsersRepo{},
+		teamTestLogger(),
+	)
+	if err != nil {
+		t.Fatalf("NewTeamService returned err: %v", err)
+	}
+
+	count, err := service.CountOpenPRs(context.Background(), " backend ")
+	if err != nil {
+		t.Fatalf("CountOpenPRs returned err: %v", err)
+	}
+	if count != 3 {
+		t.Fatalf("expected 3, got %d", count)
+	}
+}
+
+func TestTeamService_GetTeamStats_Validation(t *testing.T) {
+	service, err := NewTeamService(fakeTeamTx{}, &fakeTeamsRepo{}, &fakeTeamUsersRepo{}, teamTestLogger())
+	if err != nil {
+		t.Fatalf("NewTeamService returned err: %v", err)
+	}
+
+	_, err = service.GetTeamStats(context.Background(), " ")
+	if !errors.Is(err, ErrTeamValidation) {
+		t.Fatalf("expected ErrTeamValidation, got %v", err)
+	}
+}
+
+func TestTeamService_CreateTeam_WritesMembershipEvents(t *testing.T) {
+	outbox := &fakeOutboxWriter{}
+	service, err := NewTeamService(
+		fakeTeamTx{},
+		&fakeTeamsRepo{},
+		&fakeTeamUsersRepo{
+			getByIDsFn: func(context.Context, []string) ([]*models.UserWithTeam, error) {
+				return []*models.UserWithTeam{{User: models.User{ID: "u2"}, TeamName: "frontend"}}, nil
+			},
+		},
+		teamTestLogger(),
+		WithTeamOutbox(outbox),
+	)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	_, err = service.CreateTeam(context.Background(), &models.Team{
+		Name: "backend",
+		Members: []*models.User{
+			{ID: "u1", Username: "alice", IsActive: true},
+			{ID: "u2", Username: "bob", IsActive: true},
+		},
+	})
+	if err != nil {
+		t.Fatalf("CreateTeam returned err: %v", err)
+	}
+	wantTopics := []string{models.TopicTeamCreated, models.TopicMemberAdded, models.TopicMemberRemoved, models.TopicMemberAdded}
+	wantAggregates := []string{"backend", "backend", "frontend", "backend"}
+	if !slices.Equal(outbox.topics, wantTopics) || !slices.Equal(outbox.aggregates, wantAggregates) {
+		t.Fatalf("unexpected events %v %v", outbox.topics, outbox.aggregates)
+	}
+}
+
+func TestTeamService_DeactivateTeamUsers_WritesEvents(t *testing.T) {
+	outbox := &fakeOutboxWriter{}
+	service, err := NewTeamService(
+		fakeTeamTx{},
+		&fakeTeamsRepo{
+			existsFn: func(context.Context, string) (bool, error) { return true, nil },
+		},
+		&fakeTeamUsersRepo{
+			deactivateFn: func(context.Context, string) ([]string, error) { return []string{"u1", "u2"}, nil },
+		},
+		teamTestLogger(),
+		WithTeamOutbox(outbox),
+	)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if _, err := service.DeactivateTeamUsers(context.Background(), "backend"); err != nil {
+		t.Fatalf("DeactivateTeamUsers returned err: %v", err)
+	}
+	if !slices.Equal(outbox.topics, []string{models.TopicMemberDeactivated, models.TopicMemberDeactivated}) {
+		t.Fatalf("unexpected events %v", outbox.topics)
+	}
+}
+
+func TestTeamService_CreateTeam_RecordsMembershipHistory(t *testing.T) {
+	users := &fakeTeamUsersRepo{
+		getByIDsFn: func(context.Context, []string) ([]*models.UserWithTeam, error) {
+			return []*models.UserWithTeam{{User: models.User{ID: "u2"}, TeamName: "frontend"}}, nil
+		},
+	}
+	service, err := NewTeamService(fakeTeamTx{}, &fakeTeamsRepo{}, users, teamTestLogger())
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	_, err = service.CreateTeam(context.Background(), &models.Team{
+		Name: "backend",
+		Members: []*models.User{
+			{ID: "u1", Username: "alice", IsActive: true},
+			{ID: "u2", Username: "bob", IsActive: true},
+		},
+	})
+	if err != nil {
+		t.Fatalf("CreateTeam returned err: %v", err)
+	}
+	want := []models.MembershipChange{
+		{TeamName: "backend", UserID: "u1", Change: models.MembershipJoined},
+		{TeamName: "frontend", UserID: "u2", Change: models.MembershipLeft, OtherTeam: "backend"},
+		{TeamName: "backend", UserID: "u2", Change: models.MembershipJoined, OtherTeam: "frontend"},
+	}
+	if !slices.Equal(users.changes, want) {
+		t.Fatalf("unexpected membership changes %+v", users.changes)
+	}
+}
+
+func TestTeamService_GetMembershipHistory(t *testing.T) {
+	teams := &fakeTeamsRepo{
+		existsFn: func(_ context.Context, name string) (bool, error) { return name == "backend", nil },
+		historyFn: func(_ context.Context, q models.MembershipHistoryQuery) ([]*models.MembershipChange, int, error) {
+			if q.Limit != defaultHistoryLimit {
+				t.Fatalf("expected default limit, got %d", q.Limit)
+			}
+			return []*models.MembershipChange{{TeamName: q.TeamName, UserID: "u1", Change: models.MembershipJoined}}, 1, nil
+		},
+	}
+	service, err := NewTeamService(fakeTeamTx{}, teams, &fakeTeamUsersRepo{}, teamTestLogger())
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	resp, err := service.GetMembershipHistory(context.Background(), models.MembershipHistoryQuery{TeamName: " backend "})
+	if err != nil {
+		t.Fatalf("GetMembershipHistory returned err: %v", err)
+	}
+	if resp.TeamName != "backend" || resp.Total != 1 || len(resp.Changes) != 1 {
+		t.Fatalf("unexpected response %+v", resp)
+	}
+	if _, err := service.GetMembershipHistory(context.Background(), models.MembershipHistoryQuery{TeamName: "ghost"}); !errors.Is(err, ErrTeamNotFound) {
+		t.Fatalf("expected ErrTeamNotFound, got %v", err)
+	}
+	if _, err := service.GetMembershipHistory(context.Background(), models.MembershipHistoryQuery{TeamName: "backend", Limit: -1}); !errors.Is(err, ErrTeamValidation) {
+		t.Fatalf("expected ErrTeamValidation, got %v", err)
+	}
+}
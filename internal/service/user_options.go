@@ -0,0 +1,20 @@
+package service
+
+type UserOption func(*UserService)
+
+// WithReviewReassigner makes deactivation hand the user's open reviews to
+// other active teammates in the same transaction, and activation top up
+// understaffed pull requests of the user's team.
+func WithReviewReassigner(r ReviewReassigner) UserOption {
+	return func(s *UserService) {
+		s.reassigner = r
+	}
+}
+
+// WithUserOutbox records member.deactivated in the outbox when an active user
+// is deactivated.
+func WithUserOutbox(outbox OutboxWriter) UserOption {
+	return func(s *UserService) {
+		s.outbox = outbox
+	}
+}
@@ -0,0 +1,81 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"strings"
+
+	"github.com/cloudyy74/pr-reviewer-service/internal/models"
+)
+
+const usernameRefPrefix = "@"
+
+var (
+	ErrUsernameAmbiguous = errors.New("username matches several users")
+	ErrUsernameTaken     = errors.New("username already taken")
+)
+
+type usernameFinder interface {
+	GetUsersByUsername(ctx context.Context, username string) ([]*models.UserWithTeam, error)
+}
+
+func findUserByUsername(ctx context.Context, users usernameFinder, username string) (*models.UserWithTeam, error) {
+	found, err := users.GetUsersByUsername(ctx, username)
+	if err != nil {
+		return nil, fmt.Errorf("get users by username: %w", err)
+	}
+	switch len(found) {
+	case 0:
+		return nil, fmt.Errorf("username %q: %w", username, ErrUserNotFound)
+	case 1:
+		return found[0], nil
+	default:
+		return nil, fmt.Errorf("%w: %q", ErrUsernameAmbiguous, username)
+	}
+}
+
+func resolveUserRef(ctx context.Context, users usernameFinder, ref string) (string, error) {
+	ref = strings.TrimSpace(ref)
+	username, ok := strings.CutPrefix(ref, usernameRefPrefix)
+	if !ok {
+		return ref, nil
+	}
+	username = strings.TrimSpace(username)
+	if username == "" {
+		return "", nil
+	}
+	u, err := findUserByUsername(ctx, users, username)
+	if err != nil {
+		return "", err
+	}
+	return u.ID, nil
+}
+
+func (s *PRService) resolveUserRef(ctx context.Context, ref string) (string, error) {
+	id, err := resolveUserRef(ctx, s.users, ref)
+	if err != nil && !errors.Is(err, ErrUserNotFound) && !errors.Is(err, ErrUsernameAmbiguous) {
+		s.log.Error("resolve username failed", slog.Any("error", err))
+	}
+	return id, err
+}
+
+// ResolveUserRef returns the user id behind ref, which is either an id or an
+// @username. Handlers use it to compare the target of a request with the
+// caller before acting on it.
+func (s *AuthService) ResolveUserRef(ctx context.Context, ref string) (string, error) {
+	id, err := resolveUserRef(ctx, s.users, ref)
+	if err != nil && !errors.Is(err, ErrUserNotFound) && !errors.Is(err, ErrUsernameAmbiguous) {
+		s.log.Error("resolve username failed", slog.Any("error", err))
+	}
+	return id, err
+}
+
+func (s *UserService) resolveUserRef(ctx context.Context, ref string) (string, error) {
+	id, err := resolveUserRef(ctx, s.users, ref)
+	if err != nil && !errors.Is(err, ErrUserNotFound) && !errors.Is(err, ErrUsernameAmbiguous) {
+		s.log.Error("resolve username failed", slog.Any("error", err))
+	}
+	return id, err
+}
@@ -6,27 +6,58 @@ import (
 	"fmt"
 	"log/slog"
 	"strings"
+	"time"
 
 	"github.com/cloudyy74/pr-reviewer-service/internal/models"
 	"github.com/cloudyy74/pr-reviewer-service/internal/storage"
 )
 
+const (
+	maxDigestWindowMinutes = 24 * 60
+	// MaxBatchGetUsers bounds how many ids one BatchGetUsers call may ask
+	// for, so a single request cannot build an unbounded query.
+	MaxBatchGetUsers = 100
+
+	maxAbsenceReasonLength = 256
+	// MaxReviewWeight bounds how strongly a user can be favoured by
+	// weighted reviewer selection; the default weight is 1.
+	MaxReviewWeight = 100
+)
+
 var (
-	ErrUserValidation = errors.New("validation error")
-	ErrUserNotFound   = errors.New("user not found")
+	ErrUserValidation  = errors.New("validation error")
+	ErrUserNotFound    = errors.New("user not found")
+	ErrUserConflict    = errors.New("user was changed concurrently")
+	ErrAbsenceNotFound = errors.New("absence not found")
 )
 
 type UserRepository interface {
-	SetUserActive(context.Context, string, bool) (*models.UserWithTeam, error)
+	SetUserActive(ctx context.Context, userID string, isActive bool, version int64) (*models.UserWithTeam, error)
+	SetUserWeight(ctx context.Context, userID string, weight float64, version int64) (*models.UserWithTeam, error)
+	SetUserWorkingHours(ctx context.Context, userID, timezone string, start, end *int, version int64) (*models.UserWithTeam, error)
+	GetUsersByIDs(context.Context, []string) ([]*models.UserWithTeam, error)
+	GetUsersByUsername(context.Context, string) ([]*models.UserWithTeam, error)
+	GetNotificationSettings(context.Context, string) (*models.NotificationSettings, error)
+	UpsertNotificationSettings(context.Context, models.NotificationSettings) error
+	AddAbsence(context.Context, models.Absence) (*models.Absence, error)
+	DeleteAbsence(ctx context.Context, id int64) error
+}
+
+type ReviewReassigner interface {
+	ReassignOpenReviews(ctx context.Context, userID, reason string) ([]*models.PRReassignResult, error)
+	PublishReassignments(results []*models.PRReassignResult)
+	BackfillReviewers(ctx context.Context, req *models.BackfillReviewersRequest) (*models.BackfillReviewersResponse, error)
 }
 
 type UserService struct {
-	tx    txManager
-	users UserRepository
-	log   *slog.Logger
+	tx         txManager
+	users      UserRepository
+	reassigner ReviewReassigner
+	outbox     OutboxWriter
+	log        *slog.Logger
 }
 
-func NewUserService(tx txManager, users UserRepository, log *slog.Logger) (*UserService, error) {
+func NewUserService(tx txManager, users UserRepository, log *slog.Logger, opts ...UserOption) (*UserService, error) {
 	if tx == nil {
 		return nil, errors.New("tx manager cannot be nil")
 	}
@@ -36,29 +67,395 @@ func NewUserService(tx txManager, users UserRepository, log *slog.Logger) (*User
 	if log == nil {
 		return nil, errors.New("logger cannot be nil")
 	}
-	return &UserService{
+	s := &UserService{
 		tx:    tx,
 		users: users,
 		log:   log,
-	}, nil
+	}
+	for _, opt := range opts {
+		opt(s)
+	}
+	return s, nil
+}
+
+// SetUserActive changes the active flag. A non-zero version is compared
+// with the stored one and a mismatch fails with ErrUserConflict.
+func (s *UserService) SetUserActive(ctx context.Context, userID string, isActive bool, version int64) (*models.UserResponse, error) {
+	userID, err := s.resolveUserRef(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	if userID == "" {
+		return nil, fmt.Errorf("%w: user_id is required", ErrUserValidation)
+	}
+
+	var resp *models.UserResponse
+	err = s.tx.Run(ctx, func(ctx context.Context) error {
+		wasActive, err := s.wasActive(ctx, userID, isActive)
+		if err != nil {
+			return err
+		}
+		u, err := s.users.SetUserActive(ctx, userID, isActive, version)
+		if err != nil {
+			switch {
+			case errors.Is(err, storage.ErrUserNotFound):
+				return fmt.Errorf("set user active: %w", ErrUserNotFound)
+			case errors.Is(err, storage.ErrUserVersionChanged):
+				return fmt.Errorf("%w: %s is no longer at version %d", ErrUserConflict, userID, version)
+			default:
+				s.log.Error("set user active failed", slog.Any("error", err), slog.String("user_id", userID))
+				return fmt.Errorf("set user active: %w", err)
+			}
+		}
+		resp = &models.UserResponse{User: *u}
+		if wasActive {
+			if err := emitOutboxEvent(ctx, s.outbox, models.TopicMemberDeactivated, u.TeamName, models.TeamEvent{
+				TeamName:   u.TeamName,
+				UserID:     u.ID,
+				Username:   u.Username,
+				Reason:     "user deactivated",
+				OccurredAt: time.Now().UTC(),
+			}); err != nil {
+				return err
+			}
+		}
+		if isActive || s.reassigner == nil {
+			return nil
+		}
+		resp.Reassigned, err = s.reassigner.ReassignOpenReviews(ctx, userID, "reviewer deactivated")
+		if err != nil {
+			s.log.Error("reassign reviews of deactivated user failed", slog.Any("error", err), slog.String("user_id", userID))
+			return fmt.Errorf("reassign open reviews: %w", err)
+		}
+		return nil
+	})
+	if err != nil {
+		if errors.Is(err, ErrUserNotFound) {
+			return nil, ErrUserNotFound
+		}
+		return nil, err
+	}
+	if s.reassigner == nil {
+		return resp, nil
+	}
+	if len(resp.Reassigned) > 0 {
+		s.reassigner.PublishReassignments(resp.Reassigned)
+	}
+	if isActive && resp.User.TeamName != "" {
+		backfill, err := s.reassigner.BackfillReviewers(ctx, &models.BackfillReviewersRequest{TeamName: resp.User.TeamName})
+		if err != nil {
+			s.log.Warn("backfill reviewers after activation failed", slog.Any("error", err), slog.String("user_id", userID))
+		} else {
+			resp.Backfilled = backfill.Results
+		}
+	}
+	return resp, nil
+}
+
+// wasActive reports whether a deactivation is about to change an active
+// user, which is when member.deactivated is recorded.
+func (s *UserService) wasActive(ctx context.Context, userID string, isActive bool) (bool, error) {
+	if isActive || s.outbox == nil {
+		return false, nil
+	}
+	users, err := s.users.GetUsersByIDs(ctx, []string{userID})
+	if err != nil {
+		return false, fmt.Errorf("get user: %w", err)
+	}
+	return len(users) == 1 && users[0].IsActive, nil
+}
+
+// SetUserWeight changes how often random reviewer selection picks the user
+// relative to teammates with the default weight of 1. Version works as in
+// SetUserActive.
+func (s *UserService) SetUserWeight(ctx context.Context, userID string, weight float64, version int64) (*models.UserResponse, error) {
+	userID, err := s.resolveUserRef(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	if userID == "" {
+		return nil, fmt.Errorf("%w: user_id is required", ErrUserValidation)
+	}
+	if err := validateReviewWeight(weight); err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrUserValidation, err)
+	}
+
+	u, err := s.users.SetUserWeight(ctx, userID, weight, version)
+	if err != nil {
+		if errors.Is(err, storage.ErrUserNotFound) {
+			return nil, ErrUserNotFound
+		}
+		if errors.Is(err, storage.ErrUserVersionChanged) {
+			return nil, fmt.Errorf("%w: %s is no longer at version %d", ErrUserConflict, userID, version)
+		}
+		s.log.Error("set user weight failed", slog.Any("error", err), slog.String("user_id", userID))
+		return nil, fmt.Errorf("set user weight: %w", err)
+	}
+	return &models.UserResponse{User: *u}, nil
 }
 
-func (s *UserService) SetUserActive(ctx context.Context, userID string, isActive bool) (*models.UserResponse, error) {
-	userID = strings.TrimSpace(userID)
+func validateReviewWeight(weight float64) error {
+	if !(weight > 0 && weight <= MaxReviewWeight) {
+		return fmt.Errorf("weight must be greater than 0 and at most %d", MaxReviewWeight)
+	}
+	return nil
+}
+
+// SetWorkingHours stores the user's timezone and daily working window used
+// by the working_hours reviewer strategy. Empty start and end clear the
+// window; an empty timezone means UTC. Version works as in SetUserActive.
+func (s *UserService) SetWorkingHours(ctx context.Context, userID string, hours models.WorkingHours, version int64) (*models.UserResponse, error) {
+	userID, err := s.resolveUserRef(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
 	if userID == "" {
 		return nil, fmt.Errorf("%w: user_id is required", ErrUserValidation)
 	}
+	timezone := strings.TrimSpace(hours.Timezone)
+	if timezone == "" {
+		timezone = "UTC"
+	}
+	if _, err := time.LoadLocation(timezone); err != nil || strings.EqualFold(timezone, "local") {
+		return nil, fmt.Errorf("%w: unknown timezone %q", ErrUserValidation, hours.Timezone)
+	}
+	var start, end *int
+	if hours.Start != "" || hours.End != "" {
+		if start, err = parseClockTime("start", hours.Start); err != nil {
+			return nil, err
+		}
+		if end, err = parseClockTime("end", hours.End); err != nil {
+			return nil, err
+		}
+	}
 
-	u, err := s.users.SetUserActive(ctx, userID, isActive)
+	u, err := s.users.SetUserWorkingHours(ctx, userID, timezone, start, end, version)
+	if err != nil {
+		if errors.Is(err, storage.ErrUserNotFound) {
+			return nil, ErrUserNotFound
+		}
+		if errors.Is(err, storage.ErrUserVersionChanged) {
+			return nil, fmt.Errorf("%w: %s is no longer at version %d", ErrUserConflict, userID, version)
+		}
+		s.log.Error("set user working hours failed", slog.Any("error", err), slog.String("user_id", userID))
+		return nil, fmt.Errorf("set user working hours: %w", err)
+	}
+	return &models.UserResponse{User: *u}, nil
+}
+
+// parseClockTime turns "HH:MM" into minutes after midnight.
+func parseClockTime(field, value string) (*int, error) {
+	t, err := time.Parse("15:04", strings.TrimSpace(value))
+	if err != nil {
+		return nil, fmt.Errorf("%w: %s must be a time of day as HH:MM", ErrUserValidation, field)
+	}
+	minutes := t.Hour()*60 + t.Minute()
+	return &minutes, nil
+}
+
+// SetAbsence records a vacation or other absence. While it lasts, reviewer
+// selection skips the user although they stay active, and nothing has to be
+// undone when it ends.
+func (s *UserService) SetAbsence(ctx context.Context, req *models.SetAbsenceRequest) (*models.Absence, error) {
+	if req == nil {
+		return nil, fmt.Errorf("%w: empty body", ErrUserValidation)
+	}
+	userID, err := s.resolveUserRef(ctx, req.UserID)
+	if err != nil {
+		return nil, err
+	}
+	if userID == "" {
+		return nil, fmt.Errorf("%w: user_id is required", ErrUserValidation)
+	}
+	start, err := time.Parse(time.DateOnly, strings.TrimSpace(req.StartDate))
+	if err != nil {
+		return nil, fmt.Errorf("%w: start_date must be a date as YYYY-MM-DD", ErrUserValidation)
+	}
+	end, err := time.Parse(time.DateOnly, strings.TrimSpace(req.EndDate))
+	if err != nil {
+		return nil, fmt.Errorf("%w: end_date must be a date as YYYY-MM-DD", ErrUserValidation)
+	}
+	if end.Before(start) {
+		return nil, fmt.Errorf("%w: end_date must not be before start_date", ErrUserValidation)
+	}
+	reason := strings.TrimSpace(req.Reason)
+	if len(reason) > maxAbsenceReasonLength {
+		return nil, fmt.Errorf("%w: reason must be at most %d characters", ErrUserValidation, maxAbsenceReasonLength)
+	}
+
+	absence, err := s.users.AddAbsence(ctx, models.Absence{
+		UserID:    userID,
+		StartDate: start.Format(time.DateOnly),
+		EndDate:   end.Format(time.DateOnly),
+		Reason:    reason,
+	})
+	if err != nil {
+		if errors.Is(err, storage.ErrUserNotFound) {
+			return nil, ErrUserNotFound
+		}
+		s.log.Error("set absence failed", slog.Any("error", err), slog.String("user_id", userID))
+		return nil, fmt.Errorf("add absence: %w", err)
+	}
+	s.log.Info("absence set",
+		slog.String("user_id", userID),
+		slog.String("start_date", absence.StartDate),
+		slog.String("end_date", absence.EndDate),
+	)
+	return absence, nil
+}
+
+func (s *UserService) DeleteAbsence(ctx context.Context, id int64) error {
+	if id <= 0 {
+		return fmt.Errorf("%w: absence_id is required", ErrUserValidation)
+	}
+	if err := s.users.DeleteAbsence(ctx, id); err != nil {
+		if errors.Is(err, storage.ErrAbsenceNotFound) {
+			return ErrAbsenceNotFound
+		}
+		s.log.Error("delete absence failed", slog.Any("error", err), slog.Int64("absence_id", id))
+		return fmt.Errorf("delete absence: %w", err)
+	}
+	return nil
+}
+
+func (s *UserService) GetUsersByIDs(ctx context.Context, userIDs []string) ([]*models.UserWithTeam, error) {
+	unique := uniqueIDs(userIDs)
+	if len(unique) == 0 {
+		return []*models.UserWithTeam{}, nil
+	}
+
+	users, err := s.users.GetUsersByIDs(ctx, unique)
+	if err != nil {
+		s.log.Error("get users by ids failed", slog.Any("error", err))
+		return nil, fmt.Errorf("get users by ids: %w", err)
+	}
+	return users, nil
+}
+
+// BatchGetUsers looks up to MaxBatchGetUsers users in one query and reports
+// which of the requested ids do not exist. Blank and repeated ids are
+// ignored.
+func (s *UserService) BatchGetUsers(ctx context.Context, userIDs []string) (*models.UsersBatchGetResponse, error) {
+	unique := uniqueIDs(userIDs)
+	if len(unique) == 0 {
+		return nil, fmt.Errorf("%w: user_ids is required", ErrUserValidation)
+	}
+	if len(unique) > MaxBatchGetUsers {
+		return nil, fmt.Errorf("%w: at most %d user_ids per request", ErrUserValidation, MaxBatchGetUsers)
+	}
+
+	users, err := s.users.GetUsersByIDs(ctx, unique)
+	if err != nil {
+		s.log.Error("batch get users failed", slog.Any("error", err))
+		return nil, fmt.Errorf("get users by ids: %w", err)
+	}
+	found := make(map[string]struct{}, len(users))
+	for _, u := range users {
+		found[u.ID] = struct{}{}
+	}
+	missing := make([]string, 0, len(unique)-len(users))
+	for _, id := range unique {
+		if _, ok := found[id]; !ok {
+			missing = append(missing, id)
+		}
+	}
+	return &models.UsersBatchGetResponse{Users: users, Missing: missing}, nil
+}
+
+func uniqueIDs(userIDs []string) []string {
+	unique := make([]string, 0, len(userIDs))
+	seen := make(map[string]struct{}, len(userIDs))
+	for _, id := range userIDs {
+		id = strings.TrimSpace(id)
+		if id == "" {
+			continue
+		}
+		if _, ok := seen[id]; ok {
+			continue
+		}
+		seen[id] = struct{}{}
+		unique = append(unique, id)
+	}
+	return unique
+}
+
+func (s *UserService) GetUserByUsername(ctx context.Context, username string) (*models.UserResponse, error) {
+	username = strings.TrimPrefix(strings.TrimSpace(username), usernameRefPrefix)
+	if username == "" {
+		return nil, fmt.Errorf("%w: username is required", ErrUserValidation)
+	}
+
+	u, err := findUserByUsername(ctx, s.users, username)
+	if err != nil {
+		if !errors.Is(err, ErrUserNotFound) && !errors.Is(err, ErrUsernameAmbiguous) {
+			s.log.Error("get user by username failed", slog.Any("error", err), slog.String("username", username))
+		}
+		return nil, err
+	}
+
+	return &models.UserResponse{User: *u}, nil
+}
+
+func (s *UserService) GetNotificationSettings(ctx context.Context, userID string) (*models.NotificationSettings, error) {
+	userID, err := s.resolveUserRef(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	if userID == "" {
+		return nil, fmt.Errorf("%w: user_id is required", ErrUserValidation)
+	}
+
+	settings, err := s.users.GetNotificationSettings(ctx, userID)
 	if err != nil {
 		switch {
 		case errors.Is(err, storage.ErrUserNotFound):
-			return nil, fmt.Errorf("set user active: %w", ErrUserNotFound)
+			return nil, fmt.Errorf("get notification settings: %w", ErrUserNotFound)
 		default:
-			s.log.Error("set user active failed", slog.Any("error", err), slog.String("user_id", userID))
-			return nil, fmt.Errorf("set user active: %w", err)
+			s.log.Error("get notification settings failed", slog.Any("error", err), slog.String("user_id", userID))
+			return nil, fmt.Errorf("get notification settings: %w", err)
 		}
 	}
+	return settings, nil
+}
 
-	return &models.UserResponse{User: *u}, nil
+func (s *UserService) SetNotificationSettings(ctx context.Context, settings *models.NotificationSettings) (*models.NotificationSettings, error) {
+	if settings == nil {
+		return nil, fmt.Errorf("%w: empty body", ErrUserValidation)
+	}
+	userID, err := s.resolveUserRef(ctx, settings.UserID)
+	if err != nil {
+		return nil, err
+	}
+	if userID == "" {
+		return nil, fmt.Errorf("%w: user_id is required", ErrUserValidation)
+	}
+	if settings.DigestWindowMinutes < 0 || settings.DigestWindowMinutes > maxDigestWindowMinutes {
+		return nil, fmt.Errorf("%w: digest_window_minutes must be between 0 and %d", ErrUserValidation, maxDigestWindowMinutes)
+	}
+	settings.UserID = userID
+
+	err = s.tx.Run(ctx, func(ctx context.Context) error {
+		if _, err := s.users.GetNotificationSettings(ctx, userID); err != nil {
+			return err
+		}
+		return s.users.UpsertNotificationSettings(ctx, *settings)
+	})
+	if err != nil {
+		switch {
+		case errors.Is(err, storage.ErrUserNotFound):
+			return nil, fmt.Errorf("set notification settings: %w", ErrUserNotFound)
+		default:
+			s.log.Error("set notification settings transaction failed", slog.Any("error", err))
+			return nil, fmt.Errorf("error in transcation: %w", err)
+		}
+	}
+	return settings, nil
+}
+
+func (s *UserService) DigestWindow(ctx context.Context, userID string) (time.Duration, error) {
+	settings, err := s.users.GetNotificationSettings(ctx, userID)
+	if err != nil {
+		return 0, fmt.Errorf("get digest window: %w", err)
+	}
+	return time.Duration(settings.DigestWindowMinutes) * time.Minute, nil
 }
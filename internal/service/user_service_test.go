@@ -3,8 +3,11 @@ package service
 import (
 	"context"
 	"errors"
+	"fmt"
 	"io"
 	"log/slog"
+	"slices"
+	"strings"
 	"testing"
 
 	"github.com/cloudyy74/pr-reviewer-service/internal/models"
@@ -13,12 +16,60 @@ import (
 
 type fakeUserSetRepo struct {
 	setUserActiveFn func(context.Context, string, bool) (*models.UserWithTeam, error)
+	setWeightFn     func(context.Context, string, float64) (*models.UserWithTeam, error)
+	setHoursFn      func(context.Context, string, string, *int, *int) (*models.UserWithTeam, error)
+	addAbsenceFn    func(context.Context, models.Absence) (*models.Absence, error)
+	deleteAbsenceFn func(context.Context, int64) error
+	getByIDsFn      func(context.Context, []string) ([]*models.UserWithTeam, error)
+	getByUsernameFn func(context.Context, string) ([]*models.UserWithTeam, error)
+	getSettingsFn   func(context.Context, string) (*models.NotificationSettings, error)
+	upserted        *models.NotificationSettings
+	gotVersion      int64
 }
 
-func (f *fakeUserSetRepo) SetUserActive(ctx context.Context, userID string, isActive bool) (*models.UserWithTeam, error) {
+func (f *fakeUserSetRepo) SetUserActive(ctx context.Context, userID string, isActive bool, version int64) (*models.UserWithTeam, error) {
+	f.gotVersion = version
 	return f.setUserActiveFn(ctx, userID, isActive)
 }
 
+func (f *fakeUserSetRepo) SetUserWeight(ctx context.Context, userID string, weight float64, version int64) (*models.UserWithTeam, error) {
+	f.gotVersion = version
+	return f.setWeightFn(ctx, userID, weight)
+}
+
+func (f *fakeUserSetRepo) SetUserWorkingHours(ctx context.Context, userID, timezone string, start, end *int, version int64) (*models.UserWithTeam, error) {
+	f.gotVersion = version
+	return f.setHoursFn(ctx, userID, timezone, start, end)
+}
+
+func (f *fakeUserSetRepo) AddAbsence(ctx context.Context, absence models.Absence) (*models.Absence, error) {
+	return f.addAbsenceFn(ctx, absence)
+}
+
+func (f *fakeUserSetRepo) DeleteAbsence(ctx context.Context, id int64) error {
+	return f.deleteAbsenceFn(ctx, id)
+}
+
+func (f *fakeUserSetRepo) GetUsersByIDs(ctx context.Context, userIDs []string) ([]*models.UserWithTeam, error) {
+	return f.getByIDsFn(ctx, userIDs)
+}
+
+func (f *fakeUserSetRepo) GetUsersByUsername(ctx context.Context, username string) ([]*models.UserWithTeam, error) {
+	if f.getByUsernameFn == nil {
+		return nil, nil
+	}
+	return f.getByUsernameFn(ctx, username)
+}
+
+func (f *fakeUserSetRepo) GetNotificationSettings(ctx context.Context, userID string) (*models.NotificationSettings, error) {
+	return f.getSettingsFn(ctx, userID)
+}
+
+func (f *fakeUserSetRepo) UpsertNotificationSettings(_ context.Context, settings models.NotificationSettings) error {
+	f.upserted = &settings
+	return nil
+}
+
 type fakeTx struct{}
 
 func (fakeTx) Run(_ context.Context, fn func(ctx context.Context) error) error {
@@ -46,7 +97,7 @@ func TestUserService_SetUserActive_Success(t *testing.T) {
 	if err != nil {
 		t.Fatalf("unexpected error: %v", err)
 	}
-	userResp, err := service.SetUserActive(context.Background(), " user-1 ", true)
+	userResp, err := service.SetUserActive(context.Background(), " user-1 ", true, 0)
 	if err != nil {
 		t.Fatalf("SetUserActive returned error: %v", err)
 	}
@@ -59,6 +110,186 @@ func TestUserService_SetUserActive_Success(t *testing.T) {
 	}
 }
 
+func TestUserService_SetUserActive_DeactivationWritesEventOnce(t *testing.T) {
+	active := true
+	repo := &fakeUserSetRepo{
+		getByIDsFn: func(_ context.Context, ids []string) ([]*models.UserWithTeam, error) {
+			return []*models.UserWithTeam{{User: models.User{ID: ids[0], IsActive: active}, TeamName: "backend"}}, nil
+		},
+		setUserActiveFn: func(_ context.Context, userID string, isActive bool) (*models.UserWithTeam, error) {
+			active = isActive
+			return &models.UserWithTeam{User: models.User{ID: userID, IsActive: isActive}, TeamName: "backend"}, nil
+		},
+	}
+	outbox := &fakeOutboxWriter{}
+	service, err := NewUserService(fakeTx{}, repo, userTestLogger(), WithUserOutbox(outbox))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	for range 2 {
+		if _, err := service.SetUserActive(context.Background(), "user-1", false, 0); err != nil {
+			t.Fatalf("SetUserActive returned error: %v", err)
+		}
+	}
+	if len(outbox.topics) != 1 || outbox.topics[0] != models.TopicMemberDeactivated || outbox.aggregates[0] != "backend" {
+		t.Fatalf("expected a single member.deactivated event, got %v %v", outbox.topics, outbox.aggregates)
+	}
+}
+
+func TestUserService_SetUserWeight(t *testing.T) {
+	repo := &fakeUserSetRepo{
+		setWeightFn: func(_ context.Context, userID string, weight float64) (*models.UserWithTeam, error) {
+			if userID == "ghost" {
+				return nil, storage.ErrUserNotFound
+			}
+			return &models.UserWithTeam{User: models.User{ID: userID, Weight: weight}, TeamName: "backend"}, nil
+		},
+	}
+	service, err := NewUserService(fakeTx{}, repo, userTestLogger())
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	resp, err := service.SetUserWeight(context.Background(), "u1", 2.5, 0)
+	if err != nil {
+		t.Fatalf("SetUserWeight returned error: %v", err)
+	}
+	if resp.User.Weight != 2.5 {
+		t.Fatalf("unexpected user returned: %#v", resp.User)
+	}
+	for _, weight := range []float64{0, -1, MaxReviewWeight + 1} {
+		if _, err := service.SetUserWeight(context.Background(), "u1", weight, 0); !errors.Is(err, ErrUserValidation) {
+			t.Fatalf("weight %v: expected validation error, got %v", weight, err)
+		}
+	}
+	if _, err := service.SetUserWeight(context.Background(), "ghost", 1, 0); !errors.Is(err, ErrUserNotFound) {
+		t.Fatalf("expected ErrUserNotFound, got %v", err)
+	}
+}
+
+func TestUserService_SetWorkingHours(t *testing.T) {
+	var gotTimezone string
+	var gotStart, gotEnd *int
+	repo := &fakeUserSetRepo{
+		setHoursFn: func(_ context.Context, userID, timezone string, start, end *int) (*models.UserWithTeam, error) {
+			if userID == "ghost" {
+				return nil, storage.ErrUserNotFound
+			}
+			gotTimezone, gotStart, gotEnd = timezone, start, end
+			return &models.UserWithTeam{User: models.User{ID: userID}, TeamName: "backend"}, nil
+		},
+	}
+	service, err := NewUserService(fakeTx{}, repo, userTestLogger())
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	hours := models.WorkingHours{Timezone: "Asia/Tokyo", Start: "22:00", End: "06:30"}
+	if _, err := service.SetWorkingHours(context.Background(), "u1", hours, 4); err != nil {
+		t.Fatalf("SetWorkingHours returned error: %v", err)
+	}
+	if gotTimezone != "Asia/Tokyo" || gotStart == nil || *gotStart != 22*60 || gotEnd == nil || *gotEnd != 6*60+30 {
+		t.Fatalf("unexpected stored hours %q %v %v", gotTimezone, gotStart, gotEnd)
+	}
+	if repo.gotVersion != 4 {
+		t.Fatalf("expected version 4, got %d", repo.gotVersion)
+	}
+
+	if _, err := service.SetWorkingHours(context.Background(), "u1", models.WorkingHours{}, 0); err != nil {
+		t.Fatalf("clearing hours returned error: %v", err)
+	}
+	if gotTimezone != "UTC" || gotStart != nil || gotEnd != nil {
+		t.Fatalf("expected cleared hours in UTC, got %q %v %v", gotTimezone, gotStart, gotEnd)
+	}
+
+	for _, bad := range []models.WorkingHours{
+		{Timezone: "Mars/Olympus", Start: "09:00", End: "17:00"},
+		{Timezone: "Local", Start: "09:00", End: "17:00"},
+		{Timezone: "UTC", Start: "25:00", End: "17:00"},
+		{Timezone: "UTC", Start: "09:00"},
+	} {
+		if _, err := service.SetWorkingHours(context.Background(), "u1", bad, 0); !errors.Is(err, ErrUserValidation) {
+			t.Fatalf("%+v: expected validation error, got %v", bad, err)
+		}
+	}
+	if _, err := service.SetWorkingHours(context.Background(), "ghost", hours, 0); !errors.Is(err, ErrUserNotFound) {
+		t.Fatalf("expected ErrUserNotFound, got %v", err)
+	}
+}
+
+type fakeReassigner struct {
+	calledFor    string
+	results      []*models.PRReassignResult
+	err          error
+	published    []*models.PRReassignResult
+	backfillTeam string
+}
+
+func (f *fakeReassigner) ReassignOpenReviews(_ context.Context, userID, _ string) ([]*models.PRReassignResult, error) {
+	f.calledFor = userID
+	return f.results, f.err
+}
+
+func (f *fakeReassigner) PublishReassignments(results []*models.PRReassignResult) {
+	f.published = results
+}
+
+func (f *fakeReassigner) BackfillReviewers(_ context.Context, req *models.BackfillReviewersRequest) (*models.BackfillReviewersResponse, error) {
+	f.backfillTeam = req.TeamName
+	return &models.BackfillReviewersResponse{Results: []*models.PRBackfillResult{
+		{PullRequestID: "pr-9", Outcome: models.BackfillOutcomeFilled, Added: []string{"u1"}},
+	}}, nil
+}
+
+func TestUserService_SetUserActive_DeactivationReassignsReviews(t *testing.T) {
+	repo := &fakeUserSetRepo{
+		setUserActiveFn: func(_ context.Context, userID string, isActive bool) (*models.UserWithTeam, error) {
+			return &models.UserWithTeam{User: models.User{ID: userID, IsActive: isActive}, TeamName: "backend"}, nil
+		},
+	}
+	reassigner := &fakeReassigner{results: []*models.PRReassignResult{
+		{PullRequestID: "pr-1", Outcome: models.ReassignOutcomeReassigned, ReplacedBy: "u2"},
+	}}
+	service, err := NewUserService(fakeTx{}, repo, userTestLogger(), WithReviewReassigner(reassigner))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	activated, err := service.SetUserActive(context.Background(), "u1", true, 0)
+	if err != nil {
+		t.Fatalf("SetUserActive returned error: %v", err)
+	}
+	if len(activated.Backfilled) != 1 {
+		t.Fatalf("expected backfill results in response, got %+v", activated.Backfilled)
+	}
+	if reassigner.calledFor != "" {
+		t.Fatalf("activation must not reassign reviews")
+	}
+	if reassigner.backfillTeam != "backend" {
+		t.Fatalf("expected activation to backfill team reviewers, got %q", reassigner.backfillTeam)
+	}
+
+	resp, err := service.SetUserActive(context.Background(), "u1", false, 0)
+	if err != nil {
+		t.Fatalf("SetUserActive returned error: %v", err)
+	}
+	if reassigner.calledFor != "u1" || len(resp.Reassigned) != 1 || resp.Reassigned[0].ReplacedBy != "u2" {
+		t.Fatalf("unexpected reassignment: %+v", resp.Reassigned)
+	}
+	if len(reassigner.published) != 1 {
+		t.Fatalf("expected reassignments to be published after commit")
+	}
+
+	reassigner.err = errors.New("db down")
+	reassigner.published = nil
+	if _, err := service.SetUserActive(context.Background(), "u1", false, 0); err == nil {
+		t.Fatalf("expected deactivation to fail with its reassignment")
+	}
+	if reassigner.published != nil {
+		t.Fatalf("nothing must be published when the transaction fails")
+	}
+}
+
 func TestUserService_SetUserActive_UserNotFound(t *testing.T) {
 	repo := &fakeUserSetRepo{
 		setUserActiveFn: func(context.Context, string, bool) (*models.UserWithTeam, error) {
@@ -69,7 +300,7 @@ func TestUserService_SetUserActive_UserNotFound(t *testing.T) {
 	if err != nil {
 		t.Fatalf("unexpected error: %v", err)
 	}
-	_, err = service.SetUserActive(context.Background(), "user-1", true)
+	_, err = service.SetUserActive(context.Background(), "user-1", true, 0)
 	if !errors.Is(err, ErrUserNotFound) {
 		t.Fatalf("expected ErrUserNotFound, got %v", err)
 	}
@@ -81,8 +312,263 @@ func TestUserService_SetUserActive_Validation(t *testing.T) {
 	if err != nil {
 		t.Fatalf("unexpected error: %v", err)
 	}
-	_, err = service.SetUserActive(context.Background(), " \t \n ", true)
+	_, err = service.SetUserActive(context.Background(), " \t \n ", true, 0)
+	if !errors.Is(err, ErrUserValidation) {
+		t.Fatalf("expected ErrUserValidation, got %v", err)
+	}
+}
+
+func TestUserService_GetUsersByIDs_Deduplicates(t *testing.T) {
+	var requested []string
+	repo := &fakeUserSetRepo{
+		getByIDsFn: func(_ context.Context, userIDs []string) ([]*models.UserWithTeam, error) {
+			requested = userIDs
+			return []*models.UserWithTeam{{User: models.User{ID: "u1"}}, {User: models.User{ID: "u2"}}}, nil
+		},
+	}
+	service, err := NewUserService(fakeTx{}, repo, userTestLogger())
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	users, err := service.GetUsersByIDs(context.Background(), []string{"u1", " u2 ", "u1", ""})
+	if err != nil {
+		t.Fatalf("GetUsersByIDs returned error: %v", err)
+	}
+	if len(users) != 2 {
+		t.Fatalf("unexpected users: %#v", users)
+	}
+	if len(requested) != 2 || requested[0] != "u1" || requested[1] != "u2" {
+		t.Fatalf("unexpected requested ids: %v", requested)
+	}
+}
+
+func TestUserService_GetUsersByIDs_EmptySkipsRepo(t *testing.T) {
+	service, err := NewUserService(fakeTx{}, &fakeUserSetRepo{}, userTestLogger())
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	users, err := service.GetUsersByIDs(context.Background(), nil)
+	if err != nil {
+		t.Fatalf("GetUsersByIDs returned error: %v", err)
+	}
+	if users == nil || len(users) != 0 {
+		t.Fatalf("expected empty users, got %#v", users)
+	}
+}
+
+func TestUserService_SetAbsence(t *testing.T) {
+	var stored models.Absence
+	repo := &fakeUserSetRepo{
+		addAbsenceFn: func(_ context.Context, absence models.Absence) (*models.Absence, error) {
+			if absence.UserID == "ghost" {
+				return nil, storage.ErrUserNotFound
+			}
+			stored = absence
+			absence.ID = 7
+			return &absence, nil
+		},
+		deleteAbsenceFn: func(_ context.Context, id int64) error {
+			if id != 7 {
+				return storage.ErrAbsenceNotFound
+			}
+			return nil
+		},
+	}
+	service, err := NewUserService(fakeTx{}, repo, userTestLogger())
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	absence, err := service.SetAbsence(context.Background(), &models.SetAbsenceRequest{
+		UserID: "u1", StartDate: "2026-12-24", EndDate: " 2027-01-02 ", Reason: " vacation ",
+	})
+	if err != nil {
+		t.Fatalf("SetAbsence returned error: %v", err)
+	}
+	if absence.ID != 7 || stored.EndDate != "2027-01-02" || stored.Reason != "vacation" {
+		t.Fatalf("unexpected absence %+v stored as %+v", absence, stored)
+	}
+
+	for _, bad := range []*models.SetAbsenceRequest{
+		nil,
+		{StartDate: "2026-12-24", EndDate: "2026-12-25"},
+		{UserID: "u1", StartDate: "24.12.2026", EndDate: "2026-12-25"},
+		{UserID: "u1", StartDate: "2026-12-24"},
+		{UserID: "u1", StartDate: "2026-12-24", EndDate: "2026-12-23"},
+		{UserID: "u1", StartDate: "2026-12-24", EndDate: "2026-12-24", Reason: strings.Repeat("x", maxAbsenceReasonLength+1)},
+	} {
+		if _, err := service.SetAbsence(context.Background(), bad); !errors.Is(err, ErrUserValidation) {
+			t.Fatalf("%+v: expected validation error, got %v", bad, err)
+		}
+	}
+	if _, err := service.SetAbsence(context.Background(), &models.SetAbsenceRequest{UserID: "ghost", StartDate: "2026-12-24", EndDate: "2026-12-24"}); !errors.Is(err, ErrUserNotFound) {
+		t.Fatalf("expected ErrUserNotFound, got %v", err)
+	}
+
+	if err := service.DeleteAbsence(context.Background(), 7); err != nil {
+		t.Fatalf("DeleteAbsence returned error: %v", err)
+	}
+	if err := service.DeleteAbsence(context.Background(), 8); !errors.Is(err, ErrAbsenceNotFound) {
+		t.Fatalf("expected ErrAbsenceNotFound, got %v", err)
+	}
+	if err := service.DeleteAbsence(context.Background(), 0); !errors.Is(err, ErrUserValidation) {
+		t.Fatalf("expected validation error, got %v", err)
+	}
+}
+
+func TestUserService_BatchGetUsers(t *testing.T) {
+	calls := 0
+	repo := &fakeUserSetRepo{
+		getByIDsFn: func(_ context.Context, userIDs []string) ([]*models.UserWithTeam, error) {
+			calls++
+			if !slices.Equal(userIDs, []string{"u3", "ghost", "u1"}) {
+				t.Fatalf("unexpected requested ids: %v", userIDs)
+			}
+			return []*models.UserWithTeam{{User: models.User{ID: "u1"}}, {User: models.User{ID: "u3"}}}, nil
+		},
+	}
+	service, err := NewUserService(fakeTx{}, repo, userTestLogger())
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	resp, err := service.BatchGetUsers(context.Background(), []string{"u3", "ghost", "u1", "u3", " "})
+	if err != nil {
+		t.Fatalf("BatchGetUsers returned error: %v", err)
+	}
+	if calls != 1 || len(resp.Users) != 2 || !slices.Equal(resp.Missing, []string{"ghost"}) {
+		t.Fatalf("unexpected response after %d calls: %+v", calls, resp)
+	}
+
+	if _, err := service.BatchGetUsers(context.Background(), nil); !errors.Is(err, ErrUserValidation) {
+		t.Fatalf("expected validation error for empty ids, got %v", err)
+	}
+	tooMany := make([]string, MaxBatchGetUsers+1)
+	for i := range tooMany {
+		tooMany[i] = fmt.Sprintf("u%d", i)
+	}
+	if _, err := service.BatchGetUsers(context.Background(), tooMany); !errors.Is(err, ErrUserValidation) {
+		t.Fatalf("expected validation error for too many ids, got %v", err)
+	}
+}
+
+func TestUserService_GetUserByUsername(t *testing.T) {
+	repo := &fakeUserSetRepo{
+		getByUsernameFn: func(_ context.Context, username string) ([]*models.UserWithTeam, error) {
+			switch username {
+			case "alice":
+				return []*models.UserWithTeam{{User: models.User{ID: "u1", Username: "alice"}, TeamName: "backend"}}, nil
+			case "bob":
+				return []*models.UserWithTeam{{User: models.User{ID: "u2"}}, {User: models.User{ID: "u3"}}}, nil
+			default:
+				return nil, nil
+			}
+		},
+	}
+	service, err := NewUserService(fakeTx{}, repo, userTestLogger())
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	resp, err := service.GetUserByUsername(context.Background(), " @alice ")
+	if err != nil {
+		t.Fatalf("GetUserByUsername returned error: %v", err)
+	}
+	if resp.User.ID != "u1" || resp.User.TeamName != "backend" {
+		t.Fatalf("unexpected user: %#v", resp.User)
+	}
+	if _, err := service.GetUserByUsername(context.Background(), "bob"); !errors.Is(err, ErrUsernameAmbiguous) {
+		t.Fatalf("expected ErrUsernameAmbiguous, got %v", err)
+	}
+	if _, err := service.GetUserByUsername(context.Background(), "carol"); !errors.Is(err, ErrUserNotFound) {
+		t.Fatalf("expected ErrUserNotFound, got %v", err)
+	}
+	if _, err := service.GetUserByUsername(context.Background(), " "); !errors.Is(err, ErrUserValidation) {
+		t.Fatalf("expected ErrUserValidation, got %v", err)
+	}
+}
+
+func TestUserService_SetUserActive_ByUsername(t *testing.T) {
+	repo := &fakeUserSetRepo{
+		setUserActiveFn: func(_ context.Context, userID string, isActive bool) (*models.UserWithTeam, error) {
+			if userID != "u1" {
+				t.Fatalf("expected resolved user id u1, got %q", userID)
+			}
+			return &models.UserWithTeam{User: models.User{ID: userID, IsActive: isActive}}, nil
+		},
+		getByUsernameFn: func(_ context.Context, _ string) ([]*models.UserWithTeam, error) {
+			return []*models.UserWithTeam{{User: models.User{ID: "u1", Username: "alice"}}}, nil
+		},
+	}
+	service, err := NewUserService(fakeTx{}, repo, userTestLogger())
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if _, err := service.SetUserActive(context.Background(), "@alice", false, 0); err != nil {
+		t.Fatalf("SetUserActive returned error: %v", err)
+	}
+}
+
+func TestUserService_SetNotificationSettings(t *testing.T) {
+	repo := &fakeUserSetRepo{
+		getSettingsFn: func(_ context.Context, userID string) (*models.NotificationSettings, error) {
+			return &models.NotificationSettings{UserID: userID}, nil
+		},
+	}
+	service, err := NewUserService(fakeTx{}, repo, userTestLogger())
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	settings, err := service.SetNotificationSettings(context.Background(), &models.NotificationSettings{UserID: " u1 ", DigestWindowMinutes: 15})
+	if err != nil {
+		t.Fatalf("SetNotificationSettings returned err: %v", err)
+	}
+	if settings.UserID != "u1" || repo.upserted == nil || repo.upserted.DigestWindowMinutes != 15 {
+		t.Fatalf("unexpected settings: %#v, upserted %#v", settings, repo.upserted)
+	}
+
+	_, err = service.SetNotificationSettings(context.Background(), &models.NotificationSettings{UserID: "u1", DigestWindowMinutes: maxDigestWindowMinutes + 1})
 	if !errors.Is(err, ErrUserValidation) {
 		t.Fatalf("expected ErrUserValidation, got %v", err)
 	}
 }
+
+func TestUserService_SetNotificationSettings_NotFound(t *testing.T) {
+	repo := &fakeUserSetRepo{
+		getSettingsFn: func(context.Context, string) (*models.NotificationSettings, error) {
+			return nil, storage.ErrUserNotFound
+		},
+	}
+	service, err := NewUserService(fakeTx{}, repo, userTestLogger())
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	_, err = service.SetNotificationSettings(context.Background(), &models.NotificationSettings{UserID: "u1", DigestWindowMinutes: 15})
+	if !errors.Is(err, ErrUserNotFound) {
+		t.Fatalf("expected ErrUserNotFound, got %v", err)
+	}
+	if repo.upserted != nil {
+		t.Fatalf("settings should not be stored for unknown user")
+	}
+}
+
+func TestUserService_SetUserActive_VersionConflict(t *testing.T) {
+	repo := &fakeUserSetRepo{
+		setUserActiveFn: func(context.Context, string, bool) (*models.UserWithTeam, error) {
+			return nil, storage.ErrUserVersionChanged
+		},
+	}
+	service, err := NewUserService(fakeTx{}, repo, userTestLogger())
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if _, err := service.SetUserActive(context.Background(), "u1", true, 3); !errors.Is(err, ErrUserConflict) {
+		t.Fatalf("expected ErrUserConflict, got %v", err)
+	}
+	if repo.gotVersion != 3 {
+		t.Fatalf("expected version 3 to reach the repository, got %d", repo.gotVersion)
+	}
+}
@@ -0,0 +1,111 @@
+package shedding
+
+import (
+	"database/sql"
+	"errors"
+	"log/slog"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/cloudyy74/pr-reviewer-service/internal/models"
+)
+
+const (
+	waitSmoothing = 0.5
+	recoverRatio  = 0.5
+)
+
+type PoolStats func() sql.DBStats
+
+type Shedder struct {
+	stats     PoolStats
+	threshold time.Duration
+	log       *slog.Logger
+
+	mu       sync.Mutex
+	last     sql.DBStats
+	avgWait  float64
+	shedding atomic.Bool
+	shed     atomic.Int64
+	switches atomic.Int64
+}
+
+func New(stats PoolStats, threshold time.Duration, log *slog.Logger) (*Shedder, error) {
+	if stats == nil {
+		return nil, errors.New("pool stats cannot be nil")
+	}
+	if threshold <= 0 {
+		return nil, errors.New("threshold must be positive")
+	}
+	if log == nil {
+		return nil, errors.New("logger cannot be nil")
+	}
+	return &Shedder{
+		stats:     stats,
+		threshold: threshold,
+		log:       log,
+		last:      stats(),
+	}, nil
+}
+
+// Sample compares pool wait counters with the previous sample and switches
+// shedding on above the threshold and off once the smoothed wait drops below
+// half of it, so the state does not flap around the boundary.
+func (s *Shedder) Sample() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	cur := s.stats()
+	waits := cur.WaitCount - s.last.WaitCount
+	waited := cur.WaitDuration - s.last.WaitDuration
+	s.last = cur
+
+	var windowAvg float64
+	if waits > 0 {
+		windowAvg = float64(waited) / float64(waits)
+	}
+	s.avgWait = waitSmoothing*windowAvg + (1-waitSmoothing)*s.avgWait
+
+	threshold := float64(s.threshold)
+	switch {
+	case !s.shedding.Load() && s.avgWait > threshold:
+		s.shedding.Store(true)
+		s.switches.Add(1)
+		s.log.Warn("load shedding enabled",
+			slog.Duration("avg_pool_wait", time.Duration(s.avgWait)),
+			slog.Duration("threshold", s.threshold),
+		)
+	case s.shedding.Load() && s.avgWait < threshold*recoverRatio:
+		s.shedding.Store(false)
+		s.switches.Add(1)
+		s.log.Info("load shedding disabled", slog.Duration("avg_pool_wait", time.Duration(s.avgWait)))
+	}
+}
+
+func (s *Shedder) Shedding() bool {
+	return s.shedding.Load()
+}
+
+func (s *Shedder) Rejected() {
+	s.shed.Add(1)
+}
+
+func (s *Shedder) Metrics() models.LoadMetrics {
+	s.mu.Lock()
+	avgWait := s.avgWait
+	pool := s.last
+	s.mu.Unlock()
+
+	return models.LoadMetrics{
+		Shedding:         s.shedding.Load(),
+		AvgPoolWaitMs:    avgWait / float64(time.Millisecond),
+		ThresholdMs:      float64(s.threshold) / float64(time.Millisecond),
+		ShedRequests:     s.shed.Load(),
+		SheddingSwitches: s.switches.Load(),
+		PoolOpen:         pool.OpenConnections,
+		PoolInUse:        pool.InUse,
+		PoolMaxOpen:      pool.MaxOpenConnections,
+		PoolWaitCount:    pool.WaitCount,
+	}
+}
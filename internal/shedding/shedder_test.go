@@ -0,0 +1,55 @@
+package shedding
+
+import (
+	"database/sql"
+	"io"
+	"log/slog"
+	"testing"
+	"time"
+)
+
+func TestShedder_SwitchesWithHysteresis(t *testing.T) {
+	var stats sql.DBStats
+	s, err := New(func() sql.DBStats { return stats }, 10*time.Millisecond, slog.New(slog.NewTextHandler(io.Discard, nil)))
+	if err != nil {
+		t.Fatalf("New returned err: %v", err)
+	}
+
+	wait := func(count int64, each time.Duration) {
+		stats.WaitCount += count
+		stats.WaitDuration += time.Duration(count) * each
+		s.Sample()
+	}
+
+	wait(10, 50*time.Millisecond)
+	if !s.Shedding() {
+		t.Fatalf("expected shedding after slow pool waits, metrics %+v", s.Metrics())
+	}
+
+	wait(10, 8*time.Millisecond)
+	if !s.Shedding() {
+		t.Fatalf("shedding should stay on until wait drops below half the threshold, metrics %+v", s.Metrics())
+	}
+
+	wait(0, 0)
+	wait(0, 0)
+	wait(0, 0)
+	if s.Shedding() {
+		t.Fatalf("expected shedding to stop, metrics %+v", s.Metrics())
+	}
+
+	s.Rejected()
+	m := s.Metrics()
+	if m.ShedRequests != 1 || m.SheddingSwitches != 2 || m.PoolWaitCount != 20 {
+		t.Fatalf("unexpected metrics: %+v", m)
+	}
+}
+
+func TestNew_Validation(t *testing.T) {
+	if _, err := New(nil, time.Millisecond, slog.New(slog.NewTextHandler(io.Discard, nil))); err == nil {
+		t.Fatalf("expected error for nil stats")
+	}
+	if _, err := New(func() sql.DBStats { return sql.DBStats{} }, 0, slog.New(slog.NewTextHandler(io.Discard, nil))); err == nil {
+		t.Fatalf("expected error for zero threshold")
+	}
+}
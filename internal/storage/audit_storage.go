@@ -0,0 +1,47 @@
+package storage
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+
+	"github.com/cloudyy74/pr-reviewer-service/internal/models"
+	"github.com/cloudyy74/pr-reviewer-service/pkg/postgres"
+)
+
+type AuditStorage struct {
+	db  *postgres.Postgres
+	log *slog.Logger
+}
+
+func NewAuditStorage(db *postgres.Postgres, log *slog.Logger) (*AuditStorage, error) {
+	if db == nil {
+		return nil, errors.New("database cannot be nil")
+	}
+	if log == nil {
+		return nil, errors.New("logger cannot be nil")
+	}
+	return &AuditStorage{
+		db:  db,
+		log: log,
+	}, nil
+}
+
+func (s *AuditStorage) RecordAudit(ctx context.Context, entry models.AuditEntry) error {
+	exec := getExecer(ctx, s.db.DB)
+	_, err := exec.ExecContext(
+		ctx,
+		`insert into audit_log (actor_id, subject_id, method, path, status) values ($1, $2, $3, $4, $5)`,
+		entry.ActorID,
+		entry.SubjectID,
+		entry.Method,
+		entry.Path,
+		entry.Status,
+	)
+	if err != nil {
+		s.log.Error("failed to record audit entry", slog.Any("error", err), slog.String("actor_id", entry.ActorID))
+		return fmt.Errorf("record audit entry: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,45 @@
+package storage
+
+import (
+	"context"
+	"errors"
+	"io"
+	"log/slog"
+	"regexp"
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+
+	"github.com/cloudyy74/pr-reviewer-service/internal/models"
+	"github.com/cloudyy74/pr-reviewer-service/pkg/postgres"
+)
+
+func TestAuditStorage_RecordAudit(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("create sqlmock: %v", err)
+	}
+	defer db.Close()
+	st, err := NewAuditStorage(&postgres.Postgres{DB: db}, slog.New(slog.NewTextHandler(io.Discard, nil)))
+	if err != nil {
+		t.Fatalf("NewAuditStorage: %v", err)
+	}
+
+	query := regexp.QuoteMeta(`insert into audit_log (actor_id, subject_id, method, path, status) values ($1, $2, $3, $4, $5)`)
+	mock.ExpectExec(query).
+		WithArgs("admin", "u1", "POST", "/me/decline", 200).
+		WillReturnResult(sqlmock.NewResult(1, 1))
+	mock.ExpectExec(query).
+		WithArgs("admin", "u1", "GET", "/me", 200).
+		WillReturnError(errors.New("boom"))
+
+	entry := models.AuditEntry{ActorID: "admin", SubjectID: "u1", Method: "POST", Path: "/me/decline", Status: 200}
+	if err := st.RecordAudit(context.Background(), entry); err != nil {
+		t.Fatalf("RecordAudit returned err: %v", err)
+	}
+	entry.Method, entry.Path = "GET", "/me"
+	if err := st.RecordAudit(context.Background(), entry); err == nil {
+		t.Fatal("expected error")
+	}
+	verifyExpectations(t, mock)
+}
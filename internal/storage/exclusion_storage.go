@@ -0,0 +1,114 @@
+package storage
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+
+	"github.com/cloudyy74/pr-reviewer-service/internal/models"
+	"github.com/cloudyy74/pr-reviewer-service/pkg/postgres"
+)
+
+var ErrExclusionNotFound = errors.New("reviewer exclusion not found")
+
+type ExclusionStorage struct {
+	db  *postgres.Postgres
+	log *slog.Logger
+}
+
+func NewExclusionStorage(db *postgres.Postgres, log *slog.Logger) (*ExclusionStorage, error) {
+	if db == nil {
+		return nil, errors.New("database cannot be nil")
+	}
+	if log == nil {
+		return nil, errors.New("logger cannot be nil")
+	}
+	return &ExclusionStorage{
+		db:  db,
+		log: log,
+	}, nil
+}
+
+// AddExclusion stores the rule, replacing mutual and reason of an existing
+// rule for the same pair.
+func (s *ExclusionStorage) AddExclusion(ctx context.Context, e models.ReviewerExclusion) (*models.ReviewerExclusion, error) {
+	exec := getQueryExecer(ctx, s.db.DB)
+	err := exec.QueryRowContext(
+		ctx,
+		`
+insert into reviewer_exclusions (reviewer_id, author_id, mutual, reason)
+values ($1, $2, $3, $4)
+on conflict (reviewer_id, author_id) do update set
+mutual = excluded.mutual,
+reason = excluded.reason
+returning created_at`,
+		e.ReviewerID,
+		e.AuthorID,
+		e.Mutual,
+		e.Reason,
+	).Scan(&e.CreatedAt)
+	if err != nil {
+		if postgres.IsForeignKeyViolation(err) {
+			return nil, ErrUserNotFound
+		}
+		s.log.Error("failed to add reviewer exclusion", slog.Any("error", err))
+		return nil, fmt.Errorf("insert reviewer exclusion: %w", err)
+	}
+	return &e, nil
+}
+
+// ListExclusions returns rules involving userID on either side, or all rules
+// when userID is empty.
+func (s *ExclusionStorage) ListExclusions(ctx context.Context, userID string) ([]*models.ReviewerExclusion, error) {
+	exec := getQueryExecer(ctx, s.db.DB)
+	rows, err := exec.QueryContext(
+		ctx,
+		`
+select reviewer_id, author_id, mutual, reason, created_at
+from reviewer_exclusions
+where $1 = '' or reviewer_id = $1 or author_id = $1
+order by created_at, reviewer_id, author_id`,
+		userID,
+	)
+	if err != nil {
+		s.log.Error("failed to list reviewer exclusions", slog.Any("error", err))
+		return nil, fmt.Errorf("list reviewer exclusions: %w", err)
+	}
+	defer rows.Close()
+
+	exclusions := make([]*models.ReviewerExclusion, 0)
+	for rows.Next() {
+		var e models.ReviewerExclusion
+		if err := rows.Scan(&e.ReviewerID, &e.AuthorID, &e.Mutual, &e.Reason, &e.CreatedAt); err != nil {
+			return nil, fmt.Errorf("scan reviewer exclusion: %w", err)
+		}
+		exclusions = append(exclusions, &e)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("iterate reviewer exclusions: %w", err)
+	}
+	return exclusions, nil
+}
+
+func (s *ExclusionStorage) DeleteExclusion(ctx context.Context, reviewerID, authorID string) error {
+	exec := getExecer(ctx, s.db.DB)
+	res, err := exec.ExecContext(
+		ctx,
+		`delete from reviewer_exclusions where reviewer_id = $1 and author_id = $2`,
+		reviewerID,
+		authorID,
+	)
+	if err != nil {
+		s.log.Error("failed to delete reviewer exclusion", slog.Any("error", err))
+		return fmt.Errorf("delete reviewer exclusion: %w", err)
+	}
+	n, err := res.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("rows affected: %w", err)
+	}
+	if n == 0 {
+		return ErrExclusionNotFound
+	}
+	return nil
+}
@@ -0,0 +1,67 @@
+package storage
+
+import (
+	"context"
+	"errors"
+	"io"
+	"log/slog"
+	"regexp"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/jackc/pgx/v5/pgconn"
+
+	"github.com/cloudyy74/pr-reviewer-service/internal/models"
+	"github.com/cloudyy74/pr-reviewer-service/pkg/postgres"
+)
+
+func newExclusionStorage(t *testing.T) (*ExclusionStorage, sqlmock.Sqlmock) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("create sqlmock: %v", err)
+	}
+	t.Cleanup(func() { db.Close() })
+
+	st, err := NewExclusionStorage(&postgres.Postgres{DB: db}, slog.New(slog.NewTextHandler(io.Discard, nil)))
+	if err != nil {
+		t.Fatalf("NewExclusionStorage: %v", err)
+	}
+	return st, mock
+}
+
+func TestExclusionStorage_AddExclusion(t *testing.T) {
+	st, mock := newExclusionStorage(t)
+	createdAt := time.Date(2025, 3, 1, 10, 0, 0, 0, time.UTC)
+	mock.ExpectQuery(regexp.QuoteMeta(`on conflict (reviewer_id, author_id) do update set`)).
+		WithArgs("u1", "u2", true, "pairing").
+		WillReturnRows(sqlmock.NewRows([]string{"created_at"}).AddRow(createdAt))
+	mock.ExpectQuery(regexp.QuoteMeta(`insert into reviewer_exclusions`)).
+		WithArgs("u1", "ghost", false, "").
+		WillReturnError(&pgconn.PgError{Code: "23503"})
+
+	ctx := context.Background()
+	e, err := st.AddExclusion(ctx, models.ReviewerExclusion{ReviewerID: "u1", AuthorID: "u2", Mutual: true, Reason: "pairing"})
+	if err != nil {
+		t.Fatalf("AddExclusion returned err: %v", err)
+	}
+	if !e.CreatedAt.Equal(createdAt) || !e.Mutual {
+		t.Fatalf("unexpected exclusion: %+v", e)
+	}
+	if _, err := st.AddExclusion(ctx, models.ReviewerExclusion{ReviewerID: "u1", AuthorID: "ghost"}); !errors.Is(err, ErrUserNotFound) {
+		t.Fatalf("expected ErrUserNotFound, got %v", err)
+	}
+	verifyExpectations(t, mock)
+}
+
+func TestExclusionStorage_DeleteExclusion_NotFound(t *testing.T) {
+	st, mock := newExclusionStorage(t)
+	mock.ExpectExec(regexp.QuoteMeta(`delete from reviewer_exclusions where reviewer_id = $1 and author_id = $2`)).
+		WithArgs("u1", "u2").
+		WillReturnResult(sqlmock.NewResult(0, 0))
+
+	if err := st.DeleteExclusion(context.Background(), "u1", "u2"); !errors.Is(err, ErrExclusionNotFound) {
+		t.Fatalf("expected ErrExclusionNotFound, got %v", err)
+	}
+	verifyExpectations(t, mock)
+}
@@ -3,6 +3,8 @@ package storage
 import (
 	"context"
 	"database/sql"
+
+	"github.com/cloudyy74/pr-reviewer-service/internal/chaos"
 )
 
 type execer interface {
@@ -15,15 +17,45 @@ type queryExecer interface {
 }
 
 func getExecer(ctx context.Context, db *sql.DB) execer {
-	if tx, ok := TxFromCtx(ctx); ok {
-		return tx
-	}
-	return db
+	return getQueryExecer(ctx, db)
 }
 
 func getQueryExecer(ctx context.Context, db *sql.DB) queryExecer {
+	var exec queryExecer = db
 	if tx, ok := TxFromCtx(ctx); ok {
-		return tx
+		exec = tx
+	}
+	if chaos.Enabled {
+		return faultExecer{exec}
+	}
+	return exec
+}
+
+type faultExecer struct {
+	queryExecer
+}
+
+func (f faultExecer) ExecContext(ctx context.Context, query string, args ...any) (sql.Result, error) {
+	if err := chaos.StorageFault(ctx); err != nil {
+		return nil, err
+	}
+	return f.queryExecer.ExecContext(ctx, query, args...)
+}
+
+func (f faultExecer) QueryContext(ctx context.Context, query string, args ...any) (*sql.Rows, error) {
+	if err := chaos.StorageFault(ctx); err != nil {
+		return nil, err
+	}
+	return f.queryExecer.QueryContext(ctx, query, args...)
+}
+
+// QueryRowContext cannot return a custom error through *sql.Row, so an injected
+// failure runs the query with a cancelled context and surfaces as context.Canceled.
+func (f faultExecer) QueryRowContext(ctx context.Context, query string, args ...any) *sql.Row {
+	if err := chaos.StorageFault(ctx); err != nil {
+		cancelled, cancel := context.WithCancel(ctx)
+		cancel()
+		return f.queryExecer.QueryRowContext(cancelled, query, args...)
 	}
-	return db
+	return f.queryExecer.QueryRowContext(ctx, query, args...)
 }
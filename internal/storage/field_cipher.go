@@ -0,0 +1,93 @@
+package storage
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"strings"
+)
+
+const (
+	fieldCipherVersion = "v1"
+	fieldKeySize       = 32
+)
+
+var ErrUnknownEncryptionKey = errors.New("unknown encryption key")
+
+// FieldCipher encrypts sensitive columns with AES-256-GCM. Values carry the id
+// of the key they were sealed with, so old keys can stay in the keyring for
+// reading while new writes use the primary key.
+type FieldCipher struct {
+	primary string
+	keys    map[string]cipher.AEAD
+}
+
+func NewFieldCipher(primary string, keys map[string][]byte) (*FieldCipher, error) {
+	if primary == "" {
+		return nil, errors.New("primary encryption key id cannot be empty")
+	}
+	if _, ok := keys[primary]; !ok {
+		return nil, fmt.Errorf("primary encryption key %q is not in the keyring", primary)
+	}
+	c := &FieldCipher{primary: primary, keys: make(map[string]cipher.AEAD, len(keys))}
+	for id, key := range keys {
+		if strings.Contains(id, ":") {
+			return nil, fmt.Errorf("encryption key id %q cannot contain ':'", id)
+		}
+		if len(key) != fieldKeySize {
+			return nil, fmt.Errorf("encryption key %q must be %d bytes", id, fieldKeySize)
+		}
+		block, err := aes.NewCipher(key)
+		if err != nil {
+			return nil, fmt.Errorf("encryption key %q: %w", id, err)
+		}
+		aead, err := cipher.NewGCM(block)
+		if err != nil {
+			return nil, fmt.Errorf("encryption key %q: %w", id, err)
+		}
+		c.keys[id] = aead
+	}
+	return c, nil
+}
+
+func (c *FieldCipher) PrimaryKeyID() string {
+	return c.primary
+}
+
+func (c *FieldCipher) Encrypt(plaintext string) (string, error) {
+	aead := c.keys[c.primary]
+	nonce := make([]byte, aead.NonceSize())
+	if _, err := rand.Read(nonce); err != nil {
+		return "", fmt.Errorf("generate nonce: %w", err)
+	}
+	sealed := aead.Seal(nonce, nonce, []byte(plaintext), []byte(c.primary))
+	return fieldCipherVersion + ":" + c.primary + ":" + base64.RawStdEncoding.EncodeToString(sealed), nil
+}
+
+func (c *FieldCipher) Decrypt(value string) (string, error) {
+	version, rest, ok := strings.Cut(value, ":")
+	if !ok || version != fieldCipherVersion {
+		return "", errors.New("malformed encrypted value")
+	}
+	keyID, encoded, ok := strings.Cut(rest, ":")
+	if !ok {
+		return "", errors.New("malformed encrypted value")
+	}
+	aead, ok := c.keys[keyID]
+	if !ok {
+		return "", fmt.Errorf("%w: %q", ErrUnknownEncryptionKey, keyID)
+	}
+	sealed, err := base64.RawStdEncoding.DecodeString(encoded)
+	if err != nil || len(sealed) < aead.NonceSize() {
+		return "", errors.New("malformed encrypted value")
+	}
+	nonce, ciphertext := sealed[:aead.NonceSize()], sealed[aead.NonceSize():]
+	plaintext, err := aead.Open(nil, nonce, ciphertext, []byte(keyID))
+	if err != nil {
+		return "", fmt.Errorf("decrypt value: %w", err)
+	}
+	return string(plaintext), nil
+}
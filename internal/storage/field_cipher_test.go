@@ -0,0 +1,63 @@
+package storage
+
+import (
+	"bytes"
+	"errors"
+	"strings"
+	"testing"
+)
+
+func testKey(b byte) []byte {
+	return bytes.Repeat([]byte{b}, fieldKeySize)
+}
+
+func TestFieldCipher_RoundTripAndRotation(t *testing.T) {
+	old, err := NewFieldCipher("k1", map[string][]byte{"k1": testKey(1)})
+	if err != nil {
+		t.Fatalf("NewFieldCipher returned err: %v", err)
+	}
+	sealed, err := old.Encrypt("xoxb-secret")
+	if err != nil {
+		t.Fatalf("Encrypt returned err: %v", err)
+	}
+	if strings.Contains(sealed, "xoxb-secret") || !strings.HasPrefix(sealed, "v1:k1:") {
+		t.Fatalf("unexpected sealed value %q", sealed)
+	}
+
+	rotated, err := NewFieldCipher("k2", map[string][]byte{"k1": testKey(1), "k2": testKey(2)})
+	if err != nil {
+		t.Fatalf("NewFieldCipher returned err: %v", err)
+	}
+	if got, err := rotated.Decrypt(sealed); err != nil || got != "xoxb-secret" {
+		t.Fatalf("expected old value readable after rotation, got %q, %v", got, err)
+	}
+	resealed, _ := rotated.Encrypt("xoxb-secret")
+	if !strings.HasPrefix(resealed, "v1:k2:") {
+		t.Fatalf("expected new writes to use k2, got %q", resealed)
+	}
+
+	if _, err := old.Decrypt(resealed); !errors.Is(err, ErrUnknownEncryptionKey) {
+		t.Fatalf("expected ErrUnknownEncryptionKey, got %v", err)
+	}
+	tampered := resealed[:len(resealed)-2] + "AA"
+	if _, err := rotated.Decrypt(tampered); err == nil {
+		t.Fatal("expected tampered value to be rejected")
+	}
+}
+
+func TestNewFieldCipher_Validation(t *testing.T) {
+	cases := map[string]struct {
+		primary string
+		keys    map[string][]byte
+	}{
+		"empty primary":   {primary: "", keys: map[string][]byte{"k1": testKey(1)}},
+		"missing primary": {primary: "k2", keys: map[string][]byte{"k1": testKey(1)}},
+		"short key":       {primary: "k1", keys: map[string][]byte{"k1": []byte("short")}},
+		"colon in id":     {primary: "k:1", keys: map[string][]byte{"k:1": testKey(1)}},
+	}
+	for name, tc := range cases {
+		if _, err := NewFieldCipher(tc.primary, tc.keys); err == nil {
+			t.Fatalf("%s: expected error", name)
+		}
+	}
+}
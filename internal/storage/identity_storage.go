@@ -0,0 +1,66 @@
+package storage
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"log/slog"
+
+	"github.com/cloudyy74/pr-reviewer-service/pkg/postgres"
+)
+
+var ErrIdentityNotFound = errors.New("identity not found")
+
+type IdentityStorage struct {
+	db  *postgres.Postgres
+	log *slog.Logger
+}
+
+func NewIdentityStorage(db *postgres.Postgres, log *slog.Logger) (*IdentityStorage, error) {
+	if db == nil {
+		return nil, errors.New("database cannot be nil")
+	}
+	if log == nil {
+		return nil, errors.New("logger cannot be nil")
+	}
+	return &IdentityStorage{
+		db:  db,
+		log: log,
+	}, nil
+}
+
+func (s *IdentityStorage) GetUserIDByIdentity(ctx context.Context, issuer, subject string) (string, error) {
+	exec := getQueryExecer(ctx, s.db.DB)
+	var userID string
+	err := exec.QueryRowContext(
+		ctx,
+		`select user_id from user_identities where issuer = $1 and subject = $2`,
+		issuer,
+		subject,
+	).Scan(&userID)
+	if errors.Is(err, sql.ErrNoRows) {
+		return "", fmt.Errorf("get identity: %w", ErrIdentityNotFound)
+	}
+	if err != nil {
+		s.log.Error("failed to get identity", slog.Any("error", err), slog.String("subject", subject))
+		return "", fmt.Errorf("get identity: %w", err)
+	}
+	return userID, nil
+}
+
+func (s *IdentityStorage) LinkIdentity(ctx context.Context, issuer, subject, userID string) error {
+	exec := getExecer(ctx, s.db.DB)
+	_, err := exec.ExecContext(
+		ctx,
+		`insert into user_identities (issuer, subject, user_id) values ($1, $2, $3) on conflict do nothing`,
+		issuer,
+		subject,
+		userID,
+	)
+	if err != nil {
+		s.log.Error("failed to link identity", slog.Any("error", err), slog.String("user_id", userID))
+		return fmt.Errorf("link identity: %w", err)
+	}
+	return nil
+}
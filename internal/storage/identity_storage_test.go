@@ -0,0 +1,60 @@
+package storage
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"io"
+	"log/slog"
+	"regexp"
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+
+	"github.com/cloudyy74/pr-reviewer-service/pkg/postgres"
+)
+
+func newIdentityStorage(t *testing.T) (*IdentityStorage, sqlmock.Sqlmock) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("create sqlmock: %v", err)
+	}
+	t.Cleanup(func() { db.Close() })
+
+	st, err := NewIdentityStorage(&postgres.Postgres{DB: db}, slog.New(slog.NewTextHandler(io.Discard, nil)))
+	if err != nil {
+		t.Fatalf("NewIdentityStorage: %v", err)
+	}
+	return st, mock
+}
+
+func TestIdentityStorage_GetUserIDByIdentity(t *testing.T) {
+	st, mock := newIdentityStorage(t)
+	mock.ExpectQuery(regexp.QuoteMeta(`select user_id from user_identities where issuer = $1 and subject = $2`)).
+		WithArgs("https://idp", "sub-1").
+		WillReturnRows(sqlmock.NewRows([]string{"user_id"}).AddRow("u1"))
+	mock.ExpectQuery(regexp.QuoteMeta(`from user_identities`)).
+		WithArgs("https://idp", "sub-2").
+		WillReturnError(sql.ErrNoRows)
+
+	userID, err := st.GetUserIDByIdentity(context.Background(), "https://idp", "sub-1")
+	if err != nil || userID != "u1" {
+		t.Fatalf("expected u1, got %q, %v", userID, err)
+	}
+	if _, err := st.GetUserIDByIdentity(context.Background(), "https://idp", "sub-2"); !errors.Is(err, ErrIdentityNotFound) {
+		t.Fatalf("expected ErrIdentityNotFound, got %v", err)
+	}
+	verifyExpectations(t, mock)
+}
+
+func TestIdentityStorage_LinkIdentity(t *testing.T) {
+	st, mock := newIdentityStorage(t)
+	mock.ExpectExec(regexp.QuoteMeta(`insert into user_identities (issuer, subject, user_id) values ($1, $2, $3) on conflict do nothing`)).
+		WithArgs("https://idp", "sub-1", "u1").
+		WillReturnResult(sqlmock.NewResult(0, 1))
+
+	if err := st.LinkIdentity(context.Background(), "https://idp", "sub-1", "u1"); err != nil {
+		t.Fatalf("LinkIdentity returned err: %v", err)
+	}
+	verifyExpectations(t, mock)
+}
@@ -0,0 +1,144 @@
+package storage
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"log/slog"
+
+	"github.com/cloudyy74/pr-reviewer-service/internal/models"
+	"github.com/cloudyy74/pr-reviewer-service/pkg/postgres"
+)
+
+var ErrIntegrationTokenNotFound = errors.New("integration token not found")
+
+type IntegrationTokenStorage struct {
+	db     *postgres.Postgres
+	cipher *FieldCipher
+	log    *slog.Logger
+}
+
+func NewIntegrationTokenStorage(db *postgres.Postgres, cipher *FieldCipher, log *slog.Logger) (*IntegrationTokenStorage, error) {
+	if db == nil {
+		return nil, errors.New("database cannot be nil")
+	}
+	if cipher == nil {
+		return nil, errors.New("cipher cannot be nil")
+	}
+	if log == nil {
+		return nil, errors.New("logger cannot be nil")
+	}
+	return &IntegrationTokenStorage{
+		db:     db,
+		cipher: cipher,
+		log:    log,
+	}, nil
+}
+
+func (s *IntegrationTokenStorage) SetIntegrationToken(ctx context.Context, teamName, provider, token string) (*models.IntegrationToken, error) {
+	encrypted, err := s.cipher.Encrypt(token)
+	if err != nil {
+		return nil, fmt.Errorf("encrypt integration token: %w", err)
+	}
+	exec := getQueryExecer(ctx, s.db.DB)
+	info := &models.IntegrationToken{TeamName: teamName, Provider: provider, KeyID: s.cipher.PrimaryKeyID()}
+	err = exec.QueryRowContext(
+		ctx,
+		`
+insert into integration_tokens (team_name, provider, token_encrypted, key_id) values ($1, $2, $3, $4)
+on conflict (team_name, provider) do update set
+token_encrypted = excluded.token_encrypted,
+key_id = excluded.key_id,
+updated_at = now()
+returning updated_at`,
+		teamName,
+		provider,
+		encrypted,
+		info.KeyID,
+	).Scan(&info.UpdatedAt)
+	if err != nil {
+		s.log.Error("failed to set integration token", slog.Any("error", err), slog.String("team", teamName), slog.String("provider", provider))
+		return nil, fmt.Errorf("set integration token: %w", err)
+	}
+	return info, nil
+}
+
+func (s *IntegrationTokenStorage) GetIntegrationToken(ctx context.Context, teamName, provider string) (string, error) {
+	exec := getQueryExecer(ctx, s.db.DB)
+	var encrypted string
+	err := exec.QueryRowContext(
+		ctx,
+		`select token_encrypted from integration_tokens where team_name = $1 and provider = $2`,
+		teamName,
+		provider,
+	).Scan(&encrypted)
+	if errors.Is(err, sql.ErrNoRows) {
+		return "", fmt.Errorf("get integration token: %w", ErrIntegrationTokenNotFound)
+	}
+	if err != nil {
+		s.log.Error("failed to get integration token", slog.Any("error", err), slog.String("team", teamName), slog.String("provider", provider))
+		return "", fmt.Errorf("get integration token: %w", err)
+	}
+	token, err := s.cipher.Decrypt(encrypted)
+	if err != nil {
+		return "", fmt.Errorf("get integration token: %w", err)
+	}
+	return token, nil
+}
+
+// ReencryptIntegrationTokens re-seals every token that is not encrypted with
+// the primary key and returns how many rows were rewritten.
+func (s *IntegrationTokenStorage) ReencryptIntegrationTokens(ctx context.Context) (int, error) {
+	exec := getQueryExecer(ctx, s.db.DB)
+	rows, err := exec.QueryContext(
+		ctx,
+		`select team_name, provider, token_encrypted from integration_tokens where key_id <> $1 for update`,
+		s.cipher.PrimaryKeyID(),
+	)
+	if err != nil {
+		s.log.Error("failed to select integration tokens for rotation", slog.Any("error", err))
+		return 0, fmt.Errorf("select integration tokens: %w", err)
+	}
+	type staleToken struct {
+		team, provider, encrypted string
+	}
+	var stale []staleToken
+	for rows.Next() {
+		var t staleToken
+		if err := rows.Scan(&t.team, &t.provider, &t.encrypted); err != nil {
+			rows.Close()
+			return 0, fmt.Errorf("scan integration token: %w", err)
+		}
+		stale = append(stale, t)
+	}
+	if err := rows.Close(); err != nil {
+		return 0, fmt.Errorf("close integration token rows: %w", err)
+	}
+	if err := rows.Err(); err != nil {
+		return 0, fmt.Errorf("iterate integration tokens: %w", err)
+	}
+
+	for _, t := range stale {
+		token, err := s.cipher.Decrypt(t.encrypted)
+		if err != nil {
+			return 0, fmt.Errorf("decrypt integration token %s/%s: %w", t.team, t.provider, err)
+		}
+		encrypted, err := s.cipher.Encrypt(token)
+		if err != nil {
+			return 0, fmt.Errorf("encrypt integration token %s/%s: %w", t.team, t.provider, err)
+		}
+		if _, err := exec.ExecContext(
+			ctx,
+			`update integration_tokens set token_encrypted = $3, key_id = $4 where team_name = $1 and provider = $2`,
+			t.team,
+			t.provider,
+			encrypted,
+			s.cipher.PrimaryKeyID(),
+		); err != nil {
+			s.log.Error("failed to update integration token", slog.Any("error", err), slog.String("team", t.team))
+			return 0, fmt.Errorf("update integration token: %w", err)
+		}
+	}
+	return len(stale), nil
+}
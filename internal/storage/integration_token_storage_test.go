@@ -0,0 +1,84 @@
+package storage
+
+import (
+	"context"
+	"database/sql/driver"
+	"io"
+	"log/slog"
+	"regexp"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+
+	"github.com/cloudyy74/pr-reviewer-service/pkg/postgres"
+)
+
+type sealedWith string
+
+func (prefix sealedWith) Match(v driver.Value) bool {
+	s, ok := v.(string)
+	return ok && strings.HasPrefix(s, string(prefix))
+}
+
+func newIntegrationTokenStorage(t *testing.T, cipher *FieldCipher) (*IntegrationTokenStorage, sqlmock.Sqlmock) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("create sqlmock: %v", err)
+	}
+	t.Cleanup(func() { db.Close() })
+
+	st, err := NewIntegrationTokenStorage(&postgres.Postgres{DB: db}, cipher, slog.New(slog.NewTextHandler(io.Discard, nil)))
+	if err != nil {
+		t.Fatalf("NewIntegrationTokenStorage: %v", err)
+	}
+	return st, mock
+}
+
+func TestIntegrationTokenStorage_SetAndGet(t *testing.T) {
+	cipher, _ := NewFieldCipher("k1", map[string][]byte{"k1": testKey(1)})
+	st, mock := newIntegrationTokenStorage(t, cipher)
+	sealed, _ := cipher.Encrypt("ghp_secret")
+	updatedAt := time.Date(2025, 3, 1, 10, 0, 0, 0, time.UTC)
+
+	mock.ExpectQuery(regexp.QuoteMeta(`insert into integration_tokens (team_name, provider, token_encrypted, key_id) values ($1, $2, $3, $4)`)).
+		WithArgs("backend", "github", sealedWith("v1:k1:"), "k1").
+		WillReturnRows(sqlmock.NewRows([]string{"updated_at"}).AddRow(updatedAt))
+	mock.ExpectQuery(regexp.QuoteMeta(`select token_encrypted from integration_tokens where team_name = $1 and provider = $2`)).
+		WithArgs("backend", "github").
+		WillReturnRows(sqlmock.NewRows([]string{"token_encrypted"}).AddRow(sealed))
+
+	info, err := st.SetIntegrationToken(context.Background(), "backend", "github", "ghp_secret")
+	if err != nil {
+		t.Fatalf("SetIntegrationToken returned err: %v", err)
+	}
+	if info.KeyID != "k1" || !info.UpdatedAt.Equal(updatedAt) {
+		t.Fatalf("unexpected info: %+v", info)
+	}
+	token, err := st.GetIntegrationToken(context.Background(), "backend", "github")
+	if err != nil || token != "ghp_secret" {
+		t.Fatalf("expected decrypted token, got %q, %v", token, err)
+	}
+	verifyExpectations(t, mock)
+}
+
+func TestIntegrationTokenStorage_Reencrypt(t *testing.T) {
+	old, _ := NewFieldCipher("k1", map[string][]byte{"k1": testKey(1)})
+	sealed, _ := old.Encrypt("xoxb-secret")
+	cipher, _ := NewFieldCipher("k2", map[string][]byte{"k1": testKey(1), "k2": testKey(2)})
+	st, mock := newIntegrationTokenStorage(t, cipher)
+
+	mock.ExpectQuery(regexp.QuoteMeta(`select team_name, provider, token_encrypted from integration_tokens where key_id <> $1 for update`)).
+		WithArgs("k2").
+		WillReturnRows(sqlmock.NewRows([]string{"team_name", "provider", "token_encrypted"}).AddRow("backend", "slack", sealed))
+	mock.ExpectExec(regexp.QuoteMeta(`update integration_tokens set token_encrypted = $3, key_id = $4 where team_name = $1 and provider = $2`)).
+		WithArgs("backend", "slack", sealedWith("v1:k2:"), "k2").
+		WillReturnResult(sqlmock.NewResult(0, 1))
+
+	n, err := st.ReencryptIntegrationTokens(context.Background())
+	if err != nil || n != 1 {
+		t.Fatalf("expected 1 rotated token, got %d, %v", n, err)
+	}
+	verifyExpectations(t, mock)
+}
@@ -0,0 +1,98 @@
+package storage
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"time"
+
+	"github.com/cloudyy74/pr-reviewer-service/internal/models"
+	"github.com/cloudyy74/pr-reviewer-service/pkg/postgres"
+)
+
+// JiraTaskStorage remembers which Jira sub-task belongs to which reviewer
+// assignment, so a retried event does not create the sub-task twice.
+type JiraTaskStorage struct {
+	db  *postgres.Postgres
+	log *slog.Logger
+}
+
+func NewJiraTaskStorage(db *postgres.Postgres, log *slog.Logger) (*JiraTaskStorage, error) {
+	if db == nil {
+		return nil, errors.New("database cannot be nil")
+	}
+	if log == nil {
+		return nil, errors.New("logger cannot be nil")
+	}
+	return &JiraTaskStorage{
+		db:  db,
+		log: log,
+	}, nil
+}
+
+// GetOpenJiraTasks returns the sub-tasks of a pull request that have not
+// been transitioned yet, ordered by user.
+func (s *JiraTaskStorage) GetOpenJiraTasks(ctx context.Context, prID string) ([]*models.JiraTask, error) {
+	exec := getQueryExecer(ctx, s.db.DB)
+	rows, err := exec.QueryContext(
+		ctx,
+		`
+select issue_key, pull_request_id, user_id, created_at
+from jira_review_tasks
+where pull_request_id = $1
+  and done_at is null
+order by user_id, created_at
+`,
+		prID,
+	)
+	if err != nil {
+		s.log.Error("failed to get open jira tasks", slog.Any("error", err), slog.String("pr_id", prID))
+		return nil, fmt.Errorf("get open jira tasks: %w", err)
+	}
+	defer rows.Close()
+
+	tasks := make([]*models.JiraTask, 0)
+	for rows.Next() {
+		var task models.JiraTask
+		if err := rows.Scan(&task.IssueKey, &task.PullRequestID, &task.UserID, &task.CreatedAt); err != nil {
+			return nil, fmt.Errorf("scan jira task: %w", err)
+		}
+		tasks = append(tasks, &task)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("iterate jira tasks: %w", err)
+	}
+	return tasks, nil
+}
+
+func (s *JiraTaskStorage) AddJiraTask(ctx context.Context, task *models.JiraTask) error {
+	exec := getExecer(ctx, s.db.DB)
+	_, err := exec.ExecContext(
+		ctx,
+		`insert into jira_review_tasks (issue_key, pull_request_id, user_id) values ($1, $2, $3) on conflict (issue_key) do nothing`,
+		task.IssueKey,
+		task.PullRequestID,
+		task.UserID,
+	)
+	if err != nil {
+		s.log.Error("failed to add jira task", slog.Any("error", err), slog.String("issue_key", task.IssueKey))
+		return fmt.Errorf("add jira task: %w", err)
+	}
+	return nil
+}
+
+func (s *JiraTaskStorage) MarkJiraTaskDone(ctx context.Context, issueKey string, at time.Time) error {
+	exec := getExecer(ctx, s.db.DB)
+	_, err := exec.ExecContext(
+		ctx,
+		`update jira_review_tasks set done_at = $2 where issue_key = $1 and done_at is null`,
+		issueKey,
+		at,
+	)
+	if err != nil {
+		s.log.Error("failed to mark jira task done", slog.Any("error", err), slog.String("issue_key", issueKey))
+		return fmt.Errorf("mark jira task done: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,61 @@
+package storage
+
+import (
+	"context"
+	"io"
+	"log/slog"
+	"regexp"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+
+	"github.com/cloudyy74/pr-reviewer-service/internal/models"
+	"github.com/cloudyy74/pr-reviewer-service/pkg/postgres"
+)
+
+func newJiraTaskStorage(t *testing.T) (*JiraTaskStorage, sqlmock.Sqlmock) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("create sqlmock: %v", err)
+	}
+	t.Cleanup(func() { db.Close() })
+
+	st, err := NewJiraTaskStorage(&postgres.Postgres{DB: db}, slog.New(slog.NewTextHandler(io.Discard, nil)))
+	if err != nil {
+		t.Fatalf("NewJiraTaskStorage: %v", err)
+	}
+	return st, mock
+}
+
+func TestJiraTaskStorage(t *testing.T) {
+	st, mock := newJiraTaskStorage(t)
+	createdAt := time.Date(2025, 3, 1, 10, 0, 0, 0, time.UTC)
+	mock.ExpectExec(regexp.QuoteMeta(`insert into jira_review_tasks (issue_key, pull_request_id, user_id) values ($1, $2, $3) on conflict (issue_key) do nothing`)).
+		WithArgs("PAY-2", "pr1", "u1").
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectQuery(regexp.QuoteMeta(`where pull_request_id = $1
+  and done_at is null`)).
+		WithArgs("pr1").
+		WillReturnRows(sqlmock.NewRows([]string{"issue_key", "pull_request_id", "user_id", "created_at"}).
+			AddRow("PAY-2", "pr1", "u1", createdAt))
+	mock.ExpectExec(regexp.QuoteMeta(`update jira_review_tasks set done_at = $2 where issue_key = $1 and done_at is null`)).
+		WithArgs("PAY-2", createdAt).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+
+	ctx := context.Background()
+	if err := st.AddJiraTask(ctx, &models.JiraTask{IssueKey: "PAY-2", PullRequestID: "pr1", UserID: "u1"}); err != nil {
+		t.Fatalf("AddJiraTask returned err: %v", err)
+	}
+	tasks, err := st.GetOpenJiraTasks(ctx, "pr1")
+	if err != nil {
+		t.Fatalf("GetOpenJiraTasks returned err: %v", err)
+	}
+	if len(tasks) != 1 || tasks[0].IssueKey != "PAY-2" || !tasks[0].CreatedAt.Equal(createdAt) {
+		t.Fatalf("unexpected tasks: %#v", tasks)
+	}
+	if err := st.MarkJiraTaskDone(ctx, "PAY-2", createdAt); err != nil {
+		t.Fatalf("MarkJiraTaskDone returned err: %v", err)
+	}
+	verifyExpectations(t, mock)
+}
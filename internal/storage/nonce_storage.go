@@ -0,0 +1,64 @@
+package storage
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"time"
+
+	"github.com/cloudyy74/pr-reviewer-service/pkg/postgres"
+)
+
+type NonceStorage struct {
+	db  *postgres.Postgres
+	log *slog.Logger
+}
+
+func NewNonceStorage(db *postgres.Postgres, log *slog.Logger) (*NonceStorage, error) {
+	if db == nil {
+		return nil, errors.New("database cannot be nil")
+	}
+	if log == nil {
+		return nil, errors.New("logger cannot be nil")
+	}
+	return &NonceStorage{
+		db:  db,
+		log: log,
+	}, nil
+}
+
+// RememberNonce records a nonce of a client and reports whether it is new.
+// A nonce whose row has expired but is not yet deleted still counts as seen;
+// the signature check rejects such requests as stale before they get here.
+func (s *NonceStorage) RememberNonce(ctx context.Context, clientID, nonce string, expiresAt time.Time) (bool, error) {
+	exec := getExecer(ctx, s.db.DB)
+	res, err := exec.ExecContext(
+		ctx,
+		`
+insert into request_nonces (client_id, nonce, expires_at) values ($1, $2, $3)
+on conflict (client_id, nonce) do nothing`,
+		clientID,
+		nonce,
+		expiresAt,
+	)
+	if err != nil {
+		s.log.Error("failed to remember nonce", slog.Any("error", err), slog.String("client_id", clientID))
+		return false, fmt.Errorf("remember nonce: %w", err)
+	}
+	inserted, err := res.RowsAffected()
+	if err != nil {
+		return false, fmt.Errorf("remember nonce: %w", err)
+	}
+	return inserted == 1, nil
+}
+
+func (s *NonceStorage) DeleteExpiredNonces(ctx context.Context) (int64, error) {
+	exec := getExecer(ctx, s.db.DB)
+	res, err := exec.ExecContext(ctx, `delete from request_nonces where expires_at <= now()`)
+	if err != nil {
+		s.log.Error("failed to delete expired nonces", slog.Any("error", err))
+		return 0, fmt.Errorf("delete expired nonces: %w", err)
+	}
+	return res.RowsAffected()
+}
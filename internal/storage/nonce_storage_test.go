@@ -0,0 +1,38 @@
+package storage
+
+import (
+	"context"
+	"io"
+	"log/slog"
+	"regexp"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+
+	"github.com/cloudyy74/pr-reviewer-service/pkg/postgres"
+)
+
+func TestNonceStorage_RememberNonce(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("create sqlmock: %v", err)
+	}
+	t.Cleanup(func() { db.Close() })
+	st, err := NewNonceStorage(&postgres.Postgres{DB: db}, slog.New(slog.NewTextHandler(io.Discard, nil)))
+	if err != nil {
+		t.Fatalf("NewNonceStorage: %v", err)
+	}
+	expires := time.Date(2025, 3, 1, 10, 5, 0, 0, time.UTC)
+	query := regexp.QuoteMeta(`on conflict (client_id, nonce) do nothing`)
+	mock.ExpectExec(query).WithArgs("billing", "abc", expires).WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectExec(query).WithArgs("billing", "abc", expires).WillReturnResult(sqlmock.NewResult(0, 0))
+
+	if fresh, err := st.RememberNonce(context.Background(), "billing", "abc", expires); err != nil || !fresh {
+		t.Fatalf("expected a new nonce, got %v, %v", fresh, err)
+	}
+	if fresh, err := st.RememberNonce(context.Background(), "billing", "abc", expires); err != nil || fresh {
+		t.Fatalf("expected a seen nonce, got %v, %v", fresh, err)
+	}
+	verifyExpectations(t, mock)
+}
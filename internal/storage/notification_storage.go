@@ -0,0 +1,147 @@
+package storage
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+
+	"github.com/cloudyy74/pr-reviewer-service/internal/models"
+	"github.com/cloudyy74/pr-reviewer-service/pkg/postgres"
+)
+
+type NotificationStorage struct {
+	db  *postgres.Postgres
+	log *slog.Logger
+}
+
+func NewNotificationStorage(db *postgres.Postgres, log *slog.Logger) (*NotificationStorage, error) {
+	if db == nil {
+		return nil, errors.New("database cannot be nil")
+	}
+	if log == nil {
+		return nil, errors.New("logger cannot be nil")
+	}
+	return &NotificationStorage{
+		db:  db,
+		log: log,
+	}, nil
+}
+
+func (s *NotificationStorage) RecordNotification(ctx context.Context, attempt models.NotificationAttempt) error {
+	prIDs := attempt.PullRequestIDs
+	if prIDs == nil {
+		prIDs = []string{}
+	}
+	payload, err := json.Marshal(prIDs)
+	if err != nil {
+		return fmt.Errorf("marshal pull request ids: %w", err)
+	}
+	exec := getExecer(ctx, s.db.DB)
+	_, err = exec.ExecContext(
+		ctx,
+		`
+insert into notifications (dedup_key, user_id, channel, event, pull_request_ids, status, last_error)
+values ($1, $2, $3, $4, $5, $6, nullif($7, ''))
+on conflict (dedup_key) do update set
+status = excluded.status,
+attempts = notifications.attempts + 1,
+last_error = excluded.last_error,
+pull_request_ids = excluded.pull_request_ids,
+updated_at = now()`,
+		attempt.Key,
+		attempt.UserID,
+		attempt.Channel,
+		attempt.Event,
+		payload,
+		attempt.Status,
+		attempt.Error,
+	)
+	if err != nil {
+		s.log.Error("failed to record notification", slog.Any("error", err), slog.String("user_id", attempt.UserID))
+		return fmt.Errorf("record notification: %w", err)
+	}
+	return nil
+}
+
+func (s *NotificationStorage) ListNotifications(ctx context.Context, q models.NotificationQuery) ([]*models.NotificationRecord, int, error) {
+	exec := getQueryExecer(ctx, s.db.DB)
+
+	var total int
+	err := exec.QueryRowContext(
+		ctx,
+		`
+select count(*)
+from notifications n
+where ($1 = '' or n.user_id = $1)
+  and ($2 = '' or n.pull_request_ids @> jsonb_build_array($2::text))
+  and ($3 = '' or n.channel = $3)
+  and ($4 = '' or n.status = $4)
+  and ($5::timestamptz is null or n.created_at >= $5)
+  and ($6::timestamptz is null or n.created_at < $6)
+`,
+		q.UserID,
+		q.PullRequestID,
+		q.Channel,
+		q.Status,
+		q.From,
+		q.To,
+	).Scan(&total)
+	if err != nil {
+		s.log.Error("failed to count notifications", slog.Any("error", err))
+		return nil, 0, fmt.Errorf("count notifications: %w", err)
+	}
+
+	rows, err := exec.QueryContext(
+		ctx,
+		`
+select n.id, n.user_id, n.channel, n.event, n.pull_request_ids, n.status, n.attempts,
+    coalesce(n.last_error, ''), n.created_at, n.updated_at
+from notifications n
+where ($1 = '' or n.user_id = $1)
+  and ($2 = '' or n.pull_request_ids @> jsonb_build_array($2::text))
+  and ($3 = '' or n.channel = $3)
+  and ($4 = '' or n.status = $4)
+  and ($5::timestamptz is null or n.created_at >= $5)
+  and ($6::timestamptz is null or n.created_at < $6)
+order by n.created_at desc, n.id desc
+limit $7 offset $8
+`,
+		q.UserID,
+		q.PullRequestID,
+		q.Channel,
+		q.Status,
+		q.From,
+		q.To,
+		q.Limit,
+		q.Offset,
+	)
+	if err != nil {
+		s.log.Error("failed to list notifications", slog.Any("error", err))
+		return nil, 0, fmt.Errorf("list notifications: %w", err)
+	}
+	defer rows.Close()
+
+	records := make([]*models.NotificationRecord, 0)
+	for rows.Next() {
+		var (
+			rec   models.NotificationRecord
+			prIDs []byte
+		)
+		if err := rows.Scan(
+			&rec.ID, &rec.UserID, &rec.Channel, &rec.Event, &prIDs, &rec.Status, &rec.Attempts,
+			&rec.LastError, &rec.CreatedAt, &rec.UpdatedAt,
+		); err != nil {
+			return nil, 0, fmt.Errorf("scan notification: %w", err)
+		}
+		if err := json.Unmarshal(prIDs, &rec.PullRequestIDs); err != nil {
+			return nil, 0, fmt.Errorf("decode pull request ids: %w", err)
+		}
+		records = append(records, &rec)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, 0, fmt.Errorf("iterate notifications: %w", err)
+	}
+	return records, total, nil
+}
@@ -0,0 +1,78 @@
+package storage
+
+import (
+	"context"
+	"io"
+	"log/slog"
+	"regexp"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+
+	"github.com/cloudyy74/pr-reviewer-service/internal/models"
+	"github.com/cloudyy74/pr-reviewer-service/pkg/postgres"
+)
+
+func newNotificationStorage(t *testing.T) (*NotificationStorage, sqlmock.Sqlmock) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("create sqlmock: %v", err)
+	}
+	t.Cleanup(func() { db.Close() })
+
+	st, err := NewNotificationStorage(&postgres.Postgres{DB: db}, slog.New(slog.NewTextHandler(io.Discard, nil)))
+	if err != nil {
+		t.Fatalf("NewNotificationStorage: %v", err)
+	}
+	return st, mock
+}
+
+func TestNotificationStorage_RecordNotification(t *testing.T) {
+	st, mock := newNotificationStorage(t)
+	mock.ExpectExec(regexp.QuoteMeta(`attempts = notifications.attempts + 1`)).
+		WithArgs("assignment:u1:pr-1:1", "u1", "log", models.NotificationEventAssignment, []byte(`["pr-1"]`), models.NotificationFailed, "timeout").
+		WillReturnResult(sqlmock.NewResult(1, 1))
+
+	err := st.RecordNotification(context.Background(), models.NotificationAttempt{
+		Key:            "assignment:u1:pr-1:1",
+		UserID:         "u1",
+		Channel:        "log",
+		Event:          models.NotificationEventAssignment,
+		PullRequestIDs: []string{"pr-1"},
+		Status:         models.NotificationFailed,
+		Error:          "timeout",
+	})
+	if err != nil {
+		t.Fatalf("RecordNotification returned err: %v", err)
+	}
+	verifyExpectations(t, mock)
+}
+
+func TestNotificationStorage_ListNotifications(t *testing.T) {
+	st, mock := newNotificationStorage(t)
+	now := time.Date(2025, 3, 1, 10, 0, 0, 0, time.UTC)
+	q := models.NotificationQuery{UserID: "u1", Status: models.NotificationFailed, Limit: 10}
+
+	mock.ExpectQuery(regexp.QuoteMeta(`select count(*)
+from notifications n`)).
+		WithArgs("u1", "", "", models.NotificationFailed, nil, nil).
+		WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(1))
+	mock.ExpectQuery(regexp.QuoteMeta(`order by n.created_at desc, n.id desc`)).
+		WithArgs("u1", "", "", models.NotificationFailed, nil, nil, 10, 0).
+		WillReturnRows(sqlmock.NewRows([]string{
+			"id", "user_id", "channel", "event", "pull_request_ids", "status", "attempts", "last_error", "created_at", "updated_at",
+		}).AddRow(int64(7), "u1", "log", models.NotificationEventDigest, []byte(`["pr-1","pr-2"]`), models.NotificationFailed, 3, "timeout", now, now))
+
+	records, total, err := st.ListNotifications(context.Background(), q)
+	if err != nil {
+		t.Fatalf("ListNotifications returned err: %v", err)
+	}
+	if total != 1 || len(records) != 1 {
+		t.Fatalf("unexpected result: %d, %v", total, records)
+	}
+	if got := records[0]; got.Attempts != 3 || len(got.PullRequestIDs) != 2 || got.LastError != "timeout" {
+		t.Fatalf("unexpected record: %+v", got)
+	}
+	verifyExpectations(t, mock)
+}
@@ -0,0 +1,170 @@
+package storage
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"sync/atomic"
+
+	"github.com/cloudyy74/pr-reviewer-service/pkg/postgres"
+)
+
+type MigrationPhase string
+
+const (
+	MigrationPhaseOff       MigrationPhase = "off"
+	MigrationPhaseDualWrite MigrationPhase = "dual_write"
+	MigrationPhaseCutover   MigrationPhase = "cutover"
+)
+
+var ErrUnknownMigrationPhase = errors.New("unknown migration phase")
+
+func ParseMigrationPhase(s string) (MigrationPhase, error) {
+	switch p := MigrationPhase(s); p {
+	case "":
+		return MigrationPhaseOff, nil
+	case MigrationPhaseOff, MigrationPhaseDualWrite, MigrationPhaseCutover:
+		return p, nil
+	default:
+		return "", fmt.Errorf("%w: %s", ErrUnknownMigrationPhase, s)
+	}
+}
+
+// WritesNew reports whether writers must keep the new column in sync.
+func (p MigrationPhase) WritesNew() bool {
+	return p == MigrationPhaseDualWrite || p == MigrationPhaseCutover
+}
+
+// ReadsNew reports whether readers should prefer the new column.
+func (p MigrationPhase) ReadsNew() bool {
+	return p == MigrationPhaseCutover
+}
+
+type phaseFlag struct {
+	v atomic.Value
+}
+
+func (f *phaseFlag) get() MigrationPhase {
+	if p, ok := f.v.Load().(MigrationPhase); ok {
+		return p
+	}
+	return MigrationPhaseOff
+}
+
+func (f *phaseFlag) set(p MigrationPhase) {
+	f.v.Store(p)
+}
+
+// prStatusColumn is embedded by every storage that reads pull request
+// status. Status predicates and projections go through statusColumn, so a
+// PRStatusEnumMigration cutover moves all reads to status_enum and the old
+// column can be dropped afterwards.
+type prStatusColumn struct {
+	statusPhase phaseFlag
+}
+
+// SetStatusMigrationPhase switches how the status_enum column introduced by
+// PRStatusEnumMigration is written and read. It is safe to call while
+// requests are in flight.
+func (c *prStatusColumn) SetStatusMigrationPhase(phase MigrationPhase) {
+	c.statusPhase.set(phase)
+}
+
+// statusColumn returns the status of the pull_requests row aliased as alias,
+// or of the unqualified table when alias is empty.
+func (c *prStatusColumn) statusColumn(alias string) string {
+	if alias != "" {
+		alias += "."
+	}
+	if c.statusPhase.get().ReadsNew() {
+		return "coalesce(" + alias + "status_enum::text, " + alias + "status)"
+	}
+	return alias + "status"
+}
+
+// ColumnMigration describes a column being replaced online: writers fill
+// both columns, a backfill job copies Convert into NewColumn for older
+// rows, and readers switch to NewColumn once nothing is left to copy.
+type ColumnMigration struct {
+	Name      string
+	Table     string
+	OldColumn string
+	NewColumn string
+	Convert   string
+}
+
+// PRStatusEnumMigration moves pull request status from the varchar column to
+// the pr_status enum. It is the only registered migration: reviewer
+// assigned_at (000005) has no old column to convert from, and adding it with
+// default now() does not rewrite the table on PostgreSQL 11+.
+var PRStatusEnumMigration = ColumnMigration{
+	Name:      "pr_status_enum",
+	Table:     "pull_requests",
+	OldColumn: "status",
+	NewColumn: "status_enum",
+	Convert:   "status::pr_status",
+}
+
+type OnlineMigrationStorage struct {
+	db  *postgres.Postgres
+	log *slog.Logger
+}
+
+func NewOnlineMigrationStorage(db *postgres.Postgres, log *slog.Logger) (*OnlineMigrationStorage, error) {
+	if db == nil {
+		return nil, errors.New("database cannot be nil")
+	}
+	if log == nil {
+		return nil, errors.New("logger cannot be nil")
+	}
+	return &OnlineMigrationStorage{
+		db:  db,
+		log: log,
+	}, nil
+}
+
+// Backfill copies up to batch out-of-sync rows into the new column. Rows
+// locked by concurrent writers are skipped and picked up by a later batch.
+func (s *OnlineMigrationStorage) Backfill(ctx context.Context, m ColumnMigration, batch int) (int64, error) {
+	exec := getExecer(ctx, s.db.DB)
+	res, err := exec.ExecContext(
+		ctx,
+		fmt.Sprintf(`
+update %[1]s
+set %[2]s = %[3]s
+where ctid in (
+    select ctid
+    from %[1]s
+    where %[2]s is distinct from %[3]s
+    limit $1
+    for update skip locked
+)`, m.Table, m.NewColumn, m.Convert),
+		batch,
+	)
+	if err != nil {
+		s.log.Error("failed to backfill column", slog.Any("error", err), slog.String("migration", m.Name))
+		return 0, fmt.Errorf("backfill %s: %w", m.Name, err)
+	}
+	rows, err := res.RowsAffected()
+	if err != nil {
+		return 0, fmt.Errorf("rows affected: %w", err)
+	}
+	return rows, nil
+}
+
+func (s *OnlineMigrationStorage) Remaining(ctx context.Context, m ColumnMigration) (int, error) {
+	exec := getQueryExecer(ctx, s.db.DB)
+	var count int
+	err := exec.QueryRowContext(
+		ctx,
+		fmt.Sprintf(`
+select count(*)
+from %s
+where %s is distinct from %s`, m.Table, m.NewColumn, m.Convert),
+	).Scan(&count)
+	if err != nil {
+		return 0, fmt.Errorf("count %s remaining: %w", m.Name, err)
+	}
+	return count, nil
+}
@@ -0,0 +1,164 @@
+package storage
+
+import (
+	"context"
+	"errors"
+	"io"
+	"log/slog"
+	"regexp"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+
+	"github.com/cloudyy74/pr-reviewer-service/internal/models"
+	"github.com/cloudyy74/pr-reviewer-service/pkg/postgres"
+)
+
+func newOnlineMigrationStorage(t *testing.T) (*OnlineMigrationStorage, sqlmock.Sqlmock) {
+	t.Helper()
+
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("sqlmock.New: %v", err)
+	}
+	t.Cleanup(func() { db.Close() })
+
+	st, err := NewOnlineMigrationStorage(&postgres.Postgres{DB: db}, slog.New(slog.NewTextHandler(io.Discard, nil)))
+	if err != nil {
+		t.Fatalf("NewOnlineMigrationStorage: %v", err)
+	}
+	return st, mock
+}
+
+func TestParseMigrationPhase(t *testing.T) {
+	cases := map[string]MigrationPhase{
+		"":           MigrationPhaseOff,
+		"off":        MigrationPhaseOff,
+		"dual_write": MigrationPhaseDualWrite,
+		"cutover":    MigrationPhaseCutover,
+	}
+	for in, want := range cases {
+		got, err := ParseMigrationPhase(in)
+		if err != nil || got != want {
+			t.Fatalf("ParseMigrationPhase(%q) = %q, %v; want %q", in, got, err, want)
+		}
+	}
+	if _, err := ParseMigrationPhase("later"); !errors.Is(err, ErrUnknownMigrationPhase) {
+		t.Fatalf("expected ErrUnknownMigrationPhase, got %v", err)
+	}
+	if MigrationPhaseOff.WritesNew() || !MigrationPhaseDualWrite.WritesNew() || MigrationPhaseDualWrite.ReadsNew() || !MigrationPhaseCutover.ReadsNew() {
+		t.Fatalf("unexpected phase semantics")
+	}
+}
+
+func TestOnlineMigrationStorage_Backfill(t *testing.T) {
+	st, mock := newOnlineMigrationStorage(t)
+	mock.ExpectExec(regexp.QuoteMeta(`
+update pull_requests
+set status_enum = status::pr_status
+where ctid in (
+    select ctid
+    from pull_requests
+    where status_enum is distinct from status::pr_status
+    limit $1
+    for update skip locked
+)`)).
+		WithArgs(500).
+		WillReturnResult(sqlmock.NewResult(0, 42))
+
+	n, err := st.Backfill(context.Background(), PRStatusEnumMigration, 500)
+	if err != nil {
+		t.Fatalf("Backfill returned err: %v", err)
+	}
+	if n != 42 {
+		t.Fatalf("expected 42 rows, got %d", n)
+	}
+	verifyExpectations(t, mock)
+}
+
+func TestOnlineMigrationStorage_Remaining(t *testing.T) {
+	st, mock := newOnlineMigrationStorage(t)
+	mock.ExpectQuery(regexp.QuoteMeta(`
+select count(*)
+from pull_requests
+where status_enum is distinct from status::pr_status`)).
+		WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(3))
+
+	n, err := st.Remaining(context.Background(), PRStatusEnumMigration)
+	if err != nil {
+		t.Fatalf("Remaining returned err: %v", err)
+	}
+	if n != 3 {
+		t.Fatalf("expected 3, got %d", n)
+	}
+	verifyExpectations(t, mock)
+}
+
+func TestPRStorage_DualWritesStatusEnum(t *testing.T) {
+	st, mock := newPRStorage(t)
+	st.SetStatusMigrationPhase(MigrationPhaseDualWrite)
+
+	mock.ExpectQuery(regexp.QuoteMeta(`
+        insert into pull_requests (id, title, author_id, status, description, repository_url, branch, priority, deadline, status_enum)`)).
+		WithArgs("pr1", "title", "author", models.StatusOpen, "", "", "", "", nil).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "title", "author_id", "status", "description", "repository_url", "branch", "priority", "deadline", "created_at", "merged_at"}).
+			AddRow("pr1", "title", "author", models.StatusOpen, "", "", "", models.PriorityNormal, nil, time.Now(), nil))
+	mock.ExpectExec(regexp.QuoteMeta(`
+update pull_requests
+set status = $2,
+    status_enum = $2::pr_status,
+    merged_at = $3
+where id = $1`)).
+		WithArgs("pr1", models.StatusMerged, sqlmock.AnyArg()).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+
+	ctx := context.Background()
+	if _, err := st.CreatePR(ctx, models.PullRequest{ID: "pr1", Title: "title", AuthorID: "author", Status: models.StatusOpen}); err != nil {
+		t.Fatalf("CreatePR returned err: %v", err)
+	}
+	if err := st.MarkPRMerged(ctx, "pr1", time.Now()); err != nil {
+		t.Fatalf("MarkPRMerged returned err: %v", err)
+	}
+	verifyExpectations(t, mock)
+}
+
+func TestPRStorage_CutoverReadsStatusEnum(t *testing.T) {
+	st, mock := newPRStorage(t)
+	st.SetStatusMigrationPhase(MigrationPhaseCutover)
+
+	mock.ExpectQuery(regexp.QuoteMeta(`select pr.id, pr.title, pr.description, pr.repository_url, pr.branch, pr.priority, pr.author_id, coalesce(pr.status_enum::text, pr.status), pr.deadline, pr.created_at, pr.merged_at, pr.closed_at`)).
+		WithArgs("pr1").
+		WillReturnRows(sqlmock.NewRows([]string{"id", "title", "description", "repository_url", "branch", "priority", "author_id", "status", "deadline", "created_at", "merged_at", "closed_at", "username", "team_name", "is_active"}).
+			AddRow("pr1", "title", "", "", "", models.PriorityNormal, "author", models.StatusMerged, nil, time.Now(), time.Now(), nil, "dave", "backend", true))
+	mock.ExpectQuery(regexp.QuoteMeta(`select r.user_id, u.username, r.assigned_at, r.acknowledged_at`)).
+		WithArgs("pr1").
+		WillReturnRows(sqlmock.NewRows([]string{"user_id", "username", "assigned_at", "acknowledged_at"}))
+	mock.ExpectQuery(regexp.QuoteMeta(`from pr_labels l`)).
+		WithArgs("pr1").
+		WillReturnRows(sqlmock.NewRows([]string{"pull_request_id", "label"}))
+
+	pr, err := st.GetPR(context.Background(), "pr1")
+	if err != nil {
+		t.Fatalf("GetPR returned err: %v", err)
+	}
+	if pr.Status != models.StatusMerged {
+		t.Fatalf("unexpected status %q", pr.Status)
+	}
+	verifyExpectations(t, mock)
+}
+
+func TestTeamStorage_CutoverReadsStatusEnum(t *testing.T) {
+	st, mock := newTeamStorage(t)
+	st.SetStatusMigrationPhase(MigrationPhaseCutover)
+
+	mock.ExpectQuery(regexp.QuoteMeta(`and coalesce(pr.status_enum::text, pr.status) = $2`)).
+		WithArgs("backend", models.StatusOpen).
+		WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(2))
+
+	count, err := st.CountOpenPRs(context.Background(), "backend")
+	if err != nil || count != 2 {
+		t.Fatalf("CountOpenPRs returned %d, %v", count, err)
+	}
+	verifyExpectations(t, mock)
+}
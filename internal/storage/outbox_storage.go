@@ -0,0 +1,186 @@
+package storage
+
+import (
+	"cmp"
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"log/slog"
+	"slices"
+	"time"
+
+	"github.com/cloudyy74/pr-reviewer-service/internal/models"
+	"github.com/cloudyy74/pr-reviewer-service/pkg/postgres"
+)
+
+type OutboxStorage struct {
+	db  *postgres.Postgres
+	log *slog.Logger
+}
+
+func NewOutboxStorage(db *postgres.Postgres, log *slog.Logger) (*OutboxStorage, error) {
+	if db == nil {
+		return nil, errors.New("database cannot be nil")
+	}
+	if log == nil {
+		return nil, errors.New("logger cannot be nil")
+	}
+	return &OutboxStorage{
+		db:  db,
+		log: log,
+	}, nil
+}
+
+func (s *OutboxStorage) AddOutboxEvent(ctx context.Context, topic, aggregateID string, payload []byte) error {
+	exec := getExecer(ctx, s.db.DB)
+	_, err := exec.ExecContext(
+		ctx,
+		`insert into outbox_events (topic, aggregate_id, payload) values ($1, $2, $3)`,
+		topic,
+		aggregateID,
+		payload,
+	)
+	if err != nil {
+		s.log.Error("failed to add outbox event", slog.Any("error", err), slog.String("topic", topic))
+		return fmt.Errorf("add outbox event: %w", err)
+	}
+	return nil
+}
+
+func (s *OutboxStorage) ClaimOutboxEvents(ctx context.Context, limit int, lease time.Duration) ([]*models.OutboxEvent, error) {
+	exec := getQueryExecer(ctx, s.db.DB)
+	rows, err := exec.QueryContext(
+		ctx,
+		`
+update outbox_events
+set available_at = now() + $2 * interval '1 millisecond',
+    attempts = attempts + 1
+where id in (
+    select id
+    from outbox_events
+    where delivered_at is null
+      and available_at <= now()
+    order by id
+    limit $1
+    for update skip locked
+)
+returning id, topic, aggregate_id, payload, created_at, attempts
+`,
+		limit,
+		lease.Milliseconds(),
+	)
+	if err != nil {
+		s.log.Error("failed to claim outbox events", slog.Any("error", err))
+		return nil, fmt.Errorf("claim outbox events: %w", err)
+	}
+	defer rows.Close()
+
+	events := make([]*models.OutboxEvent, 0, limit)
+	for rows.Next() {
+		var e models.OutboxEvent
+		if err := rows.Scan(&e.ID, &e.Topic, &e.AggregateID, &e.Payload, &e.CreatedAt, &e.Attempts); err != nil {
+			return nil, fmt.Errorf("scan outbox event: %w", err)
+		}
+		events = append(events, &e)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("claim outbox events rows: %w", err)
+	}
+	slices.SortFunc(events, func(a, b *models.OutboxEvent) int { return cmp.Compare(a.ID, b.ID) })
+	return events, nil
+}
+
+func (s *OutboxStorage) MarkOutboxDelivered(ctx context.Context, id int64) error {
+	exec := getExecer(ctx, s.db.DB)
+	_, err := exec.ExecContext(
+		ctx,
+		`update outbox_events set delivered_at = now(), last_error = null where id = $1`,
+		id,
+	)
+	if err != nil {
+		return fmt.Errorf("mark outbox event delivered: %w", err)
+	}
+	return nil
+}
+
+func (s *OutboxStorage) MarkOutboxFailed(ctx context.Context, id int64, lastError string, retryAt time.Time) error {
+	exec := getExecer(ctx, s.db.DB)
+	_, err := exec.ExecContext(
+		ctx,
+		`update outbox_events set available_at = $2, last_error = $3 where id = $1 and delivered_at is null`,
+		id,
+		retryAt,
+		lastError,
+	)
+	if err != nil {
+		return fmt.Errorf("mark outbox event failed: %w", err)
+	}
+	return nil
+}
+
+func (s *OutboxStorage) GetOutboxBacklog(ctx context.Context) (*models.OutboxBacklog, error) {
+	exec := getQueryExecer(ctx, s.db.DB)
+	var backlog models.OutboxBacklog
+	var oldest sql.NullTime
+	err := exec.QueryRowContext(
+		ctx,
+		`
+select count(*),
+       count(*) filter (where last_error is not null),
+       min(created_at)
+from outbox_events
+where delivered_at is null
+`,
+	).Scan(&backlog.Pending, &backlog.Retrying, &oldest)
+	if err != nil {
+		s.log.Error("failed to get outbox backlog", slog.Any("error", err))
+		return nil, fmt.Errorf("get outbox backlog: %w", err)
+	}
+	if oldest.Valid {
+		backlog.OldestPendingAt = &oldest.Time
+	}
+	return &backlog, nil
+}
+
+// ListEvents returns events with an id above q.Since, oldest first. Events
+// younger than settle are left out: ids are taken when a transaction inserts
+// the event, so a younger event may still become visible below an id a
+// reader has already passed.
+func (s *OutboxStorage) ListEvents(ctx context.Context, q models.EventLogQuery, settle time.Duration) ([]*models.LoggedEvent, error) {
+	exec := getQueryExecer(ctx, s.db.DB)
+	rows, err := exec.QueryContext(
+		ctx,
+		`
+select id, topic, aggregate_id, payload, created_at
+from outbox_events
+where id > $1
+  and ($2 = '' or topic = $2)
+  and created_at <= now() - $4 * interval '1 millisecond'
+order by id
+limit $3
+`,
+		q.Since,
+		q.Topic,
+		q.Limit,
+		settle.Milliseconds(),
+	)
+	if err != nil {
+		s.log.Error("failed to list events", slog.Any("error", err))
+		return nil, fmt.Errorf("list events: %w", err)
+	}
+	defer rows.Close()
+
+	events := make([]*models.LoggedEvent, 0, q.Limit)
+	for rows.Next() {
+		var e models.LoggedEvent
+		if err := rows.Scan(&e.ID, &e.Topic, &e.AggregateID, &e.Payload, &e.CreatedAt); err != nil {
+			return nil, fmt.Errorf("scan event: %w", err)
+		}
+		events = append(events, &e)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("list events rows: %w", err)
+	}
+	return events, nil
+}
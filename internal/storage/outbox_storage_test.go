@@ -0,0 +1,97 @@
+package storage
+
+import (
+	"context"
+	"io"
+	"log/slog"
+	"regexp"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+
+	"github.com/cloudyy74/pr-reviewer-service/internal/models"
+	"github.com/cloudyy74/pr-reviewer-service/pkg/postgres"
+)
+
+func newOutboxStorage(t *testing.T) (*OutboxStorage, sqlmock.Sqlmock) {
+	t.Helper()
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("sqlmock.New: %v", err)
+	}
+	t.Cleanup(func() { db.Close() })
+
+	log := slog.New(slog.NewTextHandler(io.Discard, nil))
+	st, err := NewOutboxStorage(&postgres.Postgres{DB: db}, log)
+	if err != nil {
+		t.Fatalf("NewOutboxStorage: %v", err)
+	}
+	return st, mock
+}
+
+func TestOutboxStorage_ClaimOutboxEvents(t *testing.T) {
+	st, mock := newOutboxStorage(t)
+	created := time.Date(2025, 3, 1, 10, 0, 0, 0, time.UTC)
+	mock.ExpectQuery(regexp.QuoteMeta("for update skip locked")).
+		WithArgs(10, int64(30000)).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "topic", "aggregate_id", "payload", "created_at", "attempts"}).
+			AddRow(int64(7), "pr.merged", "pr-2", []byte(`{"a":2}`), created, 1).
+			AddRow(int64(3), "pr.created", "pr-1", []byte(`{"a":1}`), created, 2))
+
+	events, err := st.ClaimOutboxEvents(context.Background(), 10, 30*time.Second)
+	if err != nil {
+		t.Fatalf("ClaimOutboxEvents returned err: %v", err)
+	}
+	if len(events) != 2 || events[0].ID != 3 || events[1].Topic != "pr.merged" || string(events[0].Payload) != `{"a":1}` {
+		t.Fatalf("unexpected events: %#v", events)
+	}
+	verifyExpectations(t, mock)
+}
+
+func TestOutboxStorage_MarkOutboxFailed(t *testing.T) {
+	st, mock := newOutboxStorage(t)
+	retryAt := time.Date(2025, 3, 1, 10, 5, 0, 0, time.UTC)
+	mock.ExpectExec(regexp.QuoteMeta("update outbox_events set available_at = $2, last_error = $3 where id = $1 and delivered_at is null")).
+		WithArgs(int64(3), retryAt, "boom").
+		WillReturnResult(sqlmock.NewResult(0, 1))
+
+	if err := st.MarkOutboxFailed(context.Background(), 3, "boom", retryAt); err != nil {
+		t.Fatalf("MarkOutboxFailed returned err: %v", err)
+	}
+	verifyExpectations(t, mock)
+}
+
+func TestOutboxStorage_GetOutboxBacklog(t *testing.T) {
+	st, mock := newOutboxStorage(t)
+	oldest := time.Date(2025, 3, 1, 9, 0, 0, 0, time.UTC)
+	mock.ExpectQuery(regexp.QuoteMeta("from outbox_events")).
+		WillReturnRows(sqlmock.NewRows([]string{"count", "retrying", "min"}).AddRow(5, 1, oldest))
+
+	backlog, err := st.GetOutboxBacklog(context.Background())
+	if err != nil {
+		t.Fatalf("GetOutboxBacklog returned err: %v", err)
+	}
+	if backlog.Pending != 5 || backlog.Retrying != 1 || backlog.OldestPendingAt == nil || !backlog.OldestPendingAt.Equal(oldest) {
+		t.Fatalf("unexpected backlog: %#v", backlog)
+	}
+	verifyExpectations(t, mock)
+}
+
+func TestOutboxStorage_ListEvents(t *testing.T) {
+	st, mock := newOutboxStorage(t)
+	created := time.Date(2025, 3, 1, 10, 0, 0, 0, time.UTC)
+	mock.ExpectQuery(regexp.QuoteMeta("where id > $1")).
+		WithArgs(int64(40), "pr.merged", 11, int64(5000)).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "topic", "aggregate_id", "payload", "created_at"}).
+			AddRow(int64(42), "pr.merged", "pr-1", []byte(`{"a":1}`), created))
+
+	events, err := st.ListEvents(context.Background(), models.EventLogQuery{Since: 40, Topic: "pr.merged", Limit: 11}, 5*time.Second)
+	if err != nil {
+		t.Fatalf("ListEvents returned err: %v", err)
+	}
+	if len(events) != 1 || events[0].ID != 42 || events[0].AggregateID != "pr-1" || string(events[0].Payload) != `{"a":1}` {
+		t.Fatalf("unexpected events: %#v", events)
+	}
+	verifyExpectations(t, mock)
+}
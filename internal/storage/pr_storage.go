@@ -16,9 +16,15 @@ var (
 	ErrPRExists            = errors.New("pr already exists")
 	ErrPRNotFound          = errors.New("pr not found")
 	ErrReviewerNotAssigned = errors.New("reviewer not assigned")
+	ErrReviewerAssigned    = errors.New("reviewer already assigned")
+	ErrStatusNotFound      = errors.New("status not found")
+	ErrPRNotOpen           = errors.New("pr is not open")
 )
 
+const prStatusCheck = "pull_requests_status_check"
+
 type PRStorage struct {
+	prStatusColumn
 	db  *postgres.Postgres
 	log *slog.Logger
 }
@@ -48,23 +54,45 @@ func scanMergedAt(dest **time.Time, nt sql.NullTime) {
 func (s *PRStorage) CreatePR(ctx context.Context, pr models.PullRequest) (*models.PullRequest, error) {
 	exec := getQueryExecer(ctx, s.db.DB)
 	var created models.PullRequest
-	var merged sql.NullTime
-	err := exec.QueryRowContext(ctx, `
-        insert into pull_requests (id, title, author_id, status_id)
-        values ($1, $2, $3, (select id from statuses where name = $4))
-        returning id, title, author_id, $4 as status, merged_at`,
-		pr.ID, pr.Title, pr.AuthorID, pr.Status,
-	).Scan(&created.ID, &created.Title, &created.AuthorID, &created.Status, &merged)
+	var createdAt time.Time
+	var merged, deadline sql.NullTime
+	// Without an explicit deadline the review SLA of the author's team, if
+	// set, counts from the insert.
+	query := `
+        insert into pull_requests (id, title, author_id, status, description, repository_url, branch, priority, deadline)
+        values ($1, $2, $3, $4, $5, $6, $7, $8, ` + slaDeadline + `)
+        returning id, title, author_id, ` + s.statusColumn("") + `, description, repository_url, branch, priority, deadline, created_at, merged_at`
+	if s.statusPhase.get().WritesNew() {
+		query = `
+        insert into pull_requests (id, title, author_id, status, description, repository_url, branch, priority, deadline, status_enum)
+        values ($1, $2, $3, $4, $5, $6, $7, $8, ` + slaDeadline + `, $4::pr_status)
+        returning id, title, author_id, ` + s.statusColumn("") + `, description, repository_url, branch, priority, deadline, created_at, merged_at`
+	}
+	err := exec.QueryRowContext(ctx, query,
+		pr.ID, pr.Title, pr.AuthorID, pr.Status, pr.Description, pr.RepositoryURL, pr.Branch, pr.Priority, pr.Deadline,
+	).Scan(&created.ID, &created.Title, &created.AuthorID, &created.Status, &created.Description, &created.RepositoryURL, &created.Branch, &created.Priority, &deadline, &createdAt, &merged)
 	if err != nil {
 		if postgres.IsUniqueViolation(err) {
 			return nil, ErrPRExists
 		}
+		if postgres.IsCheckViolation(err, prStatusCheck) {
+			return nil, fmt.Errorf("%w: %s", ErrStatusNotFound, pr.Status)
+		}
 		return nil, fmt.Errorf("insert pr: %w", err)
 	}
+	created.CreatedAt = &createdAt
 	scanMergedAt(&created.MergedAt, merged)
+	scanMergedAt(&created.Deadline, deadline)
 	return &created, nil
 }
 
+const slaDeadline = `coalesce($9::timestamptz, (
+            select now() + make_interval(hours => ts.review_sla_hours)
+            from users u
+                join team_settings ts on ts.team_name = u.team_name
+            where u.id = $3 and ts.review_sla_hours > 0
+        ))`
+
 func (s *PRStorage) AddReviewers(ctx context.Context, prID string, reviewerIDs []string) error {
 	if len(reviewerIDs) == 0 {
 		return nil
@@ -77,6 +105,9 @@ func (s *PRStorage) AddReviewers(ctx context.Context, prID string, reviewerIDs [
 			prID,
 			reviewerID,
 		); err != nil {
+			if postgres.IsUniqueViolation(err) {
+				return ErrReviewerAssigned
+			}
 			s.log.Error("failed to add reviewer", slog.Any("error", err), slog.String("pr_id", prID), slog.String("user_id", reviewerID))
 			return fmt.Errorf("add reviewer %s: %w", reviewerID, err)
 		}
@@ -84,19 +115,319 @@ func (s *PRStorage) AddReviewers(ctx context.Context, prID string, reviewerIDs [
 	return nil
 }
 
+func (s *PRStorage) AddAssignmentEvents(ctx context.Context, prID string, userIDs []string, event, reason string) error {
+	exec := getExecer(ctx, s.db.DB)
+	for _, userID := range userIDs {
+		if _, err := exec.ExecContext(
+			ctx,
+			"insert into assignment_events (pull_request_id, user_id, event, reason) values ($1, $2, $3, $4)",
+			prID,
+			userID,
+			event,
+			reason,
+		); err != nil {
+			s.log.Error("failed to add assignment event", slog.Any("error", err), slog.String("pr_id", prID), slog.String("user_id", userID))
+			return fmt.Errorf("add assignment event %s: %w", event, err)
+		}
+	}
+	return nil
+}
+
+func (s *PRStorage) AcknowledgeReviewer(ctx context.Context, prID, userID string, at time.Time) (bool, error) {
+	exec := getExecer(ctx, s.db.DB)
+	res, err := exec.ExecContext(
+		ctx,
+		`
+update pull_requests_reviewers
+set acknowledged_at = $3
+where pull_request_id = $1
+  and user_id = $2
+  and acknowledged_at is null`,
+		prID,
+		userID,
+		at,
+	)
+	if err != nil {
+		return false, fmt.Errorf("acknowledge reviewer: %w", err)
+	}
+	rows, err := res.RowsAffected()
+	if err != nil {
+		return false, fmt.Errorf("acknowledge reviewer rows: %w", err)
+	}
+	return rows > 0, nil
+}
+
+// SetReviewState records the review verdict of userID on prID, acknowledging
+// the assignment as well if it was not yet. It reports whether the state
+// changed.
+func (s *PRStorage) SetReviewState(ctx context.Context, prID, userID, state string, at time.Time) (bool, error) {
+	exec := getExecer(ctx, s.db.DB)
+	res, err := exec.ExecContext(
+		ctx,
+		`
+update pull_requests_reviewers
+set review_state = $3,
+    reviewed_at = $4,
+    acknowledged_at = coalesce(acknowledged_at, $4)
+where pull_request_id = $1
+  and user_id = $2
+  and review_state <> $3`,
+		prID,
+		userID,
+		state,
+		at,
+	)
+	if err != nil {
+		return false, fmt.Errorf("set review state: %w", err)
+	}
+	rows, err := res.RowsAffected()
+	if err != nil {
+		return false, fmt.Errorf("set review state rows: %w", err)
+	}
+	return rows > 0, nil
+}
+
+func (s *PRStorage) GetExpiredAssignments(ctx context.Context, now time.Time) ([]*models.ReviewerAssignment, error) {
+	exec := getQueryExecer(ctx, s.db.DB)
+	rows, err := exec.QueryContext(
+		ctx,
+		`
+select r.pull_request_id, r.user_id, r.assigned_at
+from pull_requests_reviewers r
+    join pull_requests pr on pr.id = r.pull_request_id
+    join users a on a.id = pr.author_id
+    join team_settings ts on ts.team_name = a.team_name
+where `+s.statusColumn("pr")+` = $1
+  and r.acknowledged_at is null
+  and ts.ack_timeout_hours > 0
+  and r.assigned_at < $2::timestamptz - make_interval(hours => ts.ack_timeout_hours)
+order by r.assigned_at, r.pull_request_id
+`,
+		models.StatusOpen,
+		now,
+	)
+	if err != nil {
+		s.log.Error("failed to get expired assignments", slog.Any("error", err))
+		return nil, fmt.Errorf("get expired assignments: %w", err)
+	}
+	defer rows.Close()
+
+	assignments := make([]*models.ReviewerAssignment, 0)
+	for rows.Next() {
+		var a models.ReviewerAssignment
+		if err := rows.Scan(&a.PullRequestID, &a.UserID, &a.AssignedAt); err != nil {
+			return nil, fmt.Errorf("scan expired assignment: %w", err)
+		}
+		assignments = append(assignments, &a)
+	}
+
+	return assignments, nil
+}
+
+func (s *PRStorage) GetAssignmentHistory(ctx context.Context, q models.AssignmentHistoryQuery) ([]*models.AssignmentHistoryItem, int, error) {
+	exec := getQueryExecer(ctx, s.db.DB)
+
+	var total int
+	err := exec.QueryRowContext(
+		ctx,
+		`
+select count(*)
+from assignment_events e
+where e.user_id = $1
+  and e.event = 'ASSIGNED'
+  and ($2::timestamptz is null or e.created_at >= $2)
+  and ($3::timestamptz is null or e.created_at < $3)
+`,
+		q.UserID,
+		q.From,
+		q.To,
+	).Scan(&total)
+	if err != nil {
+		s.log.Error("failed to count assignment history", slog.Any("error", err), slog.String("user_id", q.UserID))
+		return nil, 0, fmt.Errorf("count assignment history: %w", err)
+	}
+
+	rows, err := exec.QueryContext(
+		ctx,
+		`
+select e.pull_request_id, pr.title, pr.author_id, e.created_at,
+    case
+        when x.event = 'DECLINED' then 'DECLINED'
+        when x.event = 'REMOVED' then 'REMOVED'
+        when x.event is not null then 'REASSIGNED'
+        when `+s.statusColumn("pr")+` = 'MERGED' then 'MERGED'
+        when `+s.statusColumn("pr")+` = 'CLOSED' then 'CLOSED'
+        else 'PENDING'
+    end as outcome,
+    coalesce(x.reason, '') as reason,
+    coalesce(x.created_at, pr.merged_at, pr.closed_at) as ended_at
+from assignment_events e
+    join pull_requests pr on pr.id = e.pull_request_id
+    left join lateral (
+        select n.event, n.reason, n.created_at
+        from assignment_events n
+        where n.pull_request_id = e.pull_request_id
+          and n.user_id = e.user_id
+          and n.id > e.id
+          and n.event not in ('ASSIGNED', 'ACKNOWLEDGED', 'APPROVED', 'CHANGES_REQUESTED')
+        order by n.id
+        limit 1
+    ) x on true
+where e.user_id = $1
+  and e.event = 'ASSIGNED'
+  and ($2::timestamptz is null or e.created_at >= $2)
+  and ($3::timestamptz is null or e.created_at < $3)
+order by e.created_at desc, e.id desc
+limit $4 offset $5
+`,
+		q.UserID,
+		q.From,
+		q.To,
+		q.Limit,
+		q.Offset,
+	)
+	if err != nil {
+		s.log.Error("failed to get assignment history", slog.Any("error", err), slog.String("user_id", q.UserID))
+		return nil, 0, fmt.Errorf("get assignment history: %w", err)
+	}
+	defer rows.Close()
+
+	items := make([]*models.AssignmentHistoryItem, 0)
+	for rows.Next() {
+		var item models.AssignmentHistoryItem
+		var ended sql.NullTime
+		if err := rows.Scan(
+			&item.PullRequestID,
+			&item.PullRequestName,
+			&item.AuthorID,
+			&item.AssignedAt,
+			&item.Outcome,
+			&item.Reason,
+			&ended,
+		); err != nil {
+			return nil, 0, fmt.Errorf("scan assignment history: %w", err)
+		}
+		scanMergedAt(&item.EndedAt, ended)
+		items = append(items, &item)
+	}
+
+	return items, total, nil
+}
+
+// ListPRs returns a page of pull requests matching q, newest first, and the
+// number of all matching ones. The page is read by id with GetPRsByIDs, so
+// the pull requests come with their reviewers.
+func (s *PRStorage) ListPRs(ctx context.Context, q models.PRListQuery) ([]*models.PullRequest, int, error) {
+	exec := getQueryExecer(ctx, s.db.DB)
+	qb := newQueryBuilder()
+	status, authorID, reviewerID, teamName := qb.arg(q.Status), qb.arg(q.AuthorID), qb.arg(q.ReviewerID), qb.arg(q.TeamName)
+	createdFrom, createdTo, mergedFrom, mergedTo := qb.arg(q.CreatedFrom), qb.arg(q.CreatedTo), qb.arg(q.MergedFrom), qb.arg(q.MergedTo)
+	qb.write(`
+from pull_requests pr
+    join users a on a.id = pr.author_id
+where (`, status, ` = '' or `, s.statusColumn("pr"), ` = `, status, `)
+  and (`, authorID, ` = '' or pr.author_id = `, authorID, `)
+  and (`, reviewerID, ` = '' or exists (
+        select 1 from pull_requests_reviewers r
+        where r.pull_request_id = pr.id and r.user_id = `, reviewerID, `
+  ))
+  and (`, teamName, ` = '' or lower(a.team_name) = lower(`, teamName, `))
+  and (`, createdFrom, `::timestamptz is null or pr.created_at >= `, createdFrom, `)
+  and (`, createdTo, `::timestamptz is null or pr.created_at < `, createdTo, `)
+  and (`, mergedFrom, `::timestamptz is null or pr.merged_at >= `, mergedFrom, `)
+  and (`, mergedTo, `::timestamptz is null or pr.merged_at < `, mergedTo, `)
+`)
+	if len(q.Labels) > 0 {
+		// Labels are unique per pull request, so matching all of them means
+		// matching as many rows as there are labels.
+		qb.write(`  and (
+        select count(*) from pr_labels l
+        where l.pull_request_id = pr.id and l.label in (`, qb.list(q.Labels), `)
+  ) = `, qb.arg(len(q.Labels)), `
+`)
+	}
+	if q.Overdue {
+		qb.write(`  and `, s.statusColumn("pr"), ` = `, qb.arg(models.StatusOpen), ` and pr.deadline < now()
+`)
+	}
+	filter := qb.query()
+
+	var total int
+	if err := exec.QueryRowContext(ctx, `select count(*)`+filter, qb.queryArgs()...).Scan(&total); err != nil {
+		s.log.Error("failed to count prs", slog.Any("error", err))
+		return nil, 0, fmt.Errorf("count prs: %w", err)
+	}
+
+	limit, offset := qb.arg(q.Limit), qb.arg(q.Offset)
+	rows, err := exec.QueryContext(
+		ctx,
+		`select pr.id`+filter+`order by pr.created_at desc, pr.id desc
+limit `+limit+` offset `+offset+`
+`,
+		qb.queryArgs()...,
+	)
+	if err != nil {
+		s.log.Error("failed to list prs", slog.Any("error", err))
+		return nil, 0, fmt.Errorf("list prs: %w", err)
+	}
+	defer rows.Close()
+	ids := make([]string, 0, q.Limit)
+	for rows.Next() {
+		var id string
+		if err := rows.Scan(&id); err != nil {
+			return nil, 0, fmt.Errorf("scan pr id: %w", err)
+		}
+		ids = append(ids, id)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, 0, fmt.Errorf("list prs: %w", err)
+	}
+	rows.Close()
+
+	prs, err := s.GetPRsByIDs(ctx, ids)
+	if err != nil {
+		return nil, 0, err
+	}
+	byID := make(map[string]*models.PullRequest, len(prs))
+	for _, pr := range prs {
+		byID[pr.ID] = pr
+	}
+	page := make([]*models.PullRequest, 0, len(ids))
+	for _, id := range ids {
+		if pr, ok := byID[id]; ok {
+			page = append(page, pr)
+		}
+	}
+	return page, total, nil
+}
+
+// prAgeDays is the age of the pull request of the row alias in whole days,
+// frozen at the merge time once it is merged.
+func prAgeDays(alias string) string {
+	return `floor(extract(epoch from coalesce(` + alias + `.merged_at, now()) - ` + alias + `.created_at) / 86400)::int`
+}
+
+// priorityRank sorts the pull requests of the row alias from urgent to low.
+func priorityRank(alias string) string {
+	return `case ` + alias + `.priority when '` + models.PriorityUrgent + `' then 0 when '` + models.PriorityHigh + `' then 1 when '` + models.PriorityLow + `' then 3 else 2 end`
+}
+
+// GetReviewerPRs returns the review queue of userID: open pull requests
+// before merged ones, by priority and then oldest first within each, so the
+// next one to pick up comes first.
 func (s *PRStorage) GetReviewerPRs(ctx context.Context, userID string) ([]*models.PullRequestShort, error) {
 	exec := getQueryExecer(ctx, s.db.DB)
 	rows, err := exec.QueryContext(
 		ctx,
 		`
-select pr.id, pr.title, pr.author_id, s.name
+select pr.id, pr.title, pr.author_id, `+s.statusColumn("pr")+`, pr.priority, `+prAgeDays("pr")+`
 from pull_requests pr
     join pull_requests_reviewers r on r.pull_request_id = pr.id
-    join statuses s on s.id = pr.status_id
 where r.user_id = $1
-order by pr.id
+order by `+s.statusColumn("pr")+` = $2 desc, `+priorityRank("pr")+`, pr.created_at, pr.id
 `,
 		userID,
+		models.StatusOpen,
 	)
 	if err != nil {
 		s.log.Error("failed to get reviewer prs", slog.Any("error", err), slog.String("user_id", userID))
@@ -107,7 +438,7 @@ order by pr.id
 	prs := make([]*models.PullRequestShort, 0)
 	for rows.Next() {
 		var pr models.PullRequestShort
-		if err := rows.Scan(&pr.ID, &pr.Title, &pr.AuthorID, &pr.Status); err != nil {
+		if err := rows.Scan(&pr.ID, &pr.Title, &pr.AuthorID, &pr.Status, &pr.Priority, &pr.AgeDays); err != nil {
 			return nil, fmt.Errorf("scan reviewer pr: %w", err)
 		}
 		prs = append(prs, &pr)
@@ -116,6 +447,173 @@ order by pr.id
 	return prs, nil
 }
 
+// GetReviewerAgenda returns the open assignments of userID ordered by
+// assignment time. Unacknowledged ones are due after the ack timeout of the
+// author's team, or after defaultDueHours when the team has none.
+func (s *PRStorage) GetReviewerAgenda(ctx context.Context, userID string, defaultDueHours int) ([]*models.AgendaItem, error) {
+	exec := getQueryExecer(ctx, s.db.DB)
+	rows, err := exec.QueryContext(
+		ctx,
+		`
+select pr.id, pr.title, pr.author_id, r.assigned_at, r.acknowledged_at,
+       case when r.acknowledged_at is null
+           then r.assigned_at + make_interval(hours => coalesce(nullif(ts.ack_timeout_hours, 0), $3))
+       end,
+       `+prAgeDays("pr")+`,
+       (r.assigned_at at time zone u.timezone)::date = (now() at time zone u.timezone)::date
+from pull_requests_reviewers r
+    join pull_requests pr on pr.id = r.pull_request_id
+    join users u on u.id = r.user_id
+    join users a on a.id = pr.author_id
+    left join team_settings ts on ts.team_name = a.team_name
+where r.user_id = $1
+  and `+s.statusColumn("pr")+` = $2
+order by r.assigned_at, pr.id
+`,
+		userID,
+		models.StatusOpen,
+		defaultDueHours,
+	)
+	if err != nil {
+		s.log.Error("failed to get reviewer agenda", slog.Any("error", err), slog.String("user_id", userID))
+		return nil, fmt.Errorf("get reviewer agenda: %w", err)
+	}
+	defer rows.Close()
+
+	items := make([]*models.AgendaItem, 0)
+	for rows.Next() {
+		var item models.AgendaItem
+		var acked, due sql.NullTime
+		if err := rows.Scan(&item.PullRequestID, &item.Title, &item.AuthorID, &item.AssignedAt, &acked, &due,
+			&item.AgeDays, &item.AssignedToday); err != nil {
+			return nil, fmt.Errorf("scan agenda item: %w", err)
+		}
+		scanMergedAt(&item.AcknowledgedAt, acked)
+		scanMergedAt(&item.DueAt, due)
+		items = append(items, &item)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("iterate agenda: %w", err)
+	}
+	return items, nil
+}
+
+func (s *PRStorage) GetOpenPRsByAuthor(ctx context.Context, authorID string) ([]*models.PullRequestShort, error) {
+	exec := getQueryExecer(ctx, s.db.DB)
+	rows, err := exec.QueryContext(
+		ctx,
+		`
+select pr.id, pr.title, pr.author_id, `+s.statusColumn("pr")+`, `+prAgeDays("pr")+`
+from pull_requests pr
+where pr.author_id = $1
+  and `+s.statusColumn("pr")+` = $2
+order by pr.id
+`,
+		authorID,
+		models.StatusOpen,
+	)
+	if err != nil {
+		s.log.Error("failed to get author open prs", slog.Any("error", err), slog.String("author_id", authorID))
+		return nil, fmt.Errorf("get author open prs: %w", err)
+	}
+	defer rows.Close()
+
+	prs := make([]*models.PullRequestShort, 0)
+	for rows.Next() {
+		var pr models.PullRequestShort
+		if err := rows.Scan(&pr.ID, &pr.Title, &pr.AuthorID, &pr.Status, &pr.AgeDays); err != nil {
+			return nil, fmt.Errorf("scan author open pr: %w", err)
+		}
+		prs = append(prs, &pr)
+	}
+
+	return prs, nil
+}
+
+// GetRecentReviewers returns everyone assigned to review any of the author's
+// last lastPRs pull requests, including reviewers replaced since.
+func (s *PRStorage) GetRecentReviewers(ctx context.Context, authorID string, lastPRs int) ([]string, error) {
+	if lastPRs <= 0 {
+		return []string{}, nil
+	}
+	exec := getQueryExecer(ctx, s.db.DB)
+	rows, err := exec.QueryContext(
+		ctx,
+		`
+select distinct e.user_id
+from assignment_events e
+where e.event = $3
+  and e.pull_request_id in (
+      select pr.id
+      from pull_requests pr
+      where pr.author_id = $1
+      order by pr.created_at desc, pr.id desc
+      limit $2
+  )
+order by e.user_id
+`,
+		authorID,
+		lastPRs,
+		models.EventAssigned,
+	)
+	if err != nil {
+		s.log.Error("failed to get recent reviewers", slog.Any("error", err), slog.String("author_id", authorID))
+		return nil, fmt.Errorf("get recent reviewers: %w", err)
+	}
+	defer rows.Close()
+
+	reviewers := make([]string, 0)
+	for rows.Next() {
+		var id string
+		if err := rows.Scan(&id); err != nil {
+			return nil, fmt.Errorf("scan recent reviewer: %w", err)
+		}
+		reviewers = append(reviewers, id)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("iterate recent reviewers: %w", err)
+	}
+	return reviewers, nil
+}
+
+func (s *PRStorage) GetPRsNeedingReviewers(ctx context.Context, teamName string, minReviewers int) ([]*models.PRNeedingReviewers, error) {
+	exec := getQueryExecer(ctx, s.db.DB)
+	rows, err := exec.QueryContext(
+		ctx,
+		`
+select pr.id, pr.title, pr.author_id, u.team_name, count(r.user_id)
+from pull_requests pr
+    join users u on u.id = pr.author_id
+    left join pull_requests_reviewers r on r.pull_request_id = pr.id
+where `+s.statusColumn("pr")+` = $1
+  and ($3 = '' or lower(u.team_name) = lower($3))
+group by pr.id, pr.title, pr.author_id, u.team_name
+having count(r.user_id) < $2
+order by pr.created_at, pr.id
+`,
+		models.StatusOpen,
+		minReviewers,
+		teamName,
+	)
+	if err != nil {
+		s.log.Error("failed to get prs needing reviewers", slog.Any("error", err), slog.String("team", teamName))
+		return nil, fmt.Errorf("get prs needing reviewers: %w", err)
+	}
+	defer rows.Close()
+
+	prs := make([]*models.PRNeedingReviewers, 0)
+	for rows.Next() {
+		var pr models.PRNeedingReviewers
+		if err := rows.Scan(&pr.ID, &pr.Title, &pr.AuthorID, &pr.TeamName, &pr.Reviewers); err != nil {
+			return nil, fmt.Errorf("scan pr needing reviewers: %w", err)
+		}
+		pr.Missing = minReviewers - pr.Reviewers
+		prs = append(prs, &pr)
+	}
+
+	return prs, nil
+}
+
 func (s *PRStorage) GetAssignmentsStats(ctx context.Context) (*models.AssignmentsStatsResponse, error) {
 	exec := getQueryExecer(ctx, s.db.DB)
 	stats := &models.AssignmentsStatsResponse{
@@ -173,17 +671,21 @@ order by reviewers desc, pull_request_id
 func (s *PRStorage) GetPR(ctx context.Context, prID string) (*models.PullRequest, error) {
 	exec := getQueryExecer(ctx, s.db.DB)
 	var pr models.PullRequest
-	var merged sql.NullTime
+	var author models.UserWithTeam
+	var createdAt time.Time
+	var merged, closed, deadline sql.NullTime
 	err := exec.QueryRowContext(
 		ctx,
 		`
-select pr.id, pr.title, pr.author_id, s.name, pr.merged_at
+select pr.id, pr.title, pr.description, pr.repository_url, pr.branch, pr.priority, pr.author_id, `+s.statusColumn("pr")+`, pr.deadline, pr.created_at, pr.merged_at, pr.closed_at,
+       a.username, a.team_name, a.is_active
 from pull_requests pr
-    join statuses s on s.id = pr.status_id
+    join users a on a.id = pr.author_id
 where pr.id = $1
 `,
 		prID,
-	).Scan(&pr.ID, &pr.Title, &pr.AuthorID, &pr.Status, &merged)
+	).Scan(&pr.ID, &pr.Title, &pr.Description, &pr.RepositoryURL, &pr.Branch, &pr.Priority, &pr.AuthorID, &pr.Status, &deadline, &createdAt, &merged, &closed,
+		&author.Username, &author.TeamName, &author.IsActive)
 	if errors.Is(err, sql.ErrNoRows) {
 		return nil, fmt.Errorf("get pr: %w", ErrPRNotFound)
 	}
@@ -191,11 +693,23 @@ where pr.id = $1
 		s.log.Error("failed to get pr", slog.Any("error", err), slog.String("pr_id", prID))
 		return nil, fmt.Errorf("get pr: %w", err)
 	}
+	pr.CreatedAt = &createdAt
 	scanMergedAt(&pr.MergedAt, merged)
+	scanMergedAt(&pr.ClosedAt, closed)
+	scanMergedAt(&pr.Deadline, deadline)
+	pr.Overdue = pr.IsOverdue(time.Now())
+	author.ID = pr.AuthorID
+	pr.Author = &author
 
 	rows, err := exec.QueryContext(
 		ctx,
-		`select user_id from pull_requests_reviewers where pull_request_id = $1 order by user_id`,
+		`
+select r.user_id, u.username, r.assigned_at, r.acknowledged_at, r.review_state, r.reviewed_at
+from pull_requests_reviewers r
+    join users u on u.id = r.user_id
+where r.pull_request_id = $1
+order by r.user_id
+`,
 		prID,
 	)
 	if err != nil {
@@ -203,32 +717,288 @@ where pr.id = $1
 	}
 	defer rows.Close()
 	reviewers := make([]string, 0)
+	details := make([]*models.ReviewerDetail, 0)
 	for rows.Next() {
-		var reviewer string
-		if err := rows.Scan(&reviewer); err != nil {
+		var detail models.ReviewerDetail
+		var acked, reviewed sql.NullTime
+		if err := rows.Scan(&detail.UserID, &detail.Username, &detail.AssignedAt, &acked, &detail.ReviewState, &reviewed); err != nil {
 			return nil, fmt.Errorf("scan reviewer: %w", err)
 		}
-		reviewers = append(reviewers, reviewer)
+		setReviewerState(&detail, acked)
+		scanMergedAt(&detail.ReviewedAt, reviewed)
+		reviewers = append(reviewers, detail.UserID)
+		details = append(details, &detail)
 	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("iterate reviewers: %w", err)
+	}
+	rows.Close()
 	pr.Reviewers = reviewers
+	pr.ReviewerDetails = details
+
+	labels, err := s.GetLabelsByPRIDs(ctx, []string{prID})
+	if err != nil {
+		return nil, err
+	}
+	pr.Labels = labels[prID]
 	return &pr, nil
 }
 
+func setReviewerState(detail *models.ReviewerDetail, acked sql.NullTime) {
+	scanMergedAt(&detail.AcknowledgedAt, acked)
+	detail.State = models.ReviewerStateAssigned
+	if detail.AcknowledgedAt != nil {
+		detail.State = models.ReviewerStateAcknowledged
+	}
+}
+
+// GetPRsByIDs reads the pull requests that exist among prIDs, ordered by id,
+// with their authors and reviewers in two queries regardless of how many are
+// asked for.
+func (s *PRStorage) GetPRsByIDs(ctx context.Context, prIDs []string) ([]*models.PullRequest, error) {
+	if len(prIDs) == 0 {
+		return []*models.PullRequest{}, nil
+	}
+	exec := getQueryExecer(ctx, s.db.DB)
+	qb := newQueryBuilder()
+	qb.write(`
+select pr.id, pr.title, pr.description, pr.repository_url, pr.branch, pr.priority, pr.author_id, `+s.statusColumn("pr")+`, pr.deadline, pr.created_at, pr.merged_at, pr.closed_at,
+       a.username, a.team_name, a.is_active
+from pull_requests pr
+    join users a on a.id = pr.author_id
+where pr.id in (`, qb.list(prIDs), `)
+order by pr.id
+`)
+	rows, err := exec.QueryContext(ctx, qb.query(), qb.queryArgs()...)
+	if err != nil {
+		s.log.Error("failed to get prs by ids", slog.Any("error", err))
+		return nil, fmt.Errorf("get prs by ids: %w", err)
+	}
+	defer rows.Close()
+
+	prs := make([]*models.PullRequest, 0, len(prIDs))
+	byID := make(map[string]*models.PullRequest, len(prIDs))
+	for rows.Next() {
+		pr := &models.PullRequest{Reviewers: []string{}, ReviewerDetails: []*models.ReviewerDetail{}}
+		author := &models.UserWithTeam{}
+		var createdAt time.Time
+		var merged, closed, deadline sql.NullTime
+		if err := rows.Scan(&pr.ID, &pr.Title, &pr.Description, &pr.RepositoryURL, &pr.Branch, &pr.Priority, &pr.AuthorID, &pr.Status, &deadline, &createdAt, &merged, &closed,
+			&author.Username, &author.TeamName, &author.IsActive); err != nil {
+			return nil, fmt.Errorf("scan pr: %w", err)
+		}
+		pr.CreatedAt = &createdAt
+		scanMergedAt(&pr.MergedAt, merged)
+		scanMergedAt(&pr.ClosedAt, closed)
+		scanMergedAt(&pr.Deadline, deadline)
+		pr.Overdue = pr.IsOverdue(time.Now())
+		author.ID = pr.AuthorID
+		pr.Author = author
+		prs = append(prs, pr)
+		byID[pr.ID] = pr
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("iterate prs: %w", err)
+	}
+	if len(prs) == 0 {
+		return prs, nil
+	}
+
+	found := make([]string, 0, len(prs))
+	for _, pr := range prs {
+		found = append(found, pr.ID)
+	}
+	reviewers, err := s.GetReviewersByPRIDs(ctx, found)
+	if err != nil {
+		return nil, err
+	}
+	labels, err := s.GetLabelsByPRIDs(ctx, found)
+	if err != nil {
+		return nil, err
+	}
+	for _, pr := range prs {
+		for _, detail := range reviewers[pr.ID] {
+			pr.Reviewers = append(pr.Reviewers, detail.UserID)
+			pr.ReviewerDetails = append(pr.ReviewerDetails, detail)
+		}
+		pr.Labels = labels[pr.ID]
+	}
+	return prs, nil
+}
+
+// GetLabelsByPRIDs reads the labels of every pull request in prIDs with a
+// single query, each list sorted. Pull requests without labels are absent
+// from the map.
+func (s *PRStorage) GetLabelsByPRIDs(ctx context.Context, prIDs []string) (map[string][]string, error) {
+	labels := make(map[string][]string, len(prIDs))
+	if len(prIDs) == 0 {
+		return labels, nil
+	}
+	exec := getQueryExecer(ctx, s.db.DB)
+	qb := newQueryBuilder()
+	qb.write(`
+select l.pull_request_id, l.label
+from pr_labels l
+where l.pull_request_id in (`, qb.list(prIDs), `)
+order by l.pull_request_id, l.label
+`)
+	rows, err := exec.QueryContext(ctx, qb.query(), qb.queryArgs()...)
+	if err != nil {
+		s.log.Error("failed to get pr labels", slog.Any("error", err))
+		return nil, fmt.Errorf("get pr labels: %w", err)
+	}
+	defer rows.Close()
+	for rows.Next() {
+		var prID, label string
+		if err := rows.Scan(&prID, &label); err != nil {
+			return nil, fmt.Errorf("scan label: %w", err)
+		}
+		labels[prID] = append(labels[prID], label)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("iterate labels: %w", err)
+	}
+	return labels, nil
+}
+
+// UpdatePRDetails sets the title and description of an open pull request.
+// It returns ErrPRNotOpen when the pull request was merged or closed in the
+// meantime.
+func (s *PRStorage) UpdatePRDetails(ctx context.Context, prID, title, description string) error {
+	exec := getQueryExecer(ctx, s.db.DB)
+	var open bool
+	err := exec.QueryRowContext(
+		ctx,
+		`
+update pull_requests
+set title = case when `+s.statusColumn("")+` = 'OPEN' then $2 else title end,
+    description = case when `+s.statusColumn("")+` = 'OPEN' then $3 else description end
+where id = $1
+returning `+s.statusColumn("")+` = 'OPEN'
+`,
+		prID,
+		title,
+		description,
+	).Scan(&open)
+	if errors.Is(err, sql.ErrNoRows) {
+		return fmt.Errorf("update pr details: %w", ErrPRNotFound)
+	}
+	if err != nil {
+		s.log.Error("failed to update pr details", slog.Any("error", err), slog.String("pr_id", prID))
+		return fmt.Errorf("update pr details: %w", err)
+	}
+	if !open {
+		return fmt.Errorf("update pr details: %w", ErrPRNotOpen)
+	}
+	return nil
+}
+
+// SetPRLabels replaces the labels of a pull request.
+func (s *PRStorage) SetPRLabels(ctx context.Context, prID string, labels []string) error {
+	exec := getExecer(ctx, s.db.DB)
+	if _, err := exec.ExecContext(ctx, `delete from pr_labels where pull_request_id = $1`, prID); err != nil {
+		return fmt.Errorf("clear pr labels: %w", err)
+	}
+	if len(labels) == 0 {
+		return nil
+	}
+	qb := newQueryBuilder()
+	qb.write(`insert into pr_labels (pull_request_id, label) values `)
+	for i, label := range labels {
+		if i > 0 {
+			qb.write(", ")
+		}
+		qb.write("(", qb.arg(prID), ", ", qb.arg(label), ")")
+	}
+	if _, err := exec.ExecContext(ctx, qb.query(), qb.queryArgs()...); err != nil {
+		return fmt.Errorf("insert pr labels: %w", err)
+	}
+	return nil
+}
+
+// GetReviewersByPRIDs reads the reviewers of every pull request in prIDs with
+// a single query and groups them by pull request id, each group ordered by
+// user id. Pull requests without reviewers are absent from the map.
+func (s *PRStorage) GetReviewersByPRIDs(ctx context.Context, prIDs []string) (map[string][]*models.ReviewerDetail, error) {
+	reviewers := make(map[string][]*models.ReviewerDetail, len(prIDs))
+	if len(prIDs) == 0 {
+		return reviewers, nil
+	}
+	exec := getQueryExecer(ctx, s.db.DB)
+	qb := newQueryBuilder()
+	qb.write(`
+select r.pull_request_id, r.user_id, u.username, r.assigned_at, r.acknowledged_at, r.review_state, r.reviewed_at
+from pull_requests_reviewers r
+    join users u on u.id = r.user_id
+where r.pull_request_id in (`, qb.list(prIDs), `)
+order by r.pull_request_id, r.user_id
+`)
+	rows, err := exec.QueryContext(ctx, qb.query(), qb.queryArgs()...)
+	if err != nil {
+		s.log.Error("failed to get prs reviewers", slog.Any("error", err))
+		return nil, fmt.Errorf("get prs reviewers: %w", err)
+	}
+	defer rows.Close()
+	for rows.Next() {
+		var prID string
+		var detail models.ReviewerDetail
+		var acked, reviewed sql.NullTime
+		if err := rows.Scan(&prID, &detail.UserID, &detail.Username, &detail.AssignedAt, &acked, &detail.ReviewState, &reviewed); err != nil {
+			return nil, fmt.Errorf("scan reviewer: %w", err)
+		}
+		setReviewerState(&detail, acked)
+		scanMergedAt(&detail.ReviewedAt, reviewed)
+		reviewers[prID] = append(reviewers[prID], &detail)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("iterate reviewers: %w", err)
+	}
+	return reviewers, nil
+}
+
 func (s *PRStorage) MarkPRMerged(ctx context.Context, prID string, mergedAt time.Time) error {
+	if err := s.setFinalStatus(ctx, prID, models.StatusMerged, "merged_at", mergedAt); err != nil {
+		return fmt.Errorf("mark pr merged: %w", err)
+	}
+	return nil
+}
+
+// MarkPRClosed closes a pull request without merging it. Its reviewers stay
+// on record but no longer count as open load.
+func (s *PRStorage) MarkPRClosed(ctx context.Context, prID string, closedAt time.Time) error {
+	if err := s.setFinalStatus(ctx, prID, models.StatusClosed, "closed_at", closedAt); err != nil {
+		return fmt.Errorf("mark pr closed: %w", err)
+	}
+	return nil
+}
+
+func (s *PRStorage) setFinalStatus(ctx context.Context, prID, status, atColumn string, at time.Time) error {
 	exec := getExecer(ctx, s.db.DB)
+	query := `
+update pull_requests
+set status = $2,
+    ` + atColumn + ` = $3
+where id = $1`
+	if s.statusPhase.get().WritesNew() {
+		query = `
+update pull_requests
+set status = $2,
+    status_enum = $2::pr_status,
+    ` + atColumn + ` = $3
+where id = $1`
+	}
 	res, err := exec.ExecContext(
 		ctx,
-		`
-update pull_requests
-set status_id = (select id from statuses where name = $2),
-    merged_at = $3
-where id = $1`,
+		query,
 		prID,
-		models.StatusMerged,
-		mergedAt,
+		status,
+		at,
 	)
 	if err != nil {
-		return fmt.Errorf("mark pr merged: %w", err)
+		if postgres.IsCheckViolation(err, prStatusCheck) {
+			return fmt.Errorf("%w: %s", ErrStatusNotFound, status)
+		}
+		return err
 	}
 	rows, err := res.RowsAffected()
 	if err != nil {
@@ -240,6 +1010,27 @@ where id = $1`,
 	return nil
 }
 
+func (s *PRStorage) RemoveReviewer(ctx context.Context, prID, reviewerID string) error {
+	exec := getExecer(ctx, s.db.DB)
+	res, err := exec.ExecContext(
+		ctx,
+		`delete from pull_requests_reviewers where pull_request_id = $1 and user_id = $2`,
+		prID,
+		reviewerID,
+	)
+	if err != nil {
+		return fmt.Errorf("delete reviewer: %w", err)
+	}
+	rows, err := res.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("delete reviewer rows: %w", err)
+	}
+	if rows == 0 {
+		return ErrReviewerNotAssigned
+	}
+	return nil
+}
+
 func (s *PRStorage) ReplaceReviewer(ctx context.Context, prID, oldReviewerID, newReviewerID string) error {
 	exec := getExecer(ctx, s.db.DB)
 	res, err := exec.ExecContext(
@@ -7,6 +7,7 @@ import (
 	"io"
 	"log/slog"
 	"regexp"
+	"slices"
 	"testing"
 	"time"
 
@@ -41,30 +42,37 @@ func newPRStorage(t *testing.T) (*PRStorage, sqlmock.Sqlmock) {
 func TestPRStorage_CreatePR_Success(t *testing.T) {
 	st, mock := newPRStorage(t)
 	const prID = "pr1"
+	deadline := time.Date(2025, 3, 3, 10, 0, 0, 0, time.UTC)
 	query := regexp.QuoteMeta(`
-        insert into pull_requests (id, title, author_id, status_id)
-        values ($1, $2, $3, (select id from statuses where name = $4))
-        returning id, title, author_id, $4 as status, merged_at`)
+        insert into pull_requests (id, title, author_id, status, description, repository_url, branch, priority, deadline)
+        values ($1, $2, $3, $4, $5, $6, $7, $8, coalesce($9::timestamptz, (`)
 	mock.ExpectQuery(query).
-		WithArgs(prID, "title", "author", models.StatusOpen).
-		WillReturnRows(sqlmock.NewRows([]string{"id", "title", "author_id", "status", "merged_at"}).
-			AddRow(prID, "title", "author", models.StatusOpen, nil))
+		WithArgs(prID, "title", "author", models.StatusOpen, "desc", "https://git.example.com/shop", "feature/search", models.PriorityHigh, nil).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "title", "author_id", "status", "description", "repository_url", "branch", "priority", "deadline", "created_at", "merged_at"}).
+			AddRow(prID, "title", "author", models.StatusOpen, "desc", "https://git.example.com/shop", "feature/search", models.PriorityHigh, deadline, time.Now(), nil))
 
 	pr, err := st.CreatePR(context.Background(), models.PullRequest{
-		ID:       prID,
-		Title:    "title",
-		AuthorID: "author",
-		Status:   models.StatusOpen,
+		ID:            prID,
+		Title:         "title",
+		Description:   "desc",
+		RepositoryURL: "https://git.example.com/shop",
+		Branch:        "feature/search",
+		Priority:      models.PriorityHigh,
+		AuthorID:      "author",
+		Status:        models.StatusOpen,
 	})
 	if err != nil {
 		t.Fatalf("CreatePR returned err: %v", err)
 	}
-	if pr == nil || pr.ID != prID {
+	if pr == nil || pr.ID != prID || pr.RepositoryURL != "https://git.example.com/shop" || pr.Branch != "feature/search" || pr.Priority != models.PriorityHigh {
 		t.Fatalf("unexpected PR: %#v", pr)
 	}
 	if pr.MergedAt != nil {
 		t.Fatalf("expected merged_at to be nil")
 	}
+	if pr.Deadline == nil || !pr.Deadline.Equal(deadline) {
+		t.Fatalf("expected the team SLA deadline, got %v", pr.Deadline)
+	}
 	verifyExpectations(t, mock)
 }
 
@@ -72,11 +80,10 @@ func TestPRStorage_CreatePR_UniqueViolation(t *testing.T) {
 	st, mock := newPRStorage(t)
 	const prID = "pr1"
 	query := regexp.QuoteMeta(`
-        insert into pull_requests (id, title, author_id, status_id)
-        values ($1, $2, $3, (select id from statuses where name = $4))
-        returning id, title, author_id, $4 as status, merged_at`)
+        insert into pull_requests (id, title, author_id, status, description, repository_url, branch, priority, deadline)
+        values ($1, $2, $3, $4, $5, $6, $7, $8, coalesce($9::timestamptz, (`)
 	mock.ExpectQuery(query).
-		WithArgs(prID, "title", "author", models.StatusOpen).
+		WithArgs(prID, "title", "author", models.StatusOpen, "", "", "", "", nil).
 		WillReturnError(&pgconn.PgError{Code: "23505"})
 
 	_, err := st.CreatePR(context.Background(), models.PullRequest{
@@ -110,24 +117,24 @@ func TestPRStorage_AddReviewers(t *testing.T) {
 func TestPRStorage_GetReviewerPRs(t *testing.T) {
 	st, mock := newPRStorage(t)
 	query := regexp.QuoteMeta(`
-select pr.id, pr.title, pr.author_id, s.name
+select pr.id, pr.title, pr.author_id, pr.status, pr.priority, floor(extract(epoch from coalesce(pr.merged_at, now()) - pr.created_at) / 86400)::int
 from pull_requests pr
     join pull_requests_reviewers r on r.pull_request_id = pr.id
-    join statuses s on s.id = pr.status_id
 where r.user_id = $1
-order by pr.id
+order by pr.status = $2 desc, case pr.priority when 'URGENT' then 0 when 'HIGH' then 1 when 'LOW' then 3 else 2 end, pr.created_at, pr.id
 `)
-	rows := sqlmock.NewRows([]string{"id", "title", "author_id", "status"}).
-		AddRow("pr1", "title1", "author1", models.StatusOpen)
+	rows := sqlmock.NewRows([]string{"id", "title", "author_id", "status", "priority", "age_days"}).
+		AddRow("pr2", "title2", "author1", models.StatusOpen, models.PriorityUrgent, 5).
+		AddRow("pr1", "title1", "author1", models.StatusOpen, models.PriorityNormal, 1)
 	mock.ExpectQuery(query).
-		WithArgs("u1").
+		WithArgs("u1", models.StatusOpen).
 		WillReturnRows(rows)
 
 	prs, err := st.GetReviewerPRs(context.Background(), "u1")
 	if err != nil {
 		t.Fatalf("GetReviewerPRs returned err: %v", err)
 	}
-	if len(prs) != 1 || prs[0].ID != "pr1" {
+	if len(prs) != 2 || prs[0].ID != "pr2" || prs[0].Priority != models.PriorityUrgent || prs[0].AgeDays != 5 {
 		t.Fatalf("unexpected prs: %#v", prs)
 	}
 	verifyExpectations(t, mock)
@@ -215,41 +222,234 @@ order by reviewers desc, pull_request_id
 func TestPRStorage_GetPR_Success(t *testing.T) {
 	st, mock := newPRStorage(t)
 	prQuery := regexp.QuoteMeta(`
-select pr.id, pr.title, pr.author_id, s.name, pr.merged_at
+select pr.id, pr.title, pr.description, pr.repository_url, pr.branch, pr.priority, pr.author_id, pr.status, pr.deadline, pr.created_at, pr.merged_at, pr.closed_at,
+       a.username, a.team_name, a.is_active
 from pull_requests pr
-    join statuses s on s.id = pr.status_id
+    join users a on a.id = pr.author_id
 where pr.id = $1
 `)
 	mergedAt := time.Now()
 	mock.ExpectQuery(prQuery).
 		WithArgs("pr1").
-		WillReturnRows(sqlmock.NewRows([]string{"id", "title", "author_id", "status", "merged_at"}).
-			AddRow("pr1", "title", "author", models.StatusOpen, mergedAt))
-
-	reviewerRows := sqlmock.NewRows([]string{"user_id"}).AddRow("u1").AddRow("u2")
-	mock.ExpectQuery(regexp.QuoteMeta(`select user_id from pull_requests_reviewers where pull_request_id = $1 order by user_id`)).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "title", "description", "repository_url", "branch", "priority", "author_id", "status", "deadline", "created_at", "merged_at", "closed_at", "username", "team_name", "is_active"}).
+			AddRow("pr1", "title", "", "https://git.example.com/shop", "feature/search", models.PriorityNormal, "author", models.StatusOpen, mergedAt.Add(-time.Minute), mergedAt.Add(-time.Hour), mergedAt, nil, "dave", "backend", true))
+
+	assignedAt := mergedAt.Add(-time.Hour)
+	reviewerRows := sqlmock.NewRows([]string{"user_id", "username", "assigned_at", "acknowledged_at", "review_state", "reviewed_at"}).
+		AddRow("u1", "alice", assignedAt, assignedAt, models.ReviewStateApproved, assignedAt).
+		AddRow("u2", "bob", assignedAt, nil, models.ReviewStatePending, nil)
+	mock.ExpectQuery(regexp.QuoteMeta(`
+select r.user_id, u.username, r.assigned_at, r.acknowledged_at, r.review_state, r.reviewed_at
+from pull_requests_reviewers r
+    join users u on u.id = r.user_id
+where r.pull_request_id = $1
+order by r.user_id
+`)).
 		WithArgs("pr1").
 		WillReturnRows(reviewerRows)
+	mock.ExpectQuery(regexp.QuoteMeta(`where l.pull_request_id in ($1)`)).
+		WithArgs("pr1").
+		WillReturnRows(sqlmock.NewRows([]string{"pull_request_id", "label"}).AddRow("pr1", "backend").AddRow("pr1", "urgent"))
 
 	pr, err := st.GetPR(context.Background(), "pr1")
 	if err != nil {
 		t.Fatalf("GetPR returned err: %v", err)
 	}
-	if pr.Status != models.StatusOpen || len(pr.Reviewers) != 2 {
+	if !slices.Equal(pr.Labels, []string{"backend", "urgent"}) {
+		t.Fatalf("unexpected labels: %v", pr.Labels)
+	}
+	if pr.Deadline == nil || !pr.Overdue {
+		t.Fatalf("expected an overdue pr, got deadline %v", pr.Deadline)
+	}
+	if pr.Status != models.StatusOpen || pr.Branch != "feature/search" || len(pr.Reviewers) != 2 || len(pr.ReviewerDetails) != 2 {
 		t.Fatalf("unexpected pr: %#v", pr)
 	}
+	if pr.ReviewerDetails[0].State != models.ReviewerStateAcknowledged || pr.ReviewerDetails[0].Username != "alice" ||
+		pr.ReviewerDetails[0].ReviewState != models.ReviewStateApproved || pr.ReviewerDetails[0].ReviewedAt == nil {
+		t.Fatalf("unexpected first reviewer: %#v", pr.ReviewerDetails[0])
+	}
+	if pr.ReviewerDetails[1].State != models.ReviewerStateAssigned || pr.ReviewerDetails[1].AcknowledgedAt != nil {
+		t.Fatalf("unexpected second reviewer: %#v", pr.ReviewerDetails[1])
+	}
 	if pr.MergedAt == nil || !pr.MergedAt.Equal(mergedAt) {
 		t.Fatalf("expected merged_at to be set")
 	}
+	if pr.Author == nil || pr.Author.ID != "author" || pr.Author.Username != "dave" || pr.Author.TeamName != "backend" || !pr.Author.IsActive {
+		t.Fatalf("unexpected author: %#v", pr.Author)
+	}
+	verifyExpectations(t, mock)
+}
+
+func TestPRStorage_GetPRsByIDs(t *testing.T) {
+	st, mock := newPRStorage(t)
+	createdAt := time.Now()
+	mock.ExpectQuery(regexp.QuoteMeta(`where pr.id in ($1, $2, $3)
+order by pr.id`)).
+		WithArgs("pr1", "pr2", "ghost").
+		WillReturnRows(sqlmock.NewRows([]string{"id", "title", "description", "repository_url", "branch", "priority", "author_id", "status", "deadline", "created_at", "merged_at", "closed_at", "username", "team_name", "is_active"}).
+			AddRow("pr1", "first", "", "", "", models.PriorityNormal, "author", models.StatusOpen, nil, createdAt, nil, nil, "dave", "backend", true).
+			AddRow("pr2", "second", "", "", "", models.PriorityNormal, "author", models.StatusMerged, nil, createdAt, createdAt, nil, "dave", "backend", true))
+	mock.ExpectQuery(regexp.QuoteMeta(`where r.pull_request_id in ($1, $2)
+order by r.pull_request_id, r.user_id`)).
+		WithArgs("pr1", "pr2").
+		WillReturnRows(sqlmock.NewRows([]string{"pull_request_id", "user_id", "username", "assigned_at", "acknowledged_at", "review_state", "reviewed_at"}).
+			AddRow("pr1", "u1", "alice", createdAt, createdAt, models.ReviewStatePending, nil).
+			AddRow("pr1", "u2", "bob", createdAt, nil, models.ReviewStatePending, nil))
+	mock.ExpectQuery(regexp.QuoteMeta(`where l.pull_request_id in ($1, $2)`)).
+		WithArgs("pr1", "pr2").
+		WillReturnRows(sqlmock.NewRows([]string{"pull_request_id", "label"}).AddRow("pr2", "docs"))
+
+	prs, err := st.GetPRsByIDs(context.Background(), []string{"pr1", "pr2", "ghost"})
+	if err != nil {
+		t.Fatalf("GetPRsByIDs returned err: %v", err)
+	}
+	if len(prs) != 2 || prs[0].ID != "pr1" || prs[1].ID != "pr2" {
+		t.Fatalf("unexpected prs: %#v", prs)
+	}
+	if len(prs[0].Reviewers) != 2 || prs[0].ReviewerDetails[0].State != models.ReviewerStateAcknowledged || prs[0].ReviewerDetails[1].State != models.ReviewerStateAssigned {
+		t.Fatalf("unexpected reviewers of pr1: %v %#v", prs[0].Reviewers, prs[0].ReviewerDetails)
+	}
+	if len(prs[1].Reviewers) != 0 || prs[1].MergedAt == nil || prs[1].Author.Username != "dave" || !slices.Equal(prs[1].Labels, []string{"docs"}) {
+		t.Fatalf("unexpected pr2: %#v", prs[1])
+	}
+	verifyExpectations(t, mock)
+}
+
+func TestPRStorage_ListPRs(t *testing.T) {
+	st, mock := newPRStorage(t)
+	from := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	createdAt := from.Add(time.Hour)
+	mock.ExpectQuery(regexp.QuoteMeta(`select count(*)
+from pull_requests pr`)).
+		WithArgs(models.StatusOpen, "", "u1", "backend", from, nil, nil, nil).
+		WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(5))
+	mock.ExpectQuery(regexp.QuoteMeta(`order by pr.created_at desc, pr.id desc
+limit $9 offset $10`)).
+		WithArgs(models.StatusOpen, "", "u1", "backend", from, nil, nil, nil, 2, 2).
+		WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow("pr9").AddRow("pr1"))
+	mock.ExpectQuery(regexp.QuoteMeta(`where pr.id in ($1, $2)`)).
+		WithArgs("pr9", "pr1").
+		WillReturnRows(sqlmock.NewRows([]string{"id", "title", "description", "repository_url", "branch", "priority", "author_id", "status", "deadline", "created_at", "merged_at", "closed_at", "username", "team_name", "is_active"}).
+			AddRow("pr1", "first", "", "", "", models.PriorityNormal, "author", models.StatusOpen, nil, createdAt, nil, nil, "dave", "backend", true).
+			AddRow("pr9", "ninth", "", "", "", models.PriorityNormal, "author", models.StatusOpen, nil, createdAt, nil, nil, "dave", "backend", true))
+	mock.ExpectQuery(regexp.QuoteMeta(`where r.pull_request_id in ($1, $2)`)).
+		WithArgs("pr1", "pr9").
+		WillReturnRows(sqlmock.NewRows([]string{"pull_request_id", "user_id", "username", "assigned_at", "acknowledged_at", "review_state", "reviewed_at"}).
+			AddRow("pr1", "u1", "alice", createdAt, nil, models.ReviewStatePending, nil).
+			AddRow("pr9", "u1", "alice", createdAt, nil, models.ReviewStatePending, nil))
+	mock.ExpectQuery(regexp.QuoteMeta(`from pr_labels l`)).
+		WithArgs("pr1", "pr9").
+		WillReturnRows(sqlmock.NewRows([]string{"pull_request_id", "label"}))
+
+	prs, total, err := st.ListPRs(context.Background(), models.PRListQuery{
+		Status:      models.StatusOpen,
+		ReviewerID:  "u1",
+		TeamName:    "backend",
+		CreatedFrom: &from,
+		Limit:       2,
+		Offset:      2,
+	})
+	if err != nil {
+		t.Fatalf("ListPRs returned err: %v", err)
+	}
+	if total != 5 || len(prs) != 2 || prs[0].ID != "pr9" || prs[1].ID != "pr1" {
+		t.Fatalf("expected newest first page of 5, got total=%d %#v", total, prs)
+	}
+	if len(prs[0].Reviewers) != 1 {
+		t.Fatalf("expected reviewers to be loaded, got %v", prs[0].Reviewers)
+	}
+	verifyExpectations(t, mock)
+}
+
+func TestPRStorage_ListPRs_Labels(t *testing.T) {
+	st, mock := newPRStorage(t)
+	mock.ExpectQuery(regexp.QuoteMeta(`where l.pull_request_id = pr.id and l.label in ($9, $10)
+  ) = $11`)).
+		WithArgs("", "", "", "", nil, nil, nil, nil, "bug", "urgent", 2).
+		WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(0))
+	mock.ExpectQuery(regexp.QuoteMeta(`limit $12 offset $13`)).
+		WithArgs("", "", "", "", nil, nil, nil, nil, "bug", "urgent", 2, 10, 0).
+		WillReturnRows(sqlmock.NewRows([]string{"id"}))
+
+	prs, total, err := st.ListPRs(context.Background(), models.PRListQuery{Labels: []string{"bug", "urgent"}, Limit: 10})
+	if err != nil {
+		t.Fatalf("ListPRs returned err: %v", err)
+	}
+	if total != 0 || len(prs) != 0 {
+		t.Fatalf("expected empty page, got total=%d %#v", total, prs)
+	}
+	verifyExpectations(t, mock)
+}
+
+func TestPRStorage_ListPRs_Overdue(t *testing.T) {
+	st, mock := newPRStorage(t)
+	mock.ExpectQuery(regexp.QuoteMeta(`and pr.status = $9 and pr.deadline < now()`)).
+		WithArgs("", "", "", "", nil, nil, nil, nil, models.StatusOpen).
+		WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(0))
+	mock.ExpectQuery(regexp.QuoteMeta(`limit $10 offset $11`)).
+		WithArgs("", "", "", "", nil, nil, nil, nil, models.StatusOpen, 10, 0).
+		WillReturnRows(sqlmock.NewRows([]string{"id"}))
+
+	if _, _, err := st.ListPRs(context.Background(), models.PRListQuery{Overdue: true, Limit: 10}); err != nil {
+		t.Fatalf("ListPRs returned err: %v", err)
+	}
+	verifyExpectations(t, mock)
+}
+
+func TestPRStorage_ListPRs_OverdueWithLabels(t *testing.T) {
+	st, mock := newPRStorage(t)
+	// The overdue status follows the label list and count, so it must not be
+	// numbered on its own.
+	mock.ExpectQuery(regexp.QuoteMeta(`l.label in ($9)
+  ) = $10
+  and pr.status = $11 and pr.deadline < now()`)).
+		WithArgs("", "", "", "", nil, nil, nil, nil, "bug", 1, models.StatusOpen).
+		WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(0))
+	mock.ExpectQuery(regexp.QuoteMeta(`limit $12 offset $13`)).
+		WithArgs("", "", "", "", nil, nil, nil, nil, "bug", 1, models.StatusOpen, 10, 0).
+		WillReturnRows(sqlmock.NewRows([]string{"id"}))
+
+	if _, _, err := st.ListPRs(context.Background(), models.PRListQuery{Labels: []string{"bug"}, Overdue: true, Limit: 10}); err != nil {
+		t.Fatalf("ListPRs returned err: %v", err)
+	}
+	verifyExpectations(t, mock)
+}
+
+func TestPRStorage_GetReviewersByPRIDs(t *testing.T) {
+	st, mock := newPRStorage(t)
+	assignedAt := time.Now()
+	mock.ExpectQuery(regexp.QuoteMeta(`where r.pull_request_id in ($1, $2, $3)`)).
+		WithArgs("pr1", "pr2", "pr3").
+		WillReturnRows(sqlmock.NewRows([]string{"pull_request_id", "user_id", "username", "assigned_at", "acknowledged_at", "review_state", "reviewed_at"}).
+			AddRow("pr1", "u1", "alice", assignedAt, nil, models.ReviewStatePending, nil).
+			AddRow("pr3", "u1", "alice", assignedAt, assignedAt, models.ReviewStateChangesRequested, assignedAt).
+			AddRow("pr3", "u2", "bob", assignedAt, nil, models.ReviewStatePending, nil))
+
+	reviewers, err := st.GetReviewersByPRIDs(context.Background(), []string{"pr1", "pr2", "pr3"})
+	if err != nil {
+		t.Fatalf("GetReviewersByPRIDs returned err: %v", err)
+	}
+	if len(reviewers) != 2 || len(reviewers["pr1"]) != 1 || len(reviewers["pr3"]) != 2 {
+		t.Fatalf("unexpected reviewers: %#v", reviewers)
+	}
+	if got := reviewers["pr3"][0]; got.UserID != "u1" || got.State != models.ReviewerStateAcknowledged || got.ReviewState != models.ReviewStateChangesRequested {
+		t.Fatalf("unexpected reviewer: %#v", got)
+	}
+
+	empty, err := st.GetReviewersByPRIDs(context.Background(), nil)
+	if err != nil || len(empty) != 0 {
+		t.Fatalf("expected no query for empty ids, got %v %v", empty, err)
+	}
 	verifyExpectations(t, mock)
 }
 
 func TestPRStorage_GetPR_NotFound(t *testing.T) {
 	st, mock := newPRStorage(t)
 	query := regexp.QuoteMeta(`
-select pr.id, pr.title, pr.author_id, s.name, pr.merged_at
+select pr.id, pr.title, pr.description, pr.repository_url, pr.branch, pr.priority, pr.author_id, pr.status, pr.deadline, pr.created_at, pr.merged_at, pr.closed_at,
+       a.username, a.team_name, a.is_active
 from pull_requests pr
-    join statuses s on s.id = pr.status_id
+    join users a on a.id = pr.author_id
 where pr.id = $1
 `)
 	mock.ExpectQuery(query).
@@ -263,11 +463,57 @@ where pr.id = $1
 	verifyExpectations(t, mock)
 }
 
+func TestPRStorage_UpdatePRDetails(t *testing.T) {
+	st, mock := newPRStorage(t)
+	query := regexp.QuoteMeta(`returning status = 'OPEN'`)
+	mock.ExpectQuery(query).
+		WithArgs("pr1", "new title", "desc").
+		WillReturnRows(sqlmock.NewRows([]string{"open"}).AddRow(true))
+	mock.ExpectQuery(query).
+		WithArgs("merged", "new title", "desc").
+		WillReturnRows(sqlmock.NewRows([]string{"open"}).AddRow(false))
+	mock.ExpectQuery(query).
+		WithArgs("ghost", "new title", "desc").
+		WillReturnError(sql.ErrNoRows)
+
+	if err := st.UpdatePRDetails(context.Background(), "pr1", "new title", "desc"); err != nil {
+		t.Fatalf("UpdatePRDetails returned err: %v", err)
+	}
+	if err := st.UpdatePRDetails(context.Background(), "merged", "new title", "desc"); !errors.Is(err, ErrPRNotOpen) {
+		t.Fatalf("expected ErrPRNotOpen, got %v", err)
+	}
+	if err := st.UpdatePRDetails(context.Background(), "ghost", "new title", "desc"); !errors.Is(err, ErrPRNotFound) {
+		t.Fatalf("expected ErrPRNotFound, got %v", err)
+	}
+	verifyExpectations(t, mock)
+}
+
+func TestPRStorage_SetPRLabels(t *testing.T) {
+	st, mock := newPRStorage(t)
+	mock.ExpectExec(regexp.QuoteMeta(`delete from pr_labels where pull_request_id = $1`)).
+		WithArgs("pr1").
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectExec(regexp.QuoteMeta(`insert into pr_labels (pull_request_id, label) values ($1, $2), ($3, $4)`)).
+		WithArgs("pr1", "backend", "pr1", "urgent").
+		WillReturnResult(sqlmock.NewResult(0, 2))
+	mock.ExpectExec(regexp.QuoteMeta(`delete from pr_labels where pull_request_id = $1`)).
+		WithArgs("pr1").
+		WillReturnResult(sqlmock.NewResult(0, 2))
+
+	if err := st.SetPRLabels(context.Background(), "pr1", []string{"backend", "urgent"}); err != nil {
+		t.Fatalf("SetPRLabels returned err: %v", err)
+	}
+	if err := st.SetPRLabels(context.Background(), "pr1", nil); err != nil {
+		t.Fatalf("clearing labels returned err: %v", err)
+	}
+	verifyExpectations(t, mock)
+}
+
 func TestPRStorage_MarkPRMerged(t *testing.T) {
 	st, mock := newPRStorage(t)
 	mock.ExpectExec(regexp.QuoteMeta(`
 update pull_requests
-set status_id = (select id from statuses where name = $2),
+set status = $2,
     merged_at = $3
 where id = $1`)).
 		WithArgs("pr1", models.StatusMerged, sqlmock.AnyArg()).
@@ -284,7 +530,7 @@ func TestPRStorage_MarkPRMerged_NotFound(t *testing.T) {
 	st, mock := newPRStorage(t)
 	mock.ExpectExec(regexp.QuoteMeta(`
 update pull_requests
-set status_id = (select id from statuses where name = $2),
+set status = $2,
     merged_at = $3
 where id = $1
 `)).
@@ -298,6 +544,22 @@ where id = $1
 	verifyExpectations(t, mock)
 }
 
+func TestPRStorage_MarkPRClosed(t *testing.T) {
+	st, mock := newPRStorage(t)
+	mock.ExpectExec(regexp.QuoteMeta(`
+update pull_requests
+set status = $2,
+    closed_at = $3
+where id = $1`)).
+		WithArgs("pr1", models.StatusClosed, sqlmock.AnyArg()).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+
+	if err := st.MarkPRClosed(context.Background(), "pr1", time.Now()); err != nil {
+		t.Fatalf("MarkPRClosed returned err: %v", err)
+	}
+	verifyExpectations(t, mock)
+}
+
 func TestPRStorage_ReplaceReviewer(t *testing.T) {
 	st, mock := newPRStorage(t)
 	mock.ExpectExec(regexp.QuoteMeta(`delete from pull_requests_reviewers where pull_request_id = $1 and user_id = $2`)).
@@ -326,3 +588,241 @@ func TestPRStorage_ReplaceReviewer_NotAssigned(t *testing.T) {
 	}
 	verifyExpectations(t, mock)
 }
+
+func TestPRStorage_RemoveReviewer(t *testing.T) {
+	st, mock := newPRStorage(t)
+	mock.ExpectExec(regexp.QuoteMeta(`delete from pull_requests_reviewers where pull_request_id = $1 and user_id = $2`)).
+		WithArgs("pr1", "u1").
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectExec(regexp.QuoteMeta(`delete from pull_requests_reviewers where pull_request_id = $1 and user_id = $2`)).
+		WithArgs("pr1", "u9").
+		WillReturnResult(sqlmock.NewResult(0, 0))
+
+	if err := st.RemoveReviewer(context.Background(), "pr1", "u1"); err != nil {
+		t.Fatalf("RemoveReviewer returned err: %v", err)
+	}
+	if err := st.RemoveReviewer(context.Background(), "pr1", "u9"); !errors.Is(err, ErrReviewerNotAssigned) {
+		t.Fatalf("expected ErrReviewerNotAssigned, got %v", err)
+	}
+	verifyExpectations(t, mock)
+}
+
+func TestPRStorage_AddReviewers_AlreadyAssigned(t *testing.T) {
+	st, mock := newPRStorage(t)
+	mock.ExpectExec(regexp.QuoteMeta(`insert into pull_requests_reviewers (pull_request_id, user_id) values ($1, $2)`)).
+		WithArgs("pr1", "u1").
+		WillReturnError(&pgconn.PgError{Code: "23505"})
+
+	if err := st.AddReviewers(context.Background(), "pr1", []string{"u1"}); !errors.Is(err, ErrReviewerAssigned) {
+		t.Fatalf("expected ErrReviewerAssigned, got %v", err)
+	}
+	verifyExpectations(t, mock)
+}
+
+func TestPRStorage_GetOpenPRsByAuthor(t *testing.T) {
+	st, mock := newPRStorage(t)
+	query := regexp.QuoteMeta(`
+select pr.id, pr.title, pr.author_id, pr.status, floor(extract(epoch from coalesce(pr.merged_at, now()) - pr.created_at) / 86400)::int
+from pull_requests pr
+where pr.author_id = $1
+  and pr.status = $2
+order by pr.id
+`)
+	mock.ExpectQuery(query).
+		WithArgs("author", models.StatusOpen).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "title", "author_id", "status", "age_days"}).
+			AddRow("pr1", "title1", "author", models.StatusOpen, 0))
+
+	prs, err := st.GetOpenPRsByAuthor(context.Background(), "author")
+	if err != nil {
+		t.Fatalf("GetOpenPRsByAuthor returned err: %v", err)
+	}
+	if len(prs) != 1 || prs[0].ID != "pr1" {
+		t.Fatalf("unexpected prs: %#v", prs)
+	}
+	verifyExpectations(t, mock)
+}
+
+func TestPRStorage_GetReviewerAgenda(t *testing.T) {
+	st, mock := newPRStorage(t)
+	assignedAt := time.Now()
+	mock.ExpectQuery(regexp.QuoteMeta(`then r.assigned_at + make_interval(hours => coalesce(nullif(ts.ack_timeout_hours, 0), $3))`)).
+		WithArgs("u1", models.StatusOpen, 24).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "title", "author_id", "assigned_at", "acknowledged_at", "due_at", "age_days", "assigned_today"}).
+			AddRow("pr1", "first", "author", assignedAt, nil, assignedAt.Add(24*time.Hour), 2, false).
+			AddRow("pr2", "second", "author", assignedAt, assignedAt, nil, 0, true))
+
+	items, err := st.GetReviewerAgenda(context.Background(), "u1", 24)
+	if err != nil {
+		t.Fatalf("GetReviewerAgenda returned err: %v", err)
+	}
+	if len(items) != 2 || items[0].DueAt == nil || items[0].AcknowledgedAt != nil || items[0].AgeDays != 2 {
+		t.Fatalf("unexpected first item: %#v", items[0])
+	}
+	if items[1].DueAt != nil || items[1].AcknowledgedAt == nil || !items[1].AssignedToday {
+		t.Fatalf("unexpected second item: %#v", items[1])
+	}
+	verifyExpectations(t, mock)
+}
+
+func TestPRStorage_AddAssignmentEvents(t *testing.T) {
+	st, mock := newPRStorage(t)
+	query := regexp.QuoteMeta("insert into assignment_events (pull_request_id, user_id, event, reason) values ($1, $2, $3, $4)")
+	mock.ExpectExec(query).
+		WithArgs("pr1", "u1", models.EventAssigned, "").
+		WillReturnResult(sqlmock.NewResult(1, 1))
+	mock.ExpectExec(query).
+		WithArgs("pr1", "u2", models.EventAssigned, "").
+		WillReturnResult(sqlmock.NewResult(2, 1))
+
+	if err := st.AddAssignmentEvents(context.Background(), "pr1", []string{"u1", "u2"}, models.EventAssigned, ""); err != nil {
+		t.Fatalf("AddAssignmentEvents returned err: %v", err)
+	}
+	verifyExpectations(t, mock)
+}
+
+func TestPRStorage_AcknowledgeReviewer(t *testing.T) {
+	st, mock := newPRStorage(t)
+	mock.ExpectExec(regexp.QuoteMeta(`
+update pull_requests_reviewers
+set acknowledged_at = $3
+where pull_request_id = $1
+  and user_id = $2
+  and acknowledged_at is null`)).
+		WithArgs("pr1", "u1", sqlmock.AnyArg()).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+
+	acked, err := st.AcknowledgeReviewer(context.Background(), "pr1", "u1", time.Now())
+	if err != nil {
+		t.Fatalf("AcknowledgeReviewer returned err: %v", err)
+	}
+	if !acked {
+		t.Fatalf("expected assignment to be acknowledged")
+	}
+	verifyExpectations(t, mock)
+}
+
+func TestPRStorage_SetReviewState(t *testing.T) {
+	st, mock := newPRStorage(t)
+	mock.ExpectExec(regexp.QuoteMeta(`
+update pull_requests_reviewers
+set review_state = $3,
+    reviewed_at = $4,
+    acknowledged_at = coalesce(acknowledged_at, $4)
+where pull_request_id = $1
+  and user_id = $2
+  and review_state <> $3`)).
+		WithArgs("pr1", "u1", models.ReviewStateApproved, sqlmock.AnyArg()).
+		WillReturnResult(sqlmock.NewResult(0, 0))
+
+	changed, err := st.SetReviewState(context.Background(), "pr1", "u1", models.ReviewStateApproved, time.Now())
+	if err != nil {
+		t.Fatalf("SetReviewState returned err: %v", err)
+	}
+	if changed {
+		t.Fatalf("expected repeated verdict to change nothing")
+	}
+	verifyExpectations(t, mock)
+}
+
+func TestPRStorage_GetExpiredAssignments(t *testing.T) {
+	st, mock := newPRStorage(t)
+	assignedAt := time.Now().Add(-48 * time.Hour)
+	mock.ExpectQuery(regexp.QuoteMeta(`and r.assigned_at < $2::timestamptz - make_interval(hours => ts.ack_timeout_hours)`)).
+		WithArgs(models.StatusOpen, sqlmock.AnyArg()).
+		WillReturnRows(sqlmock.NewRows([]string{"pull_request_id", "user_id", "assigned_at"}).
+			AddRow("pr1", "u1", assignedAt))
+
+	assignments, err := st.GetExpiredAssignments(context.Background(), time.Now())
+	if err != nil {
+		t.Fatalf("GetExpiredAssignments returned err: %v", err)
+	}
+	if len(assignments) != 1 || assignments[0].UserID != "u1" || !assignments[0].AssignedAt.Equal(assignedAt) {
+		t.Fatalf("unexpected assignments: %#v", assignments)
+	}
+	verifyExpectations(t, mock)
+}
+
+const verdictsExcluded = `and n.event not in ('ASSIGNED', 'ACKNOWLEDGED', 'APPROVED', 'CHANGES_REQUESTED')`
+
+func TestPRStorage_GetAssignmentHistory(t *testing.T) {
+	st, mock := newPRStorage(t)
+	from := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	assignedAt := from.Add(time.Hour)
+	endedAt := from.Add(2 * time.Hour)
+	mock.ExpectQuery(regexp.QuoteMeta(`select count(*)
+from assignment_events e`)).
+		WithArgs("u1", from, nil).
+		WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(3))
+	// Review verdicts and acknowledgements do not end an assignment.
+	mock.ExpectQuery(regexp.QuoteMeta(verdictsExcluded)+`(?s).*`+regexp.QuoteMeta(`order by e.created_at desc, e.id desc
+limit $4 offset $5`)).
+		WithArgs("u1", from, nil, 2, 0).
+		WillReturnRows(sqlmock.NewRows([]string{"pull_request_id", "title", "author_id", "created_at", "outcome", "reason", "ended_at"}).
+			AddRow("pr1", "title", "a1", assignedAt, models.OutcomeReassigned, "", endedAt).
+			AddRow("pr2", "other", "a1", assignedAt, models.OutcomePending, "", nil))
+
+	items, total, err := st.GetAssignmentHistory(context.Background(), models.AssignmentHistoryQuery{
+		UserID: "u1",
+		From:   &from,
+		Limit:  2,
+	})
+	if err != nil {
+		t.Fatalf("GetAssignmentHistory returned err: %v", err)
+	}
+	if total != 3 || len(items) != 2 {
+		t.Fatalf("unexpected result: total=%d items=%d", total, len(items))
+	}
+	if items[0].EndedAt == nil || !items[0].EndedAt.Equal(endedAt) || items[1].EndedAt != nil {
+		t.Fatalf("unexpected ended_at values: %#v %#v", items[0], items[1])
+	}
+	verifyExpectations(t, mock)
+}
+
+func TestPRStorage_CreatePR_UnknownStatus(t *testing.T) {
+	st, mock := newPRStorage(t)
+	mock.ExpectQuery(regexp.QuoteMeta("insert into pull_requests (id, title, author_id, status, description")).
+		WithArgs("pr1", "Title", "u1", models.StatusOpen, "", "", "", "", nil).
+		WillReturnError(&pgconn.PgError{Code: "23514", ConstraintName: "pull_requests_status_check"})
+
+	_, err := st.CreatePR(context.Background(), models.PullRequest{ID: "pr1", Title: "Title", AuthorID: "u1", Status: models.StatusOpen})
+	if !errors.Is(err, ErrStatusNotFound) {
+		t.Fatalf("expected ErrStatusNotFound, got %v", err)
+	}
+	verifyExpectations(t, mock)
+}
+
+func TestPRStorage_GetPRsNeedingReviewers(t *testing.T) {
+	st, mock := newPRStorage(t)
+	mock.ExpectQuery(regexp.QuoteMeta(`having count(r.user_id) < $2`)).
+		WithArgs(models.StatusOpen, 2, "backend").
+		WillReturnRows(sqlmock.NewRows([]string{"id", "title", "author_id", "team_name", "count"}).
+			AddRow("pr1", "title", "u1", "backend", 0).
+			AddRow("pr2", "title", "u1", "backend", 1))
+
+	prs, err := st.GetPRsNeedingReviewers(context.Background(), "backend", 2)
+	if err != nil {
+		t.Fatalf("GetPRsNeedingReviewers returned err: %v", err)
+	}
+	if len(prs) != 2 || prs[0].Missing != 2 || prs[1].Missing != 1 {
+		t.Fatalf("unexpected prs: %+v %+v", prs[0], prs[1])
+	}
+	verifyExpectations(t, mock)
+}
+
+func TestPRStorage_GetRecentReviewers(t *testing.T) {
+	st, mock := newPRStorage(t)
+	mock.ExpectQuery(regexp.QuoteMeta(`order by pr.created_at desc, pr.id desc
+      limit $2`)).
+		WithArgs("u1", 3, models.EventAssigned).
+		WillReturnRows(sqlmock.NewRows([]string{"user_id"}).AddRow("u2").AddRow("u3"))
+
+	reviewers, err := st.GetRecentReviewers(context.Background(), "u1", 3)
+	if err != nil {
+		t.Fatalf("GetRecentReviewers returned err: %v", err)
+	}
+	if len(reviewers) != 2 || reviewers[0] != "u2" {
+		t.Fatalf("unexpected reviewers %v", reviewers)
+	}
+	verifyExpectations(t, mock)
+}
@@ -0,0 +1,43 @@
+package storage
+
+import (
+	"strconv"
+	"strings"
+)
+
+type queryBuilder struct {
+	sb   strings.Builder
+	args []any
+}
+
+func newQueryBuilder() *queryBuilder {
+	return &queryBuilder{}
+}
+
+func (b *queryBuilder) arg(value any) string {
+	b.args = append(b.args, value)
+	return "$" + strconv.Itoa(len(b.args))
+}
+
+func (b *queryBuilder) list(values []string) string {
+	placeholders := make([]string, len(values))
+	for i, v := range values {
+		placeholders[i] = b.arg(v)
+	}
+	return strings.Join(placeholders, ", ")
+}
+
+func (b *queryBuilder) write(parts ...string) *queryBuilder {
+	for _, p := range parts {
+		b.sb.WriteString(p)
+	}
+	return b
+}
+
+func (b *queryBuilder) query() string {
+	return b.sb.String()
+}
+
+func (b *queryBuilder) queryArgs() []any {
+	return b.args
+}
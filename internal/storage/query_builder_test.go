@@ -0,0 +1,20 @@
+package storage
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestQueryBuilder_NumbersPlaceholdersInOrder(t *testing.T) {
+	qb := newQueryBuilder()
+	qb.write("select * from users where team_name = ", qb.arg("backend"))
+	qb.write(" and id not in (", qb.list([]string{"u1", "u2"}), ")")
+	qb.write(" limit ", qb.arg(1))
+
+	if got, want := qb.query(), "select * from users where team_name = $1 and id not in ($2, $3) limit $4"; got != want {
+		t.Fatalf("unexpected query:\n got: %s\nwant: %s", got, want)
+	}
+	if got, want := qb.queryArgs(), []any{"backend", "u1", "u2", 1}; !reflect.DeepEqual(got, want) {
+		t.Fatalf("unexpected args: %#v", got)
+	}
+}
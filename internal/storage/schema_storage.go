@@ -0,0 +1,102 @@
+package storage
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"log/slog"
+
+	"github.com/cloudyy74/pr-reviewer-service/pkg/postgres"
+)
+
+type SchemaStorage struct {
+	db  *postgres.Postgres
+	log *slog.Logger
+}
+
+func NewSchemaStorage(db *postgres.Postgres, log *slog.Logger) (*SchemaStorage, error) {
+	if db == nil {
+		return nil, errors.New("database cannot be nil")
+	}
+	if log == nil {
+		return nil, errors.New("logger cannot be nil")
+	}
+	return &SchemaStorage{
+		db:  db,
+		log: log,
+	}, nil
+}
+
+func (s *SchemaStorage) GetColumns(ctx context.Context) (map[string][]string, error) {
+	exec := getQueryExecer(ctx, s.db.DB)
+	rows, err := exec.QueryContext(
+		ctx,
+		`
+select table_name, column_name
+from information_schema.columns
+where table_schema = current_schema()
+order by table_name, ordinal_position
+`,
+	)
+	if err != nil {
+		s.log.Error("failed to get columns", slog.Any("error", err))
+		return nil, fmt.Errorf("get columns: %w", err)
+	}
+	defer rows.Close()
+
+	columns := make(map[string][]string)
+	for rows.Next() {
+		var table, column string
+		if err := rows.Scan(&table, &column); err != nil {
+			return nil, fmt.Errorf("scan column: %w", err)
+		}
+		columns[table] = append(columns[table], column)
+	}
+
+	return columns, nil
+}
+
+func (s *SchemaStorage) GetIndexes(ctx context.Context) ([]string, error) {
+	exec := getQueryExecer(ctx, s.db.DB)
+	rows, err := exec.QueryContext(
+		ctx,
+		`
+select indexname
+from pg_indexes
+where schemaname = current_schema()
+order by indexname
+`,
+	)
+	if err != nil {
+		s.log.Error("failed to get indexes", slog.Any("error", err))
+		return nil, fmt.Errorf("get indexes: %w", err)
+	}
+	defer rows.Close()
+
+	indexes := make([]string, 0)
+	for rows.Next() {
+		var index string
+		if err := rows.Scan(&index); err != nil {
+			return nil, fmt.Errorf("scan index: %w", err)
+		}
+		indexes = append(indexes, index)
+	}
+
+	return indexes, nil
+}
+
+func (s *SchemaStorage) GetMigrationVersion(ctx context.Context) (int, bool, error) {
+	exec := getQueryExecer(ctx, s.db.DB)
+	var version int
+	var dirty bool
+	err := exec.QueryRowContext(ctx, "select version, dirty from schema_migrations limit 1").Scan(&version, &dirty)
+	if errors.Is(err, sql.ErrNoRows) {
+		return 0, false, nil
+	}
+	if err != nil {
+		s.log.Error("failed to get migration version", slog.Any("error", err))
+		return 0, false, fmt.Errorf("get migration version: %w", err)
+	}
+	return version, dirty, nil
+}
@@ -0,0 +1,63 @@
+package storage
+
+import (
+	"context"
+	"database/sql"
+	"io"
+	"log/slog"
+	"regexp"
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+
+	"github.com/cloudyy74/pr-reviewer-service/pkg/postgres"
+)
+
+func newSchemaStorage(t *testing.T) (*SchemaStorage, sqlmock.Sqlmock) {
+	t.Helper()
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("sqlmock.New: %v", err)
+	}
+	t.Cleanup(func() { db.Close() })
+
+	log := slog.New(slog.NewTextHandler(io.Discard, nil))
+	st, err := NewSchemaStorage(&postgres.Postgres{DB: db}, log)
+	if err != nil {
+		t.Fatalf("NewSchemaStorage: %v", err)
+	}
+	return st, mock
+}
+
+func TestSchemaStorage_GetColumns(t *testing.T) {
+	st, mock := newSchemaStorage(t)
+	mock.ExpectQuery(regexp.QuoteMeta(`from information_schema.columns`)).
+		WillReturnRows(sqlmock.NewRows([]string{"table_name", "column_name"}).
+			AddRow("teams", "name").
+			AddRow("users", "id").
+			AddRow("users", "username"))
+
+	columns, err := st.GetColumns(context.Background())
+	if err != nil {
+		t.Fatalf("GetColumns returned err: %v", err)
+	}
+	if len(columns) != 2 || len(columns["users"]) != 2 || columns["teams"][0] != "name" {
+		t.Fatalf("unexpected columns: %#v", columns)
+	}
+	verifyExpectations(t, mock)
+}
+
+func TestSchemaStorage_GetMigrationVersion_Empty(t *testing.T) {
+	st, mock := newSchemaStorage(t)
+	mock.ExpectQuery(regexp.QuoteMeta("select version, dirty from schema_migrations limit 1")).
+		WillReturnError(sql.ErrNoRows)
+
+	version, dirty, err := st.GetMigrationVersion(context.Background())
+	if err != nil {
+		t.Fatalf("GetMigrationVersion returned err: %v", err)
+	}
+	if version != 0 || dirty {
+		t.Fatalf("unexpected version: %d dirty=%v", version, dirty)
+	}
+	verifyExpectations(t, mock)
+}
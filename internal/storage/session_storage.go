@@ -0,0 +1,136 @@
+package storage
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"log/slog"
+	"time"
+
+	"github.com/cloudyy74/pr-reviewer-service/internal/models"
+	"github.com/cloudyy74/pr-reviewer-service/pkg/postgres"
+)
+
+var (
+	ErrSessionNotFound     = errors.New("session not found")
+	ErrCredentialsNotFound = errors.New("credentials not found")
+)
+
+type SessionStorage struct {
+	db  *postgres.Postgres
+	log *slog.Logger
+}
+
+func NewSessionStorage(db *postgres.Postgres, log *slog.Logger) (*SessionStorage, error) {
+	if db == nil {
+		return nil, errors.New("database cannot be nil")
+	}
+	if log == nil {
+		return nil, errors.New("logger cannot be nil")
+	}
+	return &SessionStorage{
+		db:  db,
+		log: log,
+	}, nil
+}
+
+func (s *SessionStorage) SetPasswordHash(ctx context.Context, userID, hash string) error {
+	exec := getExecer(ctx, s.db.DB)
+	_, err := exec.ExecContext(
+		ctx,
+		`
+insert into user_credentials (user_id, password_hash) values ($1, $2)
+on conflict (user_id) do update set
+password_hash = excluded.password_hash,
+updated_at = now()`,
+		userID,
+		hash,
+	)
+	if err != nil {
+		s.log.Error("failed to set password hash", slog.Any("error", err), slog.String("user_id", userID))
+		return fmt.Errorf("set password hash: %w", err)
+	}
+	return nil
+}
+
+func (s *SessionStorage) GetPasswordHash(ctx context.Context, userID string) (string, error) {
+	exec := getQueryExecer(ctx, s.db.DB)
+	var hash string
+	err := exec.QueryRowContext(
+		ctx,
+		`select password_hash from user_credentials where user_id = $1`,
+		userID,
+	).Scan(&hash)
+	if errors.Is(err, sql.ErrNoRows) {
+		return "", fmt.Errorf("get password hash: %w", ErrCredentialsNotFound)
+	}
+	if err != nil {
+		s.log.Error("failed to get password hash", slog.Any("error", err), slog.String("user_id", userID))
+		return "", fmt.Errorf("get password hash: %w", err)
+	}
+	return hash, nil
+}
+
+func (s *SessionStorage) CreateSession(ctx context.Context, id, userID, csrfToken string, expiresAt time.Time) error {
+	exec := getExecer(ctx, s.db.DB)
+	_, err := exec.ExecContext(
+		ctx,
+		`insert into sessions (id, user_id, csrf_token, expires_at) values ($1, $2, $3, $4)`,
+		id,
+		userID,
+		csrfToken,
+		expiresAt,
+	)
+	if err != nil {
+		s.log.Error("failed to create session", slog.Any("error", err), slog.String("user_id", userID))
+		return fmt.Errorf("create session: %w", err)
+	}
+	return nil
+}
+
+func (s *SessionStorage) GetSession(ctx context.Context, id string) (*models.Session, error) {
+	exec := getQueryExecer(ctx, s.db.DB)
+	var session models.Session
+	err := exec.QueryRowContext(
+		ctx,
+		`
+select s.id, s.csrf_token, s.expires_at, u.id, u.username, u.team_name, u.is_active
+from sessions s
+    join users u on u.id = s.user_id
+where s.id = $1
+  and s.expires_at > now()
+`,
+		id,
+	).Scan(
+		&session.ID, &session.CSRFToken, &session.ExpiresAt,
+		&session.User.ID, &session.User.Username, &session.User.TeamName, &session.User.IsActive,
+	)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, fmt.Errorf("get session: %w", ErrSessionNotFound)
+	}
+	if err != nil {
+		s.log.Error("failed to get session", slog.Any("error", err))
+		return nil, fmt.Errorf("get session: %w", err)
+	}
+	return &session, nil
+}
+
+func (s *SessionStorage) DeleteSession(ctx context.Context, id string) error {
+	exec := getExecer(ctx, s.db.DB)
+	if _, err := exec.ExecContext(ctx, `delete from sessions where id = $1`, id); err != nil {
+		s.log.Error("failed to delete session", slog.Any("error", err))
+		return fmt.Errorf("delete session: %w", err)
+	}
+	return nil
+}
+
+func (s *SessionStorage) DeleteExpiredSessions(ctx context.Context) (int64, error) {
+	exec := getExecer(ctx, s.db.DB)
+	res, err := exec.ExecContext(ctx, `delete from sessions where expires_at <= now()`)
+	if err != nil {
+		s.log.Error("failed to delete expired sessions", slog.Any("error", err))
+		return 0, fmt.Errorf("delete expired sessions: %w", err)
+	}
+	return res.RowsAffected()
+}
@@ -0,0 +1,84 @@
+package storage
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"io"
+	"log/slog"
+	"regexp"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+
+	"github.com/cloudyy74/pr-reviewer-service/pkg/postgres"
+)
+
+func newSessionStorage(t *testing.T) (*SessionStorage, sqlmock.Sqlmock) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("create sqlmock: %v", err)
+	}
+	t.Cleanup(func() { db.Close() })
+
+	st, err := NewSessionStorage(&postgres.Postgres{DB: db}, slog.New(slog.NewTextHandler(io.Discard, nil)))
+	if err != nil {
+		t.Fatalf("NewSessionStorage: %v", err)
+	}
+	return st, mock
+}
+
+func TestSessionStorage_GetSession(t *testing.T) {
+	st, mock := newSessionStorage(t)
+	expires := time.Date(2025, 3, 1, 22, 0, 0, 0, time.UTC)
+	mock.ExpectQuery(regexp.QuoteMeta(`and s.expires_at > now()`)).
+		WithArgs("hash").
+		WillReturnRows(sqlmock.NewRows([]string{"id", "csrf_token", "expires_at", "user_id", "username", "team_name", "is_active"}).
+			AddRow("hash", "csrf", expires, "u1", "alice", "backend", true))
+
+	session, err := st.GetSession(context.Background(), "hash")
+	if err != nil {
+		t.Fatalf("GetSession returned err: %v", err)
+	}
+	if session.User.ID != "u1" || session.CSRFToken != "csrf" || !session.ExpiresAt.Equal(expires) {
+		t.Fatalf("unexpected session: %+v", session)
+	}
+	verifyExpectations(t, mock)
+}
+
+func TestSessionStorage_GetSession_NotFound(t *testing.T) {
+	st, mock := newSessionStorage(t)
+	mock.ExpectQuery(regexp.QuoteMeta(`from sessions s`)).
+		WithArgs("hash").
+		WillReturnError(sql.ErrNoRows)
+
+	if _, err := st.GetSession(context.Background(), "hash"); !errors.Is(err, ErrSessionNotFound) {
+		t.Fatalf("expected ErrSessionNotFound, got %v", err)
+	}
+	verifyExpectations(t, mock)
+}
+
+func TestSessionStorage_GetPasswordHash_NotFound(t *testing.T) {
+	st, mock := newSessionStorage(t)
+	mock.ExpectQuery(regexp.QuoteMeta(`select password_hash from user_credentials where user_id = $1`)).
+		WithArgs("u1").
+		WillReturnError(sql.ErrNoRows)
+
+	if _, err := st.GetPasswordHash(context.Background(), "u1"); !errors.Is(err, ErrCredentialsNotFound) {
+		t.Fatalf("expected ErrCredentialsNotFound, got %v", err)
+	}
+	verifyExpectations(t, mock)
+}
+
+func TestSessionStorage_DeleteExpiredSessions(t *testing.T) {
+	st, mock := newSessionStorage(t)
+	mock.ExpectExec(regexp.QuoteMeta(`delete from sessions where expires_at <= now()`)).
+		WillReturnResult(sqlmock.NewResult(0, 3))
+
+	n, err := st.DeleteExpiredSessions(context.Background())
+	if err != nil || n != 3 {
+		t.Fatalf("expected 3 deleted sessions, got %d, %v", n, err)
+	}
+	verifyExpectations(t, mock)
+}
@@ -0,0 +1,183 @@
+package storage
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"log/slog"
+
+	"github.com/cloudyy74/pr-reviewer-service/internal/models"
+	"github.com/cloudyy74/pr-reviewer-service/pkg/postgres"
+)
+
+var ErrSnapshotNotFound = errors.New("assignment snapshot not found")
+
+// SnapshotStorage copies pull_requests_reviewers and team_rotation_cursors
+// into assignment_snapshots as JSON and writes them back on restore. History
+// tables such as assignment_events are never rewritten.
+type SnapshotStorage struct {
+	prStatusColumn
+	db  *postgres.Postgres
+	log *slog.Logger
+}
+
+func NewSnapshotStorage(db *postgres.Postgres, log *slog.Logger) (*SnapshotStorage, error) {
+	if db == nil {
+		return nil, errors.New("database cannot be nil")
+	}
+	if log == nil {
+		return nil, errors.New("logger cannot be nil")
+	}
+	return &SnapshotStorage{
+		db:  db,
+		log: log,
+	}, nil
+}
+
+func (s *SnapshotStorage) CreateSnapshot(ctx context.Context, label, createdBy string) (*models.AssignmentSnapshot, error) {
+	exec := getQueryExecer(ctx, s.db.DB)
+	snapshot := models.AssignmentSnapshot{Label: label, CreatedBy: createdBy}
+	err := exec.QueryRowContext(
+		ctx,
+		`
+insert into assignment_snapshots (label, created_by, reviewers, rotation_cursors)
+select $1, $2,
+    (
+        select coalesce(jsonb_agg(jsonb_build_object(
+            'pull_request_id', r.pull_request_id,
+            'user_id', r.user_id,
+            'assigned_at', r.assigned_at,
+            'acknowledged_at', r.acknowledged_at
+        ) order by r.pull_request_id, r.user_id), '[]'::jsonb)
+        from pull_requests_reviewers r
+    ),
+    (
+        select coalesce(jsonb_agg(jsonb_build_object(
+            'team_name', c.team_name,
+            'last_user_id', c.last_user_id
+        ) order by c.team_name), '[]'::jsonb)
+        from team_rotation_cursors c
+    )
+returning id, jsonb_array_length(reviewers), jsonb_array_length(rotation_cursors), created_at`,
+		label,
+		createdBy,
+	).Scan(&snapshot.ID, &snapshot.Reviewers, &snapshot.RotationCursors, &snapshot.CreatedAt)
+	if err != nil {
+		s.log.Error("failed to create assignment snapshot", slog.Any("error", err))
+		return nil, fmt.Errorf("insert assignment snapshot: %w", err)
+	}
+	return &snapshot, nil
+}
+
+// RestoreSnapshot makes the reviewers of every pull request that existed
+// when the snapshot was taken and is still open match the snapshot, and
+// resets the rotation cursors of teams that still exist. Reviewers and pull
+// requests deleted since then are skipped. It must run in a transaction.
+func (s *SnapshotStorage) RestoreSnapshot(ctx context.Context, id int64) (*models.SnapshotRestore, error) {
+	exec := getQueryExecer(ctx, s.db.DB)
+	var found bool
+	err := exec.QueryRowContext(ctx, `select true from assignment_snapshots where id = $1 for update`, id).Scan(&found)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, ErrSnapshotNotFound
+		}
+		s.log.Error("failed to get assignment snapshot", slog.Any("error", err), slog.Int64("snapshot_id", id))
+		return nil, fmt.Errorf("get assignment snapshot: %w", err)
+	}
+
+	restore := &models.SnapshotRestore{}
+	restore.Removed, err = s.queryAssignments(
+		ctx,
+		`
+with snap as (
+    select r.pull_request_id, r.user_id
+    from assignment_snapshots s
+        cross join jsonb_to_recordset(s.reviewers) as r(pull_request_id varchar, user_id varchar)
+    where s.id = $1
+)
+delete from pull_requests_reviewers prr
+using pull_requests pr, assignment_snapshots s
+where s.id = $1
+  and pr.id = prr.pull_request_id
+  and `+s.statusColumn("pr")+` = 'OPEN'
+  and pr.created_at <= s.created_at
+  and not exists (
+      select 1 from snap
+      where snap.pull_request_id = prr.pull_request_id
+        and snap.user_id = prr.user_id
+  )
+returning prr.pull_request_id, prr.user_id, prr.assigned_at`,
+		id,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("remove reviewers: %w", err)
+	}
+
+	restore.Restored, err = s.queryAssignments(
+		ctx,
+		`
+insert into pull_requests_reviewers (pull_request_id, user_id, assigned_at, acknowledged_at)
+select r.pull_request_id, r.user_id, r.assigned_at, r.acknowledged_at
+from assignment_snapshots s
+    cross join jsonb_to_recordset(s.reviewers)
+        as r(pull_request_id varchar, user_id varchar, assigned_at timestamptz, acknowledged_at timestamptz)
+    join pull_requests pr on pr.id = r.pull_request_id
+    join users u on u.id = r.user_id
+where s.id = $1
+  and `+s.statusColumn("pr")+` = 'OPEN'
+  and pr.created_at <= s.created_at
+on conflict (pull_request_id, user_id) do nothing
+returning pull_request_id, user_id, assigned_at`,
+		id,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("restore reviewers: %w", err)
+	}
+
+	res, err := exec.ExecContext(
+		ctx,
+		`
+insert into team_rotation_cursors (team_name, last_user_id, updated_at)
+select c.team_name, c.last_user_id, now()
+from assignment_snapshots s
+    cross join jsonb_to_recordset(s.rotation_cursors) as c(team_name varchar, last_user_id varchar)
+    join teams t on t.name = c.team_name
+where s.id = $1
+on conflict (team_name) do update set
+last_user_id = excluded.last_user_id,
+updated_at = excluded.updated_at`,
+		id,
+	)
+	if err != nil {
+		s.log.Error("failed to restore rotation cursors", slog.Any("error", err), slog.Int64("snapshot_id", id))
+		return nil, fmt.Errorf("restore rotation cursors: %w", err)
+	}
+	cursors, err := res.RowsAffected()
+	if err != nil {
+		return nil, fmt.Errorf("rows affected: %w", err)
+	}
+	restore.RotationCursors = int(cursors)
+
+	return restore, nil
+}
+
+func (s *SnapshotStorage) queryAssignments(ctx context.Context, query string, id int64) ([]models.ReviewerAssignment, error) {
+	exec := getQueryExecer(ctx, s.db.DB)
+	rows, err := exec.QueryContext(ctx, query, id)
+	if err != nil {
+		s.log.Error("failed to restore assignment snapshot", slog.Any("error", err), slog.Int64("snapshot_id", id))
+		return nil, err
+	}
+	defer rows.Close()
+
+	assignments := make([]models.ReviewerAssignment, 0)
+	for rows.Next() {
+		var a models.ReviewerAssignment
+		if err := rows.Scan(&a.PullRequestID, &a.UserID, &a.AssignedAt); err != nil {
+			return nil, fmt.Errorf("scan assignment: %w", err)
+		}
+		assignments = append(assignments, a)
+	}
+	return assignments, rows.Err()
+}
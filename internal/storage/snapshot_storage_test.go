@@ -0,0 +1,87 @@
+package storage
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"io"
+	"log/slog"
+	"regexp"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+
+	"github.com/cloudyy74/pr-reviewer-service/pkg/postgres"
+)
+
+func newSnapshotStorage(t *testing.T) (*SnapshotStorage, sqlmock.Sqlmock) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("create sqlmock: %v", err)
+	}
+	t.Cleanup(func() { db.Close() })
+
+	st, err := NewSnapshotStorage(&postgres.Postgres{DB: db}, slog.New(slog.NewTextHandler(io.Discard, nil)))
+	if err != nil {
+		t.Fatalf("NewSnapshotStorage: %v", err)
+	}
+	return st, mock
+}
+
+func TestSnapshotStorage_CreateSnapshot(t *testing.T) {
+	st, mock := newSnapshotStorage(t)
+	createdAt := time.Date(2025, 3, 1, 10, 0, 0, 0, time.UTC)
+	mock.ExpectQuery(regexp.QuoteMeta(`insert into assignment_snapshots (label, created_by, reviewers, rotation_cursors)`)).
+		WithArgs("before import", "admin").
+		WillReturnRows(sqlmock.NewRows([]string{"id", "reviewers", "rotation_cursors", "created_at"}).AddRow(int64(3), 12, 2, createdAt))
+
+	snapshot, err := st.CreateSnapshot(context.Background(), "before import", "admin")
+	if err != nil {
+		t.Fatalf("CreateSnapshot returned err: %v", err)
+	}
+	if snapshot.ID != 3 || snapshot.Reviewers != 12 || snapshot.RotationCursors != 2 || !snapshot.CreatedAt.Equal(createdAt) {
+		t.Fatalf("unexpected snapshot: %+v", snapshot)
+	}
+	verifyExpectations(t, mock)
+}
+
+func TestSnapshotStorage_RestoreSnapshot(t *testing.T) {
+	st, mock := newSnapshotStorage(t)
+	assignedAt := time.Date(2025, 3, 1, 10, 0, 0, 0, time.UTC)
+	mock.ExpectQuery(regexp.QuoteMeta(`select true from assignment_snapshots where id = $1 for update`)).
+		WithArgs(int64(3)).
+		WillReturnRows(sqlmock.NewRows([]string{"bool"}).AddRow(true))
+	mock.ExpectQuery(regexp.QuoteMeta(`delete from pull_requests_reviewers prr`)).
+		WithArgs(int64(3)).
+		WillReturnRows(sqlmock.NewRows([]string{"pull_request_id", "user_id", "assigned_at"}).AddRow("pr-1", "u3", assignedAt))
+	mock.ExpectQuery(regexp.QuoteMeta(`on conflict (pull_request_id, user_id) do nothing`)).
+		WithArgs(int64(3)).
+		WillReturnRows(sqlmock.NewRows([]string{"pull_request_id", "user_id", "assigned_at"}).
+			AddRow("pr-1", "u2", assignedAt).
+			AddRow("pr-2", "u2", assignedAt))
+	mock.ExpectExec(regexp.QuoteMeta(`insert into team_rotation_cursors (team_name, last_user_id, updated_at)`)).
+		WithArgs(int64(3)).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+
+	restore, err := st.RestoreSnapshot(context.Background(), 3)
+	if err != nil {
+		t.Fatalf("RestoreSnapshot returned err: %v", err)
+	}
+	if len(restore.Removed) != 1 || restore.Removed[0].UserID != "u3" || len(restore.Restored) != 2 || restore.RotationCursors != 1 {
+		t.Fatalf("unexpected restore: %+v", restore)
+	}
+	verifyExpectations(t, mock)
+}
+
+func TestSnapshotStorage_RestoreSnapshot_NotFound(t *testing.T) {
+	st, mock := newSnapshotStorage(t)
+	mock.ExpectQuery(regexp.QuoteMeta(`select true from assignment_snapshots where id = $1 for update`)).
+		WithArgs(int64(9)).
+		WillReturnError(sql.ErrNoRows)
+
+	if _, err := st.RestoreSnapshot(context.Background(), 9); !errors.Is(err, ErrSnapshotNotFound) {
+		t.Fatalf("expected ErrSnapshotNotFound, got %v", err)
+	}
+	verifyExpectations(t, mock)
+}
@@ -0,0 +1,591 @@
+package storage
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"log/slog"
+	"time"
+
+	"github.com/cloudyy74/pr-reviewer-service/internal/models"
+	"github.com/cloudyy74/pr-reviewer-service/pkg/postgres"
+)
+
+type StatsStorage struct {
+	prStatusColumn
+	db  *postgres.Postgres
+	log *slog.Logger
+}
+
+func NewStatsStorage(db *postgres.Postgres, log *slog.Logger) (*StatsStorage, error) {
+	if db == nil {
+		return nil, errors.New("database cannot be nil")
+	}
+	if log == nil {
+		return nil, errors.New("logger cannot be nil")
+	}
+	return &StatsStorage{
+		db:  db,
+		log: log,
+	}, nil
+}
+
+func (s *StatsStorage) GetOpenPRCounts(ctx context.Context, minReviewers int) (int, int, error) {
+	exec := getQueryExecer(ctx, s.db.DB)
+	var open, needReviewers int
+	err := exec.QueryRowContext(
+		ctx,
+		`
+select count(*), count(*) filter (where coalesce(r.reviewers, 0) < $2)
+from pull_requests pr
+    left join (
+        select pull_request_id, count(*) as reviewers
+        from pull_requests_reviewers
+        group by pull_request_id
+    ) r on r.pull_request_id = pr.id
+where `+s.statusColumn("pr")+` = $1
+`,
+		models.StatusOpen,
+		minReviewers,
+	).Scan(&open, &needReviewers)
+	if err != nil {
+		s.log.Error("failed to count open prs", slog.Any("error", err))
+		return 0, 0, fmt.Errorf("count open prs: %w", err)
+	}
+	return open, needReviewers, nil
+}
+
+// GetOpenPRsByTeam counts open pull requests by the current team of their
+// author. Teams without open pull requests are left out.
+func (s *StatsStorage) GetOpenPRsByTeam(ctx context.Context) (map[string]int, error) {
+	exec := getQueryExecer(ctx, s.db.DB)
+	rows, err := exec.QueryContext(
+		ctx,
+		`
+select u.team_name, count(*)
+from pull_requests pr
+    join users u on u.id = pr.author_id
+where `+s.statusColumn("pr")+` = $1
+  and u.team_name is not null
+group by u.team_name
+`,
+		models.StatusOpen,
+	)
+	if err != nil {
+		s.log.Error("failed to count open prs by team", slog.Any("error", err))
+		return nil, fmt.Errorf("count open prs by team: %w", err)
+	}
+	defer rows.Close()
+
+	counts := make(map[string]int)
+	for rows.Next() {
+		var (
+			teamName string
+			count    int
+		)
+		if err := rows.Scan(&teamName, &count); err != nil {
+			return nil, fmt.Errorf("scan open prs by team: %w", err)
+		}
+		counts[teamName] = count
+	}
+	return counts, rows.Err()
+}
+
+func (s *StatsStorage) GetAvgAssignmentLatency(ctx context.Context, since time.Time) (float64, error) {
+	exec := getQueryExecer(ctx, s.db.DB)
+	var seconds float64
+	err := exec.QueryRowContext(
+		ctx,
+		`
+select coalesce(avg(extract(epoch from e.created_at - pr.created_at)), 0)::float8
+from assignment_events e
+    join pull_requests pr on pr.id = e.pull_request_id
+where e.event = $1
+  and e.created_at >= $2
+`,
+		models.EventAssigned,
+		since,
+	).Scan(&seconds)
+	if err != nil {
+		s.log.Error("failed to get assignment latency", slog.Any("error", err))
+		return 0, fmt.Errorf("get assignment latency: %w", err)
+	}
+	return seconds, nil
+}
+
+func (s *StatsStorage) GetBusiestReviewer(ctx context.Context) (*models.ReviewerLoad, error) {
+	exec := getQueryExecer(ctx, s.db.DB)
+	var load models.ReviewerLoad
+	err := exec.QueryRowContext(
+		ctx,
+		`
+select u.id, u.username, count(*) as open_assignments
+from pull_requests_reviewers r
+    join users u on u.id = r.user_id
+    join pull_requests pr on pr.id = r.pull_request_id
+where `+s.statusColumn("pr")+` = $1
+group by u.id, u.username
+order by open_assignments desc, u.id
+limit 1
+`,
+		models.StatusOpen,
+	).Scan(&load.UserID, &load.Username, &load.OpenAssignments)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, nil
+	}
+	if err != nil {
+		s.log.Error("failed to get busiest reviewer", slog.Any("error", err))
+		return nil, fmt.Errorf("get busiest reviewer: %w", err)
+	}
+	return &load, nil
+}
+
+func (s *StatsStorage) GetTeamsOverCapacity(ctx context.Context, perMember int) ([]*models.TeamLoad, error) {
+	exec := getQueryExecer(ctx, s.db.DB)
+	rows, err := exec.QueryContext(
+		ctx,
+		`
+select t.team_name, t.active_members, coalesce(a.open_assignments, 0) as open_assignments
+from (
+    select team_name, count(*) filter (where is_active) as active_members
+    from users
+    where team_name is not null
+    group by team_name
+) t
+    left join (
+        select u.team_name, count(*) as open_assignments
+        from pull_requests_reviewers r
+            join users u on u.id = r.user_id
+            join pull_requests pr on pr.id = r.pull_request_id
+        where `+s.statusColumn("pr")+` = $1
+        group by u.team_name
+    ) a on a.team_name = t.team_name
+where coalesce(a.open_assignments, 0) > t.active_members * $2
+order by open_assignments desc, t.team_name
+`,
+		models.StatusOpen,
+		perMember,
+	)
+	if err != nil {
+		s.log.Error("failed to get teams over capacity", slog.Any("error", err))
+		return nil, fmt.Errorf("get teams over capacity: %w", err)
+	}
+	defer rows.Close()
+
+	teams := make([]*models.TeamLoad, 0)
+	for rows.Next() {
+		var team models.TeamLoad
+		if err := rows.Scan(&team.TeamName, &team.ActiveMembers, &team.OpenAssignments); err != nil {
+			return nil, fmt.Errorf("scan team load: %w", err)
+		}
+		team.Capacity = team.ActiveMembers * perMember
+		teams = append(teams, &team)
+	}
+
+	return teams, nil
+}
+
+var timeseriesQueries = map[string]string{
+	models.MetricAssignments: `
+select coalesce(m.team_name, ''), date_trunc($1, e.created_at, 'UTC') as bucket, count(*)
+from assignment_events e
+    join users u on u.id = e.user_id` + memberTeamJoin("u.id", "$4") + `
+where e.event = 'ASSIGNED'
+  and e.created_at >= $2
+  and e.created_at < $3
+group by 1, 2
+order by 1, 2
+`,
+	models.MetricPRsCreated: `
+select coalesce(m.team_name, ''), date_trunc($1, pr.created_at, 'UTC') as bucket, count(*)
+from pull_requests pr
+    join users u on u.id = pr.author_id` + memberTeamJoin("u.id", "$4") + `
+where pr.created_at >= $2
+  and pr.created_at < $3
+group by 1, 2
+order by 1, 2
+`,
+	models.MetricPRsMerged: `
+select coalesce(m.team_name, ''), date_trunc($1, pr.merged_at, 'UTC') as bucket, count(*)
+from pull_requests pr
+    join users u on u.id = pr.author_id` + memberTeamJoin("u.id", "$4") + `
+where pr.merged_at >= $2
+  and pr.merged_at < $3
+group by 1, 2
+order by 1, 2
+`,
+}
+
+// memberTeamJoin joins m.team_name to a query over users u: the current team
+// when the timestamptz parameter asOf is null, otherwise the team the user
+// had joined by then according to team_membership_history. Moves record the
+// leave before the join at the same instant, so the latest row wins.
+func memberTeamJoin(userColumn, asOf string) string {
+	return fmt.Sprintf(`
+    left join lateral (
+        select case when %[2]s::timestamptz is null then u.team_name else (
+            select case when h.change = 'JOINED' then h.team_name end
+            from team_membership_history h
+            where h.user_id = %[1]s
+              and h.changed_at <= %[2]s::timestamptz
+            order by h.changed_at desc, h.id desc
+            limit 1
+        ) end as team_name
+    ) m on true`, userColumn, asOf)
+}
+
+func (s *StatsStorage) GetTimeseries(ctx context.Context, metric, interval string, from, to time.Time, asOf *time.Time) ([]*models.TeamBucketCount, error) {
+	query, ok := timeseriesQueries[metric]
+	if !ok {
+		return nil, fmt.Errorf("unknown metric %q", metric)
+	}
+	exec := getQueryExecer(ctx, s.db.DB)
+	rows, err := exec.QueryContext(ctx, query, interval, from, to, asOf)
+	if err != nil {
+		s.log.Error("failed to get timeseries", slog.Any("error", err), slog.String("metric", metric))
+		return nil, fmt.Errorf("get timeseries: %w", err)
+	}
+	defer rows.Close()
+
+	counts := make([]*models.TeamBucketCount, 0)
+	for rows.Next() {
+		var c models.TeamBucketCount
+		if err := rows.Scan(&c.TeamName, &c.BucketStart, &c.Count); err != nil {
+			return nil, fmt.Errorf("scan timeseries bucket: %w", err)
+		}
+		counts = append(counts, &c)
+	}
+
+	return counts, nil
+}
+
+func (s *StatsStorage) GetWeeklyAssignmentShares(ctx context.Context, from, to time.Time) ([]*models.WeeklyAssignmentShare, error) {
+	exec := getQueryExecer(ctx, s.db.DB)
+	rows, err := exec.QueryContext(
+		ctx,
+		`
+select team_name, user_id, week_start, user_assignments,
+    sum(user_assignments) over w :: int as team_assignments,
+    count(*) over w :: int as reviewers_count
+from (
+    select u.team_name, e.user_id, date_trunc('week', e.created_at, 'UTC') as week_start, count(*) as user_assignments
+    from assignment_events e
+        join users u on u.id = e.user_id
+    where e.event = $1
+      and e.created_at >= $2
+      and e.created_at < $3
+      and u.team_name is not null
+    group by 1, 2, 3
+) a
+window w as (partition by team_name, week_start)
+order by week_start, team_name, user_id
+`,
+		models.EventAssigned,
+		from,
+		to,
+	)
+	if err != nil {
+		s.log.Error("failed to get weekly assignment shares", slog.Any("error", err))
+		return nil, fmt.Errorf("get weekly assignment shares: %w", err)
+	}
+	defer rows.Close()
+
+	shares := make([]*models.WeeklyAssignmentShare, 0)
+	for rows.Next() {
+		var share models.WeeklyAssignmentShare
+		if err := rows.Scan(
+			&share.TeamName,
+			&share.UserID,
+			&share.WeekStart,
+			&share.UserAssignments,
+			&share.TeamAssignments,
+			&share.ReviewersCount,
+		); err != nil {
+			return nil, fmt.Errorf("scan weekly assignment share: %w", err)
+		}
+		shares = append(shares, &share)
+	}
+
+	return shares, nil
+}
+
+func (s *StatsStorage) SaveAnomaly(ctx context.Context, anomaly *models.AssignmentAnomaly) (bool, error) {
+	exec := getQueryExecer(ctx, s.db.DB)
+	var id int64
+	err := exec.QueryRowContext(
+		ctx,
+		`
+insert into assignment_anomalies
+    (team_name, user_id, week_start, user_assignments, team_assignments, reviewers_count, share, explanation)
+values ($1, $2, $3, $4, $5, $6, $7, $8)
+on conflict (team_name, user_id, week_start) do nothing
+returning id, detected_at
+`,
+		anomaly.TeamName,
+		anomaly.UserID,
+		anomaly.WeekStart,
+		anomaly.UserAssignments,
+		anomaly.TeamAssignments,
+		anomaly.ReviewersCount,
+		anomaly.Share,
+		anomaly.Explanation,
+	).Scan(&id, &anomaly.DetectedAt)
+	if errors.Is(err, sql.ErrNoRows) {
+		return false, nil
+	}
+	if err != nil {
+		s.log.Error("failed to save anomaly", slog.Any("error", err))
+		return false, fmt.Errorf("save anomaly: %w", err)
+	}
+	return true, nil
+}
+
+func (s *StatsStorage) GetAnomalies(ctx context.Context, since time.Time) ([]*models.AssignmentAnomaly, error) {
+	exec := getQueryExecer(ctx, s.db.DB)
+	rows, err := exec.QueryContext(
+		ctx,
+		`
+select team_name, user_id, week_start, user_assignments, team_assignments, reviewers_count, share, explanation, detected_at
+from assignment_anomalies
+where week_start >= $1
+order by week_start desc, team_name, user_id
+`,
+		since,
+	)
+	if err != nil {
+		s.log.Error("failed to get anomalies", slog.Any("error", err))
+		return nil, fmt.Errorf("get anomalies: %w", err)
+	}
+	defer rows.Close()
+
+	anomalies := make([]*models.AssignmentAnomaly, 0)
+	for rows.Next() {
+		var a models.AssignmentAnomaly
+		if err := rows.Scan(
+			&a.TeamName,
+			&a.UserID,
+			&a.WeekStart,
+			&a.UserAssignments,
+			&a.TeamAssignments,
+			&a.ReviewersCount,
+			&a.Share,
+			&a.Explanation,
+			&a.DetectedAt,
+		); err != nil {
+			return nil, fmt.Errorf("scan anomaly: %w", err)
+		}
+		anomalies = append(anomalies, &a)
+	}
+
+	return anomalies, nil
+}
+
+func (s *StatsStorage) GetAssignmentHeatmap(ctx context.Context, teamName string, from, to time.Time, asOf *time.Time) ([]*models.HeatmapCell, error) {
+	exec := getQueryExecer(ctx, s.db.DB)
+	rows, err := exec.QueryContext(
+		ctx,
+		`
+select m.team_name,
+    extract(isodow from e.created_at at time zone 'UTC')::int - 1 as weekday,
+    extract(hour from e.created_at at time zone 'UTC')::int as hour,
+    count(*)
+from assignment_events e
+    join users u on u.id = e.user_id`+memberTeamJoin("u.id", "$5")+`
+where e.event = $1
+  and e.created_at >= $2
+  and e.created_at < $3
+  and m.team_name is not null
+  and ($4 = '' or m.team_name = $4)
+group by 1, 2, 3
+order by 1, 2, 3
+`,
+		models.EventAssigned,
+		from,
+		to,
+		teamName,
+		asOf,
+	)
+	if err != nil {
+		s.log.Error("failed to get assignment heatmap", slog.Any("error", err))
+		return nil, fmt.Errorf("get assignment heatmap: %w", err)
+	}
+	defer rows.Close()
+
+	cells := make([]*models.HeatmapCell, 0)
+	for rows.Next() {
+		var cell models.HeatmapCell
+		if err := rows.Scan(&cell.TeamName, &cell.Weekday, &cell.Hour, &cell.Count); err != nil {
+			return nil, fmt.Errorf("scan heatmap cell: %w", err)
+		}
+		cells = append(cells, &cell)
+	}
+
+	return cells, nil
+}
+
+func (s *StatsStorage) GetWeeklyMergedCounts(ctx context.Context, from, to time.Time, asOf *time.Time) ([]*models.MergedCount, error) {
+	exec := getQueryExecer(ctx, s.db.DB)
+	rows, err := exec.QueryContext(
+		ctx,
+		`
+select coalesce(m.team_name, ''), u.id, u.username, date_trunc('week', pr.merged_at, 'UTC') as week_start, count(*)
+from pull_requests pr
+    join users u on u.id = pr.author_id`+memberTeamJoin("u.id", "$3")+`
+where pr.merged_at >= $1
+  and pr.merged_at < $2
+group by 1, 2, 3, 4
+order by 1, 2, 4
+`,
+		from,
+		to,
+		asOf,
+	)
+	if err != nil {
+		s.log.Error("failed to get merged counts", slog.Any("error", err))
+		return nil, fmt.Errorf("get merged counts: %w", err)
+	}
+	defer rows.Close()
+
+	counts := make([]*models.MergedCount, 0)
+	for rows.Next() {
+		var c models.MergedCount
+		if err := rows.Scan(&c.TeamName, &c.AuthorID, &c.Username, &c.WeekStart, &c.Count); err != nil {
+			return nil, fmt.Errorf("scan merged count: %w", err)
+		}
+		counts = append(counts, &c)
+	}
+
+	return counts, nil
+}
+
+func (s *StatsStorage) GetCompletionCounts(ctx context.Context, teamName string, from, to time.Time, asOf *time.Time) ([]*models.UserCompletion, error) {
+	exec := getQueryExecer(ctx, s.db.DB)
+	rows, err := exec.QueryContext(
+		ctx,
+		`
+select u.id, u.username, coalesce(m.team_name, ''),
+    count(*),
+    count(*) filter (where o.outcome = 'MERGED'),
+    count(*) filter (where o.outcome = 'REASSIGNED'),
+    count(*) filter (where o.outcome = 'DECLINED'),
+    count(*) filter (where o.outcome = 'CLOSED'),
+    count(*) filter (where o.outcome = 'REMOVED'),
+    count(*) filter (where o.outcome = 'PENDING')
+from (
+    select e.user_id,
+        case
+            when x.event = 'DECLINED' then 'DECLINED'
+            when x.event = 'REMOVED' then 'REMOVED'
+            when x.event is not null then 'REASSIGNED'
+            when `+s.statusColumn("pr")+` = 'MERGED' then 'MERGED'
+            when `+s.statusColumn("pr")+` = 'CLOSED' then 'CLOSED'
+            else 'PENDING'
+        end as outcome
+    from assignment_events e
+        join pull_requests pr on pr.id = e.pull_request_id
+        left join lateral (
+            select n.event
+            from assignment_events n
+            where n.pull_request_id = e.pull_request_id
+              and n.user_id = e.user_id
+              and n.id > e.id
+              and n.event not in ('ASSIGNED', 'ACKNOWLEDGED', 'APPROVED', 'CHANGES_REQUESTED')
+            order by n.id
+            limit 1
+        ) x on true
+    where e.event = 'ASSIGNED'
+      and e.created_at >= $1
+      and e.created_at < $2
+) o
+    join users u on u.id = o.user_id`+memberTeamJoin("u.id", "$4")+`
+where ($3 = '' or m.team_name = $3)
+group by u.id, u.username, m.team_name
+order by u.id
+`,
+		from,
+		to,
+		teamName,
+		asOf,
+	)
+	if err != nil {
+		s.log.Error("failed to get completion counts", slog.Any("error", err))
+		return nil, fmt.Errorf("get completion counts: %w", err)
+	}
+	defer rows.Close()
+
+	users := make([]*models.UserCompletion, 0)
+	for rows.Next() {
+		var u models.UserCompletion
+		if err := rows.Scan(
+			&u.UserID,
+			&u.Username,
+			&u.TeamName,
+			&u.Assigned,
+			&u.Merged,
+			&u.Reassigned,
+			&u.Declined,
+			&u.Closed,
+			&u.Removed,
+			&u.Pending,
+		); err != nil {
+			return nil, fmt.Errorf("scan completion counts: %w", err)
+		}
+		users = append(users, &u)
+	}
+
+	return users, nil
+}
+
+// GetLabelStats counts labeled pull requests by label: open ones, ones created
+// in [from, to) and ones merged in [from, to) with their average time to
+// merge. teamName filters by the current team of the author. Labels with
+// nothing to count are left out.
+func (s *StatsStorage) GetLabelStats(ctx context.Context, teamName string, from, to time.Time) ([]*models.LabelStats, error) {
+	exec := getQueryExecer(ctx, s.db.DB)
+	rows, err := exec.QueryContext(
+		ctx,
+		`
+select l.label,
+    count(*) filter (where `+s.statusColumn("pr")+` = $4),
+    count(*) filter (where pr.created_at >= $1 and pr.created_at < $2),
+    count(*) filter (where pr.merged_at >= $1 and pr.merged_at < $2),
+    avg(extract(epoch from pr.merged_at - pr.created_at) / 3600) filter (where pr.merged_at >= $1 and pr.merged_at < $2)
+from pr_labels l
+    join pull_requests pr on pr.id = l.pull_request_id
+    join users a on a.id = pr.author_id
+where ($3 = '' or lower(a.team_name) = lower($3))
+  and (`+s.statusColumn("pr")+` = $4
+    or (pr.created_at >= $1 and pr.created_at < $2)
+    or (pr.merged_at >= $1 and pr.merged_at < $2))
+group by l.label
+order by l.label
+`,
+		from,
+		to,
+		teamName,
+		models.StatusOpen,
+	)
+	if err != nil {
+		s.log.Error("failed to get label stats", slog.Any("error", err))
+		return nil, fmt.Errorf("get label stats: %w", err)
+	}
+	defer rows.Close()
+
+	labels := make([]*models.LabelStats, 0)
+	for rows.Next() {
+		var l models.LabelStats
+		var avg sql.NullFloat64
+		if err := rows.Scan(&l.Label, &l.Open, &l.Created, &l.Merged, &avg); err != nil {
+			return nil, fmt.Errorf("scan label stats: %w", err)
+		}
+		if avg.Valid {
+			l.AvgMergeHours = &avg.Float64
+		}
+		labels = append(labels, &l)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("get label stats: %w", err)
+	}
+	return labels, nil
+}
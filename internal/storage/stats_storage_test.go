@@ -0,0 +1,304 @@
+package storage
+
+import (
+	"context"
+	"database/sql"
+	"io"
+	"log/slog"
+	"regexp"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+
+	"github.com/cloudyy74/pr-reviewer-service/internal/models"
+	"github.com/cloudyy74/pr-reviewer-service/pkg/postgres"
+)
+
+func newStatsStorage(t *testing.T) (*StatsStorage, sqlmock.Sqlmock) {
+	t.Helper()
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("sqlmock.New: %v", err)
+	}
+	t.Cleanup(func() { db.Close() })
+
+	log := slog.New(slog.NewTextHandler(io.Discard, nil))
+	st, err := NewStatsStorage(&postgres.Postgres{DB: db}, log)
+	if err != nil {
+		t.Fatalf("NewStatsStorage: %v", err)
+	}
+	return st, mock
+}
+
+func TestStatsStorage_GetOpenPRCounts(t *testing.T) {
+	st, mock := newStatsStorage(t)
+	mock.ExpectQuery(regexp.QuoteMeta(`select count(*), count(*) filter (where coalesce(r.reviewers, 0) < $2)`)).
+		WithArgs(models.StatusOpen, 2).
+		WillReturnRows(sqlmock.NewRows([]string{"open", "need"}).AddRow(7, 2))
+
+	open, need, err := st.GetOpenPRCounts(context.Background(), 2)
+	if err != nil {
+		t.Fatalf("GetOpenPRCounts returned err: %v", err)
+	}
+	if open != 7 || need != 2 {
+		t.Fatalf("unexpected counts: open=%d need=%d", open, need)
+	}
+	verifyExpectations(t, mock)
+}
+
+func TestStatsStorage_GetOpenPRsByTeam(t *testing.T) {
+	st, mock := newStatsStorage(t)
+	mock.ExpectQuery(regexp.QuoteMeta(`select u.team_name, count(*)`)).
+		WithArgs(models.StatusOpen).
+		WillReturnRows(sqlmock.NewRows([]string{"team_name", "count"}).AddRow("backend", 4).AddRow("mobile", 1))
+
+	counts, err := st.GetOpenPRsByTeam(context.Background())
+	if err != nil {
+		t.Fatalf("GetOpenPRsByTeam returned err: %v", err)
+	}
+	if len(counts) != 2 || counts["backend"] != 4 || counts["mobile"] != 1 {
+		t.Fatalf("unexpected counts: %v", counts)
+	}
+	verifyExpectations(t, mock)
+}
+
+func TestStatsStorage_GetAvgAssignmentLatency(t *testing.T) {
+	st, mock := newStatsStorage(t)
+	since := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	mock.ExpectQuery(regexp.QuoteMeta(`select coalesce(avg(extract(epoch from e.created_at - pr.created_at)), 0)::float8`)).
+		WithArgs(models.EventAssigned, since).
+		WillReturnRows(sqlmock.NewRows([]string{"avg"}).AddRow(12.5))
+
+	seconds, err := st.GetAvgAssignmentLatency(context.Background(), since)
+	if err != nil {
+		t.Fatalf("GetAvgAssignmentLatency returned err: %v", err)
+	}
+	if seconds != 12.5 {
+		t.Fatalf("unexpected latency: %v", seconds)
+	}
+	verifyExpectations(t, mock)
+}
+
+func TestStatsStorage_GetBusiestReviewer_NoAssignments(t *testing.T) {
+	st, mock := newStatsStorage(t)
+	mock.ExpectQuery(regexp.QuoteMeta(`order by open_assignments desc, u.id`)).
+		WithArgs(models.StatusOpen).
+		WillReturnError(sql.ErrNoRows)
+
+	load, err := st.GetBusiestReviewer(context.Background())
+	if err != nil {
+		t.Fatalf("GetBusiestReviewer returned err: %v", err)
+	}
+	if load != nil {
+		t.Fatalf("expected nil load, got %#v", load)
+	}
+	verifyExpectations(t, mock)
+}
+
+func TestStatsStorage_GetTeamsOverCapacity(t *testing.T) {
+	st, mock := newStatsStorage(t)
+	mock.ExpectQuery(regexp.QuoteMeta(`where coalesce(a.open_assignments, 0) > t.active_members * $2`)).
+		WithArgs(models.StatusOpen, 3).
+		WillReturnRows(sqlmock.NewRows([]string{"team_name", "active_members", "open_assignments"}).
+			AddRow("backend", 2, 9))
+
+	teams, err := st.GetTeamsOverCapacity(context.Background(), 3)
+	if err != nil {
+		t.Fatalf("GetTeamsOverCapacity returned err: %v", err)
+	}
+	if len(teams) != 1 || teams[0].Capacity != 6 || teams[0].OpenAssignments != 9 {
+		t.Fatalf("unexpected teams: %#v", teams)
+	}
+	verifyExpectations(t, mock)
+}
+
+func TestStatsStorage_GetTimeseries(t *testing.T) {
+	st, mock := newStatsStorage(t)
+	from := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	to := from.Add(48 * time.Hour)
+	mock.ExpectQuery(regexp.QuoteMeta(`date_trunc($1, e.created_at, 'UTC') as bucket`)).
+		WithArgs(models.IntervalDay, from, to, nil).
+		WillReturnRows(sqlmock.NewRows([]string{"team_name", "bucket", "count"}).
+			AddRow("backend", from, 3).
+			AddRow("backend", from.Add(24*time.Hour), 1))
+
+	counts, err := st.GetTimeseries(context.Background(), models.MetricAssignments, models.IntervalDay, from, to, nil)
+	if err != nil {
+		t.Fatalf("GetTimeseries returned err: %v", err)
+	}
+	if len(counts) != 2 || counts[0].Count != 3 || counts[1].TeamName != "backend" {
+		t.Fatalf("unexpected counts: %#v", counts)
+	}
+	verifyExpectations(t, mock)
+}
+
+func TestStatsStorage_GetTimeseries_UnknownMetric(t *testing.T) {
+	st, _ := newStatsStorage(t)
+
+	if _, err := st.GetTimeseries(context.Background(), "nope", models.IntervalDay, time.Now(), time.Now(), nil); err == nil {
+		t.Fatalf("expected error for unknown metric")
+	}
+}
+
+func TestMemberTeamJoin_UsesMembershipHistory(t *testing.T) {
+	join := memberTeamJoin("u.id", "$4")
+	for _, want := range []string{
+		"when $4::timestamptz is null then u.team_name",
+		"from team_membership_history h",
+		"h.changed_at <= $4::timestamptz",
+		"order by h.changed_at desc, h.id desc",
+	} {
+		if !strings.Contains(join, want) {
+			t.Fatalf("expected %q in join:\n%s", want, join)
+		}
+	}
+}
+
+func TestStatsStorage_GetWeeklyAssignmentShares(t *testing.T) {
+	st, mock := newStatsStorage(t)
+	from := time.Date(2025, 3, 3, 0, 0, 0, 0, time.UTC)
+	to := from.AddDate(0, 0, 7)
+	mock.ExpectQuery(regexp.QuoteMeta(`window w as (partition by team_name, week_start)`)).
+		WithArgs(models.EventAssigned, from, to).
+		WillReturnRows(sqlmock.NewRows([]string{"team_name", "user_id", "week_start", "user_assignments", "team_assignments", "reviewers_count"}).
+			AddRow("backend", "u1", from, 8, 10, 2))
+
+	shares, err := st.GetWeeklyAssignmentShares(context.Background(), from, to)
+	if err != nil {
+		t.Fatalf("GetWeeklyAssignmentShares returned err: %v", err)
+	}
+	if len(shares) != 1 || shares[0].UserAssignments != 8 || shares[0].TeamAssignments != 10 || shares[0].ReviewersCount != 2 {
+		t.Fatalf("unexpected shares: %#v", shares)
+	}
+	verifyExpectations(t, mock)
+}
+
+func TestStatsStorage_SaveAnomaly_AlreadyRecorded(t *testing.T) {
+	st, mock := newStatsStorage(t)
+	week := time.Date(2025, 3, 3, 0, 0, 0, 0, time.UTC)
+	anomaly := &models.AssignmentAnomaly{
+		TeamName:        "backend",
+		UserID:          "u1",
+		WeekStart:       week,
+		UserAssignments: 8,
+		TeamAssignments: 10,
+		ReviewersCount:  2,
+		Share:           0.8,
+		Explanation:     "explanation",
+	}
+	mock.ExpectQuery(regexp.QuoteMeta(`on conflict (team_name, user_id, week_start) do nothing`)).
+		WithArgs("backend", "u1", week, 8, 10, 2, 0.8, "explanation").
+		WillReturnError(sql.ErrNoRows)
+
+	created, err := st.SaveAnomaly(context.Background(), anomaly)
+	if err != nil {
+		t.Fatalf("SaveAnomaly returned err: %v", err)
+	}
+	if created {
+		t.Fatalf("expected existing anomaly not to be created")
+	}
+	verifyExpectations(t, mock)
+}
+
+func TestStatsStorage_GetAnomalies(t *testing.T) {
+	st, mock := newStatsStorage(t)
+	since := time.Date(2025, 2, 1, 0, 0, 0, 0, time.UTC)
+	week := time.Date(2025, 3, 3, 0, 0, 0, 0, time.UTC)
+	mock.ExpectQuery(regexp.QuoteMeta(`from assignment_anomalies`)).
+		WithArgs(since).
+		WillReturnRows(sqlmock.NewRows([]string{"team_name", "user_id", "week_start", "user_assignments", "team_assignments", "reviewers_count", "share", "explanation", "detected_at"}).
+			AddRow("backend", "u1", week, 8, 10, 2, 0.8, "explanation", week.AddDate(0, 0, 7)))
+
+	anomalies, err := st.GetAnomalies(context.Background(), since)
+	if err != nil {
+		t.Fatalf("GetAnomalies returned err: %v", err)
+	}
+	if len(anomalies) != 1 || anomalies[0].Share != 0.8 || anomalies[0].Explanation != "explanation" {
+		t.Fatalf("unexpected anomalies: %#v", anomalies)
+	}
+	verifyExpectations(t, mock)
+}
+
+func TestStatsStorage_GetAssignmentHeatmap(t *testing.T) {
+	st, mock := newStatsStorage(t)
+	from := time.Date(2025, 3, 3, 0, 0, 0, 0, time.UTC)
+	to := from.AddDate(0, 0, 28)
+	mock.ExpectQuery(regexp.QuoteMeta(`extract(isodow from e.created_at at time zone 'UTC')::int - 1 as weekday`)).
+		WithArgs(models.EventAssigned, from, to, "backend", nil).
+		WillReturnRows(sqlmock.NewRows([]string{"team_name", "weekday", "hour", "count"}).
+			AddRow("backend", 0, 9, 4).
+			AddRow("backend", 4, 17, 1))
+
+	cells, err := st.GetAssignmentHeatmap(context.Background(), "backend", from, to, nil)
+	if err != nil {
+		t.Fatalf("GetAssignmentHeatmap returned err: %v", err)
+	}
+	if len(cells) != 2 || cells[0].Hour != 9 || cells[1].Weekday != 4 {
+		t.Fatalf("unexpected cells: %#v", cells)
+	}
+	verifyExpectations(t, mock)
+}
+
+func TestStatsStorage_GetWeeklyMergedCounts(t *testing.T) {
+	st, mock := newStatsStorage(t)
+	from := time.Date(2025, 2, 3, 0, 0, 0, 0, time.UTC)
+	to := from.AddDate(0, 0, 56)
+	mock.ExpectQuery(regexp.QuoteMeta(`date_trunc('week', pr.merged_at, 'UTC') as week_start`)).
+		WithArgs(from, to, nil).
+		WillReturnRows(sqlmock.NewRows([]string{"team_name", "id", "username", "week_start", "count"}).
+			AddRow("backend", "u1", "Alice", from, 3))
+
+	counts, err := st.GetWeeklyMergedCounts(context.Background(), from, to, nil)
+	if err != nil {
+		t.Fatalf("GetWeeklyMergedCounts returned err: %v", err)
+	}
+	if len(counts) != 1 || counts[0].Username != "Alice" || counts[0].Count != 3 {
+		t.Fatalf("unexpected counts: %#v", counts)
+	}
+	verifyExpectations(t, mock)
+}
+
+func TestStatsStorage_GetCompletionCounts(t *testing.T) {
+	st, mock := newStatsStorage(t)
+	from := time.Date(2025, 3, 1, 0, 0, 0, 0, time.UTC)
+	to := from.AddDate(0, 0, 30)
+	asOf := from.AddDate(0, -1, 0)
+	// A reviewer removed without a replacement is not a reassignment, and
+	// neither is a review verdict.
+	mock.ExpectQuery(regexp.QuoteMeta(`when x.event = 'REMOVED' then 'REMOVED'
+            when x.event is not null then 'REASSIGNED'`)+`(?s).*`+regexp.QuoteMeta(verdictsExcluded)).
+		WithArgs(from, to, "", asOf).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "username", "team_name", "assigned", "merged", "reassigned", "declined", "closed", "removed", "pending"}).
+			AddRow("u1", "Alice", "backend", 11, 5, 2, 1, 1, 1, 1))
+
+	users, err := st.GetCompletionCounts(context.Background(), "", from, to, &asOf)
+	if err != nil {
+		t.Fatalf("GetCompletionCounts returned err: %v", err)
+	}
+	if len(users) != 1 || users[0].Merged != 5 || users[0].Declined != 1 || users[0].Closed != 1 || users[0].Removed != 1 || users[0].Pending != 1 {
+		t.Fatalf("unexpected users: %#v", users)
+	}
+	verifyExpectations(t, mock)
+}
+
+func TestStatsStorage_GetLabelStats(t *testing.T) {
+	st, mock := newStatsStorage(t)
+	from := time.Date(2025, 3, 1, 0, 0, 0, 0, time.UTC)
+	to := from.AddDate(0, 0, 30)
+	mock.ExpectQuery(regexp.QuoteMeta(`from pr_labels l`)).
+		WithArgs(from, to, "backend", models.StatusOpen).
+		WillReturnRows(sqlmock.NewRows([]string{"label", "open", "created", "merged", "avg_merge_hours"}).
+			AddRow("bug", 2, 3, 1, 12.5).
+			AddRow("docs", 1, 0, 0, nil))
+
+	labels, err := st.GetLabelStats(context.Background(), "backend", from, to)
+	if err != nil {
+		t.Fatalf("GetLabelStats returned err: %v", err)
+	}
+	if len(labels) != 2 || labels[0].AvgMergeHours == nil || *labels[0].AvgMergeHours != 12.5 || labels[1].AvgMergeHours != nil || labels[1].Open != 1 {
+		t.Fatalf("unexpected labels: %#v", labels)
+	}
+	verifyExpectations(t, mock)
+}
@@ -2,18 +2,22 @@ package storage
 
 import (
 	"context"
+	"database/sql"
 	"errors"
 	"fmt"
 	"log/slog"
 
+	"github.com/cloudyy74/pr-reviewer-service/internal/models"
 	"github.com/cloudyy74/pr-reviewer-service/pkg/postgres"
 )
 
 var (
-	ErrTeamExists = errors.New("team already exists")
+	ErrTeamExists   = errors.New("team already exists")
+	ErrTeamNotFound = errors.New("team not found")
 )
 
 type TeamStorage struct {
+	prStatusColumn
 	db  *postgres.Postgres
 	log *slog.Logger
 }
@@ -35,7 +39,7 @@ func (s *TeamStorage) CreateTeam(ctx context.Context, teamName string) error {
 	exec := getExecer(ctx, s.db.DB)
 	res, err := exec.ExecContext(
 		ctx,
-		"insert into teams (name) values ($1) on conflict (name) do nothing",
+		"insert into teams (name) values ($1) on conflict do nothing",
 		teamName,
 	)
 	if err != nil {
@@ -73,3 +77,245 @@ func (s *TeamStorage) ExistsTeam(ctx context.Context, name string) (bool, error)
 
 	return exists, nil
 }
+
+func (s *TeamStorage) GetTeamSettings(ctx context.Context, teamName string) (*models.TeamSettings, error) {
+	exec := getQueryExecer(ctx, s.db.DB)
+	var settings models.TeamSettings
+	err := exec.QueryRowContext(
+		ctx,
+		`
+select t.name, coalesce(ts.strict_duplicate_check, false), coalesce(ts.ack_timeout_hours, 0), coalesce(ts.review_sla_hours, 0)
+from teams t
+    left join team_settings ts on ts.team_name = t.name
+where t.name = $1
+`,
+		teamName,
+	).Scan(&settings.TeamName, &settings.StrictDuplicateCheck, &settings.AckTimeoutHours, &settings.ReviewSLAHours)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, fmt.Errorf("get team settings: %w", ErrTeamNotFound)
+	}
+	if err != nil {
+		s.log.Error("failed to get team settings", slog.Any("error", err), slog.String("team", teamName))
+		return nil, fmt.Errorf("get team settings: %w", err)
+	}
+
+	rows, err := exec.QueryContext(
+		ctx,
+		`select buddy_team from team_fallbacks where team_name = $1 order by position`,
+		teamName,
+	)
+	if err != nil {
+		s.log.Error("failed to get team fallbacks", slog.Any("error", err), slog.String("team", teamName))
+		return nil, fmt.Errorf("get team fallbacks: %w", err)
+	}
+	defer rows.Close()
+	settings.FallbackTeams = make([]string, 0)
+	for rows.Next() {
+		var buddy string
+		if err := rows.Scan(&buddy); err != nil {
+			return nil, fmt.Errorf("scan team fallback: %w", err)
+		}
+		settings.FallbackTeams = append(settings.FallbackTeams, buddy)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("iterate team fallbacks: %w", err)
+	}
+
+	ruleRows, err := exec.QueryContext(
+		ctx,
+		`select component, path_prefix, user_id from team_required_reviewers where team_name = $1 order by position`,
+		teamName,
+	)
+	if err != nil {
+		s.log.Error("failed to get required reviewers", slog.Any("error", err), slog.String("team", teamName))
+		return nil, fmt.Errorf("get required reviewers: %w", err)
+	}
+	defer ruleRows.Close()
+	settings.RequiredReviewers = make([]models.RequiredReviewerRule, 0)
+	for ruleRows.Next() {
+		var rule models.RequiredReviewerRule
+		if err := ruleRows.Scan(&rule.Component, &rule.PathPrefix, &rule.UserID); err != nil {
+			return nil, fmt.Errorf("scan required reviewer: %w", err)
+		}
+		settings.RequiredReviewers = append(settings.RequiredReviewers, rule)
+	}
+	if err := ruleRows.Err(); err != nil {
+		return nil, fmt.Errorf("iterate required reviewers: %w", err)
+	}
+	return &settings, nil
+}
+
+func (s *TeamStorage) UpsertTeamSettings(ctx context.Context, settings models.TeamSettings) error {
+	exec := getExecer(ctx, s.db.DB)
+	_, err := exec.ExecContext(
+		ctx,
+		`
+insert into team_settings (team_name, strict_duplicate_check, ack_timeout_hours, review_sla_hours) values ($1, $2, $3, $4)
+on conflict (team_name) do update set
+strict_duplicate_check = excluded.strict_duplicate_check,
+ack_timeout_hours = excluded.ack_timeout_hours,
+review_sla_hours = excluded.review_sla_hours`,
+		settings.TeamName,
+		settings.StrictDuplicateCheck,
+		settings.AckTimeoutHours,
+		settings.ReviewSLAHours,
+	)
+	if err != nil {
+		s.log.Error("failed to upsert team settings", slog.Any("error", err), slog.String("team", settings.TeamName))
+		return fmt.Errorf("upsert team settings: %w", err)
+	}
+
+	if _, err := exec.ExecContext(ctx, `delete from team_fallbacks where team_name = $1`, settings.TeamName); err != nil {
+		return fmt.Errorf("clear team fallbacks: %w", err)
+	}
+	for i, buddy := range settings.FallbackTeams {
+		if _, err := exec.ExecContext(
+			ctx,
+			`insert into team_fallbacks (team_name, buddy_team, position) values ($1, $2, $3)`,
+			settings.TeamName,
+			buddy,
+			i,
+		); err != nil {
+			if postgres.IsForeignKeyViolation(err) {
+				return fmt.Errorf("fallback team %s: %w", buddy, ErrTeamNotFound)
+			}
+			s.log.Error("failed to add team fallback", slog.Any("error", err), slog.String("team", settings.TeamName))
+			return fmt.Errorf("add team fallback %s: %w", buddy, err)
+		}
+	}
+
+	if _, err := exec.ExecContext(ctx, `delete from team_required_reviewers where team_name = $1`, settings.TeamName); err != nil {
+		return fmt.Errorf("clear required reviewers: %w", err)
+	}
+	for i, rule := range settings.RequiredReviewers {
+		if _, err := exec.ExecContext(
+			ctx,
+			`insert into team_required_reviewers (team_name, position, component, path_prefix, user_id) values ($1, $2, $3, $4, $5)`,
+			settings.TeamName,
+			i,
+			rule.Component,
+			rule.PathPrefix,
+			rule.UserID,
+		); err != nil {
+			if postgres.IsForeignKeyViolation(err) {
+				return fmt.Errorf("required reviewer %s: %w", rule.UserID, ErrUserNotFound)
+			}
+			s.log.Error("failed to add required reviewer", slog.Any("error", err), slog.String("team", settings.TeamName))
+			return fmt.Errorf("add required reviewer %s: %w", rule.UserID, err)
+		}
+	}
+	return nil
+}
+
+func (s *TeamStorage) CountOpenPRs(ctx context.Context, teamName string) (int, error) {
+	exec := getQueryExecer(ctx, s.db.DB)
+	var count int
+	err := exec.QueryRowContext(
+		ctx,
+		`
+select count(*)
+from pull_requests pr
+    join users u on u.id = pr.author_id
+where u.team_name = $1
+  and `+s.statusColumn("pr")+` = $2
+`,
+		teamName,
+		models.StatusOpen,
+	).Scan(&count)
+	if err != nil {
+		s.log.Error("failed to count team open prs", slog.Any("error", err), slog.String("team", teamName))
+		return 0, fmt.Errorf("count team open prs: %w", err)
+	}
+	return count, nil
+}
+
+func (s *TeamStorage) GetTeamStats(ctx context.Context, teamName string) (*models.TeamStats, error) {
+	exec := getQueryExecer(ctx, s.db.DB)
+	var stats models.TeamStats
+	err := exec.QueryRowContext(
+		ctx,
+		`
+select
+    (select count(*) from users where team_name = $1),
+    (select count(*) from users where team_name = $1 and is_active),
+    (select count(*)
+     from pull_requests pr
+         join users u on u.id = pr.author_id
+     where u.team_name = $1
+       and `+s.statusColumn("pr")+` = $2),
+    (select count(*)
+     from pull_requests_reviewers r
+         join users u on u.id = r.user_id
+         join pull_requests pr on pr.id = r.pull_request_id
+     where u.team_name = $1
+       and `+s.statusColumn("pr")+` = $2)
+`,
+		teamName,
+		models.StatusOpen,
+	).Scan(&stats.MembersCount, &stats.ActiveMembers, &stats.OpenPRsAuthored, &stats.OpenAssignments)
+	if err != nil {
+		s.log.Error("failed to get team stats", slog.Any("error", err), slog.String("team", teamName))
+		return nil, fmt.Errorf("get team stats: %w", err)
+	}
+	return &stats, nil
+}
+
+// GetMembershipHistory pages through a team's joins and leaves, newest first.
+func (s *TeamStorage) GetMembershipHistory(ctx context.Context, q models.MembershipHistoryQuery) ([]*models.MembershipChange, int, error) {
+	exec := getQueryExecer(ctx, s.db.DB)
+
+	var total int
+	err := exec.QueryRowContext(
+		ctx,
+		`
+select count(*)
+from team_membership_history
+where team_name = $1
+  and ($2::timestamptz is null or changed_at >= $2)
+  and ($3::timestamptz is null or changed_at < $3)
+`,
+		q.TeamName,
+		q.From,
+		q.To,
+	).Scan(&total)
+	if err != nil {
+		s.log.Error("failed to count membership history", slog.Any("error", err), slog.String("team", q.TeamName))
+		return nil, 0, fmt.Errorf("count membership history: %w", err)
+	}
+
+	rows, err := exec.QueryContext(
+		ctx,
+		`
+select id, team_name, user_id, change, coalesce(other_team, ''), changed_at
+from team_membership_history
+where team_name = $1
+  and ($2::timestamptz is null or changed_at >= $2)
+  and ($3::timestamptz is null or changed_at < $3)
+order by changed_at desc, id desc
+limit $4 offset $5
+`,
+		q.TeamName,
+		q.From,
+		q.To,
+		q.Limit,
+		q.Offset,
+	)
+	if err != nil {
+		s.log.Error("failed to get membership history", slog.Any("error", err), slog.String("team", q.TeamName))
+		return nil, 0, fmt.Errorf("get membership history: %w", err)
+	}
+	defer rows.Close()
+
+	changes := make([]*models.MembershipChange, 0)
+	for rows.Next() {
+		var c models.MembershipChange
+		if err := rows.Scan(&c.ID, &c.TeamName, &c.UserID, &c.Change, &c.OtherTeam, &c.ChangedAt); err != nil {
+			return nil, 0, fmt.Errorf("scan membership change: %w", err)
+		}
+		changes = append(changes, &c)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, 0, fmt.Errorf("membership history rows: %w", err)
+	}
+	return changes, total, nil
+}
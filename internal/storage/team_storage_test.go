@@ -2,14 +2,17 @@ package storage
 
 import (
 	"context"
+	"database/sql"
 	"errors"
 	"io"
 	"log/slog"
 	"regexp"
 	"testing"
+	"time"
 
 	"github.com/DATA-DOG/go-sqlmock"
 
+	"github.com/cloudyy74/pr-reviewer-service/internal/models"
 	"github.com/cloudyy74/pr-reviewer-service/pkg/postgres"
 )
 
@@ -32,7 +35,7 @@ func newTeamStorage(t *testing.T) (*TeamStorage, sqlmock.Sqlmock) {
 
 func TestTeamStorage_CreateTeam_Success(t *testing.T) {
 	st, mock := newTeamStorage(t)
-	mock.ExpectExec(regexp.QuoteMeta("insert into teams (name) values ($1) on conflict (name) do nothing")).
+	mock.ExpectExec(regexp.QuoteMeta("insert into teams (name) values ($1) on conflict do nothing")).
 		WithArgs("backend").
 		WillReturnResult(sqlmock.NewResult(0, 1))
 
@@ -44,7 +47,7 @@ func TestTeamStorage_CreateTeam_Success(t *testing.T) {
 
 func TestTeamStorage_CreateTeam_AlreadyExists(t *testing.T) {
 	st, mock := newTeamStorage(t)
-	mock.ExpectExec(regexp.QuoteMeta("insert into teams (name) values ($1) on conflict (name) do nothing")).
+	mock.ExpectExec(regexp.QuoteMeta("insert into teams (name) values ($1) on conflict do nothing")).
 		WithArgs("backend").
 		WillReturnResult(sqlmock.NewResult(0, 0))
 
@@ -57,7 +60,7 @@ func TestTeamStorage_CreateTeam_AlreadyExists(t *testing.T) {
 
 func TestTeamStorage_CreateTeam_DBError(t *testing.T) {
 	st, mock := newTeamStorage(t)
-	mock.ExpectExec(regexp.QuoteMeta("insert into teams (name) values ($1) on conflict (name) do nothing")).
+	mock.ExpectExec(regexp.QuoteMeta("insert into teams (name) values ($1) on conflict do nothing")).
 		WithArgs("backend").
 		WillReturnError(errors.New("db error"))
 
@@ -85,3 +88,132 @@ func TestTeamStorage_ExistsTeam(t *testing.T) {
 	}
 	verifyExpectations(t, mock)
 }
+
+func TestTeamStorage_GetTeamSettings(t *testing.T) {
+	st, mock := newTeamStorage(t)
+	mock.ExpectQuery(regexp.QuoteMeta(`
+select t.name, coalesce(ts.strict_duplicate_check, false), coalesce(ts.ack_timeout_hours, 0), coalesce(ts.review_sla_hours, 0)
+from teams t
+    left join team_settings ts on ts.team_name = t.name
+where t.name = $1
+`)).
+		WithArgs("backend").
+		WillReturnRows(sqlmock.NewRows([]string{"name", "strict_duplicate_check", "ack_timeout_hours", "review_sla_hours"}).AddRow("backend", true, 4, 48))
+	mock.ExpectQuery(regexp.QuoteMeta(`select buddy_team from team_fallbacks where team_name = $1 order by position`)).
+		WithArgs("backend").
+		WillReturnRows(sqlmock.NewRows([]string{"buddy_team"}).AddRow("platform").AddRow("frontend"))
+	mock.ExpectQuery(regexp.QuoteMeta(`select component, path_prefix, user_id from team_required_reviewers where team_name = $1 order by position`)).
+		WithArgs("backend").
+		WillReturnRows(sqlmock.NewRows([]string{"component", "path_prefix", "user_id"}).AddRow("payments", "", "u5"))
+
+	settings, err := st.GetTeamSettings(context.Background(), "backend")
+	if err != nil {
+		t.Fatalf("GetTeamSettings returned err: %v", err)
+	}
+	if settings.TeamName != "backend" || !settings.StrictDuplicateCheck || settings.AckTimeoutHours != 4 || settings.ReviewSLAHours != 48 ||
+		len(settings.FallbackTeams) != 2 || settings.FallbackTeams[0] != "platform" ||
+		len(settings.RequiredReviewers) != 1 || settings.RequiredReviewers[0].UserID != "u5" {
+		t.Fatalf("unexpected settings: %#v", settings)
+	}
+	verifyExpectations(t, mock)
+}
+
+func TestTeamStorage_GetTeamSettings_NotFound(t *testing.T) {
+	st, mock := newTeamStorage(t)
+	mock.ExpectQuery(regexp.QuoteMeta(`from teams t`)).
+		WithArgs("backend").
+		WillReturnError(sql.ErrNoRows)
+
+	_, err := st.GetTeamSettings(context.Background(), "backend")
+	if !errors.Is(err, ErrTeamNotFound) {
+		t.Fatalf("expected ErrTeamNotFound, got %v", err)
+	}
+	verifyExpectations(t, mock)
+}
+
+func TestTeamStorage_UpsertTeamSettings(t *testing.T) {
+	st, mock := newTeamStorage(t)
+	mock.ExpectExec(regexp.QuoteMeta(`insert into team_settings (team_name, strict_duplicate_check, ack_timeout_hours, review_sla_hours) values ($1, $2, $3, $4)`)).
+		WithArgs("backend", true, 0, 24).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectExec(regexp.QuoteMeta(`delete from team_fallbacks where team_name = $1`)).
+		WithArgs("backend").
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectExec(regexp.QuoteMeta(`insert into team_fallbacks (team_name, buddy_team, position) values ($1, $2, $3)`)).
+		WithArgs("backend", "platform", 0).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectExec(regexp.QuoteMeta(`delete from team_required_reviewers where team_name = $1`)).
+		WithArgs("backend").
+		WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectExec(regexp.QuoteMeta(`insert into team_required_reviewers (team_name, position, component, path_prefix, user_id) values ($1, $2, $3, $4, $5)`)).
+		WithArgs("backend", 0, "", "services/payments/", "u5").
+		WillReturnResult(sqlmock.NewResult(0, 1))
+
+	err := st.UpsertTeamSettings(context.Background(), models.TeamSettings{
+		TeamName:             "backend",
+		StrictDuplicateCheck: true,
+		ReviewSLAHours:       24,
+		FallbackTeams:        []string{"platform"},
+		RequiredReviewers:    []models.RequiredReviewerRule{{PathPrefix: "services/payments/", UserID: "u5"}},
+	})
+	if err != nil {
+		t.Fatalf("UpsertTeamSettings returned err: %v", err)
+	}
+	verifyExpectations(t, mock)
+}
+
+func TestTeamStorage_CountOpenPRs(t *testing.T) {
+	st, mock := newTeamStorage(t)
+	mock.ExpectQuery(regexp.QuoteMeta(`where u.team_name = $1
+  and pr.status = $2`)).
+		WithArgs("backend", models.StatusOpen).
+		WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(4))
+
+	count, err := st.CountOpenPRs(context.Background(), "backend")
+	if err != nil {
+		t.Fatalf("CountOpenPRs returned err: %v", err)
+	}
+	if count != 4 {
+		t.Fatalf("expected 4 open prs, got %d", count)
+	}
+	verifyExpectations(t, mock)
+}
+
+func TestTeamStorage_GetTeamStats(t *testing.T) {
+	st, mock := newTeamStorage(t)
+	mock.ExpectQuery(regexp.QuoteMeta(`(select count(*) from users where team_name = $1 and is_active)`)).
+		WithArgs("backend", models.StatusOpen).
+		WillReturnRows(sqlmock.NewRows([]string{"members", "active", "open_prs", "open_assignments"}).AddRow(5, 4, 2, 3))
+
+	stats, err := st.GetTeamStats(context.Background(), "backend")
+	if err != nil {
+		t.Fatalf("GetTeamStats returned err: %v", err)
+	}
+	want := models.TeamStats{MembersCount: 5, ActiveMembers: 4, OpenPRsAuthored: 2, OpenAssignments: 3}
+	if *stats != want {
+		t.Fatalf("unexpected stats: %#v", stats)
+	}
+	verifyExpectations(t, mock)
+}
+
+func TestTeamStorage_GetMembershipHistory(t *testing.T) {
+	st, mock := newTeamStorage(t)
+	changedAt := time.Date(2025, 3, 1, 10, 0, 0, 0, time.UTC)
+	mock.ExpectQuery(regexp.QuoteMeta("from team_membership_history")).
+		WithArgs("backend", nil, nil).
+		WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(2))
+	mock.ExpectQuery(regexp.QuoteMeta("order by changed_at desc, id desc")).
+		WithArgs("backend", nil, nil, 10, 0).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "team_name", "user_id", "change", "other_team", "changed_at"}).
+			AddRow(2, "backend", "u2", models.MembershipJoined, "frontend", changedAt).
+			AddRow(1, "backend", "u1", models.MembershipJoined, "", changedAt.Add(-time.Hour)))
+
+	changes, total, err := st.GetMembershipHistory(context.Background(), models.MembershipHistoryQuery{TeamName: "backend", Limit: 10})
+	if err != nil {
+		t.Fatalf("GetMembershipHistory returned err: %v", err)
+	}
+	if total != 2 || len(changes) != 2 || changes[0].OtherTeam != "frontend" || !changes[0].ChangedAt.Equal(changedAt) {
+		t.Fatalf("unexpected history %d %+v", total, changes)
+	}
+	verifyExpectations(t, mock)
+}
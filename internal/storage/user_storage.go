@@ -7,17 +7,21 @@ import (
 	"fmt"
 	"log/slog"
 	"strings"
+	"time"
 
 	"github.com/cloudyy74/pr-reviewer-service/internal/models"
 	"github.com/cloudyy74/pr-reviewer-service/pkg/postgres"
 )
 
 var (
-	ErrUserNotFound = errors.New("user not found")
-	ErrNoCandidate  = errors.New("no active candidate")
+	ErrUserNotFound       = errors.New("user not found")
+	ErrNoCandidate        = errors.New("no active candidate")
+	ErrUserVersionChanged = errors.New("user version changed")
+	ErrAbsenceNotFound    = errors.New("absence not found")
 )
 
 type UserStorage struct {
+	prStatusColumn
 	db  *postgres.Postgres
 	log *slog.Logger
 }
@@ -35,24 +39,63 @@ func NewUserStorage(db *postgres.Postgres, log *slog.Logger) (*UserStorage, erro
 	}, nil
 }
 
+// UpsertUser creates the user or overwrites an existing one. A non-zero
+// u.Version makes the overwrite conditional on the stored version, failing
+// with ErrUserVersionChanged if the user was updated since it was read.
 func (s *UserStorage) UpsertUser(ctx context.Context, u models.User, teamName string) error {
 	exec := getExecer(ctx, s.db.DB)
-	_, err := exec.ExecContext(
+	res, err := exec.ExecContext(
 		ctx,
 		`
-insert into users (id, username, team_name, is_active) values ($1, $2, $3, $4) on conflict (id) do update set
+insert into users (id, username, team_name, is_active, review_weight)
+values ($1, $2, $3, $4, coalesce(nullif($5::real, 0), 1)) on conflict (id) do update set
 username = excluded.username,
 team_name = excluded.team_name,
-is_active = excluded.is_active`,
+is_active = excluded.is_active,
+review_weight = coalesce(nullif($5::real, 0), users.review_weight),
+version = users.version + 1
+where $6::bigint = 0 or users.version = $6`,
 		u.ID,
 		u.Username,
 		teamName,
 		u.IsActive,
+		u.Weight,
+		u.Version,
 	)
 	if err != nil {
 		s.log.Error("failed to upsert user", slog.Any("error", err))
 		return fmt.Errorf("upsert user: %w", err)
 	}
+	affected, err := res.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("upsert user rows affected: %w", err)
+	}
+	if affected == 0 {
+		return fmt.Errorf("upsert user %s: %w", u.ID, ErrUserVersionChanged)
+	}
+	return nil
+}
+
+// AddMembershipChanges appends joins and leaves to the team membership
+// history.
+func (s *UserStorage) AddMembershipChanges(ctx context.Context, changes []models.MembershipChange) error {
+	exec := getExecer(ctx, s.db.DB)
+	for _, c := range changes {
+		_, err := exec.ExecContext(
+			ctx,
+			`
+insert into team_membership_history (team_name, user_id, change, other_team)
+values ($1, $2, $3, nullif($4, ''))`,
+			c.TeamName,
+			c.UserID,
+			c.Change,
+			c.OtherTeam,
+		)
+		if err != nil {
+			s.log.Error("failed to add membership change", slog.Any("error", err), slog.String("user_id", c.UserID))
+			return fmt.Errorf("add membership change: %w", err)
+		}
+	}
 	return nil
 }
 
@@ -61,7 +104,7 @@ func (s *UserStorage) GetUsersByTeam(ctx context.Context, teamName string) ([]*m
 	rows, err := exec.QueryContext(
 		ctx,
 		`
-select id, username, is_active from users
+select id, username, is_active, review_weight from users
 where team_name = $1
 `,
 		teamName,
@@ -72,53 +115,63 @@ where team_name = $1
 	}
 	defer rows.Close()
 
-	var users []*models.User
+	users := make([]*models.User, 0)
 
 	for rows.Next() {
 		var u models.User
-		if err := rows.Scan(&u.ID, &u.Username, &u.IsActive); err != nil {
+		if err := rows.Scan(&u.ID, &u.Username, &u.IsActive, &u.Weight); err != nil {
 			return nil, fmt.Errorf("get users by team: %w", err)
 		}
 		users = append(users, &u)
 	}
 
-	if users == nil {
-		users = make([]*models.User, 0)
-	}
-
 	return users, nil
 }
 
-func (s *UserStorage) DeactivateTeamUsers(ctx context.Context, teamName string) (int64, error) {
-	exec := getExecer(ctx, s.db.DB)
-	res, err := exec.ExecContext(
+// DeactivateTeamUsers deactivates every active member of the team and
+// returns the IDs of the users it changed.
+func (s *UserStorage) DeactivateTeamUsers(ctx context.Context, teamName string) ([]string, error) {
+	exec := getQueryExecer(ctx, s.db.DB)
+	rows, err := exec.QueryContext(
 		ctx,
-		`update users set is_active = false where team_name = $1 and is_active`,
+		`update users set is_active = false, version = version + 1 where team_name = $1 and is_active returning id`,
 		teamName,
 	)
 	if err != nil {
 		s.log.Error("failed to deactivate team users", slog.Any("error", err), slog.String("team", teamName))
-		return 0, fmt.Errorf("deactivate team users: %w", err)
+		return nil, fmt.Errorf("deactivate team users: %w", err)
 	}
-	affected, err := res.RowsAffected()
-	if err != nil {
-		return 0, fmt.Errorf("deactivate team users rows: %w", err)
+	defer rows.Close()
+
+	ids := make([]string, 0)
+	for rows.Next() {
+		var id string
+		if err := rows.Scan(&id); err != nil {
+			return nil, fmt.Errorf("scan deactivated user: %w", err)
+		}
+		ids = append(ids, id)
 	}
-	return affected, nil
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("deactivate team users rows: %w", err)
+	}
+	return ids, nil
 }
 
-func (s *UserStorage) SetUserActive(ctx context.Context, userID string, isActive bool) (*models.UserWithTeam, error) {
+// SetUserActive updates the flag only if the stored version equals version;
+// 0 skips the check.
+func (s *UserStorage) SetUserActive(ctx context.Context, userID string, isActive bool, version int64) (*models.UserWithTeam, error) {
 	exec := getQueryExecer(ctx, s.db.DB)
 	var u models.UserWithTeam
 	err := exec.QueryRowContext(ctx,
-		`update users set is_active = $1 where id = $2
-		 returning id, username, team_name, is_active`,
+		`update users set is_active = $1, version = version + 1 where id = $2 and ($3::bigint = 0 or version = $3)
+		 returning id, username, team_name, is_active, review_weight, version`,
 		isActive,
 		userID,
-	).Scan(&u.ID, &u.Username, &u.TeamName, &u.IsActive)
+		version,
+	).Scan(&u.ID, &u.Username, &u.TeamName, &u.IsActive, &u.Weight, &u.Version)
 
 	if err == sql.ErrNoRows {
-		return nil, fmt.Errorf("set user active: %w", ErrUserNotFound)
+		return nil, fmt.Errorf("set user active: %w", s.missingUserErr(ctx, userID, version))
 	}
 	if err != nil {
 		return nil, fmt.Errorf("set user active: %w", err)
@@ -127,14 +180,139 @@ func (s *UserStorage) SetUserActive(ctx context.Context, userID string, isActive
 	return &u, nil
 }
 
+// SetUserWeight updates the weight only if the stored version equals
+// version; 0 skips the check.
+func (s *UserStorage) SetUserWeight(ctx context.Context, userID string, weight float64, version int64) (*models.UserWithTeam, error) {
+	exec := getQueryExecer(ctx, s.db.DB)
+	var u models.UserWithTeam
+	err := exec.QueryRowContext(ctx,
+		`update users set review_weight = $1, version = version + 1 where id = $2 and ($3::bigint = 0 or version = $3)
+		 returning id, username, team_name, is_active, review_weight, version`,
+		weight,
+		userID,
+		version,
+	).Scan(&u.ID, &u.Username, &u.TeamName, &u.IsActive, &u.Weight, &u.Version)
+
+	if err == sql.ErrNoRows {
+		return nil, fmt.Errorf("set user weight: %w", s.missingUserErr(ctx, userID, version))
+	}
+	if err != nil {
+		return nil, fmt.Errorf("set user weight: %w", err)
+	}
+
+	return &u, nil
+}
+
+// SetUserWorkingHours stores the timezone and the working window in minutes
+// after local midnight; nil start and end clear the window. Version works as
+// in SetUserWeight.
+func (s *UserStorage) SetUserWorkingHours(ctx context.Context, userID, timezone string, start, end *int, version int64) (*models.UserWithTeam, error) {
+	exec := getQueryExecer(ctx, s.db.DB)
+	var u models.UserWithTeam
+	var startMin, endMin sql.NullInt16
+	hours := models.WorkingHours{}
+	err := exec.QueryRowContext(ctx,
+		`update users set timezone = $1, work_start_min = $2, work_end_min = $3, version = version + 1
+		 where id = $4 and ($5::bigint = 0 or version = $5)
+		 returning id, username, team_name, is_active, review_weight, version, timezone, work_start_min, work_end_min`,
+		timezone,
+		start,
+		end,
+		userID,
+		version,
+	).Scan(&u.ID, &u.Username, &u.TeamName, &u.IsActive, &u.Weight, &u.Version, &hours.Timezone, &startMin, &endMin)
+
+	if err == sql.ErrNoRows {
+		return nil, fmt.Errorf("set user working hours: %w", s.missingUserErr(ctx, userID, version))
+	}
+	if err != nil {
+		return nil, fmt.Errorf("set user working hours: %w", err)
+	}
+
+	if startMin.Valid && endMin.Valid {
+		hours.Start = clockTime(startMin.Int16)
+		hours.End = clockTime(endMin.Int16)
+	}
+	u.WorkingHours = &hours
+	return &u, nil
+}
+
+func clockTime(minutes int16) string {
+	return fmt.Sprintf("%02d:%02d", minutes/60, minutes%60)
+}
+
+// AddAbsence records an absence of an existing user; dates are "YYYY-MM-DD"
+// and both are inclusive.
+func (s *UserStorage) AddAbsence(ctx context.Context, absence models.Absence) (*models.Absence, error) {
+	exec := getQueryExecer(ctx, s.db.DB)
+	var created models.Absence
+	var start, end time.Time
+	err := exec.QueryRowContext(
+		ctx,
+		`
+insert into user_absences (user_id, start_date, end_date, reason)
+select id, $2::date, $3::date, $4 from users where id = $1
+returning id, user_id, start_date, end_date, reason`,
+		absence.UserID,
+		absence.StartDate,
+		absence.EndDate,
+		absence.Reason,
+	).Scan(&created.ID, &created.UserID, &start, &end, &created.Reason)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, fmt.Errorf("add absence: %w", ErrUserNotFound)
+	}
+	if err != nil {
+		s.log.Error("failed to add absence", slog.Any("error", err), slog.String("user_id", absence.UserID))
+		return nil, fmt.Errorf("add absence: %w", err)
+	}
+	created.StartDate = start.Format(time.DateOnly)
+	created.EndDate = end.Format(time.DateOnly)
+	return &created, nil
+}
+
+func (s *UserStorage) DeleteAbsence(ctx context.Context, id int64) error {
+	exec := getExecer(ctx, s.db.DB)
+	res, err := exec.ExecContext(ctx, `delete from user_absences where id = $1`, id)
+	if err != nil {
+		s.log.Error("failed to delete absence", slog.Any("error", err), slog.Int64("absence_id", id))
+		return fmt.Errorf("delete absence: %w", err)
+	}
+	affected, err := res.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("rows affected: %w", err)
+	}
+	if affected == 0 {
+		return ErrAbsenceNotFound
+	}
+	return nil
+}
+
+// missingUserErr tells a compare-and-swap update that matched no row because
+// the version moved on apart from one whose user does not exist.
+func (s *UserStorage) missingUserErr(ctx context.Context, userID string, version int64) error {
+	if version == 0 {
+		return ErrUserNotFound
+	}
+	exec := getQueryExecer(ctx, s.db.DB)
+	var exists bool
+	err := exec.QueryRowContext(ctx, `select exists (select 1 from users where id = $1)`, userID).Scan(&exists)
+	if err != nil {
+		return fmt.Errorf("check user exists: %w", err)
+	}
+	if exists {
+		return ErrUserVersionChanged
+	}
+	return ErrUserNotFound
+}
+
 func (s *UserStorage) GetUserWithTeam(ctx context.Context, userID string) (*models.UserWithTeam, error) {
 	exec := getQueryExecer(ctx, s.db.DB)
 	var u models.UserWithTeam
 	err := exec.QueryRowContext(
 		ctx,
-		`select id, username, team_name, is_active from users where id = $1`,
+		`select id, username, team_name, is_active, version from users where id = $1`,
 		userID,
-	).Scan(&u.ID, &u.Username, &u.TeamName, &u.IsActive)
+	).Scan(&u.ID, &u.Username, &u.TeamName, &u.IsActive, &u.Version)
 	if errors.Is(err, sql.ErrNoRows) {
 		return nil, fmt.Errorf("get user with team: %w", ErrUserNotFound)
 	}
@@ -145,6 +323,36 @@ func (s *UserStorage) GetUserWithTeam(ctx context.Context, userID string) (*mode
 	return &u, nil
 }
 
+// weightedRandomOrder orders candidates for weighted sampling without
+// replacement: a user with weight w sorts first with probability proportional
+// to w (Efraimidis-Spirakis keys, -ln(U)/w ascending).
+func weightedRandomOrder(weightColumn string) string {
+	return `-ln(1 - random()) / ` + weightColumn
+}
+
+// notExcludedFor is a predicate rejecting candidates in userColumn that a
+// reviewer exclusion rule bars from reviewing the author bound to authorArg.
+func notExcludedFor(userColumn, authorArg string) string {
+	return `not exists (
+      select 1
+      from reviewer_exclusions e
+      where (e.reviewer_id = ` + userColumn + ` and e.author_id = ` + authorArg + `)
+         or (e.mutual and e.reviewer_id = ` + authorArg + ` and e.author_id = ` + userColumn + `)
+  )`
+}
+
+// notAbsent is a predicate rejecting users of the row alias that are on a
+// recorded absence today in their own timezone. Absences only hide users
+// from selection, so nobody has to be reactivated when one ends.
+func notAbsent(alias string) string {
+	return `not exists (
+      select 1
+      from user_absences ab
+      where ab.user_id = ` + alias + `.id
+        and (now() at time zone ` + alias + `.timezone)::date between ab.start_date and ab.end_date
+  )`
+}
+
 func (s *UserStorage) GetActiveTeammates(ctx context.Context, teamName, excludeUserID string, limit int) ([]*models.User, error) {
 	if limit <= 0 {
 		return []*models.User{}, nil
@@ -158,7 +366,9 @@ from users
 where team_name = $1
   and is_active
   and id <> $2
-order by random()
+  and `+notExcludedFor("users.id", "$2")+`
+  and `+notAbsent("users")+`
+order by `+weightedRandomOrder("review_weight")+`
 limit $3
 `,
 		teamName,
@@ -171,7 +381,7 @@ limit $3
 	}
 	defer rows.Close()
 
-	var users []*models.User
+	users := make([]*models.User, 0)
 	for rows.Next() {
 		var u models.User
 		if err := rows.Scan(&u.ID, &u.Username, &u.IsActive); err != nil {
@@ -183,15 +393,285 @@ limit $3
 	return users, nil
 }
 
-func (s *UserStorage) GetRandomActiveTeammate(ctx context.Context, teamName string, excludeIDs []string) (*models.User, error) {
+// GetTeamReviewLoad returns every active member of the team who is not
+// absent with the number of open pull requests they review, including
+// members with none.
+func (s *UserStorage) GetTeamReviewLoad(ctx context.Context, teamName string) ([]*models.ReviewerLoad, error) {
+	exec := getQueryExecer(ctx, s.db.DB)
+	rows, err := exec.QueryContext(
+		ctx,
+		`
+select u.id, u.username, count(pr.id)
+from users u
+    left join pull_requests_reviewers r on r.user_id = u.id
+    left join pull_requests pr on pr.id = r.pull_request_id and `+s.statusColumn("pr")+` = $2
+where u.team_name = $1
+  and u.is_active
+  and `+notAbsent("u")+`
+group by u.id, u.username
+order by u.id
+`,
+		teamName,
+		models.StatusOpen,
+	)
+	if err != nil {
+		s.log.Error("failed to get team review load", slog.Any("error", err), slog.String("team_name", teamName))
+		return nil, fmt.Errorf("get team review load: %w", err)
+	}
+	defer rows.Close()
+
+	loads := make([]*models.ReviewerLoad, 0)
+	for rows.Next() {
+		var l models.ReviewerLoad
+		if err := rows.Scan(&l.UserID, &l.Username, &l.OpenAssignments); err != nil {
+			return nil, fmt.Errorf("scan review load: %w", err)
+		}
+		loads = append(loads, &l)
+	}
+
+	return loads, nil
+}
+
+// GetExcludedReviewers returns the users that reviewer exclusion rules bar
+// from reviewing pull requests by authorID.
+func (s *UserStorage) GetExcludedReviewers(ctx context.Context, authorID string) ([]string, error) {
 	exec := getQueryExecer(ctx, s.db.DB)
-	args := []any{teamName}
-	queryBuilder := strings.Builder{}
-	queryBuilder.WriteString(`
+	rows, err := exec.QueryContext(
+		ctx,
+		`
+select reviewer_id from reviewer_exclusions where author_id = $1
+union
+select author_id from reviewer_exclusions where mutual and reviewer_id = $1
+`,
+		authorID,
+	)
+	if err != nil {
+		s.log.Error("failed to get excluded reviewers", slog.Any("error", err), slog.String("author_id", authorID))
+		return nil, fmt.Errorf("get excluded reviewers: %w", err)
+	}
+	defer rows.Close()
+
+	ids := make([]string, 0)
+	for rows.Next() {
+		var id string
+		if err := rows.Scan(&id); err != nil {
+			return nil, fmt.Errorf("scan excluded reviewer: %w", err)
+		}
+		ids = append(ids, id)
+	}
+
+	return ids, nil
+}
+
+// GetLeastLoadedTeammates returns active teammates ordered by how many open
+// reviews they hold, breaking ties randomly.
+func (s *UserStorage) GetLeastLoadedTeammates(ctx context.Context, teamName, excludeUserID string, limit int) ([]*models.User, error) {
+	if limit <= 0 {
+		return []*models.User{}, nil
+	}
+	exec := getQueryExecer(ctx, s.db.DB)
+	rows, err := exec.QueryContext(
+		ctx,
+		`
+select u.id, u.username, u.is_active
+from users u
+    left join pull_requests_reviewers r on r.user_id = u.id
+    left join pull_requests pr on pr.id = r.pull_request_id and `+s.statusColumn("pr")+` = $4
+where u.team_name = $1
+  and u.is_active
+  and u.id <> $2
+  and `+notExcludedFor("u.id", "$2")+`
+  and `+notAbsent("u")+`
+group by u.id, u.username, u.is_active
+order by count(pr.id), random()
+limit $3
+`,
+		teamName,
+		excludeUserID,
+		limit,
+		models.StatusOpen,
+	)
+	if err != nil {
+		s.log.Error("failed to get least loaded teammates", slog.Any("error", err))
+		return nil, fmt.Errorf("get least loaded teammates: %w", err)
+	}
+	defer rows.Close()
+
+	users := make([]*models.User, 0)
+	for rows.Next() {
+		var u models.User
+		if err := rows.Scan(&u.ID, &u.Username, &u.IsActive); err != nil {
+			return nil, fmt.Errorf("scan teammate: %w", err)
+		}
+		users = append(users, &u)
+	}
+
+	return users, nil
+}
+
+// GetNextRotationTeammates returns the next active teammates after the
+// team's rotation cursor in user id order, wrapping around, and moves the
+// cursor to the last one returned. The cursor row is locked until the
+// surrounding transaction ends, so concurrent assignments take turns.
+func (s *UserStorage) GetNextRotationTeammates(ctx context.Context, teamName, excludeUserID string, limit int) ([]*models.User, error) {
+	if limit <= 0 {
+		return []*models.User{}, nil
+	}
+	exec := getQueryExecer(ctx, s.db.DB)
+	var cursor sql.NullString
+	err := exec.QueryRowContext(
+		ctx,
+		`
+insert into team_rotation_cursors (team_name)
+values ($1)
+on conflict (team_name) do update set team_name = excluded.team_name
+returning last_user_id
+`,
+		teamName,
+	).Scan(&cursor)
+	if err != nil {
+		s.log.Error("failed to lock rotation cursor", slog.Any("error", err), slog.String("team", teamName))
+		return nil, fmt.Errorf("lock rotation cursor: %w", err)
+	}
+
+	rows, err := exec.QueryContext(
+		ctx,
+		`
 select id, username, is_active
 from users
 where team_name = $1
-  and is_active`)
+  and is_active
+  and id <> $2
+  and `+notExcludedFor("users.id", "$2")+`
+  and `+notAbsent("users")+`
+order by id <= $3, id
+limit $4
+`,
+		teamName,
+		excludeUserID,
+		cursor.String,
+		limit,
+	)
+	if err != nil {
+		s.log.Error("failed to get rotation teammates", slog.Any("error", err))
+		return nil, fmt.Errorf("get rotation teammates: %w", err)
+	}
+	defer rows.Close()
+
+	users := make([]*models.User, 0, limit)
+	for rows.Next() {
+		var u models.User
+		if err := rows.Scan(&u.ID, &u.Username, &u.IsActive); err != nil {
+			return nil, fmt.Errorf("scan teammate: %w", err)
+		}
+		users = append(users, &u)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("iterate rotation teammates: %w", err)
+	}
+	if len(users) == 0 {
+		return users, nil
+	}
+
+	if _, err := exec.ExecContext(
+		ctx,
+		`update team_rotation_cursors set last_user_id = $2, updated_at = now() where team_name = $1`,
+		teamName,
+		users[len(users)-1].ID,
+	); err != nil {
+		return nil, fmt.Errorf("advance rotation cursor: %w", err)
+	}
+	return users, nil
+}
+
+// WorkingHoursMinOverlap is how many minutes a day a candidate's working
+// hours must share with the author's to be preferred.
+const WorkingHoursMinOverlap = 60
+
+// utcWorkingWindow selects the working window of the user row alias as its
+// start in minutes after UTC midnight today and its length in minutes; both
+// are null when the user has no working hours.
+func utcWorkingWindow(alias string) string {
+	offset := `(extract(epoch from (now() at time zone ` + alias + `.timezone) - (now() at time zone 'UTC')) / 60)::int`
+	return `((` + alias + `.work_start_min - ` + offset + `) % 1440 + 1440) % 1440 as start,
+        (` + alias + `.work_end_min - ` + alias + `.work_start_min + 1439) % 1440 + 1 as length`
+}
+
+// GetOverlappingTeammates returns active teammates whose working hours
+// overlap the author's by at least WorkingHoursMinOverlap minutes first, then
+// teammates without working hours, then the rest, in weighted random order
+// within each group. Authors without working hours get plain weighted random
+// order. Windows are compared on today's UTC offsets, so daylight saving
+// shifts are taken into account.
+func (s *UserStorage) GetOverlappingTeammates(ctx context.Context, teamName, excludeUserID string, limit int) ([]*models.User, error) {
+	if limit <= 0 {
+		return []*models.User{}, nil
+	}
+	exec := getQueryExecer(ctx, s.db.DB)
+	rows, err := exec.QueryContext(
+		ctx,
+		`
+with author as (
+    select `+utcWorkingWindow("users")+`
+    from users
+    where id = $2 and work_start_min is not null and work_end_min is not null
+)
+select u.id, u.username, u.is_active
+from users u
+    cross join lateral (select `+utcWorkingWindow("u")+`) c
+    left join author a on true
+    left join lateral (
+        select sum(greatest(0, least(a.start + a.length, c.start + c.length + shift.k) - greatest(a.start, c.start + shift.k))) as minutes
+        from (values (-1440), (0), (1440)) as shift(k)
+        where a.start is not null and c.start is not null
+    ) overlap on true
+where u.team_name = $1
+  and u.is_active
+  and u.id <> $2
+  and `+notExcludedFor("u.id", "$2")+`
+  and `+notAbsent("u")+`
+order by case when overlap.minutes >= $4 then 0 when overlap.minutes is null then 1 else 2 end,
+    `+weightedRandomOrder("u.review_weight")+`
+limit $3
+`,
+		teamName,
+		excludeUserID,
+		limit,
+		WorkingHoursMinOverlap,
+	)
+	if err != nil {
+		s.log.Error("failed to get overlapping teammates", slog.Any("error", err))
+		return nil, fmt.Errorf("get overlapping teammates: %w", err)
+	}
+	defer rows.Close()
+
+	users := make([]*models.User, 0, limit)
+	for rows.Next() {
+		var u models.User
+		if err := rows.Scan(&u.ID, &u.Username, &u.IsActive); err != nil {
+			return nil, fmt.Errorf("scan teammate: %w", err)
+		}
+		users = append(users, &u)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("iterate overlapping teammates: %w", err)
+	}
+	return users, nil
+}
+
+// GetRandomActiveTeammate picks a random active teammate that is not in
+// excludeIDs, not absent and not barred from reviewing authorID's pull
+// requests.
+func (s *UserStorage) GetRandomActiveTeammate(ctx context.Context, teamName, authorID string, excludeIDs []string) (*models.User, error) {
+	exec := getQueryExecer(ctx, s.db.DB)
+	qb := newQueryBuilder()
+	qb.write(`
+select id, username, is_active
+from users
+where team_name = `, qb.arg(teamName), `
+  and is_active
+  and `, notExcludedFor("users.id", qb.arg(authorID)), `
+  and `, notAbsent("users"))
 
 	unique := make([]string, 0, len(excludeIDs))
 	seen := make(map[string]struct{}, len(excludeIDs))
@@ -207,17 +687,12 @@ where team_name = $1
 		unique = append(unique, id)
 	}
 	if len(unique) > 0 {
-		placeholders := make([]string, len(unique))
-		for i, id := range unique {
-			placeholders[i] = fmt.Sprintf("$%d", i+2)
-			args = append(args, id)
-		}
-		queryBuilder.WriteString("\n  and id not in (" + strings.Join(placeholders, ", ") + ")")
+		qb.write("\n  and id not in (", qb.list(unique), ")")
 	}
-	queryBuilder.WriteString("\norder by random()\nlimit 1")
+	qb.write("\norder by ", weightedRandomOrder("review_weight"), "\nlimit 1")
 
 	var u models.User
-	if err := exec.QueryRowContext(ctx, queryBuilder.String(), args...).Scan(&u.ID, &u.Username, &u.IsActive); err != nil {
+	if err := exec.QueryRowContext(ctx, qb.query(), qb.queryArgs()...).Scan(&u.ID, &u.Username, &u.IsActive); err != nil {
 		if errors.Is(err, sql.ErrNoRows) {
 			return nil, ErrNoCandidate
 		}
@@ -226,3 +701,95 @@ where team_name = $1
 
 	return &u, nil
 }
+
+func (s *UserStorage) GetUsersByIDs(ctx context.Context, userIDs []string) ([]*models.UserWithTeam, error) {
+	if len(userIDs) == 0 {
+		return []*models.UserWithTeam{}, nil
+	}
+	exec := getQueryExecer(ctx, s.db.DB)
+	qb := newQueryBuilder()
+	qb.write("select id, username, team_name, is_active, version from users where id in (", qb.list(userIDs), ") order by id")
+	rows, err := exec.QueryContext(ctx, qb.query(), qb.queryArgs()...)
+	if err != nil {
+		s.log.Error("failed to get users by ids", slog.Any("error", err))
+		return nil, fmt.Errorf("get users by ids: %w", err)
+	}
+	defer rows.Close()
+
+	users := make([]*models.UserWithTeam, 0, len(userIDs))
+	for rows.Next() {
+		var u models.UserWithTeam
+		if err := rows.Scan(&u.ID, &u.Username, &u.TeamName, &u.IsActive, &u.Version); err != nil {
+			return nil, fmt.Errorf("scan user: %w", err)
+		}
+		users = append(users, &u)
+	}
+
+	return users, nil
+}
+
+func (s *UserStorage) GetUsersByUsername(ctx context.Context, username string) ([]*models.UserWithTeam, error) {
+	exec := getQueryExecer(ctx, s.db.DB)
+	rows, err := exec.QueryContext(
+		ctx,
+		`select id, username, team_name, is_active, version from users where username = $1 order by id`,
+		username,
+	)
+	if err != nil {
+		s.log.Error("failed to get users by username", slog.Any("error", err))
+		return nil, fmt.Errorf("get users by username: %w", err)
+	}
+	defer rows.Close()
+
+	users := make([]*models.UserWithTeam, 0, 1)
+	for rows.Next() {
+		var u models.UserWithTeam
+		if err := rows.Scan(&u.ID, &u.Username, &u.TeamName, &u.IsActive, &u.Version); err != nil {
+			return nil, fmt.Errorf("scan user: %w", err)
+		}
+		users = append(users, &u)
+	}
+
+	return users, nil
+}
+
+func (s *UserStorage) GetNotificationSettings(ctx context.Context, userID string) (*models.NotificationSettings, error) {
+	exec := getQueryExecer(ctx, s.db.DB)
+	var settings models.NotificationSettings
+	err := exec.QueryRowContext(
+		ctx,
+		`
+select u.id, coalesce(ns.digest_window_minutes, 0)
+from users u
+    left join user_notification_settings ns on ns.user_id = u.id
+where u.id = $1
+`,
+		userID,
+	).Scan(&settings.UserID, &settings.DigestWindowMinutes)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, fmt.Errorf("get notification settings: %w", ErrUserNotFound)
+	}
+	if err != nil {
+		s.log.Error("failed to get notification settings", slog.Any("error", err), slog.String("user_id", userID))
+		return nil, fmt.Errorf("get notification settings: %w", err)
+	}
+	return &settings, nil
+}
+
+func (s *UserStorage) UpsertNotificationSettings(ctx context.Context, settings models.NotificationSettings) error {
+	exec := getExecer(ctx, s.db.DB)
+	_, err := exec.ExecContext(
+		ctx,
+		`
+insert into user_notification_settings (user_id, digest_window_minutes) values ($1, $2)
+on conflict (user_id) do update set
+digest_window_minutes = excluded.digest_window_minutes`,
+		settings.UserID,
+		settings.DigestWindowMinutes,
+	)
+	if err != nil {
+		s.log.Error("failed to upsert notification settings", slog.Any("error", err), slog.String("user_id", settings.UserID))
+		return fmt.Errorf("upsert notification settings: %w", err)
+	}
+	return nil
+}
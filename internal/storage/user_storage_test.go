@@ -8,6 +8,7 @@ import (
 	"log/slog"
 	"regexp"
 	"testing"
+	"time"
 
 	"github.com/DATA-DOG/go-sqlmock"
 
@@ -37,7 +38,7 @@ func newUserStorage(t *testing.T) (*UserStorage, sqlmock.Sqlmock) {
 func TestUserStorage_UpsertUser(t *testing.T) {
 	st, mock := newUserStorage(t)
 	mock.ExpectExec(regexp.QuoteMeta("insert into users")).
-		WithArgs("u1", "user", "team", true).
+		WithArgs("u1", "user", "team", true, float64(0), int64(0)).
 		WillReturnResult(sqlmock.NewResult(0, 1))
 
 	err := st.UpsertUser(context.Background(), models.User{
@@ -51,12 +52,45 @@ func TestUserStorage_UpsertUser(t *testing.T) {
 	verifyExpectations(t, mock)
 }
 
+func TestUserStorage_UpsertUser_VersionChanged(t *testing.T) {
+	st, mock := newUserStorage(t)
+	mock.ExpectExec(regexp.QuoteMeta("where $6::bigint = 0 or users.version = $6")).
+		WithArgs("u1", "user", "team", true, float64(0), int64(3)).
+		WillReturnResult(sqlmock.NewResult(0, 0))
+
+	err := st.UpsertUser(context.Background(), models.User{ID: "u1", Username: "user", IsActive: true, Version: 3}, "team")
+	if !errors.Is(err, ErrUserVersionChanged) {
+		t.Fatalf("expected ErrUserVersionChanged, got %v", err)
+	}
+	verifyExpectations(t, mock)
+}
+
+func TestUserStorage_AddMembershipChanges(t *testing.T) {
+	st, mock := newUserStorage(t)
+	query := regexp.QuoteMeta("insert into team_membership_history (team_name, user_id, change, other_team)")
+	mock.ExpectExec(query).
+		WithArgs("frontend", "u1", models.MembershipLeft, "backend").
+		WillReturnResult(sqlmock.NewResult(1, 1))
+	mock.ExpectExec(query).
+		WithArgs("backend", "u1", models.MembershipJoined, "frontend").
+		WillReturnResult(sqlmock.NewResult(2, 1))
+
+	err := st.AddMembershipChanges(context.Background(), []models.MembershipChange{
+		{TeamName: "frontend", UserID: "u1", Change: models.MembershipLeft, OtherTeam: "backend"},
+		{TeamName: "backend", UserID: "u1", Change: models.MembershipJoined, OtherTeam: "frontend"},
+	})
+	if err != nil {
+		t.Fatalf("AddMembershipChanges returned err: %v", err)
+	}
+	verifyExpectations(t, mock)
+}
+
 func TestUserStorage_GetUsersByTeam(t *testing.T) {
 	st, mock := newUserStorage(t)
-	rows := sqlmock.NewRows([]string{"id", "username", "is_active"}).
-		AddRow("u1", "user1", true).
-		AddRow("u2", "user2", false)
-	mock.ExpectQuery(regexp.QuoteMeta("select id, username, is_active from users")).
+	rows := sqlmock.NewRows([]string{"id", "username", "is_active", "review_weight"}).
+		AddRow("u1", "user1", true, 1.0).
+		AddRow("u2", "user2", false, 2.5)
+	mock.ExpectQuery(regexp.QuoteMeta("select id, username, is_active, review_weight from users")).
 		WithArgs("team").
 		WillReturnRows(rows)
 
@@ -64,7 +98,7 @@ func TestUserStorage_GetUsersByTeam(t *testing.T) {
 	if err != nil {
 		t.Fatalf("GetUsersByTeam returned err: %v", err)
 	}
-	if len(users) != 2 || users[0].ID != "u1" || users[1].ID != "u2" {
+	if len(users) != 2 || users[0].ID != "u1" || users[1].ID != "u2" || users[1].Weight != 2.5 {
 		t.Fatalf("unexpected users result: %#v", users)
 	}
 	verifyExpectations(t, mock)
@@ -72,8 +106,8 @@ func TestUserStorage_GetUsersByTeam(t *testing.T) {
 
 func TestUserStorage_GetUsersByTeam_Empty(t *testing.T) {
 	st, mock := newUserStorage(t)
-	rows := sqlmock.NewRows([]string{"id", "username", "is_active"})
-	mock.ExpectQuery(regexp.QuoteMeta("select id, username, is_active from users")).
+	rows := sqlmock.NewRows([]string{"id", "username", "is_active", "review_weight"})
+	mock.ExpectQuery(regexp.QuoteMeta("select id, username, is_active, review_weight from users")).
 		WithArgs("team").
 		WillReturnRows(rows)
 
@@ -89,16 +123,16 @@ func TestUserStorage_GetUsersByTeam_Empty(t *testing.T) {
 
 func TestUserStorage_DeactivateTeamUsers(t *testing.T) {
 	st, mock := newUserStorage(t)
-	mock.ExpectExec(regexp.QuoteMeta(`update users set is_active = false where team_name = $1 and is_active`)).
+	mock.ExpectQuery(regexp.QuoteMeta(`update users set is_active = false, version = version + 1 where team_name = $1 and is_active returning id`)).
 		WithArgs("team").
-		WillReturnResult(sqlmock.NewResult(0, 3))
+		WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow("u1").AddRow("u2").AddRow("u3"))
 
-	count, err := st.DeactivateTeamUsers(context.Background(), "team")
+	ids, err := st.DeactivateTeamUsers(context.Background(), "team")
 	if err != nil {
 		t.Fatalf("DeactivateTeamUsers returned err: %v", err)
 	}
-	if count != 3 {
-		t.Fatalf("expected 3 affected rows, got %d", count)
+	if len(ids) != 3 || ids[0] != "u1" {
+		t.Fatalf("expected 3 deactivated users, got %v", ids)
 	}
 	verifyExpectations(t, mock)
 }
@@ -106,33 +140,124 @@ func TestUserStorage_DeactivateTeamUsers(t *testing.T) {
 func TestUserStorage_SetUserActive(t *testing.T) {
 	st, mock := newUserStorage(t)
 	query := regexp.QuoteMeta(`
-update users set is_active = $1 where id = $2
- returning id, username, team_name, is_active`)
+update users set is_active = $1, version = version + 1 where id = $2 and ($3::bigint = 0 or version = $3)
+ returning id, username, team_name, is_active, review_weight, version`)
 	mock.ExpectQuery(query).
-		WithArgs(true, "u1").
-		WillReturnRows(sqlmock.NewRows([]string{"id", "username", "team_name", "is_active"}).
-			AddRow("u1", "user", "team", true))
+		WithArgs(true, "u1", int64(4)).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "username", "team_name", "is_active", "review_weight", "version"}).
+			AddRow("u1", "user", "team", true, 1.0, 5))
 
-	user, err := st.SetUserActive(context.Background(), "u1", true)
+	user, err := st.SetUserActive(context.Background(), "u1", true, 4)
 	if err != nil {
 		t.Fatalf("SetUserActive returned err: %v", err)
 	}
-	if user.ID != "u1" || !user.IsActive {
+	if user.ID != "u1" || !user.IsActive || user.Version != 5 {
+		t.Fatalf("unexpected user returned: %#v", user)
+	}
+	verifyExpectations(t, mock)
+}
+
+func TestUserStorage_SetUserActive_VersionChanged(t *testing.T) {
+	st, mock := newUserStorage(t)
+	mock.ExpectQuery(regexp.QuoteMeta(`update users set is_active = $1, version = version + 1`)).
+		WithArgs(false, "u1", int64(4)).
+		WillReturnError(sql.ErrNoRows)
+	mock.ExpectQuery(regexp.QuoteMeta(`select exists (select 1 from users where id = $1)`)).
+		WithArgs("u1").
+		WillReturnRows(sqlmock.NewRows([]string{"exists"}).AddRow(true))
+
+	_, err := st.SetUserActive(context.Background(), "u1", false, 4)
+	if !errors.Is(err, ErrUserVersionChanged) {
+		t.Fatalf("expected ErrUserVersionChanged, got %v", err)
+	}
+	verifyExpectations(t, mock)
+}
+
+func TestUserStorage_SetUserWeight(t *testing.T) {
+	st, mock := newUserStorage(t)
+	mock.ExpectQuery(regexp.QuoteMeta(`update users set review_weight = $1, version = version + 1 where id = $2`)).
+		WithArgs(2.0, "u1", int64(0)).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "username", "team_name", "is_active", "review_weight", "version"}).
+			AddRow("u1", "user", "team", true, 2.0, 2))
+
+	user, err := st.SetUserWeight(context.Background(), "u1", 2, 0)
+	if err != nil {
+		t.Fatalf("SetUserWeight returned err: %v", err)
+	}
+	if user.Weight != 2 || user.TeamName != "team" {
 		t.Fatalf("unexpected user returned: %#v", user)
 	}
 	verifyExpectations(t, mock)
 }
 
+func TestUserStorage_SetUserWorkingHours(t *testing.T) {
+	st, mock := newUserStorage(t)
+	start, end := 9*60, 17*60+30
+	mock.ExpectQuery(regexp.QuoteMeta(`update users set timezone = $1, work_start_min = $2, work_end_min = $3, version = version + 1`)).
+		WithArgs("Europe/Berlin", start, end, "u1", int64(3)).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "username", "team_name", "is_active", "review_weight", "version", "timezone", "work_start_min", "work_end_min"}).
+			AddRow("u1", "user", "team", true, 1.0, 4, "Europe/Berlin", start, end))
+
+	user, err := st.SetUserWorkingHours(context.Background(), "u1", "Europe/Berlin", &start, &end, 3)
+	if err != nil {
+		t.Fatalf("SetUserWorkingHours returned err: %v", err)
+	}
+	want := models.WorkingHours{Timezone: "Europe/Berlin", Start: "09:00", End: "17:30"}
+	if user.Version != 4 || user.WorkingHours == nil || *user.WorkingHours != want {
+		t.Fatalf("unexpected user returned: %#v %#v", user, user.WorkingHours)
+	}
+	verifyExpectations(t, mock)
+}
+
+func TestUserStorage_AddAbsence(t *testing.T) {
+	st, mock := newUserStorage(t)
+	start := time.Date(2026, 12, 24, 0, 0, 0, 0, time.UTC)
+	mock.ExpectQuery(regexp.QuoteMeta(`select id, $2::date, $3::date, $4 from users where id = $1`)).
+		WithArgs("u1", "2026-12-24", "2027-01-02", "vacation").
+		WillReturnRows(sqlmock.NewRows([]string{"id", "user_id", "start_date", "end_date", "reason"}).
+			AddRow(3, "u1", start, start.AddDate(0, 0, 9), "vacation"))
+	mock.ExpectQuery(regexp.QuoteMeta(`insert into user_absences`)).
+		WithArgs("ghost", "2026-12-24", "2026-12-24", "").
+		WillReturnError(sql.ErrNoRows)
+
+	absence, err := st.AddAbsence(context.Background(), models.Absence{UserID: "u1", StartDate: "2026-12-24", EndDate: "2027-01-02", Reason: "vacation"})
+	if err != nil {
+		t.Fatalf("AddAbsence returned err: %v", err)
+	}
+	if absence.ID != 3 || absence.StartDate != "2026-12-24" || absence.EndDate != "2027-01-02" {
+		t.Fatalf("unexpected absence: %#v", absence)
+	}
+	if _, err := st.AddAbsence(context.Background(), models.Absence{UserID: "ghost", StartDate: "2026-12-24", EndDate: "2026-12-24"}); !errors.Is(err, ErrUserNotFound) {
+		t.Fatalf("expected ErrUserNotFound, got %v", err)
+	}
+	verifyExpectations(t, mock)
+}
+
+func TestUserStorage_DeleteAbsence(t *testing.T) {
+	st, mock := newUserStorage(t)
+	mock.ExpectExec(regexp.QuoteMeta(`delete from user_absences where id = $1`)).
+		WithArgs(int64(3)).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectExec(regexp.QuoteMeta(`delete from user_absences where id = $1`)).
+		WithArgs(int64(4)).
+		WillReturnResult(sqlmock.NewResult(0, 0))
+
+	if err := st.DeleteAbsence(context.Background(), 3); err != nil {
+		t.Fatalf("DeleteAbsence returned err: %v", err)
+	}
+	if err := st.DeleteAbsence(context.Background(), 4); !errors.Is(err, ErrAbsenceNotFound) {
+		t.Fatalf("expected ErrAbsenceNotFound, got %v", err)
+	}
+	verifyExpectations(t, mock)
+}
+
 func TestUserStorage_SetUserActive_NotFound(t *testing.T) {
 	st, mock := newUserStorage(t)
-	query := regexp.QuoteMeta(`
-update users set is_active = $1 where id = $2
- returning id, username, team_name, is_active`)
-	mock.ExpectQuery(query).
-		WithArgs(true, "u1").
+	mock.ExpectQuery(regexp.QuoteMeta(`update users set is_active = $1`)).
+		WithArgs(true, "u1", int64(0)).
 		WillReturnError(sql.ErrNoRows)
 
-	_, err := st.SetUserActive(context.Background(), "u1", true)
+	_, err := st.SetUserActive(context.Background(), "u1", true, 0)
 	if err == nil || !errors.Is(err, ErrUserNotFound) {
 		t.Fatalf("expected ErrUserNotFound, got %v", err)
 	}
@@ -141,10 +266,10 @@ update users set is_active = $1 where id = $2
 
 func TestUserStorage_GetUserWithTeam(t *testing.T) {
 	st, mock := newUserStorage(t)
-	mock.ExpectQuery(regexp.QuoteMeta("select id, username, team_name, is_active from users where id = $1")).
+	mock.ExpectQuery(regexp.QuoteMeta("select id, username, team_name, is_active, version from users where id = $1")).
 		WithArgs("u1").
-		WillReturnRows(sqlmock.NewRows([]string{"id", "username", "team_name", "is_active"}).
-			AddRow("u1", "user", "team", true))
+		WillReturnRows(sqlmock.NewRows([]string{"id", "username", "team_name", "is_active", "version"}).
+			AddRow("u1", "user", "team", true, 1))
 
 	user, err := st.GetUserWithTeam(context.Background(), "u1")
 	if err != nil {
@@ -166,7 +291,19 @@ from users
 where team_name = $1
   and is_active
   and id <> $2
-order by random()
+  and not exists (
+      select 1
+      from reviewer_exclusions e
+      where (e.reviewer_id = users.id and e.author_id = $2)
+         or (e.mutual and e.reviewer_id = $2 and e.author_id = users.id)
+  )
+  and not exists (
+      select 1
+      from user_absences ab
+      where ab.user_id = users.id
+        and (now() at time zone users.timezone)::date between ab.start_date and ab.end_date
+  )
+order by -ln(1 - random()) / review_weight
 limit $3
 `)).
 		WithArgs("team", "u1", 1).
@@ -182,6 +319,92 @@ limit $3
 	verifyExpectations(t, mock)
 }
 
+func TestUserStorage_GetLeastLoadedTeammates(t *testing.T) {
+	st, mock := newUserStorage(t)
+	rows := sqlmock.NewRows([]string{"id", "username", "is_active"}).
+		AddRow("u3", "user3", true).
+		AddRow("u2", "user2", true)
+	mock.ExpectQuery(regexp.QuoteMeta(`order by count(pr.id), random()`)).
+		WithArgs("team", "u1", 2, models.StatusOpen).
+		WillReturnRows(rows)
+
+	users, err := st.GetLeastLoadedTeammates(context.Background(), "team", "u1", 2)
+	if err != nil {
+		t.Fatalf("GetLeastLoadedTeammates returned err: %v", err)
+	}
+	if len(users) != 2 || users[0].ID != "u3" {
+		t.Fatalf("unexpected users: %#v", users)
+	}
+	verifyExpectations(t, mock)
+}
+
+func TestUserStorage_GetOverlappingTeammates(t *testing.T) {
+	st, mock := newUserStorage(t)
+	rows := sqlmock.NewRows([]string{"id", "username", "is_active"}).
+		AddRow("u4", "user4", true).
+		AddRow("u2", "user2", true)
+	mock.ExpectQuery(regexp.QuoteMeta(`order by case when overlap.minutes >= $4 then 0 when overlap.minutes is null then 1 else 2 end`)).
+		WithArgs("team", "u1", 2, WorkingHoursMinOverlap).
+		WillReturnRows(rows)
+
+	users, err := st.GetOverlappingTeammates(context.Background(), "team", "u1", 2)
+	if err != nil {
+		t.Fatalf("GetOverlappingTeammates returned err: %v", err)
+	}
+	if len(users) != 2 || users[0].ID != "u4" {
+		t.Fatalf("unexpected users: %#v", users)
+	}
+	verifyExpectations(t, mock)
+}
+
+func TestUserStorage_GetNextRotationTeammates(t *testing.T) {
+	st, mock := newUserStorage(t)
+	mock.ExpectQuery(regexp.QuoteMeta(`
+insert into team_rotation_cursors (team_name)
+values ($1)
+on conflict (team_name) do update set team_name = excluded.team_name
+returning last_user_id
+`)).
+		WithArgs("team").
+		WillReturnRows(sqlmock.NewRows([]string{"last_user_id"}).AddRow("u4"))
+	mock.ExpectQuery(regexp.QuoteMeta(`order by id <= $3, id`)).
+		WithArgs("team", "u1", "u4", 2).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "username", "is_active"}).
+			AddRow("u5", "user5", true).
+			AddRow("u2", "user2", true))
+	mock.ExpectExec(regexp.QuoteMeta(`update team_rotation_cursors set last_user_id = $2, updated_at = now() where team_name = $1`)).
+		WithArgs("team", "u2").
+		WillReturnResult(sqlmock.NewResult(0, 1))
+
+	users, err := st.GetNextRotationTeammates(context.Background(), "team", "u1", 2)
+	if err != nil {
+		t.Fatalf("GetNextRotationTeammates returned err: %v", err)
+	}
+	if len(users) != 2 || users[0].ID != "u5" || users[1].ID != "u2" {
+		t.Fatalf("unexpected users: %#v", users)
+	}
+	verifyExpectations(t, mock)
+}
+
+func TestUserStorage_GetNextRotationTeammates_NoCandidates(t *testing.T) {
+	st, mock := newUserStorage(t)
+	mock.ExpectQuery(regexp.QuoteMeta(`insert into team_rotation_cursors`)).
+		WithArgs("team").
+		WillReturnRows(sqlmock.NewRows([]string{"last_user_id"}).AddRow(nil))
+	mock.ExpectQuery(regexp.QuoteMeta(`order by id <= $3, id`)).
+		WithArgs("team", "u1", "", 2).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "username", "is_active"}))
+
+	users, err := st.GetNextRotationTeammates(context.Background(), "team", "u1", 2)
+	if err != nil {
+		t.Fatalf("GetNextRotationTeammates returned err: %v", err)
+	}
+	if len(users) != 0 {
+		t.Fatalf("expected no users, got %#v", users)
+	}
+	verifyExpectations(t, mock)
+}
+
 func TestUserStorage_GetRandomActiveTeammate_NoCandidate(t *testing.T) {
 	st, mock := newUserStorage(t)
 	mock.ExpectQuery(regexp.QuoteMeta(`
@@ -189,13 +412,25 @@ select id, username, is_active
 from users
 where team_name = $1
   and is_active
-  and id not in ($2)
-order by random()
+  and not exists (
+      select 1
+      from reviewer_exclusions e
+      where (e.reviewer_id = users.id and e.author_id = $2)
+         or (e.mutual and e.reviewer_id = $2 and e.author_id = users.id)
+  )
+  and not exists (
+      select 1
+      from user_absences ab
+      where ab.user_id = users.id
+        and (now() at time zone users.timezone)::date between ab.start_date and ab.end_date
+  )
+  and id not in ($3, $4)
+order by -ln(1 - random()) / review_weight
 limit 1`)).
-		WithArgs("team", "u1").
+		WithArgs("team", "author", "author", "u1").
 		WillReturnError(sql.ErrNoRows)
 
-	_, err := st.GetRandomActiveTeammate(context.Background(), "team", []string{"u1"})
+	_, err := st.GetRandomActiveTeammate(context.Background(), "team", "author", []string{"author", "u1"})
 	if err == nil || !errors.Is(err, ErrNoCandidate) {
 		t.Fatalf("expected ErrNoCandidate, got %v", err)
 	}
@@ -208,3 +443,80 @@ func verifyExpectations(t *testing.T, mock sqlmock.Sqlmock) {
 		t.Fatalf("unmet expectations: %v", err)
 	}
 }
+
+func TestUserStorage_GetUsersByIDs(t *testing.T) {
+	st, mock := newUserStorage(t)
+	mock.ExpectQuery(regexp.QuoteMeta("select id, username, team_name, is_active, version from users where id in ($1, $2) order by id")).
+		WithArgs("u1", "u2").
+		WillReturnRows(sqlmock.NewRows([]string{"id", "username", "team_name", "is_active", "version"}).
+			AddRow("u1", "alice", "team", true, 1).
+			AddRow("u2", "bob", "team", false, 3))
+
+	users, err := st.GetUsersByIDs(context.Background(), []string{"u1", "u2"})
+	if err != nil {
+		t.Fatalf("GetUsersByIDs returned err: %v", err)
+	}
+	if len(users) != 2 || users[1].Username != "bob" || users[1].IsActive {
+		t.Fatalf("unexpected users: %#v", users)
+	}
+	verifyExpectations(t, mock)
+}
+
+func TestUserStorage_GetUsersByUsername(t *testing.T) {
+	st, mock := newUserStorage(t)
+	mock.ExpectQuery(regexp.QuoteMeta("select id, username, team_name, is_active, version from users where username = $1 order by id")).
+		WithArgs("alice").
+		WillReturnRows(sqlmock.NewRows([]string{"id", "username", "team_name", "is_active", "version"}).
+			AddRow("u1", "alice", "backend", true, 1))
+
+	users, err := st.GetUsersByUsername(context.Background(), "alice")
+	if err != nil {
+		t.Fatalf("GetUsersByUsername returned err: %v", err)
+	}
+	if len(users) != 1 || users[0].ID != "u1" || users[0].TeamName != "backend" {
+		t.Fatalf("unexpected users: %#v", users)
+	}
+	verifyExpectations(t, mock)
+}
+
+func TestUserStorage_GetNotificationSettings(t *testing.T) {
+	st, mock := newUserStorage(t)
+	mock.ExpectQuery(regexp.QuoteMeta(`left join user_notification_settings ns on ns.user_id = u.id`)).
+		WithArgs("u1").
+		WillReturnRows(sqlmock.NewRows([]string{"id", "digest_window_minutes"}).AddRow("u1", 15))
+
+	settings, err := st.GetNotificationSettings(context.Background(), "u1")
+	if err != nil {
+		t.Fatalf("GetNotificationSettings returned err: %v", err)
+	}
+	if settings.UserID != "u1" || settings.DigestWindowMinutes != 15 {
+		t.Fatalf("unexpected settings: %#v", settings)
+	}
+	verifyExpectations(t, mock)
+}
+
+func TestUserStorage_GetNotificationSettings_NotFound(t *testing.T) {
+	st, mock := newUserStorage(t)
+	mock.ExpectQuery(regexp.QuoteMeta(`from users u`)).
+		WithArgs("u1").
+		WillReturnError(sql.ErrNoRows)
+
+	_, err := st.GetNotificationSettings(context.Background(), "u1")
+	if !errors.Is(err, ErrUserNotFound) {
+		t.Fatalf("expected ErrUserNotFound, got %v", err)
+	}
+	verifyExpectations(t, mock)
+}
+
+func TestUserStorage_UpsertNotificationSettings(t *testing.T) {
+	st, mock := newUserStorage(t)
+	mock.ExpectExec(regexp.QuoteMeta(`insert into user_notification_settings (user_id, digest_window_minutes) values ($1, $2)`)).
+		WithArgs("u1", 30).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+
+	err := st.UpsertNotificationSettings(context.Background(), models.NotificationSettings{UserID: "u1", DigestWindowMinutes: 30})
+	if err != nil {
+		t.Fatalf("UpsertNotificationSettings returned err: %v", err)
+	}
+	verifyExpectations(t, mock)
+}
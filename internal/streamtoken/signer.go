@@ -0,0 +1,106 @@
+package streamtoken
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+const minSecretLength = 32
+
+var (
+	ErrInvalidToken = errors.New("invalid stream token")
+	ErrExpiredToken = errors.New("stream token expired")
+)
+
+type Signer struct {
+	mu       sync.RWMutex
+	secret   []byte
+	previous []byte
+	ttl      time.Duration
+	now      func() time.Time
+}
+
+func NewSigner(secret string, ttl time.Duration) (*Signer, error) {
+	if len(secret) < minSecretLength {
+		return nil, fmt.Errorf("stream token secret must be at least %d bytes", minSecretLength)
+	}
+	if ttl <= 0 {
+		return nil, errors.New("stream token ttl must be positive")
+	}
+	return &Signer{
+		secret: []byte(secret),
+		ttl:    ttl,
+		now:    time.Now,
+	}, nil
+}
+
+// Rotate switches to a new signing secret. Tokens signed with the previous
+// secret stay valid until they expire.
+func (s *Signer) Rotate(secret string) error {
+	if len(secret) < minSecretLength {
+		return fmt.Errorf("stream token secret must be at least %d bytes", minSecretLength)
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.previous, s.secret = s.secret, []byte(secret)
+	return nil
+}
+
+func (s *Signer) Issue(userID string) (string, time.Time) {
+	s.mu.RLock()
+	secret := s.secret
+	s.mu.RUnlock()
+
+	expiresAt := s.now().Add(s.ttl).Truncate(time.Second)
+	payload := base64.RawURLEncoding.EncodeToString([]byte(userID)) + "." + strconv.FormatInt(expiresAt.Unix(), 10)
+	return payload + "." + sign(secret, payload), expiresAt
+}
+
+func (s *Signer) Verify(token string) (string, error) {
+	idx := strings.LastIndexByte(token, '.')
+	if idx < 0 {
+		return "", ErrInvalidToken
+	}
+	payload, sig := token[:idx], token[idx+1:]
+	if !s.validSignature(payload, sig) {
+		return "", ErrInvalidToken
+	}
+	rawUser, rawExp, ok := strings.Cut(payload, ".")
+	if !ok {
+		return "", ErrInvalidToken
+	}
+	userID, err := base64.RawURLEncoding.DecodeString(rawUser)
+	if err != nil || len(userID) == 0 {
+		return "", ErrInvalidToken
+	}
+	exp, err := strconv.ParseInt(rawExp, 10, 64)
+	if err != nil {
+		return "", ErrInvalidToken
+	}
+	if !s.now().Before(time.Unix(exp, 0)) {
+		return "", ErrExpiredToken
+	}
+	return string(userID), nil
+}
+
+func (s *Signer) validSignature(payload, sig string) bool {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	if hmac.Equal([]byte(sig), []byte(sign(s.secret, payload))) {
+		return true
+	}
+	return s.previous != nil && hmac.Equal([]byte(sig), []byte(sign(s.previous, payload)))
+}
+
+func sign(secret []byte, payload string) string {
+	mac := hmac.New(sha256.New, secret)
+	mac.Write([]byte(payload))
+	return base64.RawURLEncoding.EncodeToString(mac.Sum(nil))
+}
@@ -0,0 +1,94 @@
+package streamtoken
+
+import (
+	"errors"
+	"strings"
+	"testing"
+	"time"
+)
+
+const testSecret = "0123456789abcdef0123456789abcdef"
+
+func TestSigner_IssueVerify(t *testing.T) {
+	s, err := NewSigner(testSecret, time.Minute)
+	if err != nil {
+		t.Fatalf("NewSigner returned err: %v", err)
+	}
+	now := time.Date(2025, 3, 1, 10, 0, 0, 0, time.UTC)
+	s.now = func() time.Time { return now }
+
+	token, expiresAt := s.Issue("u1")
+	if !expiresAt.Equal(now.Add(time.Minute)) {
+		t.Fatalf("unexpected expiry: %v", expiresAt)
+	}
+	userID, err := s.Verify(token)
+	if err != nil || userID != "u1" {
+		t.Fatalf("expected u1, got %q, %v", userID, err)
+	}
+
+	now = now.Add(time.Minute)
+	if _, err := s.Verify(token); !errors.Is(err, ErrExpiredToken) {
+		t.Fatalf("expected ErrExpiredToken, got %v", err)
+	}
+}
+
+func TestSigner_RejectsTampered(t *testing.T) {
+	s, err := NewSigner(testSecret, time.Minute)
+	if err != nil {
+		t.Fatalf("NewSigner returned err: %v", err)
+	}
+	token, _ := s.Issue("u1")
+	other, _ := s.Issue("u2")
+
+	forged := other[:strings.IndexByte(other, '.')] + token[strings.IndexByte(token, '.'):]
+	for _, bad := range []string{"", "garbage", forged, token + "x"} {
+		if _, err := s.Verify(bad); !errors.Is(err, ErrInvalidToken) {
+			t.Fatalf("expected ErrInvalidToken for %q, got %v", bad, err)
+		}
+	}
+
+	otherSigner, _ := NewSigner(strings.Repeat("z", 32), time.Minute)
+	if _, err := otherSigner.Verify(token); !errors.Is(err, ErrInvalidToken) {
+		t.Fatalf("expected token from another secret to be rejected, got %v", err)
+	}
+}
+
+func TestNewSigner_Validation(t *testing.T) {
+	if _, err := NewSigner("short", time.Minute); err == nil {
+		t.Fatalf("expected error for short secret")
+	}
+	if _, err := NewSigner(testSecret, 0); err == nil {
+		t.Fatalf("expected error for zero ttl")
+	}
+}
+
+func TestSigner_Rotate(t *testing.T) {
+	s, err := NewSigner(testSecret, time.Minute)
+	if err != nil {
+		t.Fatalf("NewSigner returned err: %v", err)
+	}
+	oldToken, _ := s.Issue("u1")
+
+	if err := s.Rotate("short"); err == nil {
+		t.Fatal("expected error for short secret")
+	}
+	if err := s.Rotate(strings.Repeat("n", 32)); err != nil {
+		t.Fatalf("Rotate returned err: %v", err)
+	}
+	newToken, _ := s.Issue("u1")
+	if newToken == oldToken {
+		t.Fatal("expected token signed with the new secret")
+	}
+	for _, token := range []string{oldToken, newToken} {
+		if userID, err := s.Verify(token); err != nil || userID != "u1" {
+			t.Fatalf("expected u1, got %q, %v", userID, err)
+		}
+	}
+
+	if err := s.Rotate(strings.Repeat("m", 32)); err != nil {
+		t.Fatalf("Rotate returned err: %v", err)
+	}
+	if _, err := s.Verify(oldToken); !errors.Is(err, ErrInvalidToken) {
+		t.Fatalf("expected token from two rotations ago to be rejected, got %v", err)
+	}
+}
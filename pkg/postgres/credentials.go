@@ -0,0 +1,128 @@
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"errors"
+	"fmt"
+	"log/slog"
+	"sync"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/jackc/pgx/v5/stdlib"
+)
+
+const (
+	minReloadInterval  = 10 * time.Second
+	defaultMaxIdleConn = 2
+)
+
+// CredentialReloader re-reads the database url from its secret source.
+type CredentialReloader func(ctx context.Context) (string, error)
+
+type credentials struct {
+	mu         sync.RWMutex
+	user       string
+	password   string
+	lastReload time.Time
+}
+
+func (c *credentials) set(user, password string) bool {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.user == user && c.password == password {
+		return false
+	}
+	c.user, c.password = user, password
+	return true
+}
+
+func (c *credentials) get() (string, string) {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	return c.user, c.password
+}
+
+func (c *credentials) allowReload(now time.Time) bool {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if now.Sub(c.lastReload) < minReloadInterval {
+		return false
+	}
+	c.lastReload = now
+	return true
+}
+
+func IsAuthFailure(err error) bool {
+	var pgErr *pgconn.PgError
+	return errors.As(err, &pgErr) && (pgErr.Code == "28P01" || pgErr.Code == "28000")
+}
+
+// UpdateURL switches new connections to the credentials from dbURL and drops
+// idle connections opened with the old ones. Only user and password are
+// rotated; host and database changes still require a restart.
+func (p *Postgres) UpdateURL(dbURL string) error {
+	connConfig, err := pgx.ParseConfig(dbURL)
+	if err != nil {
+		return fmt.Errorf("parse database url: %w", err)
+	}
+	if !p.creds.set(connConfig.User, connConfig.Password) {
+		return nil
+	}
+	p.log.Info("database credentials updated, recycling idle connections", slog.String("user", connConfig.User))
+	if p.DB != nil {
+		p.DB.SetMaxIdleConns(0)
+		p.DB.SetMaxIdleConns(defaultMaxIdleConn)
+	}
+	return nil
+}
+
+func (p *Postgres) open(connConfig pgx.ConnConfig) (*sql.DB, error) {
+	inner := stdlib.GetConnector(connConfig, stdlib.OptionBeforeConnect(func(_ context.Context, cfg *pgx.ConnConfig) error {
+		cfg.User, cfg.Password = p.creds.get()
+		return nil
+	}))
+	db := sql.OpenDB(&reloadingConnector{Connector: inner, pg: p})
+	db.SetMaxIdleConns(defaultMaxIdleConn)
+	return db, nil
+}
+
+// reloadCredentials asks the secret source for a fresh url after an auth
+// failure and reports whether the credentials changed.
+func (p *Postgres) reloadCredentials(ctx context.Context) bool {
+	if p.reload == nil || !p.creds.allowReload(time.Now()) {
+		return false
+	}
+	user, password := p.creds.get()
+	dbURL, err := p.reload(ctx)
+	if err != nil {
+		p.log.Error("failed to reload database credentials", slog.Any("error", err))
+		return false
+	}
+	if err := p.UpdateURL(dbURL); err != nil {
+		p.log.Error("failed to apply database credentials", slog.Any("error", err))
+		return false
+	}
+	newUser, newPassword := p.creds.get()
+	return user != newUser || password != newPassword
+}
+
+type reloadingConnector struct {
+	driver.Connector
+	pg *Postgres
+}
+
+func (c *reloadingConnector) Connect(ctx context.Context) (driver.Conn, error) {
+	conn, err := c.Connector.Connect(ctx)
+	if err == nil || !IsAuthFailure(err) {
+		return conn, err
+	}
+	c.pg.log.Warn("database authentication failed, reloading credentials", slog.Any("error", err))
+	if !c.pg.reloadCredentials(ctx) {
+		return nil, err
+	}
+	return c.Connector.Connect(ctx)
+}
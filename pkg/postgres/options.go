@@ -27,3 +27,11 @@ func ConnMaxLifetime(lifetime time.Duration) Option {
 		p.connMaxLifetime = lifetime
 	}
 }
+
+// ReloadCredentials sets the source asked for a fresh database url when a new
+// connection fails authentication, e.g. after a password rotation.
+func ReloadCredentials(reload CredentialReloader) Option {
+	return func(p *Postgres) {
+		p.reload = reload
+	}
+}
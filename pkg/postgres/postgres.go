@@ -3,10 +3,11 @@ package postgres
 import (
 	"context"
 	"database/sql"
+	"fmt"
 	"log/slog"
 	"time"
 
-	_ "github.com/jackc/pgx/v5/stdlib"
+	"github.com/jackc/pgx/v5"
 )
 
 const (
@@ -21,6 +22,9 @@ type Postgres struct {
 	connAttempts    int
 	connTimeout     time.Duration
 	connMaxLifetime time.Duration
+	reload          CredentialReloader
+
+	creds credentials
 
 	DB  *sql.DB
 	log *slog.Logger
@@ -39,9 +43,14 @@ func New(ctx context.Context, dbURL string, log *slog.Logger, opts ...Option) (*
 		opt(pg)
 	}
 
-	var err error
+	connConfig, err := pgx.ParseConfig(dbURL)
+	if err != nil {
+		return nil, fmt.Errorf("parse database url: %w", err)
+	}
+	pg.creds.set(connConfig.User, connConfig.Password)
+
 	for pg.connAttempts > 0 {
-		pg.DB, err = sql.Open("pgx", dbURL)
+		pg.DB, err = pg.open(*connConfig)
 		if err == nil {
 			err = pg.DB.Ping()
 			if err != nil {